@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/homeport/homeport/internal/app/metrics"
+)
+
+// alertsTopic is the WebSocket topic alert state changes are broadcast to.
+const alertsTopic = "alerts"
+
+// redactedSecret replaces channel secrets in responses. Sending it back in an
+// update keeps the stored secret.
+const redactedSecret = "********"
+
+// AlertsHandler handles alert rule, silence and notification channel requests.
+type AlertsHandler struct {
+	engine *metrics.AlertEngine
+}
+
+// NewAlertsHandler creates an alerts handler that evaluates the persisted
+// rules against the metrics service. It starts metrics collection, since rules
+// are evaluated against the collected data.
+func NewAlertsHandler(metricsService *metrics.Service) (*AlertsHandler, error) {
+	store, err := metrics.NewAlertStore("")
+	if err != nil {
+		return nil, err
+	}
+
+	engine := metrics.NewAlertEngine(metricsService, store)
+	engine.Subscribe(func(alert metrics.Alert) {
+		wsHub.Broadcast(alertsTopic, WSBroadcastMessage{
+			Type:      WSTypeAlert,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Data:      alert,
+		})
+	})
+
+	metricsService.StartCollection()
+	engine.Start()
+
+	return &AlertsHandler{engine: engine}, nil
+}
+
+// Close stops evaluating alert rules.
+func (h *AlertsHandler) Close() error {
+	h.engine.Stop()
+	return nil
+}
+
+// RegisterRoutes registers alert routes.
+func (h *AlertsHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/alerts", func(r chi.Router) {
+		r.Get("/", h.ListActiveAlerts)
+		r.Get("/history", h.ListAlertHistory)
+
+		r.Route("/rules", func(r chi.Router) {
+			r.Get("/", h.ListRules)
+			r.Post("/", h.CreateRule)
+			r.Get("/{ruleID}", h.GetRule)
+			r.Put("/{ruleID}", h.UpdateRule)
+			r.Delete("/{ruleID}", h.DeleteRule)
+		})
+
+		r.Route("/silences", func(r chi.Router) {
+			r.Get("/", h.ListSilences)
+			r.Post("/", h.CreateSilence)
+			r.Delete("/{silenceID}", h.DeleteSilence)
+		})
+
+		r.Route("/channels", func(r chi.Router) {
+			r.Get("/", h.ListChannels)
+			r.Post("/", h.CreateChannel)
+			r.Put("/{channelID}", h.UpdateChannel)
+			r.Delete("/{channelID}", h.DeleteChannel)
+			r.Post("/{channelID}/test", h.TestChannel)
+		})
+	})
+}
+
+// ListActiveAlerts returns the pending and firing alerts.
+func (h *AlertsHandler) ListActiveAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts := h.engine.ActiveAlerts()
+	respondJSON(w, r, http.StatusOK, map[string]interface{}{
+		"alerts": alerts,
+		"count":  len(alerts),
+	})
+}
+
+// ListAlertHistory returns recently resolved alerts.
+func (h *AlertsHandler) ListAlertHistory(w http.ResponseWriter, r *http.Request) {
+	alerts := h.engine.History()
+	respondJSON(w, r, http.StatusOK, map[string]interface{}{
+		"alerts": alerts,
+		"count":  len(alerts),
+	})
+}
+
+// AlertRuleRequest is the body of rule create and update requests.
+// Durations use Go syntax, e.g. "5m" or "1h30m".
+type AlertRuleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	MetricName  string   `json:"metric_name"`
+	ContainerID string   `json:"container_id,omitempty"`
+	Aggregation string   `json:"aggregation,omitempty"`
+	Window      string   `json:"window,omitempty"`
+	Operator    string   `json:"operator"`
+	Threshold   float64  `json:"threshold"`
+	For         string   `json:"for,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	Channels    []string `json:"channels,omitempty"`
+	Enabled     *bool    `json:"enabled,omitempty"`
+}
+
+// toRule converts the request to a rule, applying defaults.
+func (req AlertRuleRequest) toRule() (*metrics.AlertRule, error) {
+	rule := &metrics.AlertRule{
+		Name:        req.Name,
+		Description: req.Description,
+		AlertThreshold: metrics.AlertThreshold{
+			MetricName:  req.MetricName,
+			ContainerID: req.ContainerID,
+			Operator:    req.Operator,
+			Value:       req.Threshold,
+			Severity:    metrics.AlertSeverity(req.Severity),
+		},
+		Aggregation: metrics.AggregationType(req.Aggregation),
+		Window:      5 * time.Minute,
+		Channels:    req.Channels,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+	if rule.Aggregation == "" {
+		rule.Aggregation = metrics.AggregationAvg
+	}
+	if rule.Severity == "" {
+		rule.Severity = metrics.AlertSeverityWarning
+	}
+
+	if req.Window != "" {
+		window, err := time.ParseDuration(req.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window: %w", err)
+		}
+		rule.Window = window
+	}
+	if req.For != "" {
+		duration, err := time.ParseDuration(req.For)
+		if err != nil {
+			return nil, fmt.Errorf("invalid for duration: %w", err)
+		}
+		rule.Duration = duration
+	}
+
+	return rule, nil
+}
+
+// AlertRuleResponse represents an alert rule.
+type AlertRuleResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	MetricName  string    `json:"metric_name"`
+	ContainerID string    `json:"container_id,omitempty"`
+	Aggregation string    `json:"aggregation"`
+	Window      string    `json:"window"`
+	Operator    string    `json:"operator"`
+	Threshold   float64   `json:"threshold"`
+	For         string    `json:"for"`
+	Severity    string    `json:"severity"`
+	Channels    []string  `json:"channels"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newAlertRuleResponse(rule *metrics.AlertRule) AlertRuleResponse {
+	channels := rule.Channels
+	if channels == nil {
+		channels = []string{}
+	}
+	return AlertRuleResponse{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Description: rule.Description,
+		MetricName:  rule.MetricName,
+		ContainerID: rule.ContainerID,
+		Aggregation: string(rule.Aggregation),
+		Window:      rule.Window.String(),
+		Operator:    rule.Operator,
+		Threshold:   rule.Value,
+		For:         rule.Duration.String(),
+		Severity:    string(rule.Severity),
+		Channels:    channels,
+		Enabled:     rule.Enabled,
+		CreatedAt:   rule.CreatedAt,
+		UpdatedAt:   rule.UpdatedAt,
+	}
+}
+
+// ListRules returns every alert rule.
+func (h *AlertsHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules := h.engine.Store().ListRules()
+	responses := make([]AlertRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = newAlertRuleResponse(rule)
+	}
+	respondJSON(w, r, http.StatusOK, map[string]interface{}{
+		"rules": responses,
+		"count": len(responses),
+	})
+}
+
+// CreateRule creates an alert rule.
+func (h *AlertsHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	h.saveRule(w, r, "", http.StatusCreated)
+}
+
+// GetRule returns an alert rule.
+func (h *AlertsHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.engine.Store().GetRule(chi.URLParam(r, "ruleID"))
+	if err != nil {
+		respondError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	respondJSON(w, r, http.StatusOK, newAlertRuleResponse(rule))
+}
+
+// UpdateRule replaces an alert rule.
+func (h *AlertsHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "ruleID")
+	if _, err := h.engine.Store().GetRule(ruleID); err != nil {
+		respondError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	h.saveRule(w, r, ruleID, http.StatusOK)
+}
+
+// saveRule decodes a rule request and stores it under ruleID, or a new ID
+// when ruleID is empty.
+func (h *AlertsHandler) saveRule(w http.ResponseWriter, r *http.Request, ruleID string, status int) {
+	var req AlertRuleRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		respondError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule, err := req.toRule()
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, channelID := range rule.Channels {
+		if _, err := h.engine.Store().GetChannel(channelID); err != nil {
+			respondError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	rule.ID = ruleID
+
+	saved, err := h.engine.Store().SaveRule(rule)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, r, status, newAlertRuleResponse(saved))
+}
+
+// DeleteRule deletes an alert rule. Its active alert is dropped at the next
+// evaluation.
+func (h *AlertsHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "ruleID")
+	if err := h.engine.Store().DeleteRule(ruleID); err != nil {
+		respondError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	respondJSON(w, r, http.StatusOK, map[string]string{"status": "deleted", "id": ruleID})
+}
+
+// SilenceRequest is the body of silence create requests. Either Duration or
+// EndsAt sets when the silence ends.
+type SilenceRequest struct {
+	RuleID    string     `json:"rule_id,omitempty"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	Duration  string     `json:"duration,omitempty"`
+	Comment   string     `json:"comment,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+}
+
+// ListSilences returns every silence, including expired ones.
+func (h *AlertsHandler) ListSilences(w http.ResponseWriter, r *http.Request) {
+	silences := h.engine.Store().ListSilences()
+	respondJSON(w, r, http.StatusOK, map[string]interface{}{
+		"silences": silences,
+		"count":    len(silences),
+	})
+}
+
+// CreateSilence creates a silence.
+func (h *AlertsHandler) CreateSilence(w http.ResponseWriter, r *http.Request) {
+	var req SilenceRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		respondError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.RuleID != "" {
+		if _, err := h.engine.Store().GetRule(req.RuleID); err != nil {
+			respondError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	silence := &metrics.Silence{
+		RuleID:    req.RuleID,
+		StartsAt:  time.Now(),
+		Comment:   req.Comment,
+		CreatedBy: req.CreatedBy,
+	}
+	if req.StartsAt != nil {
+		silence.StartsAt = *req.StartsAt
+	}
+	switch {
+	case req.EndsAt != nil:
+		silence.EndsAt = *req.EndsAt
+	case req.Duration != "":
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid duration: %v", err))
+			return
+		}
+		silence.EndsAt = silence.StartsAt.Add(duration)
+	}
+
+	saved, err := h.engine.Store().SaveSilence(silence)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, r, http.StatusCreated, saved)
+}
+
+// DeleteSilence deletes a silence, ending it early.
+func (h *AlertsHandler) DeleteSilence(w http.ResponseWriter, r *http.Request) {
+	silenceID := chi.URLParam(r, "silenceID")
+	if err := h.engine.Store().DeleteSilence(silenceID); err != nil {
+		respondError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	respondJSON(w, r, http.StatusOK, map[string]string{"status": "deleted", "id": silenceID})
+}
+
+// redactChannel returns a copy of a channel without its secrets.
+func redactChannel(channel *metrics.NotificationChannel) metrics.NotificationChannel {
+	redacted := *channel
+	if redacted.Token != "" {
+		redacted.Token = redactedSecret
+	}
+	if redacted.Password != "" {
+		redacted.Password = redactedSecret
+	}
+	if len(redacted.Headers) > 0 {
+		redacted.Headers = make(map[string]string, len(channel.Headers))
+		for key := range channel.Headers {
+			redacted.Headers[key] = redactedSecret
+		}
+	}
+	return redacted
+}
+
+// ListChannels returns every notification channel.
+func (h *AlertsHandler) ListChannels(w http.ResponseWriter, r *http.Request) {
+	channels := h.engine.Store().ListChannels()
+	redacted := make([]metrics.NotificationChannel, len(channels))
+	for i, channel := range channels {
+		redacted[i] = redactChannel(channel)
+	}
+	respondJSON(w, r, http.StatusOK, map[string]interface{}{
+		"channels": redacted,
+		"count":    len(redacted),
+	})
+}
+
+// CreateChannel creates a notification channel.
+func (h *AlertsHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	channel := &metrics.NotificationChannel{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(channel); err != nil {
+		respondError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	channel.ID = ""
+
+	saved, err := h.engine.Store().SaveChannel(channel)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, r, http.StatusCreated, redactChannel(saved))
+}
+
+// UpdateChannel updates a notification channel. Fields missing from the body
+// and redacted secrets keep their stored values.
+func (h *AlertsHandler) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	channelID := chi.URLParam(r, "channelID")
+	existing, err := h.engine.Store().GetChannel(channelID)
+	if err != nil {
+		respondError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+
+	channel := *existing
+	channel.Headers = nil
+	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+		respondError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	channel.ID = channelID
+
+	if channel.Token == redactedSecret {
+		channel.Token = existing.Token
+	}
+	if channel.Password == redactedSecret {
+		channel.Password = existing.Password
+	}
+	if channel.Headers == nil {
+		channel.Headers = existing.Headers
+	}
+	for key, value := range channel.Headers {
+		if value == redactedSecret {
+			channel.Headers[key] = existing.Headers[key]
+		}
+	}
+
+	saved, err := h.engine.Store().SaveChannel(&channel)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, r, http.StatusOK, redactChannel(saved))
+}
+
+// DeleteChannel deletes a notification channel.
+func (h *AlertsHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	channelID := chi.URLParam(r, "channelID")
+	if err := h.engine.Store().DeleteChannel(channelID); err != nil {
+		respondError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	respondJSON(w, r, http.StatusOK, map[string]string{"status": "deleted", "id": channelID})
+}
+
+// TestChannel sends a test notification through a channel.
+func (h *AlertsHandler) TestChannel(w http.ResponseWriter, r *http.Request) {
+	channelID := chi.URLParam(r, "channelID")
+	if _, err := h.engine.Store().GetChannel(channelID); err != nil {
+		respondError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	if err := h.engine.TestChannel(ctx, channelID); err != nil {
+		respondError(w, r, http.StatusBadGateway, err.Error())
+		return
+	}
+	respondJSON(w, r, http.StatusOK, map[string]string{
+		"status": "sent",
+	})
+}
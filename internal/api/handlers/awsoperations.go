@@ -5,7 +5,9 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"path"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -20,6 +22,9 @@ type AWSOperationsHandler struct {
 	service    *awsoperations.Service
 	lambda     *awsoperations.LambdaDriver
 	sqs        *awsoperations.SQSDriver
+	s3         *awsoperations.S3Driver
+	dynamodb   *awsoperations.DynamoDBDriver
+	sns        *awsoperations.SNSDriver
 	drivers    *awsoperations.DriverRegistry
 	authorizer authz.Authorizer
 	auditSink  func(authz.Decision) error
@@ -43,6 +48,12 @@ func NewAWSOperationsHandlerWithAuthorization(service *awsoperations.Service, au
 			h.lambda = typed
 		case *awsoperations.SQSDriver:
 			h.sqs = typed
+		case *awsoperations.S3Driver:
+			h.s3 = typed
+		case *awsoperations.DynamoDBDriver:
+			h.dynamodb = typed
+		case *awsoperations.SNSDriver:
+			h.sns = typed
 		}
 	}
 	registry, err := awsoperations.NewDriverRegistry(drivers...)
@@ -74,6 +85,21 @@ func (h *AWSOperationsHandler) RegisterRoutes(r chi.Router) {
 			r.Delete("/messages", h.PurgeSQSQueue)
 			r.Route("/messages/{messageID}", func(r chi.Router) { r.Post("/retry", h.RetrySQSMessage); r.Delete("/", h.DeleteSQSMessage) })
 		})
+		// Flat routes keep GET /services/{service}/resources/{resourceID}
+		// reachable for S3, DynamoDB and SNS.
+		r.Get("/services/s3/resources", h.ListS3Resources)
+		r.Get("/services/s3/resources/{resourceID}/objects", h.ListS3Objects)
+		r.Get("/services/s3/resources/{resourceID}/objects/*", h.GetS3Object)
+		r.Delete("/services/s3/resources/{resourceID}/objects/*", h.DeleteS3Object)
+		r.Post("/services/s3/resources/{resourceID}/presign", h.PresignS3Object)
+		r.Get("/services/dynamodb/resources", h.ListDynamoDBResources)
+		r.Get("/services/dynamodb/resources/{resourceID}/items", h.ScanDynamoDBItems)
+		r.Put("/services/dynamodb/resources/{resourceID}/items", h.PutDynamoDBItem)
+		r.Delete("/services/dynamodb/resources/{resourceID}/items", h.DeleteDynamoDBItem)
+		r.Post("/services/dynamodb/resources/{resourceID}/items/get", h.GetDynamoDBItem)
+		r.Get("/services/sns/resources", h.ListSNSResources)
+		r.Get("/services/sns/resources/{resourceID}/subscriptions", h.ListSNSSubscriptions)
+		r.Post("/services/sns/resources/{resourceID}/publish", h.PublishSNSMessage)
 	})
 }
 func (h *AWSOperationsHandler) ListWorkspaces(w http.ResponseWriter, r *http.Request) {
@@ -261,6 +287,41 @@ func (h *AWSOperationsHandler) ListSQSResources(w http.ResponseWriter, r *http.R
 	}
 	h.resources(w, r, workspace.ID, awsoperations.ServiceSQS, items)
 }
+func (h *AWSOperationsHandler) ListS3Resources(w http.ResponseWriter, r *http.Request) {
+	h.listDriverResources(w, r, awsoperations.ServiceS3, h.s3, h.s3 != nil)
+}
+func (h *AWSOperationsHandler) ListDynamoDBResources(w http.ResponseWriter, r *http.Request) {
+	h.listDriverResources(w, r, awsoperations.ServiceDynamoDB, h.dynamodb, h.dynamodb != nil)
+}
+func (h *AWSOperationsHandler) ListSNSResources(w http.ResponseWriter, r *http.Request) {
+	h.listDriverResources(w, r, awsoperations.ServiceSNS, h.sns, h.sns != nil)
+}
+
+// listDriverResources lists the bound resources of a service through its
+// driver, or their persisted projection while the service is unavailable.
+// configured guards against a nil driver hidden in the interface.
+func (h *AWSOperationsHandler) listDriverResources(w http.ResponseWriter, r *http.Request, service awsoperations.ServiceKey, driver awsoperations.Driver, configured bool) {
+	workspace, ok := h.workspace(w, r)
+	if !ok {
+		return
+	}
+	if state := workspace.Services[service]; state.Status != awsoperations.ServiceStatusAvailable {
+		metadata, _ := awsoperations.ServiceMetadataFor(service)
+		items, _ := awsoperations.NewUnavailableDriver(metadata).List(r.Context(), *workspace)
+		h.resources(w, r, workspace.ID, service, items)
+		return
+	}
+	if !configured {
+		h.backendUnavailable(w, r)
+		return
+	}
+	items, err := driver.List(r.Context(), *workspace)
+	if err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	h.resources(w, r, workspace.ID, service, items)
+}
 func (h *AWSOperationsHandler) resources(w http.ResponseWriter, r *http.Request, id string, service awsoperations.ServiceKey, items []any) {
 	if items == nil {
 		items = []any{}
@@ -438,6 +499,227 @@ func (h *AWSOperationsHandler) PurgeSQSQueue(w http.ResponseWriter, r *http.Requ
 	}
 	h.operation(w, r, workspace.ID, awsoperations.ServiceSQS, chi.URLParam(r, "resourceID"), "purged", map[string]any{"deleted": deleted})
 }
+func (h *AWSOperationsHandler) ListS3Objects(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.s3Workspace(w, r)
+	if !ok {
+		return
+	}
+	objects, err := h.s3.Objects(r.Context(), *workspace, chi.URLParam(r, "resourceID"), r.URL.Query().Get("prefix"))
+	if err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	if objects == nil {
+		objects = []awsoperations.ObjectRecord{}
+	}
+	respondJSON(w, r, 200, struct {
+		WorkspaceID string                       `json:"workspace_id"`
+		Service     awsoperations.ServiceKey     `json:"service"`
+		ResourceID  string                       `json:"resource_id"`
+		Objects     []awsoperations.ObjectRecord `json:"objects"`
+	}{workspace.ID, awsoperations.ServiceS3, chi.URLParam(r, "resourceID"), objects})
+}
+func (h *AWSOperationsHandler) GetS3Object(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.s3Workspace(w, r)
+	if !ok {
+		return
+	}
+	key := chi.URLParam(r, "*")
+	object, body, err := h.s3.Object(r.Context(), *workspace, chi.URLParam(r, "resourceID"), key)
+	if err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	defer body.Close()
+	contentType := object.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	w.Header().Set("Content-Disposition", "attachment; filename="+strconv.Quote(path.Base(key)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, body)
+}
+func (h *AWSOperationsHandler) DeleteS3Object(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.s3Workspace(w, r)
+	if !ok {
+		return
+	}
+	if !h.authorizeMutation(w, r, workspace, awsoperations.ServiceS3, chi.URLParam(r, "resourceID"), "delete-object") {
+		return
+	}
+	key := chi.URLParam(r, "*")
+	if err := h.s3.DeleteObject(r.Context(), *workspace, chi.URLParam(r, "resourceID"), key); err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	h.operation(w, r, workspace.ID, awsoperations.ServiceS3, chi.URLParam(r, "resourceID"), "deleted", map[string]string{"key": key})
+}
+
+type s3PresignRequest struct {
+	Key              string `json:"key"`
+	ExpiresInSeconds int    `json:"expires_in_seconds"`
+}
+
+// PresignS3Object is audited like a mutation: the URL it returns reads the
+// object without further authorization until it expires.
+func (h *AWSOperationsHandler) PresignS3Object(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.s3Workspace(w, r)
+	if !ok {
+		return
+	}
+	if !h.authorizeMutation(w, r, workspace, awsoperations.ServiceS3, chi.URLParam(r, "resourceID"), "presign") {
+		return
+	}
+	input := s3PresignRequest{ExpiresInSeconds: 3600}
+	if !decodeAWSOperationsRequest(w, r, &input) {
+		return
+	}
+	result, err := h.s3.Presign(r.Context(), *workspace, chi.URLParam(r, "resourceID"), input.Key, time.Duration(input.ExpiresInSeconds)*time.Second)
+	if err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	h.operation(w, r, workspace.ID, awsoperations.ServiceS3, chi.URLParam(r, "resourceID"), "presigned", result)
+}
+func (h *AWSOperationsHandler) ScanDynamoDBItems(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.dynamoDBWorkspace(w, r)
+	if !ok {
+		return
+	}
+	limit := 100
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			respondError(w, r, 400, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	var startKey map[string]any
+	if raw := r.URL.Query().Get("start_key"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &startKey); err != nil {
+			respondError(w, r, 400, "start_key must be a JSON key object")
+			return
+		}
+	}
+	page, err := h.dynamodb.Scan(r.Context(), *workspace, chi.URLParam(r, "resourceID"), limit, startKey)
+	if err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	respondJSON(w, r, 200, struct {
+		WorkspaceID string                   `json:"workspace_id"`
+		Service     awsoperations.ServiceKey `json:"service"`
+		ResourceID  string                   `json:"resource_id"`
+		*awsoperations.ItemPage
+	}{workspace.ID, awsoperations.ServiceDynamoDB, chi.URLParam(r, "resourceID"), page})
+}
+
+type dynamoDBItemRequest struct {
+	Key  map[string]any `json:"key"`
+	Item map[string]any `json:"item"`
+}
+
+func (h *AWSOperationsHandler) GetDynamoDBItem(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.dynamoDBWorkspace(w, r)
+	if !ok {
+		return
+	}
+	var input dynamoDBItemRequest
+	if !decodeAWSOperationsRequest(w, r, &input) {
+		return
+	}
+	item, err := h.dynamodb.GetItem(r.Context(), *workspace, chi.URLParam(r, "resourceID"), input.Key)
+	if err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	respondJSON(w, r, 200, struct {
+		WorkspaceID string                   `json:"workspace_id"`
+		Service     awsoperations.ServiceKey `json:"service"`
+		ResourceID  string                   `json:"resource_id"`
+		Item        map[string]any           `json:"item"`
+	}{workspace.ID, awsoperations.ServiceDynamoDB, chi.URLParam(r, "resourceID"), item})
+}
+func (h *AWSOperationsHandler) PutDynamoDBItem(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.dynamoDBWorkspace(w, r)
+	if !ok {
+		return
+	}
+	if !h.authorizeMutation(w, r, workspace, awsoperations.ServiceDynamoDB, chi.URLParam(r, "resourceID"), "put-item") {
+		return
+	}
+	var input dynamoDBItemRequest
+	if !decodeAWSOperationsRequest(w, r, &input) {
+		return
+	}
+	if err := h.dynamodb.PutItem(r.Context(), *workspace, chi.URLParam(r, "resourceID"), input.Item); err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	h.operation(w, r, workspace.ID, awsoperations.ServiceDynamoDB, chi.URLParam(r, "resourceID"), "written", nil)
+}
+func (h *AWSOperationsHandler) DeleteDynamoDBItem(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.dynamoDBWorkspace(w, r)
+	if !ok {
+		return
+	}
+	if !h.authorizeMutation(w, r, workspace, awsoperations.ServiceDynamoDB, chi.URLParam(r, "resourceID"), "delete-item") {
+		return
+	}
+	var input dynamoDBItemRequest
+	if !decodeAWSOperationsRequest(w, r, &input) {
+		return
+	}
+	if err := h.dynamodb.DeleteItem(r.Context(), *workspace, chi.URLParam(r, "resourceID"), input.Key); err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	h.operation(w, r, workspace.ID, awsoperations.ServiceDynamoDB, chi.URLParam(r, "resourceID"), "deleted", nil)
+}
+func (h *AWSOperationsHandler) ListSNSSubscriptions(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.snsWorkspace(w, r)
+	if !ok {
+		return
+	}
+	subscriptions, err := h.sns.Subscriptions(r.Context(), *workspace, chi.URLParam(r, "resourceID"))
+	if err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	if subscriptions == nil {
+		subscriptions = []awsoperations.SubscriptionRecord{}
+	}
+	respondJSON(w, r, 200, struct {
+		WorkspaceID   string                             `json:"workspace_id"`
+		Service       awsoperations.ServiceKey           `json:"service"`
+		ResourceID    string                             `json:"resource_id"`
+		Subscriptions []awsoperations.SubscriptionRecord `json:"subscriptions"`
+	}{workspace.ID, awsoperations.ServiceSNS, chi.URLParam(r, "resourceID"), subscriptions})
+}
+func (h *AWSOperationsHandler) PublishSNSMessage(w http.ResponseWriter, r *http.Request) {
+	workspace, ok := h.snsWorkspace(w, r)
+	if !ok {
+		return
+	}
+	if !h.authorizeMutation(w, r, workspace, awsoperations.ServiceSNS, chi.URLParam(r, "resourceID"), "publish") {
+		return
+	}
+	var input struct {
+		Message string `json:"message"`
+	}
+	if !decodeAWSOperationsRequest(w, r, &input) {
+		return
+	}
+	result, err := h.sns.Publish(r.Context(), *workspace, chi.URLParam(r, "resourceID"), input.Message)
+	if err != nil {
+		h.operationError(w, r, err)
+		return
+	}
+	h.operation(w, r, workspace.ID, awsoperations.ServiceSNS, chi.URLParam(r, "resourceID"), "published", result)
+}
 func (h *AWSOperationsHandler) resource(w http.ResponseWriter, r *http.Request, workspaceID string, service awsoperations.ServiceKey, resource any) {
 	respondJSON(w, r, 200, struct {
 		WorkspaceID string                   `json:"workspace_id"`
@@ -476,6 +758,39 @@ func (h *AWSOperationsHandler) sqsWorkspace(w http.ResponseWriter, r *http.Reque
 	}
 	return workspace, true
 }
+func (h *AWSOperationsHandler) s3Workspace(w http.ResponseWriter, r *http.Request) (*awsoperations.Workspace, bool) {
+	workspace, ok := h.workspace(w, r)
+	if !ok {
+		return nil, false
+	}
+	if h.s3 == nil {
+		h.backendUnavailable(w, r)
+		return nil, false
+	}
+	return workspace, true
+}
+func (h *AWSOperationsHandler) dynamoDBWorkspace(w http.ResponseWriter, r *http.Request) (*awsoperations.Workspace, bool) {
+	workspace, ok := h.workspace(w, r)
+	if !ok {
+		return nil, false
+	}
+	if h.dynamodb == nil {
+		h.backendUnavailable(w, r)
+		return nil, false
+	}
+	return workspace, true
+}
+func (h *AWSOperationsHandler) snsWorkspace(w http.ResponseWriter, r *http.Request) (*awsoperations.Workspace, bool) {
+	workspace, ok := h.workspace(w, r)
+	if !ok {
+		return nil, false
+	}
+	if h.sns == nil {
+		h.backendUnavailable(w, r)
+		return nil, false
+	}
+	return workspace, true
+}
 func (h *AWSOperationsHandler) workspace(w http.ResponseWriter, r *http.Request) (*awsoperations.Workspace, bool) {
 	if h == nil || h.service == nil {
 		respondError(w, r, 500, "AWS operations service is not configured")
@@ -504,6 +819,10 @@ func (h *AWSOperationsHandler) operationError(w http.ResponseWriter, r *http.Req
 		respondError(w, r, 403, "AWS operations resource is not bound to this workspace")
 	case errors.Is(err, awsoperations.ErrServiceUnavailable), errors.Is(err, awsoperations.ErrCapabilityUnavailable):
 		respondError(w, r, 409, "AWS operations service is unavailable")
+	case errors.Is(err, awsoperations.ErrNotFound):
+		respondError(w, r, 404, "AWS operations item not found")
+	case errors.Is(err, awsoperations.ErrInvalidInput):
+		respondError(w, r, 400, "AWS operations request is invalid")
 	default:
 		respondError(w, r, 500, "AWS operations local backend failed")
 	}
@@ -5,12 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/homeport/homeport/internal/app/awsoperations"
@@ -405,6 +407,73 @@ func TestAWSOperationsDoesNotExposeLambdaCreateAndWrapsInvocation(t *testing.T)
 	}
 }
 
+func TestAWSOperationsAuthorizesAndAuditsS3ObjectDeleteBeforeBackendDispatch(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		allowed bool
+	}{
+		{name: "allowed", allowed: true},
+		{name: "denied", allowed: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := &awsOperationsObjectsBackend{}
+			audit := authz.NewAuditLog()
+			handler, workspace := newAuthorizedS3OperationsHandler(t, objects, tc.allowed, audit)
+			server := newAWSOperationsTestServer(t, handler)
+
+			request, err := http.NewRequest(http.MethodDelete, server.URL+"/aws/operations/workspaces/"+workspace.ID+"/services/s3/resources/assets/objects/reports/2026/q1.csv", nil)
+			if err != nil {
+				t.Fatalf("NewRequest(): %v", err)
+			}
+			response, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatalf("DELETE S3 object: %v", err)
+			}
+			defer response.Body.Close()
+
+			wantStatus := http.StatusOK
+			if !tc.allowed {
+				wantStatus = http.StatusForbidden
+			}
+			if response.StatusCode != wantStatus {
+				t.Fatalf("DELETE S3 object status = %d, want %d", response.StatusCode, wantStatus)
+			}
+			if len(objects.deleted) != boolToInt(tc.allowed) || (tc.allowed && objects.deleted[0] != "reports/2026/q1.csv") {
+				t.Fatalf("backend deleted keys = %v, want the nested key only when allowed", objects.deleted)
+			}
+			assertAWSOperationsAuditDecision(t, audit.Decisions(), tc.allowed, "aws-operations:s3:delete-object", workspace.ID, "s3", "assets")
+		})
+	}
+}
+
+func TestAWSOperationsStreamsS3ObjectsAndKeepsGenericDetailRoute(t *testing.T) {
+	handler, workspace := newAuthorizedS3OperationsHandler(t, &awsOperationsObjectsBackend{}, true, authz.NewAuditLog())
+	server := newAWSOperationsTestServer(t, handler)
+	base := "/aws/operations/workspaces/" + workspace.ID + "/services/s3/resources/assets"
+
+	object := getAWSOperations(t, server, base+"/objects/reports/q1.csv")
+	defer object.Body.Close()
+	if object.StatusCode != http.StatusOK || object.Header.Get("Content-Type") != "text/csv" {
+		t.Fatalf("GET S3 object status = %d content type = %q", object.StatusCode, object.Header.Get("Content-Type"))
+	}
+	var content bytes.Buffer
+	if _, err := content.ReadFrom(object.Body); err != nil || content.String() != "reports/q1.csv" {
+		t.Fatalf("GET S3 object body = %q, %v", content.String(), err)
+	}
+
+	missing := getAWSOperations(t, server, base+"/objects/missing.txt")
+	defer missing.Body.Close()
+	if missing.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET missing S3 object status = %d, want %d", missing.StatusCode, http.StatusNotFound)
+	}
+
+	detail := getAWSOperations(t, server, "/aws/operations/workspaces/"+workspace.ID+"/services/s3/resources/s3-imported")
+	defer detail.Body.Close()
+	if detail.StatusCode != http.StatusOK {
+		t.Fatalf("GET S3 resource status = %d, want the generic detail route", detail.StatusCode)
+	}
+}
+
 func newAWSOperationsHandlerFixture(t *testing.T) (*AWSOperationsHandler, *awsoperations.Workspace) {
 	t.Helper()
 	discoveries, err := migrate.NewStateStore(filepath.Join(t.TempDir(), "discoveries.json"))
@@ -474,6 +543,35 @@ func newAuthorizedAWSOperationsHandler(t *testing.T, functionsBackend awsoperati
 	return handler, workspace
 }
 
+func newAuthorizedS3OperationsHandler(t *testing.T, objects awsoperations.ObjectStorageBackend, allowed bool, audit *authz.AuditLog) (*AWSOperationsHandler, *awsoperations.Workspace) {
+	t.Helper()
+	store, err := awsoperations.NewStore(filepath.Join(t.TempDir(), "workspaces.json"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	workspace, err := store.Create(&awsoperations.Workspace{
+		ID:       "operations-workspace",
+		Provider: "aws",
+		Services: map[awsoperations.ServiceKey]awsoperations.ServiceState{
+			awsoperations.ServiceS3: {Status: awsoperations.ServiceStatusAvailable, Capabilities: []awsoperations.Capability{awsoperations.CapabilityRead, awsoperations.CapabilityDelete}},
+		},
+		Bindings: []awsoperations.ResourceBinding{
+			{ImportedResourceID: "s3-imported", Service: awsoperations.ServiceS3, LocalResourceID: "assets", LocalStackID: "stack-1"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create workspace: %v", err)
+	}
+	authorizer := authz.AuthorizerFunc(func(_ context.Context, req authz.Request) (authz.Decision, error) {
+		return authz.Decision{Request: req, Allowed: allowed, Reason: "test decision"}, nil
+	})
+	handler := NewAWSOperationsHandlerWithAuthorization(
+		awsoperations.NewService(nil, store), authorizer, func(decision authz.Decision) error { audit.Record(decision); return nil },
+		awsoperations.NewS3Driver(objects),
+	)
+	return handler, workspace
+}
+
 func assertAWSOperationsAuditDecision(t *testing.T, decisions []authz.Decision, allowed bool, action, workspaceID, service, resourceID string) {
 	t.Helper()
 	if len(decisions) != 1 {
@@ -533,6 +631,28 @@ func (b *awsOperationsQueuesBackend) Purge(context.Context, string, string, stri
 	return 1, nil
 }
 
+type awsOperationsObjectsBackend struct{ deleted []string }
+
+func (*awsOperationsObjectsBackend) Buckets(context.Context) ([]awsoperations.BucketRecord, error) {
+	return []awsoperations.BucketRecord{{Name: "assets"}}, nil
+}
+func (*awsOperationsObjectsBackend) Objects(context.Context, string, string) ([]awsoperations.ObjectRecord, error) {
+	return nil, nil
+}
+func (*awsOperationsObjectsBackend) Object(_ context.Context, _, key string) (*awsoperations.ObjectRecord, io.ReadCloser, error) {
+	if key == "missing.txt" {
+		return nil, nil, awsoperations.ErrNotFound
+	}
+	return &awsoperations.ObjectRecord{Key: key, Size: int64(len(key)), ContentType: "text/csv"}, io.NopCloser(strings.NewReader(key)), nil
+}
+func (b *awsOperationsObjectsBackend) DeleteObject(_ context.Context, _, key string) error {
+	b.deleted = append(b.deleted, key)
+	return nil
+}
+func (*awsOperationsObjectsBackend) Presign(context.Context, string, string, time.Duration) (string, error) {
+	return "", nil
+}
+
 func newAWSOperationsTestServer(t *testing.T, handler *AWSOperationsHandler) *httptest.Server {
 	t.Helper()
 	router := chi.NewRouter()
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
@@ -36,6 +38,11 @@ func NewBackupHandler(cfg *backup.Config) (*BackupHandler, error) {
 	return &BackupHandler{service: svc}, nil
 }
 
+// StartScheduler starts running backup schedules.
+func (h *BackupHandler) StartScheduler() {
+	h.service.StartScheduler()
+}
+
 // Close closes the handler and releases resources.
 func (h *BackupHandler) Close() error {
 	return h.service.Close()
@@ -46,6 +53,14 @@ func (h *BackupHandler) RegisterRoutes(r chi.Router) {
 	r.Route("/backups", func(r chi.Router) {
 		r.Get("/", h.HandleListBackups)
 		r.Post("/", h.HandleCreateBackup)
+		r.Post("/prune", h.HandlePruneBackups)
+		r.Get("/archives", h.HandleListArchives)
+		r.Route("/schedules", func(r chi.Router) {
+			r.Get("/", h.HandleListSchedules)
+			r.Post("/", h.HandleAddSchedule)
+			r.Get("/{scheduleID}", h.HandleGetSchedule)
+			r.Delete("/{scheduleID}", h.HandleRemoveSchedule)
+		})
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", h.HandleGetBackup)
 			r.Delete("/", h.HandleDeleteBackup)
@@ -117,6 +132,101 @@ func (h *BackupHandler) HandleCreateBackup(w http.ResponseWriter, r *http.Reques
 	render.JSON(w, r, bkp)
 }
 
+// HandlePruneBackups handles POST /backups/prune
+func (h *BackupHandler) HandlePruneBackups(w http.ResponseWriter, r *http.Request) {
+	pruned, err := h.service.ApplyRetention(r.Context())
+	if err != nil {
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	if pruned == nil {
+		pruned = []*backup.Backup{}
+	}
+	render.JSON(w, r, map[string]interface{}{
+		"pruned": pruned,
+		"count":  len(pruned),
+	})
+}
+
+// HandleListSchedules handles GET /backups/schedules
+func (h *BackupHandler) HandleListSchedules(w http.ResponseWriter, r *http.Request) {
+	stackID := r.URL.Query().Get("stack_id")
+
+	schedules, err := h.service.ListSchedules(r.Context(), stackID)
+	if err != nil {
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"schedules": schedules,
+		"count":     len(schedules),
+	})
+}
+
+// HandleAddSchedule handles POST /backups/schedules
+func (h *BackupHandler) HandleAddSchedule(w http.ResponseWriter, r *http.Request) {
+	var req backup.ScheduleRequest
+	if !httputil.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Name != "" {
+		if err := validateBackupName(req.Name); err != nil {
+			httputil.BadRequest(w, r, err.Error())
+			return
+		}
+	}
+
+	sched, err := h.service.AddSchedule(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, backup.ErrInvalidSchedule) {
+			httputil.BadRequest(w, r, err.Error())
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	render.JSON(w, r, sched)
+}
+
+// HandleGetSchedule handles GET /backups/schedules/{scheduleID}
+func (h *BackupHandler) HandleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	sched, err := h.service.GetSchedule(r.Context(), chi.URLParam(r, "scheduleID"))
+	if err != nil {
+		if errors.Is(err, backup.ErrScheduleNotFound) {
+			httputil.NotFound(w, r, "schedule not found")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, sched)
+}
+
+// HandleRemoveSchedule handles DELETE /backups/schedules/{scheduleID}
+func (h *BackupHandler) HandleRemoveSchedule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "scheduleID")
+
+	if err := h.service.RemoveSchedule(r.Context(), id); err != nil {
+		if errors.Is(err, backup.ErrScheduleNotFound) {
+			httputil.NotFound(w, r, "schedule not found")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]string{
+		"status": "deleted",
+		"id":     id,
+	})
+}
+
 // HandleGetBackup handles GET /backups/{id}
 func (h *BackupHandler) HandleGetBackup(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -165,18 +275,33 @@ func (h *BackupHandler) HandleRestoreBackup(w http.ResponseWriter, r *http.Reque
 	var req struct {
 		TargetStackID string   `json:"target_stack_id"`
 		Volumes       []string `json:"volumes"`
+		TargetTime    string   `json:"target_time"` // RFC 3339, for point-in-time restores
 	}
 
 	if !httputil.DecodeJSON(w, r, &req) {
 		return
 	}
 
-	err := h.service.RestoreBackup(r.Context(), id, req.TargetStackID, req.Volumes)
+	var err error
+	if req.TargetTime != "" {
+		target, parseErr := time.Parse(time.RFC3339, req.TargetTime)
+		if parseErr != nil {
+			httputil.BadRequest(w, r, "target_time must be an RFC 3339 timestamp")
+			return
+		}
+		err = h.service.RestoreBackupToTime(r.Context(), id, req.TargetStackID, req.Volumes, target)
+	} else {
+		err = h.service.RestoreBackup(r.Context(), id, req.TargetStackID, req.Volumes)
+	}
 	if err != nil {
 		if errors.Is(err, backup.ErrBackupNotFound) {
 			httputil.NotFound(w, r, "backup not found")
 			return
 		}
+		if errors.Is(err, backup.ErrPITRUnavailable) {
+			httputil.BadRequest(w, r, err.Error())
+			return
+		}
 		httputil.InternalError(w, r, err)
 		return
 	}
@@ -187,6 +312,20 @@ func (h *BackupHandler) HandleRestoreBackup(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// HandleListArchives handles GET /backups/archives
+func (h *BackupHandler) HandleListArchives(w http.ResponseWriter, r *http.Request) {
+	archives, err := h.service.ListArchives(r.Context())
+	if err != nil {
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"archives": archives,
+		"count":    len(archives),
+	})
+}
+
 // HandleDownloadBackup handles GET /backups/{id}/download
 func (h *BackupHandler) HandleDownloadBackup(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -217,8 +356,13 @@ func (h *BackupHandler) HandleDownloadBackup(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Set headers for download
-	w.Header().Set("Content-Type", "application/gzip")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"backup-%s.tar.gz\"", id))
+	// Encrypted archives are served as stored
+	contentType, ext := "application/gzip", ".tar.gz"
+	if strings.HasSuffix(filePath, ".enc") {
+		contentType, ext = "application/octet-stream", ".tar.gz.enc"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"backup-%s%s\"", id, ext))
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", info.Size()))
 
 	// Stream the file
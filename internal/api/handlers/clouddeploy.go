@@ -79,7 +79,7 @@ func (h *CloudDeployHandler) Apply(w http.ResponseWriter, r *http.Request) {
 
 func validCloudDeployProvider(provider string) bool {
 	switch provider {
-	case "hetzner", "scaleway", "ovh":
+	case "hetzner", "scaleway", "ovh", "exoscale", "infomaniak":
 		return true
 	default:
 		return false
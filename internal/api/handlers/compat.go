@@ -1,24 +1,61 @@
 package handlers
 
 import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	appcompat "github.com/homeport/homeport/internal/app/compat"
+	compataws "github.com/homeport/homeport/internal/app/compat/aws"
 )
 
 type CompatHandler struct {
-	registry *appcompat.Registry
+	registry   *appcompat.Registry
+	accessKeys *compataws.FileAccessKeyStore
+	verifier   *compataws.SigV4Verifier
+	adminToken string
 }
 
-func NewCompatHandler(registry *appcompat.Registry) *CompatHandler {
-	return &CompatHandler{registry: registry}
+type CompatHandlerOption func(*CompatHandler)
+
+// WithCompatAccessKeys serves the access key API from store. With
+// requireSigV4 set, every AWS adapter request must also be signed with one of
+// its keys.
+func WithCompatAccessKeys(store *compataws.FileAccessKeyStore, requireSigV4 bool) CompatHandlerOption {
+	return func(h *CompatHandler) {
+		h.accessKeys = store
+		if requireSigV4 {
+			h.verifier = compataws.NewSigV4Verifier(store)
+		}
+	}
+}
+
+// WithCompatAdminToken sets the bearer token the access key API requires.
+// Without one the access key API is disabled, since the compat gateway is
+// reachable by the workloads it serves.
+func WithCompatAdminToken(token string) CompatHandlerOption {
+	return func(h *CompatHandler) {
+		h.adminToken = token
+	}
+}
+
+func NewCompatHandler(registry *appcompat.Registry, opts ...CompatHandlerOption) *CompatHandler {
+	h := &CompatHandler{registry: registry}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *CompatHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/compat", h.HandleList)
+	r.Get("/compat/access-keys", h.ListAccessKeys)
+	r.Post("/compat/access-keys", h.CreateAccessKey)
+	r.Delete("/compat/access-keys/{accessKeyID}", h.DeleteAccessKey)
 	r.Handle("/compat/{provider}/{service}", h)
 	r.Handle("/compat/{provider}/{service}/*", h)
 }
@@ -53,7 +90,81 @@ func (h *CompatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		render.JSON(w, r, map[string]string{"message": err.Error()})
 		return
 	}
-	adapter.ServeHTTP(w, trimCompatAdapterPrefix(r, chi.URLParam(r, "provider"), chi.URLParam(r, "service")))
+	provider, service := chi.URLParam(r, "provider"), chi.URLParam(r, "service")
+	var next http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter.ServeHTTP(w, trimCompatAdapterPrefix(r, provider, service))
+	})
+	// Signatures cover the path the client sent, so verify before trimming.
+	if provider == "aws" && h.verifier != nil {
+		next = h.verifier.Handler(service, next)
+	}
+	next.ServeHTTP(w, r)
+}
+
+func (h *CompatHandler) ListAccessKeys(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAccessKeyAdmin(w, r) {
+		return
+	}
+	respondJSON(w, r, http.StatusOK, map[string]any{"access_keys": h.accessKeys.List(r.URL.Query().Get("workspace"))})
+}
+
+func (h *CompatHandler) CreateAccessKey(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAccessKeyAdmin(w, r) {
+		return
+	}
+	var req struct {
+		Workspace string `json:"workspace"`
+		Principal string `json:"principal"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Workspace == "" {
+		respondError(w, r, http.StatusBadRequest, "workspace is required")
+		return
+	}
+	key, err := h.accessKeys.Issue(req.Workspace, req.Principal)
+	if err != nil {
+		respondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, r, http.StatusCreated, key)
+}
+
+func (h *CompatHandler) DeleteAccessKey(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAccessKeyAdmin(w, r) {
+		return
+	}
+	err := h.accessKeys.Revoke(chi.URLParam(r, "accessKeyID"))
+	if errors.Is(err, compataws.ErrAccessKeyNotFound) {
+		respondError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeAccessKeyAdmin writes an error and returns false unless the
+// request carries the admin token.
+func (h *CompatHandler) authorizeAccessKeyAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if h.accessKeys == nil {
+		respondError(w, r, http.StatusNotFound, "compat access keys are not configured")
+		return false
+	}
+	if h.adminToken == "" {
+		respondError(w, r, http.StatusForbidden, "compat access key management requires HOMEPORT_COMPAT_ADMIN_TOKEN")
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		respondError(w, r, http.StatusUnauthorized, "invalid or missing admin token")
+		return false
+	}
+	return true
 }
 
 func trimCompatAdapterPrefix(r *http.Request, provider, service string) *http.Request {
@@ -1,11 +1,19 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/go-chi/chi/v5"
 	appcompat "github.com/homeport/homeport/internal/app/compat"
 	compataws "github.com/homeport/homeport/internal/app/compat/aws"
@@ -35,3 +43,84 @@ func TestCompatHandlerRoutesServiceSubpathsToAdapter(t *testing.T) {
 		t.Fatalf("status = %d body = %s, want 201", resp.Code, resp.Body.String())
 	}
 }
+
+func TestCompatHandlerRequiresSigV4WithIssuedAccessKeys(t *testing.T) {
+	registry := appcompat.NewRegistry()
+	if err := registry.Register(compataws.NewLambdaAdapter()); err != nil {
+		t.Fatal(err)
+	}
+	store, err := compataws.NewFileAccessKeyStore(filepath.Join(t.TempDir(), "access-keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := chi.NewRouter()
+	NewCompatHandler(registry, WithCompatAccessKeys(store, true), WithCompatAdminToken("admin-token")).RegisterRoutes(router)
+
+	const body = `{"FunctionName":"orders-handler","Runtime":"nodejs20.x","Role":"arn:aws:iam::000000000000:role/homeport","Handler":"index.handler","Code":{"ZipFile":"aG9tZXBvcnQ="}}`
+	newRequest := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "http://homeport.test/compat/aws/lambda/2015-03-31/functions", strings.NewReader(body))
+	}
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newRequest())
+	if resp.Code != http.StatusForbidden || resp.Header().Get("X-Amzn-Errortype") != "MissingAuthenticationToken" {
+		t.Fatalf("unsigned status = %d body = %s, want 403 MissingAuthenticationToken", resp.Code, resp.Body.String())
+	}
+
+	issueRequest := func(token string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/compat/access-keys", strings.NewReader(`{"workspace":"shop","principal":"ci"}`))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req
+	}
+	for _, token := range []string{"", "wrong-token"} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, issueRequest(token))
+		if resp.Code != http.StatusUnauthorized {
+			t.Fatalf("issue with token %q status = %d body = %s, want 401", token, resp.Code, resp.Body.String())
+		}
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, issueRequest("admin-token"))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("issue status = %d body = %s, want 201", resp.Code, resp.Body.String())
+	}
+	var key compataws.AccessKey
+	if err := json.NewDecoder(resp.Body).Decode(&key); err != nil || key.SecretAccessKey == "" {
+		t.Fatalf("issued key = %#v, %v", key, err)
+	}
+
+	req := newRequest()
+	sum := sha256.Sum256([]byte(body))
+	creds := aws.Credentials{AccessKeyID: key.AccessKeyID, SecretAccessKey: key.SecretAccessKey}
+	if err := v4.NewSigner().SignHTTP(context.Background(), creds, req, hex.EncodeToString(sum[:]), "lambda", "us-east-1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("signed status = %d body = %s, want 201", resp.Code, resp.Body.String())
+	}
+}
+
+func TestCompatHandlerDisablesAccessKeyAPIWithoutAdminToken(t *testing.T) {
+	store, err := compataws.NewFileAccessKeyStore(filepath.Join(t.TempDir(), "access-keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := chi.NewRouter()
+	NewCompatHandler(appcompat.NewRegistry(), WithCompatAccessKeys(store, true)).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodPost, "/compat/access-keys", strings.NewReader(`{"workspace":"shop"}`))
+	req.Header.Set("Authorization", "Bearer ")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("issue status = %d body = %s, want 403", resp.Code, resp.Body.String())
+	}
+	if keys := store.List("shop"); len(keys) != 0 {
+		t.Fatalf("keys issued without an admin token: %#v", keys)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,6 +15,8 @@ import (
 	appcutover "github.com/homeport/homeport/internal/app/cutover"
 	"github.com/homeport/homeport/internal/app/migrate"
 	domaincutover "github.com/homeport/homeport/internal/domain/cutover"
+	"github.com/homeport/homeport/internal/infrastructure/cutover/dns"
+	"github.com/homeport/homeport/internal/pkg/logger"
 )
 
 // CutoverHandler handles cutover-related HTTP requests.
@@ -28,7 +32,7 @@ type CutoverHandler struct {
 // NewCutoverHandler creates a new cutover handler.
 func NewCutoverHandler() *CutoverHandler {
 	handler := &CutoverHandler{
-		service:     appcutover.NewService(),
+		service:     newCutoverService(),
 		events:      make(map[string][]appcutover.CutoverEvent),
 		subscribers: make(map[string]map[chan appcutover.CutoverEvent]struct{}),
 	}
@@ -45,7 +49,7 @@ func NewCutoverHandler() *CutoverHandler {
 // It is useful to applications that already own the discovery and workspace stores.
 func NewCutoverHandlerWithAWSOperations(operations *awsoperations.Service) *CutoverHandler {
 	handler := &CutoverHandler{
-		service:       appcutover.NewService(),
+		service:       newCutoverService(),
 		awsOperations: operations,
 		events:        make(map[string][]appcutover.CutoverEvent),
 		subscribers:   make(map[string]map[chan appcutover.CutoverEvent]struct{}),
@@ -54,6 +58,66 @@ func NewCutoverHandlerWithAWSOperations(operations *awsoperations.Service) *Cuto
 	return handler
 }
 
+// newCutoverService creates the cutover service with the configured DNS
+// provider registered.
+func newCutoverService() *appcutover.Service {
+	service := openCutoverService()
+	registerCutoverDNSProvider(service)
+	return service
+}
+
+// openCutoverService creates a cutover service that persists executions under
+// ~/.homeport, so they survive a restart. It falls back to an in-memory
+// service, and says so, when the store cannot be opened.
+func openCutoverService() *appcutover.Service {
+	store, err := appcutover.NewStore("")
+	if err != nil {
+		logger.Warn("Failed to open the cutover store, cutovers will not survive a restart", "error", err)
+		return appcutover.NewService()
+	}
+	journalPath, err := appcutover.DefaultJournalPath()
+	if err != nil {
+		logger.Warn("Failed to locate the cutover journal, cutovers will not survive a restart", "error", err)
+		return appcutover.NewService()
+	}
+	service, err := appcutover.NewServiceWithStore(store, domaincutover.NewFileJournal(journalPath))
+	if err != nil {
+		logger.Warn("Failed to load cutover executions, cutovers will not survive a restart", "error", err)
+		return appcutover.NewService()
+	}
+	return service
+}
+
+// registerCutoverDNSProvider registers the DNS provider named by
+// HOMEPORT_CUTOVER_DNS_PROVIDER, configured from the HOMEPORT_CUTOVER_DNS_*
+// variables, so cutovers created with it change records through it.
+func registerCutoverDNSProvider(service *appcutover.Service) {
+	name := os.Getenv("HOMEPORT_CUTOVER_DNS_PROVIDER")
+	if name == "" || name == string(domaincutover.DNSProviderManual) {
+		return
+	}
+	provider, err := dns.CreateProvider(name, &domaincutover.DNSProviderConfig{
+		Type:          domaincutover.DNSProviderType(name),
+		APIKey:        os.Getenv("HOMEPORT_CUTOVER_DNS_API_KEY"),
+		APISecret:     os.Getenv("HOMEPORT_CUTOVER_DNS_API_SECRET"),
+		APIToken:      os.Getenv("HOMEPORT_CUTOVER_DNS_API_TOKEN"),
+		ZoneID:        os.Getenv("HOMEPORT_CUTOVER_DNS_ZONE_ID"),
+		Endpoint:      os.Getenv("HOMEPORT_CUTOVER_DNS_ENDPOINT"),
+		TSIGAlgorithm: os.Getenv("HOMEPORT_CUTOVER_DNS_TSIG_ALGORITHM"),
+		Region:        os.Getenv("HOMEPORT_CUTOVER_DNS_REGION"),
+	})
+	if err != nil {
+		logger.Warn("Cutover DNS provider not available", "provider", name, "error", err)
+		return
+	}
+	service.RegisterDNSProvider(name, provider)
+}
+
+// Service returns the underlying cutover service.
+func (h *CutoverHandler) Service() *appcutover.Service {
+	return h.service
+}
+
 // RegisterAWSLocalBindings accepts identities emitted by trusted deployment or
 // cutover code. It is intentionally not registered as an HTTP endpoint.
 func (h *CutoverHandler) RegisterAWSLocalBindings(discoveryID, targetStackID string, bindings []awsoperations.LocalResourceBinding) error {
@@ -86,10 +150,13 @@ func (h *CutoverHandler) RegisterRoutes(r chi.Router) {
 		r.Post("/preview", h.PreviewCutover)
 		r.Post("/validate", h.ValidatePlan)
 		r.Post("/start", h.StartCutover)
+		r.Get("/interrupted", h.ListInterrupted)
 		r.Route("/{cutoverId}", func(r chi.Router) {
 			r.Get("/status", h.GetStatus)
 			r.Get("/stream", h.StreamProgress)
+			r.Get("/journal", h.GetJournal)
 			r.Post("/cancel", h.CancelCutover)
+			r.Post("/resume", h.ResumeCutover)
 			r.Post("/rollback", h.RollbackCutover)
 		})
 	})
@@ -125,12 +192,84 @@ type DNSChangeRequest struct {
 	TTL        int    `json:"ttl,omitempty"`
 }
 
+// TrafficShiftRequest represents a staged traffic shift in the request.
+type TrafficShiftRequest struct {
+	ID                    string               `json:"id"`
+	Name                  string               `json:"name,omitempty"`
+	Method                string               `json:"method,omitempty"` // "dns_weighted", "traefik", empty for automatic
+	Domain                string               `json:"domain"`
+	RecordName            string               `json:"record_name,omitempty"`
+	Stages                []int                `json:"stages,omitempty"`
+	StageDurationSeconds  int                  `json:"stage_duration_seconds,omitempty"`
+	SampleIntervalSeconds int                  `json:"sample_interval_seconds,omitempty"`
+	GateChecks            []HealthCheckRequest `json:"gate_checks,omitempty"`
+	MaxErrorRate          *float64             `json:"max_error_rate,omitempty"`
+	MetricsURL            string               `json:"metrics_url,omitempty"`
+	MetricsService        string               `json:"metrics_service,omitempty"`
+	RecordType            string               `json:"record_type,omitempty"`
+	TTL                   int                  `json:"ttl,omitempty"`
+	NewValue              string               `json:"new_value,omitempty"`
+	OldValue              string               `json:"old_value,omitempty"`
+	TraefikService        string               `json:"traefik_service,omitempty"`
+	LegacyURL             string               `json:"legacy_url,omitempty"`
+}
+
+// toDomain converts the request to a traffic shift, keeping the defaults
+// for fields that are not set.
+func (req TrafficShiftRequest) toDomain() *domaincutover.TrafficShift {
+	recordName := req.RecordName
+	if recordName == "" {
+		recordName = "@"
+	}
+
+	shift := domaincutover.NewTrafficShift(req.ID, req.Domain, recordName)
+	shift.Name = req.Name
+	shift.Method = domaincutover.TrafficShiftMethod(req.Method)
+	if len(req.Stages) > 0 {
+		shift.Stages = req.Stages
+	}
+	if req.StageDurationSeconds > 0 {
+		shift.StageDuration = time.Duration(req.StageDurationSeconds) * time.Second
+	}
+	if req.SampleIntervalSeconds > 0 {
+		shift.SampleInterval = time.Duration(req.SampleIntervalSeconds) * time.Second
+	}
+	if req.MaxErrorRate != nil {
+		shift.MaxErrorRate = *req.MaxErrorRate
+	}
+	shift.MetricsURL = req.MetricsURL
+	shift.MetricsService = req.MetricsService
+	shift.RecordType = req.RecordType
+	shift.TTL = req.TTL
+	shift.NewValue = req.NewValue
+	shift.OldValue = req.OldValue
+	shift.TraefikService = req.TraefikService
+	shift.LegacyURL = req.LegacyURL
+
+	for _, check := range req.GateChecks {
+		gate := &domaincutover.HealthCheck{
+			ID:       check.ID,
+			Name:     check.Name,
+			Type:     domaincutover.HealthCheckType(check.Type),
+			Endpoint: check.Endpoint,
+			Timeout:  time.Duration(check.Timeout) * time.Second,
+		}
+		if gate.Timeout == 0 {
+			gate.Timeout = 30 * time.Second
+		}
+		shift.GateChecks = append(shift.GateChecks, gate)
+	}
+
+	return shift
+}
+
 // CreateCutoverRequest represents a request to create a cutover plan.
 type CreateCutoverRequest struct {
 	BundleID          string                     `json:"bundle_id"`
 	Name              string                     `json:"name,omitempty"`
 	PreChecks         []HealthCheckRequest       `json:"pre_checks"`
 	DNSChanges        []DNSChangeRequest         `json:"dns_changes"`
+	TrafficShifts     []TrafficShiftRequest      `json:"traffic_shifts,omitempty"`
 	PostChecks        []HealthCheckRequest       `json:"post_checks"`
 	DryRun            bool                       `json:"dry_run"`
 	DNSProvider       string                     `json:"dns_provider,omitempty"`
@@ -156,7 +295,7 @@ func (h *CutoverHandler) ValidatePlan(w http.ResponseWriter, r *http.Request) {
 	var errors []string
 	var warnings []string
 
-	if len(req.DNSChanges) == 0 {
+	if len(req.DNSChanges) == 0 && len(req.TrafficShifts) == 0 {
 		warnings = append(warnings, "No DNS changes specified")
 	}
 
@@ -183,6 +322,12 @@ func (h *CutoverHandler) ValidatePlan(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	for _, shift := range req.TrafficShifts {
+		for _, e := range shift.toDomain().Validate() {
+			errors = append(errors, fmt.Sprintf("Traffic shift '%s': %s", shift.ID, e))
+		}
+	}
+
 	respondJSON(w, r, http.StatusOK, map[string]interface{}{
 		"valid":    len(errors) == 0,
 		"errors":   errors,
@@ -255,6 +400,15 @@ func (h *CutoverHandler) StartCutover(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	trafficShifts := make([]*domaincutover.TrafficShift, len(req.TrafficShifts))
+	for i, shift := range req.TrafficShifts {
+		trafficShifts[i] = shift.toDomain()
+		if errs := trafficShifts[i].Validate(); len(errs) > 0 {
+			respondError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid traffic shift %s: %s", shift.ID, strings.Join(errs, "; ")))
+			return
+		}
+	}
+
 	postChecks := make([]*domaincutover.HealthCheck, len(req.PostChecks))
 	for i, check := range req.PostChecks {
 		postChecks[i] = &domaincutover.HealthCheck{
@@ -271,13 +425,14 @@ func (h *CutoverHandler) StartCutover(w http.ResponseWriter, r *http.Request) {
 
 	// Create the plan
 	plan, err := h.service.CreatePlan(&appcutover.CreatePlanRequest{
-		BundleID:    req.BundleID,
-		Name:        req.Name,
-		PreChecks:   preChecks,
-		DNSChanges:  dnsChanges,
-		PostChecks:  postChecks,
-		DryRun:      req.DryRun,
-		DNSProvider: req.DNSProvider,
+		BundleID:      req.BundleID,
+		Name:          req.Name,
+		PreChecks:     preChecks,
+		DNSChanges:    dnsChanges,
+		TrafficShifts: trafficShifts,
+		PostChecks:    postChecks,
+		DryRun:        req.DryRun,
+		DNSProvider:   req.DNSProvider,
 	})
 
 	if err != nil {
@@ -330,6 +485,11 @@ func (h *CutoverHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	respondJSON(w, r, http.StatusOK, cutoverStatusResponse(exec))
+}
+
+// cutoverStatusResponse converts an execution snapshot to its API form.
+func cutoverStatusResponse(exec *appcutover.CutoverExecutionSnapshot) CutoverStatusResponse {
 	// Calculate progress
 	totalSteps := len(exec.Plan.Steps)
 	completedSteps := 0
@@ -356,14 +516,48 @@ func (h *CutoverHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	respondJSON(w, r, http.StatusOK, CutoverStatusResponse{
-		CutoverID: cutoverID,
+	return CutoverStatusResponse{
+		CutoverID: exec.Plan.ID,
 		Status:    string(exec.Plan.Status),
 		Progress:  progress,
 		Steps:     steps,
 		StartedAt: exec.StartedAt,
 		Logs:      exec.Logs,
 		Error:     exec.Plan.Error,
+	}
+}
+
+// ListInterrupted returns the cutovers that were interrupted by a restart and
+// can be resumed or rolled back.
+func (h *CutoverHandler) ListInterrupted(w http.ResponseWriter, r *http.Request) {
+	interrupted := h.service.Interrupted()
+	cutovers := make([]CutoverStatusResponse, len(interrupted))
+	for i, exec := range interrupted {
+		cutovers[i] = cutoverStatusResponse(exec)
+	}
+
+	respondJSON(w, r, http.StatusOK, map[string]interface{}{
+		"cutovers": cutovers,
+		"count":    len(cutovers),
+	})
+}
+
+// GetJournal returns the journaled DNS mutations of a cutover.
+func (h *CutoverHandler) GetJournal(w http.ResponseWriter, r *http.Request) {
+	cutoverID := chi.URLParam(r, "cutoverId")
+
+	mutations, err := h.service.Journal(cutoverID)
+	if err != nil {
+		respondError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if mutations == nil {
+		mutations = []domaincutover.DNSMutation{}
+	}
+
+	respondJSON(w, r, http.StatusOK, map[string]interface{}{
+		"mutations": mutations,
+		"count":     len(mutations),
 	})
 }
 
@@ -545,6 +739,20 @@ func (h *CutoverHandler) CancelCutover(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ResumeCutover continues an interrupted cutover.
+func (h *CutoverHandler) ResumeCutover(w http.ResponseWriter, r *http.Request) {
+	cutoverID := chi.URLParam(r, "cutoverId")
+
+	if err := h.service.Resume(context.Background(), cutoverID, h.recordCutoverEvent); err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, map[string]string{
+		"status": "running",
+	})
+}
+
 // RollbackCutover manually triggers a rollback.
 func (h *CutoverHandler) RollbackCutover(w http.ResponseWriter, r *http.Request) {
 	cutoverID := chi.URLParam(r, "cutoverId")
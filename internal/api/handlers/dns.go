@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -20,6 +21,7 @@ const (
 	MinTTL              = 1
 	MaxTTL              = 604800
 	DefaultTTL          = 3600
+	MaxZoneFileSize     = 10 << 20
 )
 
 var (
@@ -115,6 +117,21 @@ func validateTTL(ttl uint32) error {
 	return nil
 }
 
+// validateRecord runs the checks applied to every record, whether it comes
+// from the API or from an imported zone file.
+func validateRecord(record dns.Record) error {
+	if err := validateRecordName(record.Name); err != nil {
+		return err
+	}
+	if err := validateRecordType(record.Type); err != nil {
+		return err
+	}
+	if err := validateRecordValue(record.Type, record.Value); err != nil {
+		return err
+	}
+	return validateTTL(record.TTL)
+}
+
 func validateZoneType(zoneType dns.ZoneType) error {
 	if zoneType != dns.ZoneTypePrimary && zoneType != dns.ZoneTypeSecondary {
 		return fmt.Errorf("invalid zone type: must be 'primary' or 'secondary'")
@@ -138,14 +155,24 @@ type DNSHandler struct {
 }
 
 // NewDNSHandler creates a new DNS handler.
-func NewDNSHandler() (*DNSHandler, error) {
-	svc, err := dns.NewService()
+func NewDNSHandler(cfg *dns.Config) (*DNSHandler, error) {
+	svc, err := dns.NewService(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create DNS service: %w", err)
 	}
 	return &DNSHandler{service: svc}, nil
 }
 
+// StartServer starts the embedded authoritative name server when configured.
+func (h *DNSHandler) StartServer() error {
+	return h.service.StartServer()
+}
+
+// Close stops the name server.
+func (h *DNSHandler) Close() error {
+	return h.service.Close()
+}
+
 // CreateZoneRequest represents the request body for creating a zone.
 type CreateZoneRequest struct {
 	Name string       `json:"name"`
@@ -307,30 +334,10 @@ func (h *DNSHandler) HandleCreateRecord(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := validateRecordName(req.Name); err != nil {
-		httputil.BadRequest(w, r, err.Error())
-		return
-	}
-
-	if err := validateRecordType(req.Type); err != nil {
-		httputil.BadRequest(w, r, err.Error())
-		return
-	}
-
-	if err := validateRecordValue(req.Type, req.Value); err != nil {
-		httputil.BadRequest(w, r, err.Error())
-		return
-	}
-
 	if req.TTL == 0 {
 		req.TTL = DefaultTTL
 	}
 
-	if err := validateTTL(req.TTL); err != nil {
-		httputil.BadRequest(w, r, err.Error())
-		return
-	}
-
 	record := dns.Record{
 		Name:     req.Name,
 		Type:     req.Type,
@@ -341,6 +348,11 @@ func (h *DNSHandler) HandleCreateRecord(w http.ResponseWriter, r *http.Request)
 		Port:     req.Port,
 	}
 
+	if err := validateRecord(record); err != nil {
+		httputil.BadRequest(w, r, err.Error())
+		return
+	}
+
 	created, err := h.service.CreateRecord(r.Context(), zoneID, record)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
@@ -403,30 +415,10 @@ func (h *DNSHandler) HandleUpdateRecord(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := validateRecordName(req.Name); err != nil {
-		httputil.BadRequest(w, r, err.Error())
-		return
-	}
-
-	if err := validateRecordType(req.Type); err != nil {
-		httputil.BadRequest(w, r, err.Error())
-		return
-	}
-
-	if err := validateRecordValue(req.Type, req.Value); err != nil {
-		httputil.BadRequest(w, r, err.Error())
-		return
-	}
-
 	if req.TTL == 0 {
 		req.TTL = DefaultTTL
 	}
 
-	if err := validateTTL(req.TTL); err != nil {
-		httputil.BadRequest(w, r, err.Error())
-		return
-	}
-
 	record := dns.Record{
 		Name:     req.Name,
 		Type:     req.Type,
@@ -437,6 +429,11 @@ func (h *DNSHandler) HandleUpdateRecord(w http.ResponseWriter, r *http.Request)
 		Port:     req.Port,
 	}
 
+	if err := validateRecord(record); err != nil {
+		httputil.BadRequest(w, r, err.Error())
+		return
+	}
+
 	updated, err := h.service.UpdateRecord(r.Context(), zoneID, recordID, record)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
@@ -480,6 +477,60 @@ func (h *DNSHandler) HandleDeleteRecord(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// HandleImportZone handles POST /dns/zones/import?name=<zone>
+//
+// The request body is an RFC 1035 zone file.
+func (h *DNSHandler) HandleImportZone(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSuffix(r.URL.Query().Get("name"), ".")
+	if err := validateZoneName(name); err != nil {
+		httputil.BadRequest(w, r, err.Error())
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, MaxZoneFileSize)
+	result, err := h.service.ImportZone(r.Context(), name, body, validateRecord)
+	if err != nil {
+		if errors.Is(err, dns.ErrPersist) {
+			httputil.InternalErrorWithMessage(w, r, "Failed to import zone", err)
+			return
+		}
+		httputil.BadRequest(w, r, err.Error())
+		return
+	}
+
+	render.JSON(w, r, result)
+}
+
+// HandleExportZone handles GET /dns/zones/{zoneID}/export
+func (h *DNSHandler) HandleExportZone(w http.ResponseWriter, r *http.Request) {
+	zoneID := chi.URLParam(r, "zoneID")
+
+	if err := validateDNSID(zoneID, "zone ID"); err != nil {
+		httputil.BadRequest(w, r, err.Error())
+		return
+	}
+
+	zone, err := h.service.GetZone(r.Context(), zoneID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			httputil.NotFound(w, r, "Zone not found")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	zoneFile, err := h.service.ExportZone(r.Context(), zoneID)
+	if err != nil {
+		httputil.InternalErrorWithMessage(w, r, "Failed to export zone", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/dns; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"db.%s\"", zone.Name))
+	_, _ = w.Write([]byte(zoneFile))
+}
+
 // HandleValidateZone handles POST /dns/zones/{zoneID}/validate
 func (h *DNSHandler) HandleValidateZone(w http.ResponseWriter, r *http.Request) {
 	zoneID := chi.URLParam(r, "zoneID")
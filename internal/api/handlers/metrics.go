@@ -25,6 +25,11 @@ func NewMetricsHandler(dockerService *docker.Service) (*MetricsHandler, error) {
 	return &MetricsHandler{service: svc}, nil
 }
 
+// Service returns the underlying metrics service
+func (h *MetricsHandler) Service() *metrics.Service {
+	return h.service
+}
+
 // Close closes the metrics handler resources
 func (h *MetricsHandler) Close() error {
 	if h.service != nil {
@@ -293,9 +293,9 @@ func (h *MigrateHandler) HandleExportProvider(w http.ResponseWriter, r *http.Req
 	}
 
 	// Validate provider
-	validProviders := map[string]bool{"hetzner": true, "scaleway": true, "ovh": true}
+	validProviders := map[string]bool{"hetzner": true, "scaleway": true, "ovh": true, "exoscale": true, "infomaniak": true}
 	if !validProviders[provider] {
-		httputil.BadRequest(w, r, "invalid provider: must be hetzner, scaleway, or ovh")
+		httputil.BadRequest(w, r, "invalid provider: must be hetzner, scaleway, ovh, exoscale, or infomaniak")
 		return
 	}
 
@@ -172,9 +172,9 @@ func (h *StacksHandler) HandleCreatePendingStack(w http.ResponseWriter, r *http.
 	}
 
 	// Validate provider
-	validProviders := map[string]bool{"hetzner": true, "scaleway": true, "ovh": true}
+	validProviders := map[string]bool{"hetzner": true, "scaleway": true, "ovh": true, "exoscale": true, "infomaniak": true}
 	if !validProviders[req.DeploymentConfig.Provider] {
-		httputil.BadRequest(w, r, "provider must be one of: hetzner, scaleway, ovh")
+		httputil.BadRequest(w, r, "provider must be one of: hetzner, scaleway, ovh, exoscale, infomaniak")
 		return
 	}
 
@@ -35,6 +35,7 @@ func (h *SyncHandler) RegisterRoutes(r chi.Router) {
 			r.Post("/pause", h.PauseSync)
 			r.Post("/resume", h.ResumeSync)
 			r.Post("/cancel", h.CancelSync)
+			r.Post("/complete", h.CompleteSync)
 		})
 	})
 }
@@ -175,6 +176,7 @@ type SyncTaskStatus struct {
 	BytesDone      int64  `json:"bytes_done"`
 	ItemsTotal     int64  `json:"items_total"`
 	ItemsDone      int64  `json:"items_done"`
+	ReplicationLag *int64 `json:"replication_lag_bytes,omitempty"`
 	Error          string `json:"error,omitempty"`
 }
 
@@ -196,6 +198,7 @@ func (h *SyncHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	for i, task := range exec.Plan.Tasks {
 		progress := 0
 		var bytesTotal, bytesDone, itemsTotal, itemsDone int64
+		var replicationLag *int64
 
 		if task.Progress != nil {
 			if task.Progress.BytesTotal > 0 {
@@ -205,6 +208,7 @@ func (h *SyncHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 			bytesDone = task.Progress.BytesDone
 			itemsTotal = task.Progress.ItemsTotal
 			itemsDone = task.Progress.ItemsDone
+			replicationLag = task.Progress.ReplicationLag
 		}
 
 		if task.Status == domainsync.SyncStatusCompleted {
@@ -222,6 +226,7 @@ func (h *SyncHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 			BytesDone:  bytesDone,
 			ItemsTotal: itemsTotal,
 			ItemsDone:  itemsDone,
+			ReplicationLag: replicationLag,
 			Error:      task.ErrorMessage,
 		}
 	}
@@ -356,6 +361,21 @@ func (h *SyncHandler) ResumeSync(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// CompleteSync ends the replication of a sync, promoting its targets once
+// they have caught up with their sources.
+func (h *SyncHandler) CompleteSync(w http.ResponseWriter, r *http.Request) {
+	syncID := chi.URLParam(r, "syncId")
+
+	if err := h.service.Complete(r.Context(), syncID); err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, r, http.StatusOK, map[string]string{
+		"status": "completed",
+	})
+}
+
 // CancelSync cancels a running sync.
 func (h *SyncHandler) CancelSync(w http.ResponseWriter, r *http.Request) {
 	syncID := chi.URLParam(r, "syncId")
@@ -36,6 +36,7 @@ const (
 	WSTypeExportError    WSMessageType = "export_error"
 	WSTypeHealthCheck    WSMessageType = "health_check"
 	WSTypeLog            WSMessageType = "log"
+	WSTypeAlert          WSMessageType = "alert"
 	WSTypePing           WSMessageType = "ping"
 	WSTypePong           WSMessageType = "pong"
 )
@@ -415,3 +416,23 @@ func HandleExportWebSocket(w http.ResponseWriter, r *http.Request) {
 	go client.writePump()
 	go client.readPump(topic)
 }
+
+// HandleAlertsWebSocket handles WebSocket connections for alert state changes
+func HandleAlertsWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+
+	client := &WebSocketClient{
+		conn: conn,
+		send: make(chan []byte, 256),
+		done: make(chan struct{}),
+	}
+
+	wsHub.Register(alertsTopic, client)
+
+	go client.writePump()
+	go client.readPump(alertsTopic)
+}
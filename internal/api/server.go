@@ -3,9 +3,11 @@ package api
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -18,6 +20,8 @@ import (
 	"github.com/homeport/homeport/internal/app/cache"
 	"github.com/homeport/homeport/internal/app/clouddeploy"
 	"github.com/homeport/homeport/internal/app/compat"
+	compataws "github.com/homeport/homeport/internal/app/compat/aws"
+	"github.com/homeport/homeport/internal/app/dns"
 	"github.com/homeport/homeport/internal/app/docker"
 	"github.com/homeport/homeport/internal/app/identity"
 	"github.com/homeport/homeport/internal/app/logs"
@@ -28,6 +32,7 @@ import (
 	apprunbook "github.com/homeport/homeport/internal/app/runbook"
 	"github.com/homeport/homeport/internal/app/secrets"
 	"github.com/homeport/homeport/internal/app/stacks"
+	"github.com/homeport/homeport/internal/app/storage"
 	appwizard "github.com/homeport/homeport/internal/app/wizard"
 	"github.com/homeport/homeport/internal/domain/authz"
 	"github.com/homeport/homeport/internal/pkg/logger"
@@ -48,6 +53,7 @@ type Server struct {
 	dockerService        *docker.Service
 	dockerHandler        *handlers.DockerHandler
 	metricsHandler       *handlers.MetricsHandler
+	alertsHandler        *handlers.AlertsHandler
 	logsHandler          *handlers.LogsHandler
 	identityService      *identity.Service
 	identityHandler      *handlers.IdentityHandler
@@ -89,11 +95,14 @@ func NewServer(cfg Config) (*Server, error) {
 	}
 
 	// Initialize DNS handler
-	dnsHandler, err := handlers.NewDNSHandler()
+	dnsHandler, err := handlers.NewDNSHandler(dns.ConfigFromEnv())
 	if err != nil {
 		logger.Warn("DNS handler not available", "error", err)
 	} else {
 		s.dnsHandler = dnsHandler
+		if err := dnsHandler.StartServer(); err != nil {
+			logger.Warn("Authoritative DNS server not available", "error", err)
+		}
 	}
 
 	// Initialize Queues handler (creates service internally)
@@ -121,11 +130,17 @@ func NewServer(cfg Config) (*Server, error) {
 	}
 
 	// Initialize Backup handler
-	backupHandler, err := handlers.NewBackupHandler(&backup.Config{})
+	backupConfig, err := backup.ConfigFromEnv()
+	if err != nil {
+		logger.Warn("Invalid backup configuration, using local backups only", "error", err)
+		backupConfig = &backup.Config{}
+	}
+	backupHandler, err := handlers.NewBackupHandler(backupConfig)
 	if err != nil {
 		logger.Warn("Backup handler not available", "error", err)
 	} else {
 		s.backupHandler = backupHandler
+		backupHandler.StartScheduler()
 	}
 
 	// Initialize Stacks handler
@@ -154,6 +169,36 @@ func NewServer(cfg Config) (*Server, error) {
 	// Initialize Sync handler
 	s.syncHandler = handlers.NewSyncHandler()
 
+	// Initialize compatibility gateway handler
+	s3Backend, err := compataws.NewS3BackendFromEnv()
+	if err != nil {
+		logger.Warn("S3 compat storage backend not available, keeping objects in memory", "error", err)
+	}
+	dynamoBackend, err := compataws.NewDynamoDBBackendFromEnv()
+	if err != nil {
+		logger.Warn("DynamoDB compat storage backend not available, keeping tables in memory", "error", err)
+	}
+	// Without its keys the gateway cannot verify signed requests, so it must
+	// not serve unsigned ones in their place.
+	var compatOptions []handlers.CompatHandlerOption
+	requireSigV4 := compatRequireSigV4(cfg.Host)
+	if accessKeys, err := compataws.NewFileAccessKeyStore(os.Getenv("HOMEPORT_COMPAT_ACCESS_KEYS_FILE")); err != nil {
+		if requireSigV4 {
+			return nil, fmt.Errorf("compat access keys are required to verify signed requests: %w", err)
+		}
+		logger.Warn("Compat access keys not available", "error", err)
+	} else {
+		compatOptions = append(compatOptions,
+			handlers.WithCompatAccessKeys(accessKeys, requireSigV4),
+			handlers.WithCompatAdminToken(os.Getenv("HOMEPORT_COMPAT_ADMIN_TOKEN")),
+		)
+	}
+	compatRegistry := compat.NewDefaultRegistry(
+		compat.WithS3Options(compataws.WithS3Backend(s3Backend)),
+		compat.WithDynamoDBOptions(compataws.WithDynamoDBBackend(dynamoBackend)),
+	)
+	s.compatHandler = handlers.NewCompatHandler(compatRegistry, compatOptions...)
+
 	// Initialize the shared post-cutover AWS operations stores. Cutover writes this
 	// projection and the operations API reads the same persisted workspace data.
 	if discoveries, err := migrate.NewStateStore(""); err != nil {
@@ -169,6 +214,27 @@ func NewServer(cfg Config) (*Server, error) {
 		if s.queuesHandler != nil {
 			drivers = append(drivers, awsoperations.NewSQSDriver(awsoperations.NewQueuesBackend(s.queuesHandler.Service())))
 		}
+		if config, configured, err := storage.ConfigFromEnv(); err != nil {
+			logger.Warn("AWS operations S3 driver not available", "error", err)
+		} else if configured {
+			if objects, err := storage.NewService(config); err != nil {
+				logger.Warn("AWS operations S3 driver not available", "error", err)
+			} else {
+				drivers = append(drivers, awsoperations.NewS3Driver(awsoperations.NewObjectStorageBackend(objects)))
+			}
+		}
+		// DynamoDB and SNS operate on the compat adapters serving the
+		// cutover targets, so both APIs see the same tables and topics.
+		if adapter, err := compatRegistry.Get("aws", "dynamodb"); err == nil {
+			if tables, ok := adapter.(*compataws.DynamoDBAdapter); ok {
+				drivers = append(drivers, awsoperations.NewDynamoDBDriver(awsoperations.NewTablesBackend(tables)))
+			}
+		}
+		if adapter, err := compatRegistry.Get("aws", "sns"); err == nil {
+			if topics, ok := adapter.(*compataws.SNSAdapter); ok {
+				drivers = append(drivers, awsoperations.NewSNSDriver(awsoperations.NewTopicsBackend(topics)))
+			}
+		}
 		var auditSink func(authz.Decision) error
 		if home, err := os.UserHomeDir(); err != nil {
 			logger.Warn("AWS operations audit log not available", "error", err)
@@ -182,6 +248,9 @@ func NewServer(cfg Config) (*Server, error) {
 	if s.cutoverHandler == nil {
 		s.cutoverHandler = handlers.NewCutoverHandler()
 	}
+	if interrupted := s.cutoverHandler.Service().Interrupted(); len(interrupted) > 0 {
+		logger.Warn("Interrupted cutovers found, resume or roll them back", "count", len(interrupted))
+	}
 
 	// Initialize Runbook handler
 	s.runbookHandler = handlers.NewRunbookHandler(apprunbook.NewService("."))
@@ -189,9 +258,6 @@ func NewServer(cfg Config) (*Server, error) {
 	// Initialize Wizard handler
 	s.wizardHandler = handlers.NewWizardHandler(appwizard.NewService("."))
 
-	// Initialize compatibility gateway handler
-	s.compatHandler = handlers.NewCompatHandler(compat.NewDefaultRegistry())
-
 	// Initialize Providers handler
 	providersSvc := providers.NewService()
 	s.providersHandler = handlers.NewProvidersHandler(providersSvc)
@@ -218,6 +284,13 @@ func NewServer(cfg Config) (*Server, error) {
 			logger.Warn("Metrics handler not available", "error", err)
 		} else {
 			s.metricsHandler = metricsHandler
+
+			alertsHandler, err := handlers.NewAlertsHandler(metricsHandler.Service())
+			if err != nil {
+				logger.Warn("Alerts handler not available", "error", err)
+			} else {
+				s.alertsHandler = alertsHandler
+			}
 		}
 
 		logsSvc, err := logs.NewService(s.dockerService)
@@ -297,10 +370,12 @@ func (s *Server) setupRoutes() {
 			r.Route("/dns/zones", func(r chi.Router) {
 				r.Get("/", s.dnsHandler.HandleListZones)
 				r.Post("/", s.dnsHandler.HandleCreateZone)
+				r.Post("/import", s.dnsHandler.HandleImportZone)
 				r.Route("/{zoneID}", func(r chi.Router) {
 					r.Get("/", s.dnsHandler.HandleGetZone)
 					r.Delete("/", s.dnsHandler.HandleDeleteZone)
 					r.Post("/validate", s.dnsHandler.HandleValidateZone)
+					r.Get("/export", s.dnsHandler.HandleExportZone)
 					r.Route("/records", func(r chi.Router) {
 						r.Get("/", s.dnsHandler.HandleListRecords)
 						r.Post("/", s.dnsHandler.HandleCreateRecord)
@@ -379,6 +454,11 @@ func (s *Server) setupRoutes() {
 			s.cutoverHandler.RegisterRoutes(r)
 		}
 
+		// Alert routes
+		if s.alertsHandler != nil {
+			s.alertsHandler.RegisterRoutes(r)
+		}
+
 		// AWS routes are intentionally available only for the post-cutover local
 		// workspace projection; no provider API clients are used here.
 		if s.awsOperationsHandler != nil {
@@ -412,6 +492,7 @@ func (s *Server) setupRoutes() {
 		r.Get("/ws/sync/{planId}", handlers.HandleSyncWebSocket)
 		r.Get("/ws/deploy/{deploymentId}", handlers.HandleDeployWebSocket)
 		r.Get("/ws/export/{bundleId}", handlers.HandleExportWebSocket)
+		r.Get("/ws/alerts", handlers.HandleAlertsWebSocket)
 
 		// Stack routes
 		r.Route("/stacks/{stackID}", func(r chi.Router) {
@@ -530,6 +611,9 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	if s.dockerHandler != nil {
 		_ = s.dockerHandler.Close()
 	}
+	if s.alertsHandler != nil {
+		_ = s.alertsHandler.Close()
+	}
 	if s.metricsHandler != nil {
 		_ = s.metricsHandler.Close()
 	}
@@ -542,6 +626,9 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	if s.backupHandler != nil {
 		_ = s.backupHandler.Close()
 	}
+	if s.dnsHandler != nil {
+		_ = s.dnsHandler.Close()
+	}
 	if s.stacksHandler != nil {
 		_ = s.stacksHandler.Close()
 	}
@@ -561,3 +648,22 @@ func (s *Server) Shutdown(ctx context.Context) error {
 func (s *Server) Router() *chi.Mux {
 	return s.router
 }
+
+// compatRequireSigV4 reports whether AWS compat requests must be signed with
+// an issued access key. HOMEPORT_COMPAT_REQUIRE_SIGV4 decides when set;
+// otherwise signing is required unless the server only listens on loopback.
+func compatRequireSigV4(host string) bool {
+	if value := os.Getenv("HOMEPORT_COMPAT_REQUIRE_SIGV4"); value != "" {
+		required, err := strconv.ParseBool(value)
+		if err != nil {
+			logger.Warn("Invalid HOMEPORT_COMPAT_REQUIRE_SIGV4, requiring signed requests", "value", value)
+			return true
+		}
+		return required
+	}
+	if host == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip == nil || !ip.IsLoopback()
+}
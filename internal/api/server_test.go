@@ -38,3 +38,27 @@ func TestServerRegistersAWSOperationsRoutes(t *testing.T) {
 		t.Fatalf("GET AWS operations workspaces status = %d, want %d", rec.Code, http.StatusOK)
 	}
 }
+
+func TestCompatRequireSigV4DefaultsToNonLoopbackHosts(t *testing.T) {
+	tests := []struct {
+		host string
+		env  string
+		want bool
+	}{
+		{host: "localhost", want: false},
+		{host: "127.0.0.1", want: false},
+		{host: "::1", want: false},
+		{host: "0.0.0.0", want: true},
+		{host: "", want: true},
+		{host: "homeport.internal", want: true},
+		{host: "0.0.0.0", env: "false", want: false},
+		{host: "localhost", env: "true", want: true},
+		{host: "localhost", env: "yes please", want: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("HOMEPORT_COMPAT_REQUIRE_SIGV4", tt.env)
+		if got := compatRequireSigV4(tt.host); got != tt.want {
+			t.Errorf("compatRequireSigV4(%q) with env %q = %v, want %v", tt.host, tt.env, got, tt.want)
+		}
+	}
+}
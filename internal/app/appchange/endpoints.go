@@ -0,0 +1,138 @@
+package appchange
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	awsHost   = regexp.MustCompile(`(?i)(?:https?://)?((?:[a-z0-9-]+\.)+)amazonaws\.com(?:\.cn)?\b`)
+	gcpHost   = regexp.MustCompile(`(?i)(?:https?://)?([a-z0-9-]+)\.googleapis\.com\b`)
+	azureHost = regexp.MustCompile(`(?i)(?:https?://)?(?:[a-z0-9-]+\.)?(servicebus|blob)\.(?:core\.)?windows\.net\b`)
+	awsRegion = regexp.MustCompile(`^[a-z]{2}(?:-gov|-iso[a-z]?)?-[a-z]+-[0-9]$`)
+	pythonOS  = regexp.MustCompile(`(?m)^\s*import\s+(?:[\w.]+\s*,\s*)*os\b`)
+)
+
+// endpointHits finds hard-coded provider endpoints. Comments are skipped in
+// source files; configuration files are read as they are. Hosts of services
+// the scanner has no mapping for are left out, since most of them (token,
+// metadata or console hosts) are not traffic HomePort takes over.
+func endpointHits(src *source) []hit {
+	var hits []hit
+	add := func(m []int, service cloudService) {
+		if service.known {
+			hits = append(hits, hit{kind: hitEndpoint, service: service, start: m[0], end: m[1], match: src.code[m[0]:m[1]]})
+		}
+	}
+	for _, m := range awsHost.FindAllStringSubmatchIndex(src.code, -1) {
+		add(m, lookupService("aws", awsHostService(strings.TrimSuffix(strings.ToLower(src.code[m[2]:m[3]]), "."))))
+	}
+	for _, m := range gcpHost.FindAllStringSubmatchIndex(src.code, -1) {
+		add(m, lookupService("gcp", strings.ToLower(src.code[m[2]:m[3]])))
+	}
+	for _, m := range azureHost.FindAllStringSubmatchIndex(src.code, -1) {
+		add(m, lookupService("azure", strings.ToLower(src.code[m[2]:m[3]])))
+	}
+	return hits
+}
+
+// awsHostService returns the service label of the labels before
+// amazonaws.com, e.g. s3 for bucket.s3.dualstack.eu-west-3.
+func awsHostService(labels string) string {
+	parts := strings.Split(labels, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		label := parts[i]
+		switch {
+		case awsRegion.MatchString(label), label == "dualstack", label == "fips", label == "api":
+			continue
+		case strings.HasPrefix(label, "s3-"):
+			return "s3"
+		default:
+			return label
+		}
+	}
+	return ""
+}
+
+// envRead returns the expression that reads the environment variable env in
+// lang. Configuration files get a ${env} reference.
+func envRead(lang language, env string) string {
+	switch lang {
+	case languageGo:
+		return `os.Getenv("` + env + `")`
+	case languagePython:
+		return `os.environ["` + env + `"]`
+	case languageJavaScript:
+		return "process.env." + env
+	case languageJava:
+		return `System.getenv("` + env + `")`
+	default:
+		return "${" + env + "}"
+	}
+}
+
+// needsOSImport reports whether the env read of src relies on an os import
+// the file does not have yet.
+func needsOSImport(src *source) bool {
+	switch src.lang {
+	case languageGo:
+		return !strings.Contains(src.code, `"os"`)
+	case languagePython:
+		return !pythonOS.MatchString(src.code)
+	default:
+		return false
+	}
+}
+
+// endpointEdit returns the edit that makes the endpoint of h come from env.
+// In configuration files the endpoint becomes a ${env} reference. In source
+// files the string literal holding it is split around the language's env
+// read, e.g. "https://sqs.eu-west-3.amazonaws.com/123/jobs" becomes
+// process.env.AWS_ENDPOINT_URL_SQS + "/123/jobs". JavaScript template
+// literals get a ${...} substitution instead. It returns false when the
+// endpoint is not in a literal that can be split this way.
+func endpointEdit(src *source, h hit, env string) (edit, bool) {
+	read := envRead(src.lang, env)
+	if src.lang == languageOther {
+		return edit{start: h.start, end: h.end, text: read}, true
+	}
+	lit, ok := src.literalAt(h.start)
+	if !ok || h.end > lit.end {
+		return edit{}, false
+	}
+	text := src.text
+	delimiter := lit.delimiter(text)
+	quotes := text[lit.start : lit.start+delimiter]
+	if lit.end-lit.start < 2*delimiter || text[lit.end-delimiter:lit.end] != quotes || h.end > lit.end-delimiter {
+		return edit{}, false // unterminated
+	}
+	switch {
+	case src.lang == languageJavaScript && quotes == "`":
+		return edit{start: h.start, end: h.end, text: "${" + read + "}"}, true
+	case src.lang == languageJava && delimiter == 3:
+		return edit{}, false // text blocks cannot be reopened on the same line
+	}
+
+	// Python string prefixes (f, r, u) are repeated on each piece; bytes
+	// cannot be concatenated with the str the env read returns.
+	start, open := lit.start, quotes
+	if src.lang == languagePython {
+		for start > 0 && lit.start-start < 2 && strings.IndexByte("rRbBuUfF", text[start-1]) >= 0 {
+			start--
+		}
+		if strings.ContainsAny(text[start:lit.start], "bB") {
+			return edit{}, false
+		}
+		open = text[start:lit.start] + quotes
+	}
+
+	parts := make([]string, 0, 3)
+	if before := text[lit.start+delimiter : h.start]; before != "" {
+		parts = append(parts, open+before+quotes)
+	}
+	parts = append(parts, read)
+	if after := text[h.end : lit.end-delimiter]; after != "" {
+		parts = append(parts, open+after+quotes)
+	}
+	return edit{start: start, end: lit.end, text: strings.Join(parts, " + ")}, true
+}
@@ -0,0 +1,190 @@
+package appchange
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type language int
+
+const (
+	languageOther language = iota
+	languageGo
+	languagePython
+	languageJavaScript
+	languageJava
+)
+
+func languageOf(path string) language {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		return languageGo
+	case ".py":
+		return languagePython
+	case ".js", ".mjs", ".cjs", ".jsx", ".ts", ".mts", ".cts", ".tsx":
+		return languageJavaScript
+	case ".java":
+		return languageJava
+	default:
+		return languageOther
+	}
+}
+
+// source is a file prepared for scanning. code blanks comments, and skeleton
+// also blanks the contents of string literals, so matching parentheses are
+// never thrown off by text. Both keep the byte offsets and newlines of text.
+// literals holds the spans of the string literals, quotes included.
+type source struct {
+	path     string
+	lang     language
+	text     string
+	code     string
+	skeleton string
+	literals []span
+}
+
+// span is the byte range [start, end) of text.
+type span struct {
+	start, end int
+}
+
+func newSource(path string, lang language, text string) *source {
+	src := &source{path: path, lang: lang, text: text}
+	if lang == languageOther {
+		src.code, src.skeleton = text, text
+		return src
+	}
+	src.code, src.skeleton, src.literals = lex(lang, text)
+	return src
+}
+
+// lex blanks comments and string contents of a source file and returns the
+// spans of its string literals. Regular expression literals in JavaScript
+// are read as code.
+func lex(lang language, text string) (string, string, []span) {
+	var literals []span
+	code := []byte(text)
+	skeleton := []byte(text)
+	blank := func(b []byte, from, to int) {
+		for i := from; i < to && i < len(b); i++ {
+			if b[i] != '\n' {
+				b[i] = ' '
+			}
+		}
+	}
+	hashComments := lang == languagePython
+	for i := 0; i < len(text); {
+		switch {
+		case hashComments && text[i] == '#':
+			end := lineEnd(text, i)
+			blank(code, i, end)
+			blank(skeleton, i, end)
+			i = end
+		case !hashComments && strings.HasPrefix(text[i:], "//"):
+			end := lineEnd(text, i)
+			blank(code, i, end)
+			blank(skeleton, i, end)
+			i = end
+		case !hashComments && strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += i + 4
+			}
+			blank(code, i, end)
+			blank(skeleton, i, end)
+			i = end
+		case text[i] == '"' || text[i] == '\'' || text[i] == '`':
+			literal := span{start: i, end: stringEnd(lang, text, i)}
+			delimiter := literal.delimiter(text)
+			blank(skeleton, i+delimiter, literal.end-delimiter)
+			literals = append(literals, literal)
+			i = literal.end
+		default:
+			i++
+		}
+	}
+	return string(code), string(skeleton), literals
+}
+
+// delimiter returns the length of the quotes around the literal sp of text.
+func (sp span) delimiter(text string) int {
+	if sp.end-sp.start >= 6 && (strings.HasPrefix(text[sp.start:], `"""`) || strings.HasPrefix(text[sp.start:], "'''")) {
+		return 3
+	}
+	return 1
+}
+
+func lineEnd(text string, from int) int {
+	if end := strings.IndexByte(text[from:], '\n'); end >= 0 {
+		return from + end
+	}
+	return len(text)
+}
+
+// stringEnd returns the offset just past the literal opening at from.
+func stringEnd(lang language, text string, from int) int {
+	quote := text[from]
+	triple := string([]byte{quote, quote, quote})
+	if (lang == languagePython || (lang == languageJava && quote == '"')) && strings.HasPrefix(text[from:], triple) {
+		for i := from + 3; i < len(text); i++ {
+			if text[i] == '\\' {
+				i++
+				continue
+			}
+			if strings.HasPrefix(text[i:], triple) {
+				return i + 3
+			}
+		}
+		return len(text)
+	}
+	// Go raw strings and JavaScript templates span lines; other literals end
+	// at the line break when unterminated.
+	multiline := quote == '`' && (lang == languageGo || lang == languageJavaScript)
+	raw := quote == '`' && lang == languageGo
+	for i := from + 1; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && !raw:
+			i++
+		case text[i] == quote:
+			return i + 1
+		case text[i] == '\n' && !multiline:
+			return i
+		}
+	}
+	return len(text)
+}
+
+// closingParen returns the offset just past the parenthesis that closes the
+// one at open, or the end of the line when it is unbalanced.
+func (s *source) closingParen(open int) int {
+	depth := 0
+	for i := open; i < len(s.skeleton); i++ {
+		switch s.skeleton[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return lineEnd(s.text, open)
+}
+
+// literalAt returns the string literal that holds offset.
+func (s *source) literalAt(offset int) (span, bool) {
+	i := sort.Search(len(s.literals), func(i int) bool { return s.literals[i].end > offset })
+	if i < len(s.literals) && s.literals[i].start < offset {
+		return s.literals[i], true
+	}
+	return span{}, false
+}
+
+// line returns the 1-based line of offset.
+func (s *source) line(offset int) int {
+	return strings.Count(s.text[:offset], "\n") + 1
+}
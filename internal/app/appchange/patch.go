@@ -0,0 +1,86 @@
+package appchange
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const diffContext = 3
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+// patchFile applies edits to the text of src and renders the result as a
+// unified diff, or returns "" when there is nothing to change.
+func patchFile(src *source, edits []edit) string {
+	if len(edits) == 0 {
+		return ""
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	patched := src.text
+	for _, e := range edits {
+		patched = patched[:e.start] + e.text + patched[e.end:]
+	}
+	return unifiedDiff(src.path, src.text, patched)
+}
+
+// unifiedDiff compares two versions of a file that only differ by line
+// substitutions, which is all generated patches do.
+func unifiedDiff(name, before, after string) string {
+	oldLines, newLines := splitLines(before), splitLines(after)
+	if len(oldLines) != len(newLines) {
+		return ""
+	}
+	var changed []int
+	for i := range oldLines {
+		if oldLines[i] != newLines[i] {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+	for i := 0; i < len(changed); {
+		start := max(changed[i]-diffContext, 0)
+		end := min(changed[i]+diffContext+1, len(oldLines))
+		j := i + 1
+		for j < len(changed) && changed[j]-diffContext <= end {
+			end = min(changed[j]+diffContext+1, len(oldLines))
+			j++
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", start+1, end-start, start+1, end-start)
+		for line := start; line < end; line++ {
+			if oldLines[line] == newLines[line] {
+				writeDiffLine(&b, ' ', oldLines[line])
+				continue
+			}
+			writeDiffLine(&b, '-', oldLines[line])
+			writeDiffLine(&b, '+', newLines[line])
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// splitLines splits text after each newline, keeping the newlines so a last
+// line without one can be told apart.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writeDiffLine(b *strings.Builder, prefix byte, line string) {
+	b.WriteByte(prefix)
+	b.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		b.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,391 @@
+package appchange
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type hitKind int
+
+const (
+	hitClient hitKind = iota
+	hitEndpoint
+)
+
+// hit is one cloud usage found in a source. start and end are byte offsets;
+// match is the endpoint text endpoint hits replace.
+type hit struct {
+	kind       hitKind
+	service    cloudService
+	start, end int
+	match      string
+}
+
+// clientHits finds SDK client construction in the languages the scanner
+// understands. Constructors are only recognised for names the file imports
+// from a provider SDK, so look-alike names elsewhere are not reported.
+func clientHits(src *source) []hit {
+	switch src.lang {
+	case languageGo:
+		return goClientHits(src)
+	case languagePython:
+		return pythonClientHits(src)
+	case languageJavaScript:
+		return javaScriptClientHits(src)
+	case languageJava:
+		return javaClientHits(src)
+	default:
+		return nil
+	}
+}
+
+// goSDKService maps a Go import path to the service its package serves.
+func goSDKService(importPath string) (cloudService, bool) {
+	for _, prefix := range []string{"github.com/aws/aws-sdk-go-v2/service/", "github.com/aws/aws-sdk-go/service/"} {
+		if rest, ok := strings.CutPrefix(importPath, prefix); ok {
+			return lookupService("aws", strings.Split(rest, "/")[0]), true
+		}
+	}
+	if rest, ok := strings.CutPrefix(importPath, "cloud.google.com/go/"); ok {
+		return lookupService("gcp", strings.Split(rest, "/")[0]), true
+	}
+	if rest, ok := strings.CutPrefix(importPath, "github.com/Azure/azure-sdk-for-go/sdk/"); ok {
+		parts := strings.Split(rest, "/")
+		if len(parts) >= 2 && strings.HasPrefix(parts[1], "az") {
+			return lookupService("azure", strings.TrimPrefix(parts[1], "az")), true
+		}
+	}
+	return cloudService{}, false
+}
+
+var goVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+func goClientHits(src *source) []hit {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, src.path, src.text, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	packages := map[string]cloudService{}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		service, ok := goSDKService(importPath)
+		if !ok {
+			continue
+		}
+		name := path.Base(importPath)
+		if goVersionSuffix.MatchString(name) {
+			name = path.Base(path.Dir(importPath))
+		}
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		packages[name] = service
+	}
+	if len(packages) == 0 {
+		return nil
+	}
+	var hits []hit
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		service, ok := packages[pkg.Name]
+		if !ok || !goConstructor(selector.Sel.Name) {
+			return true
+		}
+		hits = append(hits, hit{kind: hitClient, service: service, start: fset.Position(call.Pos()).Offset, end: fset.Position(call.End()).Offset})
+		return true
+	})
+	return hits
+}
+
+func goConstructor(name string) bool {
+	return name == "New" || name == "NewFromConfig" || strings.HasPrefix(name, "NewClient") ||
+		strings.HasPrefix(name, "NewPublisherClient") || strings.HasPrefix(name, "NewSubscriberClient")
+}
+
+var (
+	pythonBoto3Import  = regexp.MustCompile(`(?m)^\s*(?:import\s+(?:aio)?boto3\b|from\s+(?:aio)?boto3\b)`)
+	pythonBoto3Client  = regexp.MustCompile(`(?:\b[A-Za-z_][A-Za-z0-9_]*|\))\.(?:client|resource)\(\s*(?:service_name\s*=\s*)?['"]([A-Za-z0-9-]+)['"]`)
+	pythonFromImport   = regexp.MustCompile(`(?m)^\s*from\s+([A-Za-z0-9_.]+)\s+import\s+(?:\(([^)]*)\)|([^\n]+))`)
+	pythonModuleImport = regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_.]+)(?:\s+as\s+([A-Za-z0-9_]+))?`)
+)
+
+// pythonSDKService maps a fully qualified Python module or class to its
+// service.
+func pythonSDKService(name string) (cloudService, bool) {
+	switch {
+	case strings.HasPrefix(name, "google.cloud."):
+		return lookupService("gcp", strings.Split(strings.TrimPrefix(name, "google.cloud."), ".")[0]), true
+	case strings.HasPrefix(name, "azure."):
+		parts := strings.Split(strings.TrimPrefix(name, "azure."), ".")
+		if parts[0] == "storage" && len(parts) > 1 {
+			return lookupService("azure", parts[1]), true
+		}
+		return lookupService("azure", parts[0]), true
+	}
+	return cloudService{}, false
+}
+
+func pythonClientHits(src *source) []hit {
+	var hits []hit
+	if pythonBoto3Import.MatchString(src.code) {
+		for _, m := range pythonBoto3Client.FindAllStringSubmatchIndex(src.code, -1) {
+			open := strings.IndexByte(src.code[m[0]:m[1]], '(') + m[0]
+			hits = append(hits, hit{kind: hitClient, service: lookupService("aws", src.code[m[2]:m[3]]), start: m[0], end: src.callEnd(open)})
+		}
+	}
+	// Bound names are modules (storage.Client()) or client classes
+	// (ServiceBusClient(...)); both are followed by a call.
+	modules := map[string]cloudService{}
+	classes := map[string]cloudService{}
+	for _, m := range pythonFromImport.FindAllStringSubmatch(src.code, -1) {
+		for _, item := range strings.Split(m[2]+m[3], ",") {
+			fields := strings.Fields(item)
+			if len(fields) == 0 {
+				continue
+			}
+			name, bound := fields[0], fields[len(fields)-1]
+			if service, ok := pythonSDKService(m[1] + "." + name); ok {
+				if strings.HasSuffix(name, "Client") {
+					classes[bound] = service
+				} else {
+					modules[bound] = service
+				}
+			}
+		}
+	}
+	for _, m := range pythonModuleImport.FindAllStringSubmatch(src.code, -1) {
+		if service, ok := pythonSDKService(m[1]); ok {
+			bound := m[1]
+			if m[2] != "" {
+				bound = m[2]
+			}
+			modules[bound] = service
+		}
+	}
+	for name, service := range modules {
+		pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\.(?:[A-Za-z0-9_]+\.)*(?:[A-Z][A-Za-z0-9_]*)?Client(?:\.from_[a-z_]+)?\s*\(`)
+		hits = append(hits, callHits(src, pattern, service)...)
+	}
+	for name, service := range classes {
+		pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `(?:\.from_[a-z_]+)?\s*\(`)
+		hits = append(hits, callHits(src, pattern, service)...)
+	}
+	return hits
+}
+
+var (
+	jsImportFrom      = regexp.MustCompile(`import\s+(?:type\s+)?([^;]*?)\s+from\s+['"]([^'"]+)['"]`)
+	jsRequire         = regexp.MustCompile(`(?:const|let|var)\s+(\{[^}]*\}|[A-Za-z_$][A-Za-z0-9_$]*)\s*=\s*require\(\s*['"]([^'"]+)['"]\s*\)`)
+	jsAWSNamespaceNew = regexp.MustCompile(`new\s+([A-Za-z_$][A-Za-z0-9_$]*)\.([A-Z][A-Za-z0-9]*)(?:\.DocumentClient)?\s*\(`)
+)
+
+// javaScriptSDKService maps an npm module to its service. The v2 aws-sdk
+// package serves every service and reports false with aws set.
+func javaScriptSDKService(module string) (service cloudService, ok bool, aws bool) {
+	switch {
+	case module == "aws-sdk":
+		return cloudService{}, false, true
+	case module == "@aws-sdk/lib-dynamodb":
+		return lookupService("aws", "dynamodb"), true, false
+	case strings.HasPrefix(module, "@aws-sdk/client-"):
+		return lookupService("aws", strings.TrimPrefix(module, "@aws-sdk/client-")), true, false
+	case strings.HasPrefix(module, "@google-cloud/"):
+		return lookupService("gcp", strings.TrimPrefix(module, "@google-cloud/")), true, false
+	case strings.HasPrefix(module, "@azure/"):
+		name := strings.TrimPrefix(module, "@azure/")
+		return lookupService("azure", strings.TrimPrefix(name, "storage-")), true, false
+	}
+	return cloudService{}, false, false
+}
+
+// javaScriptBindings returns the local names an import or require clause
+// binds, and whether the clause binds a whole module namespace.
+func javaScriptBindings(clause string) (names []string, namespace []string) {
+	clause = strings.TrimSpace(clause)
+	if braces := strings.Index(clause, "{"); braces >= 0 {
+		end := strings.Index(clause, "}")
+		if end < braces {
+			end = len(clause)
+		}
+		for _, item := range strings.Split(clause[braces+1:end], ",") {
+			fields := strings.Fields(strings.ReplaceAll(item, ":", " as "))
+			if len(fields) > 0 {
+				names = append(names, fields[len(fields)-1])
+			}
+		}
+		clause = clause[:braces]
+	}
+	for _, item := range strings.Split(clause, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 {
+			continue
+		}
+		namespace = append(namespace, fields[len(fields)-1])
+	}
+	return names, namespace
+}
+
+func javaScriptClientHits(src *source) []hit {
+	named := map[string]cloudService{}
+	namespaces := map[string]cloudService{}
+	awsNamespaces := map[string]bool{}
+	bind := func(clause, module string) {
+		service, ok, aws := javaScriptSDKService(module)
+		names, namespace := javaScriptBindings(clause)
+		for _, name := range namespace {
+			switch {
+			case aws:
+				awsNamespaces[name] = true
+			case ok:
+				namespaces[name] = service
+			}
+		}
+		for _, name := range names {
+			switch {
+			case aws:
+				named[name] = lookupService("aws", name)
+			case ok:
+				named[name] = service
+			}
+		}
+	}
+	for _, m := range jsImportFrom.FindAllStringSubmatch(src.code, -1) {
+		bind(m[1], m[2])
+	}
+	for _, m := range jsRequire.FindAllStringSubmatch(src.code, -1) {
+		bind(m[1], m[2])
+	}
+	var hits []hit
+	for name, service := range named {
+		if !javaScriptClientName(name) {
+			continue
+		}
+		pattern := regexp.MustCompile(`(?:new\s+\b` + regexp.QuoteMeta(name) + `\s*\(|\b` + regexp.QuoteMeta(name) + `\.from[A-Za-z]*\s*\()`)
+		hits = append(hits, callHits(src, pattern, service)...)
+	}
+	for name, service := range namespaces {
+		pattern := regexp.MustCompile(`new\s+\b` + regexp.QuoteMeta(name) + `\.[A-Z][A-Za-z0-9]*\s*\(`)
+		hits = append(hits, callHits(src, pattern, service)...)
+	}
+	for _, m := range jsAWSNamespaceNew.FindAllStringSubmatchIndex(src.code, -1) {
+		if !awsNamespaces[src.code[m[2]:m[3]]] {
+			continue
+		}
+		open := m[1] - 1
+		hits = append(hits, hit{kind: hitClient, service: lookupService("aws", src.code[m[4]:m[5]]), start: m[0], end: src.callEnd(open)})
+	}
+	return hits
+}
+
+// javaScriptClientName accepts v3 client classes (S3Client), v3 aggregated
+// clients (S3) and provider entry points (Storage, PubSub, ServiceBusClient).
+func javaScriptClientName(name string) bool {
+	if name == "" || name[0] < 'A' || name[0] > 'Z' {
+		return false
+	}
+	return !strings.HasSuffix(name, "Command") && !strings.HasSuffix(name, "Input") && !strings.HasSuffix(name, "Output") && !strings.HasSuffix(name, "Exception")
+}
+
+var javaImport = regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_.]+)\.([A-Z][A-Za-z0-9_]*)\s*;`)
+
+// javaSDKService maps a Java package to its service.
+func javaSDKService(pkg string) (cloudService, bool) {
+	prefixes := []struct{ prefix, provider string }{
+		{"software.amazon.awssdk.services.", "aws"},
+		{"com.amazonaws.services.", "aws"},
+		{"com.google.cloud.", "gcp"},
+		{"com.azure.messaging.", "azure"},
+		{"com.azure.storage.", "azure"},
+	}
+	for _, p := range prefixes {
+		if rest, ok := strings.CutPrefix(pkg, p.prefix); ok {
+			return lookupService(p.provider, strings.Split(rest, ".")[0]), true
+		}
+	}
+	return cloudService{}, false
+}
+
+func javaClientHits(src *source) []hit {
+	var hits []hit
+	for _, m := range javaImport.FindAllStringSubmatch(src.code, -1) {
+		service, ok := javaSDKService(m[1])
+		if !ok {
+			continue
+		}
+		class := m[2]
+		var pattern *regexp.Regexp
+		switch {
+		case strings.HasSuffix(class, "ClientBuilder"):
+			pattern = regexp.MustCompile(`(?:new\s+\b` + class + `\s*\(|\b` + class + `\.(?:standard|defaultClient)\s*\()`)
+		case strings.HasSuffix(class, "Client"), class == "StorageOptions", class == "Publisher", class == "Subscriber":
+			pattern = regexp.MustCompile(`\b` + class + `\.(?:builder|create|newBuilder|getDefaultInstance|newFactory)\s*\(`)
+		default:
+			continue
+		}
+		hits = append(hits, callHits(src, pattern, service)...)
+	}
+	return hits
+}
+
+// callHits reports every match of pattern, which must end at an opening
+// parenthesis, spanning to the parenthesis that closes the call.
+func callHits(src *source, pattern *regexp.Regexp, service cloudService) []hit {
+	var hits []hit
+	for _, m := range pattern.FindAllStringIndex(src.code, -1) {
+		hits = append(hits, hit{kind: hitClient, service: service, start: m[0], end: src.callEnd(m[1] - 1)})
+	}
+	return hits
+}
+
+// callEnd returns the offset just past the call whose parenthesis opens at
+// open, including method calls chained to it, so a builder reports every
+// line it spans.
+func (s *source) callEnd(open int) int {
+	end := s.closingParen(open)
+	for {
+		i := skipSpace(s.skeleton, end)
+		if i >= len(s.skeleton) || s.skeleton[i] != '.' {
+			return end
+		}
+		j := i + 1
+		for j < len(s.skeleton) && isIdentByte(s.skeleton[j]) {
+			j++
+		}
+		k := skipSpace(s.skeleton, j)
+		if j == i+1 || k >= len(s.skeleton) || s.skeleton[k] != '(' {
+			return end
+		}
+		end = s.closingParen(k)
+	}
+}
+
+func skipSpace(text string, from int) int {
+	for from < len(text) && strings.IndexByte(" \t\r\n", text[from]) >= 0 {
+		from++
+	}
+	return from
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
@@ -1,38 +1,168 @@
 package appchange
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strings"
+	"sort"
 
+	"github.com/homeport/homeport/internal/app/compat"
 	domain "github.com/homeport/homeport/internal/domain/appchange"
 )
 
-type Service struct{}
+const (
+	maxScanFileSize = 2 << 20
+	// binarySniffSize is how much of a file is checked for NUL bytes, as git
+	// does before treating it as binary.
+	binarySniffSize = 8000
+)
+
+type Service struct {
+	registry *compat.Registry
+}
 
-func NewService() *Service { return &Service{} }
+// NewService maps findings to the adapters of the default compat registry.
+func NewService() *Service { return NewServiceWithRegistry(compat.NewDefaultRegistry()) }
+
+// NewServiceWithRegistry maps findings to the adapters of registry.
+func NewServiceWithRegistry(registry *compat.Registry) *Service {
+	return &Service{registry: registry}
+}
 
+// ScanPath reports the cloud SDK clients and hard-coded provider endpoints of
+// the tree at root, with a patch for every file that has generated-patch
+// changes. Paths ignored by .gitignore, .git directories, binaries and files
+// over 2 MiB are skipped. Reported paths are relative to root.
 func (s *Service) ScanPath(root string) (domain.Report, error) {
 	report := domain.Report{}
+	ignore := &gitignore{}
 	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
-		if err != nil || entry.IsDir() {
+		if err != nil {
 			return err
 		}
-		data, err := os.ReadFile(path)
+		rel, err := filepath.Rel(root, path)
 		if err != nil {
 			return err
 		}
-		text := string(data)
-		if strings.Contains(text, "AWS_S3_ENDPOINT") || strings.Contains(text, "s3.amazonaws.com") {
-			report.Changes = append(report.Changes, domain.Change{Service: "S3", Mode: domain.ModeAdapter, File: path, Reason: "S3 endpoint can be redirected to MinIO", AdapterURL: "http://minio:9000"})
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if rel == "." {
+				return ignore.load(root, "")
+			}
+			if entry.Name() == ".git" || ignore.ignored(rel, true) {
+				return filepath.SkipDir
+			}
+			return ignore.load(root, rel)
+		}
+		if rel == "." {
+			rel = filepath.Base(path)
 		}
-		if strings.Contains(text, "storage.googleapis.com") {
-			report.Changes = append(report.Changes, domain.Change{Service: "Cloud Storage", Mode: domain.ModeGeneratedPatch, File: path, Search: "storage.googleapis.com", Replace: "${HOMEPORT_STORAGE_ENDPOINT}", Reason: "Native GCS endpoint must point to the HomePort storage adapter", ValidationCmd: "grep -R HOMEPORT_STORAGE_ENDPOINT ."})
+		if !entry.Type().IsRegular() || ignore.ignored(rel, false) {
+			return nil
 		}
-		if strings.Contains(text, "servicebus.windows.net") {
-			report.Changes = append(report.Changes, domain.Change{Service: "Service Bus", Mode: domain.ModeGeneratedPatch, File: path, Search: "servicebus.windows.net", Replace: "${HOMEPORT_SERVICEBUS_ENDPOINT}", Reason: "Azure Service Bus SDK endpoint must point to the HomePort adapter", ValidationCmd: "grep -R HOMEPORT_SERVICEBUS_ENDPOINT ."})
+		text, ok, err := readText(path)
+		if err != nil || !ok {
+			return err
+		}
+		changes, patch := s.scanFile(newSource(rel, languageOf(path), text))
+		report.Changes = append(report.Changes, changes...)
+		if patch != "" {
+			report.Patches = append(report.Patches, domain.Patch{File: rel, Diff: patch})
 		}
 		return nil
 	})
 	return report, err
 }
+
+// readText returns the content of a text file, or false for binaries and
+// files too large to scan.
+func readText(path string) (string, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer func() { _ = file.Close() }()
+	data, err := io.ReadAll(io.LimitReader(file, maxScanFileSize+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(data) > maxScanFileSize || bytes.IndexByte(data[:min(len(data), binarySniffSize)], 0) >= 0 {
+		return "", false, nil
+	}
+	return string(data), true, nil
+}
+
+func (s *Service) scanFile(src *source) ([]domain.Change, string) {
+	hits := append(clientHits(src), endpointHits(src)...)
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].start < hits[j].start })
+	var changes []domain.Change
+	var edits []edit
+	seen := map[domain.Change]bool{}
+	for _, h := range hits {
+		change, e := s.change(src, h)
+		if change.Mode == domain.ModeGeneratedPatch {
+			if len(edits) > 0 && e.start < edits[len(edits)-1].end {
+				// A second endpoint in a literal that is already split
+				change.Mode = domain.ModeManualReview
+				change.Search, change.Replace = "", ""
+			} else {
+				edits = append(edits, e)
+			}
+		}
+		if !seen[change] {
+			seen[change] = true
+			changes = append(changes, change)
+		}
+	}
+	return changes, patchFile(src, edits)
+}
+
+// change maps a hit to the adapter or native replacement that takes over its
+// service. Endpoints are patched to read the replacement's variable, and the
+// patch edit is returned with the change; clients need no change when their
+// SDK reads that variable itself.
+func (s *Service) change(src *source, h hit) (domain.Change, edit) {
+	change := domain.Change{
+		Service:   h.service.name,
+		File:      src.path,
+		StartLine: src.line(h.start),
+		EndLine:   src.line(max(h.start, h.end-1)),
+	}
+	target, ok := s.replacementFor(h.service)
+	destination := "compat adapter"
+	if target.native {
+		destination = "native replacement"
+	}
+	switch {
+	case !ok:
+		change.Mode = domain.ModeManualReview
+		change.Reason = fmt.Sprintf("No compat adapter or native replacement is registered for %s", h.service.name)
+	case h.kind == hitEndpoint:
+		e, ok := endpointEdit(src, h, target.env)
+		if !ok {
+			change.Mode = domain.ModeManualReview
+			change.Reason = fmt.Sprintf("Hard-coded %s endpoint must be read from %s to reach the HomePort %s", h.service.name, target.env, destination)
+			break
+		}
+		change.Mode = domain.ModeGeneratedPatch
+		change.Search = src.text[e.start:e.end]
+		change.Replace = e.text
+		change.Reason = fmt.Sprintf("Hard-coded %s endpoint must point to the HomePort %s", h.service.name, destination)
+		if needsOSImport(src) {
+			change.Reason += "; the file also needs to import os"
+		}
+		change.ValidationCmd = "grep -R " + target.env + " ."
+		return change, e
+	case target.sdkNative:
+		change.Mode = domain.ModeAdapter
+		change.Reason = fmt.Sprintf("%s client reads %s, which redirects it to the HomePort %s", h.service.name, target.env, destination)
+		change.AdapterURL = target.url
+	default:
+		change.Mode = domain.ModeManualReview
+		change.Reason = fmt.Sprintf("%s client must be built with the endpoint from %s to reach the HomePort %s", h.service.name, target.env, destination)
+		change.AdapterURL = target.url
+	}
+	return change, edit{}
+}
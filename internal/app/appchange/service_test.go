@@ -3,6 +3,7 @@ package appchange
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	domain "github.com/homeport/homeport/internal/domain/appchange"
@@ -42,3 +43,197 @@ func TestScanPathDetectsMultipleChangesInOneFile(t *testing.T) {
 		t.Fatalf("changes = %#v", report.Changes)
 	}
 }
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestScanPathDetectsSDKClientsPerLanguage(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.go": `package main
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	bus "github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+)
+
+// s3.NewFromConfig(cfg) in a comment is not a client.
+func clients() {
+	_ = s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+	_, _ = bus.NewClientFromConnectionString(conn, nil)
+}
+`,
+		"worker.py": `import boto3
+from google.cloud import storage
+
+queue = boto3.client("sqs")
+bucket = storage.Client()
+`,
+		"index.ts": `import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { Client } from "pg";
+
+const db = new DynamoDBClient({ region: "eu-west-3" });
+const pg = new Client();
+`,
+		"App.java": `import software.amazon.awssdk.services.sns.SnsClient;
+import software.amazon.awssdk.services.sns.model.PublishRequest;
+
+class App {
+    SnsClient sns = SnsClient.builder()
+        .region(Region.EU_WEST_3)
+        .build();
+    PublishRequest request = PublishRequest.builder().build();
+}
+`,
+	})
+
+	report, err := NewService().ScanPath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		file, service string
+		mode          domain.Mode
+		start, end    int
+	}{
+		{"App.java", "SNS", domain.ModeAdapter, 5, 7},
+		{"index.ts", "DynamoDB", domain.ModeAdapter, 4, 4},
+		{"main.go", "S3", domain.ModeAdapter, 10, 12},
+		{"main.go", "Service Bus", domain.ModeManualReview, 13, 13},
+		{"worker.py", "SQS", domain.ModeAdapter, 4, 4},
+		{"worker.py", "Cloud Storage", domain.ModeManualReview, 5, 5},
+	}
+	if len(report.Changes) != len(want) {
+		t.Fatalf("changes = %#v, want %d", report.Changes, len(want))
+	}
+	for i, w := range want {
+		got := report.Changes[i]
+		if got.File != w.file || got.Service != w.service || got.Mode != w.mode || got.StartLine != w.start || got.EndLine != w.end {
+			t.Errorf("change %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if got := report.Changes[2].AdapterURL; got != "http://homeport:8080/api/v1/compat/aws/s3" {
+		t.Errorf("S3 adapter URL = %q", got)
+	}
+}
+
+func TestScanPathGeneratesPatchesForEndpoints(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config/app.env": "REGION=eu-west-3\nQUEUE_URL=https://sqs.eu-west-3.amazonaws.com/123/jobs\nDEBUG=false\n",
+		"client.js":      "// docs: https://sqs.eu-west-3.amazonaws.com\nconst url = \"https://sqs.eu-west-3.amazonaws.com/123/jobs\";\n",
+	})
+
+	report, err := NewService().ScanPath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 2 || len(report.Patches) != 2 {
+		t.Fatalf("report = %#v, want one change and patch per file", report)
+	}
+	change := report.Changes[1]
+	if change.File != "config/app.env" || change.Mode != domain.ModeGeneratedPatch || change.StartLine != 2 ||
+		change.Search != "https://sqs.eu-west-3.amazonaws.com" || change.Replace != "${AWS_ENDPOINT_URL_SQS}" {
+		t.Fatalf("change = %+v", change)
+	}
+	wantDiff := `--- a/config/app.env
++++ b/config/app.env
+@@ -1,3 +1,3 @@
+ REGION=eu-west-3
+-QUEUE_URL=https://sqs.eu-west-3.amazonaws.com/123/jobs
++QUEUE_URL=${AWS_ENDPOINT_URL_SQS}/123/jobs
+ DEBUG=false
+`
+	if report.Patches[1].File != "config/app.env" || report.Patches[1].Diff != wantDiff {
+		t.Fatalf("patch = %+v, want\n%s", report.Patches[1], wantDiff)
+	}
+	if report.Changes[0].StartLine != 2 || strings.Contains(report.Patches[0].Diff, "-// docs") {
+		t.Fatalf("comment endpoint was reported: %+v\n%s", report.Changes[0], report.Patches[0].Diff)
+	}
+	if change := report.Changes[0]; change.Search != `"https://sqs.eu-west-3.amazonaws.com/123/jobs"` ||
+		change.Replace != `process.env.AWS_ENDPOINT_URL_SQS + "/123/jobs"` {
+		t.Fatalf("client.js change = %+v", change)
+	}
+	if !strings.Contains(report.Patches[0].Diff, "+const url = process.env.AWS_ENDPOINT_URL_SQS + \"/123/jobs\";\n") {
+		t.Fatalf("client.js patch =\n%s", report.Patches[0].Diff)
+	}
+}
+
+func TestScanPathPatchesEndpointsWithEnvReadsPerLanguage(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.go":   "package main\n\nimport \"os\"\n\nvar queue = \"https://sqs.eu-west-3.amazonaws.com/123/jobs\"\n",
+		"worker.py": "import os\nQUEUE = f\"https://sqs.eu-west-3.amazonaws.com/{account}/jobs\"\n",
+		"raw.py":    "QUEUE = b\"https://sqs.eu-west-3.amazonaws.com/123/jobs\"\n",
+		"App.java":  "class App {\n  String queue = \"https://sqs.eu-west-3.amazonaws.com\";\n}\n",
+		"app.ts":    "const url = `https://sqs.eu-west-3.amazonaws.com/${id}/jobs`;\n",
+		"tool.go":   "package main\n\nvar queue = `https://sqs.eu-west-3.amazonaws.com`\n",
+	})
+
+	report, err := NewService().ScanPath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		mode    domain.Mode
+		replace string
+		osHint  bool
+	}{
+		"main.go":   {domain.ModeGeneratedPatch, `os.Getenv("AWS_ENDPOINT_URL_SQS") + "/123/jobs"`, false},
+		"worker.py": {domain.ModeGeneratedPatch, `os.environ["AWS_ENDPOINT_URL_SQS"] + f"/{account}/jobs"`, false},
+		"raw.py":    {domain.ModeManualReview, "", false},
+		"App.java":  {domain.ModeGeneratedPatch, `System.getenv("AWS_ENDPOINT_URL_SQS")`, false},
+		"app.ts":    {domain.ModeGeneratedPatch, "${process.env.AWS_ENDPOINT_URL_SQS}", false},
+		"tool.go":   {domain.ModeGeneratedPatch, `os.Getenv("AWS_ENDPOINT_URL_SQS")`, true},
+	}
+	if len(report.Changes) != len(tests) {
+		t.Fatalf("changes = %+v, want one per file", report.Changes)
+	}
+	for _, change := range report.Changes {
+		want := tests[change.File]
+		if change.Mode != want.mode || change.Replace != want.replace {
+			t.Errorf("%s: mode %s, replace %q; want %s, %q", change.File, change.Mode, change.Replace, want.mode, want.replace)
+		}
+		if hint := strings.Contains(change.Reason, "import os"); hint != want.osHint {
+			t.Errorf("%s: reason %q, want os import hint %v", change.File, change.Reason, want.osHint)
+		}
+	}
+}
+
+func TestScanPathSkipsIgnoredAndBinaryFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".gitignore":            "dist/\n*.log\n!keep.log\n",
+		"dist/bundle.js":        "storage.googleapis.com",
+		"debug.log":             "storage.googleapis.com",
+		"keep.log":              "storage.googleapis.com",
+		"web/.gitignore":        "/local.env\n",
+		"web/local.env":         "storage.googleapis.com",
+		"web/nested/local.env":  "storage.googleapis.com",
+		"assets/logo.png":       "\x89PNG\x00storage.googleapis.com",
+		".git/config":           "storage.googleapis.com",
+		"node_modules/x/a.json": "storage.googleapis.com",
+	})
+
+	report, err := NewService().ScanPath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, change := range report.Changes {
+		files = append(files, change.File)
+	}
+	if strings.Join(files, ",") != "keep.log,node_modules/x/a.json,web/nested/local.env" {
+		t.Fatalf("scanned files = %v", files)
+	}
+}
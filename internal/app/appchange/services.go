@@ -0,0 +1,159 @@
+package appchange
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/homeport/homeport/internal/app/compat"
+)
+
+// cloudService names a provider service the way the compat registry keys it.
+// nativeEnv and nativeURL describe the self-hosted replacement of services
+// the registry has no adapter for.
+type cloudService struct {
+	provider  string
+	key       string
+	name      string
+	nativeEnv string
+	nativeURL string
+	known     bool
+}
+
+var cloudServices = map[string]map[string]cloudService{
+	"aws": {
+		"s3":             {key: "s3", name: "S3"},
+		"sqs":            {key: "sqs", name: "SQS"},
+		"sns":            {key: "sns", name: "SNS"},
+		"dynamodb":       {key: "dynamodb", name: "DynamoDB"},
+		"kinesis":        {key: "kinesis", name: "Kinesis"},
+		"secretsmanager": {key: "secretsmanager", name: "Secrets Manager"},
+		"kms":            {key: "kms", name: "KMS"},
+		"cloudwatchlogs": {key: "cloudwatchlogs", name: "CloudWatch Logs"},
+		"lambda":         {key: "lambda", name: "Lambda"},
+		"eventbridge":    {key: "eventbridge", name: "EventBridge"},
+		"ses":            {key: "ses", name: "SES"},
+		"cognito":        {key: "cognito", name: "Cognito"},
+		"stepfunctions":  {key: "stepfunctions", name: "Step Functions"},
+		"ssm":            {key: "ssm", name: "Systems Manager"},
+		"codebuild":      {key: "codebuild", name: "CodeBuild"},
+		"comprehend":     {key: "comprehend", name: "Comprehend"},
+		"ecr":            {key: "ecr", name: "ECR"},
+		"ecs":            {key: "ecs", name: "ECS"},
+		"eks":            {key: "eks", name: "EKS"},
+		"iam":            {key: "iam", name: "IAM"},
+		"acm":            {key: "acm", name: "ACM"},
+		"efs":            {key: "efs", name: "EFS"},
+		"appsync":        {key: "appsync", name: "AppSync"},
+		"apigateway":     {key: "apigateway", name: "API Gateway"},
+		"alb":            {key: "alb", name: "Elastic Load Balancing"},
+	},
+	"gcp": {
+		"storage": {key: "storage", name: "Cloud Storage", nativeEnv: "HOMEPORT_STORAGE_ENDPOINT", nativeURL: "http://minio:9000"},
+		"pubsub":  {key: "pub-sub", name: "Pub/Sub"},
+	},
+	"azure": {
+		"servicebus": {key: "service-bus", name: "Service Bus"},
+		"blob":       {key: "blob", name: "Blob Storage", nativeEnv: "HOMEPORT_STORAGE_ENDPOINT", nativeURL: "http://minio:9000"},
+	},
+}
+
+// serviceAliases maps the other spellings SDKs and hostnames use for a
+// service, after normalizeServiceID, to its cloudServices entry.
+var serviceAliases = map[string]map[string]string{
+	"aws": {
+		"logs":                    "cloudwatchlogs",
+		"events":                  "eventbridge",
+		"cloudwatchevents":        "eventbridge",
+		"sesv2":                   "ses",
+		"email":                   "ses",
+		"simpleemailservice":      "ses",
+		"cognitoidp":              "cognito",
+		"cognitoidentityprovider": "cognito",
+		"sfn":                     "stepfunctions",
+		"states":                  "stepfunctions",
+		"apigatewayv2":            "apigateway",
+		"elasticloadbalancing":    "alb",
+		"elasticloadbalancingv2":  "alb",
+		"elb":                     "alb",
+		"simplesystemsmanagement": "ssm",
+	},
+	"gcp": {
+		"pubsubv1": "pubsub",
+	},
+	"azure": {
+		"storageblob":  "blob",
+		"azblob":       "blob",
+		"azservicebus": "servicebus",
+	},
+}
+
+func normalizeServiceID(id string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(id) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// lookupService resolves an SDK or hostname service identifier. Unknown
+// identifiers keep their spelling and report known=false.
+func lookupService(provider, id string) cloudService {
+	normalized := normalizeServiceID(id)
+	if alias, ok := serviceAliases[provider][normalized]; ok {
+		normalized = alias
+	}
+	service, ok := cloudServices[provider][normalized]
+	if !ok {
+		return cloudService{provider: provider, key: normalized, name: id}
+	}
+	service.provider = provider
+	service.known = true
+	return service
+}
+
+// replacement is where traffic for a service goes once migrated: the
+// environment variable carrying the endpoint and its default value.
+// sdkNative is set when the provider SDKs read the variable themselves.
+type replacement struct {
+	env       string
+	url       string
+	sdkNative bool
+	native    bool
+}
+
+func (s *Service) replacementFor(service cloudService) (replacement, bool) {
+	if s.registry != nil {
+		if adapter, err := s.registry.Get(service.provider, service.key); err == nil {
+			if found, ok := endpointEnv(adapter); ok {
+				return found, true
+			}
+		}
+	}
+	if service.nativeEnv != "" {
+		return replacement{env: service.nativeEnv, url: service.nativeURL, native: true}, true
+	}
+	return replacement{}, false
+}
+
+// endpointEnv picks the adapter variable that carries its endpoint, preferring
+// one the provider SDKs honour without code changes.
+func endpointEnv(adapter compat.Adapter) (replacement, bool) {
+	env := adapter.TargetEnv()
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var found replacement
+	for _, key := range keys {
+		switch {
+		case strings.HasPrefix(key, "AWS_ENDPOINT_URL_"), strings.HasSuffix(key, "_EMULATOR_HOST"):
+			return replacement{env: key, url: env[key], sdkNative: true}, true
+		case strings.Contains(key, "ENDPOINT") && found.env == "":
+			found = replacement{env: key, url: env[key]}
+		}
+	}
+	return found, found.env != ""
+}
@@ -120,6 +120,12 @@ func declaredDriver(key ServiceKey) string {
 		return "lambda-local"
 	case ServiceSQS:
 		return "sqs-local"
+	case ServiceS3:
+		return "s3-minio"
+	case ServiceDynamoDB:
+		return "dynamodb-compat"
+	case ServiceSNS:
+		return "sns-compat"
 	default:
 		return "unavailable-local"
 	}
@@ -3,6 +3,7 @@ package awsoperations
 import (
 	"context"
 	"errors"
+	"io"
 	"time"
 )
 
@@ -10,6 +11,8 @@ var (
 	ErrServiceUnavailable    = errors.New("AWS operations service unavailable")
 	ErrCapabilityUnavailable = errors.New("AWS operations capability unavailable")
 	ErrResourceNotBound      = errors.New("AWS operations resource is not bound")
+	ErrNotFound              = errors.New("AWS operations item not found")
+	ErrInvalidInput          = errors.New("AWS operations input is invalid")
 )
 
 type Driver interface {
@@ -84,6 +87,60 @@ type MessageRecord struct {
 	CompletedAt *time.Time     `json:"completed_at,omitempty"`
 	FailedAt    *time.Time     `json:"failed_at,omitempty"`
 }
+type BucketRecord struct {
+	Name               string            `json:"name"`
+	CreatedAt          time.Time         `json:"created_at"`
+	ImportedResourceID string            `json:"imported_resource_id"`
+	Region             string            `json:"region,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	LocalStackID       string            `json:"local_stack_id"`
+}
+type ObjectRecord struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	ContentType  string    `json:"content_type,omitempty"`
+	IsPrefix     bool      `json:"is_prefix,omitempty"`
+}
+type PresignRecord struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+type TableRecord struct {
+	Name               string            `json:"name"`
+	HashKey            string            `json:"hash_key"`
+	RangeKey           string            `json:"range_key,omitempty"`
+	BillingMode        string            `json:"billing_mode,omitempty"`
+	CreatedAt          time.Time         `json:"created_at"`
+	ImportedResourceID string            `json:"imported_resource_id"`
+	Region             string            `json:"region,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	LocalStackID       string            `json:"local_stack_id"`
+}
+
+// ItemPage holds items in their DynamoDB wire form, attribute name to typed
+// value such as {"S": "text"}.
+type ItemPage struct {
+	Items            []map[string]any `json:"items"`
+	LastEvaluatedKey map[string]any   `json:"last_evaluated_key,omitempty"`
+}
+type TopicRecord struct {
+	Name               string            `json:"name"`
+	ARN                string            `json:"arn"`
+	SubscriptionCount  int               `json:"subscription_count"`
+	ImportedResourceID string            `json:"imported_resource_id"`
+	Region             string            `json:"region,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	LocalStackID       string            `json:"local_stack_id"`
+}
+type SubscriptionRecord struct {
+	ARN      string `json:"arn"`
+	Protocol string `json:"protocol"`
+	Endpoint string `json:"endpoint"`
+}
+type PublishRecord struct {
+	MessageID string `json:"message_id"`
+}
 type FunctionsBackend interface {
 	List(context.Context) ([]FunctionRecord, error)
 	Get(context.Context, string) (*FunctionRecord, error)
@@ -101,6 +158,34 @@ type QueuesBackend interface {
 	Purge(context.Context, string, string, string) (int64, error)
 }
 
+// ObjectStorageBackend reports a missing bucket or object with ErrNotFound.
+type ObjectStorageBackend interface {
+	Buckets(context.Context) ([]BucketRecord, error)
+	Objects(context.Context, string, string) ([]ObjectRecord, error)
+	Object(context.Context, string, string) (*ObjectRecord, io.ReadCloser, error)
+	DeleteObject(context.Context, string, string) error
+	Presign(context.Context, string, string, time.Duration) (string, error)
+}
+
+// TablesBackend reports a missing table with ErrNotFound and items that do
+// not match the key schema with ErrInvalidInput. GetItem returns nil for a
+// missing item.
+type TablesBackend interface {
+	Tables(context.Context) ([]TableRecord, error)
+	Scan(context.Context, string, int, map[string]any) (*ItemPage, error)
+	GetItem(context.Context, string, map[string]any) (map[string]any, error)
+	PutItem(context.Context, string, map[string]any) error
+	DeleteItem(context.Context, string, map[string]any) error
+}
+
+// TopicsBackend addresses topics by name and reports a missing topic with
+// ErrNotFound.
+type TopicsBackend interface {
+	Topics(context.Context) ([]TopicRecord, error)
+	Subscriptions(context.Context, string) ([]SubscriptionRecord, error)
+	Publish(context.Context, string, string) (*PublishRecord, error)
+}
+
 func serviceState(w Workspace, service ServiceKey, capability Capability) (ServiceState, error) {
 	state, ok := w.Services[service]
 	if !ok || state.Status != ServiceStatusAvailable {
@@ -3,7 +3,15 @@ package awsoperations
 import (
 	"context"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
+
+	compataws "github.com/homeport/homeport/internal/app/compat/aws"
 )
 
 func TestLambdaDriverRejectsUpdateOutsideWorkspaceBinding(t *testing.T) {
@@ -100,6 +108,134 @@ func TestLambdaDriverAppliesBindingMetadataToUpdate(t *testing.T) {
 	}
 }
 
+func TestS3DriverPresignsOnlyBoundBucketsWithinExpiryBounds(t *testing.T) {
+	backend := &fakeObjects{}
+	driver := NewS3Driver(backend)
+	workspace := Workspace{Services: map[ServiceKey]ServiceState{ServiceS3: {Status: ServiceStatusAvailable, Capabilities: []Capability{CapabilityPresign}}}, Bindings: []ResourceBinding{{Service: ServiceS3, LocalResourceID: "assets"}}}
+
+	if _, err := driver.Presign(context.Background(), workspace, "other", "logo.png", time.Hour); !errors.Is(err, ErrResourceNotBound) {
+		t.Fatalf("Presign(unbound) error = %v, want ErrResourceNotBound", err)
+	}
+	for _, expiry := range []time.Duration{0, MaxPresignExpiry + time.Second} {
+		if _, err := driver.Presign(context.Background(), workspace, "assets", "logo.png", expiry); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("Presign(expiry %s) error = %v, want ErrInvalidInput", expiry, err)
+		}
+	}
+	if backend.presigned != "" {
+		t.Fatalf("backend presigned %q, want no downstream call", backend.presigned)
+	}
+	result, err := driver.Presign(context.Background(), workspace, "assets", "logo.png", time.Hour)
+	if err != nil {
+		t.Fatalf("Presign(bound): %v", err)
+	}
+	if backend.presigned != "assets/logo.png" || result.URL == "" || time.Until(result.ExpiresAt) <= 59*time.Minute {
+		t.Fatalf("Presign() = %#v, backend %q", result, backend.presigned)
+	}
+	if err := driver.DeleteObject(context.Background(), workspace, "assets", "logo.png"); !errors.Is(err, ErrCapabilityUnavailable) {
+		t.Fatalf("DeleteObject() error = %v, want missing capability rejected", err)
+	}
+}
+
+func TestS3DriverListsOnlyBoundBuckets(t *testing.T) {
+	driver := NewS3Driver(&fakeObjects{buckets: []BucketRecord{{Name: "assets"}, {Name: "other"}}})
+	workspace := Workspace{Services: map[ServiceKey]ServiceState{ServiceS3: {Status: ServiceStatusAvailable, Capabilities: []Capability{CapabilityList}}}, Bindings: []ResourceBinding{{ImportedResourceID: "bucket-1", Service: ServiceS3, LocalResourceID: "assets", LocalStackID: "storage", Region: "eu-west-3"}}}
+
+	items, err := driver.List(context.Background(), workspace)
+	if err != nil {
+		t.Fatalf("List(): %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("List() = %#v, want only bound bucket", items)
+	}
+	if bucket := items[0].(BucketRecord); bucket.Name != "assets" || bucket.ImportedResourceID != "bucket-1" || bucket.LocalStackID != "storage" {
+		t.Fatalf("bucket = %#v, want binding metadata", bucket)
+	}
+}
+
+func TestDynamoDBDriverWorksOnBoundCompatTable(t *testing.T) {
+	ctx := context.Background()
+	tables := compataws.NewMemoryDynamoDBBackend()
+	if err := tables.CreateTable(ctx, compataws.DynamoDBTable{Name: "orders", HashKey: "id"}); err != nil {
+		t.Fatal(err)
+	}
+	driver := NewDynamoDBDriver(NewTablesBackend(compataws.NewDynamoDBAdapter(compataws.WithDynamoDBBackend(tables))))
+	workspace := Workspace{Services: map[ServiceKey]ServiceState{ServiceDynamoDB: {Status: ServiceStatusAvailable, Capabilities: capabilitiesFor(ServiceDynamoDB)}}, Bindings: []ResourceBinding{{ImportedResourceID: "table-1", Service: ServiceDynamoDB, LocalResourceID: "orders"}}}
+	key := map[string]any{"id": map[string]any{"S": "o-1"}}
+
+	if err := driver.PutItem(ctx, workspace, "orders", map[string]any{"id": map[string]any{"S": "o-1"}, "total": map[string]any{"N": "42"}}); err != nil {
+		t.Fatalf("PutItem(): %v", err)
+	}
+	if err := driver.PutItem(ctx, workspace, "orders", map[string]any{"total": map[string]any{"N": "1"}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("PutItem(without key) error = %v, want ErrInvalidInput", err)
+	}
+	if err := driver.PutItem(ctx, workspace, "customers", map[string]any{"id": map[string]any{"S": "c-1"}}); !errors.Is(err, ErrResourceNotBound) {
+		t.Fatalf("PutItem(unbound) error = %v, want ErrResourceNotBound", err)
+	}
+	item, err := driver.GetItem(ctx, workspace, "orders", key)
+	if err != nil {
+		t.Fatalf("GetItem(): %v", err)
+	}
+	if total := item["total"].(map[string]any)["N"]; total != "42" {
+		t.Fatalf("GetItem() = %#v", item)
+	}
+	page, err := driver.Scan(ctx, workspace, "orders", 0, nil)
+	if err != nil || len(page.Items) != 1 {
+		t.Fatalf("Scan() = %#v, %v", page, err)
+	}
+	if err := driver.DeleteItem(ctx, workspace, "orders", key); err != nil {
+		t.Fatalf("DeleteItem(): %v", err)
+	}
+	if _, err := driver.GetItem(ctx, workspace, "orders", key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetItem(deleted) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSNSDriverPublishesToBoundCompatTopic(t *testing.T) {
+	ctx := context.Background()
+	delivered := make(chan string, 1)
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		delivered <- string(body)
+	}))
+	t.Cleanup(subscriber.Close)
+
+	adapter := compataws.NewSNSAdapter()
+	for _, form := range []url.Values{
+		{"Action": {"CreateTopic"}, "Name": {"orders"}},
+		{"Action": {"CreateTopic"}, "Name": {"other"}},
+		{"Action": {"Subscribe"}, "TopicArn": {"arn:aws:sns:us-east-1:000000000000:orders"}, "Protocol": {"http"}, "Endpoint": {subscriber.URL}},
+	} {
+		request := httptest.NewRequest(http.MethodPost, "/compat/aws/sns", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		adapter.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s status = %d: %s", form.Get("Action"), recorder.Code, recorder.Body)
+		}
+	}
+	driver := NewSNSDriver(NewTopicsBackend(adapter))
+	workspace := Workspace{Services: map[ServiceKey]ServiceState{ServiceSNS: {Status: ServiceStatusAvailable, Capabilities: capabilitiesFor(ServiceSNS)}}, Bindings: []ResourceBinding{{ImportedResourceID: "topic-1", Service: ServiceSNS, LocalResourceID: "orders"}}}
+
+	items, err := driver.List(ctx, workspace)
+	if err != nil || len(items) != 1 || items[0].(TopicRecord).SubscriptionCount != 1 {
+		t.Fatalf("List() = %#v, %v, want the bound topic", items, err)
+	}
+	subscriptions, err := driver.Subscriptions(ctx, workspace, "orders")
+	if err != nil || len(subscriptions) != 1 || subscriptions[0].Endpoint != subscriber.URL {
+		t.Fatalf("Subscriptions() = %#v, %v", subscriptions, err)
+	}
+	if _, err := driver.Publish(ctx, workspace, "other", "hello"); !errors.Is(err, ErrResourceNotBound) {
+		t.Fatalf("Publish(unbound) error = %v, want ErrResourceNotBound", err)
+	}
+	result, err := driver.Publish(ctx, workspace, "orders", "hello")
+	if err != nil || result.MessageID == "" {
+		t.Fatalf("Publish() = %#v, %v", result, err)
+	}
+	if got := <-delivered; got != "hello" {
+		t.Fatalf("delivered %q, want hello", got)
+	}
+}
+
 type fakeFunctions struct {
 	invoked string
 	updated *FunctionRecord
@@ -138,3 +274,21 @@ func (*fakeQueues) Messages(context.Context, string, string, string) ([]MessageR
 func (*fakeQueues) Retry(context.Context, string, string, string) error          { return nil }
 func (*fakeQueues) Delete(context.Context, string, string, string) error         { return nil }
 func (*fakeQueues) Purge(context.Context, string, string, string) (int64, error) { return 0, nil }
+
+type fakeObjects struct {
+	buckets   []BucketRecord
+	presigned string
+}
+
+func (f *fakeObjects) Buckets(context.Context) ([]BucketRecord, error) { return f.buckets, nil }
+func (*fakeObjects) Objects(context.Context, string, string) ([]ObjectRecord, error) {
+	return nil, nil
+}
+func (*fakeObjects) Object(context.Context, string, string) (*ObjectRecord, io.ReadCloser, error) {
+	return nil, nil, ErrNotFound
+}
+func (*fakeObjects) DeleteObject(context.Context, string, string) error { return nil }
+func (f *fakeObjects) Presign(_ context.Context, bucket, key string, _ time.Duration) (string, error) {
+	f.presigned = bucket + "/" + key
+	return "http://minio:9000/" + f.presigned + "?X-Amz-Signature=test", nil
+}
@@ -0,0 +1,85 @@
+package awsoperations
+
+import "context"
+
+type DynamoDBDriver struct{ backend TablesBackend }
+
+func NewDynamoDBDriver(b TablesBackend) *DynamoDBDriver { return &DynamoDBDriver{backend: b} }
+func (*DynamoDBDriver) Service() ServiceKey             { return ServiceDynamoDB }
+func (*DynamoDBDriver) Capabilities(w Workspace) []Capability {
+	return append([]Capability(nil), w.Services[ServiceDynamoDB].Capabilities...)
+}
+func (d *DynamoDBDriver) List(ctx context.Context, w Workspace) ([]any, error) {
+	if _, err := serviceState(w, ServiceDynamoDB, CapabilityList); err != nil {
+		return nil, err
+	}
+	tables, err := d.backend.Tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bound := map[string]ResourceBinding{}
+	for _, binding := range bindingsFor(w, ServiceDynamoDB) {
+		bound[binding.LocalResourceID] = binding
+	}
+	result := make([]any, 0)
+	for _, table := range tables {
+		if binding, ok := bound[table.Name]; ok {
+			table.ImportedResourceID = binding.ImportedResourceID
+			table.Region = binding.Region
+			table.Tags = binding.Tags
+			table.LocalStackID = binding.LocalStackID
+			result = append(result, table)
+		}
+	}
+	return result, nil
+}
+
+// Scan returns up to limit items after startKey; a limit of zero or less
+// returns every item.
+func (d *DynamoDBDriver) Scan(ctx context.Context, w Workspace, id string, limit int, startKey map[string]any) (*ItemPage, error) {
+	binding, err := serviceBinding(w, ServiceDynamoDB, id, CapabilityRead)
+	if err != nil {
+		return nil, err
+	}
+	return d.backend.Scan(ctx, binding.LocalResourceID, limit, startKey)
+}
+func (d *DynamoDBDriver) GetItem(ctx context.Context, w Workspace, id string, key map[string]any) (map[string]any, error) {
+	binding, err := serviceBinding(w, ServiceDynamoDB, id, CapabilityRead)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) == 0 {
+		return nil, ErrInvalidInput
+	}
+	item, err := d.backend.GetItem(ctx, binding.LocalResourceID, key)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrNotFound
+	}
+	return item, nil
+}
+
+// PutItem creates or replaces an item. Items live inside a table whose
+// identity cutover attested, so writing them is an update of that table.
+func (d *DynamoDBDriver) PutItem(ctx context.Context, w Workspace, id string, item map[string]any) error {
+	binding, err := serviceBinding(w, ServiceDynamoDB, id, CapabilityUpdate)
+	if err != nil {
+		return err
+	}
+	if len(item) == 0 {
+		return ErrInvalidInput
+	}
+	return d.backend.PutItem(ctx, binding.LocalResourceID, item)
+}
+func (d *DynamoDBDriver) DeleteItem(ctx context.Context, w Workspace, id string, key map[string]any) error {
+	binding, err := serviceBinding(w, ServiceDynamoDB, id, CapabilityDelete)
+	if err != nil {
+		return err
+	}
+	if len(key) == 0 {
+		return ErrInvalidInput
+	}
+	return d.backend.DeleteItem(ctx, binding.LocalResourceID, key)
+}
@@ -2,9 +2,16 @@ package awsoperations
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
 
+	compataws "github.com/homeport/homeport/internal/app/compat/aws"
 	"github.com/homeport/homeport/internal/app/functions"
 	"github.com/homeport/homeport/internal/app/queues"
+	"github.com/homeport/homeport/internal/app/storage"
+	"github.com/minio/minio-go/v7"
 )
 
 // NewFunctionsBackend adapts the local Homeport functions service. It is the
@@ -114,3 +121,170 @@ func (b queuesBackend) Delete(ctx context.Context, stackID, queueName, messageID
 func (b queuesBackend) Purge(ctx context.Context, stackID, queueName, status string) (int64, error) {
 	return b.service.PurgeQueue(ctx, stackID, queueName, queues.MessageStatus(status))
 }
+
+// NewObjectStorageBackend adapts the MinIO target that holds migrated S3
+// buckets.
+func NewObjectStorageBackend(service *storage.Service) ObjectStorageBackend {
+	return objectStorageBackend{service: service}
+}
+
+type objectStorageBackend struct{ service *storage.Service }
+
+func (b objectStorageBackend) Buckets(ctx context.Context) ([]BucketRecord, error) {
+	items, err := b.service.ListBuckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]BucketRecord, 0, len(items))
+	for _, item := range items {
+		result = append(result, BucketRecord{Name: item.Name, CreatedAt: item.Created})
+	}
+	return result, nil
+}
+func (b objectStorageBackend) Objects(ctx context.Context, bucket, prefix string) ([]ObjectRecord, error) {
+	items, err := b.service.ListObjects(ctx, bucket, prefix)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	result := make([]ObjectRecord, 0, len(items))
+	for _, item := range items {
+		result = append(result, objectRecord(item))
+	}
+	return result, nil
+}
+func (b objectStorageBackend) Object(ctx context.Context, bucket, key string) (*ObjectRecord, io.ReadCloser, error) {
+	// Stat first: a MinIO download only reports a missing object on read
+	info, err := b.service.StatObject(ctx, bucket, key)
+	if err != nil {
+		return nil, nil, storageError(err)
+	}
+	body, err := b.service.DownloadObject(ctx, bucket, key)
+	if err != nil {
+		return nil, nil, storageError(err)
+	}
+	record := objectRecord(info)
+	return &record, body, nil
+}
+func (b objectStorageBackend) DeleteObject(ctx context.Context, bucket, key string) error {
+	return storageError(b.service.DeleteObject(ctx, bucket, key))
+}
+func (b objectStorageBackend) Presign(ctx context.Context, bucket, key string, expiry time.Duration) (string, error) {
+	url, err := b.service.GetPresignedURL(ctx, bucket, key, expiry)
+	return url, storageError(err)
+}
+func objectRecord(item storage.ObjectInfo) ObjectRecord {
+	return ObjectRecord{Key: item.Key, Size: item.Size, LastModified: item.LastModified, ContentType: item.ContentType, IsPrefix: item.IsDir}
+}
+func storageError(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchBucket", "NoSuchKey":
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+// NewTablesBackend adapts the DynamoDB compatibility adapter, whose backend
+// is the Scylla-compatible table store workloads use after cutover.
+func NewTablesBackend(adapter *compataws.DynamoDBAdapter) TablesBackend {
+	return tablesBackend{adapter: adapter}
+}
+
+type tablesBackend struct{ adapter *compataws.DynamoDBAdapter }
+
+func (b tablesBackend) Tables(ctx context.Context) ([]TableRecord, error) {
+	items, err := b.adapter.Tables(ctx)
+	if err != nil {
+		return nil, tablesError(err)
+	}
+	result := make([]TableRecord, 0, len(items))
+	for _, item := range items {
+		result = append(result, TableRecord{Name: item.Name, HashKey: item.HashKey, RangeKey: item.RangeKey, BillingMode: item.BillingMode, CreatedAt: item.CreatedAt})
+	}
+	return result, nil
+}
+func (b tablesBackend) Scan(ctx context.Context, table string, limit int, startKey map[string]any) (*ItemPage, error) {
+	items, last, err := b.adapter.ScanTable(ctx, table, limit, startKey)
+	if err != nil {
+		return nil, tablesError(err)
+	}
+	if items == nil {
+		items = []map[string]any{}
+	}
+	return &ItemPage{Items: items, LastEvaluatedKey: last}, nil
+}
+func (b tablesBackend) GetItem(ctx context.Context, table string, key map[string]any) (map[string]any, error) {
+	item, err := b.adapter.GetTableItem(ctx, table, key)
+	return item, tablesError(err)
+}
+func (b tablesBackend) PutItem(ctx context.Context, table string, item map[string]any) error {
+	return tablesError(b.adapter.PutTableItem(ctx, table, item))
+}
+func (b tablesBackend) DeleteItem(ctx context.Context, table string, key map[string]any) error {
+	return tablesError(b.adapter.DeleteTableItem(ctx, table, key))
+}
+func tablesError(err error) error {
+	switch {
+	case errors.Is(err, compataws.ErrDynamoDBTableNotFound):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case errors.Is(err, compataws.ErrDynamoDBInvalidRequest):
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	return err
+}
+
+// NewTopicsBackend adapts the SNS compatibility adapter that serves migrated
+// topics.
+func NewTopicsBackend(adapter *compataws.SNSAdapter) TopicsBackend {
+	return topicsBackend{adapter: adapter}
+}
+
+type topicsBackend struct{ adapter *compataws.SNSAdapter }
+
+func (b topicsBackend) Topics(context.Context) ([]TopicRecord, error) {
+	items := b.adapter.Topics()
+	result := make([]TopicRecord, 0, len(items))
+	for _, item := range items {
+		result = append(result, TopicRecord{Name: item.Name, ARN: item.ARN, SubscriptionCount: item.Subscriptions})
+	}
+	return result, nil
+}
+func (b topicsBackend) Subscriptions(_ context.Context, name string) ([]SubscriptionRecord, error) {
+	arn, err := b.topicARN(name)
+	if err != nil {
+		return nil, err
+	}
+	items, err := b.adapter.Subscriptions(arn)
+	if err != nil {
+		return nil, topicsError(err)
+	}
+	result := make([]SubscriptionRecord, 0, len(items))
+	for _, item := range items {
+		result = append(result, SubscriptionRecord{ARN: item.ARN, Protocol: item.Protocol, Endpoint: item.Endpoint})
+	}
+	return result, nil
+}
+func (b topicsBackend) Publish(_ context.Context, name, message string) (*PublishRecord, error) {
+	arn, err := b.topicARN(name)
+	if err != nil {
+		return nil, err
+	}
+	messageID, err := b.adapter.Publish(arn, message)
+	if err != nil {
+		return nil, topicsError(err)
+	}
+	return &PublishRecord{MessageID: messageID}, nil
+}
+func (b topicsBackend) topicARN(name string) (string, error) {
+	for _, topic := range b.adapter.Topics() {
+		if topic.Name == name {
+			return topic.ARN, nil
+		}
+	}
+	return "", fmt.Errorf("%w: topic %s", ErrNotFound, name)
+}
+func topicsError(err error) error {
+	if errors.Is(err, compataws.ErrSNSTopicNotFound) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package awsoperations
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// MaxPresignExpiry is the longest validity S3 signature version 4 allows for
+// a presigned URL.
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+type S3Driver struct{ backend ObjectStorageBackend }
+
+func NewS3Driver(b ObjectStorageBackend) *S3Driver { return &S3Driver{backend: b} }
+func (*S3Driver) Service() ServiceKey              { return ServiceS3 }
+func (*S3Driver) Capabilities(w Workspace) []Capability {
+	return append([]Capability(nil), w.Services[ServiceS3].Capabilities...)
+}
+func (d *S3Driver) List(ctx context.Context, w Workspace) ([]any, error) {
+	if _, err := serviceState(w, ServiceS3, CapabilityList); err != nil {
+		return nil, err
+	}
+	buckets, err := d.backend.Buckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bound := map[string]ResourceBinding{}
+	for _, binding := range bindingsFor(w, ServiceS3) {
+		bound[binding.LocalResourceID] = binding
+	}
+	result := make([]any, 0)
+	for _, bucket := range buckets {
+		if binding, ok := bound[bucket.Name]; ok {
+			bucket.ImportedResourceID = binding.ImportedResourceID
+			bucket.Region = binding.Region
+			bucket.Tags = binding.Tags
+			bucket.LocalStackID = binding.LocalStackID
+			result = append(result, bucket)
+		}
+	}
+	return result, nil
+}
+func (d *S3Driver) Objects(ctx context.Context, w Workspace, id, prefix string) ([]ObjectRecord, error) {
+	binding, err := serviceBinding(w, ServiceS3, id, CapabilityRead)
+	if err != nil {
+		return nil, err
+	}
+	return d.backend.Objects(ctx, binding.LocalResourceID, prefix)
+}
+
+// Object returns the metadata and content of an object. The caller closes the
+// reader.
+func (d *S3Driver) Object(ctx context.Context, w Workspace, id, key string) (*ObjectRecord, io.ReadCloser, error) {
+	binding, err := serviceBinding(w, ServiceS3, id, CapabilityRead)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == "" {
+		return nil, nil, ErrInvalidInput
+	}
+	return d.backend.Object(ctx, binding.LocalResourceID, key)
+}
+func (d *S3Driver) DeleteObject(ctx context.Context, w Workspace, id, key string) error {
+	binding, err := serviceBinding(w, ServiceS3, id, CapabilityDelete)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return ErrInvalidInput
+	}
+	return d.backend.DeleteObject(ctx, binding.LocalResourceID, key)
+}
+
+// Presign returns a URL that reads one object without credentials until it
+// expires.
+func (d *S3Driver) Presign(ctx context.Context, w Workspace, id, key string, expiry time.Duration) (*PresignRecord, error) {
+	binding, err := serviceBinding(w, ServiceS3, id, CapabilityPresign)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" || expiry < time.Second || expiry > MaxPresignExpiry {
+		return nil, ErrInvalidInput
+	}
+	expiresAt := time.Now().UTC().Add(expiry)
+	url, err := d.backend.Presign(ctx, binding.LocalResourceID, key, expiry)
+	if err != nil {
+		return nil, err
+	}
+	return &PresignRecord{URL: url, ExpiresAt: expiresAt}, nil
+}
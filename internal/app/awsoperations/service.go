@@ -198,6 +198,12 @@ func capabilitiesFor(service ServiceKey) []Capability {
 		return []Capability{CapabilityList, CapabilityRead, CapabilityUpdate, CapabilityDelete, CapabilityInvoke, CapabilityLogs}
 	case ServiceSQS:
 		return []Capability{CapabilityList, CapabilityRead, CapabilityDelete, CapabilityPurge, CapabilityRetry}
+	case ServiceS3:
+		return []Capability{CapabilityList, CapabilityRead, CapabilityDelete, CapabilityPresign}
+	case ServiceDynamoDB:
+		return []Capability{CapabilityList, CapabilityRead, CapabilityUpdate, CapabilityDelete}
+	case ServiceSNS:
+		return []Capability{CapabilityList, CapabilityRead, CapabilityPublish}
 	default:
 		return []Capability{}
 	}
@@ -0,0 +1,52 @@
+package awsoperations
+
+import "context"
+
+type SNSDriver struct{ backend TopicsBackend }
+
+func NewSNSDriver(b TopicsBackend) *SNSDriver { return &SNSDriver{backend: b} }
+func (*SNSDriver) Service() ServiceKey        { return ServiceSNS }
+func (*SNSDriver) Capabilities(w Workspace) []Capability {
+	return append([]Capability(nil), w.Services[ServiceSNS].Capabilities...)
+}
+func (d *SNSDriver) List(ctx context.Context, w Workspace) ([]any, error) {
+	if _, err := serviceState(w, ServiceSNS, CapabilityList); err != nil {
+		return nil, err
+	}
+	topics, err := d.backend.Topics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bound := map[string]ResourceBinding{}
+	for _, binding := range bindingsFor(w, ServiceSNS) {
+		bound[binding.LocalResourceID] = binding
+	}
+	result := make([]any, 0)
+	for _, topic := range topics {
+		if binding, ok := bound[topic.Name]; ok {
+			topic.ImportedResourceID = binding.ImportedResourceID
+			topic.Region = binding.Region
+			topic.Tags = binding.Tags
+			topic.LocalStackID = binding.LocalStackID
+			result = append(result, topic)
+		}
+	}
+	return result, nil
+}
+func (d *SNSDriver) Subscriptions(ctx context.Context, w Workspace, id string) ([]SubscriptionRecord, error) {
+	binding, err := serviceBinding(w, ServiceSNS, id, CapabilityRead)
+	if err != nil {
+		return nil, err
+	}
+	return d.backend.Subscriptions(ctx, binding.LocalResourceID)
+}
+func (d *SNSDriver) Publish(ctx context.Context, w Workspace, id, message string) (*PublishRecord, error) {
+	binding, err := serviceBinding(w, ServiceSNS, id, CapabilityPublish)
+	if err != nil {
+		return nil, err
+	}
+	if message == "" {
+		return nil, ErrInvalidInput
+	}
+	return d.backend.Publish(ctx, binding.LocalResourceID, message)
+}
@@ -6,8 +6,11 @@ import "time"
 type ServiceKey string
 
 const (
-	ServiceLambda ServiceKey = "lambda"
-	ServiceSQS    ServiceKey = "sqs"
+	ServiceLambda   ServiceKey = "lambda"
+	ServiceSQS      ServiceKey = "sqs"
+	ServiceS3       ServiceKey = "s3"
+	ServiceDynamoDB ServiceKey = "dynamodb"
+	ServiceSNS      ServiceKey = "sns"
 )
 
 type ServiceStatus string
@@ -21,15 +24,17 @@ const (
 type Capability string
 
 const (
-	CapabilityList   Capability = "list"
-	CapabilityRead   Capability = "read"
-	CapabilityCreate Capability = "create"
-	CapabilityUpdate Capability = "update"
-	CapabilityDelete Capability = "delete"
-	CapabilityInvoke Capability = "invoke"
-	CapabilityLogs   Capability = "logs"
-	CapabilityPurge  Capability = "purge"
-	CapabilityRetry  Capability = "retry"
+	CapabilityList    Capability = "list"
+	CapabilityRead    Capability = "read"
+	CapabilityCreate  Capability = "create"
+	CapabilityUpdate  Capability = "update"
+	CapabilityDelete  Capability = "delete"
+	CapabilityInvoke  Capability = "invoke"
+	CapabilityLogs    Capability = "logs"
+	CapabilityPurge   Capability = "purge"
+	CapabilityRetry   Capability = "retry"
+	CapabilityPresign Capability = "presign"
+	CapabilityPublish Capability = "publish"
 )
 
 type ResourceBinding struct {
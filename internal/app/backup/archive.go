@@ -0,0 +1,635 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/homeport/homeport/internal/pkg/logger"
+)
+
+// Continuous archiving ships the WAL of PostgreSQL servers and the binlogs of
+// MySQL servers to the backup destination, so that database backups can be
+// restored to any point in time after them.
+//
+// PostgreSQL WAL is pulled with pg_receivewal through the homeport_archive
+// physical replication slot, which retains WAL on the server until it has
+// been archived: disable archiving and drop the slot when removing a server
+// from backups. MySQL binlogs are copied once the server has rotated them.
+const (
+	pgArchiveSlot          = "homeport_archive"
+	pgWALSpool             = "/tmp/homeport-wal"
+	mysqlBinlogSpool       = "/tmp/homeport-binlog"
+	defaultArchiveInterval = 5 * time.Minute
+)
+
+// ErrPITRUnavailable is returned when a point-in-time restore is requested
+// for a volume that has no database backup or archive to replay.
+var ErrPITRUnavailable = errors.New("point-in-time recovery is not available")
+
+// ArchivedSegment is a WAL segment or binlog file in the archive.
+type ArchivedSegment struct {
+	Name       string    `json:"name"`
+	Key        string    `json:"key"`
+	Size       int64     `json:"size"` // Uncompressed size
+	Encrypted  bool      `json:"encrypted,omitempty"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// WALArchive is the continuous archive of a database volume.
+type WALArchive struct {
+	Volume    string            `json:"volume"`
+	Engine    string            `json:"engine"`
+	Segments  []ArchivedSegment `json:"segments"`
+	LastError string            `json:"last_error,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+var walSegmentName = regexp.MustCompile(`^[0-9A-F]{24}(\.history)?$`)
+
+// archiveKey is the inProgress key serializing archive passes of a volume.
+func archiveKey(volumeName string) string {
+	return "archive:" + volumeName
+}
+
+// ListArchives returns the continuous archives.
+func (s *Service) ListArchives(ctx context.Context) ([]*WALArchive, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*WALArchive, 0, len(s.archives))
+	for _, a := range s.archives {
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Volume < result[j].Volume
+	})
+	return result, nil
+}
+
+// archiveStore returns where archived segments are stored: the backup
+// destination, or a directory next to local backups.
+func (s *Service) archiveStore() (Destination, error) {
+	if s.destination != nil {
+		return s.destination, nil
+	}
+	return NewLocalDestination(filepath.Join(s.config.BackupDir, "archive"))
+}
+
+// archiveAll runs an archive pass for every database volume with a backup.
+func (s *Service) archiveAll(ctx context.Context) {
+	s.mu.RLock()
+	volumes := make(map[string]bool)
+	for _, b := range s.backups {
+		if b.Status != BackupStatusCompleted {
+			continue
+		}
+		for volumeName := range b.Databases {
+			volumes[volumeName] = true
+		}
+	}
+	s.mu.RUnlock()
+
+	for volumeName := range volumes {
+		if err := s.archiveVolume(ctx, volumeName, false); err != nil {
+			logger.Warn("WAL archiving failed", "volume", volumeName, "error", err)
+		}
+	}
+}
+
+// archiveVolume ships the WAL or binlogs written since the last pass. force
+// rotates the MySQL binlog even when little was written to it.
+func (s *Service) archiveVolume(ctx context.Context, volumeName string, force bool) error {
+	key := archiveKey(volumeName)
+	s.mu.Lock()
+	if s.inProgress[key] {
+		s.mu.Unlock()
+		return nil
+	}
+	s.inProgress[key] = true
+	archive, ok := s.archives[volumeName]
+	if !ok {
+		archive = &WALArchive{Volume: volumeName}
+		s.archives[volumeName] = archive
+	}
+	archived := make(map[string]bool, len(archive.Segments))
+	for _, seg := range archive.Segments {
+		archived[seg.Name] = true
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.inProgress, key)
+		s.mu.Unlock()
+	}()
+
+	segments, err := s.archiveDatabase(ctx, volumeName, archived, force)
+
+	s.mu.Lock()
+	archive.Segments = append(archive.Segments, segments...)
+	archive.UpdatedAt = time.Now()
+	archive.LastError = ""
+	if err != nil {
+		archive.LastError = err.Error()
+	}
+	s.mu.Unlock()
+	s.saveArchives()
+
+	if len(segments) > 0 {
+		logger.Info("Database log archived", "volume", volumeName, "segments", len(segments))
+	}
+	return err
+}
+
+func (s *Service) archiveDatabase(ctx context.Context, volumeName string, archived map[string]bool, force bool) ([]ArchivedSegment, error) {
+	db, err := s.findDatabaseContainer(ctx, volumeName)
+	if err != nil {
+		return nil, err
+	}
+	if db == nil {
+		return nil, fmt.Errorf("no running database server mounts volume %s", volumeName)
+	}
+
+	s.mu.Lock()
+	s.archives[volumeName].Engine = db.Engine
+	s.mu.Unlock()
+
+	if db.Engine == EnginePostgres {
+		return s.archivePostgres(ctx, db, volumeName, archived)
+	}
+	return s.archiveMySQL(ctx, db, volumeName, archived, force)
+}
+
+// ensureArchiveSlot creates the replication slot retaining WAL until it is
+// archived.
+func (s *Service) ensureArchiveSlot(ctx context.Context, db *databaseContainer) error {
+	_, err := s.psql(ctx, db, fmt.Sprintf(
+		"SELECT pg_create_physical_replication_slot('%[1]s', true) WHERE NOT EXISTS (SELECT 1 FROM pg_replication_slots WHERE slot_name = '%[1]s')",
+		pgArchiveSlot))
+	if err != nil {
+		return fmt.Errorf("failed to create replication slot: %w", err)
+	}
+	return nil
+}
+
+// archivePostgres receives the WAL up to the current position into a spool
+// directory in the container and uploads the completed segments.
+func (s *Service) archivePostgres(ctx context.Context, db *databaseContainer, volumeName string, archived map[string]bool) ([]ArchivedSegment, error) {
+	if err := s.ensureArchiveSlot(ctx, db); err != nil {
+		return nil, err
+	}
+	// Complete the current segment; this is a no-op on an idle server
+	if _, err := s.psql(ctx, db, "SELECT pg_switch_wal()"); err != nil {
+		return nil, err
+	}
+	lsn, err := s.psql(ctx, db, "SELECT pg_current_wal_lsn()")
+	if err != nil {
+		return nil, err
+	}
+
+	receive := []string{"sh", "-c",
+		`mkdir -p "$1" && exec pg_receivewal -U "$2" -D "$1" -S "$3" --endpos="$4" --no-loop --no-password`,
+		"sh", pgWALSpool, db.pgUser(), pgArchiveSlot, lsn}
+	if err := s.execInContainer(ctx, db.ID, receive, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return s.uploadSpool(ctx, db, volumeName, pgWALSpool, func(name string) bool {
+		return walSegmentName.MatchString(name)
+	}, archived)
+}
+
+// archiveMySQL rotates the binlog when it has grown, and uploads every
+// rotated binlog not archived yet.
+func (s *Service) archiveMySQL(ctx context.Context, db *databaseContainer, volumeName string, archived map[string]bool, force bool) ([]ArchivedSegment, error) {
+	rows, err := s.mysqlQuery(ctx, db, "SELECT @@log_bin, @@log_bin_basename")
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || len(rows[0]) < 2 || rows[0][0] != "1" {
+		return nil, fmt.Errorf("binary logging is disabled on %s", db.Name)
+	}
+	binlogDir := path.Dir(rows[0][1])
+
+	logs, err := s.mysqlQuery(ctx, db, "SHOW BINARY LOGS")
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) > 0 {
+		var size int64
+		if current := logs[len(logs)-1]; len(current) > 1 {
+			size, _ = strconv.ParseInt(current[1], 10, 64)
+		}
+		// A fresh binlog only holds its header
+		if force || size > 1024 {
+			if _, err := s.mysqlQuery(ctx, db, "FLUSH BINARY LOGS"); err != nil {
+				return nil, err
+			}
+			if logs, err = s.mysqlQuery(ctx, db, "SHOW BINARY LOGS"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Copy the rotated binlogs to a spool directory, as the server may purge
+	// them while they are read
+	var names []string
+	for i, row := range logs {
+		if i < len(logs)-1 && !archived[row[0]] {
+			names = append(names, row[0])
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	spool := []string{"sh", "-c", `mkdir -p "$1" && dir="$2" && shift 2 && for f in "$@"; do cp "$dir/$f" "` + mysqlBinlogSpool + `/$f"; done`,
+		"sh", mysqlBinlogSpool, binlogDir}
+	if err := s.execInContainer(ctx, db.ID, append(spool, names...), nil, nil); err != nil {
+		return nil, err
+	}
+
+	return s.uploadSpool(ctx, db, volumeName, mysqlBinlogSpool, func(name string) bool { return true }, archived)
+}
+
+// uploadSpool uploads the files of a spool directory in the container,
+// then removes them from it.
+func (s *Service) uploadSpool(ctx context.Context, db *databaseContainer, volumeName, spool string, include func(string) bool, archived map[string]bool) ([]ArchivedSegment, error) {
+	reader, _, err := s.dockerClient.CopyFromContainer(ctx, db.ID, spool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", spool, err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	var segments []ArchivedSegment
+	var done []string
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return segments, fmt.Errorf("failed to read %s: %w", spool, err)
+		}
+		name := path.Base(header.Name)
+		if header.Typeflag != tar.TypeReg || !include(name) {
+			continue
+		}
+		if !archived[name] {
+			seg, err := s.uploadSegment(ctx, volumeName, name, tarReader)
+			if err != nil {
+				return segments, err
+			}
+			segments = append(segments, seg)
+		}
+		done = append(done, name)
+	}
+
+	if len(done) > 0 {
+		rm := append([]string{"sh", "-c", `cd "$1" && shift && rm -f "$@"`, "sh", spool}, done...)
+		if err := s.execInContainer(ctx, db.ID, rm, nil, nil); err != nil {
+			logger.Warn("Failed to clean archive spool", "container", db.Name, "error", err)
+		}
+	}
+	return segments, nil
+}
+
+// uploadSegment compresses, optionally encrypts and uploads a segment.
+func (s *Service) uploadSegment(ctx context.Context, volumeName, name string, r io.Reader) (ArchivedSegment, error) {
+	seg := ArchivedSegment{
+		Name:       name,
+		Key:        path.Join("wal", volumeName, name+".gz"),
+		Encrypted:  s.config.KeySource != nil,
+		ArchivedAt: time.Now(),
+	}
+	if seg.Encrypted {
+		seg.Key += ".enc"
+	}
+
+	store, err := s.archiveStore()
+	if err != nil {
+		return seg, err
+	}
+
+	spool, err := os.CreateTemp(s.config.BackupDir, ".segment-*")
+	if err != nil {
+		return seg, err
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+
+	var out io.Writer = spool
+	var encWriter io.WriteCloser
+	if seg.Encrypted {
+		key, err := s.config.KeySource.BackupKey(ctx)
+		if err != nil {
+			return seg, fmt.Errorf("failed to get backup key: %w", err)
+		}
+		if encWriter, err = newEncryptWriter(spool, key); err != nil {
+			return seg, err
+		}
+		out = encWriter
+	}
+	gzWriter := gzip.NewWriter(out)
+	if seg.Size, err = io.Copy(gzWriter, r); err != nil {
+		return seg, err
+	}
+	if err := gzWriter.Close(); err != nil {
+		return seg, err
+	}
+	if encWriter != nil {
+		if err := encWriter.Close(); err != nil {
+			return seg, err
+		}
+	}
+
+	info, err := spool.Stat()
+	if err != nil {
+		return seg, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return seg, err
+	}
+	if err := store.Upload(ctx, seg.Key, spool, info.Size()); err != nil {
+		return seg, fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	return seg, nil
+}
+
+// openSegment downloads an archived segment and returns its content.
+func (s *Service) openSegment(ctx context.Context, seg ArchivedSegment) (io.ReadCloser, error) {
+	store, err := s.archiveStore()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := store.Download(ctx, seg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", seg.Name, err)
+	}
+
+	var r io.Reader = remote
+	if seg.Encrypted {
+		if s.config.KeySource == nil {
+			_ = remote.Close()
+			return nil, fmt.Errorf("segment %s is encrypted but no backup key is configured", seg.Name)
+		}
+		key, err := s.config.KeySource.BackupKey(ctx)
+		if err != nil {
+			_ = remote.Close()
+			return nil, err
+		}
+		if r, err = newDecryptReader(remote, key); err != nil {
+			_ = remote.Close()
+			return nil, err
+		}
+	}
+	gzReader, err := gzip.NewReader(r)
+	if err != nil {
+		_ = remote.Close()
+		return nil, err
+	}
+	return &archiveReader{Reader: gzReader, closers: []io.Closer{gzReader, remote}}, nil
+}
+
+// segmentsFrom returns the archived segments of a volume from first onwards,
+// in order. WAL timeline history files are always included.
+func (s *Service) segmentsFrom(volumeName, first string) []ArchivedSegment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	archive, ok := s.archives[volumeName]
+	if !ok {
+		return nil
+	}
+	var result []ArchivedSegment
+	for _, seg := range archive.Segments {
+		if seg.Name >= first || strings.HasSuffix(seg.Name, ".history") {
+			result = append(result, seg)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// writeRecoveryWAL adds the archived WAL needed to recover a base backup to
+// the restore archive of its volume, owned like the data directory.
+func (s *Service) writeRecoveryWAL(ctx context.Context, tarWriter *tar.Writer, volumeName string, record *DatabaseBackup, owner *tar.Header) error {
+	segments := s.segmentsFrom(volumeName, record.StartWAL)
+	if len(segments) == 0 {
+		return fmt.Errorf("%w: no archived WAL for volume %s", ErrPITRUnavailable, volumeName)
+	}
+	dir := path.Join(record.DataDir, pgRestoreWALDir)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: dir + "/", Mode: 0700, Typeflag: tar.TypeDir, Uid: owner.Uid, Gid: owner.Gid, ModTime: time.Now()}); err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		r, err := s.openSegment(ctx, seg)
+		if err != nil {
+			return err
+		}
+		err = tarWriter.WriteHeader(&tar.Header{Name: path.Join(dir, seg.Name), Mode: 0600, Size: seg.Size, Typeflag: tar.TypeReg, Uid: owner.Uid, Gid: owner.Gid, ModTime: seg.ArchivedAt})
+		if err == nil {
+			_, err = io.CopyN(tarWriter, r, seg.Size)
+		}
+		_ = r.Close()
+		if err != nil {
+			return fmt.Errorf("failed to add WAL segment %s: %w", seg.Name, err)
+		}
+	}
+	return nil
+}
+
+// writePITRFiles adds what a base backup needs to recover up to target: the
+// recovery settings when the backup had no postgresql.auto.conf, the
+// recovery.signal file and the archived WAL.
+func (s *Service) writePITRFiles(ctx context.Context, tarWriter *tar.Writer, volumeName string, record *DatabaseBackup, target time.Time, owner *tar.Header, autoConfWritten bool) error {
+	files := map[string]string{path.Join(record.DataDir, "recovery.signal"): ""}
+	if !autoConfWritten {
+		files[path.Join(record.DataDir, "postgresql.auto.conf")] = pgRecoveryConfig(path.Join(record.MountPath, record.DataDir, pgRestoreWALDir), target)
+	}
+	for name, content := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg, Uid: owner.Uid, Gid: owner.Gid, ModTime: time.Now()}); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(tarWriter, content); err != nil {
+			return err
+		}
+	}
+	return s.writeRecoveryWAL(ctx, tarWriter, volumeName, record, owner)
+}
+
+// replayBinlogs copies the archived binlogs following a dump into the server
+// container and replays them up to target.
+func (s *Service) replayBinlogs(ctx context.Context, db *databaseContainer, volumeName string, record *DatabaseBackup, target time.Time) error {
+	segments := s.segmentsFrom(volumeName, record.BinlogFile)
+	if len(segments) == 0 {
+		return fmt.Errorf("%w: no archived binlogs for volume %s", ErrPITRUnavailable, volumeName)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		tarWriter := tar.NewWriter(pw)
+		for _, seg := range segments {
+			r, err := s.openSegment(ctx, seg)
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+			err = tarWriter.WriteHeader(&tar.Header{Name: seg.Name, Mode: 0644, Size: seg.Size, Typeflag: tar.TypeReg, ModTime: seg.ArchivedAt})
+			if err == nil {
+				_, err = io.CopyN(tarWriter, r, seg.Size)
+			}
+			_ = r.Close()
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+		_ = pw.CloseWithError(tarWriter.Close())
+	}()
+
+	mkdir := []string{"mkdir", "-p", mysqlBinlogSpool}
+	if err := s.execInContainer(ctx, db.ID, mkdir, nil, nil); err != nil {
+		return err
+	}
+	if err := s.dockerClient.CopyToContainer(ctx, db.ID, mysqlBinlogSpool, pr, container.CopyToContainerOptions{}); err != nil {
+		_ = pr.CloseWithError(err)
+		return fmt.Errorf("failed to copy binlogs: %w", err)
+	}
+
+	args := []string{strconv.FormatInt(record.BinlogPos, 10), target.UTC().Format("2006-01-02 15:04:05")}
+	for _, seg := range segments {
+		args = append(args, mysqlBinlogSpool+"/"+seg.Name)
+	}
+	replay := mysqlShell(`pos="$1" stop="$2"; shift 2; `+
+		`"$BINLOG" --start-position="$pos" --stop-datetime="$stop" "$@" | "$CLIENT" -uroot --init-command="SET sql_log_bin=0"; `+
+		`status=$?; rm -rf `+mysqlBinlogSpool+`; exit $status`, args...)
+	if err := s.execInContainer(ctx, db.ID, replay, nil, nil); err != nil {
+		return fmt.Errorf("failed to replay binlogs: %w", err)
+	}
+
+	logger.Info("Binlogs replayed", "volume", volumeName, "files", len(segments), "target", target)
+	return nil
+}
+
+// pruneArchives deletes the segments older than the first one needed by the
+// remaining backups of each volume.
+func (s *Service) pruneArchives(ctx context.Context) error {
+	s.mu.RLock()
+	oldest := make(map[string]string)
+	for _, b := range s.backups {
+		if b.Status != BackupStatusCompleted {
+			continue
+		}
+		for volumeName, record := range b.Databases {
+			first := record.StartWAL
+			if record.Engine != EnginePostgres {
+				first = record.BinlogFile
+			}
+			if current, ok := oldest[volumeName]; !ok || first < current {
+				oldest[volumeName] = first
+			}
+		}
+	}
+
+	type pruneSet struct {
+		archive *WALArchive
+		prune   []ArchivedSegment
+	}
+	var sets []pruneSet
+	for volumeName, archive := range s.archives {
+		first, ok := oldest[volumeName]
+		if !ok || first == "" {
+			continue
+		}
+		var prune []ArchivedSegment
+		for _, seg := range archive.Segments {
+			if seg.Name < first && !strings.HasSuffix(seg.Name, ".history") {
+				prune = append(prune, seg)
+			}
+		}
+		if len(prune) > 0 {
+			sets = append(sets, pruneSet{archive: archive, prune: prune})
+		}
+	}
+	s.mu.RUnlock()
+
+	if len(sets) == 0 {
+		return nil
+	}
+	store, err := s.archiveStore()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, set := range sets {
+		deleted := make(map[string]bool)
+		for _, seg := range set.prune {
+			if err := store.Delete(ctx, seg.Key); err != nil {
+				errs = append(errs, fmt.Errorf("segment %s: %w", seg.Name, err))
+				continue
+			}
+			deleted[seg.Name] = true
+		}
+
+		s.mu.Lock()
+		kept := set.archive.Segments[:0]
+		for _, seg := range set.archive.Segments {
+			if !deleted[seg.Name] {
+				kept = append(kept, seg)
+			}
+		}
+		set.archive.Segments = kept
+		s.mu.Unlock()
+	}
+	s.saveArchives()
+	return errors.Join(errs...)
+}
+
+// saveArchives persists the archive index to JSON file.
+func (s *Service) saveArchives() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := json.MarshalIndent(s.archives, "", "  ")
+	if err != nil {
+		logger.Error("Failed to marshal WAL archives", "error", err)
+		return
+	}
+
+	if err := os.WriteFile(s.config.ArchivesPath, data, 0600); err != nil {
+		logger.Error("Failed to save WAL archives", "error", err)
+	}
+}
+
+// loadArchives loads the archive index from JSON file.
+func (s *Service) loadArchives() error {
+	data, err := os.ReadFile(s.config.ArchivesPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	return json.Unmarshal(data, &s.archives)
+}
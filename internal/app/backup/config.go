@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/homeport/homeport/internal/app/secrets"
+)
+
+// ConfigFromEnv builds the backup configuration from the environment:
+//
+//   - HOMEPORT_BACKUP_DESTINATION selects an off-site destination: "local"
+//     (HOMEPORT_BACKUP_PATH), "s3" (HOMEPORT_BACKUP_S3_ENDPOINT, _BUCKET,
+//     _REGION, _ACCESS_KEY, _SECRET_KEY, _USE_SSL, HOMEPORT_BACKUP_PATH as key
+//     prefix) or "sftp" (HOMEPORT_BACKUP_SFTP_HOST, _PORT, _USER, _PASSWORD,
+//     _KEY, _HOST_KEY, _KNOWN_HOSTS, HOMEPORT_BACKUP_PATH as remote directory).
+//   - HOMEPORT_BACKUP_KEY_SECRET ("stack/name") encrypts archives with a key
+//     from the secrets service; HOMEPORT_BACKUP_KEY gives the key directly.
+//   - HOMEPORT_BACKUP_KEEP_LAST, _KEEP_DAILY, _KEEP_WEEKLY and _KEEP_MONTHLY set
+//     the retention policy.
+//   - HOMEPORT_BACKUP_WEBHOOK_URL receives failed scheduled backup
+//     notifications.
+//   - HOMEPORT_BACKUP_ARCHIVE_WAL=true archives the WAL or binlogs of backed
+//     up databases every HOMEPORT_BACKUP_ARCHIVE_INTERVAL (default 5m) for
+//     point-in-time restores.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{}
+
+	if destType := os.Getenv("HOMEPORT_BACKUP_DESTINATION"); destType != "" {
+		port, err := envInt("HOMEPORT_BACKUP_SFTP_PORT")
+		if err != nil {
+			return nil, err
+		}
+		cfg.Destination = &DestinationConfig{
+			Type:            destType,
+			Path:            os.Getenv("HOMEPORT_BACKUP_PATH"),
+			Endpoint:        os.Getenv("HOMEPORT_BACKUP_S3_ENDPOINT"),
+			Bucket:          os.Getenv("HOMEPORT_BACKUP_S3_BUCKET"),
+			Region:          os.Getenv("HOMEPORT_BACKUP_S3_REGION"),
+			AccessKeyID:     os.Getenv("HOMEPORT_BACKUP_S3_ACCESS_KEY"),
+			SecretAccessKey: os.Getenv("HOMEPORT_BACKUP_S3_SECRET_KEY"),
+			UseSSL:          os.Getenv("HOMEPORT_BACKUP_S3_USE_SSL") != "false",
+			Host:            os.Getenv("HOMEPORT_BACKUP_SFTP_HOST"),
+			Port:            port,
+			Username:        os.Getenv("HOMEPORT_BACKUP_SFTP_USER"),
+			Password:        os.Getenv("HOMEPORT_BACKUP_SFTP_PASSWORD"),
+			KeyPath:         os.Getenv("HOMEPORT_BACKUP_SFTP_KEY"),
+			HostKey:         os.Getenv("HOMEPORT_BACKUP_SFTP_HOST_KEY"),
+			KnownHostsPath:  os.Getenv("HOMEPORT_BACKUP_SFTP_KNOWN_HOSTS"),
+		}
+	}
+
+	if ref := os.Getenv("HOMEPORT_BACKUP_KEY_SECRET"); ref != "" {
+		stackID, name, ok := strings.Cut(ref, "/")
+		if !ok || stackID == "" || name == "" {
+			return nil, fmt.Errorf("HOMEPORT_BACKUP_KEY_SECRET must be stack/name, got %q", ref)
+		}
+		secretsService, err := secrets.NewService(secrets.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open secrets service: %w", err)
+		}
+		cfg.KeySource = &SecretKeySource{Secrets: secretsService, StackID: stackID, Name: name}
+	} else if key := os.Getenv("HOMEPORT_BACKUP_KEY"); key != "" {
+		cfg.KeySource = StaticKey(key)
+	}
+
+	var policy RetentionPolicy
+	for name, field := range map[string]*int{
+		"HOMEPORT_BACKUP_KEEP_LAST":    &policy.KeepLast,
+		"HOMEPORT_BACKUP_KEEP_DAILY":   &policy.KeepDaily,
+		"HOMEPORT_BACKUP_KEEP_WEEKLY":  &policy.KeepWeekly,
+		"HOMEPORT_BACKUP_KEEP_MONTHLY": &policy.KeepMonthly,
+	} {
+		value, err := envInt(name)
+		if err != nil {
+			return nil, err
+		}
+		*field = value
+	}
+	if !policy.IsZero() {
+		cfg.Retention = &policy
+	}
+
+	if url := os.Getenv("HOMEPORT_BACKUP_WEBHOOK_URL"); url != "" {
+		cfg.Notifier = &WebhookNotifier{URL: url}
+	}
+
+	cfg.ArchiveWAL = os.Getenv("HOMEPORT_BACKUP_ARCHIVE_WAL") == "true"
+	if value := os.Getenv("HOMEPORT_BACKUP_ARCHIVE_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("HOMEPORT_BACKUP_ARCHIVE_INTERVAL must be a positive duration, got %q", value)
+		}
+		cfg.ArchiveInterval = interval
+	}
+
+	return cfg, nil
+}
+
+func envInt(name string) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, value)
+	}
+	return n, nil
+}
@@ -0,0 +1,563 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/homeport/homeport/internal/pkg/logger"
+)
+
+// Backup methods
+const (
+	MethodVolume       = "volume"        // Raw copy of the volume
+	MethodPGBaseBackup = "pg_basebackup" // Physical base backup of a PostgreSQL cluster
+	MethodMySQLDump    = "mysqldump"     // Logical dump of a MySQL or MariaDB server
+)
+
+// Database engines
+const (
+	EnginePostgres = "postgres"
+	EngineMySQL    = "mysql"
+	EngineMariaDB  = "mariadb"
+)
+
+// mysqlDumpFile is the archive entry holding a logical dump, under the volume
+// directory.
+const mysqlDumpFile = "homeport-dump.sql"
+
+// DatabaseBackup records how a database volume was backed up and where point-in-time
+// recovery starts.
+type DatabaseBackup struct {
+	Engine     string `json:"engine"`
+	Method     string `json:"method"`
+	Container  string `json:"container"`
+	MountPath  string `json:"mount_path,omitempty"`  // Volume mount point in the container
+	DataDir    string `json:"data_dir,omitempty"`    // Data directory relative to the volume root (PostgreSQL)
+	StartWAL   string `json:"start_wal,omitempty"`   // First WAL segment needed for recovery (PostgreSQL)
+	BinlogFile string `json:"binlog_file,omitempty"` // Binlog coordinates of the dump (MySQL)
+	BinlogPos  int64  `json:"binlog_pos,omitempty"`
+}
+
+// databaseContainer is a running database server mounting a volume.
+type databaseContainer struct {
+	ID        string
+	Name      string
+	Engine    string
+	MountPath string
+	Env       map[string]string
+}
+
+// pgUser returns the PostgreSQL superuser.
+func (d *databaseContainer) pgUser() string {
+	if user := d.Env["POSTGRES_USER"]; user != "" {
+		return user
+	}
+	return "postgres"
+}
+
+// pgData returns the PostgreSQL data directory.
+func (d *databaseContainer) pgData() string {
+	if dir := d.Env["PGDATA"]; dir != "" {
+		return path.Clean(dir)
+	}
+	return "/var/lib/postgresql/data"
+}
+
+// detectEngine returns the database engine of a container from its
+// homeport.engine label, or from its image name.
+func detectEngine(labels map[string]string, image string) string {
+	switch engine := strings.ToLower(labels["homeport.engine"]); {
+	case strings.Contains(engine, "postgres"):
+		return EnginePostgres
+	case strings.Contains(engine, "mariadb"):
+		return EngineMariaDB
+	case strings.Contains(engine, "mysql"):
+		return EngineMySQL
+	}
+
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	switch name {
+	case "postgres", "postgis", "timescaledb", "timescaledb-ha":
+		return EnginePostgres
+	case "mariadb":
+		return EngineMariaDB
+	case "mysql", "mysql-server", "percona", "percona-server":
+		return EngineMySQL
+	}
+	return ""
+}
+
+// findDatabaseContainer returns the running database server mounting a
+// volume, or nil when the volume does not belong to one.
+func (s *Service) findDatabaseContainer(ctx context.Context, volumeName string) (*databaseContainer, error) {
+	containers, err := s.dockerClient.ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("volume", volumeName), filters.Arg("status", "running")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	for _, c := range containers {
+		engine := detectEngine(c.Labels, c.Image)
+		if engine == "" {
+			continue
+		}
+
+		db := &databaseContainer{ID: c.ID, Engine: engine, Env: make(map[string]string)}
+		if len(c.Names) > 0 {
+			db.Name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		for _, m := range c.Mounts {
+			if m.Name == volumeName {
+				db.MountPath = m.Destination
+			}
+		}
+
+		info, err := s.dockerClient.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", db.Name, err)
+		}
+		if info.Config != nil {
+			for _, kv := range info.Config.Env {
+				if k, v, ok := strings.Cut(kv, "="); ok {
+					db.Env[k] = v
+				}
+			}
+		}
+		return db, nil
+	}
+	return nil, nil
+}
+
+// execInContainer runs cmd in a container, streaming stdin and stdout. A
+// non-zero exit status is an error carrying the end of stderr.
+func (s *Service) execInContainer(ctx context.Context, containerID string, cmd []string, stdin io.Reader, stdout io.Writer) error {
+	execResp, err := s.dockerClient.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Cmd:          cmd,
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attach, err := s.dockerClient.ContainerExecAttach(ctx, execResp.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer attach.Close()
+
+	if stdin != nil {
+		go func() {
+			_, _ = io.Copy(attach.Conn, stdin)
+			_ = attach.CloseWrite()
+		}()
+	}
+	if stdout == nil {
+		stdout = io.Discard
+	}
+
+	var stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(stdout, &stderr, attach.Reader); err != nil {
+		return fmt.Errorf("%s: %w", cmd[0], err)
+	}
+
+	inspect, err := s.dockerClient.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	if inspect.ExitCode != 0 {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 512 {
+			msg = "..." + msg[len(msg)-512:]
+		}
+		return fmt.Errorf("%s exited with code %d: %s", cmd[0], inspect.ExitCode, msg)
+	}
+	return nil
+}
+
+// psql runs a query as the PostgreSQL superuser and returns the unaligned
+// output.
+func (s *Service) psql(ctx context.Context, db *databaseContainer, query string) (string, error) {
+	var out bytes.Buffer
+	cmd := []string{"psql", "-U", db.pgUser(), "-d", "postgres", "-v", "ON_ERROR_STOP=1", "-Atc", query}
+	if err := s.execInContainer(ctx, db.ID, cmd, nil, &out); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// mysqlShell runs a shell script in a MySQL or MariaDB container with the
+// root password in MYSQL_PWD and the client binaries in $CLIENT, $DUMP and
+// $BINLOG. Arguments are passed as $1, $2, ...
+func mysqlShell(script string, args ...string) []string {
+	prelude := `export MYSQL_PWD="${MARIADB_ROOT_PASSWORD:-$MYSQL_ROOT_PASSWORD}"; ` +
+		`CLIENT="$(command -v mariadb || command -v mysql)"; ` +
+		`DUMP="$(command -v mariadb-dump || command -v mysqldump)"; ` +
+		`BINLOG="$(command -v mariadb-binlog || command -v mysqlbinlog)"; `
+	return append([]string{"sh", "-c", prelude + script, "sh"}, args...)
+}
+
+// mysqlQuery runs a query as root and returns the tab-separated rows.
+func (s *Service) mysqlQuery(ctx context.Context, db *databaseContainer, query string) ([][]string, error) {
+	var out bytes.Buffer
+	cmd := mysqlShell(`exec "$CLIENT" -uroot -N -B -e "$1"`, query)
+	if err := s.execInContainer(ctx, db.ID, cmd, nil, &out); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			rows = append(rows, strings.Split(line, "\t"))
+		}
+	}
+	return rows, scanner.Err()
+}
+
+// backupDatabaseVolume backs up a volume with the method suited to the
+// database server mounting it. It returns nil without writing anything when
+// no running database server mounts the volume, in which case a raw copy is
+// consistent.
+func (s *Service) backupDatabaseVolume(ctx context.Context, volumeName string, tarWriter *tar.Writer) (*DatabaseBackup, error) {
+	db, err := s.findDatabaseContainer(ctx, volumeName)
+	if err != nil || db == nil {
+		return nil, err
+	}
+
+	switch db.Engine {
+	case EnginePostgres:
+		return s.backupPostgres(ctx, db, volumeName, tarWriter)
+	default:
+		return s.backupMySQL(ctx, db, volumeName, tarWriter)
+	}
+}
+
+// backupPostgres streams pg_basebackup into the volume directory of the
+// archive, at the location of PGDATA within the volume.
+func (s *Service) backupPostgres(ctx context.Context, db *databaseContainer, volumeName string, tarWriter *tar.Writer) (*DatabaseBackup, error) {
+	rel, ok := relativeTo(db.MountPath, db.pgData())
+	if !ok {
+		return nil, fmt.Errorf("PGDATA %s of %s is not on volume %s", db.pgData(), db.Name, volumeName)
+	}
+
+	// The archive slot must exist before the base backup so that no WAL is
+	// missed between the two
+	if s.config.ArchiveWAL {
+		if err := s.ensureArchiveSlot(ctx, db); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &DatabaseBackup{Engine: db.Engine, Method: MethodPGBaseBackup, Container: db.Name, MountPath: db.MountPath, DataDir: rel}
+
+	if err := tarWriter.WriteHeader(&tar.Header{Name: volumeName + "/", Mode: 0755, Typeflag: tar.TypeDir, ModTime: time.Now()}); err != nil {
+		return nil, fmt.Errorf("failed to write tar header: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	execErr := make(chan error, 1)
+	go func() {
+		cmd := []string{"pg_basebackup", "-U", db.pgUser(), "-D", "-", "-Ft", "-X", "fetch", "-c", "fast", "--no-password"}
+		err := s.execInContainer(ctx, db.ID, cmd, nil, pw)
+		_ = pw.CloseWithError(err)
+		execErr <- err
+	}()
+
+	dataDirWritten := rel == "."
+	innerTar := tar.NewReader(pr)
+	for {
+		header, err := innerTar.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = pr.CloseWithError(err)
+			<-execErr
+			return nil, fmt.Errorf("pg_basebackup: %w", err)
+		}
+
+		// PGDATA itself is not in the stream; create it with the owner of
+		// the first entry
+		if !dataDirWritten {
+			dirHeader := &tar.Header{
+				Name:     volumeName + "/" + rel + "/",
+				Mode:     0700,
+				Typeflag: tar.TypeDir,
+				Uid:      header.Uid,
+				Gid:      header.Gid,
+				ModTime:  time.Now(),
+			}
+			if err := tarWriter.WriteHeader(dirHeader); err != nil {
+				_ = pr.CloseWithError(err)
+				<-execErr
+				return nil, err
+			}
+			dataDirWritten = true
+		}
+
+		var content io.Reader = innerTar
+		if header.Name == "backup_label" {
+			label, err := io.ReadAll(innerTar)
+			if err != nil {
+				_ = pr.CloseWithError(err)
+				<-execErr
+				return nil, err
+			}
+			result.StartWAL = parseBackupLabel(label)
+			content = bytes.NewReader(label)
+		}
+
+		isDir := header.Typeflag == tar.TypeDir
+		header.Name = volumeName + "/" + path.Join(rel, header.Name)
+		if isDir {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			_ = pr.CloseWithError(err)
+			<-execErr
+			return nil, fmt.Errorf("failed to write header: %w", err)
+		}
+		if _, err := io.Copy(tarWriter, content); err != nil {
+			_ = pr.CloseWithError(err)
+			<-execErr
+			return nil, fmt.Errorf("failed to copy base backup: %w", err)
+		}
+	}
+
+	if err := <-execErr; err != nil {
+		return nil, err
+	}
+	logger.Info("PostgreSQL base backup taken", "volume", volumeName, "container", db.Name, "start_wal", result.StartWAL)
+	return result, nil
+}
+
+var backupLabelWAL = regexp.MustCompile(`START WAL LOCATION: \S+ \(file ([0-9A-F]{24})\)`)
+
+// parseBackupLabel returns the first WAL segment of a base backup.
+func parseBackupLabel(label []byte) string {
+	if m := backupLabelWAL.FindSubmatch(label); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// backupMySQL writes a consistent logical dump of all databases into the
+// volume directory of the archive, recording the binlog coordinates when
+// binary logging is enabled.
+func (s *Service) backupMySQL(ctx context.Context, db *databaseContainer, volumeName string, tarWriter *tar.Writer) (*DatabaseBackup, error) {
+	result := &DatabaseBackup{Engine: db.Engine, Method: MethodMySQLDump, Container: db.Name, MountPath: db.MountPath}
+
+	rows, err := s.mysqlQuery(ctx, db, "SELECT @@log_bin")
+	if err != nil {
+		return nil, err
+	}
+	binlog := len(rows) > 0 && len(rows[0]) > 0 && rows[0][0] == "1"
+
+	// Dumps are spooled to disk since tar headers need the size upfront
+	spool, err := os.CreateTemp(s.config.BackupDir, ".dump-*.sql")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+
+	script := `exec "$DUMP" -uroot --all-databases --single-transaction --quick --routines --events --triggers`
+	if binlog {
+		// --source-data replaces --master-data on recent MySQL versions
+		script = `OPT=--master-data=2; "$DUMP" --help | grep -q -- --source-data && OPT=--source-data=2; ` +
+			script + ` --flush-logs "$OPT"`
+	}
+	head := &headBuffer{limit: 64 * 1024}
+	if err := s.execInContainer(ctx, db.ID, mysqlShell(script), nil, io.MultiWriter(spool, head)); err != nil {
+		return nil, err
+	}
+	if binlog {
+		result.BinlogFile, result.BinlogPos = parseBinlogCoordinates(head.Bytes())
+	}
+
+	info, err := spool.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := tarWriter.WriteHeader(&tar.Header{Name: volumeName + "/", Mode: 0755, Typeflag: tar.TypeDir, ModTime: now}); err != nil {
+		return nil, err
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{Name: volumeName + "/" + mysqlDumpFile, Mode: 0600, Size: info.Size(), Typeflag: tar.TypeReg, ModTime: now}); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(tarWriter, spool); err != nil {
+		return nil, err
+	}
+
+	logger.Info("MySQL dump taken", "volume", volumeName, "container", db.Name, "binlog_file", result.BinlogFile, "binlog_pos", result.BinlogPos)
+	return result, nil
+}
+
+var binlogCoordinates = regexp.MustCompile(`(?:MASTER|SOURCE)_LOG_FILE='([^']+)',\s*(?:MASTER|SOURCE)_LOG_POS=(\d+)`)
+
+// parseBinlogCoordinates returns the binlog position recorded in a dump
+// header by --source-data or --master-data.
+func parseBinlogCoordinates(dump []byte) (string, int64) {
+	m := binlogCoordinates.FindSubmatch(dump)
+	if m == nil {
+		return "", 0
+	}
+	pos, _ := strconv.ParseInt(string(m[2]), 10, 64)
+	return string(m[1]), pos
+}
+
+// headBuffer keeps the first bytes written to it.
+type headBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (h *headBuffer) Write(p []byte) (int, error) {
+	if room := h.limit - h.Len(); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		h.Buffer.Write(p[:room])
+	}
+	return len(p), nil
+}
+
+// relativeTo returns target relative to base when target is base or below it.
+func relativeTo(base, target string) (string, bool) {
+	base, target = path.Clean(base), path.Clean(target)
+	if base == "" || base == "." {
+		return "", false
+	}
+	if target == base {
+		return ".", true
+	}
+	if strings.HasPrefix(target, base+"/") {
+		return strings.TrimPrefix(target, base+"/"), true
+	}
+	return "", false
+}
+
+// pgRecoveryConfig returns the settings appended to postgresql.auto.conf to
+// replay archived WAL up to target.
+func pgRecoveryConfig(walDir string, target time.Time) string {
+	return fmt.Sprintf(`
+# Added by homeport for point-in-time recovery
+restore_command = 'cp "%s/%%f" "%%p"'
+recovery_target_time = '%s+00'
+recovery_target_action = 'promote'
+`, walDir, target.UTC().Format("2006-01-02 15:04:05.000000"))
+}
+
+// pgRestoreWALDir is the directory, relative to PGDATA, holding the WAL
+// replayed by a point-in-time recovery.
+const pgRestoreWALDir = "homeport_restore_wal"
+
+// restoreMySQLDump loads the dump of a volume into the running server, then
+// replays archived binlogs up to target when one is given.
+func (s *Service) restoreMySQLDump(ctx context.Context, volumeName string, backup *Backup, record *DatabaseBackup, target *time.Time) error {
+	db, err := s.findDatabaseContainer(ctx, volumeName)
+	if err != nil {
+		return err
+	}
+	if db == nil {
+		return fmt.Errorf("no running database server mounts volume %s; start it before restoring a logical dump", volumeName)
+	}
+
+	// Archive the binlogs written since the last pass before the import
+	// writes to the server
+	if target != nil {
+		if err := s.archiveVolume(ctx, volumeName, true); err != nil {
+			logger.Warn("Failed to archive latest binlogs before restore", "volume", volumeName, "error", err)
+		}
+	}
+
+	archive, err := s.openArchive(ctx, backup)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = archive.Close() }()
+
+	tarReader := tar.NewReader(archive)
+	entry := volumeName + "/" + mysqlDumpFile
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return fmt.Errorf("backup has no dump for volume %s", volumeName)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar: %w", err)
+		}
+		if header.Name == entry {
+			break
+		}
+	}
+
+	importCmd := mysqlShell(`exec "$CLIENT" -uroot --init-command="SET sql_log_bin=0"`)
+	if err := s.execInContainer(ctx, db.ID, importCmd, tarReader, nil); err != nil {
+		return fmt.Errorf("failed to import dump: %w", err)
+	}
+	logger.Info("MySQL dump restored", "volume", volumeName, "container", db.Name)
+
+	if target == nil {
+		return nil
+	}
+	return s.replayBinlogs(ctx, db, volumeName, record, *target)
+}
+
+// stopVolumeContainers stops the running containers mounting a volume and
+// returns their IDs.
+func (s *Service) stopVolumeContainers(ctx context.Context, volumeName string) ([]string, error) {
+	containers, err := s.dockerClient.ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("volume", volumeName), filters.Arg("status", "running")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var stopped []string
+	for _, c := range containers {
+		if err := s.dockerClient.ContainerStop(ctx, c.ID, container.StopOptions{}); err != nil {
+			return stopped, fmt.Errorf("failed to stop container %s: %w", c.ID[:12], err)
+		}
+		stopped = append(stopped, c.ID)
+	}
+	return stopped, nil
+}
+
+// startContainers starts containers stopped for a restore.
+func (s *Service) startContainers(ctx context.Context, ids []string) {
+	for _, id := range ids {
+		if err := s.dockerClient.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+			logger.Error("Failed to restart container after restore", "container", id, "error", err)
+		}
+	}
+}
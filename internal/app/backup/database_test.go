@@ -0,0 +1,207 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectEngine(t *testing.T) {
+	tests := []struct {
+		labels map[string]string
+		image  string
+		want   string
+	}{
+		{map[string]string{"homeport.engine": "postgres"}, "custom/db:1", EnginePostgres},
+		{map[string]string{"homeport.engine": "mariadb"}, "", EngineMariaDB},
+		{map[string]string{"homeport.engine": "mysql"}, "", EngineMySQL},
+		{nil, "postgres:16-alpine", EnginePostgres},
+		{nil, "docker.io/postgis/postgis:16-3.4", EnginePostgres},
+		{nil, "mariadb:11", EngineMariaDB},
+		{nil, "mysql@sha256:abc", EngineMySQL},
+		{nil, "redis:7", ""},
+		{nil, "my-postgres-exporter:latest", ""},
+	}
+	for _, tt := range tests {
+		if got := detectEngine(tt.labels, tt.image); got != tt.want {
+			t.Errorf("detectEngine(%v, %q) = %q, want %q", tt.labels, tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestParseBackupLabel(t *testing.T) {
+	label := []byte("START WAL LOCATION: 0/2000028 (file 000000010000000000000002)\n" +
+		"CHECKPOINT LOCATION: 0/2000060\nBACKUP METHOD: streamed\n")
+	if got := parseBackupLabel(label); got != "000000010000000000000002" {
+		t.Errorf("parseBackupLabel() = %q", got)
+	}
+	if got := parseBackupLabel([]byte("BACKUP METHOD: streamed\n")); got != "" {
+		t.Errorf("parseBackupLabel() without location = %q", got)
+	}
+}
+
+func TestParseBinlogCoordinates(t *testing.T) {
+	tests := []struct {
+		dump string
+		file string
+		pos  int64
+	}{
+		{"-- CHANGE MASTER TO MASTER_LOG_FILE='mysql-bin.000003', MASTER_LOG_POS=157;\n", "mysql-bin.000003", 157},
+		{"-- CHANGE REPLICATION SOURCE TO SOURCE_LOG_FILE='binlog.000012', SOURCE_LOG_POS=4;\n", "binlog.000012", 4},
+		{"-- MySQL dump 10.13\n", "", 0},
+	}
+	for _, tt := range tests {
+		file, pos := parseBinlogCoordinates([]byte(tt.dump))
+		if file != tt.file || pos != tt.pos {
+			t.Errorf("parseBinlogCoordinates(%q) = %q, %d, want %q, %d", tt.dump, file, pos, tt.file, tt.pos)
+		}
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	tests := []struct {
+		base, target string
+		want         string
+		ok           bool
+	}{
+		{"/var/lib/postgresql/data", "/var/lib/postgresql/data", ".", true},
+		{"/var/lib/postgresql", "/var/lib/postgresql/data/pgdata", "data/pgdata", true},
+		{"/var/lib/postgresql/data", "/var/lib/postgresql/data2", "", false},
+		{"/var/lib/postgresql/data", "/var/lib/postgresql", "", false},
+		{"", "/data", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := relativeTo(tt.base, tt.target)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("relativeTo(%q, %q) = %q, %v, want %q, %v", tt.base, tt.target, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHeadBuffer(t *testing.T) {
+	h := &headBuffer{limit: 8}
+	for _, chunk := range []string{"abc", "defgh", "ijk"} {
+		if n, err := h.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	if h.String() != "abcdefgh" {
+		t.Errorf("head = %q", h.String())
+	}
+}
+
+func TestPGRecoveryConfig(t *testing.T) {
+	target := time.Date(2026, 5, 4, 14, 30, 0, 500000000, time.FixedZone("CEST", 2*3600))
+	conf := pgRecoveryConfig("/var/lib/postgresql/data/homeport_restore_wal", target)
+
+	for _, want := range []string{
+		`restore_command = 'cp "/var/lib/postgresql/data/homeport_restore_wal/%f" "%p"'`,
+		`recovery_target_time = '2026-05-04 12:30:00.500000+00'`,
+		`recovery_target_action = 'promote'`,
+	} {
+		if !strings.Contains(conf, want) {
+			t.Errorf("recovery config misses %q:\n%s", want, conf)
+		}
+	}
+}
+
+func TestSegmentsFromAndPruneArchives(t *testing.T) {
+	svc := newTestService(t, nil)
+	ctx := context.Background()
+
+	names := []string{
+		"000000010000000000000001",
+		"000000010000000000000002",
+		"00000002.history",
+		"000000020000000000000003",
+		"000000020000000000000004",
+	}
+	archive := &WALArchive{Volume: "shop_pgdata", Engine: EnginePostgres}
+	for _, name := range names {
+		seg, err := svc.uploadSegment(ctx, "shop_pgdata", name, strings.NewReader("wal "+name))
+		if err != nil {
+			t.Fatalf("uploadSegment(%s) error = %v", name, err)
+		}
+		archive.Segments = append(archive.Segments, seg)
+	}
+	svc.archives["shop_pgdata"] = archive
+
+	var got []string
+	for _, seg := range svc.segmentsFrom("shop_pgdata", "000000020000000000000003") {
+		got = append(got, seg.Name)
+	}
+	want := "00000002.history 000000020000000000000003 000000020000000000000004"
+	if strings.Join(got, " ") != want {
+		t.Errorf("segmentsFrom() = %v, want %s", got, want)
+	}
+
+	svc.backups["b1"] = &Backup{
+		ID:     "b1",
+		Status: BackupStatusCompleted,
+		Databases: map[string]*DatabaseBackup{
+			"shop_pgdata": {Engine: EnginePostgres, Method: MethodPGBaseBackup, StartWAL: "000000010000000000000002"},
+		},
+	}
+	if err := svc.pruneArchives(ctx); err != nil {
+		t.Fatalf("pruneArchives() error = %v", err)
+	}
+	if len(archive.Segments) != 4 || archive.Segments[0].Name != "000000010000000000000002" {
+		t.Errorf("segments after prune = %v", archive.Segments)
+	}
+}
+
+func TestWriteRecoveryWAL(t *testing.T) {
+	svc := newTestService(t, nil)
+	ctx := context.Background()
+	record := &DatabaseBackup{Engine: EnginePostgres, Method: MethodPGBaseBackup, DataDir: "data", StartWAL: "000000010000000000000002"}
+	owner := &tar.Header{Uid: 999, Gid: 999}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := svc.writeRecoveryWAL(ctx, tw, "shop_pgdata", record, owner); !errors.Is(err, ErrPITRUnavailable) {
+		t.Fatalf("writeRecoveryWAL() without archive error = %v", err)
+	}
+
+	seg, err := svc.uploadSegment(ctx, "shop_pgdata", "000000010000000000000002", strings.NewReader("segment"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.archives["shop_pgdata"] = &WALArchive{Volume: "shop_pgdata", Segments: []ArchivedSegment{seg}}
+
+	buf.Reset()
+	tw = tar.NewWriter(&buf)
+	if err := svc.writeRecoveryWAL(ctx, tw, "shop_pgdata", record, owner); err != nil {
+		t.Fatalf("writeRecoveryWAL() error = %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(&buf)
+	files := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Uid != 999 {
+			t.Errorf("%s owned by %d", header.Name, header.Uid)
+		}
+		data, _ := io.ReadAll(tr)
+		files[header.Name] = string(data)
+	}
+	if files["data/homeport_restore_wal/000000010000000000000002"] != "segment" {
+		t.Errorf("restore archive = %v", files)
+	}
+	if _, ok := files["data/homeport_restore_wal/"]; !ok {
+		t.Errorf("restore archive misses the WAL directory: %v", files)
+	}
+}
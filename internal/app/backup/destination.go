@@ -0,0 +1,439 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Destination types
+const (
+	DestinationLocal = "local"
+	DestinationS3    = "s3"
+	DestinationSFTP  = "sftp"
+)
+
+// Destination stores backup archives. Keys are slash-separated paths relative
+// to the destination root.
+type Destination interface {
+	// Type returns the destination type
+	Type() string
+
+	// Location returns a human-readable location of a key, such as
+	// s3://bucket/prefix/key
+	Location(key string) string
+
+	// Upload stores size bytes read from r under key
+	Upload(ctx context.Context, key string, r io.Reader, size int64) error
+
+	// Download opens the archive stored under key
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the archive stored under key. Deleting a missing key is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// DestinationConfig configures where backups are shipped.
+type DestinationConfig struct {
+	// Type is local, s3 or sftp
+	Type string `json:"type"`
+
+	// Path is the root directory for local and SFTP destinations, and the key
+	// prefix for S3 destinations
+	Path string `json:"path,omitempty"`
+
+	// S3-compatible endpoint (MinIO, Scaleway, OVH, Exoscale SOS, ...)
+	Endpoint        string `json:"endpoint,omitempty"`
+	Bucket          string `json:"bucket,omitempty"`
+	Region          string `json:"region,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"-"`
+	UseSSL          bool   `json:"use_ssl,omitempty"`
+
+	// SFTP server
+	Host           string `json:"host,omitempty"`
+	Port           int    `json:"port,omitempty"`
+	Username       string `json:"username,omitempty"`
+	Password       string `json:"-"`
+	KeyPath        string `json:"key_path,omitempty"`
+	HostKey        string `json:"host_key,omitempty"`         // authorized_keys format
+	KnownHostsPath string `json:"known_hosts_path,omitempty"` // used when HostKey is empty
+}
+
+// NewDestination creates the destination described by cfg.
+func NewDestination(cfg *DestinationConfig) (Destination, error) {
+	switch cfg.Type {
+	case DestinationLocal, "":
+		return NewLocalDestination(cfg.Path)
+	case DestinationS3:
+		return NewS3Destination(cfg)
+	case DestinationSFTP:
+		return NewSFTPDestination(cfg)
+	default:
+		return nil, fmt.Errorf("unknown backup destination type: %s", cfg.Type)
+	}
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Local
+// ─────────────────────────────────────────────────────────────────────────────
+
+// LocalDestination stores archives in a local directory, such as a mounted
+// NAS share.
+type LocalDestination struct {
+	dir string
+}
+
+// NewLocalDestination creates a local destination rooted at dir.
+func NewLocalDestination(dir string) (*LocalDestination, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("local destination requires a path")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	return &LocalDestination{dir: dir}, nil
+}
+
+// Type returns "local".
+func (d *LocalDestination) Type() string { return DestinationLocal }
+
+// Location returns the file path of a key.
+func (d *LocalDestination) Location(key string) string {
+	return d.path(key)
+}
+
+func (d *LocalDestination) path(key string) string {
+	return filepath.Join(d.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Upload writes the archive atomically.
+func (d *LocalDestination) Upload(ctx context.Context, key string, r io.Reader, size int64) error {
+	target := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// Download opens the archive.
+func (d *LocalDestination) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(d.path(key))
+}
+
+// Delete removes the archive.
+func (d *LocalDestination) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(d.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// S3-compatible object storage
+// ─────────────────────────────────────────────────────────────────────────────
+
+// S3Destination stores archives in an S3-compatible bucket.
+type S3Destination struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Destination creates an S3 destination. The bucket is created when it
+// does not exist.
+func NewS3Destination(cfg *DestinationConfig) (*S3Destination, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 destination requires an endpoint and a bucket")
+	}
+	endpoint := cfg.Endpoint
+	secure := cfg.UseSSL
+	if strings.HasPrefix(endpoint, "https://") {
+		secure = true
+	}
+	endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: secure,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket %s: %w", cfg.Bucket, err)
+		}
+	}
+
+	return &S3Destination{client: client, bucket: cfg.Bucket, prefix: strings.Trim(cfg.Path, "/")}, nil
+}
+
+// Type returns "s3".
+func (d *S3Destination) Type() string { return DestinationS3 }
+
+// Location returns the s3:// URL of a key.
+func (d *S3Destination) Location(key string) string {
+	return fmt.Sprintf("s3://%s/%s", d.bucket, d.objectName(key))
+}
+
+func (d *S3Destination) objectName(key string) string {
+	if d.prefix == "" {
+		return key
+	}
+	return d.prefix + "/" + key
+}
+
+// Upload puts the archive.
+func (d *S3Destination) Upload(ctx context.Context, key string, r io.Reader, size int64) error {
+	_, err := d.client.PutObject(ctx, d.bucket, d.objectName(key), r, size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	return err
+}
+
+// Download gets the archive.
+func (d *S3Destination) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := d.client.GetObject(ctx, d.bucket, d.objectName(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy: surface a missing object now
+	if _, err := obj.Stat(); err != nil {
+		_ = obj.Close()
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Delete removes the archive.
+func (d *S3Destination) Delete(ctx context.Context, key string) error {
+	return d.client.RemoveObject(ctx, d.bucket, d.objectName(key), minio.RemoveObjectOptions{})
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// SFTP
+// ─────────────────────────────────────────────────────────────────────────────
+
+// SFTPDestination stores archives on an SFTP server. A connection is opened
+// for each operation, since backups are infrequent.
+type SFTPDestination struct {
+	cfg       *DestinationConfig
+	sshConfig *ssh.ClientConfig
+	root      string
+}
+
+// NewSFTPDestination creates an SFTP destination. The server host key must be
+// given or listed in a known_hosts file.
+func NewSFTPDestination(cfg *DestinationConfig) (*SFTPDestination, error) {
+	if cfg.Host == "" || cfg.Username == "" {
+		return nil, fmt.Errorf("sftp destination requires a host and a username")
+	}
+
+	var auth ssh.AuthMethod
+	if cfg.Password != "" {
+		auth = ssh.Password(cfg.Password)
+	} else {
+		keyPath := cfg.KeyPath
+		if keyPath == "" {
+			home, _ := os.UserHomeDir()
+			keyPath = filepath.Join(home, ".ssh", "id_ed25519")
+		}
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSH key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SSH key: %w", err)
+		}
+		auth = ssh.PublicKeys(signer)
+	}
+
+	hostKeyCallback, err := sftpHostKeyCallback(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	root := cfg.Path
+	if root == "" {
+		root = "homeport-backups"
+	}
+
+	return &SFTPDestination{
+		cfg: cfg,
+		sshConfig: &ssh.ClientConfig{
+			User:            cfg.Username,
+			Auth:            []ssh.AuthMethod{auth},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         30 * time.Second,
+		},
+		root: root,
+	}, nil
+}
+
+// sftpHostKeyCallback verifies the server against the configured host key,
+// or against known_hosts.
+func sftpHostKeyCallback(cfg *DestinationConfig) (ssh.HostKeyCallback, error) {
+	if cfg.HostKey != "" {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SFTP host key: %w", err)
+		}
+		return ssh.FixedHostKey(key), nil
+	}
+
+	knownHostsPath := cfg.KnownHostsPath
+	if knownHostsPath == "" {
+		home, _ := os.UserHomeDir()
+		knownHostsPath = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(knownHostsPath)
+	if err != nil {
+		return nil, fmt.Errorf("sftp destination needs a host key or a known_hosts file: %w", err)
+	}
+	return callback, nil
+}
+
+// Type returns "sftp".
+func (d *SFTPDestination) Type() string { return DestinationSFTP }
+
+// Location returns the sftp:// URL of a key.
+func (d *SFTPDestination) Location(key string) string {
+	return fmt.Sprintf("sftp://%s@%s/%s", d.cfg.Username, d.addr(), d.remotePath(key))
+}
+
+func (d *SFTPDestination) addr() string {
+	port := d.cfg.Port
+	if port == 0 {
+		port = 22
+	}
+	return net.JoinHostPort(d.cfg.Host, strconv.Itoa(port))
+}
+
+func (d *SFTPDestination) remotePath(key string) string {
+	return path.Join(d.root, path.Clean("/"+key))
+}
+
+// connect opens an SFTP session. Calling the returned function closes both
+// the SFTP and SSH clients.
+func (d *SFTPDestination) connect() (*sftp.Client, func(), error) {
+	conn, err := ssh.Dial("tcp", d.addr(), d.sshConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", d.addr(), err)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("failed to start SFTP session: %w", err)
+	}
+	return client, func() {
+		_ = client.Close()
+		_ = conn.Close()
+	}, nil
+}
+
+// Upload writes the archive to a temporary name and renames it into place.
+func (d *SFTPDestination) Upload(ctx context.Context, key string, r io.Reader, size int64) error {
+	client, closeFn, err := d.connect()
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	target := d.remotePath(key)
+	if err := client.MkdirAll(path.Dir(target)); err != nil {
+		return fmt.Errorf("failed to create remote directory: %w", err)
+	}
+	tmp := target + ".part"
+	f, err := client.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create remote file: %w", err)
+	}
+	if _, err := f.ReadFrom(r); err != nil {
+		_ = f.Close()
+		_ = client.Remove(tmp)
+		return fmt.Errorf("failed to upload: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = client.Remove(tmp)
+		return err
+	}
+	_ = client.Remove(target)
+	return client.Rename(tmp, target)
+}
+
+// Download opens the archive. The connection stays open until the returned
+// reader is closed.
+func (d *SFTPDestination) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	client, closeFn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	f, err := client.Open(d.remotePath(key))
+	if err != nil {
+		closeFn()
+		return nil, err
+	}
+	return &sftpReadCloser{File: f, closeFn: closeFn}, nil
+}
+
+// Delete removes the archive.
+func (d *SFTPDestination) Delete(ctx context.Context, key string) error {
+	client, closeFn, err := d.connect()
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	if err := client.Remove(d.remotePath(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+type sftpReadCloser struct {
+	*sftp.File
+	closeFn func()
+}
+
+func (r *sftpReadCloser) Close() error {
+	err := r.File.Close()
+	r.closeFn()
+	return err
+}
@@ -0,0 +1,258 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/homeport/homeport/internal/app/secrets"
+)
+
+// Encrypted archives are a header followed by AES-256-GCM sealed chunks:
+//
+//	magic (8) | salt (16) | nonce prefix (7) | chunk...
+//
+// Each chunk seals up to encChunkSize bytes of plaintext with the nonce
+// prefix, a big-endian chunk counter and a final-chunk flag, so that chunks
+// cannot be reordered, dropped or truncated. The archive key is derived from
+// the master key and the salt, giving every archive its own key.
+const (
+	encMagic       = "HPBKENC1"
+	encSaltSize    = 16
+	encPrefixSize  = 7
+	encChunkSize   = 64 * 1024
+	encHeaderSize  = len(encMagic) + encSaltSize + encPrefixSize
+	encSealedChunk = encChunkSize + 16 // GCM tag
+)
+
+// ErrDecryptFailed is returned when an archive cannot be authenticated with the
+// configured key.
+var ErrDecryptFailed = errors.New("backup decryption failed: wrong key or corrupted archive")
+
+// KeySource provides the master key used to encrypt backup archives.
+type KeySource interface {
+	// BackupKey returns the master key. Keys of any length are accepted and
+	// derived into an AES-256 key.
+	BackupKey(ctx context.Context) ([]byte, error)
+}
+
+// StaticKey is a KeySource returning a fixed key.
+type StaticKey []byte
+
+// BackupKey returns the key.
+func (k StaticKey) BackupKey(ctx context.Context) ([]byte, error) {
+	if len(k) == 0 {
+		return nil, fmt.Errorf("backup key is empty")
+	}
+	return k, nil
+}
+
+// SecretKeySource reads the backup key from the secrets service. The secret
+// holds the key as hex, base64 or a passphrase.
+type SecretKeySource struct {
+	Secrets *secrets.Service
+	StackID string
+	Name    string
+}
+
+// BackupKey fetches and decodes the secret.
+func (s *SecretKeySource) BackupKey(ctx context.Context) ([]byte, error) {
+	secret, err := s.Secrets.GetSecret(ctx, s.StackID, s.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup key %s/%s: %w", s.StackID, s.Name, err)
+	}
+	if secret.Value == "" {
+		return nil, fmt.Errorf("backup key %s/%s is empty", s.StackID, s.Name)
+	}
+	if key, err := hex.DecodeString(secret.Value); err == nil && len(key) >= 16 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(secret.Value); err == nil && len(key) >= 16 {
+		return key, nil
+	}
+	return []byte(secret.Value), nil
+}
+
+// deriveKey derives the AES-256 key of an archive from the master key.
+func deriveKey(master, salt []byte) []byte {
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte(encMagic))
+	mac.Write(salt)
+	return mac.Sum(nil)
+}
+
+func newGCM(master, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(deriveKey(master, salt))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce builds the nonce of a chunk.
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encPrefixSize:], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptWriter encrypts everything written to it into an archive.
+type encryptWriter struct {
+	w       io.Writer
+	gcm     cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	closed  bool
+}
+
+// newEncryptWriter writes the archive header to w and returns a writer that
+// encrypts into w. Close must be called to seal the final chunk; it does not
+// close w.
+func newEncryptWriter(w io.Writer, master []byte) (io.WriteCloser, error) {
+	header := make([]byte, encHeaderSize)
+	copy(header, encMagic)
+	if _, err := io.ReadFull(rand.Reader, header[len(encMagic):]); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	salt := header[len(encMagic) : len(encMagic)+encSaltSize]
+	prefix := header[len(encMagic)+encSaltSize:]
+
+	gcm, err := newGCM(master, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, gcm: gcm, prefix: prefix, buf: make([]byte, 0, encChunkSize)}, nil
+}
+
+// Write buffers p and seals every full chunk that is followed by more data.
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("write to closed encrypt writer")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		if len(e.buf) == encChunkSize {
+			if err := e.seal(false); err != nil {
+				return n - len(p), err
+			}
+		}
+		take := encChunkSize - len(e.buf)
+		if take > len(p) {
+			take = len(p)
+		}
+		e.buf = append(e.buf, p[:take]...)
+		p = p[take:]
+	}
+	return n, nil
+}
+
+// Close seals the final chunk.
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	sealed := e.gcm.Seal(nil, chunkNonce(e.prefix, e.counter, last), e.buf, nil)
+	e.counter++
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+// decryptReader decrypts an archive.
+type decryptReader struct {
+	r       *bufio.Reader
+	gcm     cipher.AEAD
+	prefix  []byte
+	counter uint32
+	chunk   []byte
+	plain   []byte
+	done    bool
+}
+
+// newDecryptReader reads the archive header from r and returns a reader of
+// the plaintext. Reads fail with ErrDecryptFailed when a chunk does not
+// authenticate or the archive is truncated.
+func newDecryptReader(r io.Reader, master []byte) (io.Reader, error) {
+	header := make([]byte, encHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read archive header: %w", err)
+	}
+	if !bytes.Equal(header[:len(encMagic)], []byte(encMagic)) {
+		return nil, fmt.Errorf("not an encrypted backup archive")
+	}
+	salt := header[len(encMagic) : len(encMagic)+encSaltSize]
+	gcm, err := newGCM(master, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		r:      bufio.NewReaderSize(r, encSealedChunk),
+		gcm:    gcm,
+		prefix: append([]byte(nil), header[len(encMagic)+encSaltSize:]...),
+		chunk:  make([]byte, encSealedChunk),
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// open reads and authenticates the next chunk. A chunk is the last one when
+// it is short or followed by end of input.
+func (d *decryptReader) open() error {
+	n, err := io.ReadFull(d.r, d.chunk)
+	last := false
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, peekErr := d.r.Peek(1); errors.Is(peekErr, io.EOF) {
+			last = true
+		}
+	}
+
+	plain, openErr := d.gcm.Open(d.chunk[:0], chunkNonce(d.prefix, d.counter, last), d.chunk[:n], nil)
+	if openErr != nil {
+		return ErrDecryptFailed
+	}
+	d.counter++
+	d.plain = plain
+	d.done = last
+	return nil
+}
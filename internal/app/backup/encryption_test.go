@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+func encrypt(t *testing.T, key, plain []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := newEncryptWriter(&buf, key)
+	if err != nil {
+		t.Fatalf("newEncryptWriter() error = %v", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(key, sealed []byte) ([]byte, error) {
+	r, err := newDecryptReader(bytes.NewReader(sealed), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	key := []byte("correct horse battery staple")
+	sizes := []int{0, 1, encChunkSize - 1, encChunkSize, encChunkSize + 1, 3*encChunkSize + 17}
+	for _, size := range sizes {
+		plain := make([]byte, size)
+		_, _ = rand.Read(plain)
+
+		sealed := encrypt(t, key, plain)
+		if size >= 16 && bytes.Contains(sealed, plain) {
+			t.Errorf("size %d: ciphertext contains the plaintext", size)
+		}
+		got, err := decrypt(key, sealed)
+		if err != nil {
+			t.Fatalf("size %d: decrypt error = %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	key := []byte("correct horse battery staple")
+	plain := make([]byte, 2*encChunkSize+100)
+	_, _ = rand.Read(plain)
+	sealed := encrypt(t, key, plain)
+
+	flipped := append([]byte(nil), sealed...)
+	flipped[encHeaderSize+10] ^= 0xff
+
+	tests := map[string]struct {
+		key    []byte
+		sealed []byte
+	}{
+		"wrong key":           {[]byte("wrong key"), sealed},
+		"flipped bit":         {key, flipped},
+		"truncated at chunk":  {key, sealed[:encHeaderSize+2*encSealedChunk]},
+		"truncated mid chunk": {key, sealed[:len(sealed)-5]},
+		"dropped chunk":       {key, append(append([]byte(nil), sealed[:encHeaderSize]...), sealed[encHeaderSize+encSealedChunk:]...)},
+	}
+	for name, tt := range tests {
+		if _, err := decrypt(tt.key, tt.sealed); !errors.Is(err, ErrDecryptFailed) {
+			t.Errorf("%s: error = %v, want ErrDecryptFailed", name, err)
+		}
+	}
+
+	if _, err := decrypt(key, []byte("plain gzip data that is long enough")); err == nil {
+		t.Error("decrypting an unencrypted archive succeeded")
+	}
+}
@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Notification events
+const (
+	EventBackupFailed  = "backup.failed"
+	EventBackupSkipped = "backup.skipped"
+)
+
+// Notification describes a scheduled backup that needs attention.
+type Notification struct {
+	Event      string    `json:"event"`
+	ScheduleID string    `json:"schedule_id,omitempty"`
+	StackID    string    `json:"stack_id,omitempty"`
+	BackupID   string    `json:"backup_id,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// Notifier delivers scheduled backup notifications.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// WebhookNotifier posts notifications as JSON to a URL. The payload carries a
+// text field so that Slack and Mattermost incoming webhooks display it.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// Notify posts the notification.
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	payload := struct {
+		Notification
+		Text string `json:"text"`
+	}{
+		Notification: n,
+		Text:         fmt.Sprintf("Homeport %s: stack %s, schedule %s: %s", n.Event, n.StackID, n.ScheduleID, n.Error),
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RetentionPolicy decides which completed backups to keep. A backup is kept
+// when any rule selects it: the KeepLast newest backups, plus the newest
+// backup of each of the KeepDaily most recent days, KeepWeekly most recent ISO
+// weeks and KeepMonthly most recent months. A zero policy keeps everything.
+type RetentionPolicy struct {
+	KeepLast    int `json:"keep_last,omitempty"`
+	KeepDaily   int `json:"keep_daily,omitempty"`
+	KeepWeekly  int `json:"keep_weekly,omitempty"`
+	KeepMonthly int `json:"keep_monthly,omitempty"`
+}
+
+// IsZero reports whether the policy keeps everything.
+func (p RetentionPolicy) IsZero() bool {
+	return p.KeepLast <= 0 && p.KeepDaily <= 0 && p.KeepWeekly <= 0 && p.KeepMonthly <= 0
+}
+
+// Select splits backups into the ones to keep and the ones to prune. Backups
+// that are not completed are never pruned. Backups of different stacks are
+// retained independently.
+func (p RetentionPolicy) Select(backups []*Backup) (keep, prune []*Backup) {
+	if p.IsZero() {
+		return backups, nil
+	}
+
+	byStack := make(map[string][]*Backup)
+	for _, b := range backups {
+		if b.Status != BackupStatusCompleted {
+			keep = append(keep, b)
+			continue
+		}
+		byStack[b.StackID] = append(byStack[b.StackID], b)
+	}
+
+	stackIDs := make([]string, 0, len(byStack))
+	for id := range byStack {
+		stackIDs = append(stackIDs, id)
+	}
+	sort.Strings(stackIDs)
+
+	for _, id := range stackIDs {
+		group := byStack[id]
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].CreatedAt.After(group[j].CreatedAt)
+		})
+
+		kept := make(map[*Backup]bool)
+		for i := 0; i < p.KeepLast && i < len(group); i++ {
+			kept[group[i]] = true
+		}
+		keepBuckets(group, p.KeepDaily, kept, func(t time.Time) string {
+			return t.Format("2006-01-02")
+		})
+		keepBuckets(group, p.KeepWeekly, kept, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		})
+		keepBuckets(group, p.KeepMonthly, kept, func(t time.Time) string {
+			return t.Format("2006-01")
+		})
+
+		for _, b := range group {
+			if kept[b] {
+				keep = append(keep, b)
+			} else {
+				prune = append(prune, b)
+			}
+		}
+	}
+
+	return keep, prune
+}
+
+// keepBuckets marks the newest backup of each of the n most recent buckets.
+// group must be sorted newest first.
+func keepBuckets(group []*Backup, n int, kept map[*Backup]bool, bucket func(time.Time) string) {
+	if n <= 0 {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, b := range group {
+		key := bucket(b.CreatedAt.UTC())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept[b] = true
+		if len(seen) == n {
+			return
+		}
+	}
+}
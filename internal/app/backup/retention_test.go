@@ -0,0 +1,73 @@
+package backup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionSelect(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	// Two backups a day for 90 days, plus one for another stack and one that
+	// is still running.
+	var backups []*Backup
+	for day := 0; day < 90; day++ {
+		for _, hour := range []int{0, 6} {
+			backups = append(backups, &Backup{
+				ID:        now.AddDate(0, 0, -day).Add(-time.Duration(hour) * time.Hour).Format(time.RFC3339),
+				StackID:   "shop",
+				Status:    BackupStatusCompleted,
+				CreatedAt: now.AddDate(0, 0, -day).Add(-time.Duration(hour) * time.Hour),
+			})
+		}
+	}
+	other := &Backup{ID: "other", StackID: "blog", Status: BackupStatusCompleted, CreatedAt: now.AddDate(-1, 0, 0)}
+	running := &Backup{ID: "running", StackID: "shop", Status: BackupStatusRunning, CreatedAt: now.AddDate(-1, 0, 0)}
+	backups = append(backups, other, running)
+
+	policy := RetentionPolicy{KeepLast: 3, KeepDaily: 7, KeepWeekly: 4, KeepMonthly: 3}
+	keep, prune := policy.Select(backups)
+
+	if len(keep)+len(prune) != len(backups) {
+		t.Fatalf("keep %d + prune %d != %d backups", len(keep), len(prune), len(backups))
+	}
+	kept := make(map[string]bool)
+	for _, b := range keep {
+		kept[b.ID] = true
+	}
+	if !kept["other"] || !kept["running"] {
+		t.Error("backups of another stack or still running were pruned")
+	}
+
+	// 2026-03-31 is a Tuesday. KeepLast keeps today twice and yesterday;
+	// KeepDaily adds the 5 days before; KeepWeekly adds Sunday 22 and 15
+	// March (Sunday 29 is a daily); KeepMonthly adds 28 February and 31
+	// January.
+	shop := 0
+	for _, b := range keep {
+		if b.StackID == "shop" && b.Status == BackupStatusCompleted {
+			shop++
+		}
+	}
+	if want := 3 + 5 + 2 + 2; shop != want {
+		t.Errorf("kept %d shop backups, want %d", shop, want)
+	}
+
+	oldest := now
+	for _, b := range keep {
+		if b.StackID == "shop" && b.Status == BackupStatusCompleted && b.CreatedAt.Before(oldest) {
+			oldest = b.CreatedAt
+		}
+	}
+	if want := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC); !oldest.Equal(want) {
+		t.Errorf("oldest kept backup = %s, want %s", oldest, want)
+	}
+}
+
+func TestRetentionZeroKeepsEverything(t *testing.T) {
+	backups := []*Backup{{ID: "a", Status: BackupStatusCompleted}, {ID: "b", Status: BackupStatusCompleted}}
+	keep, prune := RetentionPolicy{}.Select(backups)
+	if len(keep) != 2 || len(prune) != 0 {
+		t.Errorf("zero policy: keep %d, prune %d", len(keep), len(prune))
+	}
+}
@@ -0,0 +1,421 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/homeport/homeport/internal/pkg/logger"
+)
+
+// Schedule errors
+var (
+	ErrScheduleNotFound = errors.New("backup schedule not found")
+	ErrInvalidSchedule  = errors.New("invalid backup schedule")
+)
+
+// Schedule backs up a stack on a cron expression. Runs missed while the
+// server was down are caught up once at startup, unless SkipMissed is set.
+type Schedule struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	StackID      string       `json:"stack_id"`
+	Cron         string       `json:"cron"`
+	Volumes      []string     `json:"volumes,omitempty"` // All stack volumes when empty
+	Enabled      bool         `json:"enabled"`
+	SkipMissed   bool         `json:"skip_missed,omitempty"`
+	NextRunAt    time.Time    `json:"next_run_at"`
+	LastRunAt    *time.Time   `json:"last_run_at,omitempty"`
+	LastBackupID string       `json:"last_backup_id,omitempty"`
+	LastStatus   BackupStatus `json:"last_status,omitempty"`
+	LastError    string       `json:"last_error,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// clone returns a copy of the schedule that the scheduler does not write to.
+func (sched *Schedule) clone() *Schedule {
+	c := *sched
+	c.Volumes = append([]string(nil), sched.Volumes...)
+	if sched.LastRunAt != nil {
+		lastRunAt := *sched.LastRunAt
+		c.LastRunAt = &lastRunAt
+	}
+	return &c
+}
+
+// ScheduleRequest contains parameters for creating a schedule.
+type ScheduleRequest struct {
+	Name       string   `json:"name"`
+	StackID    string   `json:"stack_id"`
+	Cron       string   `json:"cron"`
+	Volumes    []string `json:"volumes,omitempty"`
+	SkipMissed bool     `json:"skip_missed,omitempty"`
+}
+
+// schedulerInterval is how often due schedules are checked.
+const schedulerInterval = 30 * time.Second
+
+// scheduleKey is the inProgress key guarding concurrent runs of a schedule.
+func scheduleKey(id string) string {
+	return "schedule:" + id
+}
+
+// ListSchedules returns copies of all schedules, optionally filtered by
+// stack.
+func (s *Service) ListSchedules(ctx context.Context, stackID string) ([]*Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*Schedule, 0, len(s.schedules))
+	for _, sched := range s.schedules {
+		if stackID == "" || sched.StackID == stackID {
+			result = append(result, sched.clone())
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// GetSchedule returns a copy of a schedule by ID.
+func (s *Service) GetSchedule(ctx context.Context, id string) (*Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sched, ok := s.schedules[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	return sched.clone(), nil
+}
+
+// AddSchedule creates a schedule.
+func (s *Service) AddSchedule(ctx context.Context, req ScheduleRequest) (*Schedule, error) {
+	if req.StackID == "" {
+		return nil, fmt.Errorf("%w: stack ID is required", ErrInvalidSchedule)
+	}
+	cron, err := parseCron(req.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	now := time.Now()
+	next := cron.Next(now)
+	if next.IsZero() {
+		return nil, fmt.Errorf("%w: %q never runs", ErrInvalidSchedule, req.Cron)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = "scheduled"
+	}
+
+	sched := &Schedule{
+		ID:         generateID(),
+		Name:       name,
+		StackID:    req.StackID,
+		Cron:       req.Cron,
+		Volumes:    req.Volumes,
+		Enabled:    true,
+		SkipMissed: req.SkipMissed,
+		NextRunAt:  next,
+		CreatedAt:  now,
+	}
+
+	result := sched.clone()
+	s.mu.Lock()
+	s.schedules[sched.ID] = sched
+	s.mu.Unlock()
+	s.saveSchedules()
+
+	logger.Info("Backup schedule added", "id", result.ID, "stack", result.StackID, "cron", result.Cron, "next_run", result.NextRunAt)
+	return result, nil
+}
+
+// RemoveSchedule deletes a schedule. Backups it created are kept.
+func (s *Service) RemoveSchedule(ctx context.Context, id string) error {
+	s.mu.Lock()
+	if _, ok := s.schedules[id]; !ok {
+		s.mu.Unlock()
+		return ErrScheduleNotFound
+	}
+	delete(s.schedules, id)
+	s.mu.Unlock()
+	s.saveSchedules()
+
+	logger.Info("Backup schedule removed", "id", id)
+	return nil
+}
+
+// StartScheduler runs due schedules in the background until Close is called.
+// When WAL archiving is enabled, database logs are archived alongside.
+func (s *Service) StartScheduler() {
+	s.mu.Lock()
+	if s.stopScheduler != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stopScheduler = make(chan struct{})
+	stop := s.stopScheduler
+
+	// Skip runs missed while the server was down when asked to
+	now := time.Now()
+	for _, sched := range s.schedules {
+		if sched.SkipMissed && sched.NextRunAt.Before(now) {
+			if cron, err := parseCron(sched.Cron); err == nil {
+				sched.NextRunAt = cron.Next(now)
+			}
+		}
+	}
+	s.mu.Unlock()
+	s.saveSchedules()
+
+	s.schedulerWG.Add(1)
+	go func() {
+		defer s.schedulerWG.Done()
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+
+		s.runDueSchedules(time.Now())
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				s.runDueSchedules(now)
+			}
+		}
+	}()
+
+	if !s.config.ArchiveWAL {
+		return
+	}
+	s.schedulerWG.Add(1)
+	go func() {
+		defer s.schedulerWG.Done()
+		ticker := time.NewTicker(s.config.ArchiveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.archiveAll(context.Background())
+			}
+		}
+	}()
+}
+
+// stopSchedulerLoop stops the scheduler and waits for it to exit.
+func (s *Service) stopSchedulerLoop() {
+	s.mu.Lock()
+	stop := s.stopScheduler
+	s.stopScheduler = nil
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		s.schedulerWG.Wait()
+	}
+}
+
+// runDueSchedules starts every enabled schedule due at now. A schedule whose
+// previous backup is still running skips the occurrence.
+func (s *Service) runDueSchedules(now time.Time) {
+	type dueRun struct {
+		sched   *Schedule
+		skipped bool
+	}
+	var due []dueRun
+
+	s.mu.Lock()
+	for _, sched := range s.schedules {
+		if !sched.Enabled || sched.NextRunAt.After(now) {
+			continue
+		}
+		cron, err := parseCron(sched.Cron)
+		if err != nil {
+			sched.Enabled = false
+			sched.LastError = err.Error()
+			continue
+		}
+		sched.NextRunAt = cron.Next(now)
+
+		key := scheduleKey(sched.ID)
+		if s.inProgress[key] {
+			due = append(due, dueRun{sched: sched, skipped: true})
+			continue
+		}
+		s.inProgress[key] = true
+		due = append(due, dueRun{sched: sched})
+	}
+	s.mu.Unlock()
+
+	if len(due) == 0 {
+		return
+	}
+	s.saveSchedules()
+
+	for _, run := range due {
+		if run.skipped {
+			logger.Warn("Scheduled backup skipped, previous run still in progress", "schedule", run.sched.ID, "stack", run.sched.StackID)
+			s.notify(Notification{
+				Event:      EventBackupSkipped,
+				ScheduleID: run.sched.ID,
+				StackID:    run.sched.StackID,
+				Error:      "previous run still in progress",
+				Time:       now,
+			})
+			continue
+		}
+		s.runSchedule(run.sched, now)
+	}
+}
+
+// runSchedule starts the backup of a schedule. The schedule guard is released
+// when the backup finishes.
+func (s *Service) runSchedule(sched *Schedule, now time.Time) {
+	ctx := context.Background()
+
+	s.mu.Lock()
+	sched.LastRunAt = &now
+	sched.LastBackupID = ""
+	sched.LastStatus = BackupStatusRunning
+	sched.LastError = ""
+	// The schedule may be updated while the backup starts, so work on a copy
+	// of the fields taken under the lock.
+	id, stackID, schedName, cron := sched.ID, sched.StackID, sched.Name, sched.Cron
+	volumes := append([]string(nil), sched.Volumes...)
+	s.mu.Unlock()
+
+	if len(volumes) == 0 {
+		infos, err := s.ListVolumes(ctx, stackID)
+		if err != nil {
+			s.finishSchedule(sched, nil, err)
+			return
+		}
+		for _, v := range infos {
+			volumes = append(volumes, v.Name)
+		}
+		if len(volumes) == 0 {
+			s.finishSchedule(sched, nil, fmt.Errorf("stack %s has no volumes", stackID))
+			return
+		}
+	}
+
+	name := fmt.Sprintf("%s-%s", schedName, now.Format("20060102-1504"))
+	description := fmt.Sprintf("Scheduled backup (%s)", cron)
+
+	backup, err := s.createBackup(ctx, name, description, stackID, volumes, id)
+	if err != nil {
+		s.finishSchedule(sched, nil, err)
+		return
+	}
+
+	logger.Info("Scheduled backup started", "schedule", id, "stack", stackID, "backup", backup.ID)
+}
+
+// scheduledBackupDone records the outcome of a scheduled backup.
+func (s *Service) scheduledBackupDone(backup *Backup) {
+	s.mu.RLock()
+	sched, ok := s.schedules[backup.ScheduleID]
+	status, errMsg := backup.Status, backup.Error
+	s.mu.RUnlock()
+
+	if !ok {
+		// Schedule removed while its backup was running
+		s.mu.Lock()
+		delete(s.inProgress, scheduleKey(backup.ScheduleID))
+		s.mu.Unlock()
+		return
+	}
+
+	var err error
+	if status != BackupStatusCompleted {
+		err = errors.New(errMsg)
+	}
+	s.finishSchedule(sched, backup, err)
+}
+
+// finishSchedule releases the schedule guard, records the last status and
+// notifies failures.
+func (s *Service) finishSchedule(sched *Schedule, backup *Backup, err error) {
+	s.mu.Lock()
+	delete(s.inProgress, scheduleKey(sched.ID))
+	if backup != nil {
+		sched.LastBackupID = backup.ID
+	}
+	if err != nil {
+		sched.LastStatus = BackupStatusFailed
+		sched.LastError = err.Error()
+	} else {
+		sched.LastStatus = BackupStatusCompleted
+		sched.LastError = ""
+	}
+	id, stackID := sched.ID, sched.StackID
+	s.mu.Unlock()
+	s.saveSchedules()
+
+	if err == nil {
+		return
+	}
+
+	logger.Error("Scheduled backup failed", "schedule", id, "stack", stackID, "error", err)
+	n := Notification{
+		Event:      EventBackupFailed,
+		ScheduleID: id,
+		StackID:    stackID,
+		Error:      err.Error(),
+		Time:       time.Now(),
+	}
+	if backup != nil {
+		n.BackupID = backup.ID
+	}
+	s.notify(n)
+}
+
+// notify sends a notification when a notifier is configured.
+func (s *Service) notify(n Notification) {
+	if s.config.Notifier == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.config.Notifier.Notify(ctx, n); err != nil {
+		logger.Warn("Failed to send backup notification", "event", n.Event, "error", err)
+	}
+}
+
+// saveSchedules persists schedules to JSON file.
+func (s *Service) saveSchedules() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := json.MarshalIndent(s.schedules, "", "  ")
+	if err != nil {
+		logger.Error("Failed to marshal backup schedules", "error", err)
+		return
+	}
+
+	if err := os.WriteFile(s.config.SchedulesPath, data, 0600); err != nil {
+		logger.Error("Failed to save backup schedules", "error", err)
+	}
+}
+
+// loadSchedules loads schedules from JSON file.
+func (s *Service) loadSchedules() error {
+	data, err := os.ReadFile(s.config.SchedulesPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	return json.Unmarshal(data, &s.schedules)
+}
@@ -0,0 +1,163 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+// newTestService returns a service whose Docker daemon is unreachable, so
+// scheduled runs fail while listing volumes.
+func newTestService(t *testing.T, notifier Notifier) *Service {
+	t.Helper()
+	dockerClient, err := client.NewClientWithOpts(client.WithHost("tcp://127.0.0.1:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = dockerClient.Close() })
+
+	dir := t.TempDir()
+	return &Service{
+		backups:      make(map[string]*Backup),
+		schedules:    make(map[string]*Schedule),
+		archives:     make(map[string]*WALArchive),
+		dockerClient: dockerClient,
+		config: &Config{
+			BackupDir:     dir,
+			DataPath:      filepath.Join(dir, "backups.json"),
+			SchedulesPath: filepath.Join(dir, "backup-schedules.json"),
+			ArchivesPath:  filepath.Join(dir, "backup-archives.json"),
+			Notifier:      notifier,
+		},
+		inProgress: make(map[string]bool),
+	}
+}
+
+func TestAddScheduleValidatesAndPersists(t *testing.T) {
+	svc := newTestService(t, nil)
+	ctx := context.Background()
+
+	if _, err := svc.AddSchedule(ctx, ScheduleRequest{StackID: "shop", Cron: "every day"}); !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("invalid cron: error = %v", err)
+	}
+	if _, err := svc.AddSchedule(ctx, ScheduleRequest{Cron: "@daily"}); !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("missing stack: error = %v", err)
+	}
+
+	sched, err := svc.AddSchedule(ctx, ScheduleRequest{StackID: "shop", Cron: "0 3 * * *"})
+	if err != nil {
+		t.Fatalf("AddSchedule() error = %v", err)
+	}
+	if sched.Name != "scheduled" || !sched.Enabled || sched.NextRunAt.Hour() != 3 {
+		t.Errorf("schedule = %+v", sched)
+	}
+
+	reloaded := newTestService(t, nil)
+	reloaded.config = svc.config
+	if err := reloaded.loadSchedules(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloaded.GetSchedule(ctx, sched.ID); err != nil {
+		t.Errorf("schedule not persisted: %v", err)
+	}
+
+	if err := svc.RemoveSchedule(ctx, sched.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RemoveSchedule(ctx, sched.ID); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("second remove: error = %v", err)
+	}
+}
+
+func TestRunDueSchedules(t *testing.T) {
+	notifier := &recordingNotifier{}
+	svc := newTestService(t, notifier)
+	now := time.Now()
+
+	due := &Schedule{ID: "due", StackID: "shop", Cron: "@hourly", Enabled: true, NextRunAt: now.Add(-3 * time.Hour)}
+	busy := &Schedule{ID: "busy", StackID: "blog", Cron: "@hourly", Enabled: true, NextRunAt: now.Add(-time.Minute)}
+	later := &Schedule{ID: "later", StackID: "wiki", Cron: "@hourly", Enabled: true, NextRunAt: now.Add(time.Hour)}
+	disabled := &Schedule{ID: "disabled", StackID: "wiki", Cron: "@hourly", NextRunAt: now.Add(-time.Hour)}
+	for _, sched := range []*Schedule{due, busy, later, disabled} {
+		svc.schedules[sched.ID] = sched
+	}
+	svc.inProgress[scheduleKey("busy")] = true
+
+	svc.runDueSchedules(now)
+
+	// A missed run is caught up once, then the schedule moves to the next
+	// occurrence
+	if !due.NextRunAt.After(now) || due.LastRunAt == nil {
+		t.Errorf("due schedule not run: %+v", due)
+	}
+	if due.LastStatus != BackupStatusFailed || due.LastError == "" {
+		t.Errorf("due schedule status = %s (%s), want failed without Docker", due.LastStatus, due.LastError)
+	}
+	if svc.inProgress[scheduleKey("due")] {
+		t.Error("schedule guard not released after failure")
+	}
+	if !svc.inProgress[scheduleKey("busy")] || busy.LastRunAt != nil {
+		t.Error("busy schedule ran concurrently")
+	}
+	if later.LastRunAt != nil || disabled.LastRunAt != nil {
+		t.Error("schedule ran before it was due or while disabled")
+	}
+
+	events := map[string]string{}
+	for _, n := range notifier.sent {
+		events[n.ScheduleID] = n.Event
+	}
+	if events["due"] != EventBackupFailed || events["busy"] != EventBackupSkipped || len(events) != 2 {
+		t.Errorf("notifications = %+v", notifier.sent)
+	}
+}
+
+func TestSchedulesAreReturnedAsCopies(t *testing.T) {
+	svc := newTestService(t, nil)
+	ctx := context.Background()
+	now := time.Now()
+	svc.schedules["due"] = &Schedule{ID: "due", StackID: "shop", Cron: "@hourly", Volumes: []string{"db"}, Enabled: true, NextRunAt: now.Add(-time.Hour)}
+
+	got, err := svc.GetSchedule(ctx, "due")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed, err := svc.ListSchedules(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The scheduler writes to its own schedules while callers read theirs
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.runDueSchedules(now)
+	}()
+	got.Volumes[0] = "changed"
+	_ = listed[0].LastStatus
+	<-done
+
+	if svc.schedules["due"].Volumes[0] != "db" {
+		t.Error("changing a returned schedule changed the service's schedule")
+	}
+	if got.LastRunAt != nil || listed[0].LastRunAt != nil {
+		t.Error("a run changed a returned schedule")
+	}
+}
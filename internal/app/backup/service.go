@@ -6,12 +6,14 @@ import (
 	"compress/gzip"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sync"
 	"time"
@@ -34,6 +36,7 @@ var (
 	ErrBackupInProgress  = errors.New("backup operation in progress")
 	ErrRestoreInProgress = errors.New("restore operation in progress")
 	ErrInvalidName       = errors.New("invalid backup name")
+	ErrChecksumMismatch  = errors.New("backup archive checksum mismatch")
 )
 
 // BackupStatus represents the status of a backup operation.
@@ -48,17 +51,24 @@ const (
 
 // Backup represents a volume backup.
 type Backup struct {
-	ID          string       `json:"id"`
-	Name        string       `json:"name"`
-	Description string       `json:"description,omitempty"`
-	StackID     string       `json:"stack_id"`
-	Volumes     []string     `json:"volumes"`
-	Size        int64        `json:"size"`
-	Status      BackupStatus `json:"status"`
-	Error       string       `json:"error,omitempty"`
-	FilePath    string       `json:"file_path"`
-	CreatedAt   time.Time    `json:"created_at"`
-	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ID          string                     `json:"id"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description,omitempty"`
+	StackID     string                     `json:"stack_id"`
+	Volumes     []string                   `json:"volumes"`
+	Size        int64                      `json:"size"`
+	Status      BackupStatus               `json:"status"`
+	Error       string                     `json:"error,omitempty"`
+	FilePath    string                     `json:"file_path"`
+	Encrypted   bool                       `json:"encrypted"`
+	Checksum    string                     `json:"checksum,omitempty"`    // SHA-256 of the stored archive
+	Destination string                     `json:"destination,omitempty"` // Off-site destination type
+	RemoteKey   string                     `json:"remote_key,omitempty"`  // Archive key at the destination
+	Location    string                     `json:"location,omitempty"`    // Human-readable off-site location
+	ScheduleID  string                     `json:"schedule_id,omitempty"` // Schedule that created the backup
+	Databases   map[string]*DatabaseBackup `json:"databases,omitempty"`   // Database volumes, by volume name
+	CreatedAt   time.Time                  `json:"created_at"`
+	CompletedAt *time.Time                 `json:"completed_at,omitempty"`
 }
 
 // VolumeInfo represents information about a Docker volume.
@@ -73,17 +83,33 @@ type VolumeInfo struct {
 
 // Config holds backup service configuration.
 type Config struct {
-	BackupDir string // Directory to store backups
-	DataPath  string // Path for metadata persistence (JSON)
+	BackupDir     string             // Directory to store backups
+	DataPath      string             // Path for metadata persistence (JSON)
+	SchedulesPath string             // Path for schedule persistence (JSON)
+	ArchivesPath  string             // Path for WAL archive index persistence (JSON)
+	Destination   *DestinationConfig // Off-site copy of every archive (nil: local only)
+	KeySource     KeySource          // Encrypts archives when set
+	Retention     *RetentionPolicy   // Prunes old backups after each backup when set
+	Notifier      Notifier           // Notified of failed scheduled backups when set
+
+	// ArchiveWAL continuously archives the WAL and binlogs of backed up
+	// databases, enabling point-in-time restores
+	ArchiveWAL      bool
+	ArchiveInterval time.Duration
 }
 
 // Service handles backup operations.
 type Service struct {
-	mu           sync.RWMutex
-	backups      map[string]*Backup
-	dockerClient *client.Client
-	config       *Config
-	inProgress   map[string]bool // Track in-progress operations
+	mu            sync.RWMutex
+	backups       map[string]*Backup
+	schedules     map[string]*Schedule
+	archives      map[string]*WALArchive
+	dockerClient  *client.Client
+	config        *Config
+	destination   Destination
+	inProgress    map[string]bool // Track in-progress operations
+	stopScheduler chan struct{}
+	schedulerWG   sync.WaitGroup
 }
 
 // NewService creates a new backup service.
@@ -109,11 +135,33 @@ func NewService(cfg *Config) (*Service, error) {
 		cfg.DataPath = filepath.Join(home, ".homeport", "backups.json")
 	}
 
+	if cfg.SchedulesPath == "" {
+		cfg.SchedulesPath = filepath.Join(filepath.Dir(cfg.DataPath), "backup-schedules.json")
+	}
+
+	if cfg.ArchivesPath == "" {
+		cfg.ArchivesPath = filepath.Join(filepath.Dir(cfg.DataPath), "backup-archives.json")
+	}
+
+	if cfg.ArchiveInterval <= 0 {
+		cfg.ArchiveInterval = defaultArchiveInterval
+	}
+
 	// Create backup directory
 	if err := os.MkdirAll(cfg.BackupDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
+	// Off-site destination
+	var destination Destination
+	if cfg.Destination != nil {
+		d, err := NewDestination(cfg.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure backup destination: %w", err)
+		}
+		destination = d
+	}
+
 	// Initialize Docker client
 	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
@@ -122,8 +170,11 @@ func NewService(cfg *Config) (*Service, error) {
 
 	s := &Service{
 		backups:      make(map[string]*Backup),
+		schedules:    make(map[string]*Schedule),
+		archives:     make(map[string]*WALArchive),
 		dockerClient: dockerClient,
 		config:       cfg,
+		destination:  destination,
 		inProgress:   make(map[string]bool),
 	}
 
@@ -131,12 +182,19 @@ func NewService(cfg *Config) (*Service, error) {
 	if err := s.loadData(); err != nil {
 		logger.Warn("Failed to load backup data", "error", err)
 	}
+	if err := s.loadSchedules(); err != nil {
+		logger.Warn("Failed to load backup schedules", "error", err)
+	}
+	if err := s.loadArchives(); err != nil {
+		logger.Warn("Failed to load WAL archives", "error", err)
+	}
 
 	return s, nil
 }
 
-// Close closes the service and releases resources.
+// Close stops the scheduler, closes the service and releases resources.
 func (s *Service) Close() error {
+	s.stopSchedulerLoop()
 	return s.dockerClient.Close()
 }
 
@@ -207,6 +265,11 @@ func (s *Service) GetBackup(ctx context.Context, id string) (*Backup, error) {
 // CreateBackup creates a new backup of specified volumes.
 // This runs asynchronously - returns immediately with pending status.
 func (s *Service) CreateBackup(ctx context.Context, name, description, stackID string, volumes []string) (*Backup, error) {
+	return s.createBackup(ctx, name, description, stackID, volumes, "")
+}
+
+// createBackup starts a backup, recording the schedule that requested it.
+func (s *Service) createBackup(ctx context.Context, name, description, stackID string, volumes []string, scheduleID string) (*Backup, error) {
 	if name == "" {
 		return nil, ErrInvalidName
 	}
@@ -237,6 +300,11 @@ func (s *Service) CreateBackup(ctx context.Context, name, description, stackID s
 	// Generate unique ID
 	id := generateID()
 
+	fileName := id + ".tar.gz"
+	if s.config.KeySource != nil {
+		fileName += ".enc"
+	}
+
 	backup := &Backup{
 		ID:          id,
 		Name:        name,
@@ -244,7 +312,9 @@ func (s *Service) CreateBackup(ctx context.Context, name, description, stackID s
 		StackID:     stackID,
 		Volumes:     volumes,
 		Status:      BackupStatusPending,
-		FilePath:    filepath.Join(s.config.BackupDir, id+".tar.gz"),
+		FilePath:    filepath.Join(s.config.BackupDir, fileName),
+		Encrypted:   s.config.KeySource != nil,
+		ScheduleID:  scheduleID,
 		CreatedAt:   time.Now(),
 	}
 
@@ -275,6 +345,10 @@ func (s *Service) runBackup(backup *Backup) {
 		s.mu.Lock()
 		delete(s.inProgress, backup.ID)
 		s.mu.Unlock()
+
+		if backup.ScheduleID != "" {
+			s.scheduledBackupDone(backup)
+		}
 	}()
 
 	// Ensure alpine image is available
@@ -283,7 +357,8 @@ func (s *Service) runBackup(backup *Backup) {
 		return
 	}
 
-	// Create tar.gz file
+	// Create tar.gz file, encrypted when a key is configured, hashing what
+	// is written to disk
 	file, err := os.Create(backup.FilePath)
 	if err != nil {
 		s.failBackup(backup, fmt.Errorf("failed to create backup file: %w", err))
@@ -291,14 +366,48 @@ func (s *Service) runBackup(backup *Backup) {
 	}
 	defer func() { _ = file.Close() }()
 
-	gzWriter := gzip.NewWriter(file)
+	hasher := sha256.New()
+	var archive io.Writer = io.MultiWriter(file, hasher)
+
+	var encWriter io.WriteCloser
+	if backup.Encrypted {
+		key, err := s.config.KeySource.BackupKey(ctx)
+		if err != nil {
+			s.failBackup(backup, fmt.Errorf("failed to get backup key: %w", err))
+			return
+		}
+		encWriter, err = newEncryptWriter(archive, key)
+		if err != nil {
+			s.failBackup(backup, fmt.Errorf("failed to start encryption: %w", err))
+			return
+		}
+		archive = encWriter
+	}
+
+	gzWriter := gzip.NewWriter(archive)
 	defer func() { _ = gzWriter.Close() }()
 
 	tarWriter := tar.NewWriter(gzWriter)
 	defer func() { _ = tarWriter.Close() }()
 
-	// Backup each volume
+	// Backup each volume, through the database server when one is running
+	// on it so that the copy is consistent
 	for _, volName := range backup.Volumes {
+		record, err := s.backupDatabaseVolume(ctx, volName, tarWriter)
+		if err != nil {
+			s.failBackup(backup, fmt.Errorf("failed to backup database volume %s: %w", volName, err))
+			return
+		}
+		if record != nil {
+			s.mu.Lock()
+			if backup.Databases == nil {
+				backup.Databases = make(map[string]*DatabaseBackup)
+			}
+			backup.Databases[volName] = record
+			s.mu.Unlock()
+			continue
+		}
+
 		if err := s.backupVolume(ctx, volName, tarWriter); err != nil {
 			s.failBackup(backup, fmt.Errorf("failed to backup volume %s: %w", volName, err))
 			return
@@ -306,9 +415,24 @@ func (s *Service) runBackup(backup *Backup) {
 	}
 
 	// Close writers to flush data
-	_ = tarWriter.Close()
-	_ = gzWriter.Close()
-	_ = file.Close()
+	if err := tarWriter.Close(); err != nil {
+		s.failBackup(backup, fmt.Errorf("failed to finish archive: %w", err))
+		return
+	}
+	if err := gzWriter.Close(); err != nil {
+		s.failBackup(backup, fmt.Errorf("failed to finish compression: %w", err))
+		return
+	}
+	if encWriter != nil {
+		if err := encWriter.Close(); err != nil {
+			s.failBackup(backup, fmt.Errorf("failed to finish encryption: %w", err))
+			return
+		}
+	}
+	if err := file.Close(); err != nil {
+		s.failBackup(backup, fmt.Errorf("failed to write backup file: %w", err))
+		return
+	}
 
 	// Get file size
 	info, err := os.Stat(backup.FilePath)
@@ -317,16 +441,60 @@ func (s *Service) runBackup(backup *Backup) {
 		return
 	}
 
+	s.mu.Lock()
+	backup.Size = info.Size()
+	backup.Checksum = hex.EncodeToString(hasher.Sum(nil))
+	s.mu.Unlock()
+
+	// Ship the archive off-site
+	if s.destination != nil {
+		if err := s.upload(ctx, backup); err != nil {
+			s.failBackup(backup, fmt.Errorf("failed to upload backup to %s: %w", s.destination.Type(), err))
+			return
+		}
+	}
+
 	// Update backup with success
 	s.mu.Lock()
 	backup.Status = BackupStatusCompleted
-	backup.Size = info.Size()
 	now := time.Now()
 	backup.CompletedAt = &now
 	s.mu.Unlock()
 	s.saveData()
 
-	logger.Info("Backup completed", "id", backup.ID, "name", backup.Name, "size", backup.Size)
+	logger.Info("Backup completed", "id", backup.ID, "name", backup.Name, "size", backup.Size, "location", backup.Location)
+
+	if s.config.Retention != nil {
+		if _, err := s.ApplyRetention(ctx); err != nil {
+			logger.Warn("Backup retention failed", "error", err)
+		}
+	}
+}
+
+// upload copies a local archive to the off-site destination.
+func (s *Service) upload(ctx context.Context, backup *Backup) error {
+	stackDir := backup.StackID
+	if stackDir == "" {
+		stackDir = "default"
+	}
+	key := path.Join(stackDir, filepath.Base(backup.FilePath))
+
+	file, err := os.Open(backup.FilePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := s.destination.Upload(ctx, key, file, backup.Size); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	backup.Destination = s.destination.Type()
+	backup.RemoteKey = key
+	backup.Location = s.destination.Location(key)
+	s.mu.Unlock()
+	return nil
 }
 
 // backupVolume backs up a single volume to the tar archive.
@@ -428,6 +596,16 @@ func (s *Service) backupVolume(ctx context.Context, volumeName string, tarWriter
 
 // RestoreBackup restores volumes from a backup.
 func (s *Service) RestoreBackup(ctx context.Context, backupID, targetStackID string, volumes []string) error {
+	return s.restoreBackup(ctx, backupID, volumes, nil)
+}
+
+// RestoreBackupToTime restores database volumes from a backup, then replays
+// the archived WAL or binlogs up to target.
+func (s *Service) RestoreBackupToTime(ctx context.Context, backupID, targetStackID string, volumes []string, target time.Time) error {
+	return s.restoreBackup(ctx, backupID, volumes, &target)
+}
+
+func (s *Service) restoreBackup(ctx context.Context, backupID string, volumes []string, target *time.Time) error {
 	s.mu.RLock()
 	backup, ok := s.backups[backupID]
 	if !ok {
@@ -445,25 +623,36 @@ func (s *Service) RestoreBackup(ctx context.Context, backupID, targetStackID str
 		volumes = backup.Volumes
 	}
 
+	if target != nil {
+		if backup.CompletedAt == nil || target.Before(*backup.CompletedAt) {
+			return fmt.Errorf("%w: target time must be after the backup completed", ErrPITRUnavailable)
+		}
+		for _, volName := range volumes {
+			record := backup.Databases[volName]
+			if record == nil || (record.StartWAL == "" && record.BinlogFile == "") {
+				return fmt.Errorf("%w: volume %s has no database backup with a log position", ErrPITRUnavailable, volName)
+			}
+		}
+	}
+
 	// Ensure alpine image is available
 	if err := s.ensureAlpineImage(ctx); err != nil {
 		return fmt.Errorf("failed to pull alpine image: %w", err)
 	}
 
-	// Open the backup file
-	file, err := os.Open(backup.FilePath)
-	if err != nil {
-		return fmt.Errorf("failed to open backup file: %w", err)
+	// Fetch the archive from the destination when needed and verify it
+	if err := s.fetchArchive(ctx, backup); err != nil {
+		return err
 	}
-	defer func() { _ = file.Close() }()
 
-	gzReader, err := gzip.NewReader(file)
+	// Open the backup file
+	archive, err := s.openArchive(ctx, backup)
 	if err != nil {
-		return fmt.Errorf("failed to create gzip reader: %w", err)
+		return err
 	}
-	defer func() { _ = gzReader.Close() }()
+	defer func() { _ = archive.Close() }()
 
-	tarReader := tar.NewReader(gzReader)
+	tarReader := tar.NewReader(archive)
 
 	// Process each volume
 	volumeSet := make(map[string]bool)
@@ -513,29 +702,39 @@ func (s *Service) RestoreBackup(ctx context.Context, backupID, targetStackID str
 
 	// Restore each volume
 	for _, volName := range volumes {
-		if err := s.restoreVolume(ctx, volName, backup.FilePath); err != nil {
+		if err := s.restoreVolume(ctx, volName, backup, target); err != nil {
 			return fmt.Errorf("failed to restore volume %s: %w", volName, err)
 		}
 	}
 
-	logger.Info("Restore completed", "backup_id", backupID, "volumes", volumes)
+	logger.Info("Restore completed", "backup_id", backupID, "volumes", volumes, "target_time", target)
 	return nil
 }
 
-// restoreVolume restores a single volume from the backup archive.
-func (s *Service) restoreVolume(ctx context.Context, volumeName, backupPath string) error {
-	// Open backup file
-	file, err := os.Open(backupPath)
-	if err != nil {
-		return fmt.Errorf("failed to open backup: %w", err)
+// restoreVolume restores a single volume from the backup archive. Logical
+// dumps are loaded into the running server; physical database backups
+// replace the volume content while its containers are stopped, with the WAL
+// to replay up to target when one is given.
+func (s *Service) restoreVolume(ctx context.Context, volumeName string, backup *Backup, target *time.Time) error {
+	record := backup.Databases[volumeName]
+	if record != nil && record.Method == MethodMySQLDump {
+		return s.restoreMySQLDump(ctx, volumeName, backup, record, target)
+	}
+	pitr := record != nil && target != nil
+
+	// Archive the WAL written since the last pass while the server runs
+	if pitr {
+		if err := s.archiveVolume(ctx, volumeName, true); err != nil {
+			logger.Warn("Failed to archive latest WAL before restore", "volume", volumeName, "error", err)
+		}
 	}
-	defer func() { _ = file.Close() }()
 
-	gzReader, err := gzip.NewReader(file)
+	// Open backup file
+	archive, err := s.openArchive(ctx, backup)
 	if err != nil {
-		return fmt.Errorf("failed to create gzip reader: %w", err)
+		return err
 	}
-	defer func() { _ = gzReader.Close() }()
+	defer func() { _ = archive.Close() }()
 
 	// Create a temporary tar file with just this volume's data
 	tmpFile, err := os.CreateTemp("", "restore-*.tar")
@@ -545,10 +744,16 @@ func (s *Service) restoreVolume(ctx context.Context, volumeName, backupPath stri
 	defer func() { _ = os.Remove(tmpFile.Name()) }()
 	defer func() { _ = tmpFile.Close() }()
 
-	tarReader := tar.NewReader(gzReader)
+	tarReader := tar.NewReader(archive)
 	tarWriter := tar.NewWriter(tmpFile)
 
 	prefix := volumeName + "/"
+	var autoConf string
+	var dataOwner *tar.Header
+	if record != nil {
+		autoConf = path.Join(record.DataDir, "postgresql.auto.conf")
+	}
+	autoConfWritten := false
 	for {
 		header, err := tarReader.Next()
 		if err == io.EOF {
@@ -572,6 +777,28 @@ func (s *Service) restoreVolume(ctx context.Context, volumeName, backupPath stri
 			continue
 		}
 
+		if dataOwner == nil && header.Typeflag == tar.TypeReg {
+			dataOwner = header
+		}
+
+		// Point-in-time recovery settings go to postgresql.auto.conf
+		if pitr && header.Name == autoConf {
+			content, err := io.ReadAll(tarReader)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", autoConf, err)
+			}
+			content = append(content, pgRecoveryConfig(path.Join(record.MountPath, record.DataDir, pgRestoreWALDir), *target)...)
+			header.Size = int64(len(content))
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return fmt.Errorf("failed to write header: %w", err)
+			}
+			if _, err := tarWriter.Write(content); err != nil {
+				return fmt.Errorf("failed to write %s: %w", autoConf, err)
+			}
+			autoConfWritten = true
+			continue
+		}
+
 		if err := tarWriter.WriteHeader(header); err != nil {
 			return fmt.Errorf("failed to write header: %w", err)
 		}
@@ -582,7 +809,18 @@ func (s *Service) restoreVolume(ctx context.Context, volumeName, backupPath stri
 			}
 		}
 	}
-	_ = tarWriter.Close()
+
+	if pitr {
+		if dataOwner == nil {
+			return fmt.Errorf("backup has no data for volume %s", volumeName)
+		}
+		if err := s.writePITRFiles(ctx, tarWriter, volumeName, record, *target, dataOwner, autoConfWritten); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write restore archive: %w", err)
+	}
 	_ = tmpFile.Close()
 
 	// Ensure volume exists
@@ -595,11 +833,21 @@ func (s *Service) restoreVolume(ctx context.Context, volumeName, backupPath stri
 		}
 	}
 
-	// Create container to restore the volume
+	// Create container to restore the volume. A database data directory is
+	// replaced rather than overlaid, with its server stopped.
 	containerConfig := &container.Config{
 		Image: "alpine:latest",
 		Cmd:   []string{"tar", "-xf", "/restore.tar", "-C", "/data"},
 	}
+	if record != nil {
+		containerConfig.Cmd = []string{"sh", "-c", "find /data -mindepth 1 -delete && tar -xf /restore.tar -C /data"}
+
+		stopped, err := s.stopVolumeContainers(ctx, volumeName)
+		defer s.startContainers(context.Background(), stopped)
+		if err != nil {
+			return err
+		}
+	}
 
 	hostConfig := &container.HostConfig{
 		Mounts: []mount.Mount{
@@ -642,30 +890,209 @@ func (s *Service) restoreVolume(ctx context.Context, volumeName, backupPath stri
 	return nil
 }
 
-// DeleteBackup deletes a backup and its archive file.
+// DeleteBackup deletes a backup and its archive, locally and off-site.
 func (s *Service) DeleteBackup(ctx context.Context, id string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	backup, ok := s.backups[id]
 	if !ok {
+		s.mu.Unlock()
 		return ErrBackupNotFound
 	}
 
 	// Check if operation is in progress
 	if s.inProgress[id] {
+		s.mu.Unlock()
 		return ErrBackupInProgress
 	}
+	s.inProgress[id] = true
+	s.mu.Unlock()
+
+	err := s.removeArchives(ctx, backup)
+
+	s.mu.Lock()
+	delete(s.inProgress, id)
+	if err == nil {
+		delete(s.backups, id)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	s.saveData()
+
+	logger.Info("Backup deleted", "id", id, "name", backup.Name)
+	return nil
+}
+
+// ApplyRetention prunes the completed backups that the configured retention
+// policy no longer keeps, locally and off-site, and the archived database logs
+// no remaining backup needs. It returns the pruned backups.
+func (s *Service) ApplyRetention(ctx context.Context) ([]*Backup, error) {
+	if s.config.Retention == nil || s.config.Retention.IsZero() {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	candidates := make([]*Backup, 0, len(s.backups))
+	for id, b := range s.backups {
+		if !s.inProgress[id] {
+			candidates = append(candidates, b)
+		}
+	}
+	_, prune := s.config.Retention.Select(candidates)
+	for _, b := range prune {
+		s.inProgress[b.ID] = true
+	}
+	s.mu.Unlock()
+
+	var pruned []*Backup
+	var errs []error
+	for _, b := range prune {
+		err := s.removeArchives(ctx, b)
+		s.mu.Lock()
+		delete(s.inProgress, b.ID)
+		if err == nil {
+			delete(s.backups, b.ID)
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("backup %s: %w", b.ID, err))
+			continue
+		}
+		pruned = append(pruned, b)
+		logger.Info("Backup pruned by retention", "id", b.ID, "name", b.Name, "created_at", b.CreatedAt)
+	}
 
-	// Delete the archive file
+	if len(pruned) > 0 {
+		s.saveData()
+	}
+	if err := s.pruneArchives(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("archived logs: %w", err))
+	}
+	return pruned, errors.Join(errs...)
+}
+
+// removeArchives deletes the local and off-site archives of a backup.
+func (s *Service) removeArchives(ctx context.Context, backup *Backup) error {
+	if backup.RemoteKey != "" && s.destination != nil {
+		if err := s.destination.Delete(ctx, backup.RemoteKey); err != nil {
+			return fmt.Errorf("failed to delete remote backup %s: %w", backup.Location, err)
+		}
+	}
 	if err := os.Remove(backup.FilePath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete backup file: %w", err)
 	}
+	return nil
+}
+
+// fetchArchive makes sure the local archive of a backup exists and matches
+// its checksum, downloading it from the destination when it is missing or
+// corrupted locally.
+func (s *Service) fetchArchive(ctx context.Context, backup *Backup) error {
+	err := verifyChecksum(backup.FilePath, backup.Checksum)
+	if err == nil {
+		return nil
+	}
+	if backup.RemoteKey == "" || s.destination == nil {
+		return fmt.Errorf("backup archive unavailable: %w", err)
+	}
 
-	delete(s.backups, id)
-	s.saveData()
+	logger.Info("Fetching backup from destination", "id", backup.ID, "location", backup.Location)
+	remote, err := s.destination.Download(ctx, backup.RemoteKey)
+	if err != nil {
+		return fmt.Errorf("failed to download backup from %s: %w", backup.Location, err)
+	}
+	defer func() { _ = remote.Close() }()
 
-	logger.Info("Backup deleted", "id", id, "name", backup.Name)
+	if err := os.MkdirAll(filepath.Dir(backup.FilePath), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(backup.FilePath), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := io.Copy(tmp, remote); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to download backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := verifyChecksum(tmp.Name(), backup.Checksum); err != nil {
+		return fmt.Errorf("remote backup %s: %w", backup.Location, err)
+	}
+	return os.Rename(tmp.Name(), backup.FilePath)
+}
+
+// openArchive opens the local archive of a backup and returns the decrypted,
+// decompressed tar stream.
+func (s *Service) openArchive(ctx context.Context, backup *Backup) (io.ReadCloser, error) {
+	file, err := os.Open(backup.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup file: %w", err)
+	}
+
+	var r io.Reader = file
+	if backup.Encrypted {
+		if s.config.KeySource == nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("backup is encrypted but no backup key is configured")
+		}
+		key, err := s.config.KeySource.BackupKey(ctx)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to get backup key: %w", err)
+		}
+		if r, err = newDecryptReader(file, key); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+
+	gzReader, err := gzip.NewReader(r)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	return &archiveReader{Reader: gzReader, closers: []io.Closer{gzReader, file}}, nil
+}
+
+// archiveReader closes the whole reader chain of an archive.
+type archiveReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (a *archiveReader) Close() error {
+	var errs []error
+	for _, c := range a.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// verifyChecksum checks the SHA-256 of a file. Backups made before checksums
+// were recorded only need the file to exist.
+func verifyChecksum(filePath, checksum string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if checksum == "" {
+		return nil
+	}
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return err
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != checksum {
+		return ErrChecksumMismatch
+	}
 	return nil
 }
 
@@ -683,6 +1110,10 @@ func (s *Service) GetBackupFile(ctx context.Context, id string) (string, error)
 		return "", fmt.Errorf("backup is not completed")
 	}
 
+	if err := s.fetchArchive(ctx, backup); err != nil {
+		return "", err
+	}
+
 	return backup.FilePath, nil
 }
 
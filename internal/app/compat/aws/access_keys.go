@@ -0,0 +1,179 @@
+package aws
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAccessKeyNotFound is returned when an access key is unknown or revoked.
+var ErrAccessKeyNotFound = errors.New("access key not found")
+
+// AccessKey is a Homeport-issued credential pair for the AWS compat
+// endpoints. Every key belongs to exactly one workspace.
+type AccessKey struct {
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key,omitempty"`
+	Workspace       string    `json:"workspace"`
+	Principal       string    `json:"principal"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// AccessKeyStore resolves access key IDs to their secret and owner.
+type AccessKeyStore interface {
+	LookupAccessKey(accessKeyID string) (AccessKey, error)
+}
+
+// FileAccessKeyStore keeps issued access keys in a JSON file.
+type FileAccessKeyStore struct {
+	mu       sync.RWMutex
+	filePath string
+	keys     map[string]AccessKey
+}
+
+// NewFileAccessKeyStore opens the key file at path, defaulting to
+// ~/.homeport/compat/access-keys.json. An empty path with no home directory
+// is an error; a missing file is not.
+func NewFileAccessKeyStore(path string) (*FileAccessKeyStore, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("get home directory: %w", err)
+		}
+		path = filepath.Join(home, ".homeport", "compat", "access-keys.json")
+	}
+	s := &FileAccessKeyStore{filePath: path, keys: map[string]AccessKey{}}
+	var keys []AccessKey
+	if err := readJSONFile(path, &keys); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("load access keys: %w", err)
+	}
+	for _, key := range keys {
+		s.keys[key.AccessKeyID] = key
+	}
+	return s, nil
+}
+
+// Issue creates a new access key for principal in workspace. The returned
+// key is the only place the secret is handed out.
+func (s *FileAccessKeyStore) Issue(workspace, principal string) (AccessKey, error) {
+	if workspace == "" {
+		return AccessKey{}, fmt.Errorf("workspace is required")
+	}
+	if principal == "" {
+		principal = "workspace/" + workspace
+	}
+	id, err := newAccessKeyID()
+	if err != nil {
+		return AccessKey{}, err
+	}
+	secret := make([]byte, 30)
+	if _, err := rand.Read(secret); err != nil {
+		return AccessKey{}, err
+	}
+	key := AccessKey{
+		AccessKeyID:     id,
+		SecretAccessKey: base64.StdEncoding.EncodeToString(secret),
+		Workspace:       workspace,
+		Principal:       principal,
+		CreatedAt:       time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[id] = key
+	if err := s.persist(); err != nil {
+		delete(s.keys, id)
+		return AccessKey{}, err
+	}
+	return key, nil
+}
+
+// Revoke deletes an access key.
+func (s *FileAccessKeyStore) Revoke(accessKeyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[accessKeyID]
+	if !ok {
+		return ErrAccessKeyNotFound
+	}
+	delete(s.keys, accessKeyID)
+	if err := s.persist(); err != nil {
+		s.keys[accessKeyID] = key
+		return err
+	}
+	return nil
+}
+
+// List returns the keys of workspace, or of every workspace when it is
+// empty, without their secrets.
+func (s *FileAccessKeyStore) List(workspace string) []AccessKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]AccessKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		if workspace != "" && key.Workspace != workspace {
+			continue
+		}
+		key.SecretAccessKey = ""
+		out = append(out, key)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].AccessKeyID < out[j].AccessKeyID })
+	return out
+}
+
+func (s *FileAccessKeyStore) LookupAccessKey(accessKeyID string) (AccessKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[accessKeyID]
+	if !ok {
+		return AccessKey{}, ErrAccessKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *FileAccessKeyStore) persist() error {
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0700); err != nil {
+		return fmt.Errorf("create access key directory: %w", err)
+	}
+	keys := make([]AccessKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].AccessKeyID < keys[j].AccessKeyID })
+	if err := writeJSONFile(s.filePath, keys); err != nil {
+		return fmt.Errorf("persist access keys: %w", err)
+	}
+	return nil
+}
+
+// StaticAccessKeys is an in-memory AccessKeyStore, mostly for tests.
+type StaticAccessKeys map[string]AccessKey
+
+func (k StaticAccessKeys) LookupAccessKey(accessKeyID string) (AccessKey, error) {
+	key, ok := k[accessKeyID]
+	if !ok {
+		return AccessKey{}, ErrAccessKeyNotFound
+	}
+	if key.AccessKeyID == "" {
+		key.AccessKeyID = accessKeyID
+	}
+	return key, nil
+}
+
+// newAccessKeyID returns an AWS-shaped key ID with the HPKA prefix so
+// Homeport keys are recognisable next to real ones.
+func newAccessKeyID() (string, error) {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "HPKA" + strings.ToUpper(hex.EncodeToString(raw)), nil
+}
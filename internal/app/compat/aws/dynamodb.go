@@ -1,6 +1,12 @@
 package aws
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
 	"net/http"
 	"sort"
 	"strings"
@@ -12,7 +18,7 @@ import (
 
 type DynamoDBAdapter struct {
 	mu         sync.Mutex
-	tables     map[string]*dynamoTable
+	backend    DynamoDBBackend
 	tableQuota int
 	authorizer authz.Authorizer
 	auditSink  func(authz.Decision)
@@ -20,21 +26,9 @@ type DynamoDBAdapter struct {
 
 type DynamoDBOption func(*DynamoDBAdapter)
 
-type dynamoTable struct {
-	Name                   string
-	KeyName                string
-	AttributeDefinitions   any
-	KeySchema              any
-	GlobalSecondaryIndexes any
-	StreamSpecification    any
-	BillingMode            string
-	Items                  map[string]map[string]any
-	Tags                   map[string]string
-}
-
 func NewDynamoDBAdapter(options ...DynamoDBOption) *DynamoDBAdapter {
 	adapter := &DynamoDBAdapter{
-		tables:     map[string]*dynamoTable{},
+		backend:    NewMemoryDynamoDBBackend(),
 		authorizer: authz.AllowAll,
 	}
 	for _, option := range options {
@@ -43,6 +37,15 @@ func NewDynamoDBAdapter(options ...DynamoDBOption) *DynamoDBAdapter {
 	return adapter
 }
 
+// WithDynamoDBBackend stores tables and items in backend instead of memory.
+func WithDynamoDBBackend(backend DynamoDBBackend) DynamoDBOption {
+	return func(adapter *DynamoDBAdapter) {
+		if backend != nil {
+			adapter.backend = backend
+		}
+	}
+}
+
 func WithDynamoDBAuthorizer(authorizer authz.Authorizer) DynamoDBOption {
 	return func(adapter *DynamoDBAdapter) {
 		if authorizer != nil {
@@ -76,249 +79,706 @@ func (DynamoDBAdapter) TargetEnv() map[string]string {
 	}
 }
 func (DynamoDBAdapter) ConformanceChecks() []string {
-	return []string{"create-table", "describe-table", "list-tables", "put-item", "get-item", "query", "scan", "describe-time-to-live", "list-tags-of-resource", "tag-resource", "untag-resource", "delete-table"}
+	return []string{"create-table", "describe-table", "list-tables", "put-item", "get-item", "query", "scan", "describe-time-to-live", "list-tags-of-resource", "tag-resource", "untag-resource", "delete-table", "update-item", "delete-item", "conditional-write", "batch-get-item", "batch-write-item", "transact-write-items"}
 }
 
+// dynamoMaxItemSize is the DynamoDB item size limit of 400 KB.
+const dynamoMaxItemSize = 400 << 10
+
 func (a *DynamoDBAdapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	action, body, err := decodeAWSAction(r)
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
 		return
 	}
-	if !a.authorized(w, r, action, body) {
-		return
+	for _, resource := range dynamoResources(body) {
+		if !a.authorized(w, r, action, resource) {
+			return
+		}
 	}
 
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	ctx := r.Context()
+	var response map[string]any
 	switch action {
 	case "CreateTable":
-		name := stringValue(body["TableName"])
-		if name == "" {
-			writeDynamoValidation(w, "TableName is required")
-			return
-		}
-		if !dynamoTableNameValid(name) {
-			writeDynamoValidation(w, "TableName is invalid")
-			return
-		}
-		if _, ok := a.tables[name]; ok {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"__type": "ResourceInUseException", "message": "table already exists"})
-			return
-		}
-		if a.tableQuota > 0 && len(a.tables) >= a.tableQuota {
-			writeJSON(w, http.StatusTooManyRequests, map[string]string{"__type": "LimitExceededException", "message": "table quota exceeded"})
-			return
-		}
-		table := &dynamoTable{
-			Name:                   name,
-			KeyName:                dynamoKeyName(body["KeySchema"]),
-			AttributeDefinitions:   body["AttributeDefinitions"],
-			KeySchema:              body["KeySchema"],
-			GlobalSecondaryIndexes: body["GlobalSecondaryIndexes"],
-			StreamSpecification:    body["StreamSpecification"],
-			BillingMode:            stringValue(body["BillingMode"]),
-			Items:                  map[string]map[string]any{},
-			Tags:                   dynamoTags(body["Tags"]),
-		}
-		a.tables[name] = table
-		writeJSON(w, http.StatusOK, map[string]any{"TableDescription": dynamoTableDescription(table, "ACTIVE")})
+		response, err = a.createTable(ctx, body)
 	case "DescribeTable":
-		table := a.tables[stringValue(body["TableName"])]
-		if table == nil {
-			writeDynamoNotFound(w)
-			return
-		}
-		writeJSON(w, http.StatusOK, map[string]any{"Table": dynamoTableDescription(table, "ACTIVE")})
+		response, err = a.describeTable(ctx, body)
+	case "UpdateTable":
+		response, err = a.updateTable(ctx, body)
 	case "ListTables":
-		a.writeListTables(w, body)
+		a.writeListTables(ctx, w, body)
+		return
 	case "PutItem":
-		table := a.tables[stringValue(body["TableName"])]
-		if table == nil {
-			writeDynamoNotFound(w)
-			return
+		response, err = a.putItem(ctx, body)
+	case "GetItem":
+		response, err = a.getItem(ctx, body)
+	case "UpdateItem":
+		response, err = a.updateItem(ctx, body)
+	case "DeleteItem":
+		response, err = a.deleteItem(ctx, body)
+	case "Query":
+		response, err = a.query(ctx, body)
+	case "Scan":
+		response, err = a.scan(ctx, body)
+	case "BatchGetItem":
+		response, err = a.batchGetItem(ctx, body)
+	case "BatchWriteItem":
+		response, err = a.batchWriteItem(ctx, body)
+	case "TransactGetItems":
+		response, err = a.transactGetItems(ctx, body)
+	case "TransactWriteItems":
+		response, err = a.transactWriteItems(ctx, body)
+	case "DescribeTimeToLive":
+		if _, err = a.backend.Table(ctx, stringValue(body["TableName"])); err == nil {
+			response = map[string]any{"TimeToLiveDescription": map[string]string{"TimeToLiveStatus": "DISABLED"}}
 		}
-		item, ok := body["Item"].(map[string]any)
-		if !ok {
-			writeDynamoValidation(w, "Item is required")
-			return
+	case "ListTagsOfResource":
+		var table DynamoDBTable
+		if table, err = a.tableByARN(ctx, stringValue(body["ResourceArn"])); err == nil {
+			response = map[string]any{"Tags": dynamoTagsJSON(table.Tags)}
 		}
-		key := dynamoAttributeString(item[table.KeyName])
-		if key == "" {
-			writeDynamoValidation(w, "hash key is required")
-			return
+	case "TagResource", "UntagResource":
+		var table DynamoDBTable
+		if table, err = a.tableByARN(ctx, stringValue(body["ResourceArn"])); err == nil {
+			tags := map[string]string{}
+			mergeStringMap(tags, table.Tags)
+			if action == "TagResource" {
+				mergeStringMap(tags, dynamoTags(body["Tags"]))
+			}
+			for _, key := range kmsStringList(body["TagKeys"]) {
+				delete(tags, key)
+			}
+			table.Tags = tags
+			err = a.backend.UpdateTable(ctx, table)
+			response = map[string]any{}
 		}
-		table.Items[key] = item
-		writeJSON(w, http.StatusOK, map[string]any{})
-	case "GetItem":
-		table := a.tables[stringValue(body["TableName"])]
-		if table == nil {
-			writeDynamoNotFound(w)
-			return
+	case "DeleteTable":
+		var table DynamoDBTable
+		if table, err = a.backend.Table(ctx, stringValue(body["TableName"])); err == nil {
+			if err = a.backend.DeleteTable(ctx, table.Name); err == nil {
+				response = map[string]any{"TableDescription": dynamoTableDescription(table, "DELETING")}
+			}
 		}
-		key := dynamoKeyString(table, body["Key"])
-		item := table.Items[key]
-		if item == nil {
-			writeJSON(w, http.StatusOK, map[string]any{})
-			return
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"__type": "UnknownOperationException", "message": "unsupported DynamoDB action"})
+		return
+	}
+	if err != nil {
+		writeDynamoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Tables returns every table definition, sorted by name. Tables and the item
+// methods below let in-process callers, such as the post-cutover operations
+// area, work on the state the HTTP API serves, under the same lock.
+func (a *DynamoDBAdapter) Tables(ctx context.Context) ([]DynamoDBTable, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	names, err := a.backend.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	tables := make([]DynamoDBTable, 0, len(names))
+	for _, name := range names {
+		table, err := a.backend.Table(ctx, name)
+		if err != nil {
+			return nil, err
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"Item": item})
-	case "Query":
-		table := a.tables[stringValue(body["TableName"])]
-		if table == nil {
-			writeDynamoNotFound(w)
-			return
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// ScanTable returns up to limit items of a table in key order, starting after
+// startKey when it is set, and the key to continue from when items remain. A
+// limit of zero or less returns every item.
+func (a *DynamoDBAdapter) ScanTable(ctx context.Context, table string, limit int, startKey map[string]any) ([]map[string]any, map[string]any, error) {
+	body := map[string]any{"TableName": table}
+	if limit > 0 {
+		body["Limit"] = limit
+	}
+	if len(startKey) > 0 {
+		body["ExclusiveStartKey"] = startKey
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	response, err := a.scan(ctx, body)
+	if err != nil {
+		return nil, nil, dynamoPublicError(err)
+	}
+	items, _ := response["Items"].([]map[string]any)
+	last, _ := response["LastEvaluatedKey"].(map[string]any)
+	return items, last, nil
+}
+
+// GetTableItem returns the item under key, or nil when it does not exist.
+func (a *DynamoDBAdapter) GetTableItem(ctx context.Context, table string, key map[string]any) (map[string]any, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	response, err := a.getItem(ctx, map[string]any{"TableName": table, "Key": key})
+	if err != nil {
+		return nil, dynamoPublicError(err)
+	}
+	item, _ := response["Item"].(map[string]any)
+	return item, nil
+}
+
+// PutTableItem creates or replaces an item.
+func (a *DynamoDBAdapter) PutTableItem(ctx context.Context, table string, item map[string]any) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err := a.putItem(ctx, map[string]any{"TableName": table, "Item": item})
+	return dynamoPublicError(err)
+}
+
+// DeleteTableItem deletes the item under key. Deleting a missing item is not
+// an error.
+func (a *DynamoDBAdapter) DeleteTableItem(ctx context.Context, table string, key map[string]any) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err := a.deleteItem(ctx, map[string]any{"TableName": table, "Key": key})
+	return dynamoPublicError(err)
+}
+
+func (a *DynamoDBAdapter) createTable(ctx context.Context, body map[string]any) (map[string]any, error) {
+	name := stringValue(body["TableName"])
+	if name == "" {
+		return nil, dynamoValidation("TableName is required")
+	}
+	if !dynamoTableNameValid(name) {
+		return nil, dynamoValidation("TableName is invalid")
+	}
+	if _, err := a.backend.Table(ctx, name); err == nil {
+		return nil, ErrDynamoDBTableExists
+	}
+	if a.tableQuota > 0 {
+		names, err := a.backend.ListTables(ctx)
+		if err != nil {
+			return nil, err
 		}
-		queryKeyName := table.KeyName
-		if indexName := stringValue(body["IndexName"]); indexName != "" {
-			queryKeyName = dynamoIndexKeyName(table, indexName)
-			if queryKeyName == "" {
-				writeDynamoValidation(w, "IndexName is invalid")
-				return
-			}
+		if len(names) >= a.tableQuota {
+			return nil, &dynamoError{status: http.StatusTooManyRequests, code: "LimitExceededException", message: "table quota exceeded"}
 		}
-		key, ok := dynamoQueryKey(body, queryKeyName)
-		if !ok {
-			writeDynamoValidation(w, "KeyConditionExpression is invalid")
-			return
+	}
+	table := DynamoDBTable{
+		Name:                   name,
+		HashKey:                dynamoKeyName(body["KeySchema"]),
+		RangeKey:               dynamoRangeKeyName(body["KeySchema"]),
+		AttributeDefinitions:   body["AttributeDefinitions"],
+		KeySchema:              body["KeySchema"],
+		GlobalSecondaryIndexes: body["GlobalSecondaryIndexes"],
+		LocalSecondaryIndexes:  body["LocalSecondaryIndexes"],
+		StreamSpecification:    body["StreamSpecification"],
+		BillingMode:            stringValue(body["BillingMode"]),
+		Tags:                   dynamoTags(body["Tags"]),
+		CreatedAt:              time.Now().UTC(),
+	}
+	for _, index := range dynamoIndexes(table) {
+		if index.hash == "" {
+			return nil, dynamoValidation("One or more parameter values were invalid: index %s has no HASH key", index.name)
 		}
-		items := []map[string]any{}
-		if stringValue(body["IndexName"]) != "" {
-			for _, item := range table.Items {
-				if dynamoAttributeString(item[queryKeyName]) == key {
-					items = append(items, item)
-				}
+	}
+	if err := a.backend.CreateTable(ctx, table); err != nil {
+		return nil, err
+	}
+	return map[string]any{"TableDescription": dynamoTableDescription(table, "ACTIVE")}, nil
+}
+
+func (a *DynamoDBAdapter) describeTable(ctx context.Context, body map[string]any) (map[string]any, error) {
+	table, err := a.backend.Table(ctx, stringValue(body["TableName"]))
+	if err != nil {
+		return nil, err
+	}
+	items, err := a.backend.Items(ctx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	description := dynamoTableDescription(table, "ACTIVE")
+	description["ItemCount"] = len(items)
+	return map[string]any{"Table": description}, nil
+}
+
+// updateTable applies billing, stream and global secondary index changes.
+// Indexes are evaluated on read, so new ones are ACTIVE immediately.
+func (a *DynamoDBAdapter) updateTable(ctx context.Context, body map[string]any) (map[string]any, error) {
+	table, err := a.backend.Table(ctx, stringValue(body["TableName"]))
+	if err != nil {
+		return nil, err
+	}
+	if mode := stringValue(body["BillingMode"]); mode != "" {
+		table.BillingMode = mode
+	}
+	if spec, ok := body["StreamSpecification"]; ok {
+		table.StreamSpecification = spec
+	}
+	if definitions, ok := body["AttributeDefinitions"].([]any); ok {
+		existing, _ := table.AttributeDefinitions.([]any)
+		for _, definition := range definitions {
+			name := stringValue(mapValue(definition)["AttributeName"])
+			if dynamoAttributeType(table, name) == "" {
+				existing = append(existing, definition)
 			}
-		} else if item := table.Items[key]; item != nil {
-			items = append(items, item)
 		}
-		sort.Slice(items, func(i, j int) bool {
-			return dynamoAttributeString(items[i][table.KeyName]) < dynamoAttributeString(items[j][table.KeyName])
-		})
-		start := 0
-		if itemKey := dynamoKeyString(table, body["ExclusiveStartKey"]); itemKey != "" {
-			found := false
-			for i, item := range items {
-				if dynamoAttributeString(item[table.KeyName]) == itemKey {
-					start = i + 1
-					found = true
-					break
-				}
+		table.AttributeDefinitions = existing
+	}
+	indexes, _ := table.GlobalSecondaryIndexes.([]any)
+	updates, _ := body["GlobalSecondaryIndexUpdates"].([]any)
+	for _, raw := range updates {
+		update, _ := raw.(map[string]any)
+		if create, ok := update["Create"].(map[string]any); ok {
+			name := stringValue(create["IndexName"])
+			if _, exists := dynamoFindIndex(table, name); exists {
+				return nil, dynamoValidation("One or more parameter values were invalid: index %s already exists", name)
 			}
-			if !found {
-				writeDynamoValidation(w, "ExclusiveStartKey is invalid")
-				return
+			if dynamoKeyName(create["KeySchema"]) == "" {
+				return nil, dynamoValidation("One or more parameter values were invalid: index %s has no HASH key", name)
 			}
+			indexes = append(indexes, create)
 		}
-		limit, ok := cloudWatchLogsLimit(body, 1000, 1000, "Limit")
-		if !ok {
-			writeDynamoValidation(w, "Limit must be between 1 and 1000")
-			return
+		if remove, ok := update["Delete"].(map[string]any); ok {
+			name := stringValue(remove["IndexName"])
+			kept := indexes[:0:0]
+			for _, index := range indexes {
+				if stringValue(mapValue(index)["IndexName"]) != name {
+					kept = append(kept, index)
+				}
+			}
+			if len(kept) == len(indexes) {
+				return nil, &dynamoError{code: "ResourceNotFoundException", message: "index " + name + " not found"}
+			}
+			indexes = kept
 		}
-		end := start + limit
-		if end > len(items) {
-			end = len(items)
+	}
+	if len(updates) > 0 {
+		table.GlobalSecondaryIndexes = indexes
+	}
+	if err := a.backend.UpdateTable(ctx, table); err != nil {
+		return nil, err
+	}
+	return map[string]any{"TableDescription": dynamoTableDescription(table, "ACTIVE")}, nil
+}
+
+func (a *DynamoDBAdapter) putItem(ctx context.Context, body map[string]any) (map[string]any, error) {
+	table, err := a.backend.Table(ctx, stringValue(body["TableName"]))
+	if err != nil {
+		return nil, err
+	}
+	item, ok := body["Item"].(map[string]any)
+	if !ok {
+		return nil, dynamoValidation("Item is required")
+	}
+	key, err := dynamoItemKey(table, item)
+	if err != nil {
+		return nil, err
+	}
+	if err := dynamoValidateItem(table, item); err != nil {
+		return nil, err
+	}
+	old, err := a.backend.GetItem(ctx, table.Name, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := dynamoCheckCondition(body, old); err != nil {
+		return nil, err
+	}
+	if err := a.backend.WriteItems(ctx, []DynamoDBWrite{{Table: table.Name, Key: key, Item: item}}); err != nil {
+		return nil, err
+	}
+	return dynamoReturnValues(body, old, item, nil)
+}
+
+func (a *DynamoDBAdapter) getItem(ctx context.Context, body map[string]any) (map[string]any, error) {
+	table, err := a.backend.Table(ctx, stringValue(body["TableName"]))
+	if err != nil {
+		return nil, err
+	}
+	key, err := dynamoRequestKey(table, body["Key"])
+	if err != nil {
+		return nil, err
+	}
+	projection, err := parseDynamoProjection(stringValue(body["ProjectionExpression"]), dynamoNames(body))
+	if err != nil {
+		return nil, dynamoValidation("Invalid ProjectionExpression: %v", err)
+	}
+	item, err := a.backend.GetItem(ctx, table.Name, key)
+	if err != nil || item == nil {
+		return map[string]any{}, err
+	}
+	return map[string]any{"Item": dynamoProject(item, projection)}, nil
+}
+
+func (a *DynamoDBAdapter) updateItem(ctx context.Context, body map[string]any) (map[string]any, error) {
+	table, err := a.backend.Table(ctx, stringValue(body["TableName"]))
+	if err != nil {
+		return nil, err
+	}
+	keyValue, _ := body["Key"].(map[string]any)
+	key, err := dynamoRequestKey(table, keyValue)
+	if err != nil {
+		return nil, err
+	}
+	old, err := a.backend.GetItem(ctx, table.Name, key)
+	if err != nil {
+		return nil, err
+	}
+	updated, update, err := dynamoApplyUpdateItem(table, body, keyValue, old)
+	if err != nil {
+		return nil, err
+	}
+	if err := dynamoCheckCondition(body, old); err != nil {
+		return nil, err
+	}
+	if err := a.backend.WriteItems(ctx, []DynamoDBWrite{{Table: table.Name, Key: key, Item: updated}}); err != nil {
+		return nil, err
+	}
+	return dynamoReturnValues(body, old, updated, update)
+}
+
+func (a *DynamoDBAdapter) deleteItem(ctx context.Context, body map[string]any) (map[string]any, error) {
+	table, err := a.backend.Table(ctx, stringValue(body["TableName"]))
+	if err != nil {
+		return nil, err
+	}
+	key, err := dynamoRequestKey(table, body["Key"])
+	if err != nil {
+		return nil, err
+	}
+	old, err := a.backend.GetItem(ctx, table.Name, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := dynamoCheckCondition(body, old); err != nil {
+		return nil, err
+	}
+	if old != nil {
+		if err := a.backend.WriteItems(ctx, []DynamoDBWrite{{Table: table.Name, Key: key}}); err != nil {
+			return nil, err
 		}
-		response := map[string]any{"Items": items[start:end], "Count": end - start, "ScannedCount": end - start}
-		if end < len(items) {
-			response["LastEvaluatedKey"] = map[string]any{table.KeyName: items[end-1][table.KeyName]}
+	}
+	return dynamoReturnValues(body, old, nil, nil)
+}
+
+func (a *DynamoDBAdapter) query(ctx context.Context, body map[string]any) (map[string]any, error) {
+	table, err := a.backend.Table(ctx, stringValue(body["TableName"]))
+	if err != nil {
+		return nil, err
+	}
+	index, err := dynamoRequestIndex(table, body)
+	if err != nil {
+		return nil, err
+	}
+	hashKey, rangeKey := table.HashKey, table.RangeKey
+	if index != nil {
+		hashKey, rangeKey = index.hash, index.rangeKey
+	}
+	expression := stringValue(body["KeyConditionExpression"])
+	if expression == "" {
+		return nil, dynamoValidation("Either the KeyConditions or KeyConditionExpression parameter must be specified in the request.")
+	}
+	keyCondition, err := parseDynamoCondition(expression, dynamoNames(body), dynamoValues(body))
+	if err != nil {
+		return nil, dynamoValidation("Invalid KeyConditionExpression: %v", err)
+	}
+	if err := dynamoCheckKeyCondition(keyCondition, hashKey, rangeKey); err != nil {
+		return nil, err
+	}
+	items, err := a.backend.Items(ctx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	matched := items[:0:0]
+	for _, item := range items {
+		if dynamoInIndex(table, index, item) && dynamoMatches(keyCondition, item) {
+			matched = append(matched, item)
 		}
-		writeJSON(w, http.StatusOK, response)
-	case "Scan":
-		a.writeScan(w, body)
-	case "DescribeTimeToLive":
-		if a.tables[stringValue(body["TableName"])] == nil {
-			writeDynamoNotFound(w)
-			return
+	}
+	return dynamoPage(table, index, matched, dynamoOrder(rangeKey, table.HashKey, table.RangeKey), body)
+}
+
+func (a *DynamoDBAdapter) scan(ctx context.Context, body map[string]any) (map[string]any, error) {
+	table, err := a.backend.Table(ctx, stringValue(body["TableName"]))
+	if err != nil {
+		return nil, err
+	}
+	index, err := dynamoRequestIndex(table, body)
+	if err != nil {
+		return nil, err
+	}
+	segment, segments := 0, 1
+	if raw, ok := body["TotalSegments"]; ok {
+		segments, _ = cloudWatchLogsLimit(map[string]any{"n": raw}, 1, 1000000, "n")
+		segment = int(floatValue(body["Segment"]))
+		if segments < 1 || segment < 0 || segment >= segments {
+			return nil, dynamoValidation("Segment must be less than TotalSegments")
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"TimeToLiveDescription": map[string]string{"TimeToLiveStatus": "DISABLED"}})
-	case "ListTagsOfResource":
-		table := a.tableByARN(stringValue(body["ResourceArn"]))
-		if table == nil {
-			writeDynamoNotFound(w)
-			return
+	}
+	items, err := a.backend.Items(ctx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	hashKey := table.HashKey
+	if index != nil {
+		hashKey = index.hash
+	}
+	matched := items[:0:0]
+	for _, item := range items {
+		if !dynamoInIndex(table, index, item) {
+			continue
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"Tags": dynamoTagsJSON(table.Tags)})
-	case "TagResource":
-		table := a.tableByARN(stringValue(body["ResourceArn"]))
-		if table == nil {
-			writeDynamoNotFound(w)
-			return
+		if segments > 1 {
+			hash := fnv.New32a()
+			value, _ := json.Marshal(item[hashKey])
+			hash.Write(value)
+			if int(hash.Sum32()%uint32(segments)) != segment {
+				continue
+			}
 		}
-		mergeStringMap(table.Tags, dynamoTags(body["Tags"]))
-		writeJSON(w, http.StatusOK, map[string]any{})
-	case "UntagResource":
-		table := a.tableByARN(stringValue(body["ResourceArn"]))
-		if table == nil {
-			writeDynamoNotFound(w)
-			return
+		matched = append(matched, item)
+	}
+	body = copyAnyMap(body)
+	delete(body, "ScanIndexForward")
+	order := dynamoOrder(table.HashKey, table.RangeKey)
+	if index != nil {
+		order = dynamoOrder(index.hash, index.rangeKey, table.HashKey, table.RangeKey)
+	}
+	return dynamoPage(table, index, matched, order, body)
+}
+
+func (a *DynamoDBAdapter) batchGetItem(ctx context.Context, body map[string]any) (map[string]any, error) {
+	requests, _ := body["RequestItems"].(map[string]any)
+	total := 0
+	for _, raw := range requests {
+		keys, _ := mapValueAny(raw)["Keys"].([]any)
+		total += len(keys)
+	}
+	if total == 0 || total > 100 {
+		return nil, dynamoValidation("Too many items requested for the BatchGetItem call")
+	}
+	responses := map[string]any{}
+	for _, name := range sortedAnyKeys(requests) {
+		request := mapValueAny(requests[name])
+		table, err := a.backend.Table(ctx, name)
+		if err != nil {
+			return nil, err
 		}
-		for _, key := range kmsStringList(body["TagKeys"]) {
-			delete(table.Tags, key)
+		projection, err := parseDynamoProjection(stringValue(request["ProjectionExpression"]), dynamoNames(request))
+		if err != nil {
+			return nil, dynamoValidation("Invalid ProjectionExpression: %v", err)
 		}
-		writeJSON(w, http.StatusOK, map[string]any{})
-	case "DeleteTable":
-		name := stringValue(body["TableName"])
-		table := a.tables[name]
-		if table == nil {
-			writeDynamoNotFound(w)
-			return
+		found := []map[string]any{}
+		seen := map[string]bool{}
+		keys, _ := request["Keys"].([]any)
+		for _, raw := range keys {
+			key, err := dynamoRequestKey(table, raw)
+			if err != nil {
+				return nil, err
+			}
+			if seen[key] {
+				return nil, dynamoValidation("Provided list of item keys contains duplicates")
+			}
+			seen[key] = true
+			item, err := a.backend.GetItem(ctx, name, key)
+			if err != nil {
+				return nil, err
+			}
+			if item != nil {
+				found = append(found, dynamoProject(item, projection))
+			}
 		}
-		delete(a.tables, name)
-		writeJSON(w, http.StatusOK, map[string]any{"TableDescription": dynamoTableDescription(table, "DELETING")})
-	default:
-		writeJSON(w, http.StatusBadRequest, map[string]string{"__type": "UnknownOperationException", "message": "unsupported DynamoDB action"})
+		responses[name] = found
 	}
+	return map[string]any{"Responses": responses, "UnprocessedKeys": map[string]any{}}, nil
 }
 
-func (a *DynamoDBAdapter) writeScan(w http.ResponseWriter, body map[string]any) {
-	table := a.tables[stringValue(body["TableName"])]
-	if table == nil {
-		writeDynamoNotFound(w)
-		return
+func (a *DynamoDBAdapter) batchWriteItem(ctx context.Context, body map[string]any) (map[string]any, error) {
+	requests, _ := body["RequestItems"].(map[string]any)
+	var writes []DynamoDBWrite
+	for _, name := range sortedAnyKeys(requests) {
+		table, err := a.backend.Table(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		seen := map[string]bool{}
+		entries, _ := requests[name].([]any)
+		for _, raw := range entries {
+			entry := mapValueAny(raw)
+			write := DynamoDBWrite{Table: name}
+			if put, ok := entry["PutRequest"].(map[string]any); ok {
+				item, _ := put["Item"].(map[string]any)
+				if write.Key, err = dynamoItemKey(table, item); err != nil {
+					return nil, err
+				}
+				if err := dynamoValidateItem(table, item); err != nil {
+					return nil, err
+				}
+				write.Item = item
+			} else if del, ok := entry["DeleteRequest"].(map[string]any); ok {
+				if write.Key, err = dynamoRequestKey(table, del["Key"]); err != nil {
+					return nil, err
+				}
+			} else {
+				return nil, dynamoValidation("Each request must contain a PutRequest or a DeleteRequest")
+			}
+			if seen[write.Key] {
+				return nil, dynamoValidation("Provided list of item keys contains duplicates")
+			}
+			seen[write.Key] = true
+			writes = append(writes, write)
+		}
 	}
-	keys := make([]string, 0, len(table.Items))
-	for key := range table.Items {
-		keys = append(keys, key)
+	if len(writes) == 0 || len(writes) > 25 {
+		return nil, dynamoValidation("Member must have length less than or equal to 25 and greater than or equal to 1")
 	}
-	sort.Strings(keys)
+	if err := a.backend.WriteItems(ctx, writes); err != nil {
+		return nil, err
+	}
+	return map[string]any{"UnprocessedItems": map[string]any{}}, nil
+}
 
-	start := 0
-	if key := dynamoKeyString(table, body["ExclusiveStartKey"]); key != "" {
-		index := sort.SearchStrings(keys, key)
-		if index == len(keys) || keys[index] != key {
-			writeDynamoValidation(w, "ExclusiveStartKey is invalid")
-			return
+func (a *DynamoDBAdapter) transactGetItems(ctx context.Context, body map[string]any) (map[string]any, error) {
+	entries, _ := body["TransactItems"].([]any)
+	if len(entries) == 0 || len(entries) > 100 {
+		return nil, dynamoValidation("TransactItems must have between 1 and 100 items")
+	}
+	responses := make([]map[string]any, 0, len(entries))
+	for _, raw := range entries {
+		get, ok := mapValueAny(raw)["Get"].(map[string]any)
+		if !ok {
+			return nil, dynamoValidation("Each TransactItem must contain a Get")
+		}
+		table, err := a.backend.Table(ctx, stringValue(get["TableName"]))
+		if err != nil {
+			return nil, err
+		}
+		key, err := dynamoRequestKey(table, get["Key"])
+		if err != nil {
+			return nil, err
+		}
+		projection, err := parseDynamoProjection(stringValue(get["ProjectionExpression"]), dynamoNames(get))
+		if err != nil {
+			return nil, dynamoValidation("Invalid ProjectionExpression: %v", err)
 		}
-		start = index + 1
+		item, err := a.backend.GetItem(ctx, table.Name, key)
+		if err != nil {
+			return nil, err
+		}
+		response := map[string]any{}
+		if item != nil {
+			response["Item"] = dynamoProject(item, projection)
+		}
+		responses = append(responses, response)
 	}
-	limit, ok := cloudWatchLogsLimit(body, 1000, 1000, "Limit")
-	if !ok {
-		writeDynamoValidation(w, "Limit must be between 1 and 1000")
-		return
+	return map[string]any{"Responses": responses}, nil
+}
+
+// transactWriteItems checks every condition before writing anything and
+// cancels the whole transaction when one fails, reporting a reason per item.
+func (a *DynamoDBAdapter) transactWriteItems(ctx context.Context, body map[string]any) (map[string]any, error) {
+	entries, _ := body["TransactItems"].([]any)
+	if len(entries) == 0 || len(entries) > 100 {
+		return nil, dynamoValidation("TransactItems must have between 1 and 100 items")
 	}
-	end := start + limit
-	if end > len(keys) {
-		end = len(keys)
+	var writes []DynamoDBWrite
+	reasons := make([]map[string]any, len(entries))
+	codes := make([]string, len(entries))
+	failed := false
+	seen := map[string]bool{}
+	for i, raw := range entries {
+		entry := mapValueAny(raw)
+		var kind string
+		var op map[string]any
+		for _, candidate := range []string{"ConditionCheck", "Put", "Delete", "Update"} {
+			if value, ok := entry[candidate].(map[string]any); ok {
+				kind, op = candidate, value
+				break
+			}
+		}
+		if op == nil {
+			return nil, dynamoValidation("Each TransactItem must contain one of ConditionCheck, Put, Delete or Update")
+		}
+		table, err := a.backend.Table(ctx, stringValue(op["TableName"]))
+		if err != nil {
+			return nil, err
+		}
+		var key string
+		if kind == "Put" {
+			item, _ := op["Item"].(map[string]any)
+			if key, err = dynamoItemKey(table, item); err == nil {
+				err = dynamoValidateItem(table, item)
+			}
+		} else {
+			key, err = dynamoRequestKey(table, op["Key"])
+		}
+		if err != nil {
+			return nil, err
+		}
+		if seen[table.Name+"\x00"+key] {
+			return nil, dynamoValidation("Transaction request cannot include multiple operations on one item")
+		}
+		seen[table.Name+"\x00"+key] = true
+		if kind == "ConditionCheck" && stringValue(op["ConditionExpression"]) == "" {
+			return nil, dynamoValidation("ConditionCheck requires a ConditionExpression")
+		}
+
+		old, err := a.backend.GetItem(ctx, table.Name, key)
+		if err != nil {
+			return nil, err
+		}
+		write := DynamoDBWrite{Table: table.Name, Key: key}
+		switch kind {
+		case "Put":
+			write.Item, _ = op["Item"].(map[string]any)
+		case "Update":
+			keyValue, _ := op["Key"].(map[string]any)
+			if write.Item, _, err = dynamoApplyUpdateItem(table, op, keyValue, old); err != nil {
+				return nil, err
+			}
+		}
+		codes[i], reasons[i] = "None", map[string]any{"Code": "None"}
+		if err := dynamoCheckCondition(op, old); err != nil {
+			var condErr *dynamoError
+			if !errors.As(err, &condErr) || condErr.code != "ConditionalCheckFailedException" {
+				return nil, err
+			}
+			failed = true
+			codes[i] = "ConditionalCheckFailed"
+			reasons[i] = map[string]any{"Code": "ConditionalCheckFailed", "Message": "The conditional request failed"}
+			if item, ok := condErr.fields["Item"]; ok {
+				reasons[i]["Item"] = item
+			}
+		}
+		if kind != "ConditionCheck" {
+			writes = append(writes, write)
+		}
 	}
-	items := make([]map[string]any, 0, end-start)
-	for _, key := range keys[start:end] {
-		items = append(items, table.Items[key])
+	if failed {
+		return nil, &dynamoError{
+			code:    "TransactionCanceledException",
+			message: "Transaction cancelled, please refer cancellation reasons for specific reasons [" + strings.Join(codes, ", ") + "]",
+			fields:  map[string]any{"CancellationReasons": reasons},
+		}
 	}
-	response := map[string]any{"Items": items, "Count": len(items), "ScannedCount": len(items)}
-	if end < len(keys) {
-		response["LastEvaluatedKey"] = map[string]any{table.KeyName: table.Items[keys[end-1]][table.KeyName]}
+	if err := a.backend.WriteItems(ctx, writes); err != nil {
+		return nil, err
 	}
-	writeJSON(w, http.StatusOK, response)
+	return map[string]any{}, nil
 }
 
-func (a *DynamoDBAdapter) writeListTables(w http.ResponseWriter, body map[string]any) {
-	names := make([]string, 0, len(a.tables))
-	for name := range a.tables {
-		names = append(names, name)
+func (a *DynamoDBAdapter) writeListTables(ctx context.Context, w http.ResponseWriter, body map[string]any) {
+	names, err := a.backend.ListTables(ctx)
+	if err != nil {
+		writeDynamoError(w, err)
+		return
 	}
-	sort.Strings(names)
 
 	start := 0
 	if after := stringValue(body["ExclusiveStartTableName"]); after != "" {
@@ -364,17 +824,16 @@ func dynamoTableNameValid(name string) bool {
 	return len(name) >= 3 && len(name) <= 255 && dynamoTableNameCharsValid(name)
 }
 
-func (a *DynamoDBAdapter) tableByARN(arn string) *dynamoTable {
-	name := arn[strings.LastIndex(arn, "/")+1:]
-	return a.tables[name]
+func (a *DynamoDBAdapter) tableByARN(ctx context.Context, arn string) (DynamoDBTable, error) {
+	return a.backend.Table(ctx, arn[strings.LastIndex(arn, "/")+1:])
 }
 
-func (a *DynamoDBAdapter) authorized(w http.ResponseWriter, r *http.Request, action string, body map[string]any) bool {
+func (a *DynamoDBAdapter) authorized(w http.ResponseWriter, r *http.Request, action, resource string) bool {
 	req := authz.Request{
 		Principal:           awsPrincipal(r),
 		PrincipalAttributes: awsPrincipalAttributes(r),
 		Action:              "dynamodb:" + action,
-		Resource:            dynamoARN(body),
+		Resource:            resource,
 		Context: map[string]string{
 			"provider":     "aws",
 			"service":      "dynamodb",
@@ -410,11 +869,34 @@ func (a *DynamoDBAdapter) authorized(w http.ResponseWriter, r *http.Request, act
 	return true
 }
 
-func dynamoARN(body map[string]any) string {
+// dynamoResources returns the ARN of every table a request touches. Batch
+// and transaction requests are authorized once per table.
+func dynamoResources(body map[string]any) []string {
 	if arn := stringValue(body["ResourceArn"]); arn != "" {
-		return arn
+		return []string{arn}
+	}
+	names := map[string]bool{}
+	if requests, ok := body["RequestItems"].(map[string]any); ok {
+		for name := range requests {
+			names[name] = true
+		}
+	}
+	if entries, ok := body["TransactItems"].([]any); ok {
+		for _, entry := range entries {
+			for _, op := range mapValueAny(entry) {
+				names[stringValue(mapValueAny(op)["TableName"])] = true
+			}
+		}
 	}
-	return dynamoTableARN(stringValue(body["TableName"]))
+	if len(names) == 0 {
+		return []string{dynamoTableARN(stringValue(body["TableName"]))}
+	}
+	resources := make([]string, 0, len(names))
+	for name := range names {
+		resources = append(resources, dynamoTableARN(name))
+	}
+	sort.Strings(resources)
+	return resources
 }
 
 func dynamoTableARN(name string) string {
@@ -424,16 +906,22 @@ func dynamoTableARN(name string) string {
 	return "arn:aws:dynamodb:us-east-1:homeport:table/" + name
 }
 
-func dynamoTableDescription(table *dynamoTable, status string) map[string]any {
+func dynamoTableDescription(table DynamoDBTable, status string) map[string]any {
 	description := map[string]any{
 		"TableName":              table.Name,
 		"TableStatus":            status,
 		"TableArn":               "arn:aws:dynamodb:us-east-1:000000000000:table/" + table.Name,
 		"AttributeDefinitions":   table.AttributeDefinitions,
 		"KeySchema":              table.KeySchema,
-		"GlobalSecondaryIndexes": dynamoIndexDescriptions(table),
+		"GlobalSecondaryIndexes": dynamoIndexDescriptions(table, table.GlobalSecondaryIndexes),
 		"BillingModeSummary":     map[string]string{"BillingMode": table.BillingMode},
 	}
+	if !table.CreatedAt.IsZero() {
+		description["CreationDateTime"] = float64(table.CreatedAt.UnixMilli()) / 1000
+	}
+	if table.LocalSecondaryIndexes != nil {
+		description["LocalSecondaryIndexes"] = dynamoIndexDescriptions(table, table.LocalSecondaryIndexes)
+	}
 	if dynamoStreamEnabled(table.StreamSpecification) {
 		description["StreamSpecification"] = table.StreamSpecification
 		description["LatestStreamArn"] = dynamoTableARN(table.Name) + "/stream/2026-07-09T00:00:00.000"
@@ -442,8 +930,8 @@ func dynamoTableDescription(table *dynamoTable, status string) map[string]any {
 	return description
 }
 
-func dynamoIndexDescriptions(table *dynamoTable) []map[string]any {
-	indexes, _ := table.GlobalSecondaryIndexes.([]any)
+func dynamoIndexDescriptions(table DynamoDBTable, raw any) []map[string]any {
+	indexes, _ := raw.([]any)
 	out := make([]map[string]any, 0, len(indexes))
 	for _, item := range indexes {
 		index, _ := item.(map[string]any)
@@ -496,56 +984,521 @@ func dynamoTagsJSON(tags map[string]string) []map[string]string {
 }
 
 func dynamoKeyName(value any) string {
+	return dynamoKeySchemaName(value, "HASH", "id")
+}
+
+func dynamoRangeKeyName(value any) string {
+	return dynamoKeySchemaName(value, "RANGE", "")
+}
+
+func dynamoKeySchemaName(value any, keyType, fallback string) string {
 	items, _ := value.([]any)
 	for _, item := range items {
 		entry, _ := item.(map[string]any)
-		if stringValue(entry["KeyType"]) == "HASH" {
+		if stringValue(entry["KeyType"]) == keyType {
 			return stringValue(entry["AttributeName"])
 		}
 	}
-	return "id"
+	return fallback
 }
 
-func dynamoIndexKeyName(table *dynamoTable, indexName string) string {
-	indexes, _ := table.GlobalSecondaryIndexes.([]any)
-	for _, item := range indexes {
-		index, _ := item.(map[string]any)
-		if stringValue(index["IndexName"]) == indexName {
-			return dynamoKeyName(index["KeySchema"])
+func dynamoAttributeType(table DynamoDBTable, name string) string {
+	definitions, _ := table.AttributeDefinitions.([]any)
+	for _, definition := range definitions {
+		entry := mapValue(definition)
+		if entry["AttributeName"] == name {
+			return entry["AttributeType"]
 		}
 	}
 	return ""
 }
 
-func dynamoKeyString(table *dynamoTable, value any) string {
-	key, _ := value.(map[string]any)
-	return dynamoAttributeString(key[table.KeyName])
+type dynamoIndex struct {
+	name       string
+	hash       string
+	rangeKey   string
+	projection string
+	include    []string
+}
+
+func dynamoIndexes(table DynamoDBTable) []dynamoIndex {
+	var out []dynamoIndex
+	for _, raw := range []any{table.GlobalSecondaryIndexes, table.LocalSecondaryIndexes} {
+		indexes, _ := raw.([]any)
+		for _, item := range indexes {
+			index, _ := item.(map[string]any)
+			projection, _ := index["Projection"].(map[string]any)
+			out = append(out, dynamoIndex{
+				name:       stringValue(index["IndexName"]),
+				hash:       dynamoKeySchemaName(index["KeySchema"], "HASH", ""),
+				rangeKey:   dynamoRangeKeyName(index["KeySchema"]),
+				projection: stringValue(projection["ProjectionType"]),
+				include:    kmsStringList(projection["NonKeyAttributes"]),
+			})
+		}
+	}
+	return out
+}
+
+func dynamoFindIndex(table DynamoDBTable, name string) (dynamoIndex, bool) {
+	for _, index := range dynamoIndexes(table) {
+		if index.name == name {
+			return index, true
+		}
+	}
+	return dynamoIndex{}, false
 }
 
-func dynamoQueryKey(body map[string]any, expectedName string) (string, bool) {
-	left, right, ok := strings.Cut(stringValue(body["KeyConditionExpression"]), "=")
+func dynamoRequestIndex(table DynamoDBTable, body map[string]any) (*dynamoIndex, error) {
+	name := stringValue(body["IndexName"])
+	if name == "" {
+		return nil, nil
+	}
+	index, ok := dynamoFindIndex(table, name)
 	if !ok {
-		return "", false
+		return nil, dynamoValidation("The table does not have the specified index: %s", name)
+	}
+	return &index, nil
+}
+
+// dynamoInIndex reports whether item appears in index: secondary indexes
+// are sparse and only hold items that have all of their key attributes.
+func dynamoInIndex(table DynamoDBTable, index *dynamoIndex, item map[string]any) bool {
+	if index == nil {
+		return true
 	}
-	name := strings.TrimSpace(left)
-	if names, ok := body["ExpressionAttributeNames"].(map[string]any); ok {
-		name = stringValue(names[name])
+	for _, name := range []string{index.hash, index.rangeKey} {
+		if name == "" {
+			continue
+		}
+		value, ok := item[name].(map[string]any)
+		if !ok || dynamoValueType(value) != dynamoAttributeTypeOr(table, name, dynamoValueType(value)) {
+			return false
+		}
 	}
-	if name != expectedName {
-		return "", false
+	return true
+}
+
+func dynamoAttributeTypeOr(table DynamoDBTable, name, fallback string) string {
+	if kind := dynamoAttributeType(table, name); kind != "" {
+		return kind
 	}
-	parts := strings.Fields(right)
-	if len(parts) == 0 {
-		return "", false
+	return fallback
+}
+
+// dynamoOrder returns the distinct, non-empty attributes items are sorted
+// by, most significant first.
+func dynamoOrder(names ...string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
 	}
+	return out
+}
+
+// dynamoKeyAttributes returns the attributes that identify an item in
+// table or index: LastEvaluatedKey holds exactly these.
+func dynamoKeyAttributes(table DynamoDBTable, index *dynamoIndex) []string {
+	names := []string{table.HashKey}
+	if table.RangeKey != "" {
+		names = append(names, table.RangeKey)
+	}
+	if index != nil {
+		for _, name := range []string{index.hash, index.rangeKey} {
+			if name != "" && name != table.HashKey && name != table.RangeKey {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// dynamoPage sorts items by sortBy, applies ExclusiveStartKey, Limit,
+// FilterExpression, Select and the projections, and builds a Query or Scan
+// response.
+func dynamoPage(table DynamoDBTable, index *dynamoIndex, items []map[string]any, sortBy []string, body map[string]any) (map[string]any, error) {
+	names, values := dynamoNames(body), dynamoValues(body)
+	filter, err := parseDynamoCondition(stringValue(body["FilterExpression"]), names, values)
+	if err != nil {
+		return nil, dynamoValidation("Invalid FilterExpression: %v", err)
+	}
+	projection, err := parseDynamoProjection(stringValue(body["ProjectionExpression"]), names)
+	if err != nil {
+		return nil, dynamoValidation("Invalid ProjectionExpression: %v", err)
+	}
+	direction := 1
+	if forward, ok := body["ScanIndexForward"].(bool); ok && !forward {
+		direction = -1
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return direction*dynamoCompareAttributes(items[i], items[j], sortBy) < 0
+	})
+
+	keyAttributes := dynamoKeyAttributes(table, index)
+	start := 0
+	if startKey, ok := body["ExclusiveStartKey"].(map[string]any); ok && len(startKey) > 0 {
+		for _, name := range keyAttributes {
+			if _, ok := startKey[name].(map[string]any); !ok {
+				return nil, dynamoValidation("The provided starting key is invalid: missing key attribute %s", name)
+			}
+		}
+		start = sort.Search(len(items), func(i int) bool {
+			return direction*dynamoCompareAttributes(items[i], startKey, sortBy) > 0
+		})
+	}
+	limit, ok := cloudWatchLogsLimit(body, math.MaxInt32, math.MaxInt32, "Limit")
+	if !ok {
+		return nil, dynamoValidation("Limit must be greater than or equal to 1")
+	}
+	end := len(items)
+	if limit < end-start {
+		end = start + limit
+	}
+
+	results := []map[string]any{}
+	for _, item := range items[start:end] {
+		if !dynamoMatches(filter, item) {
+			continue
+		}
+		results = append(results, dynamoProject(dynamoIndexProjection(table, index, item), projection))
+	}
+	response := map[string]any{"Count": len(results), "ScannedCount": end - start}
+	if stringValue(body["Select"]) != "COUNT" {
+		response["Items"] = results
+	}
+	if end < len(items) {
+		last := map[string]any{}
+		for _, name := range keyAttributes {
+			last[name] = items[end-1][name]
+		}
+		response["LastEvaluatedKey"] = last
+	}
+	return response, nil
+}
+
+func dynamoCompareAttributes(a, b map[string]any, names []string) int {
+	for _, name := range names {
+		x, _ := a[name].(map[string]any)
+		y, _ := b[name].(map[string]any)
+		if cmp, ok := dynamoCompare(x, y); ok && cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// dynamoIndexProjection trims item to the attributes index projects.
+func dynamoIndexProjection(table DynamoDBTable, index *dynamoIndex, item map[string]any) map[string]any {
+	if index == nil || index.projection == "" || index.projection == "ALL" {
+		return item
+	}
+	keep := dynamoKeyAttributes(table, index)
+	if index.projection == "INCLUDE" {
+		keep = append(keep, index.include...)
+	}
+	out := map[string]any{}
+	for _, name := range keep {
+		if value, ok := item[name]; ok {
+			out[name] = value
+		}
+	}
+	return out
+}
+
+// dynamoCheckKeyCondition accepts an equality on the hash key, optionally
+// ANDed with one comparison, BETWEEN or begins_with on the range key.
+func dynamoCheckKeyCondition(condition *dynamoNode, hashKey, rangeKey string) error {
+	var leaves []*dynamoNode
+	var flatten func(*dynamoNode) error
+	flatten = func(node *dynamoNode) error {
+		switch node.kind {
+		case "and":
+			if err := flatten(node.args[0]); err != nil {
+				return err
+			}
+			return flatten(node.args[1])
+		case "or", "not":
+			return dynamoValidation("Invalid operator used in KeyConditionExpression: %s", strings.ToUpper(node.kind))
+		}
+		leaves = append(leaves, node)
+		return nil
+	}
+	if err := flatten(condition); err != nil {
+		return err
+	}
+	hashSeen, rangeSeen := false, false
+	for _, leaf := range leaves {
+		valid := leaf.kind == "compare" && leaf.op != "<>" ||
+			leaf.kind == "between" ||
+			leaf.kind == "func" && leaf.op == "begins_with"
+		if !valid || len(leaf.args) == 0 || leaf.args[0].kind != "path" || len(leaf.args[0].path) != 1 {
+			return dynamoValidation("Invalid KeyConditionExpression: unsupported key condition")
+		}
+		for _, arg := range leaf.args[1:] {
+			if arg.kind != "value" {
+				return dynamoValidation("Invalid KeyConditionExpression: key conditions compare against values")
+			}
+		}
+		switch name := leaf.args[0].path[0].name; {
+		case name == hashKey && !hashSeen && leaf.kind == "compare" && leaf.op == "=":
+			hashSeen = true
+		case name == rangeKey && rangeKey != "" && !rangeSeen:
+			rangeSeen = true
+		default:
+			return dynamoValidation("Query condition missed key schema element: %s", hashKey)
+		}
+	}
+	if !hashSeen {
+		return dynamoValidation("Query condition missed key schema element: %s", hashKey)
+	}
+	return nil
+}
+
+// dynamoItemKey derives the storage key of an item, or of a Key parameter,
+// from the table's key schema.
+func dynamoItemKey(table DynamoDBTable, item map[string]any) (string, error) {
+	var parts []string
+	for _, name := range []string{table.HashKey, table.RangeKey} {
+		if name == "" {
+			continue
+		}
+		value, ok := item[name].(map[string]any)
+		if !ok {
+			return "", dynamoValidation("One or more parameter values were invalid: Missing the key %s in the item", name)
+		}
+		kind := dynamoValueType(value)
+		if expected := dynamoAttributeType(table, name); (expected != "" && kind != expected) || (kind != "S" && kind != "N" && kind != "B") {
+			return "", dynamoValidation("One or more parameter values were invalid: Type mismatch for key %s", name)
+		}
+		text := stringValue(value[kind])
+		if text == "" {
+			return "", dynamoValidation("One or more parameter values are not valid. The AttributeValue for a key attribute cannot contain an empty string value. Key: %s", name)
+		}
+		if kind == "N" {
+			number, ok := dynamoNumber(value)
+			if !ok {
+				return "", dynamoValidation("The parameter cannot be converted to a numeric value: %s", text)
+			}
+			text = dynamoFormatNumber(number)
+		}
+		parts = append(parts, kind, text)
+	}
+	data, _ := json.Marshal(parts)
+	return string(data), nil
+}
+
+// dynamoRequestKey validates a Key parameter, which must hold exactly the
+// key attributes, and returns its storage key.
+func dynamoRequestKey(table DynamoDBTable, raw any) (string, error) {
+	key, _ := raw.(map[string]any)
+	expected := 1
+	if table.RangeKey != "" {
+		expected = 2
+	}
+	if len(key) != expected {
+		return "", dynamoValidation("The provided key element does not match the schema")
+	}
+	return dynamoItemKey(table, key)
+}
+
+func dynamoValidateItem(table DynamoDBTable, item map[string]any) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return dynamoValidation("Item is invalid: %v", err)
+	}
+	if len(data) > dynamoMaxItemSize {
+		return dynamoValidation("Item size has exceeded the maximum allowed size")
+	}
+	for _, index := range dynamoIndexes(table) {
+		for _, name := range []string{index.hash, index.rangeKey} {
+			value, ok := item[name].(map[string]any)
+			if name == "" || !ok {
+				continue
+			}
+			if expected := dynamoAttributeType(table, name); expected != "" && dynamoValueType(value) != expected {
+				return dynamoValidation("One or more parameter values were invalid: Type mismatch for Index Key %s Expected: %s Actual: %s IndexName: %s", name, expected, dynamoValueType(value), index.name)
+			}
+		}
+	}
+	return nil
+}
+
+// dynamoApplyUpdateItem computes the item an UpdateItem request leaves
+// behind; a missing item starts out as just its key.
+func dynamoApplyUpdateItem(table DynamoDBTable, body, key, old map[string]any) (map[string]any, *dynamoUpdate, error) {
+	base := old
+	if base == nil {
+		base = map[string]any{}
+		for name, value := range key {
+			base[name] = value
+		}
+	}
+	expression := stringValue(body["UpdateExpression"])
+	if expression == "" {
+		return dynamoClone(base).(map[string]any), nil, nil
+	}
+	update, err := parseDynamoUpdate(expression, dynamoNames(body), dynamoValues(body))
+	if err != nil {
+		return nil, nil, dynamoValidation("Invalid UpdateExpression: %v", err)
+	}
+	for _, path := range update.paths() {
+		if name := path[0].name; name == table.HashKey || name == table.RangeKey {
+			return nil, nil, dynamoValidation("One or more parameter values were invalid: Cannot update attribute %s. This attribute is part of the key", name)
+		}
+	}
+	updated, err := applyDynamoUpdate(base, update)
+	if err != nil {
+		return nil, nil, dynamoValidation("%v", err)
+	}
+	if err := dynamoValidateItem(table, updated); err != nil {
+		return nil, nil, err
+	}
+	return updated, update, nil
+}
+
+// dynamoCheckCondition evaluates the ConditionExpression of body against
+// the current item, nil when it does not exist.
+func dynamoCheckCondition(body, item map[string]any) error {
+	condition, err := parseDynamoCondition(stringValue(body["ConditionExpression"]), dynamoNames(body), dynamoValues(body))
+	if err != nil {
+		return dynamoValidation("Invalid ConditionExpression: %v", err)
+	}
+	if item == nil {
+		item = map[string]any{}
+	}
+	if dynamoMatches(condition, item) {
+		return nil
+	}
+	failed := &dynamoError{code: "ConditionalCheckFailedException", message: "The conditional request failed"}
+	if stringValue(body["ReturnValuesOnConditionCheckFailure"]) == "ALL_OLD" && len(item) > 0 {
+		failed.fields = map[string]any{"Item": item}
+	}
+	return failed
+}
+
+// dynamoReturnValues builds the Attributes of a write response from the
+// item before and after the write.
+func dynamoReturnValues(body, old, updated map[string]any, update *dynamoUpdate) (map[string]any, error) {
+	var attributes map[string]any
+	switch mode := stringValue(body["ReturnValues"]); mode {
+	case "", "NONE":
+	case "ALL_OLD":
+		attributes = old
+	case "ALL_NEW":
+		if update == nil && body["Item"] != nil {
+			return nil, dynamoValidation("ReturnValues can only be ALL_OLD or NONE")
+		}
+		attributes = updated
+	case "UPDATED_OLD", "UPDATED_NEW":
+		if update == nil {
+			return nil, dynamoValidation("ReturnValues can only be ALL_OLD or NONE")
+		}
+		var top []dynamoPath
+		for _, path := range update.paths() {
+			top = append(top, path[:1])
+		}
+		source := updated
+		if mode == "UPDATED_OLD" {
+			source = old
+		}
+		if source != nil {
+			attributes = dynamoProject(source, top)
+		}
+	default:
+		return nil, dynamoValidation("ReturnValues %s is invalid", mode)
+	}
+	if len(attributes) == 0 {
+		return map[string]any{}, nil
+	}
+	return map[string]any{"Attributes": attributes}, nil
+}
+
+func dynamoNames(body map[string]any) map[string]any {
+	names, _ := body["ExpressionAttributeNames"].(map[string]any)
+	return names
+}
+
+func dynamoValues(body map[string]any) map[string]any {
 	values, _ := body["ExpressionAttributeValues"].(map[string]any)
-	value, ok := values[parts[0]]
-	return dynamoAttributeString(value), ok
+	return values
 }
 
-func dynamoAttributeString(value any) string {
-	attr, _ := value.(map[string]any)
-	return stringValue(attr["S"])
+func mapValueAny(value any) map[string]any {
+	out, _ := value.(map[string]any)
+	return out
+}
+
+func copyAnyMap(in map[string]any) map[string]any {
+	out := make(map[string]any, len(in))
+	for key, value := range in {
+		out[key] = value
+	}
+	return out
+}
+
+func sortedAnyKeys(in map[string]any) []string {
+	keys := make([]string, 0, len(in))
+	for key := range in {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func floatValue(value any) float64 {
+	number, _ := value.(float64)
+	return number
+}
+
+// dynamoError is a DynamoDB error response; fields are added to the body
+// next to __type and message.
+type dynamoError struct {
+	status  int
+	code    string
+	message string
+	fields  map[string]any
+}
+
+func (e *dynamoError) Error() string { return e.code + ": " + e.message }
+
+func dynamoValidation(format string, args ...any) error {
+	return &dynamoError{code: "ValidationException", message: fmt.Sprintf(format, args...)}
+}
+
+// dynamoPublicError wraps validation failures in ErrDynamoDBInvalidRequest
+// for callers outside the package.
+func dynamoPublicError(err error) error {
+	var dynamoErr *dynamoError
+	if errors.As(err, &dynamoErr) && dynamoErr.code == "ValidationException" {
+		return fmt.Errorf("%w: %s", ErrDynamoDBInvalidRequest, dynamoErr.message)
+	}
+	return err
+}
+
+func writeDynamoError(w http.ResponseWriter, err error) {
+	var dynamoErr *dynamoError
+	switch {
+	case errors.As(err, &dynamoErr):
+		status := dynamoErr.status
+		if status == 0 {
+			status = http.StatusBadRequest
+		}
+		body := map[string]any{"__type": dynamoErr.code, "message": dynamoErr.message}
+		for key, value := range dynamoErr.fields {
+			body[key] = value
+		}
+		writeJSON(w, status, body)
+	case errors.Is(err, ErrDynamoDBTableNotFound):
+		writeDynamoNotFound(w)
+	case errors.Is(err, ErrDynamoDBTableExists):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"__type": "ResourceInUseException", "message": "table already exists"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"__type": "InternalServerError", "message": err.Error()})
+	}
 }
 
 func writeDynamoNotFound(w http.ResponseWriter) {
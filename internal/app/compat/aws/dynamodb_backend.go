@@ -0,0 +1,290 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DynamoDBBackend stores the tables and items behind the DynamoDB adapter.
+// Items are kept in their wire form, attribute name to typed value, and
+// addressed by the key string the adapter derives from the key schema.
+type DynamoDBBackend interface {
+	CreateTable(ctx context.Context, table DynamoDBTable) error
+	UpdateTable(ctx context.Context, table DynamoDBTable) error
+	DeleteTable(ctx context.Context, name string) error
+	Table(ctx context.Context, name string) (DynamoDBTable, error)
+	ListTables(ctx context.Context) ([]string, error)
+
+	// GetItem returns nil when the item does not exist.
+	GetItem(ctx context.Context, table, key string) (map[string]any, error)
+	// Items returns every item of table in no particular order.
+	Items(ctx context.Context, table string) ([]map[string]any, error)
+	// WriteItems applies all writes or none of them.
+	WriteItems(ctx context.Context, writes []DynamoDBWrite) error
+}
+
+// DynamoDBTable is a table definition as given to CreateTable. The schema
+// fields keep their request shape so DescribeTable can echo them back.
+type DynamoDBTable struct {
+	Name                   string            `json:"name"`
+	HashKey                string            `json:"hash_key"`
+	RangeKey               string            `json:"range_key,omitempty"`
+	AttributeDefinitions   any               `json:"attribute_definitions,omitempty"`
+	KeySchema              any               `json:"key_schema,omitempty"`
+	GlobalSecondaryIndexes any               `json:"global_secondary_indexes,omitempty"`
+	LocalSecondaryIndexes  any               `json:"local_secondary_indexes,omitempty"`
+	StreamSpecification    any               `json:"stream_specification,omitempty"`
+	BillingMode            string            `json:"billing_mode,omitempty"`
+	Tags                   map[string]string `json:"tags,omitempty"`
+	CreatedAt              time.Time         `json:"created_at"`
+}
+
+// DynamoDBWrite puts Item under Key, or deletes Key when Item is nil.
+type DynamoDBWrite struct {
+	Table string
+	Key   string
+	Item  map[string]any
+}
+
+var (
+	ErrDynamoDBTableNotFound = errors.New("table not found")
+	ErrDynamoDBTableExists   = errors.New("table already exists")
+	// ErrDynamoDBInvalidRequest wraps the validation failures the adapter's
+	// in-process methods report.
+	ErrDynamoDBInvalidRequest = errors.New("invalid DynamoDB request")
+)
+
+// NewDynamoDBBackendFromEnv returns the backend selected by
+// HOMEPORT_COMPAT_DYNAMODB_BACKEND: "filesystem" (the default) under
+// HOMEPORT_COMPAT_DYNAMODB_DIR or ~/.homeport/compat/dynamodb, or "memory".
+func NewDynamoDBBackendFromEnv() (DynamoDBBackend, error) {
+	switch backend := os.Getenv("HOMEPORT_COMPAT_DYNAMODB_BACKEND"); backend {
+	case "", "filesystem", "fs":
+		dir := os.Getenv("HOMEPORT_COMPAT_DYNAMODB_DIR")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			dir = filepath.Join(home, ".homeport", "compat", "dynamodb")
+		}
+		return NewFileDynamoDBBackend(dir)
+	case "memory":
+		return NewMemoryDynamoDBBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown DynamoDB compat backend %q", backend)
+	}
+}
+
+// NewMemoryDynamoDBBackend returns a backend that keeps everything in memory.
+func NewMemoryDynamoDBBackend() DynamoDBBackend {
+	return &memoryDynamoDBBackend{tables: map[string]*memoryDynamoDBTable{}}
+}
+
+// NewFileDynamoDBBackend returns a backend that keeps each table, with its
+// items, in <dir>/<table>.json. Tables are held in memory and rewritten
+// atomically on every change.
+func NewFileDynamoDBBackend(dir string) (DynamoDBBackend, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create DynamoDB storage directory: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	b := &memoryDynamoDBBackend{tables: map[string]*memoryDynamoDBTable{}}
+	for _, path := range paths {
+		var stored fileDynamoDBTable
+		if err := readJSONFile(path, &stored); err != nil {
+			return nil, fmt.Errorf("load DynamoDB table %s: %w", filepath.Base(path), err)
+		}
+		if stored.Items == nil {
+			stored.Items = map[string]map[string]any{}
+		}
+		b.tables[stored.Table.Name] = &memoryDynamoDBTable{table: stored.Table, items: stored.Items}
+	}
+	b.persist = func(name string, table *memoryDynamoDBTable) error {
+		path := filepath.Join(dir, name+".json")
+		if table == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}
+		return writeJSONFile(path, fileDynamoDBTable{Table: table.table, Items: table.items})
+	}
+	return b, nil
+}
+
+type fileDynamoDBTable struct {
+	Table DynamoDBTable             `json:"table"`
+	Items map[string]map[string]any `json:"items"`
+}
+
+type memoryDynamoDBBackend struct {
+	mu     sync.RWMutex
+	tables map[string]*memoryDynamoDBTable
+	// persist, when set, saves a table after a change or removes it when
+	// table is nil. A failed persist leaves the in-memory state untouched.
+	persist func(name string, table *memoryDynamoDBTable) error
+}
+
+type memoryDynamoDBTable struct {
+	table DynamoDBTable
+	items map[string]map[string]any
+}
+
+func (b *memoryDynamoDBBackend) CreateTable(_ context.Context, table DynamoDBTable) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.tables[table.Name]; ok {
+		return ErrDynamoDBTableExists
+	}
+	stored := &memoryDynamoDBTable{table: table, items: map[string]map[string]any{}}
+	if err := b.save(table.Name, stored); err != nil {
+		return err
+	}
+	b.tables[table.Name] = stored
+	return nil
+}
+
+func (b *memoryDynamoDBBackend) UpdateTable(_ context.Context, table DynamoDBTable) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored, ok := b.tables[table.Name]
+	if !ok {
+		return ErrDynamoDBTableNotFound
+	}
+	updated := &memoryDynamoDBTable{table: table, items: stored.items}
+	if err := b.save(table.Name, updated); err != nil {
+		return err
+	}
+	b.tables[table.Name] = updated
+	return nil
+}
+
+func (b *memoryDynamoDBBackend) DeleteTable(_ context.Context, name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.tables[name]; !ok {
+		return ErrDynamoDBTableNotFound
+	}
+	if err := b.save(name, nil); err != nil {
+		return err
+	}
+	delete(b.tables, name)
+	return nil
+}
+
+func (b *memoryDynamoDBBackend) Table(_ context.Context, name string) (DynamoDBTable, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stored, ok := b.tables[name]
+	if !ok {
+		return DynamoDBTable{}, ErrDynamoDBTableNotFound
+	}
+	return stored.table, nil
+}
+
+func (b *memoryDynamoDBBackend) ListTables(context.Context) ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	names := make([]string, 0, len(b.tables))
+	for name := range b.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (b *memoryDynamoDBBackend) GetItem(_ context.Context, table, key string) (map[string]any, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stored, ok := b.tables[table]
+	if !ok {
+		return nil, ErrDynamoDBTableNotFound
+	}
+	return stored.items[key], nil
+}
+
+func (b *memoryDynamoDBBackend) Items(_ context.Context, table string) ([]map[string]any, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stored, ok := b.tables[table]
+	if !ok {
+		return nil, ErrDynamoDBTableNotFound
+	}
+	items := make([]map[string]any, 0, len(stored.items))
+	for _, item := range stored.items {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (b *memoryDynamoDBBackend) WriteItems(_ context.Context, writes []DynamoDBWrite) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, write := range writes {
+		if _, ok := b.tables[write.Table]; !ok {
+			return ErrDynamoDBTableNotFound
+		}
+	}
+	if b.persist == nil {
+		for _, write := range writes {
+			applyDynamoDBWrite(b.tables[write.Table].items, write)
+		}
+		return nil
+	}
+
+	// Stage copies of the touched tables so a failed save changes nothing.
+	staged := map[string]map[string]map[string]any{}
+	for _, write := range writes {
+		items, ok := staged[write.Table]
+		if !ok {
+			items = make(map[string]map[string]any, len(b.tables[write.Table].items)+1)
+			for key, item := range b.tables[write.Table].items {
+				items[key] = item
+			}
+			staged[write.Table] = items
+		}
+		applyDynamoDBWrite(items, write)
+	}
+	names := make([]string, 0, len(staged))
+	for name := range staged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := b.persist(name, &memoryDynamoDBTable{table: b.tables[name].table, items: staged[name]}); err != nil {
+			return fmt.Errorf("persist DynamoDB table %s: %w", name, err)
+		}
+	}
+	for _, name := range names {
+		b.tables[name].items = staged[name]
+	}
+	return nil
+}
+
+func (b *memoryDynamoDBBackend) save(name string, table *memoryDynamoDBTable) error {
+	if b.persist == nil {
+		return nil
+	}
+	if err := b.persist(name, table); err != nil {
+		return fmt.Errorf("persist DynamoDB table %s: %w", name, err)
+	}
+	return nil
+}
+
+func applyDynamoDBWrite(items map[string]map[string]any, write DynamoDBWrite) {
+	if write.Item == nil {
+		delete(items, write.Key)
+		return
+	}
+	items[write.Key] = write.Item
+}
@@ -0,0 +1,1218 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file implements the DynamoDB expression language: condition, filter
+// and key condition expressions, update expressions and projections. Values
+// stay in their wire form, e.g. {"S": "x"} or {"M": {...}}.
+
+type dynamoToken struct {
+	kind byte // 'i' identifier, '#' name placeholder, ':' value placeholder, '0' digits, 'p' punctuation, 0 end
+	text string
+}
+
+func dynamoTokenize(expr string) ([]dynamoToken, error) {
+	var tokens []dynamoToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || c == ':':
+			j := i + 1
+			for j < len(expr) && dynamoIdentChar(expr[j]) {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("Invalid expression: syntax error at %q", expr[i:])
+			}
+			tokens = append(tokens, dynamoToken{kind: c, text: expr[i:j]})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(expr) && expr[j] >= '0' && expr[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, dynamoToken{kind: '0', text: expr[i:j]})
+			i = j
+		case dynamoIdentChar(c):
+			j := i
+			for j < len(expr) && dynamoIdentChar(expr[j]) {
+				j++
+			}
+			tokens = append(tokens, dynamoToken{kind: 'i', text: expr[i:j]})
+			i = j
+		case strings.HasPrefix(expr[i:], "<>") || strings.HasPrefix(expr[i:], "<=") || strings.HasPrefix(expr[i:], ">="):
+			tokens = append(tokens, dynamoToken{kind: 'p', text: expr[i : i+2]})
+			i += 2
+		case strings.ContainsRune("()[],.=<>+-", rune(c)):
+			tokens = append(tokens, dynamoToken{kind: 'p', text: string(c)})
+			i++
+		default:
+			return nil, fmt.Errorf("Invalid expression: unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func dynamoIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+type dynamoPathElem struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+type dynamoPath []dynamoPathElem
+
+func (p dynamoPath) String() string {
+	var out strings.Builder
+	for i, elem := range p {
+		switch {
+		case elem.isIndex:
+			out.WriteString("[" + strconv.Itoa(elem.index) + "]")
+		case i > 0:
+			out.WriteString("." + elem.name)
+		default:
+			out.WriteString(elem.name)
+		}
+	}
+	return out.String()
+}
+
+// dynamoNode is a parsed expression. Conditions are "and", "or", "not",
+// "compare", "between", "in" and "func"; operands are "path", "value" and
+// "size"; update values add "plus", "minus", "if_not_exists" and
+// "list_append".
+type dynamoNode struct {
+	kind  string
+	op    string
+	path  dynamoPath
+	value map[string]any
+	args  []*dynamoNode
+}
+
+type dynamoParser struct {
+	tokens []dynamoToken
+	pos    int
+	names  map[string]any
+	values map[string]any
+}
+
+func newDynamoParser(expr string, names, values map[string]any) (*dynamoParser, error) {
+	tokens, err := dynamoTokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamoParser{tokens: tokens, names: names, values: values}, nil
+}
+
+func (p *dynamoParser) peek() dynamoToken { return p.peekAt(0) }
+
+func (p *dynamoParser) peekAt(offset int) dynamoToken {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return dynamoToken{}
+}
+
+func (p *dynamoParser) next() dynamoToken {
+	token := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return token
+}
+
+func (p *dynamoParser) punct(text string) bool {
+	if token := p.peek(); token.kind == 'p' && token.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *dynamoParser) keyword(word string) bool {
+	if token := p.peek(); token.kind == 'i' && strings.EqualFold(token.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *dynamoParser) expect(text string) error {
+	if !p.punct(text) {
+		return p.syntaxError()
+	}
+	return nil
+}
+
+func (p *dynamoParser) syntaxError() error {
+	token := p.peek()
+	if token.kind == 0 {
+		return fmt.Errorf("Invalid expression: syntax error; unexpected end of input")
+	}
+	return fmt.Errorf("Invalid expression: syntax error; token: %q", token.text)
+}
+
+func (p *dynamoParser) done() error {
+	if p.pos < len(p.tokens) {
+		return p.syntaxError()
+	}
+	return nil
+}
+
+// parseDynamoCondition parses a condition, filter or key condition
+// expression. An empty expression yields nil, which matches everything.
+func parseDynamoCondition(expr string, names, values map[string]any) (*dynamoNode, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	p, err := newDynamoParser(expr, names, values)
+	if err != nil {
+		return nil, err
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	return node, p.done()
+}
+
+func (p *dynamoParser) parseOr() (*dynamoNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.keyword("OR") {
+		var right *dynamoNode
+		if right, err = p.parseAnd(); err == nil {
+			left = &dynamoNode{kind: "or", args: []*dynamoNode{left, right}}
+		}
+	}
+	return left, err
+}
+
+func (p *dynamoParser) parseAnd() (*dynamoNode, error) {
+	left, err := p.parseNot()
+	for err == nil && p.keyword("AND") {
+		var right *dynamoNode
+		if right, err = p.parseNot(); err == nil {
+			left = &dynamoNode{kind: "and", args: []*dynamoNode{left, right}}
+		}
+	}
+	return left, err
+}
+
+func (p *dynamoParser) parseNot() (*dynamoNode, error) {
+	if p.keyword("NOT") {
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &dynamoNode{kind: "not", args: []*dynamoNode{node}}, nil
+	}
+	return p.parsePrimary()
+}
+
+var dynamoConditionFuncs = map[string]int{
+	"attribute_exists":     1,
+	"attribute_not_exists": 1,
+	"attribute_type":       2,
+	"begins_with":          2,
+	"contains":             2,
+}
+
+func (p *dynamoParser) parsePrimary() (*dynamoNode, error) {
+	if p.punct("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return node, p.expect(")")
+	}
+	if token := p.peek(); token.kind == 'i' && p.peekAt(1).text == "(" {
+		name := strings.ToLower(token.text)
+		if arity, ok := dynamoConditionFuncs[name]; ok {
+			p.pos += 2
+			node := &dynamoNode{kind: "func", op: name}
+			for i := 0; i < arity; i++ {
+				if i > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				arg, err := p.parseOperand()
+				if err != nil {
+					return nil, err
+				}
+				node.args = append(node.args, arg)
+			}
+			if node.args[0].kind != "path" {
+				return nil, fmt.Errorf("Invalid ConditionExpression: the first argument of %s must be a document path", name)
+			}
+			return node, p.expect(")")
+		}
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind == 'p' {
+		switch token.text {
+		case "=", "<>", "<", "<=", ">", ">=":
+			p.pos++
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return &dynamoNode{kind: "compare", op: token.text, args: []*dynamoNode{left, right}}, nil
+		}
+	}
+	if p.keyword("BETWEEN") {
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword("AND") {
+			return nil, p.syntaxError()
+		}
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &dynamoNode{kind: "between", args: []*dynamoNode{left, low, high}}, nil
+	}
+	if p.keyword("IN") {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		node := &dynamoNode{kind: "in", args: []*dynamoNode{left}}
+		for {
+			arg, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			node.args = append(node.args, arg)
+			if !p.punct(",") {
+				break
+			}
+		}
+		return node, p.expect(")")
+	}
+	return nil, p.syntaxError()
+}
+
+func (p *dynamoParser) parseOperand() (*dynamoNode, error) {
+	token := p.peek()
+	switch {
+	case token.kind == ':':
+		p.pos++
+		value, ok := p.values[token.text].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("An expression attribute value used in expression is not defined; attribute value: %s", token.text)
+		}
+		return &dynamoNode{kind: "value", value: value}, nil
+	case token.kind == 'i' && strings.EqualFold(token.text, "size") && p.peekAt(1).text == "(":
+		p.pos += 2
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return &dynamoNode{kind: "size", path: path}, p.expect(")")
+	case token.kind == 'i' || token.kind == '#':
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return &dynamoNode{kind: "path", path: path}, nil
+	}
+	return nil, p.syntaxError()
+}
+
+func (p *dynamoParser) parsePath() (dynamoPath, error) {
+	name, err := p.parsePathName()
+	if err != nil {
+		return nil, err
+	}
+	path := dynamoPath{{name: name}}
+	for {
+		switch {
+		case p.punct("."):
+			name, err := p.parsePathName()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, dynamoPathElem{name: name})
+		case p.punct("["):
+			token := p.next()
+			if token.kind != '0' {
+				return nil, p.syntaxError()
+			}
+			index, err := strconv.Atoi(token.text)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid expression: list index %s is out of range", token.text)
+			}
+			path = append(path, dynamoPathElem{index: index, isIndex: true})
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		default:
+			return path, nil
+		}
+	}
+}
+
+func (p *dynamoParser) parsePathName() (string, error) {
+	token := p.peek()
+	switch token.kind {
+	case 'i':
+		p.pos++
+		return token.text, nil
+	case '#':
+		p.pos++
+		name := stringValue(p.names[token.text])
+		if name == "" {
+			return "", fmt.Errorf("An expression attribute name used in the document path is not defined; attribute name: %s", token.text)
+		}
+		return name, nil
+	}
+	return "", p.syntaxError()
+}
+
+// parseDynamoProjection parses a comma-separated list of document paths.
+func parseDynamoProjection(expr string, names map[string]any) ([]dynamoPath, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	p, err := newDynamoParser(expr, names, nil)
+	if err != nil {
+		return nil, err
+	}
+	var paths []dynamoPath
+	for {
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+		if !p.punct(",") {
+			break
+		}
+	}
+	return paths, p.done()
+}
+
+type dynamoUpdate struct {
+	sets    []dynamoUpdateAction
+	removes []dynamoPath
+	adds    []dynamoUpdateAction
+	deletes []dynamoUpdateAction
+}
+
+type dynamoUpdateAction struct {
+	path  dynamoPath
+	value *dynamoNode
+}
+
+// paths returns every document path the update writes.
+func (u *dynamoUpdate) paths() []dynamoPath {
+	paths := append([]dynamoPath(nil), u.removes...)
+	for _, actions := range [][]dynamoUpdateAction{u.sets, u.adds, u.deletes} {
+		for _, action := range actions {
+			paths = append(paths, action.path)
+		}
+	}
+	return paths
+}
+
+func parseDynamoUpdate(expr string, names, values map[string]any) (*dynamoUpdate, error) {
+	p, err := newDynamoParser(expr, names, values)
+	if err != nil {
+		return nil, err
+	}
+	update := &dynamoUpdate{}
+	seen := map[string]bool{}
+	for p.peek().kind != 0 {
+		token := p.peek()
+		clause := strings.ToUpper(token.text)
+		if token.kind != 'i' || (clause != "SET" && clause != "REMOVE" && clause != "ADD" && clause != "DELETE") {
+			return nil, p.syntaxError()
+		}
+		p.pos++
+		if seen[clause] {
+			return nil, fmt.Errorf("Invalid UpdateExpression: The %q section can only be used once in an update expression", clause)
+		}
+		seen[clause] = true
+		for {
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			switch clause {
+			case "SET":
+				if err := p.expect("="); err != nil {
+					return nil, err
+				}
+				value, err := p.parseSetValue()
+				if err != nil {
+					return nil, err
+				}
+				update.sets = append(update.sets, dynamoUpdateAction{path: path, value: value})
+			case "REMOVE":
+				update.removes = append(update.removes, path)
+			default:
+				value, err := p.parseOperand()
+				if err != nil {
+					return nil, err
+				}
+				if value.kind != "value" {
+					return nil, fmt.Errorf("Invalid UpdateExpression: %s takes an expression attribute value", clause)
+				}
+				action := dynamoUpdateAction{path: path, value: value}
+				if clause == "ADD" {
+					update.adds = append(update.adds, action)
+				} else {
+					update.deletes = append(update.deletes, action)
+				}
+			}
+			if !p.punct(",") {
+				break
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("Invalid UpdateExpression: The expression can not be empty")
+	}
+	paths := update.paths()
+	for i := range paths {
+		for j := i + 1; j < len(paths); j++ {
+			if dynamoPathsOverlap(paths[i], paths[j]) {
+				return nil, fmt.Errorf("Invalid UpdateExpression: Two document paths overlap with each other; path one: [%s], path two: [%s]", paths[i], paths[j])
+			}
+		}
+	}
+	return update, nil
+}
+
+func (p *dynamoParser) parseSetValue() (*dynamoNode, error) {
+	left, err := p.parseSetOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"+", "-"} {
+		if p.punct(op) {
+			right, err := p.parseSetOperand()
+			if err != nil {
+				return nil, err
+			}
+			kind := "plus"
+			if op == "-" {
+				kind = "minus"
+			}
+			return &dynamoNode{kind: kind, args: []*dynamoNode{left, right}}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *dynamoParser) parseSetOperand() (*dynamoNode, error) {
+	token := p.peek()
+	if token.kind == 'i' && p.peekAt(1).text == "(" {
+		switch name := strings.ToLower(token.text); name {
+		case "if_not_exists", "list_append":
+			p.pos += 2
+			first, err := p.parseSetOperand()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+			second, err := p.parseSetOperand()
+			if err != nil {
+				return nil, err
+			}
+			if name == "if_not_exists" && first.kind != "path" {
+				return nil, fmt.Errorf("Invalid UpdateExpression: the first argument of if_not_exists must be a document path")
+			}
+			return &dynamoNode{kind: name, args: []*dynamoNode{first, second}}, p.expect(")")
+		}
+	}
+	return p.parseOperand()
+}
+
+func dynamoPathsOverlap(a, b dynamoPath) bool {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dynamoMatches reports whether item satisfies condition; a nil condition
+// always matches.
+func dynamoMatches(condition *dynamoNode, item map[string]any) bool {
+	if condition == nil {
+		return true
+	}
+	switch condition.kind {
+	case "and":
+		return dynamoMatches(condition.args[0], item) && dynamoMatches(condition.args[1], item)
+	case "or":
+		return dynamoMatches(condition.args[0], item) || dynamoMatches(condition.args[1], item)
+	case "not":
+		return !dynamoMatches(condition.args[0], item)
+	case "compare":
+		left, ok := dynamoOperand(condition.args[0], item)
+		if !ok {
+			return false
+		}
+		right, ok := dynamoOperand(condition.args[1], item)
+		if !ok {
+			return false
+		}
+		switch condition.op {
+		case "=":
+			return dynamoValuesEqual(left, right)
+		case "<>":
+			return !dynamoValuesEqual(left, right)
+		}
+		cmp, ok := dynamoCompare(left, right)
+		if !ok {
+			return false
+		}
+		switch condition.op {
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		default:
+			return cmp >= 0
+		}
+	case "between":
+		value, ok := dynamoOperand(condition.args[0], item)
+		low, lok := dynamoOperand(condition.args[1], item)
+		high, hok := dynamoOperand(condition.args[2], item)
+		if !ok || !lok || !hok {
+			return false
+		}
+		above, aok := dynamoCompare(value, low)
+		below, bok := dynamoCompare(value, high)
+		return aok && bok && above >= 0 && below <= 0
+	case "in":
+		value, ok := dynamoOperand(condition.args[0], item)
+		if !ok {
+			return false
+		}
+		for _, arg := range condition.args[1:] {
+			if candidate, ok := dynamoOperand(arg, item); ok && dynamoValuesEqual(value, candidate) {
+				return true
+			}
+		}
+		return false
+	case "func":
+		value, exists := condition.args[0].path.get(item)
+		switch condition.op {
+		case "attribute_exists":
+			return exists
+		case "attribute_not_exists":
+			return !exists
+		}
+		arg, ok := dynamoOperand(condition.args[1], item)
+		if !exists || !ok {
+			return false
+		}
+		switch condition.op {
+		case "attribute_type":
+			return dynamoValueType(value) == stringValue(arg["S"])
+		case "begins_with":
+			switch dynamoValueType(value) {
+			case "S":
+				return dynamoValueType(arg) == "S" && strings.HasPrefix(stringValue(value["S"]), stringValue(arg["S"]))
+			case "B":
+				return dynamoValueType(arg) == "B" && bytes.HasPrefix(dynamoBytes(value["B"]), dynamoBytes(arg["B"]))
+			}
+			return false
+		default: // contains
+			switch dynamoValueType(value) {
+			case "S":
+				return dynamoValueType(arg) == "S" && strings.Contains(stringValue(value["S"]), stringValue(arg["S"]))
+			case "SS", "NS", "BS", "L":
+				for _, element := range dynamoElements(value) {
+					if dynamoValuesEqual(element, arg) {
+						return true
+					}
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// dynamoOperand resolves a condition operand; ok is false when a path does
+// not exist or size is applied to a value without a size.
+func dynamoOperand(node *dynamoNode, item map[string]any) (map[string]any, bool) {
+	switch node.kind {
+	case "value":
+		return node.value, true
+	case "path":
+		return node.path.get(item)
+	case "size":
+		value, ok := node.path.get(item)
+		if !ok {
+			return nil, false
+		}
+		var size int
+		switch dynamoValueType(value) {
+		case "S":
+			size = utf8.RuneCountInString(stringValue(value["S"]))
+		case "B":
+			size = len(dynamoBytes(value["B"]))
+		case "SS", "NS", "BS", "L":
+			size = len(dynamoElements(value))
+		case "M":
+			m, _ := value["M"].(map[string]any)
+			size = len(m)
+		default:
+			return nil, false
+		}
+		return map[string]any{"N": strconv.Itoa(size)}, true
+	}
+	return nil, false
+}
+
+func (p dynamoPath) get(item map[string]any) (map[string]any, bool) {
+	if len(p) == 0 {
+		return nil, false
+	}
+	current, ok := item[p[0].name].(map[string]any)
+	for _, elem := range p[1:] {
+		if !ok {
+			return nil, false
+		}
+		if elem.isIndex {
+			list, _ := current["L"].([]any)
+			if elem.index >= len(list) {
+				return nil, false
+			}
+			current, ok = list[elem.index].(map[string]any)
+		} else {
+			m, _ := current["M"].(map[string]any)
+			current, ok = m[elem.name].(map[string]any)
+		}
+	}
+	return current, ok
+}
+
+var errDynamoInvalidPath = fmt.Errorf("The document path provided in the update expression is invalid for update")
+
+// set writes value at the path of item. The parent of a nested path must
+// exist; an index past the end of a list appends.
+func (p dynamoPath) set(item map[string]any, value map[string]any) error {
+	if len(p) == 1 {
+		item[p[0].name] = value
+		return nil
+	}
+	parent, ok := p[:len(p)-1].get(item)
+	if !ok {
+		return errDynamoInvalidPath
+	}
+	last := p[len(p)-1]
+	if last.isIndex {
+		list, ok := parent["L"].([]any)
+		if !ok {
+			return errDynamoInvalidPath
+		}
+		if last.index >= len(list) {
+			parent["L"] = append(list, value)
+		} else {
+			list[last.index] = value
+		}
+		return nil
+	}
+	m, ok := parent["M"].(map[string]any)
+	if !ok {
+		return errDynamoInvalidPath
+	}
+	m[last.name] = value
+	return nil
+}
+
+func (p dynamoPath) remove(item map[string]any) {
+	if len(p) == 1 {
+		delete(item, p[0].name)
+		return
+	}
+	parent, ok := p[:len(p)-1].get(item)
+	if !ok {
+		return
+	}
+	last := p[len(p)-1]
+	if last.isIndex {
+		if list, ok := parent["L"].([]any); ok && last.index < len(list) {
+			parent["L"] = append(list[:last.index:last.index], list[last.index+1:]...)
+		}
+		return
+	}
+	if m, ok := parent["M"].(map[string]any); ok {
+		delete(m, last.name)
+	}
+}
+
+// applyDynamoUpdate returns a copy of item with update applied. Every value
+// is computed from the original item before anything is written, as
+// DynamoDB does.
+func applyDynamoUpdate(item map[string]any, update *dynamoUpdate) (map[string]any, error) {
+	sets := make([]map[string]any, len(update.sets))
+	for i, action := range update.sets {
+		value, err := dynamoUpdateValue(action.value, item)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = value
+	}
+
+	out := dynamoClone(item).(map[string]any)
+	for i, action := range update.sets {
+		if err := action.path.set(out, dynamoClone(sets[i]).(map[string]any)); err != nil {
+			return nil, err
+		}
+	}
+	// Remove list elements last and from the highest index down, so earlier
+	// removals do not shift later ones.
+	var indexed []dynamoPath
+	for _, path := range update.removes {
+		if path[len(path)-1].isIndex {
+			indexed = append(indexed, path)
+			continue
+		}
+		path.remove(out)
+	}
+	sort.Slice(indexed, func(i, j int) bool {
+		return indexed[i][len(indexed[i])-1].index > indexed[j][len(indexed[j])-1].index
+	})
+	for _, path := range indexed {
+		path.remove(out)
+	}
+	for _, action := range update.adds {
+		current, exists := action.path.get(out)
+		value, err := dynamoAdd(current, exists, action.value.value)
+		if err != nil {
+			return nil, err
+		}
+		if err := action.path.set(out, value); err != nil {
+			return nil, err
+		}
+	}
+	for _, action := range update.deletes {
+		current, exists := action.path.get(out)
+		if !exists {
+			continue
+		}
+		value, err := dynamoDeleteElements(current, action.value.value)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			action.path.remove(out)
+		} else if err := action.path.set(out, value); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+var errDynamoMissingAttribute = fmt.Errorf("The provided expression refers to an attribute that does not exist in the item")
+
+func dynamoUpdateValue(node *dynamoNode, item map[string]any) (map[string]any, error) {
+	switch node.kind {
+	case "plus", "minus":
+		left, err := dynamoUpdateValue(node.args[0], item)
+		if err != nil {
+			return nil, err
+		}
+		right, err := dynamoUpdateValue(node.args[1], item)
+		if err != nil {
+			return nil, err
+		}
+		a, aok := dynamoNumber(left)
+		b, bok := dynamoNumber(right)
+		if !aok || !bok {
+			return nil, fmt.Errorf("An operand in the update expression has an incorrect data type")
+		}
+		if node.kind == "plus" {
+			return map[string]any{"N": dynamoFormatNumber(new(big.Rat).Add(a, b))}, nil
+		}
+		return map[string]any{"N": dynamoFormatNumber(new(big.Rat).Sub(a, b))}, nil
+	case "if_not_exists":
+		if value, ok := node.args[0].path.get(item); ok {
+			return value, nil
+		}
+		return dynamoUpdateValue(node.args[1], item)
+	case "list_append":
+		left, err := dynamoUpdateValue(node.args[0], item)
+		if err != nil {
+			return nil, err
+		}
+		right, err := dynamoUpdateValue(node.args[1], item)
+		if err != nil {
+			return nil, err
+		}
+		a, aok := left["L"].([]any)
+		b, bok := right["L"].([]any)
+		if !aok || !bok {
+			return nil, fmt.Errorf("An operand in the update expression has an incorrect data type")
+		}
+		return map[string]any{"L": append(append([]any{}, a...), b...)}, nil
+	}
+	value, ok := dynamoOperand(node, item)
+	if !ok {
+		return nil, errDynamoMissingAttribute
+	}
+	return value, nil
+}
+
+func dynamoAdd(current map[string]any, exists bool, value map[string]any) (map[string]any, error) {
+	kind := dynamoValueType(value)
+	if exists && dynamoValueType(current) != kind {
+		return nil, fmt.Errorf("An operand in the update expression has an incorrect data type")
+	}
+	switch kind {
+	case "N":
+		if !exists {
+			return value, nil
+		}
+		a, _ := dynamoNumber(current)
+		b, ok := dynamoNumber(value)
+		if a == nil || !ok {
+			return nil, fmt.Errorf("An operand in the update expression has an incorrect data type")
+		}
+		return map[string]any{"N": dynamoFormatNumber(new(big.Rat).Add(a, b))}, nil
+	case "SS", "NS", "BS":
+		elements := dynamoElements(value)
+		if exists {
+			elements = dynamoElements(current)
+			for _, element := range dynamoElements(value) {
+				if !dynamoContainsValue(elements, element) {
+					elements = append(elements, element)
+				}
+			}
+		}
+		return dynamoSet(kind, elements), nil
+	}
+	return nil, fmt.Errorf("Invalid UpdateExpression: Incorrect operand type for operator or function; operator: ADD, operand type: %s", kind)
+}
+
+// dynamoDeleteElements removes the elements of value from the set current;
+// it returns nil when the set ends up empty.
+func dynamoDeleteElements(current, value map[string]any) (map[string]any, error) {
+	kind := dynamoValueType(value)
+	if kind != "SS" && kind != "NS" && kind != "BS" {
+		return nil, fmt.Errorf("Invalid UpdateExpression: Incorrect operand type for operator or function; operator: DELETE, operand type: %s", kind)
+	}
+	if dynamoValueType(current) != kind {
+		return nil, fmt.Errorf("An operand in the update expression has an incorrect data type")
+	}
+	remove := dynamoElements(value)
+	var kept []map[string]any
+	for _, element := range dynamoElements(current) {
+		if !dynamoContainsValue(remove, element) {
+			kept = append(kept, element)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, nil
+	}
+	return dynamoSet(kind, kept), nil
+}
+
+// dynamoProject keeps only the given paths of item. Nested maps and lists
+// keep just the selected members; list elements stay in list order.
+func dynamoProject(item map[string]any, paths []dynamoPath) map[string]any {
+	if len(paths) == 0 {
+		return item
+	}
+	root := &dynamoProjection{}
+	for _, path := range paths {
+		node := root
+		for _, elem := range path {
+			node = node.child(elem)
+		}
+		node.whole = true
+	}
+	out := map[string]any{}
+	for name, child := range root.names {
+		if value, ok := item[name].(map[string]any); ok {
+			if projected := child.apply(value); projected != nil {
+				out[name] = projected
+			}
+		}
+	}
+	return out
+}
+
+type dynamoProjection struct {
+	whole   bool
+	names   map[string]*dynamoProjection
+	indexes map[int]*dynamoProjection
+}
+
+func (n *dynamoProjection) child(elem dynamoPathElem) *dynamoProjection {
+	if elem.isIndex {
+		if n.indexes == nil {
+			n.indexes = map[int]*dynamoProjection{}
+		}
+		if n.indexes[elem.index] == nil {
+			n.indexes[elem.index] = &dynamoProjection{}
+		}
+		return n.indexes[elem.index]
+	}
+	if n.names == nil {
+		n.names = map[string]*dynamoProjection{}
+	}
+	if n.names[elem.name] == nil {
+		n.names[elem.name] = &dynamoProjection{}
+	}
+	return n.names[elem.name]
+}
+
+func (n *dynamoProjection) apply(value map[string]any) map[string]any {
+	if n.whole {
+		return value
+	}
+	if m, ok := value["M"].(map[string]any); ok && n.names != nil {
+		out := map[string]any{}
+		for name, child := range n.names {
+			if member, ok := m[name].(map[string]any); ok {
+				if projected := child.apply(member); projected != nil {
+					out[name] = projected
+				}
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return map[string]any{"M": out}
+	}
+	if list, ok := value["L"].([]any); ok && n.indexes != nil {
+		indexes := make([]int, 0, len(n.indexes))
+		for index := range n.indexes {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		var out []any
+		for _, index := range indexes {
+			if index < len(list) {
+				if member, ok := list[index].(map[string]any); ok {
+					if projected := n.indexes[index].apply(member); projected != nil {
+						out = append(out, projected)
+					}
+				}
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return map[string]any{"L": out}
+	}
+	return nil
+}
+
+// dynamoValueType returns the type descriptor of an attribute value, such
+// as "S", "N" or "M".
+func dynamoValueType(value map[string]any) string {
+	for kind := range value {
+		return kind
+	}
+	return ""
+}
+
+func dynamoNumber(value map[string]any) (*big.Rat, bool) {
+	text, ok := value["N"].(string)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).SetString(strings.TrimSpace(text))
+}
+
+// dynamoFormatNumber renders r in plain decimal notation without trailing
+// zeros. Sums of decimal numbers always have a finite expansion.
+func dynamoFormatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	denominator := new(big.Int).Set(r.Denom())
+	digits := 0
+	for _, factor := range []int64{2, 5} {
+		count := 0
+		f := big.NewInt(factor)
+		for new(big.Int).Mod(denominator, f).Sign() == 0 {
+			denominator.Div(denominator, f)
+			count++
+		}
+		if count > digits {
+			digits = count
+		}
+	}
+	if denominator.Cmp(big.NewInt(1)) != 0 {
+		digits = 38
+	}
+	text := strings.TrimRight(r.FloatString(digits), "0")
+	return strings.TrimSuffix(text, ".")
+}
+
+func dynamoBytes(value any) []byte {
+	data, _ := base64.StdEncoding.DecodeString(stringValue(value))
+	return data
+}
+
+// dynamoElements returns the members of a set or list as attribute values.
+func dynamoElements(value map[string]any) []map[string]any {
+	kind := dynamoValueType(value)
+	raw, _ := value[kind].([]any)
+	out := make([]map[string]any, 0, len(raw))
+	for _, element := range raw {
+		switch kind {
+		case "SS":
+			out = append(out, map[string]any{"S": element})
+		case "NS":
+			out = append(out, map[string]any{"N": element})
+		case "BS":
+			out = append(out, map[string]any{"B": element})
+		case "L":
+			if member, ok := element.(map[string]any); ok {
+				out = append(out, member)
+			}
+		}
+	}
+	return out
+}
+
+func dynamoSet(kind string, elements []map[string]any) map[string]any {
+	scalar := kind[:1]
+	members := make([]any, 0, len(elements))
+	for _, element := range elements {
+		members = append(members, element[scalar])
+	}
+	return map[string]any{kind: members}
+}
+
+func dynamoContainsValue(values []map[string]any, value map[string]any) bool {
+	for _, candidate := range values {
+		if dynamoValuesEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// dynamoCompare orders two scalar values of the same type: strings and
+// binaries byte-wise, numbers numerically.
+func dynamoCompare(a, b map[string]any) (int, bool) {
+	kind := dynamoValueType(a)
+	if kind != dynamoValueType(b) {
+		return 0, false
+	}
+	switch kind {
+	case "S":
+		return strings.Compare(stringValue(a["S"]), stringValue(b["S"])), true
+	case "N":
+		x, xok := dynamoNumber(a)
+		y, yok := dynamoNumber(b)
+		if !xok || !yok {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	case "B":
+		return bytes.Compare(dynamoBytes(a["B"]), dynamoBytes(b["B"])), true
+	}
+	return 0, false
+}
+
+func dynamoValuesEqual(a, b map[string]any) bool {
+	kind := dynamoValueType(a)
+	if kind != dynamoValueType(b) {
+		return false
+	}
+	switch kind {
+	case "S", "N", "B":
+		cmp, ok := dynamoCompare(a, b)
+		return ok && cmp == 0
+	case "BOOL", "NULL":
+		return a[kind] == b[kind]
+	case "SS", "NS", "BS":
+		x, y := dynamoElements(a), dynamoElements(b)
+		if len(x) != len(y) {
+			return false
+		}
+		for _, element := range x {
+			if !dynamoContainsValue(y, element) {
+				return false
+			}
+		}
+		return true
+	case "L":
+		x, y := dynamoElements(a), dynamoElements(b)
+		if len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !dynamoValuesEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case "M":
+		x, _ := a["M"].(map[string]any)
+		y, _ := b["M"].(map[string]any)
+		if len(x) != len(y) {
+			return false
+		}
+		for name, value := range x {
+			xv, _ := value.(map[string]any)
+			yv, ok := y[name].(map[string]any)
+			if !ok || !dynamoValuesEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// dynamoClone deep-copies a decoded JSON value.
+func dynamoClone(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		for key, member := range typed {
+			out[key] = dynamoClone(member)
+		}
+		return out
+	case []any:
+		out := make([]any, len(typed))
+		for i, member := range typed {
+			out[i] = dynamoClone(member)
+		}
+		return out
+	}
+	return value
+}
@@ -1,12 +1,15 @@
 package aws
 
 import (
-	"bytes"
+	"bufio"
 	"crypto/md5"
+	"encoding/base64"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -18,8 +21,7 @@ import (
 
 type S3Adapter struct {
 	mu          sync.Mutex
-	buckets     map[string]map[string][]byte
-	bucketTags  map[string]map[string]string
+	backend     S3Backend
 	idempotency map[string]s3StoredResponse
 	objectQuota int
 	authorizer  authz.Authorizer
@@ -36,8 +38,7 @@ type S3Option func(*S3Adapter)
 
 func NewS3Adapter(options ...S3Option) *S3Adapter {
 	adapter := &S3Adapter{
-		buckets:     map[string]map[string][]byte{},
-		bucketTags:  map[string]map[string]string{},
+		backend:     NewMemoryS3Backend(),
 		idempotency: map[string]s3StoredResponse{},
 		authorizer:  authz.AllowAll,
 	}
@@ -47,6 +48,15 @@ func NewS3Adapter(options ...S3Option) *S3Adapter {
 	return adapter
 }
 
+// WithS3Backend stores buckets and objects in backend instead of memory.
+func WithS3Backend(backend S3Backend) S3Option {
+	return func(adapter *S3Adapter) {
+		if backend != nil {
+			adapter.backend = backend
+		}
+	}
+}
+
 func WithS3Authorizer(authorizer authz.Authorizer) S3Option {
 	return func(adapter *S3Adapter) {
 		if authorizer != nil {
@@ -91,13 +101,13 @@ func (S3Adapter) TargetEnv() map[string]string {
 	}
 }
 func (S3Adapter) ConformanceChecks() []string {
-	return []string{"create-bucket", "head-bucket", "list-objects-v2", "put-object", "get-object", "delete-object", "delete-bucket", "bucket-tags"}
+	return []string{"create-bucket", "head-bucket", "list-objects-v2", "put-object", "get-object", "head-object", "ranged-get-object", "multipart-upload", "delete-object", "delete-bucket", "bucket-tags"}
 }
 
 func (a *S3Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("X-Amz-RequestId", "homeport")
 	bucket, key := s3Path(r)
-	action := s3Action(r.Method, key, r.URL.Query().Has("tagging"), r.URL.Query().Has("policy"))
+	action := s3Action(r, key)
 	if bucket == "" {
 		writeS3Error(w, http.StatusBadRequest, "InvalidRequest", "unsupported S3 request")
 		return
@@ -118,142 +128,209 @@ func (a *S3Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
+	ctx := r.Context()
+	query := r.URL.Query()
 	switch action {
 	case "CreateBucket":
 		idempotencyKey := s3IdempotencyKey(r, action, bucket)
-		if idempotencyKey != "" {
-			if stored, ok := a.idempotency[idempotencyKey]; ok {
-				writeS3StoredResponse(w, stored)
-				return
-			}
-		}
-		if a.buckets[bucket] != nil {
-			writeS3Error(w, http.StatusConflict, "BucketAlreadyOwnedByYou", "bucket already exists")
+		if a.replay(w, idempotencyKey) {
 			return
 		}
-		a.buckets[bucket] = map[string][]byte{}
-		a.bucketTags[bucket] = map[string]string{}
-		if idempotencyKey != "" {
-			a.idempotency[idempotencyKey] = s3StoredResponse{status: http.StatusOK}
+		if err := a.backend.CreateBucket(ctx, bucket); err != nil {
+			writeS3BackendError(w, err)
+			return
 		}
+		a.remember(idempotencyKey, s3StoredResponse{status: http.StatusOK})
 		w.WriteHeader(http.StatusOK)
 	case "HeadBucket":
-		if a.buckets[bucket] == nil {
-			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket not found")
+		if err := a.backend.HeadBucket(ctx, bucket); err != nil {
+			writeS3BackendError(w, err)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
 	case "ListObjectsV2":
-		objects := a.buckets[bucket]
-		if objects == nil {
-			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket not found")
-			return
-		}
-		if !a.writeListObjectsV2(w, bucket, r.URL.Query().Get("continuation-token"), r.URL.Query().Get("max-keys")) {
-			return
-		}
+		a.writeListObjectsV2(w, r, bucket)
 	case "PutBucketTagging":
-		if a.buckets[bucket] == nil {
-			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket not found")
+		if err := a.backend.HeadBucket(ctx, bucket); err != nil {
+			writeS3BackendError(w, err)
 			return
 		}
 		idempotencyKey := s3IdempotencyKey(r, action, bucket)
-		if idempotencyKey != "" {
-			if stored, ok := a.idempotency[idempotencyKey]; ok {
-				writeS3StoredResponse(w, stored)
-				return
-			}
+		if a.replay(w, idempotencyKey) {
+			return
 		}
 		tags, err := decodeS3Tags(r.Body)
 		if err != nil {
 			writeS3Error(w, http.StatusBadRequest, "MalformedXML", "the XML you provided was not well-formed or did not validate against our published schema")
 			return
 		}
-		a.bucketTags[bucket] = tags
-		if idempotencyKey != "" {
-			a.idempotency[idempotencyKey] = s3StoredResponse{status: http.StatusOK}
+		if err := a.backend.PutBucketTags(ctx, bucket, tags); err != nil {
+			writeS3BackendError(w, err)
+			return
 		}
+		a.remember(idempotencyKey, s3StoredResponse{status: http.StatusOK})
 		w.WriteHeader(http.StatusOK)
 	case "GetBucketTagging":
-		if a.buckets[bucket] == nil {
-			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket not found")
+		tags, err := a.backend.BucketTags(ctx, bucket)
+		if err != nil {
+			writeS3BackendError(w, err)
 			return
 		}
-		if len(a.bucketTags[bucket]) == 0 {
+		if len(tags) == 0 {
 			writeS3Error(w, http.StatusNotFound, "NoSuchTagSet", "bucket has no tags")
 			return
 		}
-		writeS3Tags(w, a.bucketTags[bucket])
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = io.WriteString(w, xml.Header)
+		writeS3TagsXML(w, tags)
 	case "GetBucketPolicy":
-		if a.buckets[bucket] == nil {
-			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket not found")
+		if err := a.backend.HeadBucket(ctx, bucket); err != nil {
+			writeS3BackendError(w, err)
 			return
 		}
 		writeS3Error(w, http.StatusNotFound, "NoSuchBucketPolicy", "bucket policy not found")
 	case "PutObject":
-		if a.buckets[bucket] == nil {
-			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket not found")
+		if err := a.backend.HeadBucket(ctx, bucket); err != nil {
+			writeS3BackendError(w, err)
 			return
 		}
 		idempotencyKey := s3IdempotencyKey(r, action, bucket+"/"+key)
-		if idempotencyKey != "" {
-			if stored, ok := a.idempotency[idempotencyKey]; ok {
-				writeS3StoredResponse(w, stored)
-				return
-			}
-		}
-		if _, exists := a.buckets[bucket][key]; !exists && a.objectQuota > 0 && a.objectCount() >= a.objectQuota {
-			writeS3Error(w, http.StatusTooManyRequests, "SlowDown", "object quota exceeded")
+		if a.replay(w, idempotencyKey) {
 			return
 		}
-		body, _ := io.ReadAll(r.Body)
-		a.buckets[bucket][key] = append([]byte(nil), body...)
-		etag := s3ETag(body)
-		w.Header().Set("ETag", etag)
-		if idempotencyKey != "" {
-			a.idempotency[idempotencyKey] = s3StoredResponse{status: http.StatusOK, headers: map[string]string{"ETag": etag}}
+		if !a.withinQuota(w, r, bucket, key) {
+			return
 		}
-		w.WriteHeader(http.StatusOK)
-	case "GetObject":
-		objects := a.buckets[bucket]
-		if objects == nil {
-			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket not found")
+		body, size, err := s3RequestBody(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody", err.Error())
 			return
 		}
-		body, ok := objects[key]
-		if !ok {
-			writeS3Error(w, http.StatusNotFound, "NoSuchKey", "object not found")
+		info := s3ObjectInfoFromHeaders(key, r.Header)
+		info.Size = size
+		info, err = a.backend.PutObject(ctx, bucket, info, body)
+		if err != nil {
+			writeS3BackendError(w, err)
 			return
 		}
-		w.Header().Set("ETag", s3ETag(body))
-		_, _ = io.Copy(w, bytes.NewReader(body))
+		w.Header().Set("ETag", info.ETag)
+		a.remember(idempotencyKey, s3StoredResponse{status: http.StatusOK, headers: map[string]string{"ETag": info.ETag}})
+		w.WriteHeader(http.StatusOK)
+	case "HeadObject", "GetObject":
+		a.writeObject(w, r, bucket, key, action == "HeadObject")
 	case "DeleteObject":
-		objects := a.buckets[bucket]
-		if objects == nil {
-			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket not found")
+		if err := a.backend.DeleteObject(ctx, bucket, key); err != nil {
+			writeS3BackendError(w, err)
 			return
 		}
-		delete(objects, key)
 		w.WriteHeader(http.StatusNoContent)
 	case "DeleteBucket":
-		objects := a.buckets[bucket]
-		if objects == nil {
-			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket not found")
+		if err := a.backend.DeleteBucket(ctx, bucket); err != nil {
+			writeS3BackendError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "CreateMultipartUpload":
+		info := s3ObjectInfoFromHeaders(key, r.Header)
+		uploadID, err := a.backend.CreateMultipartUpload(ctx, bucket, info)
+		if err != nil {
+			writeS3BackendError(w, err)
+			return
+		}
+		writeS3XML(w, s3InitiateMultipartUploadResponse{
+			XMLNS:    s3XMLNS,
+			Bucket:   bucket,
+			Key:      key,
+			UploadID: uploadID,
+		})
+	case "UploadPart":
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		if err != nil || partNumber < 1 || partNumber > 10000 {
+			writeS3Error(w, http.StatusBadRequest, "InvalidArgument", "part number must be an integer between 1 and 10000")
+			return
+		}
+		body, size, err := s3RequestBody(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+		etag, err := a.backend.UploadPart(ctx, bucket, key, query.Get("uploadId"), partNumber, body, size)
+		if err != nil {
+			writeS3BackendError(w, err)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusOK)
+	case "CompleteMultipartUpload":
+		var request s3CompleteMultipartUpload
+		if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeS3Error(w, http.StatusBadRequest, "MalformedXML", "the XML you provided was not well-formed or did not validate against our published schema")
 			return
 		}
-		if len(objects) > 0 {
-			writeS3Error(w, http.StatusConflict, "BucketNotEmpty", "bucket is not empty")
+		if !a.withinQuota(w, r, bucket, key) {
+			return
+		}
+		parts := make([]S3CompletedPart, 0, len(request.Parts))
+		for _, part := range request.Parts {
+			parts = append(parts, S3CompletedPart{PartNumber: part.PartNumber, ETag: part.ETag})
+		}
+		info, err := a.backend.CompleteMultipartUpload(ctx, bucket, key, query.Get("uploadId"), parts)
+		if err != nil {
+			writeS3BackendError(w, err)
+			return
+		}
+		writeS3XML(w, s3CompleteMultipartUploadResponse{
+			XMLNS:  s3XMLNS,
+			Bucket: bucket,
+			Key:    key,
+			ETag:   info.ETag,
+		})
+	case "AbortMultipartUpload":
+		if err := a.backend.AbortMultipartUpload(ctx, bucket, key, query.Get("uploadId")); err != nil {
+			writeS3BackendError(w, err)
 			return
 		}
-		delete(a.buckets, bucket)
-		delete(a.bucketTags, bucket)
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
+// writeObject serves GetObject and HeadObject, honoring Range headers.
+func (a *S3Adapter) writeObject(w http.ResponseWriter, r *http.Request, bucket, key string, headOnly bool) {
+	info, err := a.backend.HeadObject(r.Context(), bucket, key)
+	if err != nil {
+		writeS3BackendError(w, err)
+		return
+	}
+	offset, length, partial, err := parseS3Range(r.Header.Get("Range"), info.Size)
+	if err != nil {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+		writeS3BackendError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if partial {
+		status = http.StatusPartialContent
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, info.Size))
+	}
+	if headOnly {
+		setS3ResponseHeaders(w.Header(), info, length)
+		w.WriteHeader(status)
+		return
+	}
+
+	info, body, err := a.backend.GetObject(r.Context(), bucket, key, offset, length)
+	if err != nil {
+		w.Header().Del("Content-Range")
+		writeS3BackendError(w, err)
+		return
+	}
+	defer body.Close()
+	setS3ResponseHeaders(w.Header(), info, length)
+	w.WriteHeader(status)
+	_, _ = io.Copy(w, body)
+}
+
 type s3Tagging struct {
 	XMLName xml.Name `xml:"Tagging"`
 	TagSet  []s3Tag  `xml:"TagSet>Tag"`
@@ -265,23 +342,56 @@ type s3Tag struct {
 }
 
 type s3ListBucketResult struct {
-	XMLName               xml.Name       `xml:"ListBucketResult"`
-	XMLNS                 string         `xml:"xmlns,attr"`
-	Name                  string         `xml:"Name"`
-	MaxKeys               int            `xml:"MaxKeys"`
-	KeyCount              int            `xml:"KeyCount"`
-	IsTruncated           bool           `xml:"IsTruncated"`
-	Contents              []s3ListObject `xml:"Contents"`
-	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	XMLName               xml.Name         `xml:"ListBucketResult"`
+	XMLNS                 string           `xml:"xmlns,attr"`
+	Name                  string           `xml:"Name"`
+	Prefix                string           `xml:"Prefix"`
+	Delimiter             string           `xml:"Delimiter,omitempty"`
+	StartAfter            string           `xml:"StartAfter,omitempty"`
+	EncodingType          string           `xml:"EncodingType,omitempty"`
+	MaxKeys               int              `xml:"MaxKeys"`
+	KeyCount              int              `xml:"KeyCount"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	Contents              []s3ListObject   `xml:"Contents"`
+	CommonPrefixes        []s3CommonPrefix `xml:"CommonPrefixes"`
+	ContinuationToken     string           `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
 }
 
 type s3ListObject struct {
 	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
 	ETag         string `xml:"ETag"`
-	Size         int    `xml:"Size"`
+	Size         int64  `xml:"Size"`
 	StorageClass string `xml:"StorageClass"`
 }
 
+type s3CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type s3InitiateMultipartUploadResponse struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type s3CompleteMultipartUploadResponse struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	Bucket  string   `xml:"Bucket"`
+	Key     string   `xml:"Key"`
+	ETag    string   `xml:"ETag"`
+}
+
+const s3XMLNS = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+// s3TokenPrefix marks continuation tokens issued by the adapter; the
+// backend token follows it.
+const s3TokenPrefix = "homeport:"
+
 func decodeS3Tags(r io.Reader) (map[string]string, error) {
 	var tagging s3Tagging
 	if err := xml.NewDecoder(r).Decode(&tagging); err != nil {
@@ -300,7 +410,7 @@ func decodeS3Tags(r io.Reader) (map[string]string, error) {
 	return tags, nil
 }
 
-func writeS3Tags(w http.ResponseWriter, tags map[string]string) {
+func writeS3TagsXML(w io.Writer, tags map[string]string) {
 	keys := make([]string, 0, len(tags))
 	for key := range tags {
 		keys = append(keys, key)
@@ -310,75 +420,283 @@ func writeS3Tags(w http.ResponseWriter, tags map[string]string) {
 	for _, key := range keys {
 		tagging.TagSet = append(tagging.TagSet, s3Tag{Key: key, Value: tags[key]})
 	}
+	_ = xml.NewEncoder(w).Encode(tagging)
+}
+
+func writeS3XML(w http.ResponseWriter, value any) {
 	w.Header().Set("Content-Type", "application/xml")
 	_, _ = io.WriteString(w, xml.Header)
-	_ = xml.NewEncoder(w).Encode(tagging)
+	_ = xml.NewEncoder(w).Encode(value)
 }
 
-func (a *S3Adapter) writeListObjectsV2(w http.ResponseWriter, bucket, token, maxKeysValue string) bool {
-	objects := a.buckets[bucket]
-	keys := make([]string, 0, len(objects))
-	for key := range objects {
-		keys = append(keys, key)
+func (a *S3Adapter) writeListObjectsV2(w http.ResponseWriter, r *http.Request, bucket string) {
+	query := r.URL.Query()
+	opts := S3ListOptions{
+		Prefix:     query.Get("prefix"),
+		Delimiter:  query.Get("delimiter"),
+		StartAfter: query.Get("start-after"),
+		MaxKeys:    1000,
 	}
-	sort.Strings(keys)
-
-	start := 0
+	token := query.Get("continuation-token")
 	if token != "" {
-		parsed, err := strconv.Atoi(token)
-		if err != nil || parsed < 0 || parsed > len(keys) {
+		decoded, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil || !strings.HasPrefix(string(decoded), s3TokenPrefix) {
 			writeS3Error(w, http.StatusBadRequest, "InvalidRequest", "invalid continuation token")
-			return false
+			return
 		}
-		start = parsed
+		opts.ContinuationToken = strings.TrimPrefix(string(decoded), s3TokenPrefix)
 	}
-	maxKeys := 1000
-	if maxKeysValue != "" {
-		parsed, err := strconv.Atoi(maxKeysValue)
+	if value := query.Get("max-keys"); value != "" {
+		parsed, err := strconv.Atoi(value)
 		if err != nil || parsed < 0 {
 			writeS3Error(w, http.StatusBadRequest, "InvalidRequest", "invalid max-keys")
-			return false
+			return
+		}
+		if parsed < opts.MaxKeys {
+			opts.MaxKeys = parsed
 		}
-		maxKeys = parsed
 	}
-	end := start + maxKeys
-	if end > len(keys) {
-		end = len(keys)
+	encodingType := query.Get("encoding-type")
+	if encodingType != "" && encodingType != "url" {
+		writeS3Error(w, http.StatusBadRequest, "InvalidArgument", "invalid encoding type")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/xml")
-	result := s3ListBucketResult{
-		XMLNS:       "http://s3.amazonaws.com/doc/2006-03-01/",
-		Name:        bucket,
-		MaxKeys:     maxKeys,
-		KeyCount:    end - start,
-		IsTruncated: end < len(keys),
+	page, err := a.backend.ListObjects(r.Context(), bucket, opts)
+	if err != nil {
+		writeS3BackendError(w, err)
+		return
 	}
-	for _, key := range keys[start:end] {
+
+	encode := func(s string) string { return s }
+	if encodingType == "url" {
+		encode = url.QueryEscape
+	}
+	result := s3ListBucketResult{
+		XMLNS:             s3XMLNS,
+		Name:              bucket,
+		Prefix:            encode(opts.Prefix),
+		Delimiter:         encode(opts.Delimiter),
+		StartAfter:        encode(opts.StartAfter),
+		EncodingType:      encodingType,
+		MaxKeys:           opts.MaxKeys,
+		KeyCount:          len(page.Objects) + len(page.CommonPrefixes),
+		IsTruncated:       page.IsTruncated,
+		ContinuationToken: token,
+	}
+	for _, object := range page.Objects {
 		result.Contents = append(result.Contents, s3ListObject{
-			Key:          key,
-			ETag:         s3ETag(objects[key]),
-			Size:         len(objects[key]),
+			Key:          encode(object.Key),
+			LastModified: object.LastModified.UTC().Format("2006-01-02T15:04:05.000Z"),
+			ETag:         object.ETag,
+			Size:         object.Size,
 			StorageClass: "STANDARD",
 		})
 	}
-	if end < len(keys) {
-		result.NextContinuationToken = strconv.Itoa(end)
+	for _, prefix := range page.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, s3CommonPrefix{Prefix: encode(prefix)})
+	}
+	if page.IsTruncated {
+		result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(s3TokenPrefix + page.NextContinuationToken))
+	}
+	writeS3XML(w, result)
+}
+
+// parseS3Range resolves a Range header against an object of size bytes.
+// Without a range the whole object is returned with length -1; headers
+// that are not a single byte range are ignored, as S3 does.
+func parseS3Range(header string, size int64) (int64, int64, bool, error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, -1, false, nil
+	}
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, -1, false, nil
+	}
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil {
+			return 0, -1, false, nil
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, false, ErrS3InvalidRange
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, suffix, true, nil
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, -1, false, nil
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, -1, false, nil
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return 0, 0, false, ErrS3InvalidRange
+	}
+	return start, end - start + 1, true, nil
+}
+
+// s3RequestBody returns the object data of a PutObject or UploadPart
+// request and its size, or -1 when unknown. aws-chunked bodies, which SDKs
+// send with streaming signatures or trailing checksums, are decoded.
+func s3RequestBody(r *http.Request) (io.Reader, int64, error) {
+	if !strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") &&
+		!strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return r.Body, r.ContentLength, nil
+	}
+	size := int64(-1)
+	if value := r.Header.Get("X-Amz-Decoded-Content-Length"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid x-amz-decoded-content-length")
+		}
+		size = parsed
+	}
+	return &s3ChunkedReader{r: bufio.NewReader(r.Body)}, size, nil
+}
+
+// s3ChunkedReader decodes an aws-chunked body: hex-sized chunks with
+// optional signatures, ended by a zero-sized chunk and optional trailers.
+type s3ChunkedReader struct {
+	r         *bufio.Reader
+	remaining int64
+	done      bool
+}
+
+func (c *s3ChunkedReader) Read(p []byte) (int, error) {
+	for c.remaining == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		line, err := c.r.ReadString('\n')
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sizeField, _, _ := strings.Cut(line, ";")
+		size, err := strconv.ParseInt(sizeField, 16, 64)
+		if err != nil || size < 0 {
+			return 0, fmt.Errorf("invalid aws-chunked chunk size %q", sizeField)
+		}
+		if size == 0 {
+			c.done = true
+			return 0, io.EOF
+		}
+		c.remaining = size
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// s3ObjectHeaders are the standard headers stored with an object.
+var s3ObjectHeaders = []string{"Content-Type", "Content-Encoding", "Content-Disposition", "Content-Language", "Cache-Control", "Expires"}
+
+func s3ObjectInfoFromHeaders(key string, header http.Header) S3ObjectInfo {
+	info := S3ObjectInfo{
+		Key:                key,
+		Size:               -1,
+		ETag:               header.Get("ETag"),
+		ContentType:        header.Get("Content-Type"),
+		ContentEncoding:    strings.Trim(strings.ReplaceAll(header.Get("Content-Encoding"), "aws-chunked", ""), ", "),
+		ContentDisposition: header.Get("Content-Disposition"),
+		ContentLanguage:    header.Get("Content-Language"),
+		CacheControl:       header.Get("Cache-Control"),
+		Expires:            header.Get("Expires"),
+	}
+	if value := header.Get("Content-Length"); value != "" {
+		info.Size, _ = strconv.ParseInt(value, 10, 64)
+	}
+	if value := header.Get("Last-Modified"); value != "" {
+		info.LastModified, _ = http.ParseTime(value)
+	}
+	for name, values := range header {
+		if meta, ok := strings.CutPrefix(strings.ToLower(name), "x-amz-meta-"); ok && len(values) > 0 {
+			if info.Metadata == nil {
+				info.Metadata = map[string]string{}
+			}
+			info.Metadata[meta] = values[0]
+		}
+	}
+	return info
+}
+
+// setS3ObjectHeaders sets the stored metadata headers of info.
+func setS3ObjectHeaders(header http.Header, info S3ObjectInfo) {
+	for i, value := range []string{info.ContentType, info.ContentEncoding, info.ContentDisposition, info.ContentLanguage, info.CacheControl, info.Expires} {
+		if value != "" {
+			header.Set(s3ObjectHeaders[i], value)
+		}
+	}
+	for name, value := range info.Metadata {
+		header.Set("X-Amz-Meta-"+name, value)
+	}
+}
+
+// setS3ResponseHeaders sets the GetObject and HeadObject response headers
+// for a body of length bytes, or the whole object when length is negative.
+func setS3ResponseHeaders(header http.Header, info S3ObjectInfo, length int64) {
+	setS3ObjectHeaders(header, info)
+	if info.ContentType == "" {
+		header.Set("Content-Type", "binary/octet-stream")
+	}
+	if length < 0 {
+		length = info.Size
+	}
+	header.Set("Content-Length", strconv.FormatInt(length, 10))
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("ETag", info.ETag)
+	if !info.LastModified.IsZero() {
+		header.Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
 	}
-	_ = xml.NewEncoder(w).Encode(result)
-	return true
 }
 
 func s3ETag(body []byte) string {
 	return fmt.Sprintf(`"%x"`, md5.Sum(body))
 }
 
-func (a *S3Adapter) objectCount() int {
-	count := 0
-	for _, objects := range a.buckets {
-		count += len(objects)
+// withinQuota reports whether storing key keeps the object count within
+// the quota, writing SlowDown when it does not.
+func (a *S3Adapter) withinQuota(w http.ResponseWriter, r *http.Request, bucket, key string) bool {
+	if a.objectQuota <= 0 {
+		return true
+	}
+	if _, err := a.backend.HeadObject(r.Context(), bucket, key); err == nil {
+		return true
 	}
-	return count
+	count, err := a.backend.ObjectCount(r.Context())
+	if err != nil {
+		writeS3BackendError(w, err)
+		return false
+	}
+	if count >= a.objectQuota {
+		writeS3Error(w, http.StatusTooManyRequests, "SlowDown", "object quota exceeded")
+		return false
+	}
+	return true
 }
 
 func (a *S3Adapter) backendError(method string) error {
@@ -393,6 +711,30 @@ func s3IdempotencyKey(r *http.Request, action, bucket string) string {
 	return action + ":" + bucket + ":" + key
 }
 
+// replay writes the stored response of an idempotent request that was
+// already served.
+func (a *S3Adapter) replay(w http.ResponseWriter, idempotencyKey string) bool {
+	if idempotencyKey == "" {
+		return false
+	}
+	a.mu.Lock()
+	stored, ok := a.idempotency[idempotencyKey]
+	a.mu.Unlock()
+	if ok {
+		writeS3StoredResponse(w, stored)
+	}
+	return ok
+}
+
+func (a *S3Adapter) remember(idempotencyKey string, stored s3StoredResponse) {
+	if idempotencyKey == "" {
+		return
+	}
+	a.mu.Lock()
+	a.idempotency[idempotencyKey] = stored
+	a.mu.Unlock()
+}
+
 func writeS3StoredResponse(w http.ResponseWriter, stored s3StoredResponse) {
 	for key, value := range stored.headers {
 		w.Header().Set(key, value)
@@ -417,15 +759,17 @@ func (a *S3Adapter) authorized(w http.ResponseWriter, r *http.Request, action, b
 	if value := r.Header.Get("X-Homeport-Credential-Age"); value != "" {
 		context["credential_age"] = value
 	}
-	for tagKey, tagValue := range a.bucketTags[bucket] {
+	tags, _ := a.backend.BucketTags(r.Context(), bucket)
+	for tagKey, tagValue := range tags {
 		context["tag:"+tagKey] = tagValue
 	}
 	req := authz.Request{
-		Principal: awsPrincipal(r),
-		Action:    "s3:" + action,
-		Resource:  s3ARN(bucket, key),
-		Context:   context,
-		Claims:    awsClaims(r),
+		Principal:           awsPrincipal(r),
+		PrincipalAttributes: awsPrincipalAttributes(r),
+		Action:              "s3:" + action,
+		Resource:            s3ARN(bucket, key),
+		Context:             context,
+		Claims:              awsClaims(r),
 	}
 	decision, err := a.authorizer.Authorize(r.Context(), req)
 	if err != nil {
@@ -455,8 +799,10 @@ func s3Path(r *http.Request) (string, string) {
 	return bucket, parts[1]
 }
 
-func s3Action(method, key string, tagging, policy bool) string {
-	switch method {
+func s3Action(r *http.Request, key string) string {
+	query := r.URL.Query()
+	tagging, policy := query.Has("tagging"), query.Has("policy")
+	switch r.Method {
 	case http.MethodPut:
 		if key == "" && tagging {
 			return "PutBucketTagging"
@@ -464,11 +810,25 @@ func s3Action(method, key string, tagging, policy bool) string {
 		if key == "" {
 			return "CreateBucket"
 		}
+		if query.Has("uploadId") && query.Has("partNumber") && r.Header.Get("X-Amz-Copy-Source") == "" {
+			return "UploadPart"
+		}
+		if query.Has("uploadId") || r.Header.Get("X-Amz-Copy-Source") != "" || tagging {
+			return ""
+		}
 		return "PutObject"
+	case http.MethodPost:
+		if key != "" && query.Has("uploads") {
+			return "CreateMultipartUpload"
+		}
+		if key != "" && query.Has("uploadId") {
+			return "CompleteMultipartUpload"
+		}
 	case http.MethodHead:
 		if key == "" {
 			return "HeadBucket"
 		}
+		return "HeadObject"
 	case http.MethodGet:
 		if key == "" && policy {
 			return "GetBucketPolicy"
@@ -479,16 +839,18 @@ func s3Action(method, key string, tagging, policy bool) string {
 		if key == "" {
 			return "ListObjectsV2"
 		}
-		if key != "" {
-			return "GetObject"
+		if query.Has("uploadId") || tagging {
+			return ""
 		}
+		return "GetObject"
 	case http.MethodDelete:
 		if key == "" {
 			return "DeleteBucket"
 		}
-		if key != "" {
-			return "DeleteObject"
+		if query.Has("uploadId") {
+			return "AbortMultipartUpload"
 		}
+		return "DeleteObject"
 	}
 	return ""
 }
@@ -520,6 +882,23 @@ func isS3BucketEdge(c byte) bool {
 	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
 }
 
+// writeS3BackendError writes a backend error as its S3 error response, or
+// as InternalError when it is not an S3Error.
+func writeS3BackendError(w http.ResponseWriter, err error) {
+	var s3Err *S3Error
+	if errors.As(err, &s3Err) {
+		writeS3Error(w, s3Err.Status, s3Err.Code, s3Err.Message)
+		return
+	}
+	// A streamed chunk whose signature does not match fails the upload
+	var sigErr *SigV4Error
+	if errors.As(err, &sigErr) {
+		writeS3Error(w, sigErr.Status, sigErr.Code, xmlEscape(sigErr.Message))
+		return
+	}
+	writeS3Error(w, http.StatusInternalServerError, "InternalError", err.Error())
+}
+
 func writeS3Error(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/xml")
 	w.WriteHeader(status)
@@ -0,0 +1,518 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// S3Backend stores the buckets and objects served by the S3 adapter.
+// Implementations must be safe for concurrent use and report missing or
+// conflicting state with the S3Error values below.
+type S3Backend interface {
+	CreateBucket(ctx context.Context, bucket string) error
+	HeadBucket(ctx context.Context, bucket string) error
+	DeleteBucket(ctx context.Context, bucket string) error
+	PutBucketTags(ctx context.Context, bucket string, tags map[string]string) error
+	BucketTags(ctx context.Context, bucket string) (map[string]string, error)
+
+	// PutObject stores body under info.Key. info.Size is the body length,
+	// or -1 when unknown.
+	PutObject(ctx context.Context, bucket string, info S3ObjectInfo, body io.Reader) (S3ObjectInfo, error)
+	HeadObject(ctx context.Context, bucket, key string) (S3ObjectInfo, error)
+	// GetObject returns length bytes starting at offset; a negative length
+	// reads to the end of the object.
+	GetObject(ctx context.Context, bucket, key string, offset, length int64) (S3ObjectInfo, io.ReadCloser, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+	ListObjects(ctx context.Context, bucket string, opts S3ListOptions) (S3ListPage, error)
+	ObjectCount(ctx context.Context) (int, error)
+
+	CreateMultipartUpload(ctx context.Context, bucket string, info S3ObjectInfo) (string, error)
+	UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body io.Reader, size int64) (string, error)
+	CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []S3CompletedPart) (S3ObjectInfo, error)
+	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
+}
+
+// S3ObjectInfo describes a stored object and the metadata headers it was
+// uploaded with.
+type S3ObjectInfo struct {
+	Key                string            `json:"key"`
+	Size               int64             `json:"size"`
+	ETag               string            `json:"etag"`
+	LastModified       time.Time         `json:"lastModified"`
+	ContentType        string            `json:"contentType,omitempty"`
+	ContentEncoding    string            `json:"contentEncoding,omitempty"`
+	ContentDisposition string            `json:"contentDisposition,omitempty"`
+	ContentLanguage    string            `json:"contentLanguage,omitempty"`
+	CacheControl       string            `json:"cacheControl,omitempty"`
+	Expires            string            `json:"expires,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+}
+
+// S3ListOptions selects a page of ListObjectsV2 results. ContinuationToken
+// is the backend token from a previous S3ListPage.
+type S3ListOptions struct {
+	Prefix            string
+	Delimiter         string
+	StartAfter        string
+	ContinuationToken string
+	MaxKeys           int
+}
+
+// S3ListPage is one page of ListObjectsV2 results.
+type S3ListPage struct {
+	Objects               []S3ObjectInfo
+	CommonPrefixes        []string
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// S3CompletedPart is a part named in CompleteMultipartUpload.
+type S3CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// S3Error is an S3 error response.
+type S3Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *S3Error) Error() string { return e.Code + ": " + e.Message }
+
+// Is matches S3 errors by code, so errors decoded from a remote backend
+// compare equal to the sentinels.
+func (e *S3Error) Is(target error) bool {
+	other, ok := target.(*S3Error)
+	return ok && other.Code == e.Code
+}
+
+var (
+	ErrS3NoSuchBucket      = &S3Error{http.StatusNotFound, "NoSuchBucket", "bucket not found"}
+	ErrS3BucketExists      = &S3Error{http.StatusConflict, "BucketAlreadyOwnedByYou", "bucket already exists"}
+	ErrS3BucketNotEmpty    = &S3Error{http.StatusConflict, "BucketNotEmpty", "bucket is not empty"}
+	ErrS3NoSuchKey         = &S3Error{http.StatusNotFound, "NoSuchKey", "object not found"}
+	ErrS3NoSuchUpload      = &S3Error{http.StatusNotFound, "NoSuchUpload", "multipart upload not found"}
+	ErrS3InvalidPart       = &S3Error{http.StatusBadRequest, "InvalidPart", "one or more of the specified parts could not be found"}
+	ErrS3InvalidPartOrder  = &S3Error{http.StatusBadRequest, "InvalidPartOrder", "parts must be listed in ascending order"}
+	ErrS3EntityTooSmall    = &S3Error{http.StatusBadRequest, "EntityTooSmall", "part is smaller than the minimum allowed size"}
+	ErrS3InvalidRange      = &S3Error{http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "the requested range is not satisfiable"}
+	ErrS3MalformedPartList = &S3Error{http.StatusBadRequest, "MalformedXML", "the part list is empty or not well-formed"}
+)
+
+// s3MinPartSize is the minimum size of every part but the last.
+const s3MinPartSize = 5 << 20
+
+// NewS3BackendFromEnv returns the backend selected by
+// HOMEPORT_COMPAT_S3_BACKEND: "filesystem" (the default) under
+// HOMEPORT_COMPAT_S3_DIR or ~/.homeport/compat/s3, "minio" at MINIO_ENDPOINT
+// with the MINIO_ROOT_USER credentials of the generated storage stack, or
+// "memory".
+func NewS3BackendFromEnv() (S3Backend, error) {
+	switch backend := os.Getenv("HOMEPORT_COMPAT_S3_BACKEND"); backend {
+	case "", "filesystem", "fs":
+		dir := os.Getenv("HOMEPORT_COMPAT_S3_DIR")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			dir = filepath.Join(home, ".homeport", "compat", "s3")
+		}
+		return NewFileS3Backend(dir)
+	case "minio":
+		return NewMinIOS3Backend(
+			envOr("MINIO_ENDPOINT", "http://minio:9000"),
+			envOr("MINIO_ROOT_USER", "admin"),
+			envOr("MINIO_ROOT_PASSWORD", "changeme123"),
+		)
+	case "memory":
+		return NewMemoryS3Backend(), nil
+	default:
+		return nil, fmt.Errorf("unknown S3 compat backend %q", backend)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// NewMemoryS3Backend returns a backend that keeps everything in memory.
+func NewMemoryS3Backend() S3Backend {
+	return &memoryS3Backend{
+		buckets: map[string]*memoryS3Bucket{},
+		uploads: map[string]*memoryS3Upload{},
+	}
+}
+
+type memoryS3Backend struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryS3Bucket
+	uploads map[string]*memoryS3Upload
+}
+
+type memoryS3Bucket struct {
+	tags    map[string]string
+	objects map[string]memoryS3Object
+}
+
+type memoryS3Object struct {
+	info S3ObjectInfo
+	body []byte
+}
+
+type memoryS3Upload struct {
+	bucket string
+	info   S3ObjectInfo
+	parts  map[int][]byte
+}
+
+func (b *memoryS3Backend) CreateBucket(_ context.Context, bucket string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buckets[bucket] != nil {
+		return ErrS3BucketExists
+	}
+	b.buckets[bucket] = &memoryS3Bucket{tags: map[string]string{}, objects: map[string]memoryS3Object{}}
+	return nil
+}
+
+func (b *memoryS3Backend) HeadBucket(_ context.Context, bucket string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, err := b.bucket(bucket)
+	return err
+}
+
+func (b *memoryS3Backend) DeleteBucket(_ context.Context, bucket string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored, err := b.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	if len(stored.objects) > 0 {
+		return ErrS3BucketNotEmpty
+	}
+	delete(b.buckets, bucket)
+	for id, upload := range b.uploads {
+		if upload.bucket == bucket {
+			delete(b.uploads, id)
+		}
+	}
+	return nil
+}
+
+func (b *memoryS3Backend) PutBucketTags(_ context.Context, bucket string, tags map[string]string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored, err := b.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	stored.tags = copyStringMap(tags)
+	return nil
+}
+
+func (b *memoryS3Backend) BucketTags(_ context.Context, bucket string) (map[string]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored, err := b.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return copyStringMap(stored.tags), nil
+}
+
+func (b *memoryS3Backend) PutObject(_ context.Context, bucket string, info S3ObjectInfo, body io.Reader) (S3ObjectInfo, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	info.Size = int64(len(data))
+	info.ETag = s3ETag(data)
+	info.LastModified = time.Now().UTC()
+	return info, b.store(bucket, info, data)
+}
+
+func (b *memoryS3Backend) store(bucket string, info S3ObjectInfo, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored, err := b.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	stored.objects[info.Key] = memoryS3Object{info: info, body: data}
+	return nil
+}
+
+func (b *memoryS3Backend) HeadObject(_ context.Context, bucket, key string) (S3ObjectInfo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	object, err := b.object(bucket, key)
+	return object.info, err
+}
+
+func (b *memoryS3Backend) GetObject(_ context.Context, bucket, key string, offset, length int64) (S3ObjectInfo, io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	object, err := b.object(bucket, key)
+	if err != nil {
+		return S3ObjectInfo{}, nil, err
+	}
+	end := int64(len(object.body))
+	if length >= 0 && offset+length < end {
+		end = offset + length
+	}
+	if offset > end {
+		return S3ObjectInfo{}, nil, ErrS3InvalidRange
+	}
+	return object.info, io.NopCloser(bytes.NewReader(object.body[offset:end])), nil
+}
+
+func (b *memoryS3Backend) DeleteObject(_ context.Context, bucket, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored, err := b.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	delete(stored.objects, key)
+	return nil
+}
+
+func (b *memoryS3Backend) ListObjects(_ context.Context, bucket string, opts S3ListOptions) (S3ListPage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored, err := b.bucket(bucket)
+	if err != nil {
+		return S3ListPage{}, err
+	}
+	keys := make([]string, 0, len(stored.objects))
+	for key := range stored.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	page, matched := listS3Keys(keys, opts)
+	for _, key := range matched {
+		page.Objects = append(page.Objects, stored.objects[key].info)
+	}
+	return page, nil
+}
+
+func (b *memoryS3Backend) ObjectCount(context.Context) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	count := 0
+	for _, stored := range b.buckets {
+		count += len(stored.objects)
+	}
+	return count, nil
+}
+
+func (b *memoryS3Backend) CreateMultipartUpload(_ context.Context, bucket string, info S3ObjectInfo) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.bucket(bucket); err != nil {
+		return "", err
+	}
+	id := newS3UploadID()
+	b.uploads[id] = &memoryS3Upload{bucket: bucket, info: info, parts: map[int][]byte{}}
+	return id, nil
+}
+
+func (b *memoryS3Backend) UploadPart(_ context.Context, bucket, key, uploadID string, partNumber int, body io.Reader, _ int64) (string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	upload, err := b.upload(bucket, key, uploadID)
+	if err != nil {
+		return "", err
+	}
+	upload.parts[partNumber] = data
+	return s3ETag(data), nil
+}
+
+func (b *memoryS3Backend) CompleteMultipartUpload(_ context.Context, bucket, key, uploadID string, parts []S3CompletedPart) (S3ObjectInfo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	upload, err := b.upload(bucket, key, uploadID)
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	uploaded := map[int]s3UploadedPart{}
+	for number, data := range upload.parts {
+		uploaded[number] = s3UploadedPart{etag: s3ETag(data), size: int64(len(data))}
+	}
+	etag, err := completeS3Parts(parts, uploaded)
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	var data []byte
+	for _, part := range parts {
+		data = append(data, upload.parts[part.PartNumber]...)
+	}
+	info := upload.info
+	info.Size = int64(len(data))
+	info.ETag = etag
+	info.LastModified = time.Now().UTC()
+	stored, err := b.bucket(bucket)
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	stored.objects[key] = memoryS3Object{info: info, body: data}
+	delete(b.uploads, uploadID)
+	return info, nil
+}
+
+func (b *memoryS3Backend) AbortMultipartUpload(_ context.Context, bucket, key, uploadID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.upload(bucket, key, uploadID); err != nil {
+		return err
+	}
+	delete(b.uploads, uploadID)
+	return nil
+}
+
+func (b *memoryS3Backend) bucket(bucket string) (*memoryS3Bucket, error) {
+	stored := b.buckets[bucket]
+	if stored == nil {
+		return nil, ErrS3NoSuchBucket
+	}
+	return stored, nil
+}
+
+func (b *memoryS3Backend) object(bucket, key string) (memoryS3Object, error) {
+	stored, err := b.bucket(bucket)
+	if err != nil {
+		return memoryS3Object{}, err
+	}
+	object, ok := stored.objects[key]
+	if !ok {
+		return memoryS3Object{}, ErrS3NoSuchKey
+	}
+	return object, nil
+}
+
+func (b *memoryS3Backend) upload(bucket, key, uploadID string) (*memoryS3Upload, error) {
+	if _, err := b.bucket(bucket); err != nil {
+		return nil, err
+	}
+	upload := b.uploads[uploadID]
+	if upload == nil || upload.bucket != bucket || upload.info.Key != key {
+		return nil, ErrS3NoSuchUpload
+	}
+	return upload, nil
+}
+
+// listS3Keys applies ListObjectsV2 semantics to sorted keys. It returns the
+// page without objects and the keys whose objects belong on it. The
+// continuation token is the last key or common prefix returned.
+func listS3Keys(keys []string, opts S3ListOptions) (S3ListPage, []string) {
+	var page S3ListPage
+	var matched []string
+	marker := opts.StartAfter
+	if opts.ContinuationToken > marker {
+		marker = opts.ContinuationToken
+	}
+	if opts.MaxKeys == 0 {
+		return page, nil
+	}
+
+	last := ""
+	count := 0
+	for _, key := range keys {
+		if key <= marker || !strings.HasPrefix(key, opts.Prefix) {
+			continue
+		}
+		entry := key
+		isPrefix := false
+		if opts.Delimiter != "" {
+			if i := strings.Index(key[len(opts.Prefix):], opts.Delimiter); i >= 0 {
+				entry = key[:len(opts.Prefix)+i+len(opts.Delimiter)]
+				isPrefix = true
+			}
+		}
+		if isPrefix && (entry == last || entry == marker) {
+			continue
+		}
+		if count == opts.MaxKeys {
+			page.IsTruncated = true
+			page.NextContinuationToken = last
+			break
+		}
+		if isPrefix {
+			page.CommonPrefixes = append(page.CommonPrefixes, entry)
+		} else {
+			matched = append(matched, key)
+		}
+		last = entry
+		count++
+	}
+	return page, matched
+}
+
+type s3UploadedPart struct {
+	etag string
+	size int64
+}
+
+// completeS3Parts validates the part list of CompleteMultipartUpload
+// against the uploaded parts and returns the multipart ETag: the MD5 of the
+// concatenated part digests, suffixed with the part count.
+func completeS3Parts(parts []S3CompletedPart, uploaded map[int]s3UploadedPart) (string, error) {
+	if len(parts) == 0 {
+		return "", ErrS3MalformedPartList
+	}
+	digests := md5.New()
+	for i, part := range parts {
+		if i > 0 && part.PartNumber <= parts[i-1].PartNumber {
+			return "", ErrS3InvalidPartOrder
+		}
+		stored, ok := uploaded[part.PartNumber]
+		if !ok || strings.Trim(part.ETag, `"`) != strings.Trim(stored.etag, `"`) {
+			return "", ErrS3InvalidPart
+		}
+		if i < len(parts)-1 && stored.size < s3MinPartSize {
+			return "", ErrS3EntityTooSmall
+		}
+		digest, err := hex.DecodeString(strings.Trim(stored.etag, `"`))
+		if err != nil {
+			return "", ErrS3InvalidPart
+		}
+		digests.Write(digest)
+	}
+	return fmt.Sprintf(`"%x-%d"`, digests.Sum(nil), len(parts)), nil
+}
+
+func newS3UploadID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func copyStringMap(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in))
+	mergeStringMap(out, in)
+	return out
+}
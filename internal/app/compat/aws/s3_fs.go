@@ -0,0 +1,495 @@
+package aws
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewFileS3Backend returns a backend that stores objects under dir. Each
+// bucket is a directory holding bucket.json, object data files named after
+// the SHA-256 of their key, and a JSON sidecar per object with its key and
+// metadata. In-progress multipart uploads live under uploads/<id>.
+func NewFileS3Backend(dir string) (S3Backend, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create S3 storage directory: %w", err)
+	}
+	return &fileS3Backend{dir: dir, index: map[string]map[string]struct{}{}}, nil
+}
+
+type fileS3Backend struct {
+	dir string
+
+	mu sync.Mutex
+	// index caches the keys of each bucket, loaded from the sidecars on
+	// first use, so listings do not have to read every sidecar.
+	index map[string]map[string]struct{}
+}
+
+type fileS3Bucket struct {
+	Created time.Time         `json:"created"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+type fileS3Part struct {
+	ETag string `json:"etag"`
+	Size int64  `json:"size"`
+}
+
+func (b *fileS3Backend) CreateBucket(_ context.Context, bucket string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := os.Stat(b.bucketFile(bucket)); err == nil {
+		return ErrS3BucketExists
+	}
+	for _, dir := range []string{b.objectsDir(bucket), b.uploadsDir(bucket)} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	b.index[bucket] = map[string]struct{}{}
+	return writeJSONFile(b.bucketFile(bucket), fileS3Bucket{Created: time.Now().UTC()})
+}
+
+func (b *fileS3Backend) HeadBucket(_ context.Context, bucket string) error {
+	_, err := b.readBucket(bucket)
+	return err
+}
+
+func (b *fileS3Backend) DeleteBucket(_ context.Context, bucket string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keys, err := b.keys(bucket)
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return ErrS3BucketNotEmpty
+	}
+	delete(b.index, bucket)
+	return os.RemoveAll(filepath.Join(b.dir, bucket))
+}
+
+func (b *fileS3Backend) PutBucketTags(_ context.Context, bucket string, tags map[string]string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored, err := b.readBucket(bucket)
+	if err != nil {
+		return err
+	}
+	stored.Tags = copyStringMap(tags)
+	return writeJSONFile(b.bucketFile(bucket), stored)
+}
+
+func (b *fileS3Backend) BucketTags(_ context.Context, bucket string) (map[string]string, error) {
+	stored, err := b.readBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return copyStringMap(stored.Tags), nil
+}
+
+func (b *fileS3Backend) PutObject(_ context.Context, bucket string, info S3ObjectInfo, body io.Reader) (S3ObjectInfo, error) {
+	if _, err := b.readBucket(bucket); err != nil {
+		return S3ObjectInfo{}, err
+	}
+	tmp, etag, size, err := b.writeTemp(bucket, body)
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	info.Size = size
+	info.ETag = etag
+	info.LastModified = time.Now().UTC()
+	return info, b.commit(bucket, info, tmp)
+}
+
+func (b *fileS3Backend) HeadObject(_ context.Context, bucket, key string) (S3ObjectInfo, error) {
+	return b.readObject(bucket, key)
+}
+
+func (b *fileS3Backend) GetObject(_ context.Context, bucket, key string, offset, length int64) (S3ObjectInfo, io.ReadCloser, error) {
+	info, err := b.readObject(bucket, key)
+	if err != nil {
+		return S3ObjectInfo{}, nil, err
+	}
+	if offset > info.Size {
+		return S3ObjectInfo{}, nil, ErrS3InvalidRange
+	}
+	file, err := os.Open(b.dataFile(bucket, key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return S3ObjectInfo{}, nil, ErrS3NoSuchKey
+		}
+		return S3ObjectInfo{}, nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return S3ObjectInfo{}, nil, err
+	}
+	if length < 0 {
+		return info, file, nil
+	}
+	return info, struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}, nil
+}
+
+func (b *fileS3Backend) DeleteObject(_ context.Context, bucket, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keys, err := b.keys(bucket)
+	if err != nil {
+		return err
+	}
+	for _, path := range []string{b.metaFile(bucket, key), b.dataFile(bucket, key)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	delete(keys, key)
+	return nil
+}
+
+func (b *fileS3Backend) ListObjects(_ context.Context, bucket string, opts S3ListOptions) (S3ListPage, error) {
+	b.mu.Lock()
+	keys, err := b.keys(bucket)
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	b.mu.Unlock()
+	if err != nil {
+		return S3ListPage{}, err
+	}
+	sort.Strings(sorted)
+
+	page, matched := listS3Keys(sorted, opts)
+	for _, key := range matched {
+		info, err := b.readObject(bucket, key)
+		if errors.Is(err, ErrS3NoSuchKey) {
+			continue
+		}
+		if err != nil {
+			return S3ListPage{}, err
+		}
+		page.Objects = append(page.Objects, info)
+	}
+	return page, nil
+}
+
+func (b *fileS3Backend) ObjectCount(context.Context) (int, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	count := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		keys, err := b.keys(entry.Name())
+		if errors.Is(err, ErrS3NoSuchBucket) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		count += len(keys)
+	}
+	return count, nil
+}
+
+func (b *fileS3Backend) CreateMultipartUpload(_ context.Context, bucket string, info S3ObjectInfo) (string, error) {
+	if _, err := b.readBucket(bucket); err != nil {
+		return "", err
+	}
+	id := newS3UploadID()
+	dir := filepath.Join(b.uploadsDir(bucket), id)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return id, writeJSONFile(filepath.Join(dir, "upload.json"), info)
+}
+
+func (b *fileS3Backend) UploadPart(_ context.Context, bucket, key, uploadID string, partNumber int, body io.Reader, _ int64) (string, error) {
+	dir, _, err := b.upload(bucket, key, uploadID)
+	if err != nil {
+		return "", err
+	}
+	tmp, etag, size, err := b.writeTemp(bucket, body)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Join(dir, strconv.Itoa(partNumber))
+	if err := os.Rename(tmp, name+".part"); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return etag, writeJSONFile(name+".json", fileS3Part{ETag: etag, Size: size})
+}
+
+func (b *fileS3Backend) CompleteMultipartUpload(_ context.Context, bucket, key, uploadID string, parts []S3CompletedPart) (S3ObjectInfo, error) {
+	dir, info, err := b.upload(bucket, key, uploadID)
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	uploaded := map[int]s3UploadedPart{}
+	for _, part := range parts {
+		var stored fileS3Part
+		if err := readJSONFile(filepath.Join(dir, strconv.Itoa(part.PartNumber)+".json"), &stored); err == nil {
+			uploaded[part.PartNumber] = s3UploadedPart{etag: stored.ETag, size: stored.Size}
+		}
+	}
+	etag, err := completeS3Parts(parts, uploaded)
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+
+	body := &partReader{paths: make([]string, 0, len(parts))}
+	for _, part := range parts {
+		body.paths = append(body.paths, filepath.Join(dir, strconv.Itoa(part.PartNumber)+".part"))
+	}
+	tmp, _, size, err := b.writeTemp(bucket, body)
+	body.Close()
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	info.Size = size
+	info.ETag = etag
+	info.LastModified = time.Now().UTC()
+	if err := b.commit(bucket, info, tmp); err != nil {
+		return S3ObjectInfo{}, err
+	}
+	return info, os.RemoveAll(dir)
+}
+
+// partReader reads the part files of an upload one after another. Only the
+// part being read is open, so uploads with many parts don't run out of file
+// descriptors.
+type partReader struct {
+	paths []string
+	file  *os.File
+}
+
+func (r *partReader) Read(p []byte) (int, error) {
+	for {
+		if r.file == nil {
+			if len(r.paths) == 0 {
+				return 0, io.EOF
+			}
+			file, err := os.Open(r.paths[0])
+			if err != nil {
+				return 0, err
+			}
+			r.file, r.paths = file, r.paths[1:]
+		}
+
+		n, err := r.file.Read(p)
+		if err == io.EOF {
+			err = r.Close()
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// Close closes the part being read, if any.
+func (r *partReader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (b *fileS3Backend) AbortMultipartUpload(_ context.Context, bucket, key, uploadID string) error {
+	dir, _, err := b.upload(bucket, key, uploadID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// writeTemp streams body into a temporary file in the bucket and returns
+// its path, ETag and size.
+func (b *fileS3Backend) writeTemp(bucket string, body io.Reader) (string, string, int64, error) {
+	file, err := os.CreateTemp(b.objectsDir(bucket), ".upload-*")
+	if err != nil {
+		return "", "", 0, err
+	}
+	hash := md5.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", "", 0, err
+	}
+	return file.Name(), fmt.Sprintf(`"%x"`, hash.Sum(nil)), size, nil
+}
+
+// commit moves an uploaded temporary file into place and writes its
+// sidecar. The sidecar is written last: an object exists once it has one.
+func (b *fileS3Backend) commit(bucket string, info S3ObjectInfo, tmp string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keys, err := b.keys(bucket)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, b.dataFile(bucket, info.Key)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := writeJSONFile(b.metaFile(bucket, info.Key), info); err != nil {
+		return err
+	}
+	keys[info.Key] = struct{}{}
+	return nil
+}
+
+// keys returns the key index of bucket, loading it if needed. The caller
+// must hold b.mu.
+func (b *fileS3Backend) keys(bucket string) (map[string]struct{}, error) {
+	if _, err := b.readBucket(bucket); err != nil {
+		delete(b.index, bucket)
+		return nil, err
+	}
+	if keys, ok := b.index[bucket]; ok {
+		return keys, nil
+	}
+	entries, err := os.ReadDir(b.objectsDir(bucket))
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]struct{}{}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		var info S3ObjectInfo
+		if err := readJSONFile(filepath.Join(b.objectsDir(bucket), entry.Name()), &info); err != nil {
+			return nil, err
+		}
+		keys[info.Key] = struct{}{}
+	}
+	b.index[bucket] = keys
+	return keys, nil
+}
+
+func (b *fileS3Backend) readBucket(bucket string) (fileS3Bucket, error) {
+	var stored fileS3Bucket
+	if err := readJSONFile(b.bucketFile(bucket), &stored); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return stored, ErrS3NoSuchBucket
+		}
+		return stored, err
+	}
+	return stored, nil
+}
+
+func (b *fileS3Backend) readObject(bucket, key string) (S3ObjectInfo, error) {
+	if _, err := b.readBucket(bucket); err != nil {
+		return S3ObjectInfo{}, err
+	}
+	var info S3ObjectInfo
+	if err := readJSONFile(b.metaFile(bucket, key), &info); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return info, ErrS3NoSuchKey
+		}
+		return info, err
+	}
+	return info, nil
+}
+
+// upload returns the directory and object info of a multipart upload.
+func (b *fileS3Backend) upload(bucket, key, uploadID string) (string, S3ObjectInfo, error) {
+	var info S3ObjectInfo
+	if _, err := b.readBucket(bucket); err != nil {
+		return "", info, err
+	}
+	if _, err := hex.DecodeString(uploadID); err != nil || len(uploadID) != 32 {
+		return "", info, ErrS3NoSuchUpload
+	}
+	dir := filepath.Join(b.uploadsDir(bucket), uploadID)
+	if err := readJSONFile(filepath.Join(dir, "upload.json"), &info); err != nil || info.Key != key {
+		return "", info, ErrS3NoSuchUpload
+	}
+	return dir, info, nil
+}
+
+func (b *fileS3Backend) bucketFile(bucket string) string {
+	return filepath.Join(b.dir, bucket, "bucket.json")
+}
+
+func (b *fileS3Backend) objectsDir(bucket string) string {
+	return filepath.Join(b.dir, bucket, "objects")
+}
+
+func (b *fileS3Backend) uploadsDir(bucket string) string {
+	return filepath.Join(b.dir, bucket, "uploads")
+}
+
+func (b *fileS3Backend) dataFile(bucket, key string) string {
+	return filepath.Join(b.objectsDir(bucket), s3KeyHash(key)+".data")
+}
+
+func (b *fileS3Backend) metaFile(bucket, key string) string {
+	return filepath.Join(b.objectsDir(bucket), s3KeyHash(key)+".json")
+}
+
+func s3KeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeJSONFile replaces path atomically with the JSON encoding of value.
+func writeJSONFile(path string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func readJSONFile(path string, value any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
@@ -0,0 +1,379 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewMinIOS3Backend returns a backend that proxies to the MinIO server of
+// the generated storage stack at endpoint, signing requests with the given
+// credentials.
+func NewMinIOS3Backend(endpoint, accessKey, secretKey string) (S3Backend, error) {
+	parsed, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid MinIO endpoint %q", endpoint)
+	}
+	return &minioS3Backend{
+		endpoint:  parsed,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		region:    "us-east-1",
+		client:    &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+type minioS3Backend struct {
+	endpoint  *url.URL
+	accessKey string
+	secretKey string
+	region    string
+	client    *http.Client
+}
+
+type minioRequest struct {
+	method   string
+	bucket   string
+	key      string
+	query    url.Values
+	header   http.Header
+	body     io.Reader
+	size     int64
+	notFound *S3Error
+}
+
+type s3ListAllMyBucketsResult struct {
+	Buckets []struct {
+		Name string `xml:"Name"`
+	} `xml:"Buckets>Bucket"`
+}
+
+type s3InitiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type s3CompleteMultipartUpload struct {
+	XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+	Parts   []s3CompletedPart `xml:"Part"`
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type s3CompleteMultipartUploadResult struct {
+	ETag string `xml:"ETag"`
+}
+
+type s3ErrorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (b *minioS3Backend) CreateBucket(ctx context.Context, bucket string) error {
+	err := b.discard(b.do(ctx, minioRequest{method: http.MethodPut, bucket: bucket}))
+	if s3Err, ok := err.(*S3Error); ok && s3Err.Code == "BucketAlreadyExists" {
+		return ErrS3BucketExists
+	}
+	return err
+}
+
+func (b *minioS3Backend) HeadBucket(ctx context.Context, bucket string) error {
+	return b.discard(b.do(ctx, minioRequest{method: http.MethodHead, bucket: bucket, notFound: ErrS3NoSuchBucket}))
+}
+
+func (b *minioS3Backend) DeleteBucket(ctx context.Context, bucket string) error {
+	return b.discard(b.do(ctx, minioRequest{method: http.MethodDelete, bucket: bucket}))
+}
+
+func (b *minioS3Backend) PutBucketTags(ctx context.Context, bucket string, tags map[string]string) error {
+	var body bytes.Buffer
+	writeS3TagsXML(&body, tags)
+	sum := md5.Sum(body.Bytes())
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	return b.discard(b.do(ctx, minioRequest{
+		method: http.MethodPut, bucket: bucket, query: url.Values{"tagging": {""}},
+		header: header, body: &body, size: int64(body.Len()),
+	}))
+}
+
+func (b *minioS3Backend) BucketTags(ctx context.Context, bucket string) (map[string]string, error) {
+	resp, err := b.do(ctx, minioRequest{method: http.MethodGet, bucket: bucket, query: url.Values{"tagging": {""}}})
+	if s3Err, ok := err.(*S3Error); ok && s3Err.Code == "NoSuchTagSet" {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var tagging s3Tagging
+	if err := xml.NewDecoder(resp.Body).Decode(&tagging); err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	for _, tag := range tagging.TagSet {
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+func (b *minioS3Backend) PutObject(ctx context.Context, bucket string, info S3ObjectInfo, body io.Reader) (S3ObjectInfo, error) {
+	size := info.Size
+	if size < 0 {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return S3ObjectInfo{}, err
+		}
+		body, size = bytes.NewReader(data), int64(len(data))
+	}
+	header := http.Header{}
+	setS3ObjectHeaders(header, info)
+	resp, err := b.do(ctx, minioRequest{method: http.MethodPut, bucket: bucket, key: info.Key, header: header, body: body, size: size})
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	resp.Body.Close()
+	info.Size = size
+	info.ETag = resp.Header.Get("ETag")
+	info.LastModified = time.Now().UTC()
+	return info, nil
+}
+
+func (b *minioS3Backend) HeadObject(ctx context.Context, bucket, key string) (S3ObjectInfo, error) {
+	resp, err := b.do(ctx, minioRequest{method: http.MethodHead, bucket: bucket, key: key, notFound: ErrS3NoSuchKey})
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	resp.Body.Close()
+	return s3ObjectInfoFromHeaders(key, resp.Header), nil
+}
+
+func (b *minioS3Backend) GetObject(ctx context.Context, bucket, key string, offset, length int64) (S3ObjectInfo, io.ReadCloser, error) {
+	header := http.Header{}
+	if length >= 0 {
+		if length == 0 {
+			info, err := b.HeadObject(ctx, bucket, key)
+			return info, io.NopCloser(strings.NewReader("")), err
+		}
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else if offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := b.do(ctx, minioRequest{method: http.MethodGet, bucket: bucket, key: key, header: header, notFound: ErrS3NoSuchKey})
+	if err != nil {
+		return S3ObjectInfo{}, nil, err
+	}
+	info := s3ObjectInfoFromHeaders(key, resp.Header)
+	if contentRange := resp.Header.Get("Content-Range"); contentRange != "" {
+		if i := strings.LastIndex(contentRange, "/"); i >= 0 {
+			info.Size, _ = strconv.ParseInt(contentRange[i+1:], 10, 64)
+		}
+	}
+	return info, resp.Body, nil
+}
+
+func (b *minioS3Backend) DeleteObject(ctx context.Context, bucket, key string) error {
+	return b.discard(b.do(ctx, minioRequest{method: http.MethodDelete, bucket: bucket, key: key}))
+}
+
+func (b *minioS3Backend) ListObjects(ctx context.Context, bucket string, opts S3ListOptions) (S3ListPage, error) {
+	query := url.Values{"list-type": {"2"}, "max-keys": {strconv.Itoa(opts.MaxKeys)}}
+	for name, value := range map[string]string{
+		"prefix":             opts.Prefix,
+		"delimiter":          opts.Delimiter,
+		"start-after":        opts.StartAfter,
+		"continuation-token": opts.ContinuationToken,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	resp, err := b.do(ctx, minioRequest{method: http.MethodGet, bucket: bucket, query: query})
+	if err != nil {
+		return S3ListPage{}, err
+	}
+	defer resp.Body.Close()
+	var result s3ListBucketResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return S3ListPage{}, err
+	}
+	page := S3ListPage{IsTruncated: result.IsTruncated, NextContinuationToken: result.NextContinuationToken}
+	for _, object := range result.Contents {
+		modified, _ := time.Parse(time.RFC3339Nano, object.LastModified)
+		page.Objects = append(page.Objects, S3ObjectInfo{Key: object.Key, Size: object.Size, ETag: object.ETag, LastModified: modified})
+	}
+	for _, prefix := range result.CommonPrefixes {
+		page.CommonPrefixes = append(page.CommonPrefixes, prefix.Prefix)
+	}
+	return page, nil
+}
+
+func (b *minioS3Backend) ObjectCount(ctx context.Context) (int, error) {
+	resp, err := b.do(ctx, minioRequest{method: http.MethodGet})
+	if err != nil {
+		return 0, err
+	}
+	var buckets s3ListAllMyBucketsResult
+	err = xml.NewDecoder(resp.Body).Decode(&buckets)
+	resp.Body.Close()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, bucket := range buckets.Buckets {
+		opts := S3ListOptions{MaxKeys: 1000}
+		for {
+			page, err := b.ListObjects(ctx, bucket.Name, opts)
+			if err != nil {
+				return 0, err
+			}
+			count += len(page.Objects)
+			if !page.IsTruncated {
+				break
+			}
+			opts.ContinuationToken = page.NextContinuationToken
+		}
+	}
+	return count, nil
+}
+
+func (b *minioS3Backend) CreateMultipartUpload(ctx context.Context, bucket string, info S3ObjectInfo) (string, error) {
+	header := http.Header{}
+	setS3ObjectHeaders(header, info)
+	resp, err := b.do(ctx, minioRequest{method: http.MethodPost, bucket: bucket, key: info.Key, query: url.Values{"uploads": {""}}, header: header})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var result s3InitiateMultipartUploadResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	return result.UploadID, nil
+}
+
+func (b *minioS3Backend) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body io.Reader, size int64) (string, error) {
+	if size < 0 {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return "", err
+		}
+		body, size = bytes.NewReader(data), int64(len(data))
+	}
+	query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+	resp, err := b.do(ctx, minioRequest{method: http.MethodPut, bucket: bucket, key: key, query: query, body: body, size: size})
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (b *minioS3Backend) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []S3CompletedPart) (S3ObjectInfo, error) {
+	request := s3CompleteMultipartUpload{}
+	for _, part := range parts {
+		request.Parts = append(request.Parts, s3CompletedPart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+	data, err := xml.Marshal(request)
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	resp, err := b.do(ctx, minioRequest{
+		method: http.MethodPost, bucket: bucket, key: key, query: url.Values{"uploadId": {uploadID}},
+		body: bytes.NewReader(data), size: int64(len(data)),
+	})
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	defer resp.Body.Close()
+	// CompleteMultipartUpload can fail after a 200 status; the error is
+	// then in the body.
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return S3ObjectInfo{}, err
+	}
+	if s3Err := decodeS3Error(resp.StatusCode, body); s3Err != nil {
+		return S3ObjectInfo{}, s3Err
+	}
+	return b.HeadObject(ctx, bucket, key)
+}
+
+func (b *minioS3Backend) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	return b.discard(b.do(ctx, minioRequest{method: http.MethodDelete, bucket: bucket, key: key, query: url.Values{"uploadId": {uploadID}}}))
+}
+
+// do sends a signed path-style request and turns error responses into
+// S3Errors. HEAD errors have no body; they map to notFound for 404s.
+func (b *minioS3Backend) do(ctx context.Context, r minioRequest) (*http.Response, error) {
+	target := *b.endpoint
+	target.Path = strings.TrimRight(target.Path, "/") + "/"
+	if r.bucket != "" {
+		target.Path += r.bucket
+		if r.key != "" {
+			target.Path += "/" + r.key
+		}
+	}
+	target.RawPath = sigV4EscapePath(target.Path)
+	target.RawQuery = sigV4CanonicalQuery(r.query)
+
+	req, err := http.NewRequestWithContext(ctx, r.method, target.String(), r.body)
+	if err != nil {
+		return nil, err
+	}
+	if r.body != nil {
+		req.ContentLength = r.size
+	}
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	signSigV4(req, b.accessKey, b.secretKey, b.region, "s3", sigV4UnsignedSHA, time.Now())
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("minio: %w", err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && r.method == http.MethodHead && r.notFound != nil {
+		return nil, r.notFound
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if s3Err := decodeS3Error(resp.StatusCode, body); s3Err != nil {
+		return nil, s3Err
+	}
+	return nil, &S3Error{Status: resp.StatusCode, Code: "InternalError", Message: "minio: " + resp.Status}
+}
+
+func (b *minioS3Backend) discard(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// decodeS3Error returns the S3 error in body, if any.
+func decodeS3Error(status int, body []byte) *S3Error {
+	var decoded s3ErrorResponse
+	if xml.Unmarshal(body, &decoded) != nil || decoded.Code == "" {
+		return nil
+	}
+	if status < 300 {
+		status = http.StatusInternalServerError
+	}
+	return &S3Error{Status: status, Code: decoded.Code, Message: decoded.Message}
+}
@@ -0,0 +1,134 @@
+package aws
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat  = "20060102T150405Z"
+	sigV4DateFormat  = "20060102"
+	sigV4UnsignedSHA = "UNSIGNED-PAYLOAD"
+	sigV4EmptySHA    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// signSigV4 adds Signature Version 4 headers to req. payloadHash is the hex
+// SHA-256 of the body or UNSIGNED-PAYLOAD.
+func signSigV4(req *http.Request, accessKey, secretKey, region, service, payloadHash string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format(sigV4TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if req.Host == "" {
+		req.Host = req.URL.Host
+	}
+
+	var signed []string
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "content-md5" {
+			signed = append(signed, lower)
+		}
+	}
+	signed = append(signed, "host")
+	if req.ContentLength > 0 {
+		signed = append(signed, "content-length")
+	}
+	sort.Strings(signed)
+
+	scope := now.Format(sigV4DateFormat) + "/" + region + "/" + service + "/aws4_request"
+	canonical := sigV4CanonicalRequest(req, sigV4EscapePath(req.URL.Path), req.URL.Query(), signed, payloadHash)
+	signature := sigV4Signature(secretKey, now, region, service, sigV4StringToSign(now, scope, canonical))
+	req.Header.Set("Authorization", sigV4Algorithm+" Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+strings.Join(signed, ";")+", Signature="+signature)
+}
+
+// sigV4CanonicalRequest builds the canonical request over the given
+// lower-case header names. uri must already be escaped.
+func sigV4CanonicalRequest(req *http.Request, uri string, query url.Values, signedHeaders []string, payloadHash string) string {
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		value := strings.Join(req.Header.Values(name), ",")
+		switch {
+		case name == "host":
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		case name == "content-length" && value == "":
+			value = strconv.FormatInt(req.ContentLength, 10)
+		}
+		headers.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+	return strings.Join([]string{
+		req.Method,
+		uri,
+		sigV4CanonicalQuery(query),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+func sigV4CanonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, sigV4Escape(key, true)+"="+sigV4Escape(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func sigV4StringToSign(now time.Time, scope, canonicalRequest string) string {
+	sum := sha256.Sum256([]byte(canonicalRequest))
+	return sigV4Algorithm + "\n" + now.UTC().Format(sigV4TimeFormat) + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+}
+
+func sigV4Signature(secretKey string, now time.Time, region, service, stringToSign string) string {
+	key := hmacSHA256([]byte("AWS4"+secretKey), now.UTC().Format(sigV4DateFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sigV4EscapePath URI-encodes every path byte except unreserved characters
+// and slashes, as S3 expects.
+func sigV4EscapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return sigV4Escape(path, false)
+}
+
+func sigV4Escape(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			out.WriteByte(c)
+			continue
+		}
+		out.WriteByte('%')
+		out.WriteByte(hexDigits[c>>4])
+		out.WriteByte(hexDigits[c&15])
+	}
+	return out.String()
+}
@@ -0,0 +1,339 @@
+package aws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4MaxSkew    = 15 * time.Minute
+	sigV4MaxExpires = 7 * 24 * 60 * 60
+
+	// sigV4MaxHashedBody bounds the bodies read into memory to check their
+	// payload hash. Larger uploads have to be streamed.
+	sigV4MaxHashedBody = 64 << 20
+	// sigV4MaxChunkSize bounds a chunk of a streaming upload, which is held in
+	// memory until its signature is checked.
+	sigV4MaxChunkSize = 16 << 20
+
+	sigV4StreamingPayload         = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	sigV4StreamingUnsignedTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	sigV4ChunkAlgorithm           = "AWS4-HMAC-SHA256-PAYLOAD"
+)
+
+// SigV4Error is an authentication failure with its AWS error code.
+type SigV4Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *SigV4Error) Error() string { return e.Code + ": " + e.Message }
+
+func sigV4Mismatch(message string) *SigV4Error {
+	if message == "" {
+		message = "The request signature we calculated does not match the signature you provided. Check your key and signing method."
+	}
+	return &SigV4Error{Status: http.StatusForbidden, Code: "SignatureDoesNotMatch", Message: message}
+}
+
+type accessKeyContextKey struct{}
+
+// AccessKeyFromContext returns the access key a request was verified with.
+func AccessKeyFromContext(ctx context.Context) (AccessKey, bool) {
+	key, ok := ctx.Value(accessKeyContextKey{}).(AccessKey)
+	return key, ok
+}
+
+// SigV4Verifier authenticates Signature Version 4 requests, in both the
+// Authorization header and the presigned query form, against Homeport
+// access keys.
+type SigV4Verifier struct {
+	keys AccessKeyStore
+	now  func() time.Time
+}
+
+type SigV4VerifierOption func(*SigV4Verifier)
+
+func WithSigV4Clock(now func() time.Time) SigV4VerifierOption {
+	return func(v *SigV4Verifier) { v.now = now }
+}
+
+func NewSigV4Verifier(keys AccessKeyStore, opts ...SigV4VerifierOption) *SigV4Verifier {
+	v := &SigV4Verifier{keys: keys, now: time.Now}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Handler only lets verified requests through to next, with the access key
+// in their context. service picks the error format, S3 XML for "s3" and
+// otherwise whatever the request's protocol uses.
+func (v *SigV4Verifier) Handler(service string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, err := v.Verify(r)
+		if err != nil {
+			var sigErr *SigV4Error
+			if !errors.As(err, &sigErr) {
+				sigErr = &SigV4Error{Status: http.StatusInternalServerError, Code: "InternalFailure", Message: err.Error()}
+			}
+			writeSigV4Error(w, r, service, sigErr)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), accessKeyContextKey{}, key)))
+	})
+}
+
+// Verify checks the signature of r and returns the access key that signed
+// it. Failures are *SigV4Error unless the key store itself fails. Unless the
+// payload is unsigned or streamed, the body is read, hashed and replaced. A
+// streamed body is replaced by one that checks each chunk's signature as it
+// is read.
+func (v *SigV4Verifier) Verify(r *http.Request) (AccessKey, error) {
+	query := r.URL.Query()
+	presigned := query.Get("X-Amz-Algorithm") != ""
+
+	var algorithm, credential, signedHeaders, signature, amzDate string
+	if presigned {
+		algorithm = query.Get("X-Amz-Algorithm")
+		credential = query.Get("X-Amz-Credential")
+		signedHeaders = query.Get("X-Amz-SignedHeaders")
+		signature = query.Get("X-Amz-Signature")
+		amzDate = query.Get("X-Amz-Date")
+		query.Del("X-Amz-Signature")
+	} else {
+		auth := r.Header.Get("Authorization")
+		if auth == "" {
+			return AccessKey{}, &SigV4Error{Status: http.StatusForbidden, Code: "MissingAuthenticationToken", Message: "Request is missing Authentication Token"}
+		}
+		var fields string
+		algorithm, fields, _ = strings.Cut(auth, " ")
+		for _, field := range strings.Split(fields, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+			switch name {
+			case "Credential":
+				credential = value
+			case "SignedHeaders":
+				signedHeaders = value
+			case "Signature":
+				signature = value
+			}
+		}
+		amzDate = r.Header.Get("X-Amz-Date")
+		if amzDate == "" {
+			if date, err := http.ParseTime(r.Header.Get("Date")); err == nil {
+				amzDate = date.UTC().Format(sigV4TimeFormat)
+			}
+		}
+	}
+	if algorithm != sigV4Algorithm {
+		return AccessKey{}, sigV4Mismatch("Unsupported signing algorithm " + strconv.Quote(algorithm))
+	}
+	if credential == "" || signedHeaders == "" || signature == "" {
+		return AccessKey{}, sigV4Mismatch("Authorization is missing Credential, SignedHeaders or Signature")
+	}
+
+	scope := strings.Split(credential, "/")
+	if len(scope) != 5 || scope[4] != "aws4_request" {
+		return AccessKey{}, sigV4Mismatch("Credential must have the form <key>/<date>/<region>/<service>/aws4_request")
+	}
+	accessKeyID, region, service := scope[0], scope[2], scope[3]
+	key, err := v.keys.LookupAccessKey(accessKeyID)
+	if errors.Is(err, ErrAccessKeyNotFound) {
+		return AccessKey{}, &SigV4Error{Status: http.StatusForbidden, Code: "InvalidClientTokenId", Message: "The security token included in the request is invalid."}
+	}
+	if err != nil {
+		return AccessKey{}, err
+	}
+
+	signedAt, err := time.Parse(sigV4TimeFormat, amzDate)
+	if err != nil {
+		return AccessKey{}, sigV4Mismatch("X-Amz-Date is missing or malformed")
+	}
+	if scope[1] != signedAt.Format(sigV4DateFormat) {
+		return AccessKey{}, sigV4Mismatch("Credential date does not match X-Amz-Date")
+	}
+	now := v.now().UTC()
+	if signedAt.Sub(now) > sigV4MaxSkew || (!presigned && now.Sub(signedAt) > sigV4MaxSkew) {
+		return AccessKey{}, &SigV4Error{Status: http.StatusForbidden, Code: "RequestTimeTooSkewed", Message: "The difference between the request time and the current time is too large."}
+	}
+	if presigned {
+		expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if err != nil || expires < 1 || expires > sigV4MaxExpires {
+			return AccessKey{}, sigV4Mismatch("X-Amz-Expires must be between 1 and 604800 seconds")
+		}
+		if now.After(signedAt.Add(time.Duration(expires) * time.Second)) {
+			return AccessKey{}, &SigV4Error{Status: http.StatusForbidden, Code: "AccessDenied", Message: "Request has expired"}
+		}
+	}
+
+	headers := strings.Split(signedHeaders, ";")
+	hasHost := false
+	for _, name := range headers {
+		hasHost = hasHost || name == "host"
+	}
+	if !hasHost {
+		return AccessKey{}, sigV4Mismatch("SignedHeaders must include host")
+	}
+
+	payloadHash, err := sigV4PayloadHash(r, query, presigned && service == "s3")
+	if err != nil {
+		return AccessKey{}, err
+	}
+	// S3 signs the path as sent; every other service escapes it once more.
+	uri := sigV4EscapePath(r.URL.Path)
+	if service != "s3" {
+		uri = sigV4EscapePath(r.URL.EscapedPath())
+	}
+	canonical := sigV4CanonicalRequest(r, uri, query, headers, payloadHash)
+	stringToSign := sigV4StringToSign(signedAt, strings.Join(scope[1:], "/"), canonical)
+	expected := sigV4Signature(key.SecretAccessKey, signedAt, region, service, stringToSign)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return AccessKey{}, sigV4Mismatch("")
+	}
+	if payloadHash == sigV4StreamingPayload {
+		chunkScope := signedAt.Format(sigV4TimeFormat) + "\n" + strings.Join(scope[1:], "/") + "\n"
+		r.Body = &sigV4ChunkVerifier{
+			body:     r.Body,
+			r:        bufio.NewReader(r.Body),
+			previous: signature,
+			sign: func(previous string, data []byte) string {
+				sum := sha256.Sum256(data)
+				stringToSign := sigV4ChunkAlgorithm + "\n" + chunkScope + previous + "\n" + sigV4EmptySHA + "\n" + hex.EncodeToString(sum[:])
+				return sigV4Signature(key.SecretAccessKey, signedAt, region, service, stringToSign)
+			},
+		}
+	}
+	return key, nil
+}
+
+// sigV4PayloadHash returns the signed payload hash. Presigned S3 URLs leave
+// the payload unsigned unless they say otherwise. A hash the client claims is
+// checked against the body, so a captured signature cannot carry another
+// body; only unsigned payloads skip the check. Signed streaming payloads are
+// checked chunk by chunk once the request signature is known to match.
+func sigV4PayloadHash(r *http.Request, query url.Values, presignedS3 bool) (string, error) {
+	claimed := r.Header.Get("X-Amz-Content-Sha256")
+	if claimed == "" && presignedS3 {
+		if claimed = query.Get("X-Amz-Content-Sha256"); claimed == "" {
+			return sigV4UnsignedSHA, nil
+		}
+	}
+	switch {
+	case claimed == sigV4UnsignedSHA || claimed == sigV4StreamingUnsignedTrailer || claimed == sigV4StreamingPayload:
+		return claimed, nil
+	case strings.HasPrefix(claimed, "STREAMING-"):
+		return "", &SigV4Error{Status: http.StatusNotImplemented, Code: "NotImplemented", Message: "Payload signing " + strconv.Quote(claimed) + " is not supported."}
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(http.MaxBytesReader(nil, r.Body, sigV4MaxHashedBody)); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return "", &SigV4Error{Status: http.StatusRequestEntityTooLarge, Code: "EntityTooLarge", Message: "Bodies with a signed payload hash are limited to 64 MiB; stream larger uploads."}
+			}
+			return "", err
+		}
+		r.Body.Close()
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	actual := hex.EncodeToString(sum[:])
+	if claimed != "" && claimed != actual {
+		return "", &SigV4Error{Status: http.StatusBadRequest, Code: "XAmzContentSHA256Mismatch", Message: "The provided 'x-amz-content-sha256' header does not match what was computed."}
+	}
+	return actual, nil
+}
+
+// sigV4ChunkVerifier passes an aws-chunked body through unchanged while
+// checking the signature chain of its chunks, which starts at the request's
+// seed signature. A chunk is only returned once its signature matches, so a
+// tampered chunk fails the read before any of its data is used.
+type sigV4ChunkVerifier struct {
+	body     io.Closer
+	r        *bufio.Reader
+	sign     func(previous string, data []byte) string
+	previous string
+	pending  []byte
+	done     bool
+}
+
+func (c *sigV4ChunkVerifier) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// next reads and checks the next chunk into pending.
+func (c *sigV4ChunkVerifier) next() error {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	sizeField, params, _ := strings.Cut(strings.TrimRight(line, "\r\n"), ";")
+	size, err := strconv.ParseInt(sizeField, 16, 64)
+	if err != nil || size < 0 || size > sigV4MaxChunkSize {
+		return fmt.Errorf("invalid aws-chunked chunk size %q", sizeField)
+	}
+	signature, ok := strings.CutPrefix(params, "chunk-signature=")
+	if !ok {
+		return sigV4Mismatch("Chunk is missing its signature")
+	}
+
+	// The chunk data is followed by CRLF, which is passed through with it.
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	if !bytes.HasSuffix(data, []byte("\r\n")) {
+		return fmt.Errorf("aws-chunked chunk is not terminated by CRLF")
+	}
+	if !hmac.Equal([]byte(c.sign(c.previous, data[:size])), []byte(signature)) {
+		return sigV4Mismatch("The chunk signature does not match")
+	}
+	c.previous = signature
+	c.pending = append([]byte(line), data...)
+	c.done = size == 0
+	return nil
+}
+
+func (c *sigV4ChunkVerifier) Close() error {
+	return c.body.Close()
+}
+
+func writeSigV4Error(w http.ResponseWriter, r *http.Request, service string, err *SigV4Error) {
+	switch {
+	case service == "s3":
+		writeS3Error(w, err.Status, err.Code, xmlEscape(err.Message))
+	case r.Header.Get("X-Amz-Target") != "":
+		writeJSON(w, err.Status, map[string]string{"__type": err.Code, "message": err.Message})
+	case strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") || r.URL.Query().Get("Action") != "":
+		writeQueryErrorCode(w, err.Status, err.Code, err.Message)
+	default:
+		w.Header().Set("X-Amzn-Errortype", err.Code)
+		writeJSON(w, err.Status, map[string]string{"__type": err.Code, "message": err.Message})
+	}
+}
@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigV4VerifierChecksClaimedPayloadHash(t *testing.T) {
+	keys := StaticAccessKeys{"HPKATEST": {SecretAccessKey: "secret", Workspace: "shop", Principal: "orders"}}
+	verifier := NewSigV4Verifier(keys)
+
+	signed := func(body, payloadHash string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "http://homeport.test/invoices/report.txt", strings.NewReader(body))
+		signSigV4(req, "HPKATEST", "secret", "us-east-1", "s3", payloadHash, time.Now())
+		return req
+	}
+	sum := sha256.Sum256([]byte("original"))
+	hash := hex.EncodeToString(sum[:])
+
+	if _, err := verifier.Verify(signed("original", hash)); err != nil {
+		t.Fatalf("Verify(matching body) error = %v", err)
+	}
+
+	// A replayed signature with a different body must not pass.
+	replayed := signed("original", hash)
+	replayed.Body = io.NopCloser(strings.NewReader("tampered"))
+	var sigErr *SigV4Error
+	if _, err := verifier.Verify(replayed); !errors.As(err, &sigErr) || sigErr.Code != "XAmzContentSHA256Mismatch" {
+		t.Fatalf("Verify(tampered body) error = %v, want XAmzContentSHA256Mismatch", err)
+	}
+
+	if _, err := verifier.Verify(signed("anything", sigV4UnsignedSHA)); err != nil {
+		t.Fatalf("Verify(UNSIGNED-PAYLOAD) error = %v", err)
+	}
+	if _, err := verifier.Verify(signed("chunked", "STREAMING-AWS4-HMAC-SHA256-PAYLOAD")); err != nil {
+		t.Fatalf("Verify(streaming) error = %v", err)
+	}
+}
+
+func TestSigV4VerifierChecksStreamingChunkSignatures(t *testing.T) {
+	keys := StaticAccessKeys{"HPKATEST": {SecretAccessKey: "secret", Workspace: "shop", Principal: "orders"}}
+	verifier := NewSigV4Verifier(keys)
+	now := time.Now().UTC()
+
+	// streamed signs each chunk with the signature of the one before it,
+	// starting at the request's seed signature.
+	streamed := func(chunks ...string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "http://homeport.test/invoices/report.txt", nil)
+		signSigV4(req, "HPKATEST", "secret", "us-east-1", "s3", sigV4StreamingPayload, now)
+		_, previous, _ := strings.Cut(req.Header.Get("Authorization"), "Signature=")
+		scope := now.Format(sigV4DateFormat) + "/us-east-1/s3/aws4_request"
+		var body strings.Builder
+		for _, chunk := range append(chunks, "") {
+			sum := sha256.Sum256([]byte(chunk))
+			stringToSign := sigV4ChunkAlgorithm + "\n" + now.Format(sigV4TimeFormat) + "\n" + scope + "\n" + previous + "\n" + sigV4EmptySHA + "\n" + hex.EncodeToString(sum[:])
+			previous = sigV4Signature("secret", now, "us-east-1", "s3", stringToSign)
+			body.WriteString(strconv.FormatInt(int64(len(chunk)), 16) + ";chunk-signature=" + previous + "\r\n" + chunk + "\r\n")
+		}
+		req.Body = io.NopCloser(strings.NewReader(body.String()))
+		return req
+	}
+	read := func(req *http.Request) (string, error) {
+		if _, err := verifier.Verify(req); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		body, _, err := s3RequestBody(req)
+		if err != nil {
+			return "", err
+		}
+		data, err := io.ReadAll(body)
+		return string(data), err
+	}
+
+	if data, err := read(streamed("hello ", "world")); err != nil || data != "hello world" {
+		t.Fatalf("streamed body = %q, %v, want %q", data, err, "hello world")
+	}
+
+	tampered := streamed("hello ", "world")
+	raw, _ := io.ReadAll(tampered.Body)
+	tampered.Body = io.NopCloser(strings.NewReader(strings.Replace(string(raw), "world", "there", 1)))
+	var sigErr *SigV4Error
+	if _, err := read(tampered); !errors.As(err, &sigErr) || sigErr.Code != "SignatureDoesNotMatch" {
+		t.Fatalf("tampered chunk error = %v, want SignatureDoesNotMatch", err)
+	}
+
+	unsupported := httptest.NewRequest(http.MethodPut, "http://homeport.test/invoices/report.txt", strings.NewReader("data"))
+	signSigV4(unsupported, "HPKATEST", "secret", "us-east-1", "s3", "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER", now)
+	if _, err := verifier.Verify(unsupported); !errors.As(err, &sigErr) || sigErr.Code != "NotImplemented" {
+		t.Fatalf("Verify(signed trailer) error = %v, want NotImplemented", err)
+	}
+}
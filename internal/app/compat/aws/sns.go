@@ -2,6 +2,7 @@ package aws
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"html"
 	"net"
@@ -246,9 +247,7 @@ func (a *SNSAdapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 		}
-		a.deliverHTTP(topic, stringValue(body["Message"]))
-		a.nextID++
-		messageID := fmt.Sprintf("msg-%d", a.nextID)
+		messageID := a.publish(topic, stringValue(body["Message"]))
 		if dedupID != "" {
 			topic.Dedup[dedupID] = messageID
 		}
@@ -258,6 +257,83 @@ func (a *SNSAdapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// SNSTopicInfo describes a topic to in-process callers.
+type SNSTopicInfo struct {
+	ARN           string
+	Name          string
+	Attributes    map[string]string
+	Tags          map[string]string
+	Subscriptions int
+}
+
+// SNSSubscriptionInfo describes a subscription to in-process callers.
+type SNSSubscriptionInfo struct {
+	ARN      string
+	TopicARN string
+	Protocol string
+	Endpoint string
+}
+
+var ErrSNSTopicNotFound = errors.New("topic not found")
+
+// Topics returns every topic, sorted by ARN. Topics, Subscriptions and
+// Publish let in-process callers, such as the post-cutover operations area,
+// work on the topics the HTTP API serves.
+func (a *SNSAdapter) Topics() []SNSTopicInfo {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	topics := make([]SNSTopicInfo, 0, len(a.topics))
+	for arn, topic := range a.topics {
+		topics = append(topics, SNSTopicInfo{
+			ARN:           arn,
+			Name:          arn[strings.LastIndex(arn, ":")+1:],
+			Attributes:    copyStringMap(topic.Attributes),
+			Tags:          copyStringMap(topic.Tags),
+			Subscriptions: len(topic.Subscriptions),
+		})
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].ARN < topics[j].ARN })
+	return topics
+}
+
+// Subscriptions returns the subscriptions of a topic.
+func (a *SNSAdapter) Subscriptions(topicARN string) ([]SNSSubscriptionInfo, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	topic := a.topics[topicARN]
+	if topic == nil {
+		return nil, ErrSNSTopicNotFound
+	}
+	subscriptions := make([]SNSSubscriptionInfo, 0, len(topic.Subscriptions))
+	for _, sub := range topic.Subscriptions {
+		subscriptions = append(subscriptions, SNSSubscriptionInfo(sub))
+	}
+	return subscriptions, nil
+}
+
+// Publish delivers message to the subscribers of a topic and returns its
+// message ID.
+func (a *SNSAdapter) Publish(topicARN, message string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	topic := a.topics[topicARN]
+	if topic == nil {
+		return "", ErrSNSTopicNotFound
+	}
+	return a.publish(topic, message), nil
+}
+
+// publish delivers a message and assigns its ID. It must be called with mu
+// held.
+func (a *SNSAdapter) publish(topic *snsTopic, message string) string {
+	a.deliverHTTP(topic, message)
+	a.nextID++
+	return fmt.Sprintf("msg-%d", a.nextID)
+}
+
 func snsProtocolValid(protocol string) bool {
 	switch protocol {
 	case "application", "email", "email-json", "firehose", "http", "https", "lambda", "sms", "sqs":
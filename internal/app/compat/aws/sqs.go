@@ -1511,6 +1511,9 @@ func sqsARN(r *http.Request, body map[string]any) string {
 }
 
 func awsPrincipal(r *http.Request) string {
+	if key, ok := AccessKeyFromContext(r.Context()); ok {
+		return key.Principal
+	}
 	auth := r.Header.Get("Authorization")
 	if i := strings.Index(auth, "Credential="); i >= 0 {
 		credential := auth[i+len("Credential="):]
@@ -1545,6 +1548,10 @@ func awsPrincipalAttributes(r *http.Request) map[string]string {
 			attributes[name] = values[0]
 		}
 	}
+	if key, ok := AccessKeyFromContext(r.Context()); ok {
+		attributes["workspace"] = key.Workspace
+		attributes["access_key_id"] = key.AccessKeyID
+	}
 	return attributes
 }
 
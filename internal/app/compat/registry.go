@@ -28,13 +28,41 @@ func NewRegistry() *Registry {
 	return &Registry{adapters: make(map[string]Adapter)}
 }
 
-func NewDefaultRegistry() *Registry {
+// DefaultRegistryOption configures the adapters NewDefaultRegistry builds.
+type DefaultRegistryOption func(*defaultRegistryConfig)
+
+type defaultRegistryConfig struct {
+	s3       []compataws.S3Option
+	dynamodb []compataws.DynamoDBOption
+}
+
+// WithS3Options configures the S3 adapter, e.g. its storage backend.
+func WithS3Options(options ...compataws.S3Option) DefaultRegistryOption {
+	return func(config *defaultRegistryConfig) {
+		config.s3 = append(config.s3, options...)
+	}
+}
+
+// WithDynamoDBOptions configures the DynamoDB adapter, e.g. its storage
+// backend.
+func WithDynamoDBOptions(options ...compataws.DynamoDBOption) DefaultRegistryOption {
+	return func(config *defaultRegistryConfig) {
+		config.dynamodb = append(config.dynamodb, options...)
+	}
+}
+
+// NewDefaultRegistry registers every built-in adapter.
+func NewDefaultRegistry(options ...DefaultRegistryOption) *Registry {
+	var config defaultRegistryConfig
+	for _, option := range options {
+		option(&config)
+	}
 	registry := NewRegistry()
 	for _, adapter := range []Adapter{
 		compataws.NewALBAdapter(),
 		compataws.NewComprehendAdapter(),
-		compataws.NewS3Adapter(),
-		compataws.NewDynamoDBAdapter(),
+		compataws.NewS3Adapter(config.s3...),
+		compataws.NewDynamoDBAdapter(config.dynamodb...),
 		NativeAdapter("aws", "redis", map[string]string{
 			"REDIS_HOST":               "redis",
 			"REDIS_PORT":               "6379",
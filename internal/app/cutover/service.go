@@ -4,27 +4,60 @@ package cutover
 import (
 	"context"
 	"fmt"
+	"sort"
 	gosync "sync"
 	"time"
 
 	"github.com/google/uuid"
 	domaincutover "github.com/homeport/homeport/internal/domain/cutover"
 	infracutover "github.com/homeport/homeport/internal/infrastructure/cutover"
+	"github.com/homeport/homeport/internal/infrastructure/cutover/dns"
 )
 
 // Service orchestrates cutover operations.
 type Service struct {
 	orchestrator *infracutover.Orchestrator
 	plans        map[string]*CutoverExecution
+	store        *Store
+	journal      *domaincutover.FileJournal
 	mu           gosync.RWMutex
 }
 
-// CutoverExecution tracks the execution state of a cutover.
+// CutoverExecution tracks the execution state of a cutover. The plan keeps
+// the status of every step and the original values of every DNS change, so
+// a persisted execution can be resumed or rolled back by another process.
 type CutoverExecution struct {
-	Plan      *domaincutover.CutoverPlan
-	StartedAt *time.Time
-	Logs      []string
-	cancel    context.CancelFunc
+	Plan        *domaincutover.CutoverPlan `json:"plan"`
+	DNSProvider string                     `json:"dns_provider,omitempty"`
+	StartedAt   *time.Time                 `json:"started_at,omitempty"`
+	UpdatedAt   time.Time                  `json:"updated_at"`
+	Owner       *ExecutionOwner            `json:"owner,omitempty"`
+	Logs        []string                   `json:"logs"`
+	cancel      context.CancelFunc
+}
+
+// NewExecution creates an execution of plan owned by this process.
+func NewExecution(plan *domaincutover.CutoverPlan, dnsProvider string) *CutoverExecution {
+	return &CutoverExecution{
+		Plan:        plan,
+		DNSProvider: dnsProvider,
+		Owner:       currentOwner(),
+		Logs:        make([]string, 0),
+	}
+}
+
+// Claim makes this process the owner of the execution, before resuming or
+// rolling it back.
+func (e *CutoverExecution) Claim() {
+	e.Owner = currentOwner()
+}
+
+// dnsProvider returns the provider DNS changes are made with.
+func (e *CutoverExecution) dnsProvider() string {
+	if e.DNSProvider == "" {
+		return "manual"
+	}
+	return e.DNSProvider
 }
 
 // CutoverExecutionSnapshot is an immutable view of a cutover execution.
@@ -35,6 +68,8 @@ type CutoverExecutionSnapshot struct {
 }
 
 type CutoverPlanSnapshot struct {
+	ID     string
+	Name   string
 	Status domaincutover.CutoverStatus
 	Error  string
 	DryRun bool
@@ -49,23 +84,55 @@ type CutoverStepSnapshot struct {
 	Error       string
 }
 
-// NewService creates a new cutover service.
+// NewService creates a new cutover service. Only the manual DNS provider
+// is registered; others are added with RegisterDNSProvider.
 func NewService() *Service {
+	orchestrator := infracutover.NewOrchestrator()
+	orchestrator.RegisterDNSProvider("manual", dns.NewManualProvider())
 	return &Service{
-		orchestrator: infracutover.NewOrchestrator(),
+		orchestrator: orchestrator,
 		plans:        make(map[string]*CutoverExecution),
 	}
 }
 
+// RegisterDNSProvider makes a DNS provider available to cutovers created
+// with its name.
+func (s *Service) RegisterDNSProvider(name string, provider domaincutover.DNSProvider) {
+	s.orchestrator.RegisterDNSProvider(name, provider)
+}
+
+// NewServiceWithStore creates a cutover service that persists executions in
+// store and journals every DNS mutation to journal. Executions left running
+// by a process that has stopped are marked interrupted.
+func NewServiceWithStore(store *Store, journal *domaincutover.FileJournal) (*Service, error) {
+	if _, err := store.Recover(); err != nil {
+		return nil, fmt.Errorf("failed to recover cutover executions: %w", err)
+	}
+
+	executions, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewService()
+	service.store = store
+	service.journal = journal
+	for _, exec := range executions {
+		service.plans[exec.Plan.ID] = exec
+	}
+	return service, nil
+}
+
 // CreatePlanRequest contains the data needed to create a cutover plan.
 type CreatePlanRequest struct {
-	BundleID    string                       `json:"bundle_id"`
-	Name        string                       `json:"name"`
-	PreChecks   []*domaincutover.HealthCheck `json:"pre_checks"`
-	DNSChanges  []*domaincutover.DNSChange   `json:"dns_changes"`
-	PostChecks  []*domaincutover.HealthCheck `json:"post_checks"`
-	DryRun      bool                         `json:"dry_run"`
-	DNSProvider string                       `json:"dns_provider"`
+	BundleID      string                        `json:"bundle_id"`
+	Name          string                        `json:"name"`
+	PreChecks     []*domaincutover.HealthCheck  `json:"pre_checks"`
+	DNSChanges    []*domaincutover.DNSChange    `json:"dns_changes"`
+	TrafficShifts []*domaincutover.TrafficShift `json:"traffic_shifts,omitempty"`
+	PostChecks    []*domaincutover.HealthCheck  `json:"post_checks"`
+	DryRun        bool                          `json:"dry_run"`
+	DNSProvider   string                        `json:"dns_provider"`
 }
 
 // CreatePlan creates a new cutover plan.
@@ -83,6 +150,10 @@ func (s *Service) CreatePlan(req *CreatePlanRequest) (*domaincutover.CutoverPlan
 		plan.AddDNSChange(change)
 	}
 
+	for _, shift := range req.TrafficShifts {
+		plan.AddTrafficShift(shift)
+	}
+
 	for _, check := range req.PostChecks {
 		plan.AddPostCheck(check)
 	}
@@ -90,12 +161,11 @@ func (s *Service) CreatePlan(req *CreatePlanRequest) (*domaincutover.CutoverPlan
 	// Build the execution steps
 	plan.BuildSteps()
 
+	exec := NewExecution(plan, req.DNSProvider)
 	s.mu.Lock()
-	s.plans[planID] = &CutoverExecution{
-		Plan: plan,
-		Logs: make([]string, 0),
-	}
+	s.plans[planID] = exec
 	s.mu.Unlock()
+	s.save(exec)
 
 	return plan, nil
 }
@@ -115,6 +185,8 @@ func (s *Service) GetPlan(planID string) (*CutoverExecutionSnapshot, error) {
 func snapshotExecution(exec *CutoverExecution) *CutoverExecutionSnapshot {
 	snapshot := &CutoverExecutionSnapshot{
 		Plan: CutoverPlanSnapshot{
+			ID:     exec.Plan.ID,
+			Name:   exec.Plan.Name,
 			Status: exec.Plan.Status,
 			Error:  exec.Plan.Error,
 			DryRun: exec.Plan.DryRun,
@@ -172,8 +244,10 @@ func (s *Service) Execute(ctx context.Context, planID string, callback CutoverCa
 	exec.cancel = cancel
 	now := time.Now()
 	exec.StartedAt = &now
+	exec.Owner = currentOwner()
 	exec.Plan.Status = domaincutover.CutoverStatusRunning
 	s.mu.Unlock()
+	s.save(exec)
 
 	// Run cutover in background
 	go s.executePlan(ctx, exec, callback)
@@ -198,18 +272,26 @@ func (s *Service) executePlan(ctx context.Context, exec *CutoverExecution, callb
 			plan.Status = domaincutover.CutoverStatusFailed
 			plan.Error = "cancelled"
 			s.mu.Unlock()
+			s.save(exec)
 			return
 		default:
 		}
 
+		// Steps a resumed execution already completed are not run again
+		if step.Status == domaincutover.CutoverStepStatusCompleted {
+			continue
+		}
+
 		s.mu.Lock()
 		plan.CurrentStepIndex = i
 		step.Status = domaincutover.CutoverStepStatusRunning
 		now := time.Now()
 		step.StartedAt = &now
+		step.Error = ""
 		s.mu.Unlock()
 
 		addLog(fmt.Sprintf("Starting step %d: %s", i+1, step.Description))
+		s.save(exec)
 
 		if callback != nil {
 			callback(CutoverEvent{
@@ -251,7 +333,24 @@ func (s *Service) executePlan(ctx context.Context, exec *CutoverExecution, callb
 			// Find the DNS change
 			for _, change := range plan.DNSChanges {
 				if change.ID == step.ReferenceID {
-					stepErr = s.executeDNSChange(ctx, change, plan.DryRun)
+					var output string
+					output, stepErr = s.executeDNSChange(ctx, exec, change)
+					if output != "" {
+						addLog(output)
+					}
+					break
+				}
+			}
+
+		case domaincutover.CutoverStepTypeTrafficShift:
+			// Find the traffic shift
+			for _, shift := range plan.TrafficShifts {
+				if shift.ID == step.ReferenceID {
+					var output string
+					output, stepErr = s.executeTrafficShift(ctx, exec, shift)
+					if output != "" {
+						addLog(output)
+					}
 					break
 				}
 			}
@@ -282,12 +381,17 @@ func (s *Service) executePlan(ctx context.Context, exec *CutoverExecution, callb
 				})
 			}
 
-			// Check if we should rollback (only for post-checks)
+			// Check if we should rollback (only for post-checks and traffic shifts)
 			if step.Type == domaincutover.CutoverStepTypePostCheck {
 				addLog("Post-check failed, initiating rollback...")
 				s.rollback(ctx, exec, callback)
 				return
 			}
+			if step.Type == domaincutover.CutoverStepTypeTrafficShift {
+				addLog("Traffic shift gate failed, initiating rollback...")
+				s.rollback(ctx, exec, callback)
+				return
+			}
 
 			// Pre-check failure stops execution
 			if step.Type == domaincutover.CutoverStepTypePreCheck {
@@ -295,6 +399,7 @@ func (s *Service) executePlan(ctx context.Context, exec *CutoverExecution, callb
 				plan.Status = domaincutover.CutoverStatusFailed
 				plan.Error = fmt.Sprintf("Pre-check failed: %s", stepErr)
 				s.mu.Unlock()
+				s.save(exec)
 				return
 			}
 		} else {
@@ -302,6 +407,7 @@ func (s *Service) executePlan(ctx context.Context, exec *CutoverExecution, callb
 			step.Status = domaincutover.CutoverStepStatusCompleted
 			s.mu.Unlock()
 			addLog(fmt.Sprintf("Step %d completed", i+1))
+			s.save(exec)
 
 			if callback != nil {
 				callback(CutoverEvent{
@@ -344,6 +450,7 @@ func (s *Service) executePlan(ctx context.Context, exec *CutoverExecution, callb
 	s.mu.Unlock()
 
 	addLog("Cutover completed successfully!")
+	s.save(exec)
 
 	if callback != nil {
 		callback(CutoverEvent{
@@ -372,18 +479,65 @@ func (s *Service) executeHealthCheck(ctx context.Context, check *domaincutover.H
 	return nil
 }
 
-func (s *Service) executeDNSChange(ctx context.Context, change *domaincutover.DNSChange, dryRun bool) error {
-	if dryRun {
+// executeDNSChange makes a DNS change with the execution's provider. The
+// orchestrator captures the live record and checkpoints the execution before
+// the record is changed.
+func (s *Service) executeDNSChange(ctx context.Context, exec *CutoverExecution, change *domaincutover.DNSChange) (string, error) {
+	if exec.Plan.DryRun {
 		// Simulate DNS change
 		time.Sleep(300 * time.Millisecond)
+		return "", nil
+	}
+
+	return s.orchestrator.ApplyDNSChange(ctx, exec.Plan, change, s.orchestratorOptions(exec))
+}
+
+func (s *Service) executeTrafficShift(ctx context.Context, exec *CutoverExecution, shift *domaincutover.TrafficShift) (string, error) {
+	return s.orchestrator.ShiftTraffic(ctx, exec.Plan.ID, shift, s.orchestratorOptions(exec))
+}
+
+// orchestratorOptions returns the options DNS changes and traffic shifts of
+// an execution run with.
+func (s *Service) orchestratorOptions(exec *CutoverExecution) *infracutover.OrchestratorOptions {
+	opts := infracutover.DefaultOptions()
+	opts.DryRun = exec.Plan.DryRun
+	opts.DNSProvider = exec.dnsProvider()
+	opts.Journal = s.recordMutation
+	opts.Checkpoint = func(*domaincutover.CutoverPlan) error { return s.checkpoint(exec) }
+	return opts
+}
+
+// checkpoint persists an execution, if the service has a store, and fails
+// if it cannot be persisted.
+func (s *Service) checkpoint(exec *CutoverExecution) error {
+	if s.store == nil {
 		return nil
 	}
 
-	// For now, mark as applied (actual DNS provider integration would go here)
-	change.Status = domaincutover.DNSChangeStatusApplied
-	now := time.Now()
-	change.AppliedAt = &now
-	return nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.Save(exec)
+}
+
+// recordMutation appends a DNS mutation to the journal, if there is one.
+func (s *Service) recordMutation(mutation domaincutover.DNSMutation) error {
+	if s.journal == nil {
+		return nil
+	}
+	return s.journal.Record(mutation)
+}
+
+// save persists an execution, if the service has a store.
+func (s *Service) save(exec *CutoverExecution) {
+	if s.store == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.store.Save(exec); err != nil {
+		exec.Logs = append(exec.Logs, fmt.Sprintf("[%s] Failed to persist cutover state: %s", time.Now().Format("15:04:05"), err))
+	}
 }
 
 func (s *Service) rollback(ctx context.Context, exec *CutoverExecution, callback CutoverCallback) {
@@ -406,17 +560,27 @@ func (s *Service) rollback(ctx context.Context, exec *CutoverExecution, callback
 		})
 	}
 
-	// Rollback DNS changes in reverse order
+	// Send shifted traffic back before reverting DNS changes
+	if !plan.DryRun {
+		opts := s.orchestratorOptions(exec)
+		for i := len(plan.TrafficShifts) - 1; i >= 0; i-- {
+			shift := plan.TrafficShifts[i]
+			if err := s.orchestrator.RollbackTrafficShift(ctx, plan.ID, shift, opts); err != nil {
+				addLog(fmt.Sprintf("Failed to roll back traffic shift: %s", err))
+			} else {
+				addLog(fmt.Sprintf("Sent traffic for %s back to the old infrastructure", shift.FullName()))
+			}
+		}
+	}
+
+	// Rollback DNS changes in reverse order, restoring the records captured
+	// before they were changed
+	opts := s.orchestratorOptions(exec)
 	for i := len(plan.DNSChanges) - 1; i >= 0; i-- {
 		change := plan.DNSChanges[i]
 		if change.IsApplied() {
 			addLog(fmt.Sprintf("Reverting DNS change for %s", change.Domain))
-			// Revert by swapping old and new values
-			oldNew := change.NewValue
-			change.NewValue = change.OldValue
-			change.OldValue = oldNew
-
-			if err := s.executeDNSChange(ctx, change, plan.DryRun); err != nil {
+			if err := s.orchestrator.RollbackDNSChange(ctx, plan, change, opts); err != nil {
 				addLog(fmt.Sprintf("Failed to revert DNS change: %s", err))
 			} else {
 				addLog(fmt.Sprintf("Reverted DNS change for %s", change.Domain))
@@ -431,6 +595,7 @@ func (s *Service) rollback(ctx context.Context, exec *CutoverExecution, callback
 	s.mu.Unlock()
 
 	addLog("Rollback completed")
+	s.save(exec)
 
 	if callback != nil {
 		callback(CutoverEvent{
@@ -457,10 +622,16 @@ func (s *Service) Cancel(planID string) error {
 	}
 	exec.Plan.Status = domaincutover.CutoverStatusFailed
 	exec.Plan.Error = "cancelled by user"
+	if s.store != nil {
+		if err := s.store.Save(exec); err != nil {
+			return fmt.Errorf("failed to persist cutover state: %w", err)
+		}
+	}
 	return nil
 }
 
-// Rollback manually triggers a rollback for a completed cutover.
+// Rollback manually triggers a rollback for a completed or interrupted
+// cutover.
 func (s *Service) Rollback(ctx context.Context, planID string, callback CutoverCallback) error {
 	s.mu.Lock()
 	exec, ok := s.plans[planID]
@@ -469,12 +640,74 @@ func (s *Service) Rollback(ctx context.Context, planID string, callback CutoverC
 		return fmt.Errorf("cutover plan not found: %s", planID)
 	}
 
-	if exec.Plan.Status != domaincutover.CutoverStatusCompleted {
+	if exec.Plan.Status != domaincutover.CutoverStatusCompleted && !exec.Plan.CanResume() {
 		s.mu.Unlock()
-		return fmt.Errorf("can only rollback completed cutovers")
+		return fmt.Errorf("can only rollback completed or interrupted cutovers")
 	}
+	exec.Claim()
 	s.mu.Unlock()
 
 	go s.rollback(ctx, exec, callback)
 	return nil
 }
+
+// Resume continues an interrupted cutover from the first step that did not
+// complete.
+func (s *Service) Resume(ctx context.Context, planID string, callback CutoverCallback) error {
+	s.mu.Lock()
+	exec, ok := s.plans[planID]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("cutover plan not found: %s", planID)
+	}
+
+	if !exec.Plan.CanResume() {
+		s.mu.Unlock()
+		return fmt.Errorf("can only resume interrupted cutovers")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	exec.cancel = cancel
+	exec.Claim()
+	exec.Plan.Status = domaincutover.CutoverStatusRunning
+	exec.Plan.Error = ""
+	exec.Logs = append(exec.Logs, fmt.Sprintf("[%s] Resuming interrupted cutover", time.Now().Format("15:04:05")))
+	s.mu.Unlock()
+	s.save(exec)
+
+	go s.executePlan(ctx, exec, callback)
+	return nil
+}
+
+// Interrupted returns the cutovers that were interrupted and can be resumed
+// or rolled back.
+func (s *Service) Interrupted() []*CutoverExecutionSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshots := make([]*CutoverExecutionSnapshot, 0)
+	for _, exec := range s.plans {
+		if exec.Plan.CanResume() {
+			snapshots = append(snapshots, snapshotExecution(exec))
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Plan.ID < snapshots[j].Plan.ID
+	})
+	return snapshots
+}
+
+// Journal returns the journaled DNS mutations of a cutover.
+func (s *Service) Journal(planID string) ([]domaincutover.DNSMutation, error) {
+	s.mu.RLock()
+	_, ok := s.plans[planID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("cutover plan not found: %s", planID)
+	}
+
+	if s.journal == nil {
+		return []domaincutover.DNSMutation{}, nil
+	}
+	return s.journal.Mutations(planID)
+}
@@ -2,12 +2,117 @@ package cutover
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	domaincutover "github.com/homeport/homeport/internal/domain/cutover"
 )
 
+// fakeProvider is a weighted DNS provider holding records by set identifier.
+type fakeProvider struct {
+	mu      sync.Mutex
+	records map[string]*domaincutover.DNSRecord
+	batches int
+}
+
+func newFakeProvider(records ...*domaincutover.DNSRecord) *fakeProvider {
+	p := &fakeProvider{records: map[string]*domaincutover.DNSRecord{}}
+	for _, record := range records {
+		p.records[record.SetIdentifier] = record
+	}
+	return p
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) ListRecords(ctx context.Context, domain string) ([]*domaincutover.DNSRecord, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	records := make([]*domaincutover.DNSRecord, 0, len(p.records))
+	for _, record := range p.records {
+		copied := *record
+		records = append(records, &copied)
+	}
+	return records, nil
+}
+
+func (p *fakeProvider) GetRecord(ctx context.Context, domain, recordID string) (*domaincutover.DNSRecord, error) {
+	return nil, fmt.Errorf("not found")
+}
+
+func (p *fakeProvider) CreateRecord(ctx context.Context, change *domaincutover.DNSChange) error {
+	return p.UpdateRecord(ctx, change)
+}
+
+func (p *fakeProvider) UpdateRecord(ctx context.Context, change *domaincutover.DNSChange) error {
+	return p.ChangeRecords(ctx, nil, []*domaincutover.DNSChange{change})
+}
+
+func (p *fakeProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.records, recordID)
+	return nil
+}
+
+func (p *fakeProvider) ValidateCredentials(ctx context.Context) error { return nil }
+
+func (p *fakeProvider) SupportsWeightedRecords() bool { return true }
+
+func (p *fakeProvider) ChangeRecords(ctx context.Context, deletes, upserts []*domaincutover.DNSChange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, change := range deletes {
+		delete(p.records, change.SetIdentifier)
+	}
+	for _, change := range upserts {
+		p.records[change.SetIdentifier] = &domaincutover.DNSRecord{
+			Domain:        change.Domain,
+			Type:          change.RecordType,
+			Name:          change.Name,
+			Value:         change.NewValue,
+			TTL:           change.TTL,
+			SetIdentifier: change.SetIdentifier,
+			RoutingWeight: change.RoutingWeight,
+		}
+	}
+	p.batches++
+	return nil
+}
+
+// record returns a copy of the record with setIdentifier, or nil.
+func (p *fakeProvider) record(setIdentifier string) *domaincutover.DNSRecord {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	record, ok := p.records[setIdentifier]
+	if !ok {
+		return nil
+	}
+	copied := *record
+	return &copied
+}
+
+// waitForEvent waits for the event of type eventType.
+func waitForEvent(t *testing.T, events <-chan CutoverEvent, eventType string) CutoverEvent {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == eventType {
+				return event
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for a %s event", eventType)
+		}
+	}
+}
+
 func TestExecuteContinuesAfterCallerContextCancelled(t *testing.T) {
 	service := NewService()
 	plan, err := service.CreatePlan(&CreatePlanRequest{
@@ -52,3 +157,187 @@ func TestExecuteContinuesAfterCallerContextCancelled(t *testing.T) {
 		}
 	}
 }
+
+func TestServiceResumesInterruptedCutover(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := domaincutover.NewCutoverPlan("resume-1", "bundle-1")
+	plan.DNSPropagationWait = 0
+	plan.AddDNSChange(domaincutover.NewDNSChange("dns-1", "example.com", "A", "@", "198.51.100.20", "203.0.113.10"))
+	plan.AddDNSChange(domaincutover.NewDNSChange("dns-2", "example.com", "A", "www", "198.51.100.20", "203.0.113.10"))
+	plan.BuildSteps()
+	plan.Status = domaincutover.CutoverStatusRunning
+	plan.Steps[0].Status = domaincutover.CutoverStepStatusCompleted
+	plan.Steps[1].Status = domaincutover.CutoverStepStatusRunning
+	exec := NewExecution(plan, "")
+	exec.Owner.Instance = "stopped"
+	if err := store.Save(exec); err != nil {
+		t.Fatal(err)
+	}
+
+	journal := domaincutover.NewFileJournal(filepath.Join(dir, "journal.jsonl"))
+	service, err := NewServiceWithStore(store, journal)
+	if err != nil {
+		t.Fatalf("NewServiceWithStore() error = %v", err)
+	}
+
+	interrupted := service.Interrupted()
+	if len(interrupted) != 1 || interrupted[0].Plan.ID != plan.ID {
+		t.Fatalf("Interrupted() returned %d cutovers, want the stored one", len(interrupted))
+	}
+
+	events := make(chan CutoverEvent, 8)
+	if err := service.Resume(context.Background(), plan.ID, func(event CutoverEvent) { events <- event }); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case event := <-events:
+			done = event.Type == "complete"
+		case <-deadline:
+			t.Fatal("timed out waiting for the resumed cutover")
+		}
+	}
+
+	saved, err := store.Get(plan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Plan.Status != domaincutover.CutoverStatusCompleted {
+		t.Errorf("status = %q, want completed", saved.Plan.Status)
+	}
+
+	// Only the interrupted step runs again
+	mutations, err := service.Journal(plan.ID)
+	if err != nil {
+		t.Fatalf("Journal() error = %v", err)
+	}
+	if len(mutations) != 2 {
+		t.Fatalf("got %d journal entries, want 2", len(mutations))
+	}
+	if mutations[0].ChangeID != "dns-2" || mutations[0].Status != domaincutover.DNSMutationStarted {
+		t.Errorf("first entry = %s %s, want dns-2 started", mutations[0].ChangeID, mutations[0].Status)
+	}
+	if mutations[1].Status != domaincutover.DNSMutationSucceeded {
+		t.Errorf("second entry status = %s, want succeeded", mutations[1].Status)
+	}
+}
+
+func TestServiceShiftsTrafficWithExecutionDNSProvider(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	shift := domaincutover.NewTrafficShift("www", "example.com", "www")
+	shift.RecordType = "A"
+	shift.TTL = 60
+	shift.NewValue = "203.0.113.10"
+	shift.OldValue = "198.51.100.20"
+	shift.Stages = []int{50, 100}
+	shift.StageDuration = 20 * time.Millisecond
+	shift.SampleInterval = 5 * time.Millisecond
+	gate := domaincutover.NewHTTPHealthCheck("gate", "Gate", healthy.URL, http.StatusOK)
+	gate.Retries = 0
+	gate.Timeout = time.Second
+	shift.GateChecks = append(shift.GateChecks, gate)
+
+	provider := newFakeProvider(&domaincutover.DNSRecord{Domain: "example.com", Type: "A", Name: "www", Value: "198.51.100.20", TTL: 300})
+	service := NewService()
+	service.RegisterDNSProvider("weighted", provider)
+
+	plan, err := service.CreatePlan(&CreatePlanRequest{
+		BundleID:      "bundle-1",
+		TrafficShifts: []*domaincutover.TrafficShift{shift},
+		DNSProvider:   "weighted",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan CutoverEvent, 16)
+	if err := service.Execute(context.Background(), plan.ID, func(event CutoverEvent) { events <- event }); err != nil {
+		t.Fatal(err)
+	}
+	if event := waitForEvent(t, events, "complete"); event.Status != "completed" {
+		exec, _ := service.GetPlan(plan.ID)
+		t.Fatalf("status = %q, logs = %v, want completed", event.Status, exec.Logs)
+	}
+
+	if provider.batches != len(shift.Stages) {
+		t.Errorf("batches = %d, want one per stage (%d)", provider.batches, len(shift.Stages))
+	}
+	if record := provider.record("homeport"); record == nil || record.RoutingWeight == nil || *record.RoutingWeight != 100 {
+		t.Errorf("new record = %+v, want weight 100", record)
+	}
+	if provider.record("") != nil {
+		t.Error("the simple record was not replaced by the weighted records")
+	}
+}
+
+func TestServiceAppliesAndRollsBackDNSChangeWithProvider(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := NewServiceWithStore(store, domaincutover.NewFileJournal(filepath.Join(dir, "journal.jsonl")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := newFakeProvider(&domaincutover.DNSRecord{Domain: "example.com", Type: "A", Name: "www", Value: "198.51.100.20", TTL: 3600})
+	service.RegisterDNSProvider("fake", provider)
+
+	// The plan does not know the old value; the live record is captured
+	plan, err := service.CreatePlan(&CreatePlanRequest{
+		BundleID:    "bundle-1",
+		DNSChanges:  []*domaincutover.DNSChange{domaincutover.NewDNSChange("dns-1", "example.com", "A", "www", "", "203.0.113.10")},
+		DNSProvider: "fake",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.DNSPropagationWait = 0
+
+	events := make(chan CutoverEvent, 16)
+	callback := func(event CutoverEvent) { events <- event }
+	if err := service.Execute(context.Background(), plan.ID, callback); err != nil {
+		t.Fatal(err)
+	}
+	if event := waitForEvent(t, events, "complete"); event.Status != "completed" {
+		t.Fatalf("status = %q, want completed", event.Status)
+	}
+	if record := provider.record(""); record == nil || record.Value != "203.0.113.10" {
+		t.Fatalf("record after cutover = %+v, want the new value", record)
+	}
+
+	saved, err := store.Get(plan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous := saved.Plan.DNSChanges[0].Previous; previous == nil || previous.Value != "198.51.100.20" {
+		t.Fatalf("persisted previous record = %+v, want the live record", previous)
+	}
+
+	if err := service.Rollback(context.Background(), plan.ID, callback); err != nil {
+		t.Fatal(err)
+	}
+	waitForEvent(t, events, "complete")
+	if record := provider.record(""); record == nil || record.Value != "198.51.100.20" || record.TTL != 3600 {
+		t.Errorf("record after rollback = %+v, want the captured record", record)
+	}
+
+	mutations, err := service.Journal(plan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mutations) != 4 {
+		t.Errorf("got %d journal entries, want started and finished for the change and its rollback", len(mutations))
+	}
+}
@@ -0,0 +1,237 @@
+package cutover
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domaincutover "github.com/homeport/homeport/internal/domain/cutover"
+	"github.com/homeport/homeport/internal/pkg/logger"
+)
+
+// errCorruptExecution is returned for execution files that cannot be parsed.
+var errCorruptExecution = errors.New("corrupt cutover execution")
+
+// Store persists cutover executions, one JSON file per execution, so the API
+// server and the CLI can share it without overwriting each other.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a new execution store.
+// If dir is empty, defaults to ~/.homeport/cutovers
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home dir: %w", err)
+		}
+		dir = filepath.Join(home, ".homeport", "cutovers")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// DefaultJournalPath returns the location of the DNS mutation journal shared
+// by cutover executions.
+func DefaultJournalPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home dir: %w", err)
+	}
+	return filepath.Join(home, ".homeport", "cutover-journal.jsonl"), nil
+}
+
+// Save persists an execution atomically.
+func (s *Store) Save(exec *CutoverExecution) error {
+	if exec.Plan == nil || exec.Plan.ID == "" {
+		return fmt.Errorf("cutover plan ID is required")
+	}
+
+	path, err := s.path(exec.Plan.ID)
+	if err != nil {
+		return err
+	}
+
+	exec.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(exec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cutover execution: %w", err)
+	}
+
+	// Write atomically via temp file
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+
+	return nil
+}
+
+// Get loads an execution by plan ID.
+func (s *Store) Get(planID string) (*CutoverExecution, error) {
+	path, err := s.path(planID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("cutover plan not found: %s", planID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cutover execution: %w", err)
+	}
+
+	var exec CutoverExecution
+	if err := json.Unmarshal(data, &exec); err != nil {
+		return nil, fmt.Errorf("%w %s: %v", errCorruptExecution, planID, err)
+	}
+	if exec.Plan == nil {
+		return nil, fmt.Errorf("%w %s: it has no plan", errCorruptExecution, planID)
+	}
+	if exec.Logs == nil {
+		exec.Logs = make([]string, 0)
+	}
+
+	return &exec, nil
+}
+
+// List loads every execution, oldest first. Files that cannot be parsed are
+// moved aside with a .corrupt suffix and skipped, so one damaged file does
+// not hide the other executions.
+func (s *Store) List() ([]*CutoverExecution, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cutover executions: %w", err)
+	}
+
+	executions := make([]*CutoverExecution, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		exec, err := s.Get(strings.TrimSuffix(name, ".json"))
+		if errors.Is(err, errCorruptExecution) {
+			s.quarantine(name, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, exec)
+	}
+
+	sort.Slice(executions, func(i, j int) bool {
+		return executions[i].Plan.CreatedAt.Before(executions[j].Plan.CreatedAt)
+	})
+	return executions, nil
+}
+
+// Recover marks running executions whose process has stopped as interrupted
+// and returns every interrupted execution.
+func (s *Store) Recover() ([]*CutoverExecution, error) {
+	executions, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	interrupted := make([]*CutoverExecution, 0)
+	for _, exec := range executions {
+		if exec.Plan.Status == domaincutover.CutoverStatusRunning && !exec.Owner.Alive() {
+			markInterrupted(exec)
+			if err := s.Save(exec); err != nil {
+				return nil, err
+			}
+		}
+		if exec.Plan.Status == domaincutover.CutoverStatusInterrupted {
+			interrupted = append(interrupted, exec)
+		}
+	}
+
+	return interrupted, nil
+}
+
+// quarantine moves a corrupt execution file aside so it is kept for
+// inspection but no longer loaded.
+func (s *Store) quarantine(name string, cause error) {
+	path := filepath.Join(s.dir, name)
+	if err := os.Rename(path, path+".corrupt"); err != nil {
+		logger.Warn("Skipping corrupt cutover execution", "file", path, "error", cause, "rename_error", err)
+		return
+	}
+	logger.Warn("Moved corrupt cutover execution aside", "file", path+".corrupt", "error", cause)
+}
+
+// path returns the file of an execution, rejecting IDs that would escape
+// the store directory.
+func (s *Store) path(planID string) (string, error) {
+	if planID == "" || planID != filepath.Base(planID) || strings.HasPrefix(planID, ".") {
+		return "", fmt.Errorf("invalid cutover plan ID: %q", planID)
+	}
+	return filepath.Join(s.dir, planID+".json"), nil
+}
+
+// markInterrupted records that the process running an execution stopped.
+// The step it was running is failed so a resume runs it again.
+func markInterrupted(exec *CutoverExecution) {
+	exec.Plan.Status = domaincutover.CutoverStatusInterrupted
+	exec.Plan.Error = "cutover was interrupted before it finished"
+	for _, step := range exec.Plan.Steps {
+		if step.Status == domaincutover.CutoverStepStatusRunning {
+			step.Status = domaincutover.CutoverStepStatusFailed
+			step.Error = "interrupted"
+		}
+	}
+	exec.Logs = append(exec.Logs, fmt.Sprintf("[%s] Cutover was interrupted; resume or roll it back", time.Now().Format("15:04:05")))
+}
+
+// processInstance tells this process apart from an earlier one that had the
+// same PID, as a restarted container's PID 1 does.
+var processInstance = uuid.New().String()
+
+// ExecutionOwner identifies the process running an execution.
+type ExecutionOwner struct {
+	PID      int    `json:"pid"`
+	Host     string `json:"host"`
+	Instance string `json:"instance"`
+}
+
+// currentOwner returns the owner record of this process.
+func currentOwner() *ExecutionOwner {
+	host, _ := os.Hostname()
+	return &ExecutionOwner{PID: os.Getpid(), Host: host, Instance: processInstance}
+}
+
+// Alive reports whether the owning process is still running. Processes on
+// other hosts are assumed to be alive.
+func (o *ExecutionOwner) Alive() bool {
+	if o == nil || o.PID <= 0 {
+		return false
+	}
+	if o.Instance == processInstance {
+		return true
+	}
+	if host, _ := os.Hostname(); o.Host != host {
+		return true
+	}
+	if o.PID == os.Getpid() {
+		return false
+	}
+	return processAlive(o.PID)
+}
@@ -0,0 +1,123 @@
+package cutover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	domaincutover "github.com/homeport/homeport/internal/domain/cutover"
+)
+
+func TestStoreSaveAndGet(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := domaincutover.NewCutoverPlan("Move", "bundle-1")
+	plan.AddDNSChange(domaincutover.NewDNSChange("dns-1", "example.com", "A", "@", "198.51.100.20", "203.0.113.10"))
+	plan.BuildSteps()
+	plan.Steps[0].Status = domaincutover.CutoverStepStatusCompleted
+
+	if err := store.Save(NewExecution(plan, "cloudflare")); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	exec, err := store.Get(plan.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if exec.DNSProvider != "cloudflare" {
+		t.Errorf("DNSProvider = %q, want cloudflare", exec.DNSProvider)
+	}
+	if got := exec.Plan.DNSChanges[0].OldValue; got != "198.51.100.20" {
+		t.Errorf("OldValue = %q, want the original record value", got)
+	}
+	if got := exec.Plan.Steps[0].Status; got != domaincutover.CutoverStepStatusCompleted {
+		t.Errorf("step status = %q, want completed", got)
+	}
+
+	if _, err := store.Get("../escape"); err == nil {
+		t.Error("expected an error for an ID outside the store")
+	}
+}
+
+func TestStoreRecoverMarksOrphanedExecutionsInterrupted(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orphaned := domaincutover.NewCutoverPlan("Orphaned", "bundle-1")
+	orphaned.AddDNSChange(domaincutover.NewDNSChange("dns-1", "example.com", "A", "@", "198.51.100.20", "203.0.113.10"))
+	orphaned.BuildSteps()
+	orphaned.Status = domaincutover.CutoverStatusRunning
+	orphaned.Steps[0].Status = domaincutover.CutoverStepStatusRunning
+	exec := NewExecution(orphaned, "")
+	// This process with a different instance stands in for a stopped one
+	exec.Owner.Instance = "stopped"
+	if err := store.Save(exec); err != nil {
+		t.Fatal(err)
+	}
+
+	running := domaincutover.NewCutoverPlan("Running", "bundle-1")
+	running.Status = domaincutover.CutoverStatusRunning
+	if err := store.Save(NewExecution(running, "")); err != nil {
+		t.Fatal(err)
+	}
+
+	interrupted, err := store.Recover()
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if len(interrupted) != 1 || interrupted[0].Plan.ID != orphaned.ID {
+		t.Fatalf("Recover() returned %d executions, want the orphaned one", len(interrupted))
+	}
+
+	saved, err := store.Get(orphaned.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Plan.Status != domaincutover.CutoverStatusInterrupted {
+		t.Errorf("status = %q, want interrupted", saved.Plan.Status)
+	}
+	if got := saved.Plan.Steps[0].Status; got != domaincutover.CutoverStepStatusFailed {
+		t.Errorf("step status = %q, want failed so a resume runs it again", got)
+	}
+
+	saved, err = store.Get(running.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Plan.Status != domaincutover.CutoverStatusRunning {
+		t.Errorf("status = %q, want running for an execution this process owns", saved.Plan.Status)
+	}
+}
+
+func TestStoreListSkipsCorruptExecutions(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := domaincutover.NewCutoverPlan("good", "bundle-1")
+	if err := store.Save(NewExecution(plan, "")); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(corrupt, []byte(`{"plan":`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	executions, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(executions) != 1 || executions[0].Plan.ID != plan.ID {
+		t.Fatalf("List() returned %d executions, want only the valid one", len(executions))
+	}
+	if _, err := os.Stat(corrupt + ".corrupt"); err != nil {
+		t.Errorf("corrupt file was not moved aside: %v", err)
+	}
+}
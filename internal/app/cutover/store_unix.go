@@ -0,0 +1,16 @@
+//go:build !windows
+
+package cutover
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether the process with pid is running. Signal 0
+// only checks that the process exists; EPERM means it belongs to another
+// user.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
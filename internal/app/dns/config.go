@@ -0,0 +1,22 @@
+package dns
+
+import (
+	"os"
+	"strings"
+)
+
+// ConfigFromEnv builds the DNS configuration from the environment:
+//
+//   - HOMEPORT_DNS_LISTEN_ADDR starts the authoritative name server on a UDP
+//     and TCP address, such as ":53" or "127.0.0.1:5353".
+//   - HOMEPORT_DNS_ALLOW_TRANSFER lists, comma-separated, the addresses and
+//     CIDR ranges of secondaries allowed to transfer zones with AXFR.
+func ConfigFromEnv() *Config {
+	cfg := &Config{
+		ListenAddr: os.Getenv("HOMEPORT_DNS_LISTEN_ADDR"),
+	}
+	if allow := os.Getenv("HOMEPORT_DNS_ALLOW_TRANSFER"); allow != "" {
+		cfg.AllowTransfer = strings.Split(allow, ",")
+	}
+	return cfg
+}
@@ -0,0 +1,360 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/homeport/homeport/internal/pkg/logger"
+	mdns "github.com/miekg/dns"
+)
+
+// maxCNAMEChain bounds how many in-zone CNAMEs an answer follows.
+const maxCNAMEChain = 8
+
+// StartServer starts the embedded authoritative name server on the
+// configured address, over UDP and TCP, until Close is called. It does
+// nothing when no address is configured.
+func (s *Service) StartServer() error {
+	if s.config.ListenAddr == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.servers) > 0 {
+		return nil
+	}
+
+	allow, err := parseTransferACL(s.config.AllowTransfer)
+	if err != nil {
+		return err
+	}
+	handler := mdns.HandlerFunc(func(w mdns.ResponseWriter, req *mdns.Msg) {
+		s.serveDNS(w, req, allow)
+	})
+
+	udpConn, err := net.ListenPacket("udp", s.config.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on udp %s: %w", s.config.ListenAddr, err)
+	}
+	// Bind TCP to the port UDP got, in case the address asked for any port
+	tcpListener, err := net.Listen("tcp", udpConn.LocalAddr().String())
+	if err != nil {
+		_ = udpConn.Close()
+		return fmt.Errorf("failed to listen on tcp %s: %w", s.config.ListenAddr, err)
+	}
+
+	s.servers = []*mdns.Server{
+		{PacketConn: udpConn, Handler: handler},
+		{Listener: tcpListener, Handler: handler},
+	}
+	for _, srv := range s.servers {
+		go func(srv *mdns.Server) {
+			if err := srv.ActivateAndServe(); err != nil {
+				logger.Error("DNS server stopped", "error", err)
+			}
+		}(srv)
+	}
+
+	logger.Info("Authoritative DNS server started", "addr", udpConn.LocalAddr().String())
+	return nil
+}
+
+// ServerAddr returns the address the name server listens on, or an empty
+// string when it is not running.
+func (s *Service) ServerAddr() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.servers) == 0 {
+		return ""
+	}
+	return s.servers[0].PacketConn.LocalAddr().String()
+}
+
+// Close stops the name server.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	servers := s.servers
+	s.servers = nil
+	s.mu.Unlock()
+
+	for _, srv := range servers {
+		if err := srv.ShutdownContext(context.Background()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTransferACL parses IP addresses and CIDR ranges.
+func parseTransferACL(entries []string) ([]*net.IPNet, error) {
+	var acl []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid transfer address: %s", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			acl = append(acl, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transfer range: %s", entry)
+		}
+		acl = append(acl, ipNet)
+	}
+	return acl, nil
+}
+
+// transferAllowed reports whether addr may transfer zones.
+func transferAllowed(acl []*net.IPNet, addr net.Addr) bool {
+	var ip net.IP
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		ip = a.IP
+	case *net.UDPAddr:
+		ip = a.IP
+	}
+	for _, ipNet := range acl {
+		if ip != nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveDNS answers a query from the managed zones.
+func (s *Service) serveDNS(w mdns.ResponseWriter, req *mdns.Msg, allow []*net.IPNet) {
+	resp := new(mdns.Msg)
+	resp.SetReply(req)
+
+	if req.Opcode != mdns.OpcodeQuery || len(req.Question) != 1 {
+		resp.SetRcode(req, mdns.RcodeNotImplemented)
+		_ = w.WriteMsg(resp)
+		return
+	}
+	q := req.Question[0]
+
+	if q.Qtype == mdns.TypeAXFR || q.Qtype == mdns.TypeIXFR {
+		s.serveTransfer(w, req, allow)
+		return
+	}
+
+	s.answer(resp, q)
+	if o := req.IsEdns0(); o != nil {
+		resp.SetEdns0(o.UDPSize(), false)
+	}
+	if _, udp := w.RemoteAddr().(*net.UDPAddr); udp {
+		size := mdns.MinMsgSize
+		if o := req.IsEdns0(); o != nil {
+			size = int(o.UDPSize())
+		}
+		resp.Truncate(size)
+	}
+	_ = w.WriteMsg(resp)
+}
+
+// serveTransfer streams a zone over TCP to allowed clients. IXFR requests
+// get the full zone, as RFC 1995 permits.
+func (s *Service) serveTransfer(w mdns.ResponseWriter, req *mdns.Msg, allow []*net.IPNet) {
+	q := req.Question[0]
+	refuse := func() {
+		resp := new(mdns.Msg)
+		resp.SetRcode(req, mdns.RcodeRefused)
+		_ = w.WriteMsg(resp)
+	}
+
+	if _, tcp := w.RemoteAddr().(*net.TCPAddr); !tcp || !transferAllowed(allow, w.RemoteAddr()) {
+		logger.Warn("DNS zone transfer refused", "zone", q.Name, "client", w.RemoteAddr().String())
+		refuse()
+		return
+	}
+
+	s.mu.RLock()
+	zone := s.findZone(q.Name)
+	var rrs []mdns.RR
+	if zone != nil && mdns.Fqdn(strings.ToLower(zone.Name)) == strings.ToLower(q.Name) {
+		rrs = s.zoneRRs(zone)
+	}
+	s.mu.RUnlock()
+	if rrs == nil {
+		resp := new(mdns.Msg)
+		resp.SetRcode(req, mdns.RcodeNotAuth)
+		_ = w.WriteMsg(resp)
+		return
+	}
+
+	ch := make(chan *mdns.Envelope)
+	tr := new(mdns.Transfer)
+	errCh := make(chan error, 1)
+	go func() { errCh <- tr.Out(w, req, ch) }()
+	for len(rrs) > 0 {
+		n := min(len(rrs), 100)
+		ch <- &mdns.Envelope{RR: rrs[:n]}
+		rrs = rrs[n:]
+	}
+	close(ch)
+	if err := <-errCh; err != nil {
+		logger.Warn("DNS zone transfer failed", "zone", q.Name, "error", err)
+	}
+	logger.Info("DNS zone transferred", "zone", q.Name, "client", w.RemoteAddr().String())
+}
+
+// answer fills resp for a question: records of the name, a CNAME chain
+// within the zone, a referral to a delegated child zone, NODATA or NXDOMAIN.
+func (s *Service) answer(resp *mdns.Msg, q mdns.Question) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	zone := s.findZone(q.Name)
+	if zone == nil {
+		resp.Rcode = mdns.RcodeRefused
+		return
+	}
+	resp.Authoritative = true
+
+	byName := make(map[string][]mdns.RR)
+	for _, record := range s.records[zone.ID] {
+		if rr, err := recordToRR(zone.Name, record); err == nil {
+			name := rr.Header().Name
+			byName[name] = append(byName[name], rr)
+		}
+	}
+	origin := strings.ToLower(mdns.Fqdn(zone.Name))
+	byName[origin] = append(byName[origin], s.zoneSOA(zone))
+
+	name := strings.ToLower(q.Name)
+	for i := 0; i <= maxCNAMEChain; i++ {
+		// Delegation below the apex
+		if ns := delegation(byName, origin, name); ns != nil {
+			resp.Authoritative = false
+			resp.Ns = append(resp.Ns, ns...)
+			return
+		}
+
+		rrs, ok := byName[name]
+		if !ok && !nameExists(byName, name) {
+			rrs, ok = wildcard(byName, origin, name)
+			if !ok {
+				if len(resp.Answer) == 0 {
+					resp.Rcode = mdns.RcodeNameError
+				}
+				resp.Ns = append(resp.Ns, s.zoneSOA(zone))
+				return
+			}
+		}
+
+		var cname mdns.RR
+		matched := false
+		for _, rr := range rrs {
+			rrType := rr.Header().Rrtype
+			if rrType == q.Qtype || q.Qtype == mdns.TypeANY {
+				resp.Answer = append(resp.Answer, rr)
+				matched = true
+			} else if rrType == mdns.TypeCNAME {
+				cname = rr
+			}
+		}
+		if matched || cname == nil {
+			if !matched {
+				resp.Ns = append(resp.Ns, s.zoneSOA(zone))
+			}
+			return
+		}
+
+		// Follow the CNAME while it stays in the zone
+		resp.Answer = append(resp.Answer, cname)
+		name = strings.ToLower(cname.(*mdns.CNAME).Target)
+		if !mdns.IsSubDomain(origin, name) {
+			return
+		}
+	}
+}
+
+// findZone returns the most specific managed zone containing name. It must
+// be called with mu held.
+func (s *Service) findZone(name string) *Zone {
+	var best *Zone
+	bestLabels := -1
+	for _, zone := range s.zones {
+		origin := mdns.Fqdn(zone.Name)
+		if !mdns.IsSubDomain(origin, name) {
+			continue
+		}
+		if labels := mdns.CountLabel(origin); labels > bestLabels {
+			best, bestLabels = zone, labels
+		}
+	}
+	return best
+}
+
+// delegation returns the NS records of the closest delegation point between
+// the apex and name, if any.
+func delegation(byName map[string][]mdns.RR, origin, name string) []mdns.RR {
+	labels := mdns.SplitDomainName(name)
+	originLabels := mdns.CountLabel(origin)
+	for i := len(labels) - originLabels - 1; i >= 0; i-- {
+		cut := mdns.Fqdn(strings.Join(labels[i:], "."))
+		var ns []mdns.RR
+		for _, rr := range byName[cut] {
+			if rr.Header().Rrtype == mdns.TypeNS {
+				ns = append(ns, rr)
+			}
+		}
+		if len(ns) > 0 {
+			return ns
+		}
+	}
+	return nil
+}
+
+// wildcard returns the records of the wildcard at the closest encloser of
+// name, renamed to name (RFC 4592).
+func wildcard(byName map[string][]mdns.RR, origin, name string) ([]mdns.RR, bool) {
+	labels := mdns.SplitDomainName(name)
+	originLabels := mdns.CountLabel(origin)
+	for i := 1; i <= len(labels)-originLabels; i++ {
+		encloser := mdns.Fqdn(strings.Join(labels[i:], "."))
+		if !nameExists(byName, encloser) {
+			continue
+		}
+		rrs, ok := byName["*."+encloser]
+		if !ok {
+			return nil, false
+		}
+		result := make([]mdns.RR, 0, len(rrs))
+		for _, rr := range rrs {
+			rr = mdns.Copy(rr)
+			rr.Header().Name = name
+			result = append(result, rr)
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// nameExists reports whether name owns records or is an empty non-terminal
+// above names that do.
+func nameExists(byName map[string][]mdns.RR, name string) bool {
+	if _, ok := byName[name]; ok {
+		return true
+	}
+	for owner := range byName {
+		if strings.HasSuffix(owner, "."+name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mdns "github.com/miekg/dns"
+)
+
+func startTestServer(t *testing.T, allowTransfer ...string) (*Service, string) {
+	t.Helper()
+	svc, err := NewService(&Config{
+		DataPath:      filepath.Join(t.TempDir(), "dns.json"),
+		ListenAddr:    "127.0.0.1:0",
+		AllowTransfer: allowTransfer,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ImportZone(context.Background(), "example.com", strings.NewReader(testZoneFile+
+		"*.apps IN A 192.0.2.50\n"+
+		"deep.empty IN A 192.0.2.60\n"+
+		"sub IN NS ns.sub.example.com.\n"+
+		"ns.sub IN A 192.0.2.70\n"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.StartServer(); err != nil {
+		t.Fatalf("StartServer() error = %v", err)
+	}
+	t.Cleanup(func() { _ = svc.Close() })
+	return svc, svc.ServerAddr()
+}
+
+func query(t *testing.T, addr, name string, qtype uint16) *mdns.Msg {
+	t.Helper()
+	msg := new(mdns.Msg)
+	msg.SetQuestion(name, qtype)
+	resp, err := mdns.Exchange(msg, addr)
+	if err != nil {
+		t.Fatalf("query %s: %v", name, err)
+	}
+	return resp
+}
+
+func TestServerAnswers(t *testing.T) {
+	_, addr := startTestServer(t)
+
+	tests := []struct {
+		name    string
+		qtype   uint16
+		rcode   int
+		answers []string // Substrings of the answer records, in order
+		aa      bool
+	}{
+		{"www.example.com.", mdns.TypeA, mdns.RcodeSuccess, []string{"192.0.2.10"}, true},
+		{"WWW.Example.COM.", mdns.TypeAAAA, mdns.RcodeSuccess, []string{"2001:db8::10"}, true},
+		{"api.example.com.", mdns.TypeA, mdns.RcodeSuccess, []string{"CNAME\twww.example.com.", "192.0.2.10"}, true},
+		{"example.com.", mdns.TypeSOA, mdns.RcodeSuccess, []string{"SOA\tns1.example.com."}, true},
+		{"x.apps.example.com.", mdns.TypeA, mdns.RcodeSuccess, []string{"x.apps.example.com.\t3600\tIN\tA\t192.0.2.50"}, true},
+		{"www.example.com.", mdns.TypeMX, mdns.RcodeSuccess, nil, true},
+		{"empty.example.com.", mdns.TypeA, mdns.RcodeSuccess, nil, true},
+		{"missing.example.com.", mdns.TypeA, mdns.RcodeNameError, nil, true},
+		{"host.sub.example.com.", mdns.TypeA, mdns.RcodeSuccess, nil, false},
+		{"example.org.", mdns.TypeA, mdns.RcodeRefused, nil, false},
+	}
+	for _, tt := range tests {
+		resp := query(t, addr, tt.name, tt.qtype)
+		if resp.Rcode != tt.rcode || resp.Authoritative != tt.aa {
+			t.Errorf("%s %s: rcode %s, aa %v", tt.name, mdns.TypeToString[tt.qtype], mdns.RcodeToString[resp.Rcode], resp.Authoritative)
+			continue
+		}
+		if len(resp.Answer) != len(tt.answers) {
+			t.Errorf("%s %s: answers %v", tt.name, mdns.TypeToString[tt.qtype], resp.Answer)
+			continue
+		}
+		for i, want := range tt.answers {
+			if !strings.Contains(resp.Answer[i].String(), want) {
+				t.Errorf("%s %s: answer %d = %s, want %s", tt.name, mdns.TypeToString[tt.qtype], i, resp.Answer[i], want)
+			}
+		}
+	}
+
+	referral := query(t, addr, "host.sub.example.com.", mdns.TypeA)
+	if len(referral.Ns) != 1 || !strings.Contains(referral.Ns[0].String(), "NS\tns.sub.example.com.") {
+		t.Errorf("referral authority = %v", referral.Ns)
+	}
+	nx := query(t, addr, "missing.example.com.", mdns.TypeA)
+	if len(nx.Ns) != 1 || nx.Ns[0].Header().Rrtype != mdns.TypeSOA {
+		t.Errorf("NXDOMAIN authority = %v", nx.Ns)
+	}
+}
+
+func TestServerZoneTransfer(t *testing.T) {
+	t.Run("allowed", func(t *testing.T) {
+		_, addr := startTestServer(t, "127.0.0.0/8")
+
+		msg := new(mdns.Msg)
+		msg.SetAxfr("example.com.")
+		envelopes, err := new(mdns.Transfer).In(msg, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rrs []mdns.RR
+		for env := range envelopes {
+			if env.Error != nil {
+				t.Fatalf("transfer error = %v", env.Error)
+			}
+			rrs = append(rrs, env.RR...)
+		}
+		// SOA, 9 zone file records, 4 extra records, SOA
+		if len(rrs) != 15 || rrs[0].Header().Rrtype != mdns.TypeSOA || rrs[14].Header().Rrtype != mdns.TypeSOA {
+			t.Errorf("transferred %d records: %v", len(rrs), rrs)
+		}
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		_, addr := startTestServer(t, "192.0.2.0/24")
+
+		msg := new(mdns.Msg)
+		msg.SetAxfr("example.com.")
+		conn, err := mdns.Dial("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = conn.Close() }()
+		if err := conn.WriteMsg(msg); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := conn.ReadMsg()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Rcode != mdns.RcodeRefused {
+			t.Errorf("rcode = %s", mdns.RcodeToString[resp.Rcode])
+		}
+	})
+}
+
+func TestParseTransferACL(t *testing.T) {
+	acl, err := parseTransferACL([]string{"192.0.2.1", " 10.0.0.0/8", "2001:db8::/32", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for addr, want := range map[string]bool{
+		"192.0.2.1":   true,
+		"192.0.2.2":   false,
+		"10.20.30.40": true,
+		"2001:db8::1": true,
+		"2001:db9::1": false,
+	} {
+		if got := transferAllowed(acl, &net.TCPAddr{IP: net.ParseIP(addr)}); got != want {
+			t.Errorf("transferAllowed(%s) = %v, want %v", addr, got, want)
+		}
+	}
+	if _, err := parseTransferACL([]string{"not-an-ip"}); err == nil {
+		t.Error("parseTransferACL() accepted an invalid entry")
+	}
+}
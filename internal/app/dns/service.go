@@ -1,13 +1,20 @@
-// Package dns provides DNS zone and record management.
+// Package dns provides DNS zone and record management, persisted to disk and
+// optionally served by an embedded authoritative name server.
 package dns
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/homeport/homeport/internal/pkg/logger"
+	mdns "github.com/miekg/dns"
 )
 
 // ZoneType represents the type of DNS zone.
@@ -86,19 +93,63 @@ type ValidationResult struct {
 	Warnings []ValidationError `json:"warnings,omitempty"`
 }
 
+// Config holds DNS service configuration.
+type Config struct {
+	DataPath string // Path for zone persistence (JSON)
+
+	// ListenAddr is the UDP and TCP address of the embedded authoritative
+	// name server, such as ":53". The server is not started when empty.
+	ListenAddr string
+
+	// AllowTransfer lists the IP addresses and CIDR ranges allowed to
+	// transfer zones with AXFR. Transfers are refused when empty.
+	AllowTransfer []string
+}
+
 // Service provides DNS zone and record management.
 type Service struct {
 	mu      sync.RWMutex
 	zones   map[string]*Zone
 	records map[string]map[string]*Record // zoneID -> recordID -> Record
+	config  *Config
+	servers []*mdns.Server
+}
+
+// storedData is the on-disk layout of the zone database.
+type storedData struct {
+	Zones   map[string]*Zone              `json:"zones"`
+	Records map[string]map[string]*Record `json:"records"`
 }
 
 // NewService creates a new DNS management service.
-func NewService() (*Service, error) {
-	return &Service{
+func NewService(cfg *Config) (*Service, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	if cfg.DataPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		cfg.DataPath = filepath.Join(home, ".homeport", "dns.json")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.DataPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create DNS data directory: %w", err)
+	}
+
+	s := &Service{
 		zones:   make(map[string]*Zone),
 		records: make(map[string]map[string]*Record),
-	}, nil
+		config:  cfg,
+	}
+
+	if err := s.loadData(); err != nil {
+		logger.Warn("Failed to load DNS zones", "error", err)
+	}
+
+	return s, nil
 }
 
 // ListZones returns all DNS zones.
@@ -173,6 +224,9 @@ func (s *Service) CreateZone(ctx context.Context, name string, zoneType ZoneType
 		UpdatedAt: now,
 	}
 	s.records[zone.ID][nsRecord.ID] = nsRecord
+	if err := s.saveData(); err != nil {
+		return nil, err
+	}
 
 	return zone, nil
 }
@@ -189,7 +243,7 @@ func (s *Service) DeleteZone(ctx context.Context, zoneID string) error {
 	delete(s.zones, zoneID)
 	delete(s.records, zoneID)
 
-	return nil
+	return s.saveData()
 }
 
 // ListRecords returns all records in a zone.
@@ -266,8 +320,11 @@ func (s *Service) CreateRecord(ctx context.Context, zoneID string, record Record
 	}
 	s.records[zoneID][newRecord.ID] = newRecord
 
-	zone.Serial = generateSerial()
+	zone.Serial = nextSerial(zone.Serial)
 	zone.UpdatedAt = now
+	if err := s.saveData(); err != nil {
+		return nil, err
+	}
 
 	return newRecord, nil
 }
@@ -302,8 +359,11 @@ func (s *Service) UpdateRecord(ctx context.Context, zoneID, recordID string, rec
 	existing.Port = record.Port
 	existing.UpdatedAt = now
 
-	zone.Serial = generateSerial()
+	zone.Serial = nextSerial(zone.Serial)
 	zone.UpdatedAt = now
+	if err := s.saveData(); err != nil {
+		return nil, err
+	}
 
 	return existing, nil
 }
@@ -329,10 +389,10 @@ func (s *Service) DeleteRecord(ctx context.Context, zoneID, recordID string) err
 
 	delete(zoneRecords, recordID)
 
-	zone.Serial = generateSerial()
+	zone.Serial = nextSerial(zone.Serial)
 	zone.UpdatedAt = time.Now()
 
-	return nil
+	return s.saveData()
 }
 
 // ValidateZone validates a DNS zone configuration.
@@ -410,3 +470,58 @@ func generateSerial() uint32 {
 	increment := uint32(now.Hour()*60+now.Minute()) % 100
 	return base + increment
 }
+
+// nextSerial returns a serial greater than current, so that secondaries
+// notice every change.
+func nextSerial(current uint32) uint32 {
+	if serial := generateSerial(); serial > current {
+		return serial
+	}
+	return current + 1
+}
+
+// ErrPersist is returned by mutating calls when the change could not be
+// written to disk. The change is applied in memory but lost on restart.
+var ErrPersist = errors.New("failed to save DNS zones")
+
+// saveData persists zones and records to the JSON file. It must be called
+// with mu held.
+func (s *Service) saveData() error {
+	data, err := json.MarshalIndent(storedData{Zones: s.zones, Records: s.records}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPersist, err)
+	}
+
+	tmp := s.config.DataPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersist, err)
+	}
+	if err := os.Rename(tmp, s.config.DataPath); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersist, err)
+	}
+	return nil
+}
+
+// loadData loads zones and records from the JSON file.
+func (s *Service) loadData() error {
+	data, err := os.ReadFile(s.config.DataPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var stored storedData
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	for id, zone := range stored.Zones {
+		s.zones[id] = zone
+		s.records[id] = stored.Records[id]
+		if s.records[id] == nil {
+			s.records[id] = make(map[string]*Record)
+		}
+	}
+	return nil
+}
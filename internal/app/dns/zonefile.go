@@ -0,0 +1,332 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	mdns "github.com/miekg/dns"
+)
+
+// SOA timers of managed zones, in seconds.
+const (
+	soaRefresh = 3600
+	soaRetry   = 600
+	soaExpire  = 604800
+	soaMinTTL  = 300
+)
+
+// ImportResult reports the outcome of a zone file import.
+type ImportResult struct {
+	Zone     *Zone    `json:"zone"`
+	Imported int      `json:"imported"`
+	Skipped  []string `json:"skipped,omitempty"` // Unsupported or invalid records
+}
+
+// ImportZone loads an RFC 1035 zone file, such as the db.<zone> files
+// generated from Route53 hosted zones. The zone is created when it does not
+// exist; otherwise its records are replaced. The SOA record only carries
+// the serial over. Records of unsupported types, and records rejected by
+// validate when it is not nil, are skipped.
+func (s *Service) ImportZone(ctx context.Context, name string, r io.Reader, validate func(Record) error) (*ImportResult, error) {
+	origin := mdns.Fqdn(strings.ToLower(name))
+	zoneName := strings.TrimSuffix(origin, ".")
+	if _, ok := mdns.IsDomainName(origin); !ok || zoneName == "" {
+		return nil, fmt.Errorf("invalid zone name: %s", name)
+	}
+
+	parser := mdns.NewZoneParser(r, origin, "")
+	parser.SetDefaultTTL(3600)
+
+	now := time.Now()
+	result := &ImportResult{}
+	records := make(map[string]*Record)
+	var serial uint32
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		if !mdns.IsSubDomain(origin, rr.Header().Name) {
+			return nil, fmt.Errorf("record %s is outside zone %s", rr.Header().Name, zoneName)
+		}
+		if soa, isSOA := rr.(*mdns.SOA); isSOA {
+			serial = soa.Serial
+			continue
+		}
+		record, ok := recordFromRR(zoneName, rr)
+		if !ok {
+			result.Skipped = append(result.Skipped, rr.String())
+			continue
+		}
+		if validate != nil {
+			if err := validate(*record); err != nil {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s (%v)", rr, err))
+				continue
+			}
+		}
+		record.ID = uuid.New().String()
+		record.CreatedAt = now
+		record.UpdatedAt = now
+		records[record.ID] = record
+	}
+	if err := parser.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse zone file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var zone *Zone
+	for _, z := range s.zones {
+		if strings.EqualFold(z.Name, zoneName) {
+			zone = z
+			break
+		}
+	}
+	if zone == nil {
+		zone = &Zone{
+			ID:        uuid.New().String(),
+			Name:      zoneName,
+			Type:      ZoneTypePrimary,
+			CreatedAt: now,
+		}
+		s.zones[zone.ID] = zone
+	}
+	for _, record := range records {
+		record.ZoneID = zone.ID
+	}
+	s.records[zone.ID] = records
+
+	zone.Serial = nextSerial(max(zone.Serial, serial))
+	zone.UpdatedAt = now
+	if err := s.saveData(); err != nil {
+		return nil, err
+	}
+
+	zoneCopy := *zone
+	zoneCopy.RecordsCount = len(records)
+	result.Zone = &zoneCopy
+	result.Imported = len(records)
+	return result, nil
+}
+
+// ExportZone renders a zone as an RFC 1035 zone file.
+func (s *Service) ExportZone(ctx context.Context, zoneID string) (string, error) {
+	rrs, err := s.TransferZone(ctx, zoneID)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s\n", rrs[0].Header().Name)
+	// Leave out the closing SOA of the transfer
+	for _, rr := range rrs[:len(rrs)-1] {
+		b.WriteString(rr.String())
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// TransferZone returns the records of a zone as an AXFR sequence: the SOA,
+// every record, then the SOA again.
+func (s *Service) TransferZone(ctx context.Context, zoneID string) ([]mdns.RR, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	zone, ok := s.zones[zoneID]
+	if !ok {
+		return nil, fmt.Errorf("zone not found: %s", zoneID)
+	}
+	return s.zoneRRs(zone), nil
+}
+
+// zoneRRs returns the AXFR sequence of a zone. It must be called with mu
+// held.
+func (s *Service) zoneRRs(zone *Zone) []mdns.RR {
+	records := make([]*Record, 0, len(s.records[zone.ID]))
+	for _, record := range s.records[zone.ID] {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := recordFQDN(zone.Name, records[i].Name), recordFQDN(zone.Name, records[j].Name)
+		if a != b {
+			return a < b
+		}
+		if records[i].Type != records[j].Type {
+			return records[i].Type < records[j].Type
+		}
+		return records[i].Value < records[j].Value
+	})
+
+	soa := s.zoneSOA(zone)
+	rrs := []mdns.RR{soa}
+	for _, record := range records {
+		if rr, err := recordToRR(zone.Name, record); err == nil {
+			rrs = append(rrs, rr)
+		}
+	}
+	return append(rrs, soa)
+}
+
+// zoneSOA builds the SOA record of a zone, naming its first apex NS record
+// as primary server. It must be called with mu held.
+func (s *Service) zoneSOA(zone *Zone) *mdns.SOA {
+	origin := mdns.Fqdn(zone.Name)
+	primary := "ns1." + origin
+	var nsNames []string
+	for _, record := range s.records[zone.ID] {
+		if record.Type == RecordTypeNS && recordFQDN(zone.Name, record.Name) == strings.ToLower(origin) {
+			nsNames = append(nsNames, record.Value)
+		}
+	}
+	if len(nsNames) > 0 {
+		sort.Strings(nsNames)
+		primary = mdns.Fqdn(nsNames[0])
+	}
+
+	return &mdns.SOA{
+		Hdr:     mdns.RR_Header{Name: origin, Rrtype: mdns.TypeSOA, Class: mdns.ClassINET, Ttl: soaMinTTL},
+		Ns:      primary,
+		Mbox:    "hostmaster." + origin,
+		Serial:  zone.Serial,
+		Refresh: soaRefresh,
+		Retry:   soaRetry,
+		Expire:  soaExpire,
+		Minttl:  soaMinTTL,
+	}
+}
+
+// recordFQDN returns the lower-case fully qualified name of a record. Names
+// are relative to the zone unless they end with a dot or with the zone
+// name, and "@" is the apex.
+func recordFQDN(zoneName, name string) string {
+	zoneName = strings.ToLower(strings.TrimSuffix(zoneName, "."))
+	name = strings.ToLower(name)
+	switch {
+	case name == "@" || name == "" || name == zoneName:
+		return zoneName + "."
+	case strings.HasSuffix(name, "."):
+		return name
+	case strings.HasSuffix(name, "."+zoneName):
+		return name + "."
+	default:
+		return name + "." + zoneName + "."
+	}
+}
+
+// relativeName returns the record name of fqdn in a zone.
+func relativeName(zoneName, fqdn string) string {
+	zoneName = strings.ToLower(strings.TrimSuffix(zoneName, "."))
+	fqdn = strings.ToLower(strings.TrimSuffix(fqdn, "."))
+	if fqdn == zoneName {
+		return "@"
+	}
+	return strings.TrimSuffix(fqdn, "."+zoneName)
+}
+
+// recordToRR converts a record to its wire representation. Host names in
+// values are absolute, with or without a trailing dot.
+func recordToRR(zoneName string, record *Record) (mdns.RR, error) {
+	hdr := mdns.RR_Header{Name: recordFQDN(zoneName, record.Name), Class: mdns.ClassINET, Ttl: record.TTL}
+	value := strings.TrimSpace(record.Value)
+
+	switch record.Type {
+	case RecordTypeA:
+		ip := net.ParseIP(value).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IPv4 address: %s", value)
+		}
+		hdr.Rrtype = mdns.TypeA
+		return &mdns.A{Hdr: hdr, A: ip}, nil
+	case RecordTypeAAAA:
+		ip := net.ParseIP(value)
+		if ip == nil || ip.To4() != nil {
+			return nil, fmt.Errorf("invalid IPv6 address: %s", value)
+		}
+		hdr.Rrtype = mdns.TypeAAAA
+		return &mdns.AAAA{Hdr: hdr, AAAA: ip}, nil
+	case RecordTypeCNAME:
+		hdr.Rrtype = mdns.TypeCNAME
+		return &mdns.CNAME{Hdr: hdr, Target: mdns.Fqdn(value)}, nil
+	case RecordTypeNS:
+		hdr.Rrtype = mdns.TypeNS
+		return &mdns.NS{Hdr: hdr, Ns: mdns.Fqdn(value)}, nil
+	case RecordTypePTR:
+		hdr.Rrtype = mdns.TypePTR
+		return &mdns.PTR{Hdr: hdr, Ptr: mdns.Fqdn(value)}, nil
+	case RecordTypeMX:
+		hdr.Rrtype = mdns.TypeMX
+		return &mdns.MX{Hdr: hdr, Preference: deref(record.Priority), Mx: mdns.Fqdn(value)}, nil
+	case RecordTypeSRV:
+		hdr.Rrtype = mdns.TypeSRV
+		return &mdns.SRV{Hdr: hdr, Priority: deref(record.Priority), Weight: deref(record.Weight), Port: deref(record.Port), Target: mdns.Fqdn(value)}, nil
+	case RecordTypeTXT:
+		hdr.Rrtype = mdns.TypeTXT
+		return &mdns.TXT{Hdr: hdr, Txt: splitTXT(value)}, nil
+	case RecordTypeCAA:
+		parts := strings.SplitN(value, " ", 3)
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid CAA record: %s", value)
+		}
+		flag, err := strconv.ParseUint(parts[0], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CAA flag: %s", parts[0])
+		}
+		hdr.Rrtype = mdns.TypeCAA
+		return &mdns.CAA{Hdr: hdr, Flag: uint8(flag), Tag: parts[1], Value: strings.Trim(parts[2], `"`)}, nil
+	}
+	return nil, fmt.Errorf("unsupported record type: %s", record.Type)
+}
+
+// recordFromRR converts a parsed resource record. It returns false for types
+// the service does not manage.
+func recordFromRR(zoneName string, rr mdns.RR) (*Record, bool) {
+	hdr := rr.Header()
+	record := &Record{Name: relativeName(zoneName, hdr.Name), TTL: hdr.Ttl}
+
+	switch v := rr.(type) {
+	case *mdns.A:
+		record.Type, record.Value = RecordTypeA, v.A.String()
+	case *mdns.AAAA:
+		record.Type, record.Value = RecordTypeAAAA, v.AAAA.String()
+	case *mdns.CNAME:
+		record.Type, record.Value = RecordTypeCNAME, strings.TrimSuffix(v.Target, ".")
+	case *mdns.NS:
+		record.Type, record.Value = RecordTypeNS, strings.TrimSuffix(v.Ns, ".")
+	case *mdns.PTR:
+		record.Type, record.Value = RecordTypePTR, strings.TrimSuffix(v.Ptr, ".")
+	case *mdns.MX:
+		record.Type, record.Value = RecordTypeMX, strings.TrimSuffix(v.Mx, ".")
+		record.Priority = &v.Preference
+	case *mdns.SRV:
+		record.Type, record.Value = RecordTypeSRV, strings.TrimSuffix(v.Target, ".")
+		record.Priority, record.Weight, record.Port = &v.Priority, &v.Weight, &v.Port
+	case *mdns.TXT:
+		record.Type, record.Value = RecordTypeTXT, strings.Join(v.Txt, "")
+	case *mdns.CAA:
+		record.Type, record.Value = RecordTypeCAA, fmt.Sprintf("%d %s %q", v.Flag, v.Tag, v.Value)
+	default:
+		return nil, false
+	}
+	return record, true
+}
+
+// splitTXT splits a TXT value into the 255-byte strings of the wire format.
+func splitTXT(value string) []string {
+	var parts []string
+	for len(value) > 255 {
+		parts = append(parts, value[:255])
+		value = value[255:]
+	}
+	return append(parts, value)
+}
+
+func deref(v *uint16) uint16 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,215 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testZoneFile = `$ORIGIN example.com.
+$TTL 3600
+@       IN SOA  ns1.example.com. hostmaster.example.com. 2026010100 3600 600 604800 300
+@       IN NS   ns1.example.com.
+@       IN MX   10 mail.example.com.
+@       IN TXT  "v=spf1 include:_spf.example.com ~all"
+@       IN CAA  0 issue "letsencrypt.org"
+www  300 IN A   192.0.2.10
+www     IN AAAA 2001:db8::10
+api     IN CNAME www
+_sip._tcp IN SRV 10 60 5060 sip.example.com.
+mail    IN A    192.0.2.25
+key     IN DNSKEY 256 3 13 oJMRESz5E4gYzS/q6XDrvU1qMPYIjCWzJaOau8XNEZeqCYKD5ar0IRd8KqXXFJkqmVfRvMGPmM1x8fGAa2XhSA==
+`
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	svc, err := NewService(&Config{DataPath: filepath.Join(t.TempDir(), "dns.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestImportExportZone(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+
+	result, err := svc.ImportZone(ctx, "example.com", strings.NewReader(testZoneFile), nil)
+	if err != nil {
+		t.Fatalf("ImportZone() error = %v", err)
+	}
+	if result.Imported != 9 || len(result.Skipped) != 1 {
+		t.Errorf("imported %d, skipped %v", result.Imported, result.Skipped)
+	}
+	if result.Zone.Serial <= 2026010100 {
+		t.Errorf("serial %d does not follow the imported one", result.Zone.Serial)
+	}
+
+	records, err := svc.ListRecords(ctx, result.Zone.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]Record)
+	for _, r := range records {
+		found[r.Name+" "+string(r.Type)] = r
+	}
+	if r := found["www A"]; r.Value != "192.0.2.10" || r.TTL != 300 {
+		t.Errorf("www A = %+v", r)
+	}
+	if r := found["api CNAME"]; r.Value != "www.example.com" {
+		t.Errorf("api CNAME = %+v", r)
+	}
+	if r := found["_sip._tcp SRV"]; r.Value != "sip.example.com" || *r.Priority != 10 || *r.Weight != 60 || *r.Port != 5060 {
+		t.Errorf("SRV = %+v", r)
+	}
+	if r := found["@ MX"]; r.Value != "mail.example.com" || *r.Priority != 10 {
+		t.Errorf("MX = %+v", r)
+	}
+	if r := found["@ TXT"]; r.Value != "v=spf1 include:_spf.example.com ~all" {
+		t.Errorf("TXT = %+v", r)
+	}
+
+	exported, err := svc.ExportZone(ctx, result.Zone.ID)
+	if err != nil {
+		t.Fatalf("ExportZone() error = %v", err)
+	}
+	for _, want := range []string{
+		"$ORIGIN example.com.",
+		"example.com.\t300\tIN\tSOA\tns1.example.com. hostmaster.example.com.",
+		"api.example.com.\t3600\tIN\tCNAME\twww.example.com.",
+		"_sip._tcp.example.com.\t3600\tIN\tSRV\t10 60 5060 sip.example.com.",
+		"example.com.\t3600\tIN\tCAA\t0 issue \"letsencrypt.org\"",
+	} {
+		if !strings.Contains(exported, want) {
+			t.Errorf("export misses %q:\n%s", want, exported)
+		}
+	}
+
+	// The export imports back to the same records
+	again, err := svc.ImportZone(ctx, "example.com.", strings.NewReader(exported), nil)
+	if err != nil {
+		t.Fatalf("re-import error = %v", err)
+	}
+	if again.Zone.ID != result.Zone.ID || again.Imported != 9 || len(again.Skipped) != 0 {
+		t.Errorf("re-import = %+v", again)
+	}
+	if again.Zone.Serial <= result.Zone.Serial {
+		t.Errorf("serial did not increase: %d -> %d", result.Zone.Serial, again.Zone.Serial)
+	}
+}
+
+func TestImportZoneRejectsOutOfZoneRecords(t *testing.T) {
+	svc := newTestService(t)
+	_, err := svc.ImportZone(context.Background(), "example.com", strings.NewReader("other.org. 300 IN A 192.0.2.1\n"), nil)
+	if err == nil {
+		t.Error("ImportZone() accepted a record outside the zone")
+	}
+}
+
+func TestImportZoneSkipsRecordsRejectedByValidate(t *testing.T) {
+	svc := newTestService(t)
+	validate := func(record Record) error {
+		if record.TTL > 86400 {
+			return fmt.Errorf("TTL must be at most %d", 86400)
+		}
+		return nil
+	}
+
+	result, err := svc.ImportZone(context.Background(), "example.com", strings.NewReader(
+		"www 300 IN A 192.0.2.10\nold 999999 IN A 192.0.2.11\n"), validate)
+	if err != nil {
+		t.Fatalf("ImportZone() error = %v", err)
+	}
+	if result.Imported != 1 {
+		t.Errorf("imported %d records, want 1", result.Imported)
+	}
+	if len(result.Skipped) != 1 || !strings.Contains(result.Skipped[0], "TTL must be at most") {
+		t.Errorf("skipped = %v, want the record with the invalid TTL", result.Skipped)
+	}
+}
+
+func TestMutationsReturnPersistErrors(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+
+	zone, err := svc.CreateZone(ctx, "example.com", ZoneTypePrimary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A directory in place of the data file makes every save fail.
+	if err := os.Mkdir(svc.config.DataPath+".tmp", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.CreateRecord(ctx, zone.ID, Record{Name: "www", Type: RecordTypeA, Value: "192.0.2.10", TTL: 300}); !errors.Is(err, ErrPersist) {
+		t.Errorf("CreateRecord() error = %v, want ErrPersist", err)
+	}
+	if _, err := svc.ImportZone(ctx, "example.org", strings.NewReader("www 300 IN A 192.0.2.10\n"), nil); !errors.Is(err, ErrPersist) {
+		t.Errorf("ImportZone() error = %v, want ErrPersist", err)
+	}
+	if err := svc.DeleteZone(ctx, zone.ID); !errors.Is(err, ErrPersist) {
+		t.Errorf("DeleteZone() error = %v, want ErrPersist", err)
+	}
+}
+
+func TestTransferZoneIsFramedBySOA(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+
+	zone, err := svc.CreateZone(ctx, "example.org", ZoneTypePrimary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rrs, err := svc.TransferZone(ctx, zone.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rrs) != 3 {
+		t.Fatalf("transfer has %d records, want SOA NS SOA", len(rrs))
+	}
+	if rrs[0].String() != rrs[2].String() || !strings.Contains(rrs[0].String(), "SOA\tns1.example.org.") {
+		t.Errorf("transfer = %v", rrs)
+	}
+}
+
+func TestZonesPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dns.json")
+	ctx := context.Background()
+
+	svc, err := NewService(&Config{DataPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone, err := svc.CreateZone(ctx, "example.net", ZoneTypePrimary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateRecord(ctx, zone.ID, Record{Name: "www", Type: RecordTypeA, Value: "192.0.2.1", TTL: 300}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewService(&Config{DataPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.GetZone(ctx, zone.ID)
+	if err != nil {
+		t.Fatalf("zone lost on restart: %v", err)
+	}
+	if got.RecordsCount != 2 || got.Serial == 0 {
+		t.Errorf("reloaded zone = %+v", got)
+	}
+}
+
+func TestNextSerialIncreases(t *testing.T) {
+	if s := nextSerial(4000000000); s != 4000000001 {
+		t.Errorf("nextSerial() = %d", s)
+	}
+	if s := nextSerial(0); s < 2026000000 {
+		t.Errorf("nextSerial(0) = %d, want a date-based serial", s)
+	}
+}
@@ -0,0 +1,324 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertStore manages persistent storage of alert rules, silences and
+// notification channels.
+type AlertStore struct {
+	mu       sync.RWMutex
+	filePath string
+	data     alertStoreData
+}
+
+// alertStoreData is the on-disk layout of the alert store.
+type alertStoreData struct {
+	Rules    map[string]*AlertRule           `json:"rules"`
+	Silences map[string]*Silence             `json:"silences"`
+	Channels map[string]*NotificationChannel `json:"channels"`
+}
+
+// NewAlertStore creates a new alert store.
+// If path is empty, defaults to ~/.homeport/alerts.json
+func NewAlertStore(path string) (*AlertStore, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home dir: %w", err)
+		}
+		path = filepath.Join(home, ".homeport", "alerts.json")
+	}
+
+	store := &AlertStore{
+		filePath: path,
+		data: alertStoreData{
+			Rules:    make(map[string]*AlertRule),
+			Silences: make(map[string]*Silence),
+			Channels: make(map[string]*NotificationChannel),
+		},
+	}
+
+	// Load existing alert configuration if file exists
+	if err := store.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load alerts: %w", err)
+	}
+
+	return store, nil
+}
+
+// SaveRule creates or replaces an alert rule.
+func (s *AlertStore) SaveRule(rule *AlertRule) (*AlertRule, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if existing, ok := s.data.Rules[rule.ID]; ok {
+		rule.CreatedAt = existing.CreatedAt
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = now
+	}
+	rule.UpdatedAt = now
+
+	previous := s.data.Rules[rule.ID]
+	s.data.Rules[rule.ID] = rule
+	if err := s.persist(); err != nil {
+		if previous != nil {
+			s.data.Rules[rule.ID] = previous
+		} else {
+			delete(s.data.Rules, rule.ID)
+		}
+		return nil, fmt.Errorf("failed to persist alert rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetRule retrieves an alert rule by ID.
+func (s *AlertStore) GetRule(id string) (*AlertRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rule, ok := s.data.Rules[id]
+	if !ok {
+		return nil, fmt.Errorf("alert rule not found: %s", id)
+	}
+	return rule, nil
+}
+
+// ListRules returns all alert rules, oldest first.
+func (s *AlertStore) ListRules() []*AlertRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules := make([]*AlertRule, 0, len(s.data.Rules))
+	for _, rule := range s.data.Rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+	})
+	return rules
+}
+
+// DeleteRule removes an alert rule by ID.
+func (s *AlertStore) DeleteRule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, ok := s.data.Rules[id]
+	if !ok {
+		return fmt.Errorf("alert rule not found: %s", id)
+	}
+
+	delete(s.data.Rules, id)
+	if err := s.persist(); err != nil {
+		s.data.Rules[id] = rule
+		return fmt.Errorf("failed to persist after delete: %w", err)
+	}
+	return nil
+}
+
+// SaveSilence creates a silence.
+func (s *AlertStore) SaveSilence(silence *Silence) (*Silence, error) {
+	if err := silence.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if silence.ID == "" {
+		silence.ID = uuid.New().String()
+	}
+	if silence.CreatedAt.IsZero() {
+		silence.CreatedAt = time.Now()
+	}
+
+	s.data.Silences[silence.ID] = silence
+	if err := s.persist(); err != nil {
+		delete(s.data.Silences, silence.ID)
+		return nil, fmt.Errorf("failed to persist silence: %w", err)
+	}
+
+	return silence, nil
+}
+
+// ListSilences returns all silences, including expired ones, by start time.
+func (s *AlertStore) ListSilences() []*Silence {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	silences := make([]*Silence, 0, len(s.data.Silences))
+	for _, silence := range s.data.Silences {
+		silences = append(silences, silence)
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].StartsAt.Before(silences[j].StartsAt)
+	})
+	return silences
+}
+
+// DeleteSilence removes a silence by ID.
+func (s *AlertStore) DeleteSilence(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silence, ok := s.data.Silences[id]
+	if !ok {
+		return fmt.Errorf("silence not found: %s", id)
+	}
+
+	delete(s.data.Silences, id)
+	if err := s.persist(); err != nil {
+		s.data.Silences[id] = silence
+		return fmt.Errorf("failed to persist after delete: %w", err)
+	}
+	return nil
+}
+
+// SaveChannel creates or replaces a notification channel.
+func (s *AlertStore) SaveChannel(channel *NotificationChannel) (*NotificationChannel, error) {
+	if err := channel.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if channel.ID == "" {
+		channel.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if existing, ok := s.data.Channels[channel.ID]; ok {
+		channel.CreatedAt = existing.CreatedAt
+	}
+	if channel.CreatedAt.IsZero() {
+		channel.CreatedAt = now
+	}
+	channel.UpdatedAt = now
+
+	previous := s.data.Channels[channel.ID]
+	s.data.Channels[channel.ID] = channel
+	if err := s.persist(); err != nil {
+		if previous != nil {
+			s.data.Channels[channel.ID] = previous
+		} else {
+			delete(s.data.Channels, channel.ID)
+		}
+		return nil, fmt.Errorf("failed to persist notification channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// GetChannel retrieves a notification channel by ID.
+func (s *AlertStore) GetChannel(id string) (*NotificationChannel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	channel, ok := s.data.Channels[id]
+	if !ok {
+		return nil, fmt.Errorf("notification channel not found: %s", id)
+	}
+	return channel, nil
+}
+
+// ListChannels returns all notification channels, oldest first.
+func (s *AlertStore) ListChannels() []*NotificationChannel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	channels := make([]*NotificationChannel, 0, len(s.data.Channels))
+	for _, channel := range s.data.Channels {
+		channels = append(channels, channel)
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].CreatedAt.Before(channels[j].CreatedAt)
+	})
+	return channels
+}
+
+// DeleteChannel removes a notification channel by ID.
+func (s *AlertStore) DeleteChannel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channel, ok := s.data.Channels[id]
+	if !ok {
+		return fmt.Errorf("notification channel not found: %s", id)
+	}
+
+	delete(s.data.Channels, id)
+	if err := s.persist(); err != nil {
+		s.data.Channels[id] = channel
+		return fmt.Errorf("failed to persist after delete: %w", err)
+	}
+	return nil
+}
+
+// load reads the alert configuration from disk.
+func (s *AlertStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+
+	var stored alertStoreData
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to unmarshal alerts: %w", err)
+	}
+
+	if stored.Rules != nil {
+		s.data.Rules = stored.Rules
+	}
+	if stored.Silences != nil {
+		s.data.Silences = stored.Silences
+	}
+	if stored.Channels != nil {
+		s.data.Channels = stored.Channels
+	}
+	return nil
+}
+
+// persist writes the alert configuration to disk atomically.
+func (s *AlertStore) persist() error {
+	// Ensure directory exists
+	dir := filepath.Dir(s.filePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal alerts: %w", err)
+	}
+
+	// Write atomically via temp file
+	tmpFile := s.filePath + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := os.Rename(tmpFile, s.filePath); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+
+	return nil
+}
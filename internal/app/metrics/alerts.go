@@ -0,0 +1,508 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/homeport/homeport/internal/pkg/logger"
+)
+
+// maxAlertHistory is the number of resolved alerts kept in memory.
+const maxAlertHistory = 100
+
+// systemAlertMetrics are the metrics a system-level rule can watch.
+var systemAlertMetrics = []string{
+	"cpu_percent", "memory_percent", "memory_used_bytes", "disk_percent", "load1", "load5", "load15",
+}
+
+// containerAlertMetrics are the metrics a container rule can watch.
+var containerAlertMetrics = []string{
+	"cpu_percent", "memory_bytes", "memory_percent", "network_rx_bytes", "network_tx_bytes", "disk_read_bytes", "disk_write_bytes",
+}
+
+// AlertRule is a persisted rule evaluated against the collected metrics.
+// The embedded threshold says which metric to watch and when to alert; the
+// metric is aggregated over Window before it is compared.
+type AlertRule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	AlertThreshold
+	Aggregation AggregationType `json:"aggregation"`
+	Window      time.Duration   `json:"window"`
+	// Channels are the notification channel IDs to notify. Empty notifies
+	// every enabled channel.
+	Channels  []string  `json:"channels,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the rule can be evaluated.
+func (r *AlertRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule name is required")
+	}
+
+	known := systemAlertMetrics
+	if r.ContainerID != "" {
+		known = containerAlertMetrics
+	}
+	if !slices.Contains(known, r.MetricName) {
+		return fmt.Errorf("unsupported metric %q, expected one of %s", r.MetricName, strings.Join(known, ", "))
+	}
+
+	switch r.Operator {
+	case "gt", "lt", "gte", "lte", "eq":
+	default:
+		return fmt.Errorf("invalid operator %q, expected gt, lt, gte, lte or eq", r.Operator)
+	}
+
+	switch r.Aggregation {
+	case AggregationAvg, AggregationMax, AggregationMin, AggregationSum, AggregationCount,
+		AggregationP50, AggregationP95, AggregationP99:
+	default:
+		return fmt.Errorf("invalid aggregation: %q", r.Aggregation)
+	}
+
+	if r.Window <= 0 {
+		return fmt.Errorf("window must be positive")
+	}
+	if r.Duration < 0 {
+		return fmt.Errorf("duration cannot be negative")
+	}
+	if severityRank(r.Severity) == 0 {
+		return fmt.Errorf("invalid severity: %q", r.Severity)
+	}
+	return nil
+}
+
+// describe explains the current value of the rule's metric.
+func (r *AlertRule) describe(value float64) string {
+	target := "system"
+	if r.ContainerID != "" {
+		target = "container " + r.ContainerID
+	}
+	message := fmt.Sprintf("%s %s over %s on %s is %.2f, threshold %s %g",
+		r.Aggregation, r.MetricName, r.Window, target, value, r.Operator, r.Value)
+	if r.Description != "" {
+		message += "\n" + r.Description
+	}
+	return message
+}
+
+// Exceeded reports whether value crosses the threshold.
+func (t AlertThreshold) Exceeded(value float64) bool {
+	switch t.Operator {
+	case "gt":
+		return value > t.Value
+	case "gte":
+		return value >= t.Value
+	case "lt":
+		return value < t.Value
+	case "lte":
+		return value <= t.Value
+	case "eq":
+		return value == t.Value
+	default:
+		return false
+	}
+}
+
+// Silence suppresses notifications for a period of time.
+type Silence struct {
+	ID string `json:"id"`
+	// RuleID is the rule to silence. Empty silences every rule.
+	RuleID    string    `json:"rule_id,omitempty"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate checks that the silence covers a period of time.
+func (s *Silence) Validate() error {
+	if s.StartsAt.IsZero() || s.EndsAt.IsZero() {
+		return fmt.Errorf("silence requires a start and an end time")
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("silence must end after it starts")
+	}
+	return nil
+}
+
+// Matches reports whether the silence applies to a rule at a given time.
+func (s *Silence) Matches(ruleID string, now time.Time) bool {
+	if s.RuleID != "" && s.RuleID != ruleID {
+		return false
+	}
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// alertEntry tracks an active alert and whether its channels were told.
+type alertEntry struct {
+	alert    Alert
+	notified bool
+}
+
+// alertNotification is an alert to send to the channels of its rule.
+type alertNotification struct {
+	rule  *AlertRule
+	alert Alert
+}
+
+// AlertEngine evaluates alert rules against the metrics service and notifies
+// channels when alerts fire and resolve.
+type AlertEngine struct {
+	metrics  *Service
+	store    *AlertStore
+	notifier *Notifier
+	interval time.Duration
+
+	mu        sync.RWMutex
+	active    map[string]*alertEntry // by rule ID
+	history   []Alert
+	listeners []func(Alert)
+
+	// Evaluation control
+	cancel  context.CancelFunc
+	running bool
+	wg      sync.WaitGroup
+}
+
+// NewAlertEngine creates an alert engine that evaluates the rules in store at
+// the metrics collection interval.
+func NewAlertEngine(metricsService *Service, store *AlertStore) *AlertEngine {
+	return &AlertEngine{
+		metrics:  metricsService,
+		store:    store,
+		notifier: NewNotifier(),
+		interval: metricsService.config.CollectionInterval,
+		active:   make(map[string]*alertEntry),
+		history:  make([]Alert, 0),
+	}
+}
+
+// Store returns the store of rules, silences and channels.
+func (e *AlertEngine) Store() *AlertStore {
+	return e.store
+}
+
+// Subscribe registers a function called with every alert that changes state.
+func (e *AlertEngine) Subscribe(listener func(Alert)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners = append(e.listeners, listener)
+}
+
+// Start starts evaluating rules in the background.
+func (e *AlertEngine) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.running {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.running = true
+
+	e.wg.Add(1)
+	go e.evaluationLoop(ctx)
+}
+
+// Stop stops evaluating rules.
+func (e *AlertEngine) Stop() {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return
+	}
+	e.running = false
+	e.cancel()
+	e.mu.Unlock()
+
+	e.wg.Wait()
+}
+
+// evaluationLoop runs the periodic rule evaluation.
+func (e *AlertEngine) evaluationLoop(ctx context.Context) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			e.Evaluate(ctx, now)
+		}
+	}
+}
+
+// Evaluate evaluates every enabled rule at the given time. A rule whose
+// threshold is exceeded raises a pending alert, which fires once it has been
+// exceeded for the rule's duration, and resolves when the metric is back
+// under the threshold. Rules without data in their window keep their state.
+func (e *AlertEngine) Evaluate(ctx context.Context, now time.Time) {
+	rules := e.store.ListRules()
+	silences := e.store.ListSilences()
+
+	var changed []Alert
+	var notifications []alertNotification
+
+	e.mu.Lock()
+	evaluated := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		evaluated[rule.ID] = true
+
+		values := e.metrics.windowValues(rule.ContainerID, rule.MetricName, rule.Window, now)
+		if len(values) == 0 {
+			continue
+		}
+		value := aggregate(values, rule.Aggregation)
+		entry := e.active[rule.ID]
+
+		if !rule.Exceeded(value) {
+			if entry == nil {
+				continue
+			}
+			delete(e.active, rule.ID)
+			wasFiring := entry.alert.State == AlertStateFiring
+
+			resolvedAt := now
+			entry.alert.State = AlertStateResolved
+			entry.alert.ResolvedAt = &resolvedAt
+			entry.alert.CurrentValue = value
+			entry.alert.Message = rule.describe(value)
+			changed = append(changed, entry.alert)
+
+			// Pending alerts never fired, so there is nothing to resolve
+			if wasFiring {
+				e.recordHistory(entry.alert)
+				if entry.notified {
+					notifications = append(notifications, alertNotification{rule: rule, alert: entry.alert})
+				}
+			}
+			continue
+		}
+
+		stateChanged := false
+		if entry == nil {
+			entry = &alertEntry{alert: Alert{
+				ID:          uuid.New().String(),
+				RuleID:      rule.ID,
+				State:       AlertStatePending,
+				TriggeredAt: now,
+			}}
+			e.active[rule.ID] = entry
+			stateChanged = true
+		}
+
+		silenced := false
+		for _, silence := range silences {
+			if silence.Matches(rule.ID, now) {
+				silenced = true
+				break
+			}
+		}
+		if entry.alert.Silenced != silenced {
+			entry.alert.Silenced = silenced
+			stateChanged = true
+		}
+
+		entry.alert.RuleName = rule.Name
+		entry.alert.Threshold = rule.AlertThreshold
+		entry.alert.CurrentValue = value
+		entry.alert.Message = rule.describe(value)
+
+		if entry.alert.State == AlertStatePending && now.Sub(entry.alert.TriggeredAt) >= rule.Duration {
+			firedAt := now
+			entry.alert.State = AlertStateFiring
+			entry.alert.FiredAt = &firedAt
+			stateChanged = true
+		}
+
+		// Alerts that fire during a silence are sent once the silence ends
+		if entry.alert.State == AlertStateFiring && !silenced && !entry.notified {
+			entry.notified = true
+			notifications = append(notifications, alertNotification{rule: rule, alert: entry.alert})
+		}
+
+		if stateChanged {
+			changed = append(changed, entry.alert)
+		}
+	}
+
+	// Alerts of deleted or disabled rules are dropped without notification
+	for ruleID, entry := range e.active {
+		if evaluated[ruleID] {
+			continue
+		}
+		delete(e.active, ruleID)
+		resolvedAt := now
+		entry.alert.State = AlertStateResolved
+		entry.alert.ResolvedAt = &resolvedAt
+		changed = append(changed, entry.alert)
+	}
+
+	listeners := append([]func(Alert){}, e.listeners...)
+	e.mu.Unlock()
+
+	for _, alert := range changed {
+		for _, listener := range listeners {
+			listener(alert)
+		}
+	}
+
+	for _, notification := range notifications {
+		e.dispatch(ctx, notification)
+	}
+}
+
+// recordHistory keeps a resolved alert, dropping the oldest ones.
+func (e *AlertEngine) recordHistory(alert Alert) {
+	e.history = append(e.history, alert)
+	if len(e.history) > maxAlertHistory {
+		e.history = e.history[len(e.history)-maxAlertHistory:]
+	}
+}
+
+// dispatch sends an alert to the channels of its rule concurrently.
+func (e *AlertEngine) dispatch(ctx context.Context, notification alertNotification) {
+	var wg sync.WaitGroup
+	for _, channel := range e.channelsFor(notification.rule) {
+		if !channel.Accepts(&notification.alert) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(channel *NotificationChannel) {
+			defer wg.Done()
+			notifyCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			if err := e.notifier.Notify(notifyCtx, channel, &notification.alert); err != nil {
+				logger.Warn("Failed to send alert notification", "rule", notification.rule.Name, "channel", channel.Name, "error", err)
+			}
+		}(channel)
+	}
+	wg.Wait()
+}
+
+// channelsFor returns the channels a rule notifies.
+func (e *AlertEngine) channelsFor(rule *AlertRule) []*NotificationChannel {
+	if len(rule.Channels) == 0 {
+		return e.store.ListChannels()
+	}
+
+	channels := make([]*NotificationChannel, 0, len(rule.Channels))
+	for _, id := range rule.Channels {
+		channel, err := e.store.GetChannel(id)
+		if err != nil {
+			logger.Warn("Alert rule references a missing notification channel", "rule", rule.Name, "channel", id)
+			continue
+		}
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// ActiveAlerts returns the pending and firing alerts, most severe first.
+func (e *AlertEngine) ActiveAlerts() []Alert {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	alerts := make([]Alert, 0, len(e.active))
+	for _, entry := range e.active {
+		alerts = append(alerts, entry.alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		ri, rj := severityRank(alerts[i].Threshold.Severity), severityRank(alerts[j].Threshold.Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		return alerts[i].TriggeredAt.Before(alerts[j].TriggeredAt)
+	})
+	return alerts
+}
+
+// History returns recently resolved alerts, newest first.
+func (e *AlertEngine) History() []Alert {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	alerts := make([]Alert, len(e.history))
+	for i, alert := range e.history {
+		alerts[len(e.history)-1-i] = alert
+	}
+	return alerts
+}
+
+// TestChannel sends a test notification to a channel.
+func (e *AlertEngine) TestChannel(ctx context.Context, channelID string) error {
+	channel, err := e.store.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	alert := &Alert{
+		ID:          uuid.New().String(),
+		RuleName:    "Test notification",
+		State:       AlertStateFiring,
+		Threshold:   AlertThreshold{Severity: AlertSeverityInfo},
+		TriggeredAt: now,
+		FiredAt:     &now,
+		Message:     fmt.Sprintf("Test notification for channel %s sent by Homeport.", channel.Name),
+	}
+	return e.notifier.Notify(ctx, channel, alert)
+}
+
+// windowValues returns the values of a metric collected within window
+// before now. An empty container reference selects system metrics.
+func (s *Service) windowValues(containerRef, metricName string, window time.Duration, now time.Time) []float64 {
+	timeRange := TimeRange{Start: now.Add(-window), End: now}
+
+	if containerRef == "" {
+		s.systemMetrics.mu.RLock()
+		defer s.systemMetrics.mu.RUnlock()
+		return extractSystemMetricValues(filterSystemMetricsByTime(s.systemMetrics.dataPoints, timeRange), metricName)
+	}
+
+	store := s.findContainerStore(containerRef)
+	if store == nil {
+		return nil
+	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return extractContainerMetricValues(filterContainerMetricsByTime(store.dataPoints, timeRange), metricName)
+}
+
+// findContainerStore finds the metrics of a container by ID, ID prefix or
+// name.
+func (s *Service) findContainerStore(ref string) *containerMetricsStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if store, ok := s.containerMetrics[ref]; ok {
+		return store
+	}
+	for id, store := range s.containerMetrics {
+		if strings.HasPrefix(id, ref) || store.containerName == ref {
+			return store
+		}
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestMetricsService() *Service {
+	return &Service{
+		config:           DefaultConfig(),
+		containerMetrics: make(map[string]*containerMetricsStore),
+		systemMetrics:    &systemMetricsStore{dataPoints: make([]SystemMetrics, 0)},
+	}
+}
+
+func newTestAlertStore(t *testing.T) *AlertStore {
+	t.Helper()
+	store, err := NewAlertStore(filepath.Join(t.TempDir(), "alerts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func cpuRule() *AlertRule {
+	return &AlertRule{
+		Name: "High CPU",
+		AlertThreshold: AlertThreshold{
+			MetricName: "cpu_percent",
+			Operator:   "gt",
+			Value:      80,
+			Duration:   time.Minute,
+			Severity:   AlertSeverityCritical,
+		},
+		Aggregation: AggregationAvg,
+		Window:      time.Minute,
+		Enabled:     true,
+	}
+}
+
+// webhookRecorder is a webhook endpoint that records the alert states it
+// receives.
+type webhookRecorder struct {
+	mu     sync.Mutex
+	states []AlertState
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var payload webhookPayload
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.states = append(r.states, payload.Status)
+	r.mu.Unlock()
+}
+
+func (r *webhookRecorder) received() []AlertState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]AlertState(nil), r.states...)
+}
+
+func TestAlertEngineLifecycle(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	metrics := newTestMetricsService()
+	store := newTestAlertStore(t)
+	if _, err := store.SaveRule(cpuRule()); err != nil {
+		t.Fatalf("SaveRule() error = %v", err)
+	}
+	if _, err := store.SaveChannel(&NotificationChannel{Name: "hook", Type: ChannelWebhook, URL: server.URL, Enabled: true}); err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+
+	engine := NewAlertEngine(metrics, store)
+	var events []AlertState
+	engine.Subscribe(func(alert Alert) { events = append(events, alert.State) })
+
+	start := time.Now()
+	evaluate := func(offset time.Duration, cpu float64) {
+		now := start.Add(offset)
+		metrics.storeSystemMetrics(SystemMetrics{Timestamp: now, CPU: SystemCPUMetrics{UsagePercent: cpu}})
+		engine.Evaluate(context.Background(), now)
+	}
+
+	evaluate(0, 95)
+	active := engine.ActiveAlerts()
+	if len(active) != 1 || active[0].State != AlertStatePending {
+		t.Fatalf("active alerts = %+v, want one pending alert", active)
+	}
+
+	evaluate(time.Minute, 95)
+	active = engine.ActiveAlerts()
+	if len(active) != 1 || active[0].State != AlertStateFiring {
+		t.Fatalf("active alerts = %+v, want one firing alert", active)
+	}
+
+	// The average over the window drops under the threshold
+	evaluate(3*time.Minute, 10)
+	if active := engine.ActiveAlerts(); len(active) != 0 {
+		t.Fatalf("active alerts = %+v, want none", active)
+	}
+	history := engine.History()
+	if len(history) != 1 || history[0].State != AlertStateResolved || history[0].ResolvedAt == nil {
+		t.Fatalf("history = %+v, want one resolved alert", history)
+	}
+
+	want := []AlertState{AlertStatePending, AlertStateFiring, AlertStateResolved}
+	if strings.Join(statesToStrings(events), ",") != strings.Join(statesToStrings(want), ",") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	if got := recorder.received(); strings.Join(statesToStrings(got), ",") != "firing,resolved" {
+		t.Errorf("webhook received %v, want firing then resolved", got)
+	}
+}
+
+func TestAlertEngineSilence(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	metrics := newTestMetricsService()
+	store := newTestAlertStore(t)
+	rule := cpuRule()
+	rule.Duration = 0
+	if _, err := store.SaveRule(rule); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.SaveChannel(&NotificationChannel{Name: "hook", Type: ChannelWebhook, URL: server.URL, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	silence, err := store.SaveSilence(&Silence{RuleID: rule.ID, StartsAt: start.Add(-time.Minute), EndsAt: start.Add(time.Minute)})
+	if err != nil {
+		t.Fatalf("SaveSilence() error = %v", err)
+	}
+
+	engine := NewAlertEngine(metrics, store)
+	metrics.storeSystemMetrics(SystemMetrics{Timestamp: start, CPU: SystemCPUMetrics{UsagePercent: 95}})
+	engine.Evaluate(context.Background(), start)
+
+	active := engine.ActiveAlerts()
+	if len(active) != 1 || active[0].State != AlertStateFiring || !active[0].Silenced {
+		t.Fatalf("active alerts = %+v, want one silenced firing alert", active)
+	}
+	if got := recorder.received(); len(got) != 0 {
+		t.Fatalf("webhook received %v during the silence", got)
+	}
+
+	if err := store.DeleteSilence(silence.ID); err != nil {
+		t.Fatal(err)
+	}
+	metrics.storeSystemMetrics(SystemMetrics{Timestamp: start.Add(10 * time.Second), CPU: SystemCPUMetrics{UsagePercent: 95}})
+	engine.Evaluate(context.Background(), start.Add(10*time.Second))
+	if got := recorder.received(); len(got) != 1 || got[0] != AlertStateFiring {
+		t.Errorf("webhook received %v, want the firing alert once the silence ends", got)
+	}
+}
+
+func TestAlertEngineContainerRule(t *testing.T) {
+	metrics := newTestMetricsService()
+	now := time.Now()
+	metrics.storeContainerMetrics("0123456789abcdef", ContainerMetrics{
+		ContainerID:   "0123456789ab",
+		ContainerName: "web",
+		Timestamp:     now,
+		Memory:        MemoryMetrics{UsagePercent: 97},
+	})
+
+	store := newTestAlertStore(t)
+	rule := cpuRule()
+	rule.ContainerID = "web"
+	rule.MetricName = "memory_percent"
+	rule.Duration = 0
+	if _, err := store.SaveRule(rule); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewAlertEngine(metrics, store)
+	engine.Evaluate(context.Background(), now)
+	active := engine.ActiveAlerts()
+	if len(active) != 1 || active[0].CurrentValue != 97 {
+		t.Fatalf("active alerts = %+v, want one alert at 97", active)
+	}
+}
+
+func TestAlertRuleValidate(t *testing.T) {
+	rule := cpuRule()
+	if err := rule.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	rule.MetricName = "memory_bytes"
+	if err := rule.Validate(); err == nil {
+		t.Error("expected an error for a container metric on a system rule")
+	}
+
+	rule = cpuRule()
+	rule.Operator = "above"
+	if err := rule.Validate(); err == nil {
+		t.Error("expected an error for an unknown operator")
+	}
+
+	rule = cpuRule()
+	rule.Window = 0
+	if err := rule.Validate(); err == nil {
+		t.Error("expected an error without a window")
+	}
+}
+
+func TestAlertStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	store, err := NewAlertStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule, err := store.SaveRule(cpuRule())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewAlertStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reloaded.GetRule(rule.ID)
+	if err != nil {
+		t.Fatalf("GetRule() error = %v", err)
+	}
+	if got.MetricName != "cpu_percent" || got.Window != time.Minute || got.Severity != AlertSeverityCritical {
+		t.Errorf("reloaded rule = %+v", got)
+	}
+}
+
+func TestNotifierChannels(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]*http.Request)
+	bodies := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests[r.URL.Path] = r
+		bodies[r.URL.Path] = string(body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	alert := &Alert{
+		RuleName:  "High CPU",
+		State:     AlertStateFiring,
+		Threshold: AlertThreshold{Severity: AlertSeverityCritical},
+		Message:   "avg cpu_percent over 1m0s on system is 95.00, threshold gt 80",
+	}
+	notifier := NewNotifier()
+	ctx := context.Background()
+
+	if err := notifier.Notify(ctx, &NotificationChannel{Type: ChannelNtfy, URL: server.URL + "/alerts", Token: "tk"}, alert); err != nil {
+		t.Fatalf("ntfy: %v", err)
+	}
+	if err := notifier.Notify(ctx, &NotificationChannel{Type: ChannelGotify, URL: server.URL + "/gotify/", Token: "app"}, alert); err != nil {
+		t.Fatalf("gotify: %v", err)
+	}
+	if err := notifier.Notify(ctx, &NotificationChannel{Type: ChannelSlack, URL: server.URL + "/slack"}, alert); err != nil {
+		t.Fatalf("slack: %v", err)
+	}
+
+	ntfy := requests["/alerts"]
+	if ntfy == nil || ntfy.Header.Get("Priority") != "5" || ntfy.Header.Get("Authorization") != "Bearer tk" {
+		t.Errorf("unexpected ntfy request: %+v", ntfy)
+	}
+	if ntfy != nil && ntfy.Header.Get("Title") != "[FIRING] High CPU (critical)" {
+		t.Errorf("ntfy title = %q", ntfy.Header.Get("Title"))
+	}
+	if bodies["/alerts"] != alert.Message {
+		t.Errorf("ntfy body = %q", bodies["/alerts"])
+	}
+
+	gotify := requests["/gotify/message"]
+	if gotify == nil || gotify.Header.Get("X-Gotify-Key") != "app" {
+		t.Errorf("unexpected gotify request: %+v", gotify)
+	}
+	if !strings.Contains(bodies["/gotify/message"], `"priority":8`) {
+		t.Errorf("gotify body = %s", bodies["/gotify/message"])
+	}
+
+	if !strings.Contains(bodies["/slack"], `"text":"*[FIRING] High CPU (critical)*`) {
+		t.Errorf("slack body = %s", bodies["/slack"])
+	}
+
+	var sent struct {
+		addr string
+		to   []string
+		msg  string
+	}
+	notifier.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		sent.addr, sent.to, sent.msg = addr, to, string(msg)
+		return nil
+	}
+	channel := &NotificationChannel{Type: ChannelSMTP, SMTPHost: "mail.example.com", From: "homeport@example.com", To: []string{"ops@example.com"}}
+	if err := notifier.Notify(ctx, channel, alert); err != nil {
+		t.Fatalf("smtp: %v", err)
+	}
+	if sent.addr != "mail.example.com:587" || len(sent.to) != 1 {
+		t.Errorf("smtp sent to %s %v", sent.addr, sent.to)
+	}
+	if !strings.Contains(sent.msg, "Subject: [FIRING] High CPU (critical)\r\n") {
+		t.Errorf("smtp message = %q", sent.msg)
+	}
+}
+
+func TestNotificationChannelAccepts(t *testing.T) {
+	channel := &NotificationChannel{Enabled: true, MinSeverity: AlertSeverityWarning}
+	if channel.Accepts(&Alert{Threshold: AlertThreshold{Severity: AlertSeverityInfo}}) {
+		t.Error("info alert accepted by a warning channel")
+	}
+	if !channel.Accepts(&Alert{Threshold: AlertThreshold{Severity: AlertSeverityCritical}}) {
+		t.Error("critical alert rejected by a warning channel")
+	}
+	channel.Enabled = false
+	if channel.Accepts(&Alert{Threshold: AlertThreshold{Severity: AlertSeverityCritical}}) {
+		t.Error("disabled channel accepted an alert")
+	}
+}
+
+func statesToStrings(states []AlertState) []string {
+	out := make([]string, len(states))
+	for i, state := range states {
+		out[i] = string(state)
+	}
+	return out
+}
@@ -0,0 +1,288 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChannelType identifies how a notification channel delivers alerts.
+type ChannelType string
+
+const (
+	// ChannelWebhook posts the alert as JSON to a URL.
+	ChannelWebhook ChannelType = "webhook"
+	// ChannelSMTP sends the alert by email.
+	ChannelSMTP ChannelType = "smtp"
+	// ChannelNtfy publishes the alert to an ntfy topic URL.
+	ChannelNtfy ChannelType = "ntfy"
+	// ChannelGotify pushes the alert to a Gotify server.
+	ChannelGotify ChannelType = "gotify"
+	// ChannelSlack posts the alert to a Slack-compatible incoming webhook,
+	// which Mattermost and Rocket.Chat also accept.
+	ChannelSlack ChannelType = "slack"
+)
+
+// NotificationChannel is a persisted destination for alert notifications.
+type NotificationChannel struct {
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Type    ChannelType `json:"type"`
+	Enabled bool        `json:"enabled"`
+
+	// URL is the webhook URL, the ntfy topic URL or the Gotify server URL.
+	URL string `json:"url,omitempty"`
+	// Token is the ntfy access token or the Gotify application token.
+	Token string `json:"token,omitempty"`
+	// Headers are added to webhook requests.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// SMTP settings
+	SMTPHost string   `json:"smtp_host,omitempty"`
+	SMTPPort int      `json:"smtp_port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+
+	// MinSeverity drops alerts below this severity. Empty sends every alert.
+	MinSeverity AlertSeverity `json:"min_severity,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the channel has the settings its type needs.
+func (c *NotificationChannel) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("channel name is required")
+	}
+	if c.MinSeverity != "" && severityRank(c.MinSeverity) == 0 {
+		return fmt.Errorf("invalid minimum severity: %s", c.MinSeverity)
+	}
+
+	switch c.Type {
+	case ChannelWebhook, ChannelSlack, ChannelNtfy, ChannelGotify:
+		parsed, err := url.Parse(c.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s channel requires an http or https URL", c.Type)
+		}
+		if c.Type == ChannelGotify && c.Token == "" {
+			return fmt.Errorf("gotify channel requires an application token")
+		}
+	case ChannelSMTP:
+		if c.SMTPHost == "" {
+			return fmt.Errorf("smtp channel requires a host")
+		}
+		if c.From == "" || len(c.To) == 0 {
+			return fmt.Errorf("smtp channel requires a sender and at least one recipient")
+		}
+	default:
+		return fmt.Errorf("unsupported channel type: %s", c.Type)
+	}
+	return nil
+}
+
+// Accepts reports whether the channel should be notified of an alert.
+func (c *NotificationChannel) Accepts(alert *Alert) bool {
+	if !c.Enabled {
+		return false
+	}
+	return c.MinSeverity == "" || severityRank(alert.Threshold.Severity) >= severityRank(c.MinSeverity)
+}
+
+// severityRank orders severities, returning 0 for unknown ones.
+func severityRank(severity AlertSeverity) int {
+	switch severity {
+	case AlertSeverityInfo:
+		return 1
+	case AlertSeverityWarning:
+		return 2
+	case AlertSeverityCritical:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Notifier delivers alerts to notification channels.
+type Notifier struct {
+	client *http.Client
+	// sendMail is net/smtp.SendMail, replaceable in tests
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewNotifier creates a notifier.
+func NewNotifier() *Notifier {
+	return &Notifier{
+		client:   &http.Client{Timeout: 15 * time.Second},
+		sendMail: smtp.SendMail,
+	}
+}
+
+// Notify sends an alert to a channel.
+func (n *Notifier) Notify(ctx context.Context, channel *NotificationChannel, alert *Alert) error {
+	switch channel.Type {
+	case ChannelWebhook:
+		return n.notifyWebhook(ctx, channel, alert)
+	case ChannelSlack:
+		return n.notifySlack(ctx, channel, alert)
+	case ChannelNtfy:
+		return n.notifyNtfy(ctx, channel, alert)
+	case ChannelGotify:
+		return n.notifyGotify(ctx, channel, alert)
+	case ChannelSMTP:
+		return n.notifySMTP(channel, alert)
+	default:
+		return fmt.Errorf("unsupported channel type: %s", channel.Type)
+	}
+}
+
+// webhookPayload is the JSON body posted to generic webhooks.
+type webhookPayload struct {
+	Status AlertState `json:"status"`
+	Title  string     `json:"title"`
+	Alert  *Alert     `json:"alert"`
+}
+
+func (n *Notifier) notifyWebhook(ctx context.Context, channel *NotificationChannel, alert *Alert) error {
+	body, err := json.Marshal(webhookPayload{
+		Status: alert.State,
+		Title:  alertTitle(alert),
+		Alert:  alert,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	for key, value := range channel.Headers {
+		headers[key] = value
+	}
+	return n.post(ctx, channel.URL, body, headers)
+}
+
+func (n *Notifier) notifySlack(ctx context.Context, channel *NotificationChannel, alert *Alert) error {
+	body, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", alertTitle(alert), alert.Message),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack payload: %w", err)
+	}
+	return n.post(ctx, channel.URL, body, map[string]string{"Content-Type": "application/json"})
+}
+
+func (n *Notifier) notifyNtfy(ctx context.Context, channel *NotificationChannel, alert *Alert) error {
+	// ntfy priorities run from 1 (min) to 5 (urgent)
+	priority, tags := 3, "information_source"
+	switch alert.Threshold.Severity {
+	case AlertSeverityCritical:
+		priority, tags = 5, "rotating_light"
+	case AlertSeverityWarning:
+		priority, tags = 4, "warning"
+	}
+	if alert.State == AlertStateResolved {
+		priority, tags = 3, "white_check_mark"
+	}
+
+	headers := map[string]string{
+		"Title":    alertTitle(alert),
+		"Priority": strconv.Itoa(priority),
+		"Tags":     tags,
+	}
+	if channel.Token != "" {
+		headers["Authorization"] = "Bearer " + channel.Token
+	}
+	return n.post(ctx, channel.URL, []byte(alert.Message), headers)
+}
+
+func (n *Notifier) notifyGotify(ctx context.Context, channel *NotificationChannel, alert *Alert) error {
+	// Gotify priorities run from 0 to 10, 8 and above are shown as urgent
+	priority := 2
+	switch alert.Threshold.Severity {
+	case AlertSeverityCritical:
+		priority = 8
+	case AlertSeverityWarning:
+		priority = 5
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"title":    alertTitle(alert),
+		"message":  alert.Message,
+		"priority": priority,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal gotify payload: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(channel.URL, "/") + "/message"
+	return n.post(ctx, endpoint, body, map[string]string{
+		"Content-Type": "application/json",
+		"X-Gotify-Key": channel.Token,
+	})
+}
+
+func (n *Notifier) notifySMTP(channel *NotificationChannel, alert *Alert) error {
+	port := channel.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(channel.SMTPHost, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if channel.Username != "" {
+		auth = smtp.PlainAuth("", channel.Username, channel.Password, channel.SMTPHost)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", channel.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(channel.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", alertTitle(alert))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(alert.Message, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	if err := n.sendMail(addr, auth, channel.From, channel.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// post sends a POST request and treats any non-2xx response as an error.
+func (n *Notifier) post(ctx context.Context, endpoint string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification rejected with status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// alertTitle returns a one-line summary of an alert.
+func alertTitle(alert *Alert) string {
+	return fmt.Sprintf("[%s] %s (%s)", strings.ToUpper(string(alert.State)), alert.RuleName, alert.Threshold.Severity)
+}
@@ -1064,6 +1064,13 @@ func extractSystemMetricValues(points []SystemMetrics, metricName string) []floa
 			values = append(values, p.Load.Load5)
 		case "load15":
 			values = append(values, p.Load.Load15)
+		case "disk_percent":
+			// The fullest mount is the one that matters for alerting
+			fullest := 0.0
+			for _, d := range p.Disk {
+				fullest = math.Max(fullest, d.UsagePercent)
+			}
+			values = append(values, fullest)
 		}
 	}
 	return values
@@ -1085,31 +1092,36 @@ func aggregateValues(points []ContainerMetrics, values []float64, resolution tim
 	result := make(map[time.Time]float64)
 	for bucket, bucketValues := range buckets {
 		ts := time.Unix(0, bucket*int64(resolution))
-		switch aggType {
-		case AggregationAvg:
-			result[ts] = average(bucketValues)
-		case AggregationMax:
-			result[ts] = maxFloat64(bucketValues)
-		case AggregationMin:
-			result[ts] = minFloat64(bucketValues)
-		case AggregationSum:
-			result[ts] = sum(bucketValues)
-		case AggregationCount:
-			result[ts] = float64(len(bucketValues))
-		case AggregationP50:
-			result[ts] = percentile(bucketValues, 50)
-		case AggregationP95:
-			result[ts] = percentile(bucketValues, 95)
-		case AggregationP99:
-			result[ts] = percentile(bucketValues, 99)
-		default:
-			result[ts] = average(bucketValues)
-		}
+		result[ts] = aggregate(bucketValues, aggType)
 	}
 
 	return result
 }
 
+// aggregate reduces values to a single value.
+func aggregate(values []float64, aggType AggregationType) float64 {
+	switch aggType {
+	case AggregationAvg:
+		return average(values)
+	case AggregationMax:
+		return maxFloat64(values)
+	case AggregationMin:
+		return minFloat64(values)
+	case AggregationSum:
+		return sum(values)
+	case AggregationCount:
+		return float64(len(values))
+	case AggregationP50:
+		return percentile(values, 50)
+	case AggregationP95:
+		return percentile(values, 95)
+	case AggregationP99:
+		return percentile(values, 99)
+	default:
+		return average(values)
+	}
+}
+
 func calculateStats(points []MetricPoint) MetricStats {
 	if len(points) == 0 {
 		return MetricStats{}
@@ -342,12 +342,31 @@ const (
 	AlertSeverityCritical AlertSeverity = "critical"
 )
 
+// AlertState represents the lifecycle state of an alert.
+type AlertState string
+
+const (
+	// AlertStatePending means the threshold is exceeded but not yet for the
+	// duration the rule requires.
+	AlertStatePending AlertState = "pending"
+	// AlertStateFiring means the threshold has been exceeded long enough to
+	// notify.
+	AlertStateFiring AlertState = "firing"
+	// AlertStateResolved means the metric went back under the threshold.
+	AlertStateResolved AlertState = "resolved"
+)
+
 // Alert represents an active or historical alert.
 type Alert struct {
-	ID          string         `json:"id"`
-	Threshold   AlertThreshold `json:"threshold"`
-	TriggeredAt time.Time      `json:"triggered_at"`
-	ResolvedAt  *time.Time     `json:"resolved_at,omitempty"`
-	CurrentValue float64       `json:"current_value"`
-	Message     string         `json:"message"`
+	ID           string         `json:"id"`
+	RuleID       string         `json:"rule_id"`
+	RuleName     string         `json:"rule_name"`
+	State        AlertState     `json:"state"`
+	Threshold    AlertThreshold `json:"threshold"`
+	TriggeredAt  time.Time      `json:"triggered_at"`
+	FiredAt      *time.Time     `json:"fired_at,omitempty"`
+	ResolvedAt   *time.Time     `json:"resolved_at,omitempty"`
+	CurrentValue float64        `json:"current_value"`
+	Silenced     bool           `json:"silenced"`
+	Message      string         `json:"message"`
 }
@@ -1002,6 +1002,7 @@ func (s *Service) GenerateConsolidated(ctx context.Context, resources []Resource
 		}
 
 		// Store source resource info in the mapping result for consolidation
+		mappingResult.SourceResource = awsRes
 		mappingResult.SourceResourceType = res.Type
 		mappingResult.SourceResourceName = res.Name
 		mappingResult.SourceCategory = resource.Category(res.Category)
@@ -1389,6 +1390,52 @@ provider "openstack" {
   tenant_id   = var.openstack_tenant_id
   region      = var.region
 }
+`,
+		"exoscale": `# Homeport Terraform Configuration for Exoscale
+# Generated by Homeport - Cloud Migration Platform
+
+terraform {
+  required_version = ">= 1.0"
+  required_providers {
+    exoscale = {
+      source  = "exoscale/exoscale"
+      version = "~> 0.64"
+    }
+  }
+}
+
+provider "exoscale" {
+  key    = var.exoscale_api_key
+  secret = var.exoscale_api_secret
+}
+`,
+		"infomaniak": `# Homeport Terraform Configuration for Infomaniak Public Cloud
+# Generated by Homeport - Cloud Migration Platform
+
+terraform {
+  required_version = ">= 1.0"
+  required_providers {
+    openstack = {
+      source  = "terraform-provider-openstack/openstack"
+      version = "~> 2.1"
+    }
+  }
+}
+
+locals {
+  # dc3-a is served by pub1, dc4-a by pub2
+  cloud = var.region == "dc4-a" ? "pub2" : "pub1"
+}
+
+provider "openstack" {
+  auth_url            = "https://api.${local.cloud}.infomaniak.cloud/identity/v3"
+  user_domain_name    = "Default"
+  project_domain_name = "Default"
+  user_name           = var.os_username
+  password            = var.os_password
+  tenant_name         = var.os_project_name
+  region              = var.region
+}
 `,
 	}
 
@@ -1549,6 +1596,94 @@ variable "flavor_name" {
   type        = string
   default     = "s1-2"
 }
+`,
+		"exoscale": `# Variables for Exoscale deployment
+# Copy terraform.tfvars.example to terraform.tfvars and fill in values
+
+variable "exoscale_api_key" {
+  description = "Exoscale API key"
+  type        = string
+  sensitive   = true
+}
+
+variable "exoscale_api_secret" {
+  description = "Exoscale API secret"
+  type        = string
+  sensitive   = true
+}
+
+variable "project_name" {
+  description = "Project name for resource naming"
+  type        = string
+  default     = "%s"
+}
+
+variable "domain" {
+  description = "Domain name for the deployment"
+  type        = string
+  default     = "%s"
+}
+
+variable "zone" {
+  description = "Exoscale zone"
+  type        = string
+  default     = "%[3]s"
+}
+
+variable "instance_type" {
+  description = "Exoscale instance type"
+  type        = string
+  default     = "standard.small"
+}
+
+variable "ssh_key_name" {
+  description = "Name of an SSH key registered in Exoscale"
+  type        = string
+  default     = ""
+}
+`,
+		"infomaniak": `# Variables for Infomaniak Public Cloud deployment
+# Copy terraform.tfvars.example to terraform.tfvars and fill in values
+
+variable "os_username" {
+  description = "OpenStack username"
+  type        = string
+}
+
+variable "os_password" {
+  description = "OpenStack password"
+  type        = string
+  sensitive   = true
+}
+
+variable "os_project_name" {
+  description = "OpenStack project name"
+  type        = string
+}
+
+variable "project_name" {
+  description = "Project name for resource naming"
+  type        = string
+  default     = "%s"
+}
+
+variable "domain" {
+  description = "Domain name for the deployment"
+  type        = string
+  default     = "%s"
+}
+
+variable "region" {
+  description = "Infomaniak region"
+  type        = string
+  default     = "%[3]s"
+}
+
+variable "flavor_name" {
+  description = "Infomaniak instance flavor"
+  type        = string
+  default     = "a2-ram4-disk50-perf1"
+}
 `,
 	}
 
@@ -1557,14 +1692,27 @@ variable "flavor_name" {
 		tmpl = templates["hetzner"]
 	}
 
-	region := config.Region
-	if region == "" {
-		region = "fsn1"
-	}
+	region := terraformRegion(config)
 
 	return fmt.Sprintf(tmpl, config.ProjectName, config.Domain, region, region)
 }
 
+// terraformRegion returns the configured region, or the provider's default
+// when none is set.
+func terraformRegion(config *TerraformExportConfig) string {
+	if config.Region != "" {
+		return config.Region
+	}
+	switch config.Provider {
+	case "exoscale":
+		return "ch-gva-2"
+	case "infomaniak":
+		return "dc3-a"
+	default:
+		return "fsn1"
+	}
+}
+
 func generateTfvarsExample(config *TerraformExportConfig) string {
 	templates := map[string]string{
 		"hetzner": `# Hetzner Cloud Configuration
@@ -1607,6 +1755,30 @@ project_name = "%s"
 domain       = "%s"
 region       = "%s"
 flavor_name  = "s1-2"
+`,
+		"exoscale": `# Exoscale Configuration
+# Copy this file to terraform.tfvars and fill in your values
+
+exoscale_api_key    = "YOUR_EXOSCALE_API_KEY"
+exoscale_api_secret = "YOUR_EXOSCALE_API_SECRET"
+
+project_name  = "%s"
+domain        = "%s"
+zone          = "%[3]s"
+instance_type = "standard.small"
+ssh_key_name  = "your-ssh-key-name"
+`,
+		"infomaniak": `# Infomaniak Public Cloud Configuration
+# Copy this file to terraform.tfvars and fill in your values
+
+os_username     = "YOUR_OPENSTACK_USERNAME"
+os_password     = "YOUR_OPENSTACK_PASSWORD"
+os_project_name = "YOUR_OPENSTACK_PROJECT_NAME"
+
+project_name = "%s"
+domain       = "%s"
+region       = "%[3]s"
+flavor_name  = "a2-ram4-disk50-perf1"
 `,
 	}
 
@@ -1615,10 +1787,7 @@ flavor_name  = "s1-2"
 		tmpl = templates["hetzner"]
 	}
 
-	region := config.Region
-	if region == "" {
-		region = "fsn1"
-	}
+	region := terraformRegion(config)
 
 	return fmt.Sprintf(tmpl, config.ProjectName, config.Domain, region, region)
 }
@@ -1730,6 +1899,111 @@ resource "openstack_networking_secgroup_rule_v2" "http" {
   security_group_id = openstack_networking_secgroup_v2.main.id
 }
 
+resource "openstack_networking_secgroup_rule_v2" "https" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 443
+  port_range_max    = 443
+  remote_ip_prefix  = "0.0.0.0/0"
+  security_group_id = openstack_networking_secgroup_v2.main.id
+}
+`,
+		"exoscale": `# Networking Resources for Exoscale
+
+resource "exoscale_private_network" "main" {
+  zone     = var.zone
+  name     = "${var.project_name}-network"
+  start_ip = "10.0.1.10"
+  end_ip   = "10.0.1.250"
+  netmask  = "255.255.255.0"
+}
+
+resource "exoscale_security_group" "main" {
+  name        = "${var.project_name}-sg"
+  description = "Security group for ${var.project_name}"
+}
+
+resource "exoscale_security_group_rule" "ssh" {
+  security_group_id = exoscale_security_group.main.id
+  type              = "INGRESS"
+  protocol          = "TCP"
+  cidr              = "0.0.0.0/0"
+  start_port        = 22
+  end_port          = 22
+}
+
+resource "exoscale_security_group_rule" "http" {
+  security_group_id = exoscale_security_group.main.id
+  type              = "INGRESS"
+  protocol          = "TCP"
+  cidr              = "0.0.0.0/0"
+  start_port        = 80
+  end_port          = 80
+}
+
+resource "exoscale_security_group_rule" "https" {
+  security_group_id = exoscale_security_group.main.id
+  type              = "INGRESS"
+  protocol          = "TCP"
+  cidr              = "0.0.0.0/0"
+  start_port        = 443
+  end_port          = 443
+}
+`,
+		"infomaniak": `# Networking Resources for Infomaniak Public Cloud
+
+data "openstack_networking_network_v2" "external" {
+  name = "ext-floating1"
+}
+
+resource "openstack_networking_network_v2" "main" {
+  name           = "${var.project_name}-network"
+  admin_state_up = true
+}
+
+resource "openstack_networking_subnet_v2" "main" {
+  name       = "${var.project_name}-subnet"
+  network_id = openstack_networking_network_v2.main.id
+  cidr       = "10.0.1.0/24"
+  ip_version = 4
+}
+
+resource "openstack_networking_router_v2" "main" {
+  name                = "${var.project_name}-router"
+  external_network_id = data.openstack_networking_network_v2.external.id
+}
+
+resource "openstack_networking_router_interface_v2" "main" {
+  router_id = openstack_networking_router_v2.main.id
+  subnet_id = openstack_networking_subnet_v2.main.id
+}
+
+resource "openstack_networking_secgroup_v2" "main" {
+  name        = "${var.project_name}-secgroup"
+  description = "Security group for ${var.project_name}"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "ssh" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 22
+  port_range_max    = 22
+  remote_ip_prefix  = "0.0.0.0/0"
+  security_group_id = openstack_networking_secgroup_v2.main.id
+}
+
+resource "openstack_networking_secgroup_rule_v2" "http" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 80
+  port_range_max    = 80
+  remote_ip_prefix  = "0.0.0.0/0"
+  security_group_id = openstack_networking_secgroup_v2.main.id
+}
+
 resource "openstack_networking_secgroup_rule_v2" "https" {
   direction         = "ingress"
   ethertype         = "IPv4"
@@ -1844,6 +2118,86 @@ resource "openstack_compute_instance_v2" "app" {
       - systemctl start docker
   EOF
 }
+`,
+		"exoscale": `# Compute Resources for Exoscale
+
+data "exoscale_template" "ubuntu" {
+  zone = var.zone
+  name = "Linux Ubuntu 22.04 LTS 64-bit"
+}
+
+resource "exoscale_compute_instance" "app" {
+  count              = %d
+  zone               = var.zone
+  name               = "${var.project_name}-app-${count.index + 1}"
+  type               = var.instance_type
+  template_id        = data.exoscale_template.ubuntu.id
+  disk_size          = 50
+  ssh_key            = var.ssh_key_name != "" ? var.ssh_key_name : null
+  security_group_ids = [exoscale_security_group.main.id]
+
+  # Volume i is attached to instance i %% count, as on the other providers
+  block_storage_volume_ids = [
+    for i, volume in exoscale_block_storage_volume.data : volume.id
+    if i %% %[1]d == count.index
+  ]
+
+  network_interface {
+    network_id = exoscale_private_network.main.id
+  }
+
+  user_data = <<-EOF
+    #cloud-config
+    packages:
+      - docker.io
+      - docker-compose
+    runcmd:
+      - systemctl enable docker
+      - systemctl start docker
+  EOF
+}
+`,
+		"infomaniak": `# Compute Resources for Infomaniak Public Cloud
+
+data "openstack_images_image_v2" "ubuntu" {
+  name        = "Ubuntu 22.04 LTS Jammy Jellyfish"
+  most_recent = true
+}
+
+resource "openstack_compute_instance_v2" "app" {
+  count           = %d
+  name            = "${var.project_name}-app-${count.index + 1}"
+  flavor_name     = var.flavor_name
+  image_id        = data.openstack_images_image_v2.ubuntu.id
+  security_groups = [openstack_networking_secgroup_v2.main.name]
+
+  network {
+    uuid = openstack_networking_network_v2.main.id
+  }
+
+  user_data = <<-EOF
+    #cloud-config
+    packages:
+      - docker.io
+      - docker-compose
+    runcmd:
+      - systemctl enable docker
+      - systemctl start docker
+  EOF
+
+  depends_on = [openstack_networking_router_interface_v2.main]
+}
+
+resource "openstack_networking_floatingip_v2" "app" {
+  count = length(openstack_compute_instance_v2.app)
+  pool  = data.openstack_networking_network_v2.external.name
+}
+
+resource "openstack_networking_floatingip_associate_v2" "app" {
+  count       = length(openstack_compute_instance_v2.app)
+  floating_ip = openstack_networking_floatingip_v2.app[count.index].address
+  port_id     = openstack_compute_instance_v2.app[count.index].network[0].port
+}
 `,
 	}
 
@@ -1908,6 +2262,31 @@ resource "openstack_blockstorage_volume_v3" "data" {
   description = "Data volume for ${var.project_name}"
 }
 
+resource "openstack_compute_volume_attach_v2" "data" {
+  count       = %d
+  instance_id = openstack_compute_instance_v2.app[count.index %% length(openstack_compute_instance_v2.app)].id
+  volume_id   = openstack_blockstorage_volume_v3.data[count.index].id
+}
+`,
+		"exoscale": `# Storage Resources for Exoscale
+# Volumes are attached through block_storage_volume_ids on exoscale_compute_instance.app
+
+resource "exoscale_block_storage_volume" "data" {
+  count = %[1]d
+  zone  = var.zone
+  name  = "${var.project_name}-data-${count.index + 1}"
+  size  = 50
+}
+`,
+		"infomaniak": `# Storage Resources for Infomaniak Public Cloud
+
+resource "openstack_blockstorage_volume_v3" "data" {
+  count       = %d
+  name        = "${var.project_name}-data-${count.index + 1}"
+  size        = 50
+  description = "Data volume for ${var.project_name}"
+}
+
 resource "openstack_compute_volume_attach_v2" "data" {
   count       = %d
   instance_id = openstack_compute_instance_v2.app[count.index %% length(openstack_compute_instance_v2.app)].id
@@ -2016,6 +2395,70 @@ output "ssh_command" {
   description = "SSH command to connect to the first server"
   value       = "ssh root@${openstack_compute_instance_v2.app[0].access_ip_v4}"
 }
+`,
+		"exoscale": `# Outputs for Exoscale deployment
+
+output "server_ips" {
+  description = "Public IP addresses of the servers"
+  value       = exoscale_compute_instance.app[*].public_ip_address
+}
+
+output "server_names" {
+  description = "Names of the servers"
+  value       = exoscale_compute_instance.app[*].name
+}
+
+output "network_id" {
+  description = "ID of the private network"
+  value       = exoscale_private_network.main.id
+}
+
+output "volume_ids" {
+  description = "IDs of the data volumes"
+  value       = exoscale_block_storage_volume.data[*].id
+}
+
+output "app_url" {
+  description = "URL to access the application"
+  value       = "https://${var.domain}"
+}
+
+output "ssh_command" {
+  description = "SSH command to connect to the first server"
+  value       = "ssh ubuntu@${exoscale_compute_instance.app[0].public_ip_address}"
+}
+`,
+		"infomaniak": `# Outputs for Infomaniak Public Cloud deployment
+
+output "server_ips" {
+  description = "Floating IP addresses of the servers"
+  value       = openstack_networking_floatingip_v2.app[*].address
+}
+
+output "server_names" {
+  description = "Names of the servers"
+  value       = openstack_compute_instance_v2.app[*].name
+}
+
+output "network_id" {
+  description = "ID of the private network"
+  value       = openstack_networking_network_v2.main.id
+}
+
+output "volume_ids" {
+  description = "IDs of the data volumes"
+  value       = openstack_blockstorage_volume_v3.data[*].id
+}
+
+output "app_url" {
+  description = "URL to access the application"
+  value       = "https://${var.domain}"
+}
+
+output "ssh_command" {
+  description = "SSH command to connect to the first server"
+  value       = "ssh ubuntu@${openstack_networking_floatingip_v2.app[0].address}"
+}
 `,
 	}
 
@@ -2088,9 +2531,11 @@ echo "3. Configure your DNS to point to the server IP"
 
 func generateTerraformReadme(config *TerraformExportConfig) string {
 	providerDocs := map[string]string{
-		"hetzner":  "https://registry.terraform.io/providers/hetznercloud/hcloud/latest/docs",
-		"scaleway": "https://registry.terraform.io/providers/scaleway/scaleway/latest/docs",
-		"ovh":      "https://registry.terraform.io/providers/ovh/ovh/latest/docs",
+		"hetzner":    "https://registry.terraform.io/providers/hetznercloud/hcloud/latest/docs",
+		"scaleway":   "https://registry.terraform.io/providers/scaleway/scaleway/latest/docs",
+		"ovh":        "https://registry.terraform.io/providers/ovh/ovh/latest/docs",
+		"exoscale":   "https://registry.terraform.io/providers/exoscale/exoscale/latest/docs",
+		"infomaniak": "https://registry.terraform.io/providers/terraform-provider-openstack/openstack/latest/docs",
 	}
 
 	doc := providerDocs[config.Provider]
@@ -28,3 +28,31 @@ func TestAnalyzeReturnsAppChangeScanErrors(t *testing.T) {
 		t.Fatalf("expected app-change scan error, got %v", err)
 	}
 }
+
+func TestGenerateComputeTfAttachesExoscaleVolumes(t *testing.T) {
+	config := &TerraformExportConfig{Provider: "exoscale", ProjectName: "shop"}
+	resources := []ResourceInfo{
+		{Name: "web", Category: "compute"},
+		{Name: "worker", Category: "compute"},
+		{Name: "data", Category: "storage"},
+	}
+
+	compute := generateComputeTf(config, resources)
+	for _, want := range []string{
+		"count              = 2",
+		"for i, volume in exoscale_block_storage_volume.data : volume.id",
+		"if i % 2 == count.index",
+	} {
+		if !strings.Contains(compute, want) {
+			t.Errorf("exoscale compute.tf missing %q", want)
+		}
+	}
+	if strings.Contains(compute, "%!") {
+		t.Errorf("exoscale compute.tf has formatting errors:\n%s", compute)
+	}
+
+	storage := generateStorageTf(config, resources)
+	if !strings.Contains(storage, `resource "exoscale_block_storage_volume" "data"`) {
+		t.Errorf("exoscale storage.tf missing the volume resource")
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/minio/minio-go/v7"
@@ -21,6 +24,26 @@ type Service struct {
 	client *minio.Client
 }
 
+// ConfigFromEnv reads MINIO_ENDPOINT, MINIO_ROOT_USER and
+// MINIO_ROOT_PASSWORD, the variables generated stacks set for MinIO. It
+// reports false when no endpoint is configured.
+func ConfigFromEnv() (Config, bool, error) {
+	raw := os.Getenv("MINIO_ENDPOINT")
+	if raw == "" {
+		return Config{}, false, nil
+	}
+	parsed, err := url.Parse(strings.TrimRight(raw, "/"))
+	if err != nil || parsed.Host == "" {
+		return Config{}, false, fmt.Errorf("invalid MinIO endpoint %q", raw)
+	}
+	return Config{
+		Endpoint:        parsed.Host,
+		AccessKeyID:     os.Getenv("MINIO_ROOT_USER"),
+		SecretAccessKey: os.Getenv("MINIO_ROOT_PASSWORD"),
+		UseSSL:          parsed.Scheme == "https",
+	}, true, nil
+}
+
 func NewService(cfg Config) (*Service, error) {
 	client, err := minio.New(cfg.Endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
@@ -103,6 +126,19 @@ func (s *Service) UploadObject(ctx context.Context, bucket, key string, reader i
 	return err
 }
 
+func (s *Service) StatObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		LastModified: info.LastModified,
+		ContentType:  info.ContentType,
+	}, nil
+}
+
 func (s *Service) DownloadObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
 	return s.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
 }
@@ -3,6 +3,7 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	gosync "sync"
 	"time"
@@ -12,6 +13,9 @@ import (
 	infrasync "github.com/homeport/homeport/internal/infrastructure/sync"
 )
 
+// replicationStopTimeout bounds stopping the replication of a cancelled plan.
+const replicationStopTimeout = 30 * time.Second
+
 // Service orchestrates data synchronization operations.
 type Service struct {
 	registry *domainsync.StrategyRegistry
@@ -22,10 +26,19 @@ type Service struct {
 // SyncExecution tracks the execution state of a sync plan.
 type SyncExecution struct {
 	Plan      *domainsync.SyncPlan
-	Status    string // "pending", "running", "paused", "completed", "failed", "cancelled"
+	Status    string // "pending", "running", "paused", "replicating", "completing", "completed", "failed", "cancelled"
 	StartedAt *time.Time
 	Error     string
 	cancel    context.CancelFunc
+	// replications are the tasks whose target still receives changes from
+	// the source until the plan is completed or cancelled.
+	replications []replication
+}
+
+// replication is a task whose replicating strategy is still streaming.
+type replication struct {
+	strategy domainsync.ReplicatingStrategy
+	task     *domainsync.SyncTask
 }
 
 // NewService creates a new sync service with the default strategy registry.
@@ -83,6 +96,7 @@ type SyncEvent struct {
 	BytesDone  int64                   `json:"bytes_done,omitempty"`
 	ItemsTotal int64                   `json:"items_total,omitempty"`
 	ItemsDone  int64                   `json:"items_done,omitempty"`
+	ReplicationLag *int64              `json:"replication_lag_bytes,omitempty"`
 	Error     string                   `json:"error,omitempty"`
 	Message   string                   `json:"message,omitempty"`
 }
@@ -100,6 +114,10 @@ func (s *Service) Start(ctx context.Context, planID string, callback StartCallba
 		s.mu.Unlock()
 		return fmt.Errorf("sync plan already running")
 	}
+	if exec.Status == "replicating" {
+		s.mu.Unlock()
+		return fmt.Errorf("sync plan is replicating; complete or cancel it first")
+	}
 
 	// Create cancellable context
 	ctx, cancel := context.WithCancel(ctx)
@@ -166,19 +184,24 @@ func (s *Service) executePlan(ctx context.Context, exec *SyncExecution, callback
 		// Create progress channel
 		progressCh := make(chan domainsync.Progress, 100)
 
-		// Run sync in goroutine and collect progress
+		// Run sync in goroutine and collect progress. The progress channel is
+		// closed once the strategy is done with it.
 		errCh := make(chan error, 1)
 		go func() {
-			errCh <- strategy.Sync(ctx, task.Source, task.Target, progressCh)
+			err := s.syncTask(ctx, exec, strategy, task, progressCh)
+			close(progressCh)
+			errCh <- err
 		}()
 
 		// Forward progress events
+		progressEvents := progressCh
 	progressLoop:
 		for {
 			select {
-			case progress, ok := <-progressCh:
+			case progress, ok := <-progressEvents:
 				if !ok {
-					break progressLoop
+					progressEvents = nil
+					continue
 				}
 				task.Progress = &progress
 				percentDone := 0
@@ -198,12 +221,10 @@ func (s *Service) executePlan(ctx context.Context, exec *SyncExecution, callback
 						BytesDone:  progress.BytesDone,
 						ItemsTotal: progress.ItemsTotal,
 						ItemsDone:  progress.ItemsDone,
+						ReplicationLag: progress.ReplicationLag,
 					})
 				}
 			case err := <-errCh:
-				// Drain remaining progress
-				for range progressCh {
-				}
 				if err != nil {
 					task.Fail(err)
 					if callback != nil {
@@ -230,6 +251,12 @@ func (s *Service) executePlan(ctx context.Context, exec *SyncExecution, callback
 				}
 				break progressLoop
 			case <-ctx.Done():
+				// Wait for the strategy, which cleans up after itself
+				if progressEvents != nil {
+					for range progressEvents {
+					}
+				}
+				<-errCh
 				task.Fail(ctx.Err())
 				break progressLoop
 			}
@@ -238,9 +265,12 @@ func (s *Service) executePlan(ctx context.Context, exec *SyncExecution, callback
 
 	// Mark plan as complete
 	s.mu.Lock()
-	if plan.HasFailed() {
+	switch {
+	case plan.HasFailed():
 		exec.Status = "failed"
-	} else {
+	case len(exec.replications) > 0:
+		exec.Status = "replicating"
+	default:
 		exec.Status = "completed"
 	}
 	s.mu.Unlock()
@@ -254,6 +284,72 @@ func (s *Service) executePlan(ctx context.Context, exec *SyncExecution, callback
 	}
 }
 
+// syncTask runs the strategy of a task. A replicating strategy keeps
+// streaming changes to the target after the initial sync, until the plan is
+// completed or cancelled. If the plan is cancelled during the initial sync,
+// its replication is stopped.
+func (s *Service) syncTask(ctx context.Context, exec *SyncExecution, strategy domainsync.SyncStrategy, task *domainsync.SyncTask, progress chan<- domainsync.Progress) error {
+	err := strategy.Sync(ctx, task.Source, task.Target, progress)
+	replicating, ok := strategy.(domainsync.ReplicatingStrategy)
+	switch {
+	case !ok:
+		return err
+	case err == nil:
+		s.mu.Lock()
+		exec.replications = append(exec.replications, replication{strategy: replicating, task: task})
+		s.mu.Unlock()
+		return nil
+	case ctx.Err() != nil:
+		stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), replicationStopTimeout)
+		defer cancel()
+		if stopErr := replicating.StopReplication(stopCtx, task.Source, task.Target); stopErr != nil {
+			return errors.Join(err, stopErr)
+		}
+		return err
+	default:
+		// Left in place so that starting the plan again resumes the sync
+		return err
+	}
+}
+
+// Complete ends the replication of a replicating sync plan, typically at
+// cutover once writes to the sources have stopped. Each target is promoted
+// once it has applied every change of its source.
+func (s *Service) Complete(ctx context.Context, planID string) error {
+	s.mu.Lock()
+	exec, ok := s.plans[planID]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("sync plan not found: %s", planID)
+	}
+	if exec.Status != "replicating" {
+		s.mu.Unlock()
+		return fmt.Errorf("sync plan is not replicating")
+	}
+	replications := exec.replications
+	exec.replications = nil
+	exec.Status = "completing"
+	s.mu.Unlock()
+
+	var errs []error
+	for _, r := range replications {
+		if err := domainsync.CompleteReplication(ctx, r.strategy, r.task.Source, r.task.Target, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to complete replication of %s: %w", r.task.Name, err))
+		}
+	}
+	err := errors.Join(errs...)
+
+	s.mu.Lock()
+	if err != nil {
+		exec.Status = "failed"
+		exec.Error = err.Error()
+	} else {
+		exec.Status = "completed"
+	}
+	s.mu.Unlock()
+	return err
+}
+
 // Pause pauses a running sync plan.
 func (s *Service) Pause(planID string) error {
 	s.mu.Lock()
@@ -295,13 +391,13 @@ func (s *Service) Resume(ctx context.Context, planID string, callback StartCallb
 	return nil
 }
 
-// Cancel cancels a running sync plan.
+// Cancel cancels a running sync plan and stops the replication of tasks
+// that have finished their initial sync, without promoting their targets.
 func (s *Service) Cancel(planID string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	exec, ok := s.plans[planID]
 	if !ok {
+		s.mu.Unlock()
 		return fmt.Errorf("sync plan not found: %s", planID)
 	}
 
@@ -309,7 +405,19 @@ func (s *Service) Cancel(planID string) error {
 		exec.cancel()
 	}
 	exec.Status = "cancelled"
-	return nil
+	replications := exec.replications
+	exec.replications = nil
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), replicationStopTimeout)
+	defer cancel()
+	var errs []error
+	for _, r := range replications {
+		if err := r.strategy.StopReplication(ctx, r.task.Source, r.task.Target); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop replication of %s: %w", r.task.Name, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // ListPlans returns all sync plans.
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/homeport/homeport/internal/cli/ui"
 	"github.com/homeport/homeport/internal/domain/parser"
@@ -25,6 +26,8 @@ var (
 	analyzeProject     string
 	analyzeRegions     []string
 	analyzeCredentials string
+	analyzeVars        []string
+	analyzeVarFiles    []string
 )
 
 // analyzeCmd represents the analyze command
@@ -38,8 +41,13 @@ a detailed analysis of all resources, dependencies, and migration requirements.
 
 Supported input sources:
   - terraform      : Terraform state files and HCL (*.tf, terraform.tfstate)
+  - tfplan         : Terraform JSON plans (terraform show -json plan.out > tfplan.json)
+  - terragrunt     : Terragrunt projects (terragrunt.hcl units, merged per environment)
   - cloudformation : AWS CloudFormation templates (*.yaml, *.json, *.template)
   - arm            : Azure Resource Manager templates (*.json)
+  - compose        : Docker Compose projects (compose.yaml, docker-compose.yml)
+  - kubernetes     : Kubernetes manifests (Deployments, StatefulSets, Services, ...)
+  - helm           : Helm charts rendered with helm template
   - aws-api        : Live AWS API scanning (requires credentials)
   - gcp-api        : Live GCP API scanning (requires credentials)
   - azure-api      : Live Azure API scanning (requires credentials)
@@ -48,15 +56,31 @@ Examples:
   # Auto-detect and analyze Terraform directory
   homeport analyze ./infrastructure
 
+  # Analyze Terraform with variable overrides
+  homeport analyze ./infrastructure --var env=prod --var-file prod.tfvars
+
   # Analyze specific Terraform state file
   homeport analyze terraform.tfstate
 
+  # Analyze a Terraform plan produced in CI
+  homeport analyze --source tfplan tfplan.json
+
+  # Analyze every unit of a Terragrunt live repository
+  homeport analyze --source terragrunt ./live
+
   # Analyze CloudFormation templates
   homeport analyze --source cloudformation ./templates
 
   # Analyze ARM templates
   homeport analyze --source arm ./arm-templates
 
+  # Analyze a Docker Compose project
+  homeport analyze --source compose ./docker-compose.yml
+
+  # Analyze a rendered Helm chart
+  helm template prod ./chart > rendered.yaml
+  homeport analyze --source helm rendered.yaml
+
   # Analyze live AWS infrastructure via API
   homeport analyze --source aws-api --profile production --region us-east-1
 
@@ -132,11 +156,13 @@ func init() {
 
 	analyzeCmd.Flags().StringVarP(&analyzeOutput, "output", "o", "analysis.json", "output file path (use '-' for stdout)")
 	analyzeCmd.Flags().StringVarP(&analyzeFormat, "format", "f", "json", "output format (json, yaml, table)")
-	analyzeCmd.Flags().StringVarP(&analyzeSource, "source", "s", "", "source type (terraform, cloudformation, arm, aws-api, gcp-api, azure-api)")
+	analyzeCmd.Flags().StringVarP(&analyzeSource, "source", "s", "", "source type (terraform, tfplan, terragrunt, cloudformation, arm, compose, kubernetes, helm, aws-api, gcp-api, azure-api)")
 	analyzeCmd.Flags().StringVarP(&analyzeProfile, "profile", "p", "", "AWS profile name (for aws-api source)")
 	analyzeCmd.Flags().StringVar(&analyzeProject, "project", "", "GCP project ID (for gcp-api source)")
 	analyzeCmd.Flags().StringSliceVarP(&analyzeRegions, "region", "r", nil, "Region(s)/location(s) to scan (for API sources)")
 	analyzeCmd.Flags().StringVar(&analyzeCredentials, "credentials", "", "path to credentials file")
+	analyzeCmd.Flags().StringArrayVar(&analyzeVars, "var", nil, "set a Terraform variable (name=value, repeatable)")
+	analyzeCmd.Flags().StringArrayVar(&analyzeVarFiles, "var-file", nil, "load Terraform variables from a .tfvars file (repeatable)")
 }
 
 // isAPISource checks if the source is an API-based source.
@@ -149,6 +175,19 @@ func isAPISource(source string) bool {
 	}
 }
 
+// parseVarFlags parses repeated --var name=value flags.
+func parseVarFlags(flags []string) (map[string]string, error) {
+	vars := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		name, value, ok := strings.Cut(flag, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected name=value", flag)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
 // AnalysisResult represents the result of infrastructure analysis
 type AnalysisResult struct {
 	InputPath    string              `json:"input_path" yaml:"input_path"`
@@ -173,10 +212,11 @@ type ResourceSummary struct {
 
 // AnalysisStatistics represents statistics about the analysis
 type AnalysisStatistics struct {
-	TotalResources int                       `json:"total_resources" yaml:"total_resources"`
-	ByType         map[string]int            `json:"by_type" yaml:"by_type"`
-	ByRegion       map[string]int            `json:"by_region" yaml:"by_region"`
-	Migration      MigrationStatistics       `json:"migration" yaml:"migration"`
+	TotalResources  int                 `json:"total_resources" yaml:"total_resources"`
+	ByType          map[string]int      `json:"by_type" yaml:"by_type"`
+	ByRegion        map[string]int      `json:"by_region" yaml:"by_region"`
+	ModulesFollowed int                 `json:"modules_followed,omitempty" yaml:"modules_followed,omitempty"`
+	Migration       MigrationStatistics `json:"migration" yaml:"migration"`
 }
 
 // MigrationStatistics represents migration-specific statistics
@@ -222,6 +262,18 @@ func performAnalysis(inputPath string) (*AnalysisResult, error) {
 		opts.WithCredentials(creds)
 	}
 
+	// Terraform variable overrides
+	if len(analyzeVars) > 0 {
+		vars, err := parseVarFlags(analyzeVars)
+		if err != nil {
+			return nil, err
+		}
+		opts.WithVariables(vars)
+	}
+	if len(analyzeVarFiles) > 0 {
+		opts.WithVarFiles(analyzeVarFiles...)
+	}
+
 	var infra *resource.Infrastructure
 	var sourceType string
 
@@ -326,6 +378,50 @@ func performAnalysis(inputPath string) (*AnalysisResult, error) {
 			return nil, fmt.Errorf("terraform parsing failed: %w", parseErr)
 		}
 
+	case "tfplan":
+		sourceType = "tfplan"
+		if IsVerbose() {
+			ui.Info("Using Terraform plan parser...")
+		}
+		var parseErr error
+		infra, parseErr = parseAllProviders(ctx, inputPath, opts, parser.FormatTFPlan)
+		if parseErr != nil {
+			return nil, fmt.Errorf("terraform plan parsing failed: %w", parseErr)
+		}
+
+	case "terragrunt":
+		sourceType = "terragrunt"
+		if IsVerbose() {
+			ui.Info("Using Terragrunt parser...")
+		}
+		var parseErr error
+		infra, parseErr = parseAllProviders(ctx, inputPath, opts, parser.FormatTerragrunt)
+		if parseErr != nil {
+			return nil, fmt.Errorf("terragrunt parsing failed: %w", parseErr)
+		}
+
+	case "compose", "docker-compose", "kubernetes", "k8s", "helm":
+		format := parser.FormatHelm
+		switch analyzeSource {
+		case "compose", "docker-compose":
+			format = parser.FormatDockerCompose
+		case "kubernetes", "k8s":
+			format = parser.FormatKubernetes
+		}
+		sourceType = string(format)
+		if IsVerbose() {
+			ui.Info(fmt.Sprintf("Using %s parser...", format))
+		}
+		p, pErr := parser.DefaultRegistry().GetByFormat(resource.ProviderAWS, format)
+		if pErr != nil {
+			return nil, fmt.Errorf("%s parser not available: %w", format, pErr)
+		}
+		var parseErr error
+		infra, parseErr = p.Parse(ctx, inputPath, opts)
+		if parseErr != nil {
+			return nil, fmt.Errorf("%s parsing failed: %w", format, parseErr)
+		}
+
 	default:
 		// Auto-detect
 		if IsVerbose() {
@@ -361,6 +457,41 @@ func performAnalysis(inputPath string) (*AnalysisResult, error) {
 	return result, nil
 }
 
+// parseAllProviders parses inputPath with the parser for format of every
+// provider that finds resources in it and merges the results.
+func parseAllProviders(ctx context.Context, inputPath string, opts *parser.ParseOptions, format parser.Format) (*resource.Infrastructure, error) {
+	var merged *resource.Infrastructure
+	for _, provider := range []resource.Provider{resource.ProviderAWS, resource.ProviderGCP, resource.ProviderAzure} {
+		p, err := parser.DefaultRegistry().GetByFormat(provider, format)
+		if err != nil {
+			continue
+		}
+		if ok, _ := p.AutoDetect(inputPath); !ok {
+			continue
+		}
+		infra, err := p.Parse(ctx, inputPath, opts)
+		if err != nil {
+			return nil, err
+		}
+		if merged == nil {
+			merged = infra
+			continue
+		}
+		for id, res := range infra.Resources {
+			merged.Resources[id] = res
+		}
+		for key, value := range infra.Metadata {
+			if _, exists := merged.Metadata[key]; !exists {
+				merged.Metadata[key] = value
+			}
+		}
+	}
+	if merged == nil {
+		return nil, fmt.Errorf("no supported resources found in %s", inputPath)
+	}
+	return merged, nil
+}
+
 // buildAnalysisResult converts infrastructure to analysis result
 func buildAnalysisResult(infra *resource.Infrastructure, absPath, sourceType string) *AnalysisResult {
 	result := &AnalysisResult{
@@ -373,6 +504,7 @@ func buildAnalysisResult(infra *resource.Infrastructure, absPath, sourceType str
 		},
 		Dependencies: make([]DependencyMapping, 0),
 	}
+	result.Statistics.ModulesFollowed = parser.NewParseStats(infra).ModulesFollowed
 
 	// Convert resources
 	for _, res := range infra.Resources {
@@ -519,6 +651,9 @@ func outputTable(result *AnalysisResult) error {
 	fmt.Printf("Networking:      %d\n", result.Statistics.Migration.Networking)
 	fmt.Printf("Security:        %d\n", result.Statistics.Migration.Security)
 	fmt.Printf("Other:           %d\n", result.Statistics.Migration.Other)
+	if result.Statistics.ModulesFollowed > 0 {
+		fmt.Printf("Modules:         %d\n", result.Statistics.ModulesFollowed)
+	}
 
 	// Print dependencies
 	if len(result.Dependencies) > 0 {
@@ -20,6 +20,9 @@ var (
 	backupDesc       string
 	backupVolumes    []string
 	backupOutputFile string
+	backupCron       string
+	backupSkipMissed bool
+	backupTargetTime string
 )
 
 // backupCmd represents the backup command group
@@ -88,9 +91,13 @@ var backupRestoreCmd = &cobra.Command{
 
 This will restore the backed up volumes to the target stack.
 
+Database volumes backed up with continuous WAL or binlog archiving can be
+restored to a point in time after the backup with --target-time.
+
 Examples:
   homeport backup restore my-stack backup-123
-  homeport backup restore my-stack backup-123 --volumes postgres-data`,
+  homeport backup restore my-stack backup-123 --volumes postgres-data
+  homeport backup restore my-stack backup-123 --volumes postgres-data --target-time 2026-05-04T14:30:00Z`,
 	Args: cobra.ExactArgs(2),
 	RunE: runBackupRestore,
 }
@@ -124,6 +131,58 @@ Examples:
 	RunE: runBackupDelete,
 }
 
+// backupScheduleCmd groups schedule subcommands
+var backupScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage scheduled backups",
+	Long: `Manage cron schedules that back up stacks automatically.
+
+Schedules are executed by 'homeport serve'. Runs missed while the server
+was down are caught up once at startup unless --skip-missed is set.
+
+Examples:
+  homeport backup schedule add my-stack --cron "0 3 * * *"
+  homeport backup schedule list my-stack
+  homeport backup schedule remove schedule-123`,
+}
+
+// backupScheduleAddCmd adds a schedule
+var backupScheduleAddCmd = &cobra.Command{
+	Use:   "add <stack-id>",
+	Short: "Schedule backups of a stack",
+	Long: `Schedule backups of a stack with a cron expression.
+
+The expression has five fields (minute hour day-of-month month day-of-week)
+or is one of @hourly, @daily, @weekly, @monthly and @yearly. Without
+--volumes, every volume of the stack is backed up.
+
+Examples:
+  homeport backup schedule add my-stack --cron "0 3 * * *"
+  homeport backup schedule add my-stack --cron @hourly --name hourly --volumes db-data`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupScheduleAdd,
+}
+
+// backupScheduleListCmd lists schedules
+var backupScheduleListCmd = &cobra.Command{
+	Use:   "list [stack-id]",
+	Short: "List backup schedules",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runBackupScheduleList,
+}
+
+// backupScheduleRemoveCmd removes a schedule
+var backupScheduleRemoveCmd = &cobra.Command{
+	Use:   "remove <schedule-id>",
+	Short: "Remove a backup schedule",
+	Long: `Remove a backup schedule. Backups it already created are kept.
+
+Examples:
+  homeport backup schedule remove schedule-123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupScheduleRemove,
+}
+
 func init() {
 	rootCmd.AddCommand(backupCmd)
 
@@ -133,6 +192,10 @@ func init() {
 	backupCmd.AddCommand(backupRestoreCmd)
 	backupCmd.AddCommand(backupDownloadCmd)
 	backupCmd.AddCommand(backupDeleteCmd)
+	backupCmd.AddCommand(backupScheduleCmd)
+	backupScheduleCmd.AddCommand(backupScheduleAddCmd)
+	backupScheduleCmd.AddCommand(backupScheduleListCmd)
+	backupScheduleCmd.AddCommand(backupScheduleRemoveCmd)
 
 	// Global backup flags
 	backupCmd.PersistentFlags().StringVar(&backupAPIURL, "api-url", "http://localhost:8080", "API server URL")
@@ -146,9 +209,17 @@ func init() {
 
 	// Restore command flags
 	backupRestoreCmd.Flags().StringSliceVarP(&backupVolumes, "volumes", "V", nil, "volumes to restore (comma-separated)")
+	backupRestoreCmd.Flags().StringVar(&backupTargetTime, "target-time", "", "restore databases to this point in time (RFC 3339)")
 
 	// Download command flags
 	backupDownloadCmd.Flags().StringVarP(&backupOutputFile, "output", "o", "", "output file path (default: backup-<id>.tar.gz)")
+
+	// Schedule add command flags
+	backupScheduleAddCmd.Flags().StringVar(&backupCron, "cron", "", "cron expression (required)")
+	backupScheduleAddCmd.Flags().StringVarP(&backupName, "name", "n", "", "backup name prefix (default: scheduled)")
+	backupScheduleAddCmd.Flags().StringSliceVarP(&backupVolumes, "volumes", "V", nil, "volumes to backup (default: all stack volumes)")
+	backupScheduleAddCmd.Flags().BoolVar(&backupSkipMissed, "skip-missed", false, "do not catch up runs missed while the server was down")
+	_ = backupScheduleAddCmd.MarkFlagRequired("cron")
 }
 
 // BackupInfo represents backup information from the API
@@ -163,6 +234,20 @@ type BackupInfo struct {
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// BackupScheduleInfo represents backup schedule information from the API
+type BackupScheduleInfo struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	StackID    string     `json:"stack_id"`
+	Cron       string     `json:"cron"`
+	Volumes    []string   `json:"volumes"`
+	Enabled    bool       `json:"enabled"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	LastStatus string     `json:"last_status"`
+	LastError  string     `json:"last_error"`
+}
+
 // BackupListResponse represents the list backups API response
 type BackupListResponse struct {
 	Backups []BackupInfo `json:"backups"`
@@ -323,6 +408,12 @@ func runBackupRestore(cmd *cobra.Command, args []string) error {
 	if len(backupVolumes) > 0 {
 		payload["volumes"] = backupVolumes
 	}
+	if backupTargetTime != "" {
+		if _, err := time.Parse(time.RFC3339, backupTargetTime); err != nil {
+			return fmt.Errorf("--target-time must be an RFC 3339 timestamp: %w", err)
+		}
+		payload["target_time"] = backupTargetTime
+	}
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -458,6 +549,151 @@ func runBackupDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runBackupScheduleAdd(cmd *cobra.Command, args []string) error {
+	stackID := args[0]
+
+	if !IsQuiet() {
+		ui.Header("Homeport - Schedule Backups")
+		ui.Info(fmt.Sprintf("Stack: %s", stackID))
+		ui.Info(fmt.Sprintf("Cron: %s", backupCron))
+		ui.Divider()
+	}
+
+	payload := map[string]interface{}{
+		"name":        backupName,
+		"stack_id":    stackID,
+		"cron":        backupCron,
+		"volumes":     backupVolumes,
+		"skip_missed": backupSkipMissed,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/backups/schedules", getAPIURL())
+
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error: %s", string(body))
+	}
+
+	var schedule BackupScheduleInfo
+	if err := json.NewDecoder(resp.Body).Decode(&schedule); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if !IsQuiet() {
+		ui.Success("Backup schedule created")
+		ui.Info(fmt.Sprintf("Schedule ID: %s", schedule.ID))
+		ui.Info(fmt.Sprintf("Next run: %s", schedule.NextRunAt.Local().Format("2006-01-02 15:04")))
+	}
+
+	return nil
+}
+
+func runBackupScheduleList(cmd *cobra.Command, args []string) error {
+	if !IsQuiet() {
+		ui.Header("Homeport - Backup Schedules")
+		ui.Divider()
+	}
+
+	url := fmt.Sprintf("%s/api/v1/backups/schedules", getAPIURL())
+	if len(args) > 0 {
+		url = fmt.Sprintf("%s?stack_id=%s", url, args[0])
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error: %s", string(body))
+	}
+
+	var result struct {
+		Schedules []BackupScheduleInfo `json:"schedules"`
+		Count     int                  `json:"count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(result.Schedules) == 0 {
+		ui.Info("No backup schedules found")
+		return nil
+	}
+
+	table := ui.NewTable([]string{"ID", "Name", "Stack", "Cron", "Next Run", "Last Status"})
+	for _, schedule := range result.Schedules {
+		lastStatus := schedule.LastStatus
+		if lastStatus == "" {
+			lastStatus = "-"
+		}
+		if !schedule.Enabled {
+			lastStatus += " (disabled)"
+		}
+		table.AddRow([]string{
+			schedule.ID,
+			schedule.Name,
+			schedule.StackID,
+			schedule.Cron,
+			schedule.NextRunAt.Local().Format("2006-01-02 15:04"),
+			lastStatus,
+		})
+	}
+	fmt.Println(table.Render())
+
+	if !IsQuiet() {
+		ui.Info(fmt.Sprintf("Total: %d schedule(s)", result.Count))
+	}
+
+	return nil
+}
+
+func runBackupScheduleRemove(cmd *cobra.Command, args []string) error {
+	scheduleID := args[0]
+
+	url := fmt.Sprintf("%s/api/v1/backups/schedules/%s", getAPIURL(), scheduleID)
+
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("schedule not found: %s", scheduleID)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error: %s", string(body))
+	}
+
+	if !IsQuiet() {
+		ui.Success("Backup schedule removed")
+	}
+
+	return nil
+}
+
 // formatBytes converts bytes to human-readable format
 func formatBytes(bytes int64) string {
 	const unit = 1024
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	appcutover "github.com/homeport/homeport/internal/app/cutover"
 	"github.com/homeport/homeport/internal/cli/ui"
 	domainBundle "github.com/homeport/homeport/internal/domain/bundle"
 	"github.com/homeport/homeport/internal/domain/cutover"
@@ -27,6 +28,12 @@ var (
 	cutoverSkipPreCheck bool
 	cutoverAPIToken     string
 	cutoverZoneID       string
+	cutoverDNSServer    string
+	cutoverTSIGKey      string
+	cutoverTraefikDir   string
+	cutoverList         bool
+	cutoverResumeID     string
+	cutoverID           string
 )
 
 // cutoverCmd represents the cutover command
@@ -40,6 +47,9 @@ from your cloud infrastructure to the self-hosted Docker stack. It includes:
 
   - Pre-cutover health checks (validate target is ready)
   - DNS record changes (switch traffic to new infrastructure)
+  - Progressive traffic shifts (e.g. 5% → 25% → 50% → 100%) with health
+    check and error-rate gates between stages, using weighted DNS records
+    or a generated Traefik weighted route
   - Post-cutover validation (verify the migration succeeded)
   - Automatic rollback on failure
 
@@ -58,12 +68,34 @@ Examples:
   homeport cutover --bundle migration.hprt --dns-provider route53 \
     --zone-id HOSTED_ZONE_ID
 
+  # Use OVHcloud DNS (keys from OVH_APPLICATION_KEY, OVH_APPLICATION_SECRET
+  # and OVH_CONSUMER_KEY)
+  homeport cutover --bundle migration.hprt --dns-provider ovh \
+    --zone-id example.com
+
+  # Send RFC 2136 dynamic updates signed with a TSIG key
+  # (secret from RFC2136_TSIG_SECRET)
+  homeport cutover --bundle migration.hprt --dns-provider rfc2136 \
+    --zone-id example.com --dns-server ns1.example.com --tsig-key homeport
+
+  # Write Traefik weighted routes of traffic shifts to a custom directory
+  homeport cutover --bundle migration.hprt --traefik-dir /opt/stack/traefik/dynamic
+
   # Manual mode (generate instructions without executing)
   homeport cutover --bundle migration.hprt --manual
 
   # Rollback a previous cutover
   homeport cutover --bundle migration.hprt --rollback
 
+  # List persisted cutovers, including those interrupted by a crash
+  homeport cutover --list
+
+  # Resume an interrupted cutover from the step it stopped at
+  homeport cutover --resume cutover-20240102-150405
+
+  # Roll back a persisted cutover using the DNS values it recorded
+  homeport cutover --id cutover-20240102-150405 --rollback
+
   # Skip pre-cutover health checks
   homeport cutover --bundle migration.hprt --skip-pre-check`,
 	RunE: runCutover,
@@ -74,21 +106,50 @@ func init() {
 
 	cutoverCmd.Flags().StringVarP(&cutoverBundlePath, "bundle", "b", "", "path to .hprt bundle file (required)")
 	cutoverCmd.Flags().BoolVar(&cutoverDryRun, "dry-run", false, "simulate cutover without making changes")
-	cutoverCmd.Flags().StringVar(&cutoverDNSProvider, "dns-provider", "manual", "DNS provider (manual, cloudflare, route53)")
+	cutoverCmd.Flags().StringVar(&cutoverDNSProvider, "dns-provider", "manual", "DNS provider (manual, cloudflare, route53, hetzner, ovh, scaleway, gandi, rfc2136)")
 	cutoverCmd.Flags().BoolVar(&cutoverManual, "manual", false, "generate manual instructions instead of executing")
 	cutoverCmd.Flags().BoolVar(&cutoverRollback, "rollback", false, "rollback a previous cutover")
 	cutoverCmd.Flags().DurationVar(&cutoverTimeout, "timeout", 30*time.Minute, "maximum time for cutover execution")
 	cutoverCmd.Flags().BoolVar(&cutoverSkipPreCheck, "skip-pre-check", false, "skip pre-cutover health checks")
 	cutoverCmd.Flags().StringVar(&cutoverAPIToken, "api-token", "", "DNS provider API token")
-	cutoverCmd.Flags().StringVar(&cutoverZoneID, "zone-id", "", "DNS zone ID (Cloudflare zone ID or Route53 hosted zone ID)")
-
-	_ = cutoverCmd.MarkFlagRequired("bundle")
+	cutoverCmd.Flags().StringVar(&cutoverZoneID, "zone-id", "", "DNS zone ID (Cloudflare, Route53 or Hetzner) or zone name (OVH, Scaleway, Gandi or RFC 2136)")
+	cutoverCmd.Flags().StringVar(&cutoverDNSServer, "dns-server", "", "name server receiving RFC 2136 dynamic updates")
+	cutoverCmd.Flags().StringVar(&cutoverTSIGKey, "tsig-key", "", "TSIG key name for RFC 2136 dynamic updates")
+	cutoverCmd.Flags().StringVar(&cutoverTraefikDir, "traefik-dir", "traefik/dynamic", "Traefik dynamic configuration directory for traffic shift routes")
+	cutoverCmd.Flags().BoolVar(&cutoverList, "list", false, "list persisted cutovers")
+	cutoverCmd.Flags().StringVar(&cutoverResumeID, "resume", "", "resume an interrupted cutover by ID")
+	cutoverCmd.Flags().StringVar(&cutoverID, "id", "", "persisted cutover to roll back with --rollback")
 }
 
 func runCutover(cmd *cobra.Command, args []string) error {
+	store, journal, err := openCutoverStore()
+	if err != nil {
+		return err
+	}
+
+	if cutoverList {
+		return listCutovers(store)
+	}
+
+	switch {
+	case cutoverID != "" && !cutoverRollback:
+		return fmt.Errorf("--id is used with --rollback")
+	case cutoverResumeID != "" && cutoverRollback:
+		return fmt.Errorf("--resume cannot be combined with --rollback, use --id to roll back")
+	case cutoverBundlePath == "" && cutoverResumeID == "" && cutoverID == "":
+		return fmt.Errorf("required flag \"bundle\" not set")
+	}
+
 	if !IsQuiet() {
 		ui.Header("Homeport - Migration Cutover")
-		ui.Info(fmt.Sprintf("Bundle: %s", cutoverBundlePath))
+		switch {
+		case cutoverResumeID != "":
+			ui.Info(fmt.Sprintf("Resuming cutover: %s", cutoverResumeID))
+		case cutoverID != "":
+			ui.Info(fmt.Sprintf("Cutover: %s", cutoverID))
+		default:
+			ui.Info(fmt.Sprintf("Bundle: %s", cutoverBundlePath))
+		}
 		if cutoverDryRun {
 			ui.Warning("DRY RUN MODE - No changes will be made")
 		}
@@ -101,18 +162,40 @@ func runCutover(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), cutoverTimeout)
 	defer cancel()
 
-	// Step 1: Load the bundle
+	warnInterruptedCutovers(store)
+
+	// Step 1: Load the bundle, or the persisted cutover
 	if !IsQuiet() {
 		fmt.Println(ui.SimpleProgress(1, 5, "Loading bundle"))
 	}
 
-	plan, err := loadCutoverPlan(cutoverBundlePath)
+	exec, err := loadCutoverExecution(store)
 	if err != nil {
-		return fmt.Errorf("failed to load cutover plan: %w", err)
+		return err
+	}
+	plan := exec.Plan
+
+	// Reuse the provider the cutover was started with unless another is given
+	if exec.DNSProvider != "" && !cmd.Flags().Changed("dns-provider") {
+		cutoverDNSProvider = exec.DNSProvider
+	}
+	exec.DNSProvider = cutoverDNSProvider
+	exec.Claim()
+
+	// Dry runs and manual instructions change nothing, so there is nothing to
+	// resume or roll back later
+	persist := !cutoverDryRun && !cutoverManual
+	saveExecution := func() {
+		if !persist {
+			return
+		}
+		if err := store.Save(exec); err != nil {
+			ui.Warning(fmt.Sprintf("Failed to persist cutover state: %v", err))
+		}
 	}
 
 	if IsVerbose() {
-		ui.Info(fmt.Sprintf("Loaded cutover plan with %d DNS changes", len(plan.DNSChanges)))
+		ui.Info(fmt.Sprintf("Loaded cutover plan with %d DNS changes and %d traffic shifts", len(plan.DNSChanges), len(plan.TrafficShifts)))
 		ui.Info(fmt.Sprintf("Pre-checks: %d, Post-checks: %d", len(plan.PreChecks), len(plan.PostChecks)))
 	}
 
@@ -136,23 +219,33 @@ func runCutover(cmd *cobra.Command, args []string) error {
 	orchestrator := infraCutover.NewOrchestrator()
 
 	// Register DNS providers
-	if err := setupDNSProviders(orchestrator); err != nil {
+	if err := setupDNSProviders(ctx, orchestrator); err != nil {
 		return fmt.Errorf("failed to set up DNS provider: %w", err)
 	}
 
 	// Build orchestrator options
 	opts := &infraCutover.OrchestratorOptions{
-		DryRun:      cutoverDryRun,
-		DNSProvider: cutoverDNSProvider,
-		Manual:      cutoverManual,
-		Timeout:     cutoverTimeout,
-		Verbose:     IsVerbose(),
+		DryRun:           cutoverDryRun,
+		DNSProvider:      cutoverDNSProvider,
+		Manual:           cutoverManual,
+		TraefikConfigDir: cutoverTraefikDir,
+		Timeout:          cutoverTimeout,
+		Verbose:          IsVerbose(),
+		Journal:          journal.Record,
+		Checkpoint: func(*cutover.CutoverPlan) error {
+			if !persist {
+				return nil
+			}
+			return store.Save(exec)
+		},
 		OnStepStart: func(step *cutover.CutoverStep) {
+			saveExecution()
 			if IsVerbose() {
 				ui.Info(fmt.Sprintf("Starting: %s", step.Description))
 			}
 		},
 		OnStepComplete: func(step *cutover.CutoverStep) {
+			saveExecution()
 			switch step.Status {
 			case cutover.CutoverStepStatusCompleted:
 				if IsVerbose() {
@@ -169,12 +262,6 @@ func runCutover(cmd *cobra.Command, args []string) error {
 		},
 	}
 
-	// Skip pre-checks if requested
-	if cutoverSkipPreCheck {
-		plan.PreChecks = nil
-		plan.BuildSteps()
-	}
-
 	// Step 4: Execute cutover (or rollback)
 	if !IsQuiet() {
 		if cutoverRollback {
@@ -188,6 +275,7 @@ func runCutover(cmd *cobra.Command, args []string) error {
 
 	if cutoverRollback {
 		err = orchestrator.Rollback(ctx, plan, opts)
+		saveExecution()
 		if err != nil {
 			return fmt.Errorf("rollback failed: %w", err)
 		}
@@ -198,7 +286,11 @@ func runCutover(cmd *cobra.Command, args []string) error {
 		}
 	} else {
 		result, err = orchestrator.Execute(ctx, plan, opts)
+		saveExecution()
 		if err != nil {
+			if persist && plan.CanRollback() {
+				ui.Info(fmt.Sprintf("Roll back with: homeport cutover --id %s --rollback", plan.ID))
+			}
 			return fmt.Errorf("cutover failed: %w", err)
 		}
 	}
@@ -213,6 +305,112 @@ func runCutover(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// openCutoverStore opens the store of persisted cutovers and the journal of
+// DNS mutations.
+func openCutoverStore() (*appcutover.Store, *cutover.FileJournal, error) {
+	store, err := appcutover.NewStore("")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open cutover store: %w", err)
+	}
+	journalPath, err := appcutover.DefaultJournalPath()
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, cutover.NewFileJournal(journalPath), nil
+}
+
+// loadCutoverExecution returns the execution the command works on: a
+// persisted one for --resume and --id, or a new one from the bundle.
+func loadCutoverExecution(store *appcutover.Store) (*appcutover.CutoverExecution, error) {
+	switch {
+	case cutoverResumeID != "":
+		exec, err := store.Get(cutoverResumeID)
+		if err != nil {
+			return nil, err
+		}
+		if !exec.Plan.CanResume() {
+			return nil, fmt.Errorf("cutover %s is %s, only interrupted cutovers can be resumed", exec.Plan.ID, exec.Plan.Status)
+		}
+		return exec, nil
+
+	case cutoverID != "":
+		exec, err := store.Get(cutoverID)
+		if err != nil {
+			return nil, err
+		}
+		// Running cutovers belong to a process that is still alive
+		if exec.Plan.Status == cutover.CutoverStatusRunning || !exec.Plan.CanRollback() {
+			return nil, fmt.Errorf("cutover %s is %s and cannot be rolled back", exec.Plan.ID, exec.Plan.Status)
+		}
+		return exec, nil
+	}
+
+	plan, err := loadCutoverPlan(cutoverBundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cutover plan: %w", err)
+	}
+
+	// Skip pre-checks if requested
+	if cutoverSkipPreCheck {
+		plan.PreChecks = nil
+		plan.BuildSteps()
+	}
+
+	return appcutover.NewExecution(plan, cutoverDNSProvider), nil
+}
+
+// warnInterruptedCutovers marks cutovers left running by a process that has
+// stopped as interrupted, and tells the user how to finish them.
+func warnInterruptedCutovers(store *appcutover.Store) {
+	interrupted, err := store.Recover()
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Failed to check for interrupted cutovers: %v", err))
+		return
+	}
+
+	for _, exec := range interrupted {
+		if exec.Plan.ID == cutoverResumeID || exec.Plan.ID == cutoverID {
+			continue
+		}
+		ui.Warning(fmt.Sprintf("Cutover %s was interrupted. Resume it with --resume %s or roll it back with --id %s --rollback", exec.Plan.ID, exec.Plan.ID, exec.Plan.ID))
+	}
+}
+
+// listCutovers prints the persisted cutovers.
+func listCutovers(store *appcutover.Store) error {
+	if _, err := store.Recover(); err != nil {
+		return fmt.Errorf("failed to check for interrupted cutovers: %w", err)
+	}
+
+	executions, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(executions) == 0 {
+		ui.Info("No persisted cutovers")
+		return nil
+	}
+
+	rows := make([][]string, 0, len(executions))
+	for _, exec := range executions {
+		completed := 0
+		for _, step := range exec.Plan.Steps {
+			if step.Status == cutover.CutoverStepStatusCompleted {
+				completed++
+			}
+		}
+		rows = append(rows, []string{
+			exec.Plan.ID,
+			exec.Plan.Status.DisplayName(),
+			fmt.Sprintf("%d/%d", completed, len(exec.Plan.Steps)),
+			exec.DNSProvider,
+			exec.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	ui.PrintTable([]string{"ID", "Status", "Steps", "DNS Provider", "Updated"}, rows)
+	return nil
+}
+
 // loadCutoverPlan loads a cutover plan from a bundle file.
 func loadCutoverPlan(bundlePath string) (*cutover.CutoverPlan, error) {
 	// Check if the bundle file exists
@@ -281,6 +479,20 @@ func buildCutoverPlanFromBundle(bundle *domainBundle.Bundle) (*cutover.CutoverPl
 		}
 	}
 
+	// Load traffic shifts from bundle
+	if file, ok := bundle.GetFile("dns/traffic-shifts.json"); ok {
+		var shifts []*cutover.TrafficShift
+		if err := json.Unmarshal(file.Content, &shifts); err != nil {
+			return nil, fmt.Errorf("failed to parse traffic shifts: %w", err)
+		}
+		for _, shift := range shifts {
+			if len(shift.Stages) == 0 {
+				shift.Stages = append([]int(nil), cutover.DefaultTrafficShiftStages...)
+			}
+			plan.AddTrafficShift(shift)
+		}
+	}
+
 	// Load health check endpoints from bundle
 	if file, ok := bundle.GetFile("validation/endpoints.json"); ok {
 		var endpoints []struct {
@@ -329,7 +541,7 @@ func buildCutoverPlanFromBundle(bundle *domainBundle.Bundle) (*cutover.CutoverPl
 	}
 
 	// For local deployments without DNS changes, create localhost-based health checks instead
-	if len(plan.DNSChanges) == 0 {
+	if len(plan.DNSChanges) == 0 && len(plan.TrafficShifts) == 0 {
 		if isLocalDeployment {
 			// For local deployment, add localhost health checks instead of DNS changes
 			// Add a pre-check for Traefik
@@ -397,8 +609,9 @@ func buildCutoverPlanFromBundle(bundle *domainBundle.Bundle) (*cutover.CutoverPl
 	return plan, nil
 }
 
-// setupDNSProviders configures DNS providers based on command flags.
-func setupDNSProviders(orchestrator *infraCutover.Orchestrator) error {
+// setupDNSProviders configures DNS providers based on command flags and
+// checks the credentials of the requested one.
+func setupDNSProviders(ctx context.Context, orchestrator *infraCutover.Orchestrator) error {
 	// Always register manual provider
 	orchestrator.RegisterDNSProvider("manual", dns.NewManualProvider())
 
@@ -413,6 +626,14 @@ func setupDNSProviders(orchestrator *infraCutover.Orchestrator) error {
 			}
 		case "route53":
 			// AWS uses environment credentials
+		case "hetzner":
+			apiToken = os.Getenv("HETZNER_DNS_API_TOKEN")
+		case "ovh":
+			apiToken = os.Getenv("OVH_CONSUMER_KEY")
+		case "scaleway":
+			apiToken = os.Getenv("SCW_SECRET_KEY")
+		case "gandi":
+			apiToken = os.Getenv("GANDI_PERSONAL_ACCESS_TOKEN")
 		}
 	}
 
@@ -427,13 +648,26 @@ func setupDNSProviders(orchestrator *infraCutover.Orchestrator) error {
 			}
 		case "route53":
 			zoneID = os.Getenv("AWS_HOSTED_ZONE_ID")
+		case "hetzner":
+			zoneID = os.Getenv("HETZNER_DNS_ZONE_ID")
+		case "ovh":
+			zoneID = os.Getenv("OVH_DNS_ZONE")
+		case "scaleway":
+			zoneID = os.Getenv("SCW_DNS_ZONE")
+		case "gandi":
+			zoneID = os.Getenv("GANDI_DNS_ZONE")
+		case "rfc2136":
+			zoneID = os.Getenv("RFC2136_ZONE")
 		}
 	}
 
 	// Register requested provider
+	var provider cutover.DNSProvider
 	switch cutoverDNSProvider {
 	case "manual":
 		// Already registered
+		return nil
+
 	case "cloudflare":
 		if apiToken == "" {
 			return fmt.Errorf("cloudflare requires --api-token or CLOUDFLARE_API_TOKEN environment variable")
@@ -441,29 +675,118 @@ func setupDNSProviders(orchestrator *infraCutover.Orchestrator) error {
 		if zoneID == "" {
 			return fmt.Errorf("cloudflare requires --zone-id or CLOUDFLARE_ZONE_ID environment variable")
 		}
-		provider := dns.NewCloudflareProvider(&dns.CloudflareConfig{
+		provider = dns.NewCloudflareProvider(&dns.CloudflareConfig{
 			APIToken: apiToken,
 			ZoneID:   zoneID,
 		})
-		orchestrator.RegisterDNSProvider("cloudflare", provider)
 
 	case "route53":
 		if zoneID == "" {
 			return fmt.Errorf("route53 requires --zone-id or AWS_HOSTED_ZONE_ID environment variable")
 		}
-		provider := dns.NewRoute53Provider(&dns.Route53Config{
+		provider = dns.NewRoute53Provider(&dns.Route53Config{
 			HostedZoneID:    zoneID,
 			Region:          os.Getenv("AWS_REGION"),
 			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
 			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
 			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Endpoint:        os.Getenv("AWS_ENDPOINT_URL_ROUTE_53"),
+		})
+
+	case "hetzner":
+		if apiToken == "" {
+			return fmt.Errorf("hetzner requires --api-token or HETZNER_DNS_API_TOKEN environment variable")
+		}
+		if zoneID == "" {
+			return fmt.Errorf("hetzner requires --zone-id or HETZNER_DNS_ZONE_ID environment variable")
+		}
+		provider = dns.NewHetznerProvider(&dns.HetznerConfig{
+			APIToken: apiToken,
+			ZoneID:   zoneID,
+		})
+
+	case "ovh":
+		applicationKey := os.Getenv("OVH_APPLICATION_KEY")
+		applicationSecret := os.Getenv("OVH_APPLICATION_SECRET")
+		if applicationKey == "" || applicationSecret == "" {
+			return fmt.Errorf("ovh requires OVH_APPLICATION_KEY and OVH_APPLICATION_SECRET environment variables")
+		}
+		if apiToken == "" {
+			return fmt.Errorf("ovh requires --api-token or OVH_CONSUMER_KEY environment variable")
+		}
+		if zoneID == "" {
+			return fmt.Errorf("ovh requires --zone-id or OVH_DNS_ZONE environment variable")
+		}
+		provider = dns.NewOVHProvider(&dns.OVHConfig{
+			Zone:              zoneID,
+			ApplicationKey:    applicationKey,
+			ApplicationSecret: applicationSecret,
+			ConsumerKey:       apiToken,
+			Endpoint:          os.Getenv("OVH_ENDPOINT"),
+		})
+
+	case "scaleway":
+		if apiToken == "" {
+			return fmt.Errorf("scaleway requires --api-token or SCW_SECRET_KEY environment variable")
+		}
+		if zoneID == "" {
+			return fmt.Errorf("scaleway requires --zone-id or SCW_DNS_ZONE environment variable")
+		}
+		provider = dns.NewScalewayProvider(&dns.ScalewayConfig{
+			SecretKey: apiToken,
+			Zone:      zoneID,
+		})
+
+	case "gandi":
+		if apiToken == "" {
+			return fmt.Errorf("gandi requires --api-token or GANDI_PERSONAL_ACCESS_TOKEN environment variable")
+		}
+		if zoneID == "" {
+			return fmt.Errorf("gandi requires --zone-id or GANDI_DNS_ZONE environment variable")
+		}
+		provider = dns.NewGandiProvider(&dns.GandiConfig{
+			PersonalAccessToken: apiToken,
+			Zone:                zoneID,
+		})
+
+	case "rfc2136":
+		server := cutoverDNSServer
+		if server == "" {
+			server = os.Getenv("RFC2136_NAMESERVER")
+		}
+		tsigKey := cutoverTSIGKey
+		if tsigKey == "" {
+			tsigKey = os.Getenv("RFC2136_TSIG_KEY")
+		}
+		tsigSecret := os.Getenv("RFC2136_TSIG_SECRET")
+		if server == "" {
+			return fmt.Errorf("rfc2136 requires --dns-server or RFC2136_NAMESERVER environment variable")
+		}
+		if zoneID == "" {
+			return fmt.Errorf("rfc2136 requires --zone-id or RFC2136_ZONE environment variable")
+		}
+		if tsigKey != "" && tsigSecret == "" {
+			return fmt.Errorf("rfc2136 requires RFC2136_TSIG_SECRET environment variable when a TSIG key is set")
+		}
+		provider = dns.NewRFC2136Provider(&dns.RFC2136Config{
+			Server:        server,
+			Zone:          zoneID,
+			TSIGKeyName:   tsigKey,
+			TSIGSecret:    tsigSecret,
+			TSIGAlgorithm: os.Getenv("RFC2136_TSIG_ALGORITHM"),
 		})
-		orchestrator.RegisterDNSProvider("route53", provider)
 
 	default:
-		return fmt.Errorf("unsupported DNS provider: %s (supported: manual, cloudflare, route53)", cutoverDNSProvider)
+		return fmt.Errorf("unsupported DNS provider: %s (supported: %s)", cutoverDNSProvider, strings.Join(dns.SupportedProviders(), ", "))
+	}
+
+	if !cutoverManual {
+		if err := provider.ValidateCredentials(ctx); err != nil {
+			return fmt.Errorf("%s: %w", cutoverDNSProvider, err)
+		}
 	}
 
+	orchestrator.RegisterDNSProvider(cutoverDNSProvider, provider)
 	return nil
 }
 
@@ -536,6 +859,35 @@ func displayCutoverResults(result *infraCutover.ExecutionResult) {
 		fmt.Println(table.Render())
 	}
 
+	// Show traffic shifts summary
+	if len(result.Plan.TrafficShifts) > 0 {
+		fmt.Println()
+		fmt.Println("Traffic Shifts:")
+		table := ui.NewTable([]string{"Domain", "Stages", "Current", "Last Stage"})
+		for _, shift := range result.Plan.TrafficShifts {
+			stages := make([]string, len(shift.Stages))
+			for i, percent := range shift.Stages {
+				stages[i] = fmt.Sprintf("%d%%", percent)
+			}
+			lastStage := "-"
+			if n := len(shift.StageResults); n > 0 {
+				stage := shift.StageResults[n-1]
+				if stage.Passed {
+					lastStage = fmt.Sprintf("%d%% passed (%.2f%% errors)", stage.Percent, stage.ErrorRate)
+				} else {
+					lastStage = fmt.Sprintf("%d%% failed", stage.Percent)
+				}
+			}
+			table.AddRow([]string{
+				shift.FullName(),
+				strings.Join(stages, " → "),
+				fmt.Sprintf("%d%%", shift.CurrentPercent),
+				lastStage,
+			})
+		}
+		fmt.Println(table.Render())
+	}
+
 	// Show logs
 	if IsVerbose() && len(result.Logs) > 0 {
 		fmt.Println()
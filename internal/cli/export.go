@@ -19,6 +19,7 @@ import (
 	"github.com/homeport/homeport/internal/infrastructure/secrets/detector"
 	"github.com/homeport/homeport/pkg/version"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	infraBundle "github.com/homeport/homeport/internal/infrastructure/bundle"
 
@@ -73,7 +74,10 @@ Examples:
   homeport export --source ./terraform --domain example.com -o migration.hprt
 
   # Export from specific provider with region
-  homeport export --source ./terraform --provider aws --region us-east-1 -o migration.hprt`,
+  homeport export --source ./terraform --provider aws --region us-east-1 -o migration.hprt
+
+  # Stream the bundle to stdout, e.g. straight to the target server
+  homeport export --source ./terraform -o - | ssh host 'cat > migration.hprt'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if exportSource == "" {
 			return fmt.Errorf("--source is required: specify path to terraform files, state, or cloud config")
@@ -83,8 +87,11 @@ Examples:
 			return fmt.Errorf("--output (-o) is required: specify output .hprt file path")
 		}
 
-		// Ensure .hprt extension
-		if !strings.HasSuffix(exportOutput, ".hprt") {
+		if exportOutput == "-" {
+			// Progress output would corrupt the bundle stream.
+			viper.Set("quiet", true)
+		} else if !strings.HasSuffix(exportOutput, ".hprt") {
+			// Ensure .hprt extension
 			exportOutput = exportOutput + ".hprt"
 		}
 
@@ -128,7 +135,7 @@ Examples:
 func init() {
 	rootCmd.AddCommand(exportCmd)
 
-	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "output .hprt bundle file path (required)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "output .hprt bundle file path, or - for stdout (required)")
 	exportCmd.Flags().StringVarP(&exportSource, "source", "s", "", "source path (terraform files, state, or cloud config)")
 	exportCmd.Flags().BoolVar(&exportConsolidate, "consolidate", false, "consolidate similar resources into unified stacks")
 	exportCmd.Flags().BoolVar(&exportDetectSecrets, "detect-secrets", false, "detect and create secret references")
@@ -262,6 +269,13 @@ func performExport() error {
 		DetectSecrets:  exportDetectSecrets,
 	}
 
+	if exportOutput == "-" {
+		if err := exporter.ExportTo(tempDir, opts, os.Stdout); err != nil {
+			return fmt.Errorf("failed to create bundle: %w", err)
+		}
+		return nil
+	}
+
 	if err := exporter.Export(tempDir, opts); err != nil {
 		return fmt.Errorf("failed to create bundle: %w", err)
 	}
@@ -335,12 +349,13 @@ func mapResourcesForExport(ctx context.Context, analysis *AnalysisResult) ([]*ma
 		}
 
 		res := &resource.AWSResource{
-			Type:   resType,
-			Name:   resSummary.Name,
-			ID:     resSummary.ID,
-			Region: resSummary.Region,
-			Tags:   resSummary.Tags,
-			Config: make(map[string]interface{}),
+			Type:         resType,
+			Name:         resSummary.Name,
+			ID:           resSummary.ID,
+			Region:       resSummary.Region,
+			Tags:         resSummary.Tags,
+			Config:       make(map[string]interface{}),
+			Dependencies: resSummary.Dependencies,
 		}
 
 		result, err := m.Map(ctx, res)
@@ -352,6 +367,7 @@ func mapResourcesForExport(ctx context.Context, analysis *AnalysisResult) ([]*ma
 		}
 
 		if result != nil {
+			result.SourceResource = res
 			result.SourceResourceType = string(resType)
 			result.SourceResourceName = resSummary.Name
 			results = append(results, result)
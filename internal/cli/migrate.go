@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,7 +19,16 @@ import (
 	"github.com/homeport/homeport/internal/domain/stack"
 	"github.com/homeport/homeport/internal/domain/target"
 	"github.com/homeport/homeport/internal/infrastructure/consolidator"
+	outputwriter "github.com/homeport/homeport/internal/infrastructure/generator/writer"
+
+	// Register the cloud generators
+	_ "github.com/homeport/homeport/internal/infrastructure/generator/exoscale"
+	_ "github.com/homeport/homeport/internal/infrastructure/generator/hetzner"
+	_ "github.com/homeport/homeport/internal/infrastructure/generator/infomaniak"
+	_ "github.com/homeport/homeport/internal/infrastructure/generator/ovh"
+	_ "github.com/homeport/homeport/internal/infrastructure/generator/scaleway"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
@@ -35,6 +45,8 @@ var (
 	migrateInstanceType   string
 	migrateSSL            bool
 	migrateStrictClickBam bool
+	migrateOutputFormat   string
+	migrateForce          bool
 )
 
 // migrateCmd represents the migrate command
@@ -82,11 +94,33 @@ Examples:
   homeport migrate ./terraform --provider scaleway --region fr-par-1 --ha-level cluster
 
   # Migrate with custom instance type
-  homeport migrate ./terraform --provider ovh --instance-type b2-15 --ssl=true`,
+  homeport migrate ./terraform --provider ovh --instance-type b2-15 --ssl=true
+
+  # Write a reproducible archive instead of a directory
+  homeport migrate ./terraform --output-format tar.gz -o stack.tar.gz
+
+  # Pipe the generated stack into another tool
+  homeport migrate ./terraform --output-format tar.gz -o - | ssh host tar -xzf - -C /srv/stack`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		inputPath := args[0]
 
+		format, err := outputwriter.ParseFormat(migrateOutputFormat)
+		if err != nil {
+			return err
+		}
+		out, err := outputwriter.New(format, migrateOutput, os.Stdout)
+		if err != nil {
+			return err
+		}
+		if dirWriter, ok := out.(*outputwriter.DirWriter); ok {
+			dirWriter.Force = migrateForce
+		}
+		if migrateOutput == outputwriter.Stdout {
+			// Progress output would corrupt the artifact stream.
+			viper.Set("quiet", true)
+		}
+
 		if !IsQuiet() {
 			ui.Header("Homeport - Infrastructure Migration")
 			ui.Info(fmt.Sprintf("Input: %s", inputPath))
@@ -115,16 +149,29 @@ Examples:
 			return fmt.Errorf("input path does not exist: %s", inputPath)
 		}
 
-		// Create output directory
-		if err := os.MkdirAll(migrateOutput, 0755); err != nil {
-			return fmt.Errorf("failed to create output directory: %w", err)
+		// Generate into a staging directory, then publish it in one step
+		stagingDir, err := os.MkdirTemp("", "homeport-migrate-*")
+		if err != nil {
+			return fmt.Errorf("failed to create staging directory: %w", err)
 		}
+		defer func() { _ = os.RemoveAll(stagingDir) }()
 
 		// Perform migration
-		if err := performMigration(inputPath); err != nil {
+		if err := performMigration(inputPath, stagingDir, migrateOutput); err != nil {
 			return fmt.Errorf("migration failed: %w", err)
 		}
 
+		files, err := outputwriter.ReadDir(stagingDir)
+		if err != nil {
+			return err
+		}
+		if err := out.Write(files); err != nil {
+			if errors.Is(err, outputwriter.ErrForeignOutput) {
+				return fmt.Errorf("failed to write output: %w; move them away or rerun with --force to replace the directory", err)
+			}
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+
 		if !IsQuiet() {
 			ui.Divider()
 			ui.Success("Migration completed successfully")
@@ -149,18 +196,21 @@ func init() {
 	migrateCmd.Flags().BoolVar(&migrateConsolidate, "consolidate", false, "consolidate similar resources into unified stacks (reduces container count)")
 
 	// Provider flags (Sprint 7)
-	migrateCmd.Flags().StringVar(&migrateProvider, "provider", "", "target cloud provider (hetzner, scaleway, ovh)")
+	migrateCmd.Flags().StringVar(&migrateProvider, "provider", "", "target cloud provider (hetzner, scaleway, ovh, exoscale, infomaniak)")
 	migrateCmd.Flags().StringVar(&migrateRegion, "region", "", "provider-specific region (e.g., fsn1, fr-par-1, gra)")
 	migrateCmd.Flags().StringVar(&migrateHALevel, "ha-level", "basic", "high availability level (none, basic, multi-server, cluster)")
 	migrateCmd.Flags().StringVar(&migrateInstanceType, "instance-type", "", "override default instance type selection")
 	migrateCmd.Flags().BoolVar(&migrateSSL, "ssl", true, "enable SSL/TLS for services")
 	migrateCmd.Flags().BoolVar(&migrateStrictClickBam, "strict-click-bam", false, "fail if migration requires unresolved guided or manual steps")
+	migrateCmd.Flags().StringVar(&migrateOutputFormat, "output-format", "dir", "output format (dir, tar.gz, zip, stream); use -o - to write archives to stdout")
+	migrateCmd.Flags().BoolVar(&migrateForce, "force", false, "replace an output directory that holds files homeport did not generate")
 }
 
 // MigrationConfig represents the configuration for migration
 type MigrationConfig struct {
 	InputPath         string
 	OutputPath        string
+	OutputName        string // output the user asked for when OutputPath is a staging directory
 	Domain            string
 	IncludeMigration  bool
 	IncludeMonitoring bool
@@ -174,11 +224,27 @@ type MigrationConfig struct {
 	StrictClickBam bool
 }
 
-// performMigration performs the actual migration
-func performMigration(inputPath string) error {
+// outputName returns the output the user asked for, falling back to the
+// path the stack is written to. A stack streamed to stdout is named after
+// the default output directory.
+func (c *MigrationConfig) outputName() string {
+	switch c.OutputName {
+	case "":
+		return c.OutputPath
+	case outputwriter.Stdout:
+		return "output"
+	}
+	return c.OutputName
+}
+
+// performMigration performs the actual migration, writing the generated
+// stack into outputDir. outputName is the output the user asked for, which
+// generated files refer to instead of the staging directory.
+func performMigration(inputPath, outputDir, outputName string) error {
 	config := &MigrationConfig{
 		InputPath:         inputPath,
-		OutputPath:        migrateOutput,
+		OutputPath:        outputDir,
+		OutputName:        outputName,
 		Domain:            migrateDomain,
 		IncludeMigration:  migrateIncludeMigration,
 		IncludeMonitoring: migrateIncludeMonitoring,
@@ -249,7 +315,7 @@ func performMigration(inputPath string) error {
 	return nil
 }
 
-// performProviderMigration generates output for a specific cloud provider (Hetzner, Scaleway, OVH)
+// performProviderMigration generates output for a specific cloud provider (Hetzner, Scaleway, OVH, Exoscale, Infomaniak)
 func performProviderMigration(config *MigrationConfig, analysis *AnalysisResult) error {
 	// Parse HA level
 	haLevel := target.HALevelBasic
@@ -270,8 +336,12 @@ func performProviderMigration(config *MigrationConfig, analysis *AnalysisResult)
 		platform = target.PlatformScaleway
 	case "ovh":
 		platform = target.PlatformOVH
+	case "exoscale":
+		platform = target.PlatformExoscale
+	case "infomaniak":
+		platform = target.PlatformInfomaniak
 	default:
-		return fmt.Errorf("unsupported provider: %s (valid: hetzner, scaleway, ovh)", config.Provider)
+		return fmt.Errorf("unsupported provider: %s (valid: hetzner, scaleway, ovh, exoscale, infomaniak)", config.Provider)
 	}
 
 	// Get the generator
@@ -311,12 +381,13 @@ func performProviderMigration(config *MigrationConfig, analysis *AnalysisResult)
 		}
 
 		res := &resource.AWSResource{
-			Type:   resType,
-			Name:   resSummary.Name,
-			ID:     resSummary.ID,
-			Region: resSummary.Region,
-			Tags:   resSummary.Tags,
-			Config: make(map[string]interface{}),
+			Type:         resType,
+			Name:         resSummary.Name,
+			ID:           resSummary.ID,
+			Region:       resSummary.Region,
+			Tags:         resSummary.Tags,
+			Config:       make(map[string]interface{}),
+			Dependencies: resSummary.Dependencies,
 		}
 
 		result, err := m.Map(ctx, res)
@@ -350,7 +421,7 @@ func performProviderMigration(config *MigrationConfig, analysis *AnalysisResult)
 
 	genConfig := generator.NewTargetConfig(platform)
 	genConfig.WithHALevel(haLevel)
-	genConfig.WithOutputDir(config.OutputPath)
+	genConfig.WithOutputDir(config.outputName())
 	genConfig.WithSSL(config.SSL)
 	genConfig.WithMonitoring(config.IncludeMonitoring)
 	genConfig.WithBackups(true)
@@ -609,12 +680,13 @@ func generateDockerCompose(config *MigrationConfig, analysis *AnalysisResult) er
 
 		// Build a resource for the mapper
 		res := &resource.AWSResource{
-			Type:   resType,
-			Name:   resSummary.Name,
-			ID:     resSummary.ID,
-			Region: resSummary.Region,
-			Tags:   resSummary.Tags,
-			Config: make(map[string]interface{}),
+			Type:         resType,
+			Name:         resSummary.Name,
+			ID:           resSummary.ID,
+			Region:       resSummary.Region,
+			Tags:         resSummary.Tags,
+			Config:       make(map[string]interface{}),
+			Dependencies: resSummary.Dependencies,
 		}
 
 		// Map the resource
@@ -738,12 +810,13 @@ func generateConsolidatedDockerCompose(config *MigrationConfig, analysis *Analys
 
 		// Build a resource for the mapper
 		res := &resource.AWSResource{
-			Type:   resType,
-			Name:   resSummary.Name,
-			ID:     resSummary.ID,
-			Region: resSummary.Region,
-			Tags:   resSummary.Tags,
-			Config: make(map[string]interface{}),
+			Type:         resType,
+			Name:         resSummary.Name,
+			ID:           resSummary.ID,
+			Region:       resSummary.Region,
+			Tags:         resSummary.Tags,
+			Config:       make(map[string]interface{}),
+			Dependencies: resSummary.Dependencies,
 		}
 
 		// Map the resource
@@ -757,6 +830,7 @@ func generateConsolidatedDockerCompose(config *MigrationConfig, analysis *Analys
 
 		if result != nil {
 			// Preserve source resource info for consolidation
+			result.SourceResource = res
 			result.SourceResourceType = string(resType)
 			result.SourceResourceName = resSummary.Name
 			mappingResults = append(mappingResults, result)
@@ -1484,7 +1558,7 @@ func generateDocumentation(config *MigrationConfig, analysis *AnalysisResult) er
 	readmeBuilder.WriteString("- Check MIGRATION_NOTES.md for service-specific warnings and manual steps\n\n")
 	readmeBuilder.WriteString("## File Structure\n\n")
 	readmeBuilder.WriteString("```\n")
-	readmeBuilder.WriteString(fmt.Sprintf("%s/\n", filepath.Base(config.OutputPath)))
+	readmeBuilder.WriteString(fmt.Sprintf("%s/\n", filepath.Base(config.outputName())))
 	readmeBuilder.WriteString("├── docker-compose.yml    # Main Docker Compose configuration\n")
 	readmeBuilder.WriteString("├── .env.example          # Environment variables template\n")
 	readmeBuilder.WriteString("├── traefik/\n")
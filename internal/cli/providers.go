@@ -203,7 +203,7 @@ var providersCompareCmd = &cobra.Command{
 	Long: `Compare estimated costs across different EU providers.
 
 This command analyzes your infrastructure and estimates costs for
-deploying on each available provider (Hetzner, Scaleway, OVH).
+deploying on each available provider (Hetzner, Scaleway, OVH, Exoscale, Infomaniak).
 
 The comparison can be done from:
   - An analysis.json file (from 'homeport analyze' command)
@@ -542,6 +542,10 @@ func providerToPlatform(p provider.Provider) (target.Platform, bool) {
 		return target.PlatformScaleway, true
 	case provider.ProviderOVH:
 		return target.PlatformOVH, true
+	case provider.ProviderExoscale:
+		return target.PlatformExoscale, true
+	case provider.ProviderInfomaniak:
+		return target.PlatformInfomaniak, true
 	default:
 		return "", false
 	}
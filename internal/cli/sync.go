@@ -499,6 +499,11 @@ func runOneTimeSync(ctx context.Context, plan *sync.SyncPlan) error {
 	return nil
 }
 
+// continuousStrategies maps strategies to their change streaming variant
+var continuousStrategies = map[string]string{
+	"postgres": "postgres-replication",
+}
+
 // runContinuousSync performs continuous synchronization (CDC mode)
 func runContinuousSync(ctx context.Context, plan *sync.SyncPlan) error {
 	if !IsQuiet() {
@@ -508,6 +513,14 @@ func runContinuousSync(ctx context.Context, plan *sync.SyncPlan) error {
 
 	registry := syncinfra.NewDefaultRegistry()
 
+	// Strategies that stream changes replace those that copy everything on
+	// each cycle; later cycles then only wait for the target to catch up
+	for _, task := range plan.Tasks {
+		if strategy, ok := continuousStrategies[task.Strategy]; ok {
+			task.Strategy = strategy
+		}
+	}
+
 	// For continuous sync, we loop until cancelled
 	ticker := time.NewTicker(5 * time.Second) // Check interval
 	defer ticker.Stop()
@@ -519,6 +532,7 @@ func runContinuousSync(ctx context.Context, plan *sync.SyncPlan) error {
 			if !IsQuiet() {
 				ui.Info(fmt.Sprintf("Continuous sync stopped after %d sync cycles", syncCount))
 			}
+			completeReplication(registry, plan)
 			return nil
 		case <-ticker.C:
 			syncCount++
@@ -528,7 +542,8 @@ func runContinuousSync(ctx context.Context, plan *sync.SyncPlan) error {
 
 			for _, task := range plan.Tasks {
 				if ctx.Err() != nil {
-					return nil
+					// Stop through the ctx.Done case so replication is completed
+					break
 				}
 
 				if err := runSyncTask(ctx, registry, task); err != nil {
@@ -540,6 +555,36 @@ func runContinuousSync(ctx context.Context, plan *sync.SyncPlan) error {
 	}
 }
 
+// replicationCompleteTimeout bounds how long stopping a continuous sync waits
+// for replicating targets to catch up before they are promoted.
+const replicationCompleteTimeout = 5 * time.Minute
+
+// completeReplication ends the replication of streaming tasks when continuous
+// sync stops: each target catches up with its source and is promoted, and the
+// publication and replication slot are dropped from the source.
+func completeReplication(registry *sync.StrategyRegistry, plan *sync.SyncPlan) {
+	for _, task := range plan.Tasks {
+		strategy, ok := registry.Get(task.Strategy).(sync.ReplicatingStrategy)
+		if !ok || task.Source == nil || task.Target == nil {
+			continue
+		}
+		if !IsQuiet() {
+			ui.Info(fmt.Sprintf("Promoting %s once it has caught up with its source...", task.Name))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), replicationCompleteTimeout)
+		err := sync.CompleteReplication(ctx, strategy, task.Source, task.Target, nil)
+		cancel()
+		if err != nil {
+			ui.Error(fmt.Sprintf("Failed to promote %s: %v", task.Name, err))
+			continue
+		}
+		if !IsQuiet() {
+			ui.Success(fmt.Sprintf("Promoted %s, replication stopped", task.Name))
+		}
+	}
+}
+
 // runVerifyOnly verifies data without syncing
 func runVerifyOnly(ctx context.Context, plan *sync.SyncPlan) error {
 	if !IsQuiet() {
@@ -383,12 +383,13 @@ func wizardStep2ReviewResources(state *WizardState) error {
 		}
 
 		res := &resource.AWSResource{
-			Type:   resType,
-			Name:   resSummary.Name,
-			ID:     resSummary.ID,
-			Region: resSummary.Region,
-			Tags:   resSummary.Tags,
-			Config: make(map[string]interface{}),
+			Type:         resType,
+			Name:         resSummary.Name,
+			ID:           resSummary.ID,
+			Region:       resSummary.Region,
+			Tags:         resSummary.Tags,
+			Config:       make(map[string]interface{}),
+			Dependencies: resSummary.Dependencies,
 		}
 
 		result, err := m.Map(ctx, res)
@@ -397,6 +398,7 @@ func wizardStep2ReviewResources(state *WizardState) error {
 		}
 
 		if result != nil {
+			result.SourceResource = res
 			result.SourceResourceType = string(resType)
 			result.SourceResourceName = resSummary.Name
 			state.MappingResults = append(state.MappingResults, result)
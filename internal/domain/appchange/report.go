@@ -16,13 +16,23 @@ type Change struct {
 	Reason        string `json:"reason"`
 	AdapterURL    string `json:"adapter_url,omitempty"`
 	File          string `json:"file,omitempty"`
+	StartLine     int    `json:"start_line,omitempty"`
+	EndLine       int    `json:"end_line,omitempty"`
 	Search        string `json:"search,omitempty"`
 	Replace       string `json:"replace,omitempty"`
 	ValidationCmd string `json:"validation_cmd,omitempty"`
 }
 
+// Patch is the unified diff applying every generated-patch change of one
+// file.
+type Patch struct {
+	File string `json:"file"`
+	Diff string `json:"diff"`
+}
+
 type Report struct {
 	Changes []Change `json:"changes"`
+	Patches []Patch  `json:"patches,omitempty"`
 }
 
 func (r Report) RequiresAction() bool {
@@ -25,6 +25,10 @@ const (
 
 	// CutoverStatusFailed indicates the cutover failed without rollback.
 	CutoverStatusFailed CutoverStatus = "failed"
+
+	// CutoverStatusInterrupted indicates the process running the cutover
+	// stopped before it finished. It can be resumed or rolled back.
+	CutoverStatusInterrupted CutoverStatus = "interrupted"
 )
 
 // allCutoverStatuses contains all valid cutover statuses for iteration.
@@ -34,6 +38,7 @@ var allCutoverStatuses = []CutoverStatus{
 	CutoverStatusCompleted,
 	CutoverStatusRolledBack,
 	CutoverStatusFailed,
+	CutoverStatusInterrupted,
 }
 
 // AllCutoverStatuses returns all valid cutover statuses.
@@ -76,6 +81,8 @@ func (s CutoverStatus) DisplayName() string {
 		return "Rolled Back"
 	case CutoverStatusFailed:
 		return "Failed"
+	case CutoverStatusInterrupted:
+		return "Interrupted"
 	default:
 		return string(s)
 	}
@@ -91,6 +98,9 @@ const (
 	// CutoverStepTypeDNSChange indicates a DNS record change.
 	CutoverStepTypeDNSChange CutoverStepType = "dns_change"
 
+	// CutoverStepTypeTrafficShift indicates a staged traffic shift.
+	CutoverStepTypeTrafficShift CutoverStepType = "traffic_shift"
+
 	// CutoverStepTypePostCheck indicates a post-cutover health check.
 	CutoverStepTypePostCheck CutoverStepType = "post_check"
 
@@ -110,6 +120,8 @@ func (t CutoverStepType) DisplayName() string {
 		return "Pre-Cutover Check"
 	case CutoverStepTypeDNSChange:
 		return "DNS Change"
+	case CutoverStepTypeTrafficShift:
+		return "Traffic Shift"
 	case CutoverStepTypePostCheck:
 		return "Post-Cutover Check"
 	case CutoverStepTypeRollback:
@@ -168,6 +180,10 @@ type CutoverPlan struct {
 	// These redirect traffic from cloud infrastructure to self-hosted.
 	DNSChanges []*DNSChange `json:"dns_changes"`
 
+	// TrafficShifts move traffic to self-hosted in stages after the DNS
+	// changes, with gates between stages.
+	TrafficShifts []*TrafficShift `json:"traffic_shifts,omitempty"`
+
 	// PostChecks are health checks to run after DNS changes propagate.
 	// Failed post-checks may trigger automatic rollback.
 	PostChecks []*HealthCheck `json:"post_checks"`
@@ -217,7 +233,7 @@ type CutoverStep struct {
 	// Order is the execution order of this step (1-indexed).
 	Order int `json:"order"`
 
-	// Type identifies the kind of step (pre_check, dns_change, post_check).
+	// Type identifies the kind of step (pre_check, dns_change, traffic_shift, post_check).
 	Type CutoverStepType `json:"type"`
 
 	// Description explains what this step does.
@@ -226,7 +242,8 @@ type CutoverStep struct {
 	// Status is the current state of this step.
 	Status CutoverStepStatus `json:"status"`
 
-	// ReferenceID links to the specific check or change (HealthCheck.ID or DNSChange.ID).
+	// ReferenceID links to the specific check, change or shift (HealthCheck.ID,
+	// DNSChange.ID or TrafficShift.ID).
 	ReferenceID string `json:"reference_id,omitempty"`
 
 	// StartedAt is when this step started executing.
@@ -253,6 +270,7 @@ func NewCutoverPlan(id, bundleID string) *CutoverPlan {
 		BundleID:           bundleID,
 		PreChecks:          make([]*HealthCheck, 0),
 		DNSChanges:         make([]*DNSChange, 0),
+		TrafficShifts:      make([]*TrafficShift, 0),
 		PostChecks:         make([]*HealthCheck, 0),
 		RollbackTriggers:   make([]*RollbackTrigger, 0),
 		Steps:              make([]*CutoverStep, 0),
@@ -276,6 +294,12 @@ func (p *CutoverPlan) AddDNSChange(change *DNSChange) {
 	p.UpdatedAt = time.Now()
 }
 
+// AddTrafficShift adds a staged traffic shift to the cutover plan.
+func (p *CutoverPlan) AddTrafficShift(shift *TrafficShift) {
+	p.TrafficShifts = append(p.TrafficShifts, shift)
+	p.UpdatedAt = time.Now()
+}
+
 // AddPostCheck adds a health check to run after cutover.
 func (p *CutoverPlan) AddPostCheck(check *HealthCheck) {
 	p.PostChecks = append(p.PostChecks, check)
@@ -288,8 +312,8 @@ func (p *CutoverPlan) AddRollbackTrigger(trigger *RollbackTrigger) {
 	p.UpdatedAt = time.Now()
 }
 
-// BuildSteps creates the ordered list of steps from checks and DNS changes.
-// Should be called after all pre-checks, DNS changes, and post-checks are added.
+// BuildSteps creates the ordered list of steps from checks, DNS changes and
+// traffic shifts. Should be called after all of them are added.
 func (p *CutoverPlan) BuildSteps() {
 	p.Steps = make([]*CutoverStep, 0)
 	order := 1
@@ -319,6 +343,18 @@ func (p *CutoverPlan) BuildSteps() {
 		order++
 	}
 
+	// Add traffic shifts
+	for _, shift := range p.TrafficShifts {
+		p.Steps = append(p.Steps, &CutoverStep{
+			Order:       order,
+			Type:        CutoverStepTypeTrafficShift,
+			Description: shift.Description(),
+			Status:      CutoverStepStatusPending,
+			ReferenceID: shift.ID,
+		})
+		order++
+	}
+
 	// Add post-checks
 	for _, check := range p.PostChecks {
 		p.Steps = append(p.Steps, &CutoverStep{
@@ -359,16 +395,22 @@ func (p *CutoverPlan) Progress() float64 {
 	return float64(p.CompletedSteps()) / float64(total) * 100
 }
 
+// CanResume returns true if an interrupted cutover can continue.
+func (p *CutoverPlan) CanResume() bool {
+	return p.Status == CutoverStatusInterrupted
+}
+
 // CanStart returns true if the cutover plan can be started.
 func (p *CutoverPlan) CanStart() bool {
-	return p.Status == CutoverStatusPending && len(p.DNSChanges) > 0
+	return p.Status == CutoverStatusPending && (len(p.DNSChanges) > 0 || len(p.TrafficShifts) > 0)
 }
 
 // CanRollback returns true if the cutover can be rolled back.
 func (p *CutoverPlan) CanRollback() bool {
 	return p.Status == CutoverStatusRunning ||
 		p.Status == CutoverStatusCompleted ||
-		p.Status == CutoverStatusFailed
+		p.Status == CutoverStatusFailed ||
+		p.Status == CutoverStatusInterrupted
 }
 
 // HasAutoRollback returns true if any rollback trigger is set to auto-rollback.
@@ -393,8 +435,8 @@ func (p *CutoverPlan) Validate() []string {
 		errors = append(errors, "bundle ID is required")
 	}
 
-	if len(p.DNSChanges) == 0 {
-		errors = append(errors, "at least one DNS change is required")
+	if len(p.DNSChanges) == 0 && len(p.TrafficShifts) == 0 {
+		errors = append(errors, "at least one DNS change or traffic shift is required")
 	}
 
 	if p.Timeout <= 0 {
@@ -411,6 +453,10 @@ func (p *CutoverPlan) Validate() []string {
 		}
 	}
 
+	for _, shift := range p.TrafficShifts {
+		errors = append(errors, shift.Validate()...)
+	}
+
 	// Validate each health check
 	for _, check := range p.PreChecks {
 		if errs := check.Validate(); len(errs) > 0 {
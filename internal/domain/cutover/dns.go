@@ -73,6 +73,22 @@ const (
 	// DNSProviderDigitalOcean is DigitalOcean DNS.
 	DNSProviderDigitalOcean DNSProviderType = "digitalocean"
 
+	// DNSProviderHetzner is Hetzner DNS.
+	DNSProviderHetzner DNSProviderType = "hetzner"
+
+	// DNSProviderOVH is OVHcloud DNS.
+	DNSProviderOVH DNSProviderType = "ovh"
+
+	// DNSProviderScaleway is Scaleway Domains and DNS.
+	DNSProviderScaleway DNSProviderType = "scaleway"
+
+	// DNSProviderGandi is Gandi LiveDNS.
+	DNSProviderGandi DNSProviderType = "gandi"
+
+	// DNSProviderRFC2136 is any DNS server accepting RFC 2136 dynamic
+	// updates, such as BIND, Knot or PowerDNS.
+	DNSProviderRFC2136 DNSProviderType = "rfc2136"
+
 	// DNSProviderManual indicates manual DNS changes (no API).
 	DNSProviderManual DNSProviderType = "manual"
 )
@@ -95,6 +111,16 @@ func (p DNSProviderType) DisplayName() string {
 		return "Azure DNS"
 	case DNSProviderDigitalOcean:
 		return "DigitalOcean"
+	case DNSProviderHetzner:
+		return "Hetzner DNS"
+	case DNSProviderOVH:
+		return "OVHcloud"
+	case DNSProviderScaleway:
+		return "Scaleway"
+	case DNSProviderGandi:
+		return "Gandi LiveDNS"
+	case DNSProviderRFC2136:
+		return "RFC 2136 dynamic update"
 	case DNSProviderManual:
 		return "Manual"
 	default:
@@ -149,6 +175,28 @@ type DNSChange struct {
 	// ProxyEnabled indicates if Cloudflare proxy is enabled (Cloudflare-specific).
 	ProxyEnabled bool `json:"proxy_enabled,omitempty"`
 
+	// Alias points the record at another resource instead of NewValue
+	// (Route 53-specific).
+	Alias *DNSAliasTarget `json:"alias,omitempty"`
+
+	// OldAlias is the alias target the record had before the change (for rollback).
+	OldAlias *DNSAliasTarget `json:"old_alias,omitempty"`
+
+	// SetIdentifier distinguishes records of the same name and type that share
+	// traffic through weighted routing (Route 53-specific).
+	SetIdentifier string `json:"set_identifier,omitempty"`
+
+	// RoutingWeight is the share of traffic the record gets among records of
+	// the same name and type (Route 53-specific, nil for simple routing).
+	RoutingWeight *int64 `json:"routing_weight,omitempty"`
+
+	// Previous is the live record read from the provider before the change
+	// was applied, which a rollback restores. It is nil if no record existed.
+	Previous *DNSRecord `json:"previous,omitempty"`
+
+	// CapturedAt is when Previous was read from the provider.
+	CapturedAt *time.Time `json:"captured_at,omitempty"`
+
 	// Status tracks the change status (pending, applied, rolled_back).
 	Status DNSChangeStatus `json:"status"`
 
@@ -162,6 +210,20 @@ type DNSChange struct {
 	Error string `json:"error,omitempty"`
 }
 
+// DNSAliasTarget is the target of an alias record, which resolves to the
+// addresses of another resource such as a load balancer or a CDN
+// distribution.
+type DNSAliasTarget struct {
+	// HostedZoneID is the hosted zone of the target resource.
+	HostedZoneID string `json:"hosted_zone_id"`
+
+	// DNSName is the DNS name of the target resource.
+	DNSName string `json:"dns_name"`
+
+	// EvaluateTargetHealth makes the record inherit the health of the target.
+	EvaluateTargetHealth bool `json:"evaluate_target_health,omitempty"`
+}
+
 // DNSChangeStatus represents the status of a DNS change.
 type DNSChangeStatus string
 
@@ -214,7 +276,7 @@ func (c *DNSChange) IsApplied() bool {
 
 // CanRollback returns true if the change can be rolled back.
 func (c *DNSChange) CanRollback() bool {
-	return c.Status == DNSChangeStatusApplied && c.OldValue != ""
+	return c.Status == DNSChangeStatusApplied && (c.OldValue != "" || c.OldAlias != nil || c.CapturedAt != nil)
 }
 
 // Validate checks if the DNS change is valid.
@@ -237,7 +299,7 @@ func (c *DNSChange) Validate() []string {
 		errors = append(errors, "name is required (use @ for root)")
 	}
 
-	if c.NewValue == "" {
+	if c.NewValue == "" && c.Alias == nil {
 		errors = append(errors, "new value is required")
 	}
 
@@ -298,6 +360,15 @@ type DNSRecord struct {
 	// ProxyEnabled indicates if Cloudflare proxy is enabled.
 	ProxyEnabled bool `json:"proxy_enabled,omitempty"`
 
+	// Alias is the target of an alias record (Route 53-specific).
+	Alias *DNSAliasTarget `json:"alias,omitempty"`
+
+	// SetIdentifier distinguishes weighted records of the same name and type.
+	SetIdentifier string `json:"set_identifier,omitempty"`
+
+	// RoutingWeight is the weight of a weighted record.
+	RoutingWeight *int64 `json:"routing_weight,omitempty"`
+
 	// CreatedAt is when the record was created.
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 
@@ -328,6 +399,9 @@ func (r *DNSRecord) ToChange(id, newValue string) *DNSChange {
 		Port:             r.Port,
 		ProviderRecordID: r.ID,
 		ProxyEnabled:     r.ProxyEnabled,
+		OldAlias:         r.Alias,
+		SetIdentifier:    r.SetIdentifier,
+		RoutingWeight:    r.RoutingWeight,
 		Status:           DNSChangeStatusPending,
 	}
 }
@@ -357,6 +431,23 @@ type DNSProvider interface {
 	ValidateCredentials(ctx context.Context) error
 }
 
+// WeightedDNSProvider is implemented by DNS providers that split traffic
+// between records of the same name and type by their routing weight, told
+// apart by their set identifier.
+type WeightedDNSProvider interface {
+	DNSProvider
+
+	// SupportsWeightedRecords reports whether weighted records can be used.
+	SupportsWeightedRecords() bool
+
+	// ChangeRecords deletes the records of deletes and creates or updates
+	// the records of upserts in one atomic change, so traffic never goes
+	// through a state in between. Records are matched by name, type and
+	// set identifier; deleting a record that does not exist is not an
+	// error.
+	ChangeRecords(ctx context.Context, deletes, upserts []*DNSChange) error
+}
+
 // DNSProviderConfig contains configuration for a DNS provider.
 type DNSProviderConfig struct {
 	// Type is the provider type.
@@ -371,9 +462,17 @@ type DNSProviderConfig struct {
 	// APIToken is an API token (alternative to key/secret).
 	APIToken string `json:"api_token,omitempty"`
 
-	// ZoneID is the zone identifier (Cloudflare, etc.).
+	// ZoneID is the zone identifier (Cloudflare, etc.), or the zone name for
+	// providers that address zones by name (OVH, Scaleway, Gandi, RFC 2136).
 	ZoneID string `json:"zone_id,omitempty"`
 
+	// Endpoint is the API endpoint (OVH) or the name server receiving
+	// dynamic updates (RFC 2136).
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// TSIGAlgorithm is the TSIG algorithm for RFC 2136 updates.
+	TSIGAlgorithm string `json:"tsig_algorithm,omitempty"`
+
 	// Region is the AWS region (for Route 53).
 	Region string `json:"region,omitempty"`
 
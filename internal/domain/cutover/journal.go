@@ -0,0 +1,158 @@
+package cutover
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DNSMutationAction describes why a DNS record was changed.
+type DNSMutationAction string
+
+const (
+	// DNSMutationApply applies a DNS change of a cutover plan.
+	DNSMutationApply DNSMutationAction = "apply"
+
+	// DNSMutationRollback reverts a DNS change of a cutover plan.
+	DNSMutationRollback DNSMutationAction = "rollback"
+
+	// DNSMutationWeight sets the routing weight of a traffic shift record.
+	DNSMutationWeight DNSMutationAction = "weight"
+)
+
+// DNSMutationStatus is the outcome of a DNS mutation.
+type DNSMutationStatus string
+
+const (
+	// DNSMutationStarted is recorded before the provider is called, so an
+	// interrupted mutation is visible in the journal.
+	DNSMutationStarted DNSMutationStatus = "started"
+
+	// DNSMutationSucceeded is recorded after the provider accepted the change.
+	DNSMutationSucceeded DNSMutationStatus = "succeeded"
+
+	// DNSMutationFailed is recorded after the provider rejected the change.
+	DNSMutationFailed DNSMutationStatus = "failed"
+)
+
+// DNSMutation is a journal entry for one change to a DNS record.
+type DNSMutation struct {
+	Time          time.Time         `json:"time"`
+	PlanID        string            `json:"plan_id"`
+	ChangeID      string            `json:"change_id"`
+	Action        DNSMutationAction `json:"action"`
+	Status        DNSMutationStatus `json:"status"`
+	Provider      string            `json:"provider"`
+	Name          string            `json:"name"`
+	RecordType    string            `json:"record_type"`
+	OldValue      string            `json:"old_value,omitempty"`
+	NewValue      string            `json:"new_value,omitempty"`
+	TTL           int               `json:"ttl,omitempty"`
+	SetIdentifier string            `json:"set_identifier,omitempty"`
+	RoutingWeight *int64            `json:"routing_weight,omitempty"`
+	Previous      *DNSRecord        `json:"previous,omitempty"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// NewDNSMutation creates a started journal entry for a DNS change.
+func NewDNSMutation(planID string, action DNSMutationAction, provider string, change *DNSChange) DNSMutation {
+	return DNSMutation{
+		Time:          time.Now().UTC(),
+		PlanID:        planID,
+		ChangeID:      change.ID,
+		Action:        action,
+		Status:        DNSMutationStarted,
+		Provider:      provider,
+		Name:          change.FullName(),
+		RecordType:    change.RecordType,
+		OldValue:      change.OldValue,
+		NewValue:      change.NewValue,
+		TTL:           change.TTL,
+		SetIdentifier: change.SetIdentifier,
+		RoutingWeight: change.RoutingWeight,
+		Previous:      change.Previous,
+	}
+}
+
+// Finished returns a copy of the entry recording the outcome of the mutation.
+func (m DNSMutation) Finished(err error) DNSMutation {
+	m.Time = time.Now().UTC()
+	m.Status = DNSMutationSucceeded
+	if err != nil {
+		m.Status = DNSMutationFailed
+		m.Error = err.Error()
+	}
+	return m
+}
+
+// FileJournal is an append-only JSON lines journal of DNS mutations.
+type FileJournal struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileJournal creates a journal that appends to the file at path.
+func NewFileJournal(path string) *FileJournal {
+	return &FileJournal{path: path}
+}
+
+// Record appends a mutation to the journal and syncs it to disk.
+func (j *FileJournal) Record(mutation DNSMutation) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := json.NewEncoder(file).Encode(mutation); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Mutations returns the journaled mutations of a plan in the order they were
+// recorded, or every mutation when planID is empty.
+func (j *FileJournal) Mutations(planID string) ([]DNSMutation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var mutations []DNSMutation
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var mutation DNSMutation
+		if err := json.Unmarshal([]byte(line), &mutation); err != nil {
+			return nil, err
+		}
+		if planID == "" || mutation.PlanID == planID {
+			mutations = append(mutations, mutation)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return mutations, nil
+}
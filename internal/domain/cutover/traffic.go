@@ -0,0 +1,323 @@
+package cutover
+
+import (
+	"fmt"
+	"time"
+)
+
+// TrafficShiftMethod represents how traffic is split between the old and the
+// new infrastructure during a traffic shift.
+type TrafficShiftMethod string
+
+const (
+	// TrafficShiftMethodAuto uses weighted DNS records when the DNS provider
+	// supports them and the Traefik method otherwise.
+	TrafficShiftMethodAuto TrafficShiftMethod = ""
+
+	// TrafficShiftMethodDNS splits traffic with weighted DNS records.
+	TrafficShiftMethodDNS TrafficShiftMethod = "dns_weighted"
+
+	// TrafficShiftMethodTraefik splits traffic with a Traefik weighted
+	// round robin service that proxies a share of requests back to the old
+	// cloud endpoint.
+	TrafficShiftMethodTraefik TrafficShiftMethod = "traefik"
+)
+
+// String returns the string representation of the traffic shift method.
+func (m TrafficShiftMethod) String() string {
+	return string(m)
+}
+
+// IsValid checks if the traffic shift method is recognized.
+func (m TrafficShiftMethod) IsValid() bool {
+	switch m {
+	case TrafficShiftMethodAuto, TrafficShiftMethodDNS, TrafficShiftMethodTraefik:
+		return true
+	default:
+		return false
+	}
+}
+
+// DisplayName returns a human-friendly display name for the method.
+func (m TrafficShiftMethod) DisplayName() string {
+	switch m {
+	case TrafficShiftMethodAuto:
+		return "Automatic"
+	case TrafficShiftMethodDNS:
+		return "Weighted DNS"
+	case TrafficShiftMethodTraefik:
+		return "Traefik Weighted Round Robin"
+	default:
+		return string(m)
+	}
+}
+
+// DefaultTrafficShiftStages are the percentages of traffic sent to the new
+// infrastructure at each stage when none are given.
+var DefaultTrafficShiftStages = []int{5, 25, 50, 100}
+
+// TrafficShift moves traffic from the old to the new infrastructure in
+// stages. After each stage, its gate checks run and the error rate is
+// measured; when a gate fails, traffic goes back to the old infrastructure.
+//
+// With weighted DNS, the old and new records share a name and type and are
+// told apart by their set identifiers. A simple record of that name and type
+// is replaced by the weighted records in the first stage and restored on
+// rollback. With Traefik, DNS must point at the new Traefik, whose generated
+// weighted service proxies the rest of the traffic to LegacyURL.
+type TrafficShift struct {
+	// ID is the unique identifier for this traffic shift.
+	ID string `json:"id"`
+
+	// Name is a human-readable name for the traffic shift.
+	Name string `json:"name,omitempty"`
+
+	// Method selects how traffic is split.
+	Method TrafficShiftMethod `json:"method,omitempty"`
+
+	// Stages are the percentages of traffic sent to the new infrastructure,
+	// in increasing order and ending at 100.
+	Stages []int `json:"stages"`
+
+	// StageDuration is how long each stage is observed before the next one.
+	StageDuration time.Duration `json:"stage_duration"`
+
+	// SampleInterval is how often gate checks run during a stage.
+	SampleInterval time.Duration `json:"sample_interval"`
+
+	// GateChecks must pass at the end of every stage.
+	GateChecks []*HealthCheck `json:"gate_checks,omitempty"`
+
+	// MaxErrorRate is the highest error rate, in percent, a stage may
+	// observe. Zero disables the error rate gate.
+	MaxErrorRate float64 `json:"max_error_rate,omitempty"`
+
+	// MetricsURL is a Prometheus metrics endpoint, such as Traefik's, that
+	// the error rate is read from. Without it, the error rate is the share
+	// of failed gate check samples.
+	MetricsURL string `json:"metrics_url,omitempty"`
+
+	// MetricsService is the service label the error rate is read for. It
+	// defaults to TraefikService.
+	MetricsService string `json:"metrics_service,omitempty"`
+
+	// Domain is the root domain of the shifted record (e.g., "example.com").
+	Domain string `json:"domain"`
+
+	// RecordName is the subdomain or @ for root (e.g., "www").
+	RecordName string `json:"record_name"`
+
+	// RecordType is the type of the weighted records (weighted DNS only).
+	RecordType string `json:"record_type,omitempty"`
+
+	// TTL is the time-to-live of the weighted records (weighted DNS only).
+	TTL int `json:"ttl,omitempty"`
+
+	// NewValue is the record value pointing at the new infrastructure
+	// (weighted DNS only).
+	NewValue string `json:"new_value,omitempty"`
+
+	// OldValue is the record value pointing at the old infrastructure
+	// (weighted DNS only).
+	OldValue string `json:"old_value,omitempty"`
+
+	// NewSetIdentifier and OldSetIdentifier tell the weighted records apart
+	// (weighted DNS only). They default to "homeport" and "legacy".
+	NewSetIdentifier string `json:"new_set_identifier,omitempty"`
+	OldSetIdentifier string `json:"old_set_identifier,omitempty"`
+
+	// TraefikService is the Traefik service of the new infrastructure
+	// (e.g., "app@docker") (Traefik only).
+	TraefikService string `json:"traefik_service,omitempty"`
+
+	// LegacyURL is the old cloud endpoint the rest of the traffic is
+	// proxied to (Traefik only).
+	LegacyURL string `json:"legacy_url,omitempty"`
+
+	// OriginalRecord is the simple record the weighted records replaced,
+	// kept to restore it on rollback (weighted DNS only).
+	OriginalRecord *DNSRecord `json:"original_record,omitempty"`
+
+	// CurrentPercent is the share of traffic currently sent to the new
+	// infrastructure.
+	CurrentPercent int `json:"current_percent"`
+
+	// StageResults records the outcome of every stage run so far.
+	StageResults []*TrafficShiftStageResult `json:"stage_results,omitempty"`
+}
+
+// TrafficShiftStageResult is the outcome of one traffic shift stage.
+type TrafficShiftStageResult struct {
+	// Percent is the share of traffic the stage sent to the new
+	// infrastructure.
+	Percent int `json:"percent"`
+
+	// StartedAt is when traffic was shifted to this stage.
+	StartedAt time.Time `json:"started_at"`
+
+	// CompletedAt is when the stage's gates were evaluated.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// Requests is the number of requests or samples the error rate was
+	// measured over.
+	Requests int64 `json:"requests"`
+
+	// Errors is the number of failed requests or samples.
+	Errors int64 `json:"errors"`
+
+	// ErrorRate is the observed error rate in percent.
+	ErrorRate float64 `json:"error_rate"`
+
+	// Passed indicates if the stage's gates passed.
+	Passed bool `json:"passed"`
+
+	// Error explains why a gate failed.
+	Error string `json:"error,omitempty"`
+}
+
+// NewTrafficShift creates a TrafficShift with the default stages, a five
+// minute observation per stage and a 5% error rate gate.
+func NewTrafficShift(id, domain, recordName string) *TrafficShift {
+	stages := make([]int, len(DefaultTrafficShiftStages))
+	copy(stages, DefaultTrafficShiftStages)
+	return &TrafficShift{
+		ID:             id,
+		Stages:         stages,
+		StageDuration:  5 * time.Minute,
+		SampleInterval: 30 * time.Second,
+		MaxErrorRate:   5,
+		Domain:         domain,
+		RecordName:     recordName,
+		GateChecks:     make([]*HealthCheck, 0),
+	}
+}
+
+// FullName returns the fully qualified domain name of the shifted record.
+func (s *TrafficShift) FullName() string {
+	if s.RecordName == "@" || s.RecordName == "" {
+		return s.Domain
+	}
+	return s.RecordName + "." + s.Domain
+}
+
+// Description returns a short description of the traffic shift.
+func (s *TrafficShift) Description() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("Shift traffic for %s (%s)", s.FullName(), formatStages(s.Stages))
+}
+
+// NewIdentifier returns the set identifier of the new weighted record.
+func (s *TrafficShift) NewIdentifier() string {
+	if s.NewSetIdentifier == "" {
+		return "homeport"
+	}
+	return s.NewSetIdentifier
+}
+
+// OldIdentifier returns the set identifier of the old weighted record.
+func (s *TrafficShift) OldIdentifier() string {
+	if s.OldSetIdentifier == "" {
+		return "legacy"
+	}
+	return s.OldSetIdentifier
+}
+
+// ErrorRateService returns the service label the error rate is read for.
+func (s *TrafficShift) ErrorRateService() string {
+	if s.MetricsService == "" {
+		return s.TraefikService
+	}
+	return s.MetricsService
+}
+
+// HasWeightedRecords returns true if the weighted DNS records are configured.
+func (s *TrafficShift) HasWeightedRecords() bool {
+	return s.RecordType != "" && s.NewValue != "" && s.OldValue != "" && s.TTL > 0
+}
+
+// HasTraefikService returns true if the Traefik weighted service is
+// configured.
+func (s *TrafficShift) HasTraefikService() bool {
+	return s.TraefikService != "" && s.LegacyURL != ""
+}
+
+// Validate checks if the traffic shift is valid.
+func (s *TrafficShift) Validate() []string {
+	var errors []string
+
+	if s.ID == "" {
+		errors = append(errors, "traffic shift ID is required")
+	}
+
+	if s.Domain == "" {
+		errors = append(errors, "traffic shift domain is required")
+	}
+
+	if !s.Method.IsValid() {
+		errors = append(errors, "invalid traffic shift method: "+string(s.Method))
+	}
+
+	if len(s.Stages) == 0 {
+		errors = append(errors, "at least one traffic shift stage is required")
+	}
+	previous := 0
+	for _, percent := range s.Stages {
+		if percent <= previous || percent > 100 {
+			errors = append(errors, "traffic shift stages must increase from above 0 to at most 100")
+			break
+		}
+		previous = percent
+	}
+	if len(s.Stages) > 0 && s.Stages[len(s.Stages)-1] != 100 {
+		errors = append(errors, "the last traffic shift stage must be 100")
+	}
+
+	if s.StageDuration < 0 || s.SampleInterval < 0 {
+		errors = append(errors, "stage duration and sample interval must be non-negative")
+	}
+
+	if s.MaxErrorRate < 0 || s.MaxErrorRate > 100 {
+		errors = append(errors, "max error rate must be between 0 and 100")
+	}
+
+	switch s.Method {
+	case TrafficShiftMethodDNS:
+		if !s.HasWeightedRecords() {
+			errors = append(errors, "weighted DNS traffic shifts require record type, new value, old value and TTL")
+		}
+	case TrafficShiftMethodTraefik:
+		if !s.HasTraefikService() {
+			errors = append(errors, "Traefik traffic shifts require Traefik service and legacy URL")
+		}
+	case TrafficShiftMethodAuto:
+		if !s.HasWeightedRecords() && !s.HasTraefikService() {
+			errors = append(errors, "traffic shift requires weighted DNS records or a Traefik service")
+		}
+	}
+	if s.NewIdentifier() == s.OldIdentifier() {
+		errors = append(errors, "new and old set identifiers must differ")
+	}
+
+	if s.MaxErrorRate > 0 && s.MetricsURL != "" && s.ErrorRateService() == "" {
+		errors = append(errors, "metrics service is required to read the error rate")
+	}
+
+	for _, check := range s.GateChecks {
+		errors = append(errors, check.Validate()...)
+	}
+
+	return errors
+}
+
+func formatStages(stages []int) string {
+	result := ""
+	for i, percent := range stages {
+		if i > 0 {
+			result += " → "
+		}
+		result += fmt.Sprintf("%d%%", percent)
+	}
+	return result
+}
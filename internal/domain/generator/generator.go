@@ -142,18 +142,24 @@ func (o *Output) AddMetadata(key, value string) {
 	o.SetMetadata(key, value)
 }
 
-// WriteFiles writes all output files to the specified directory.
+// WriteFiles writes all output files to the specified directory, replacing
+// its previous contents. It uses the registered OutputWriter.
 func (o *Output) WriteFiles(basePath string) error {
-	// This will be implemented in the infrastructure layer
-	// as it involves file system operations
-	panic("not implemented: use infrastructure layer writer")
+	w, err := registeredOutputWriter()
+	if err != nil {
+		return err
+	}
+	return w.WriteFiles(basePath, o.Files)
 }
 
-// WriteTo writes all output files to the provided writer.
-// This is useful for testing or streaming output.
+// WriteTo streams all output files to the provided writer.
+// This is useful for testing or piping output into other tools.
 func (o *Output) WriteTo(w io.Writer) (int64, error) {
-	// This will be implemented in the infrastructure layer
-	panic("not implemented: use infrastructure layer writer")
+	ow, err := registeredOutputWriter()
+	if err != nil {
+		return 0, err
+	}
+	return ow.WriteTo(w, o.Files)
 }
 
 // HasWarnings returns true if there are any warnings.
@@ -501,9 +507,23 @@ func (o *TargetOutput) FileCount() int {
 	return len(o.Files)
 }
 
-// WriteTo writes all output files to the provided writer.
+// WriteFiles writes all output files to the specified directory, replacing
+// its previous contents. It uses the registered OutputWriter.
+func (o *TargetOutput) WriteFiles(basePath string) error {
+	w, err := registeredOutputWriter()
+	if err != nil {
+		return err
+	}
+	return w.WriteFiles(basePath, o.Files)
+}
+
+// WriteTo streams all output files to the provided writer.
 func (o *TargetOutput) WriteTo(w io.Writer) (int64, error) {
-	panic("not implemented: use infrastructure layer writer")
+	ow, err := registeredOutputWriter()
+	if err != nil {
+		return 0, err
+	}
+	return ow.WriteTo(w, o.Files)
 }
 
 // CostEstimate represents an estimated monthly cost.
@@ -543,6 +563,47 @@ func (c *CostEstimate) AddNote(note string) {
 	c.Notes = append(c.Notes, note)
 }
 
+// ─────────────────────────────────────────────────────────────────────────────
+// Output Writer
+// ─────────────────────────────────────────────────────────────────────────────
+
+// OutputWriter persists generated files, keyed by slash-separated relative
+// path. The implementation lives in the infrastructure layer, which
+// registers it with RegisterOutputWriter.
+type OutputWriter interface {
+	// WriteFiles writes files into the directory basePath, replacing its
+	// previous contents. It refuses to replace files it did not generate.
+	WriteFiles(basePath string, files map[string][]byte) error
+
+	// WriteTo streams files to w in a stable order.
+	WriteTo(w io.Writer, files map[string][]byte) (int64, error)
+}
+
+var (
+	outputWriterMu sync.RWMutex
+	outputWriter   OutputWriter
+)
+
+// RegisterOutputWriter sets the writer used by Output and TargetOutput.
+func RegisterOutputWriter(w OutputWriter) {
+	outputWriterMu.Lock()
+	defer outputWriterMu.Unlock()
+	outputWriter = w
+}
+
+func registeredOutputWriter() (OutputWriter, error) {
+	outputWriterMu.RLock()
+	defer outputWriterMu.RUnlock()
+	if outputWriter == nil {
+		return nil, ErrNoOutputWriter
+	}
+	return outputWriter, nil
+}
+
+// ErrNoOutputWriter is returned when output is written before an
+// OutputWriter has been registered.
+var ErrNoOutputWriter = errors.New("no output writer registered")
+
 // ─────────────────────────────────────────────────────────────────────────────
 // Generator Registry
 // ─────────────────────────────────────────────────────────────────────────────
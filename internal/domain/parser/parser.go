@@ -4,6 +4,7 @@ package parser
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	"github.com/homeport/homeport/internal/domain/resource"
 )
@@ -100,6 +101,12 @@ type ParseOptions struct {
 	// IgnoreErrors continues parsing even if some resources fail.
 	IgnoreErrors bool
 
+	// Variables overrides Terraform input variables, like -var on the CLI.
+	Variables map[string]string
+
+	// VarFiles lists additional Terraform .tfvars files, like -var-file.
+	VarFiles []string
+
 	// APICredentials for API-based parsing.
 	APICredentials map[string]string
 
@@ -163,6 +170,18 @@ func (o *ParseOptions) WithIgnoreErrors(ignore bool) *ParseOptions {
 	return o
 }
 
+// WithVariables sets Terraform variable overrides.
+func (o *ParseOptions) WithVariables(vars map[string]string) *ParseOptions {
+	o.Variables = vars
+	return o
+}
+
+// WithVarFiles sets additional Terraform variable files.
+func (o *ParseOptions) WithVarFiles(files ...string) *ParseOptions {
+	o.VarFiles = files
+	return o
+}
+
 // WithRegions sets regions to scan.
 func (o *ParseOptions) WithRegions(regions ...string) *ParseOptions {
 	o.Regions = regions
@@ -217,6 +236,32 @@ type ParseStats struct {
 	ErrorCount int
 }
 
+// MetadataModulesFollowed is the infrastructure metadata key parsers use to
+// report how many Terraform modules they followed.
+const MetadataModulesFollowed = "modules_followed"
+
+// NewParseStats computes parsing statistics for parsed infrastructure.
+func NewParseStats(infra *resource.Infrastructure) ParseStats {
+	stats := ParseStats{
+		ResourcesByType:     make(map[resource.Type]int),
+		ResourcesByCategory: make(map[resource.Category]int),
+	}
+	if infra == nil {
+		return stats
+	}
+
+	for _, res := range infra.Resources {
+		stats.ResourcesFound++
+		stats.ResourcesByType[res.Type]++
+		stats.ResourcesByCategory[res.Type.GetCategory()]++
+	}
+	if n, err := strconv.Atoi(infra.Metadata[MetadataModulesFollowed]); err == nil {
+		stats.ModulesFollowed = n
+	}
+
+	return stats
+}
+
 // Common parsing errors.
 var (
 	ErrNoFilesFound     = errors.New("no infrastructure files found")
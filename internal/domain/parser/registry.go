@@ -223,6 +223,11 @@ func (r *Registry) ParseMulti(ctx context.Context, path string, opts *ParseOptio
 		for id, res := range infra.Resources {
 			result.Resources[id] = res
 		}
+		for key, value := range infra.Metadata {
+			if _, exists := result.Metadata[key]; !exists {
+				result.Metadata[key] = value
+			}
+		}
 	}
 
 	return result, nil
@@ -1,5 +1,6 @@
 // Package provider defines cloud provider types, regions, metadata, and pricing catalogs
-// for both source (AWS, GCP, Azure) and target (Hetzner, Scaleway, OVH) providers.
+// for both source (AWS, GCP, Azure) and target (Hetzner, Scaleway, OVH, Exoscale,
+// Infomaniak) providers.
 package provider
 
 import (
@@ -17,8 +18,8 @@ var providerPricingCatalog = map[resource.Provider]*ProviderPricing{
 	// EU Self-Hosted Providers (Target Providers)
 	// ─────────────────────────────────────────────────────────────────────────
 
-	resource.Provider("hetzner"): {
-		Provider: resource.Provider("hetzner"),
+	resource.Provider(ProviderHetzner): {
+		Provider: resource.Provider(ProviderHetzner),
 		Instances: []InstancePricing{
 			// CX Series (Intel)
 			{Type: "cx11", VCPUs: 1, MemoryGB: 2, StorageGB: 20, PricePerMonth: 3.49, PricePerHour: 0.0049, Currency: "EUR"},
@@ -40,15 +41,15 @@ var providerPricingCatalog = map[resource.Provider]*ProviderPricing{
 		},
 		Network: NetworkPricing{
 			IngressFree:      true,
-			FreeEgressGB:     1024, // 1TB
+			FreeEgressGB:     1024,  // 1TB
 			EgressPricePerGB: 0.001, // €1.00/TB = €0.001/GB
 			Currency:         "EUR",
 		},
 		LastUpdated: catalogLastUpdated,
 	},
 
-	resource.Provider("scaleway"): {
-		Provider: resource.Provider("scaleway"),
+	resource.Provider(ProviderScaleway): {
+		Provider: resource.Provider(ProviderScaleway),
 		Instances: []InstancePricing{
 			// DEV1 Series (Development)
 			{Type: "DEV1-S", VCPUs: 2, MemoryGB: 2, StorageGB: 20, PricePerMonth: 7.99, PricePerHour: 0.0111, Currency: "EUR"},
@@ -74,8 +75,8 @@ var providerPricingCatalog = map[resource.Provider]*ProviderPricing{
 		LastUpdated: catalogLastUpdated,
 	},
 
-	resource.Provider("ovh"): {
-		Provider: resource.Provider("ovh"),
+	resource.Provider(ProviderOVH): {
+		Provider: resource.Provider(ProviderOVH),
 		Instances: []InstancePricing{
 			// S1 Series (Starter)
 			{Type: "s1-2", VCPUs: 1, MemoryGB: 2, StorageGB: 10, PricePerMonth: 5.49, PricePerHour: 0.0076, Currency: "EUR"},
@@ -101,6 +102,59 @@ var providerPricingCatalog = map[resource.Provider]*ProviderPricing{
 		LastUpdated: catalogLastUpdated,
 	},
 
+	resource.Provider(ProviderExoscale): {
+		Provider: resource.Provider(ProviderExoscale),
+		Instances: []InstancePricing{
+			// Standard Series
+			{Type: "standard.micro", VCPUs: 1, MemoryGB: 0.5, StorageGB: 10, PricePerMonth: 5.53, PricePerHour: 0.0077, Currency: "EUR"},
+			{Type: "standard.tiny", VCPUs: 1, MemoryGB: 1, StorageGB: 10, PricePerMonth: 9.86, PricePerHour: 0.0137, Currency: "EUR"},
+			{Type: "standard.small", VCPUs: 2, MemoryGB: 2, StorageGB: 10, PricePerMonth: 18.25, PricePerHour: 0.0253, Currency: "EUR"},
+			{Type: "standard.medium", VCPUs: 2, MemoryGB: 4, StorageGB: 10, PricePerMonth: 34.96, PricePerHour: 0.0486, Currency: "EUR"},
+			{Type: "standard.large", VCPUs: 4, MemoryGB: 8, StorageGB: 10, PricePerMonth: 69.93, PricePerHour: 0.0971, Currency: "EUR"},
+			{Type: "standard.extra-large", VCPUs: 4, MemoryGB: 16, StorageGB: 10, PricePerMonth: 139.86, PricePerHour: 0.1943, Currency: "EUR"},
+		},
+		Storage: StoragePricing{
+			Type:            "Block storage",
+			PricePerGBMonth: 0.10,
+			MinSizeGB:       10,
+			MaxSizeGB:       10240,
+			Currency:        "EUR",
+		},
+		Network: NetworkPricing{
+			IngressFree:      true,
+			FreeEgressGB:     0,
+			EgressPricePerGB: 0.02,
+			Currency:         "EUR",
+		},
+		LastUpdated: catalogLastUpdated,
+	},
+
+	resource.Provider(ProviderInfomaniak): {
+		Provider: resource.Provider(ProviderInfomaniak),
+		Instances: []InstancePricing{
+			// A Series (General Purpose)
+			{Type: "a1-ram2-disk20-perf1", VCPUs: 1, MemoryGB: 2, StorageGB: 20, PricePerMonth: 5.04, PricePerHour: 0.0070, Currency: "EUR"},
+			{Type: "a2-ram4-disk50-perf1", VCPUs: 2, MemoryGB: 4, StorageGB: 50, PricePerMonth: 11.52, PricePerHour: 0.0160, Currency: "EUR"},
+			{Type: "a4-ram8-disk80-perf1", VCPUs: 4, MemoryGB: 8, StorageGB: 80, PricePerMonth: 22.32, PricePerHour: 0.0310, Currency: "EUR"},
+			{Type: "a4-ram16-disk80-perf1", VCPUs: 4, MemoryGB: 16, StorageGB: 80, PricePerMonth: 33.84, PricePerHour: 0.0470, Currency: "EUR"},
+			{Type: "a8-ram32-disk80-perf1", VCPUs: 8, MemoryGB: 32, StorageGB: 80, PricePerMonth: 66.96, PricePerHour: 0.0930, Currency: "EUR"},
+		},
+		Storage: StoragePricing{
+			Type:            "Block storage",
+			PricePerGBMonth: 0.06,
+			MinSizeGB:       1,
+			MaxSizeGB:       10000,
+			Currency:        "EUR",
+		},
+		Network: NetworkPricing{
+			IngressFree:      true,
+			FreeEgressGB:     0, // Unlimited (represented as 0 = no limit applied)
+			EgressPricePerGB: 0, // Free egress
+			Currency:         "EUR",
+		},
+		LastUpdated: catalogLastUpdated,
+	},
+
 	// ─────────────────────────────────────────────────────────────────────────
 	// Reference Providers (Source Providers - for comparison)
 	// ─────────────────────────────────────────────────────────────────────────
@@ -245,9 +299,10 @@ func GetAllProviderPricing() map[Provider]*ProviderPricing {
 	return result
 }
 
-// GetEUProviderPricing returns pricing data for EU providers only (Hetzner, Scaleway, OVH).
+// GetEUProviderPricing returns pricing data for EU providers only (Hetzner, Scaleway, OVH,
+// Exoscale, Infomaniak).
 func GetEUProviderPricing() []*ProviderPricing {
-	euProviders := []Provider{ProviderHetzner, ProviderScaleway, ProviderOVH}
+	euProviders := []Provider{ProviderHetzner, ProviderScaleway, ProviderOVH, ProviderExoscale, ProviderInfomaniak}
 	var result []*ProviderPricing
 	for _, p := range euProviders {
 		if pricing := GetProviderPricing(p); pricing != nil {
@@ -1,5 +1,6 @@
 // Package provider defines cloud provider types, regions, and metadata
-// for both source (AWS, GCP, Azure) and target (Hetzner, Scaleway, OVH) providers.
+// for both source (AWS, GCP, Azure) and target (Hetzner, Scaleway, OVH, Exoscale,
+// Infomaniak) providers.
 package provider
 
 // Provider represents a cloud provider identifier.
@@ -8,9 +9,11 @@ type Provider string
 // Provider constants for all supported cloud providers.
 const (
 	// EU self-hosted providers (target providers for migration)
-	ProviderHetzner  Provider = "hetzner"
-	ProviderScaleway Provider = "scaleway"
-	ProviderOVH      Provider = "ovh"
+	ProviderHetzner    Provider = "hetzner"
+	ProviderScaleway   Provider = "scaleway"
+	ProviderOVH        Provider = "ovh"
+	ProviderExoscale   Provider = "exoscale"
+	ProviderInfomaniak Provider = "infomaniak"
 
 	// Reference providers (source providers for migration)
 	ProviderAWS   Provider = "aws"
@@ -79,6 +82,31 @@ var providerInfoMap = map[Provider]*ProviderInfo{
 			{ID: "uk1", Name: "UK", Location: "United Kingdom", Available: true},
 		},
 	},
+	ProviderExoscale: {
+		ID:          ProviderExoscale,
+		DisplayName: "Exoscale",
+		IsEU:        true,
+		IsSupported: true,
+		Regions: []Region{
+			{ID: "ch-gva-2", Name: "Geneva", Location: "Switzerland", Available: true},
+			{ID: "ch-dk-2", Name: "Zurich", Location: "Switzerland", Available: true},
+			{ID: "de-fra-1", Name: "Frankfurt", Location: "Germany", Available: true},
+			{ID: "de-muc-1", Name: "Munich", Location: "Germany", Available: true},
+			{ID: "at-vie-1", Name: "Vienna 1", Location: "Austria", Available: true},
+			{ID: "at-vie-2", Name: "Vienna 2", Location: "Austria", Available: true},
+			{ID: "bg-sof-1", Name: "Sofia", Location: "Bulgaria", Available: true},
+		},
+	},
+	ProviderInfomaniak: {
+		ID:          ProviderInfomaniak,
+		DisplayName: "Infomaniak Public Cloud",
+		IsEU:        true,
+		IsSupported: true,
+		Regions: []Region{
+			{ID: "dc3-a", Name: "Geneva", Location: "Switzerland", Available: true},
+			{ID: "dc4-a", Name: "Winterthur", Location: "Switzerland", Available: true},
+		},
+	},
 	ProviderAWS: {
 		ID:          ProviderAWS,
 		DisplayName: "Amazon Web Services",
@@ -134,6 +162,8 @@ func AllProviders() []Provider {
 		ProviderHetzner,
 		ProviderScaleway,
 		ProviderOVH,
+		ProviderExoscale,
+		ProviderInfomaniak,
 		ProviderAWS,
 		ProviderGCP,
 		ProviderAzure,
@@ -36,6 +36,9 @@ type Progress struct {
 	Errors int `json:"errors,omitempty"`
 	// Warnings counts the number of warnings encountered.
 	Warnings int `json:"warnings,omitempty"`
+	// ReplicationLag is how many bytes of changes the target has yet to apply.
+	// It is only set by strategies that replicate changes continuously.
+	ReplicationLag *int64 `json:"replication_lag_bytes,omitempty"`
 }
 
 // NewProgress creates a new progress tracker for a task.
@@ -135,6 +138,12 @@ func (p *Progress) SetCurrentItem(item string) {
 	p.UpdatedAt = time.Now()
 }
 
+// SetReplicationLag updates the replication lag in bytes.
+func (p *Progress) SetReplicationLag(lag int64) {
+	p.ReplicationLag = &lag
+	p.UpdatedAt = time.Now()
+}
+
 // RecordError increments the error counter.
 func (p *Progress) RecordError() {
 	p.Errors++
@@ -212,6 +221,10 @@ func FormatDuration(d time.Duration) string {
 // String returns a human-readable summary of the progress.
 func (p *Progress) String() string {
 	pct := p.Percentage()
+	if p.ReplicationLag != nil {
+		return fmt.Sprintf("[%s] %s - lag: %s",
+			p.Phase, p.Message, FormatBytes(*p.ReplicationLag))
+	}
 	if p.BytesTotal == 0 {
 		return fmt.Sprintf("[%s] %s - %d items done",
 			p.Phase, p.Message, p.ItemsDone)
@@ -230,7 +243,7 @@ func (p *Progress) Clone() *Progress {
 	if p == nil {
 		return nil
 	}
-	return &Progress{
+	clone := &Progress{
 		TaskID:       p.TaskID,
 		BytesTotal:   p.BytesTotal,
 		BytesDone:    p.BytesDone,
@@ -246,6 +259,11 @@ func (p *Progress) Clone() *Progress {
 		Errors:       p.Errors,
 		Warnings:     p.Warnings,
 	}
+	if p.ReplicationLag != nil {
+		lag := *p.ReplicationLag
+		clone.ReplicationLag = &lag
+	}
+	return clone
 }
 
 // ProgressCallback is a function type for receiving progress updates.
@@ -315,6 +333,12 @@ func (r *ProgressReporter) SetCurrentItem(item string) {
 	r.Report()
 }
 
+// SetReplicationLag updates the replication lag and reports progress.
+func (r *ProgressReporter) SetReplicationLag(lag int64) {
+	r.progress.SetReplicationLag(lag)
+	r.Report()
+}
+
 // Error records an error and reports progress.
 func (r *ProgressReporter) Error(message string) {
 	r.progress.RecordError()
@@ -2,7 +2,9 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 )
 
 // SyncStrategy defines the interface for data synchronization strategies.
@@ -37,6 +39,46 @@ type SyncStrategy interface {
 	SupportsResume() bool
 }
 
+// ReplicatingStrategy is implemented by strategies whose target keeps
+// receiving changes after Sync returns, through replication objects on the
+// source and target. Those objects must be removed when the sync ends, by
+// promoting the target or by stopping replication.
+type ReplicatingStrategy interface {
+	SyncStrategy
+
+	// WaitForLag blocks until the target is at most maxLag bytes behind the
+	// source. It is called with 0 once writes to the source have stopped.
+	WaitForLag(ctx context.Context, source, target *Endpoint, maxLag int64, progress chan<- Progress) error
+
+	// Promote makes the target independent of the source, carrying over any
+	// state replication does not stream, and removes the replication objects.
+	Promote(ctx context.Context, source, target *Endpoint) error
+
+	// StopReplication removes the replication objects without promoting the
+	// target, when a sync is cancelled. Missing objects are not an error.
+	StopReplication(ctx context.Context, source, target *Endpoint) error
+}
+
+// CompleteReplication waits until the target has applied every change of the
+// source and then promotes it. If the target does not catch up before ctx
+// ends, replication is stopped anyway, so the source does not keep data
+// around for a sync that is over.
+func CompleteReplication(ctx context.Context, strategy ReplicatingStrategy, source, target *Endpoint, progress chan<- Progress) error {
+	if err := strategy.WaitForLag(ctx, source, target, 0, progress); err != nil {
+		stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), replicationStopTimeout)
+		defer cancel()
+		if stopErr := strategy.StopReplication(stopCtx, source, target); stopErr != nil {
+			return errors.Join(fmt.Errorf("target did not catch up: %w", err), stopErr)
+		}
+		return fmt.Errorf("target did not catch up, replication stopped without promoting it: %w", err)
+	}
+	return strategy.Promote(ctx, source, target)
+}
+
+// replicationStopTimeout bounds StopReplication when it runs after the
+// context of a sync has ended.
+const replicationStopTimeout = 30 * time.Second
+
 // VerifyResult contains the outcome of a data verification check.
 type VerifyResult struct {
 	// Valid indicates whether the source and target data match.
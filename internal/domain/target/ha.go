@@ -314,7 +314,7 @@ func SupportedHALevelsForPlatform(platform Platform) []HALevel {
 	case PlatformK3s, PlatformKubernetes:
 		// Kubernetes supports all levels
 		return []HALevel{HALevelNone, HALevelBasic, HALevelMultiServer, HALevelCluster, HALevelGeo}
-	case PlatformScaleway, PlatformOVH, PlatformHetzner:
+	case PlatformScaleway, PlatformOVH, PlatformHetzner, PlatformExoscale, PlatformInfomaniak:
 		// Cloud platforms support managed HA
 		return []HALevel{HALevelNone, HALevelBasic, HALevelMultiServer, HALevelCluster}
 	default:
@@ -12,10 +12,10 @@ const (
 	PlatformKubernetes    Platform = "kubernetes"     // Full Kubernetes
 
 	// EU Cloud platforms
-	PlatformScaleway  Platform = "scaleway"  // Scaleway (France)
-	PlatformOVH       Platform = "ovh"       // OVHcloud (France)
-	PlatformHetzner   Platform = "hetzner"   // Hetzner (Germany)
-	PlatformExoscale  Platform = "exoscale"  // Exoscale (Switzerland)
+	PlatformScaleway   Platform = "scaleway"   // Scaleway (France)
+	PlatformOVH        Platform = "ovh"        // OVHcloud (France)
+	PlatformHetzner    Platform = "hetzner"    // Hetzner (Germany)
+	PlatformExoscale   Platform = "exoscale"   // Exoscale (Switzerland)
 	PlatformInfomaniak Platform = "infomaniak" // Infomaniak (Switzerland)
 
 	// Hybrid
@@ -75,10 +75,11 @@ type TargetConfig struct {
 	ServerCount int
 
 	// Provider-specific configurations
-	Scaleway  *ScalewayConfig
-	OVH       *OVHConfig
-	Hetzner   *HetznerConfig
-	Exoscale  *ExoscaleConfig
+	Scaleway   *ScalewayConfig
+	OVH        *OVHConfig
+	Hetzner    *HetznerConfig
+	Exoscale   *ExoscaleConfig
+	Infomaniak *InfomaniakConfig
 }
 
 // NewTargetConfig creates a new target config with defaults.
@@ -147,6 +148,14 @@ type ExoscaleConfig struct {
 	Zone      string // e.g., "ch-gva-2"
 }
 
+// InfomaniakConfig holds Infomaniak Public Cloud-specific configuration.
+type InfomaniakConfig struct {
+	ProjectName string // OpenStack project name
+	Username    string
+	Password    string
+	Region      string // e.g., "dc3-a"
+}
+
 // ValidPlatforms returns all valid platform values.
 func ValidPlatforms() []Platform {
 	return []Platform{
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/homeport/homeport/internal/domain/bundle"
@@ -57,8 +58,14 @@ func (a *Archiver) WriteArchive(b *bundle.Bundle, w io.Writer) error {
 		return fmt.Errorf("failed to write manifest: %w", err)
 	}
 
-	// Write all bundle files
-	for path, file := range b.Files {
+	// Write all bundle files in path order so archives are reproducible
+	paths := make([]string, 0, len(b.Files))
+	for path := range b.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		file := b.Files[path]
 		mode := file.Mode
 		if mode == 0 {
 			mode = 0644
@@ -2,6 +2,7 @@ package bundle
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -62,11 +63,42 @@ func NewExporter(homeportVersion string) *Exporter {
 
 // Export creates a .hprt bundle from a generated output directory.
 func (e *Exporter) Export(outputDir string, opts ExportOptions) error {
+	b, err := e.collect(outputDir, opts)
+	if err != nil {
+		return err
+	}
+
+	// Create the archive
+	if err := e.archiver.CreateArchive(b, opts.OutputPath); err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	return nil
+}
+
+// ExportTo writes a .hprt bundle from a generated output directory to w,
+// e.g. to pipe it into another tool. opts.OutputPath is ignored.
+func (e *Exporter) ExportTo(outputDir string, opts ExportOptions, w io.Writer) error {
+	b, err := e.collect(outputDir, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := e.archiver.WriteArchive(b, w); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return nil
+}
+
+// collect builds a bundle with its manifest and checksums from a generated
+// output directory.
+func (e *Exporter) collect(outputDir string, opts ExportOptions) (*bundle.Bundle, error) {
 	// Collect files from generator output
 	e.collector.BasePath = outputDir
 	b, err := e.collector.CollectFromGenerator(outputDir)
 	if err != nil {
-		return fmt.Errorf("failed to collect files: %w", err)
+		return nil, fmt.Errorf("failed to collect files: %w", err)
 	}
 
 	// Configure manifest
@@ -75,12 +107,7 @@ func (e *Exporter) Export(outputDir string, opts ExportOptions) error {
 	// Compute all checksums
 	bundle.ComputeAllChecksums(b)
 
-	// Create the archive
-	if err := e.archiver.CreateArchive(b, opts.OutputPath); err != nil {
-		return fmt.Errorf("failed to create archive: %w", err)
-	}
-
-	return nil
+	return b, nil
 }
 
 // ExportBundle creates a .hprt file from an existing bundle.
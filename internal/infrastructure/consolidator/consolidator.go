@@ -167,6 +167,9 @@ func (c *Consolidator) Consolidate(ctx context.Context, results []*mapper.Mappin
 		}
 	}
 
+	// Derive stack ordering from dependencies between source resources
+	c.linkResourceDependencies(groupedResults, consolidatedResult)
+
 	// Calculate metadata
 	consolidatedResult.CalculateMetadata()
 
@@ -206,6 +209,73 @@ func (c *Consolidator) groupByStackType(results []*mapper.MappingResult, mapping
 	return grouped
 }
 
+// linkResourceDependencies adds stack dependencies for every source resource
+// that references a resource consolidated into a different stack. Edges that
+// would introduce a cycle between stacks are skipped.
+func (c *Consolidator) linkResourceDependencies(grouped map[stack.StackType][]*mapper.MappingResult, consolidatedResult *stack.ConsolidatedResult) {
+	stacks := make(map[stack.StackType]*stack.Stack, len(consolidatedResult.Stacks))
+	for _, stk := range consolidatedResult.Stacks {
+		stacks[stk.Type] = stk
+	}
+
+	owner := make(map[string]stack.StackType)
+	for stackType, results := range grouped {
+		if _, ok := stacks[stackType]; !ok {
+			continue
+		}
+		for _, result := range results {
+			if result != nil && result.SourceResource != nil {
+				owner[result.SourceResource.ID] = stackType
+			}
+		}
+	}
+
+	for stackType, results := range grouped {
+		stk, ok := stacks[stackType]
+		if !ok {
+			continue
+		}
+		for _, result := range results {
+			if result == nil || result.SourceResource == nil {
+				continue
+			}
+			for _, depID := range result.SourceResource.Dependencies {
+				depType, ok := owner[depID]
+				if !ok || depType == stackType {
+					continue
+				}
+				if stackDependsOn(stacks, depType, stackType, make(map[stack.StackType]bool)) {
+					continue
+				}
+				stk.AddDependency(depType)
+			}
+		}
+	}
+}
+
+// stackDependsOn reports whether stack from depends on stack target, directly
+// or transitively.
+func stackDependsOn(stacks map[stack.StackType]*stack.Stack, from, target stack.StackType, visited map[stack.StackType]bool) bool {
+	if from == target {
+		return true
+	}
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+
+	stk, ok := stacks[from]
+	if !ok {
+		return false
+	}
+	for _, dep := range stk.DependsOn {
+		if stackDependsOn(stacks, dep, target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
 // isStackEnabled checks if a stack type is in the enabled list.
 func (c *Consolidator) isStackEnabled(stackType stack.StackType, enabledStacks []stack.StackType) bool {
 	if len(enabledStacks) == 0 {
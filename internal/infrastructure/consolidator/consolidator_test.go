@@ -0,0 +1,130 @@
+package consolidator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/parser"
+	"github.com/homeport/homeport/internal/domain/stack"
+	awsparser "github.com/homeport/homeport/internal/infrastructure/parser/aws"
+)
+
+// consolidateHCL parses an AWS Terraform configuration and consolidates one
+// mapping result per parsed resource.
+func consolidateHCL(t *testing.T, tfContent string) *stack.ConsolidatedResult {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	infra, err := awsparser.NewHCLParser().Parse(context.Background(), tmpDir, parser.NewParseOptions())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	results := make([]*mapper.MappingResult, 0, len(infra.Resources))
+	for _, res := range infra.Resources {
+		result := mapper.NewMappingResult(res.Name)
+		result.SourceResource = res
+		result.SourceResourceType = res.Type.String()
+		result.SourceResourceName = res.Name
+		results = append(results, result)
+	}
+
+	consolidated, err := New().Consolidate(context.Background(), results, nil)
+	if err != nil {
+		t.Fatalf("Consolidate failed: %v", err)
+	}
+	return consolidated
+}
+
+// stackDependencies returns the dependencies of a consolidated stack.
+func stackDependencies(t *testing.T, result *stack.ConsolidatedResult, stackType stack.StackType) []stack.StackType {
+	t.Helper()
+	for _, stk := range result.Stacks {
+		if stk.Type == stackType {
+			return stk.DependsOn
+		}
+	}
+	t.Fatalf("stack %s not found", stackType)
+	return nil
+}
+
+func TestConsolidateLinksReferenceAcrossStacks(t *testing.T) {
+	result := consolidateHCL(t, `
+resource "aws_db_instance" "main" {
+  identifier     = "shop-db"
+  engine         = "postgres"
+  instance_class = "db.t3.micro"
+}
+
+resource "aws_ssm_parameter" "db_host" {
+  name  = "/shop/db_host"
+  type  = "String"
+  value = aws_db_instance.main.address
+}
+`)
+
+	if deps := stackDependencies(t, result, stack.StackTypeSecrets); !slices.Contains(deps, stack.StackTypeDatabase) {
+		t.Errorf("secrets stack dependencies = %v, want database", deps)
+	}
+	if deps := stackDependencies(t, result, stack.StackTypeDatabase); slices.Contains(deps, stack.StackTypeSecrets) {
+		t.Errorf("database stack should not depend on secrets, got %v", deps)
+	}
+}
+
+func TestConsolidateLinksDependsOnAcrossStacks(t *testing.T) {
+	result := consolidateHCL(t, `
+resource "aws_elasticache_cluster" "cache" {
+  cluster_id      = "shop-cache"
+  engine          = "redis"
+  node_type       = "cache.t3.micro"
+  num_cache_nodes = 1
+}
+
+resource "aws_sqs_queue" "jobs" {
+  name       = "shop-jobs"
+  depends_on = [aws_elasticache_cluster.cache]
+}
+`)
+
+	if deps := stackDependencies(t, result, stack.StackTypeMessaging); !slices.Contains(deps, stack.StackTypeCache) {
+		t.Errorf("messaging stack dependencies = %v, want cache", deps)
+	}
+}
+
+func TestConsolidateSkipsCyclicStackDependencies(t *testing.T) {
+	result := consolidateHCL(t, `
+resource "aws_db_instance" "main" {
+  identifier     = "shop-db"
+  engine         = "postgres"
+  instance_class = "db.t3.micro"
+  depends_on     = [aws_ssm_parameter.flags]
+}
+
+resource "aws_ssm_parameter" "flags" {
+  name  = "/shop/flags"
+  type  = "String"
+  value = "{}"
+}
+
+resource "aws_ssm_parameter" "db_host" {
+  name  = "/shop/db_host"
+  type  = "String"
+  value = aws_db_instance.main.address
+}
+`)
+
+	secretsOnDatabase := slices.Contains(stackDependencies(t, result, stack.StackTypeSecrets), stack.StackTypeDatabase)
+	databaseOnSecrets := slices.Contains(stackDependencies(t, result, stack.StackTypeDatabase), stack.StackTypeSecrets)
+	if secretsOnDatabase == databaseOnSecrets {
+		t.Errorf("expected exactly one edge between secrets and database, got secrets->database=%v database->secrets=%v",
+			secretsOnDatabase, databaseOnSecrets)
+	}
+}
@@ -0,0 +1,254 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+const (
+	gandiAPIBase = "https://api.gandi.net/v5/livedns"
+
+	// gandiMinTTL is the lowest TTL LiveDNS accepts.
+	gandiMinTTL = 300
+)
+
+// GandiProvider implements DNS operations via the Gandi LiveDNS API.
+//
+// LiveDNS manages record sets rather than records; they are identified by
+// their name within the zone and their type, e.g. "www/A". Record sets with
+// several values list them one per line.
+type GandiProvider struct {
+	// token is the Gandi personal access token.
+	token string
+
+	// zone is the domain whose LiveDNS zone is managed (e.g., "example.com").
+	zone string
+
+	// endpoint is the base URL of the LiveDNS API.
+	endpoint string
+
+	// client is the HTTP client.
+	client *http.Client
+}
+
+// GandiConfig contains Gandi-specific configuration.
+type GandiConfig struct {
+	PersonalAccessToken string
+	Zone                string
+
+	// Endpoint overrides the LiveDNS API URL (e.g., a local stand-in).
+	Endpoint string
+}
+
+// NewGandiProvider creates a new Gandi LiveDNS provider.
+func NewGandiProvider(config *GandiConfig) *GandiProvider {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = gandiAPIBase
+	}
+
+	return &GandiProvider{
+		token:    config.PersonalAccessToken,
+		zone:     normalizeName(config.Zone),
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name.
+func (p *GandiProvider) Name() string {
+	return "gandi"
+}
+
+// gandiRecordSet represents a record set in LiveDNS.
+type gandiRecordSet struct {
+	Name   string   `json:"rrset_name,omitempty"`
+	Type   string   `json:"rrset_type,omitempty"`
+	TTL    int      `json:"rrset_ttl,omitempty"`
+	Values []string `json:"rrset_values"`
+}
+
+// GandiError is an error returned by the LiveDNS API.
+type GandiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *GandiError) Error() string {
+	return fmt.Sprintf("gandi API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// ListRecords retrieves all DNS records for a domain.
+func (p *GandiProvider) ListRecords(ctx context.Context, domain string) ([]*cutover.DNSRecord, error) {
+	var sets []gandiRecordSet
+	if err := p.do(ctx, http.MethodGet, p.zonePath()+"/records", nil, &sets); err != nil {
+		return nil, err
+	}
+
+	result := make([]*cutover.DNSRecord, 0, len(sets))
+	for i := range sets {
+		result = append(result, gandiRecord(&sets[i], domain))
+	}
+	return result, nil
+}
+
+// GetRecord retrieves a specific DNS record by ID.
+func (p *GandiProvider) GetRecord(ctx context.Context, domain, recordID string) (*cutover.DNSRecord, error) {
+	name, recordType, err := parseRRSetID(recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	var set gandiRecordSet
+	if err := p.do(ctx, http.MethodGet, p.rrsetPath(name, recordType), nil, &set); err != nil {
+		return nil, err
+	}
+	return gandiRecord(&set, domain), nil
+}
+
+// CreateRecord creates a new DNS record.
+func (p *GandiProvider) CreateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	return p.putRecordSet(ctx, http.MethodPost, change)
+}
+
+// UpdateRecord replaces the record set of the change's name and type, or
+// creates it if it doesn't exist.
+func (p *GandiProvider) UpdateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	return p.putRecordSet(ctx, http.MethodPut, change)
+}
+
+// DeleteRecord deletes a DNS record.
+func (p *GandiProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	name, recordType, err := parseRRSetID(recordID)
+	if err != nil {
+		return err
+	}
+
+	if err := p.do(ctx, http.MethodDelete, p.rrsetPath(name, recordType), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete record %s: %w", recordID, err)
+	}
+	return nil
+}
+
+// ValidateCredentials checks if the provider credentials are valid.
+func (p *GandiProvider) ValidateCredentials(ctx context.Context) error {
+	if p.token == "" {
+		return fmt.Errorf("personal access token is required")
+	}
+	if p.zone == "" {
+		return fmt.Errorf("zone name is required")
+	}
+
+	if err := p.do(ctx, http.MethodGet, p.zonePath(), nil, nil); err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+
+	return nil
+}
+
+// putRecordSet writes the record set of a change; POST creates it and PUT
+// creates or replaces it.
+func (p *GandiProvider) putRecordSet(ctx context.Context, method string, change *cutover.DNSChange) error {
+	name, err := nameInZone(change, p.zone)
+	if err != nil {
+		return err
+	}
+
+	recordType := strings.ToUpper(change.RecordType)
+	set := gandiRecordSet{TTL: max(change.TTL, gandiMinTTL)}
+	for _, value := range changeValues(change) {
+		set.Values = append(set.Values, zoneValue(recordType, value, change))
+	}
+	if len(set.Values) == 0 {
+		return fmt.Errorf("DNS change %s has no value", change.ID)
+	}
+
+	if err := p.do(ctx, method, p.rrsetPath(name, recordType), set, nil); err != nil {
+		return fmt.Errorf("failed to write record %s %s: %w", recordType, change.FullName(), err)
+	}
+
+	markApplied(change, rrsetID(name, recordType))
+	return nil
+}
+
+func (p *GandiProvider) zonePath() string {
+	return "/domains/" + url.PathEscape(p.zone)
+}
+
+func (p *GandiProvider) rrsetPath(name, recordType string) string {
+	return p.zonePath() + "/records/" + url.PathEscape(name) + "/" + url.PathEscape(recordType)
+}
+
+// do sends a request to the LiveDNS API and decodes the response into out.
+func (p *GandiProvider) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.endpoint+path, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		apiErr := &GandiError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &errResp) == nil && errResp.Message != "" {
+			apiErr.Message = errResp.Message
+		}
+		return apiErr
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func gandiRecord(set *gandiRecordSet, domain string) *cutover.DNSRecord {
+	record := &cutover.DNSRecord{
+		ID:     rrsetID(set.Name, set.Type),
+		Domain: domain,
+		Type:   set.Type,
+		Name:   set.Name,
+		TTL:    set.TTL,
+	}
+	setZoneValues(record, set.Values)
+	return record
+}
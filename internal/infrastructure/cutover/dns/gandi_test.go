@@ -0,0 +1,83 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+func TestGandiUpdateRecordPutsTheRecordSet(t *testing.T) {
+	var got gandiRecordSet
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pat" {
+			t.Errorf("unexpected Authorization %q", r.Header.Get("Authorization"))
+		}
+		if r.Method != http.MethodPut || r.URL.Path != "/domains/example.com/records/www.app/TXT" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"message":"DNS Record Created"}`)
+	}))
+	defer server.Close()
+
+	provider := NewGandiProvider(&GandiConfig{PersonalAccessToken: "pat", Zone: "example.com", Endpoint: server.URL})
+	change := &cutover.DNSChange{
+		ID:         "c1",
+		Domain:     "app.example.com",
+		RecordType: "TXT",
+		Name:       "www",
+		NewValue:   "v=spf1 -all\nsays \"hi\"",
+		TTL:        60,
+	}
+	if err := provider.UpdateRecord(context.Background(), change); err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+	if change.Status != cutover.DNSChangeStatusApplied || change.ProviderRecordID != "www.app/TXT" {
+		t.Errorf("change not applied: status %s, record %q", change.Status, change.ProviderRecordID)
+	}
+	if got.TTL != gandiMinTTL || strings.Join(got.Values, "|") != `"v=spf1 -all"|"says \"hi\""` {
+		t.Errorf("record set = %+v", got)
+	}
+}
+
+func TestGandiGetAndDeleteRecord(t *testing.T) {
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/domains/example.com/records/@/A":
+			_, _ = fmt.Fprint(w, `{"rrset_name":"@","rrset_type":"A","rrset_ttl":300,"rrset_values":["192.0.2.1","192.0.2.2"]}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/domains/example.com/records/@/A":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"code":404,"message":"Record not found","object":"HTTPNotFound"}`)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewGandiProvider(&GandiConfig{PersonalAccessToken: "pat", Zone: "example.com", Endpoint: server.URL})
+	record, err := provider.GetRecord(context.Background(), "example.com", "@/A")
+	if err != nil {
+		t.Fatalf("GetRecord() error = %v", err)
+	}
+	if record.ID != "@/A" || record.Value != "192.0.2.1\n192.0.2.2" || record.TTL != 300 {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	if err := provider.DeleteRecord(context.Background(), "example.com", "@/A"); err != nil || !deleted {
+		t.Errorf("DeleteRecord() error = %v, deleted = %v", err, deleted)
+	}
+
+	_, err = provider.GetRecord(context.Background(), "example.com", "missing/A")
+	if err == nil || err.Error() != "gandi API error (404): Record not found" {
+		t.Errorf("GetRecord() error = %v", err)
+	}
+}
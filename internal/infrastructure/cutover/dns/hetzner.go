@@ -0,0 +1,364 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+const hetznerAPIBase = "https://dns.hetzner.com/api/v1"
+
+// HetznerProvider implements DNS operations via the Hetzner DNS API.
+// Hetzner records hold a single value, so changes with several values map
+// to one record per value.
+type HetznerProvider struct {
+	// apiToken is the Hetzner DNS API token.
+	apiToken string
+
+	// zoneID is the Hetzner DNS zone ID.
+	zoneID string
+
+	// endpoint is the base URL of the Hetzner DNS API.
+	endpoint string
+
+	// zoneName is the zone's domain, looked up on first use.
+	zoneName string
+	mu       sync.Mutex
+
+	// client is the HTTP client.
+	client *http.Client
+}
+
+// HetznerConfig contains Hetzner DNS-specific configuration.
+type HetznerConfig struct {
+	APIToken string
+	ZoneID   string
+
+	// Endpoint overrides the Hetzner DNS API URL (e.g., a local stand-in).
+	Endpoint string
+}
+
+// NewHetznerProvider creates a new Hetzner DNS provider.
+func NewHetznerProvider(config *HetznerConfig) *HetznerProvider {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = hetznerAPIBase
+	}
+
+	return &HetznerProvider{
+		apiToken: config.APIToken,
+		zoneID:   config.ZoneID,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name.
+func (p *HetznerProvider) Name() string {
+	return "hetzner"
+}
+
+// hetznerRecord represents a DNS record in Hetzner DNS. Names are relative
+// to the zone.
+type hetznerRecord struct {
+	ID     string `json:"id,omitempty"`
+	ZoneID string `json:"zone_id"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	TTL    *int   `json:"ttl,omitempty"`
+}
+
+type hetznerRecordResponse struct {
+	Record hetznerRecord `json:"record"`
+}
+
+type hetznerRecordsResponse struct {
+	Records []hetznerRecord `json:"records"`
+	Meta    struct {
+		Pagination struct {
+			Page     int `json:"page"`
+			LastPage int `json:"last_page"`
+		} `json:"pagination"`
+	} `json:"meta"`
+}
+
+type hetznerZoneResponse struct {
+	Zone struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"zone"`
+}
+
+// HetznerError is an error returned by the Hetzner DNS API.
+type HetznerError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HetznerError) Error() string {
+	return fmt.Sprintf("hetzner API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// ListRecords retrieves all DNS records for a domain.
+func (p *HetznerProvider) ListRecords(ctx context.Context, domain string) ([]*cutover.DNSRecord, error) {
+	records, err := p.listRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*cutover.DNSRecord, 0, len(records))
+	for i := range records {
+		result = append(result, hetznerDNSRecord(&records[i], domain))
+	}
+	return result, nil
+}
+
+// GetRecord retrieves a specific DNS record by ID.
+func (p *HetznerProvider) GetRecord(ctx context.Context, domain, recordID string) (*cutover.DNSRecord, error) {
+	var resp hetznerRecordResponse
+	if err := p.do(ctx, http.MethodGet, "/records/"+url.PathEscape(recordID), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return hetznerDNSRecord(&resp.Record, domain), nil
+}
+
+// CreateRecord creates a new DNS record.
+func (p *HetznerProvider) CreateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	name, err := p.recordName(ctx, change)
+	if err != nil {
+		return err
+	}
+
+	id, err := createRecords(ctx, p, name, change)
+	if err != nil {
+		return err
+	}
+
+	markApplied(change, id)
+	return nil
+}
+
+// UpdateRecord replaces the records of the change's name and type with its
+// values, or creates them if they don't exist.
+func (p *HetznerProvider) UpdateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	name, err := p.recordName(ctx, change)
+	if err != nil {
+		return err
+	}
+
+	id, err := replaceRecords(ctx, p, name, change)
+	if err != nil {
+		return err
+	}
+
+	markApplied(change, id)
+	return nil
+}
+
+// DeleteRecord deletes a DNS record.
+func (p *HetznerProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	return p.deleteRecord(ctx, recordID)
+}
+
+// ValidateCredentials checks if the provider credentials are valid.
+func (p *HetznerProvider) ValidateCredentials(ctx context.Context) error {
+	if p.apiToken == "" {
+		return fmt.Errorf("API token is required")
+	}
+	if p.zoneID == "" {
+		return fmt.Errorf("zone ID is required")
+	}
+
+	if _, err := p.zone(ctx); err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+
+	return nil
+}
+
+func (p *HetznerProvider) findRecords(ctx context.Context, name, recordType string) ([]string, error) {
+	records, err := p.listRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, r := range records {
+		if strings.EqualFold(r.Name, name) && strings.EqualFold(r.Type, recordType) {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (p *HetznerProvider) createRecord(ctx context.Context, name string, change *cutover.DNSChange, value string) (string, error) {
+	var resp hetznerRecordResponse
+	if err := p.do(ctx, http.MethodPost, "/records", nil, p.recordFor(name, change, value), &resp); err != nil {
+		return "", fmt.Errorf("failed to create record %s %s: %w", change.RecordType, change.FullName(), err)
+	}
+	return resp.Record.ID, nil
+}
+
+func (p *HetznerProvider) updateRecord(ctx context.Context, id, name string, change *cutover.DNSChange, value string) error {
+	if err := p.do(ctx, http.MethodPut, "/records/"+url.PathEscape(id), nil, p.recordFor(name, change, value), nil); err != nil {
+		return fmt.Errorf("failed to update record %s %s: %w", change.RecordType, change.FullName(), err)
+	}
+	return nil
+}
+
+func (p *HetznerProvider) deleteRecord(ctx context.Context, id string) error {
+	if err := p.do(ctx, http.MethodDelete, "/records/"+url.PathEscape(id), nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete record %s: %w", id, err)
+	}
+	return nil
+}
+
+func (p *HetznerProvider) recordFor(name string, change *cutover.DNSChange, value string) hetznerRecord {
+	record := hetznerRecord{
+		ZoneID: p.zoneID,
+		Type:   strings.ToUpper(change.RecordType),
+		Name:   name,
+		Value:  zoneValue(change.RecordType, value, change),
+	}
+	if change.TTL > 0 {
+		ttl := change.TTL
+		record.TTL = &ttl
+	}
+	return record
+}
+
+// listRecords returns every record of the zone.
+func (p *HetznerProvider) listRecords(ctx context.Context) ([]hetznerRecord, error) {
+	var records []hetznerRecord
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("zone_id", p.zoneID)
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", "100")
+
+		var resp hetznerRecordsResponse
+		if err := p.do(ctx, http.MethodGet, "/records", query, nil, &resp); err != nil {
+			return nil, err
+		}
+		records = append(records, resp.Records...)
+
+		if page >= resp.Meta.Pagination.LastPage {
+			return records, nil
+		}
+	}
+}
+
+func (p *HetznerProvider) recordName(ctx context.Context, change *cutover.DNSChange) (string, error) {
+	zone, err := p.zone(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get zone %s: %w", p.zoneID, err)
+	}
+	return nameInZone(change, zone)
+}
+
+func (p *HetznerProvider) zone(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.zoneName == "" {
+		var resp hetznerZoneResponse
+		if err := p.do(ctx, http.MethodGet, "/zones/"+url.PathEscape(p.zoneID), nil, nil, &resp); err != nil {
+			return "", err
+		}
+		p.zoneName = normalizeName(resp.Zone.Name)
+	}
+	return p.zoneName, nil
+}
+
+// do sends a request to the Hetzner DNS API and decodes the response into
+// out.
+func (p *HetznerProvider) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.endpoint+path, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.URL.RawQuery = query.Encode()
+	req.Header.Set("Auth-API-Token", p.apiToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return &HetznerError{StatusCode: resp.StatusCode, Message: hetznerErrorMessage(data)}
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// hetznerErrorMessage extracts the message of an error response, which the
+// API puts either at the top level or under "error".
+func hetznerErrorMessage(data []byte) string {
+	var errResp struct {
+		Message string `json:"message"`
+		Error   struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &errResp) == nil {
+		if errResp.Error.Message != "" {
+			return errResp.Error.Message
+		}
+		if errResp.Message != "" {
+			return errResp.Message
+		}
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func hetznerDNSRecord(r *hetznerRecord, domain string) *cutover.DNSRecord {
+	record := &cutover.DNSRecord{
+		ID:     r.ID,
+		Domain: domain,
+		Type:   r.Type,
+		Name:   r.Name,
+	}
+	if r.TTL != nil {
+		record.TTL = *r.TTL
+	}
+	setZoneValues(record, []string{r.Value})
+	return record
+}
@@ -0,0 +1,164 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+// hetznerStandIn is a local stand-in for the Hetzner DNS API that keeps the
+// records of zone "z1", example.com, in memory.
+type hetznerStandIn struct {
+	t *testing.T
+
+	mu      gosync.Mutex
+	records []hetznerRecord
+	nextID  int
+}
+
+func newHetznerStandIn(t *testing.T, records ...hetznerRecord) (*hetznerStandIn, *HetznerProvider) {
+	t.Helper()
+	standIn := &hetznerStandIn{t: t, records: records, nextID: 100}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	provider := NewHetznerProvider(&HetznerConfig{APIToken: "token", ZoneID: "z1", Endpoint: server.URL})
+	return standIn, provider
+}
+
+func (s *hetznerStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Auth-API-Token") != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprint(w, `{"message":"Invalid authentication credentials"}`)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/records/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/zones/z1":
+		_, _ = fmt.Fprint(w, `{"zone":{"id":"z1","name":"example.com"}}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/records":
+		if r.URL.Query().Get("zone_id") != "z1" {
+			s.t.Errorf("unexpected zone_id %q", r.URL.Query().Get("zone_id"))
+		}
+		resp := hetznerRecordsResponse{Records: s.records}
+		resp.Meta.Pagination.Page, resp.Meta.Pagination.LastPage = 1, 1
+		_ = json.NewEncoder(w).Encode(resp)
+	case r.Method == http.MethodPost && r.URL.Path == "/records":
+		var record hetznerRecord
+		_ = json.NewDecoder(r.Body).Decode(&record)
+		record.ID = fmt.Sprintf("r%d", s.nextID)
+		s.nextID++
+		s.records = append(s.records, record)
+		_ = json.NewEncoder(w).Encode(hetznerRecordResponse{Record: record})
+	case r.Method == http.MethodPut:
+		for i := range s.records {
+			if s.records[i].ID == id {
+				_ = json.NewDecoder(r.Body).Decode(&s.records[i])
+				s.records[i].ID = id
+				_ = json.NewEncoder(w).Encode(hetznerRecordResponse{Record: s.records[i]})
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case r.Method == http.MethodDelete:
+		for i := range s.records {
+			if s.records[i].ID == id {
+				s.records = append(s.records[:i], s.records[i+1:]...)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestHetznerUpdateRecordReplacesEveryValue(t *testing.T) {
+	standIn, provider := newHetznerStandIn(t,
+		hetznerRecord{ID: "r1", ZoneID: "z1", Type: "A", Name: "www.app", Value: "192.0.2.1"},
+		hetznerRecord{ID: "r2", ZoneID: "z1", Type: "A", Name: "www.app", Value: "192.0.2.2"},
+		hetznerRecord{ID: "r3", ZoneID: "z1", Type: "A", Name: "www.app", Value: "192.0.2.3"},
+		hetznerRecord{ID: "r4", ZoneID: "z1", Type: "AAAA", Name: "www.app", Value: "2001:db8::1", TTL: intPtr(3600)},
+	)
+
+	change := &cutover.DNSChange{
+		ID:         "c1",
+		Domain:     "app.example.com",
+		RecordType: "A",
+		Name:       "www",
+		NewValue:   "198.51.100.1\n198.51.100.2",
+		TTL:        60,
+	}
+	if err := provider.UpdateRecord(context.Background(), change); err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+	if change.Status != cutover.DNSChangeStatusApplied || change.ProviderRecordID != "r1" {
+		t.Errorf("change not applied: status %s, record %q", change.Status, change.ProviderRecordID)
+	}
+
+	var got []string
+	for _, r := range standIn.records {
+		got = append(got, fmt.Sprintf("%s %s %s %s %d", r.ID, r.Name, r.Type, r.Value, *r.TTL))
+	}
+	want := []string{"r1 www.app A 198.51.100.1 60", "r2 www.app A 198.51.100.2 60", "r4 www.app AAAA 2001:db8::1 3600"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+}
+
+func TestHetznerCreateAndListRecords(t *testing.T) {
+	_, provider := newHetznerStandIn(t)
+
+	change := &cutover.DNSChange{
+		ID:         "c1",
+		Domain:     "example.com",
+		RecordType: "MX",
+		Name:       "@",
+		NewValue:   "mail.example.com.",
+		TTL:        300,
+		Priority:   intPtr(10),
+	}
+	if err := provider.CreateRecord(context.Background(), change); err != nil {
+		t.Fatalf("CreateRecord() error = %v", err)
+	}
+
+	records, err := provider.ListRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("ListRecords() error = %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("ListRecords() = %d records, want 1", len(records))
+	}
+	r := records[0]
+	if r.ID != change.ProviderRecordID || r.Name != "@" || r.Value != "mail.example.com." || r.Priority == nil || *r.Priority != 10 || r.TTL != 300 {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
+
+func TestHetznerValidateCredentials(t *testing.T) {
+	_, provider := newHetznerStandIn(t)
+	if err := provider.ValidateCredentials(context.Background()); err != nil {
+		t.Errorf("ValidateCredentials() error = %v", err)
+	}
+
+	provider.apiToken = "wrong"
+	provider.zoneName = ""
+	err := provider.ValidateCredentials(context.Background())
+	var apiErr *HetznerError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Invalid authentication credentials" {
+		t.Errorf("ValidateCredentials() error = %v", err)
+	}
+}
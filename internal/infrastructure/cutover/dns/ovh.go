@@ -0,0 +1,431 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+// ovhEndpoints maps the endpoint names the OVHcloud SDKs accept to API URLs.
+var ovhEndpoints = map[string]string{
+	"ovh-eu": "https://eu.api.ovh.com/1.0",
+	"ovh-ca": "https://ca.api.ovh.com/1.0",
+	"ovh-us": "https://api.us.ovhcloud.com/1.0",
+}
+
+// OVHProvider implements DNS operations via the OVHcloud DNS zone API.
+// Requests are signed with the application secret and consumer key, and the
+// zone is refreshed after every change so it is served right away. OVH
+// records hold a single value, so changes with several values map to one
+// record per value.
+type OVHProvider struct {
+	// zone is the DNS zone name (e.g., "example.com").
+	zone string
+
+	// credentials holds the OVH application and consumer keys.
+	applicationKey    string
+	applicationSecret string
+	consumerKey       string
+
+	// endpoint is the base URL of the OVH API.
+	endpoint string
+
+	// timeDelta is the offset of the API clock from the local one, which
+	// signatures must account for. It is looked up on first use.
+	timeDelta  time.Duration
+	timeSynced bool
+	mu         sync.Mutex
+
+	// client is the HTTP client.
+	client *http.Client
+}
+
+// OVHConfig contains OVH-specific configuration.
+type OVHConfig struct {
+	Zone              string
+	ApplicationKey    string
+	ApplicationSecret string
+	ConsumerKey       string
+
+	// Endpoint is an endpoint name ("ovh-eu", "ovh-ca" or "ovh-us") or an
+	// API URL. It defaults to "ovh-eu".
+	Endpoint string
+}
+
+// NewOVHProvider creates a new OVH DNS provider.
+func NewOVHProvider(config *OVHConfig) *OVHProvider {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = "ovh-eu"
+	}
+	if known, ok := ovhEndpoints[endpoint]; ok {
+		endpoint = known
+	}
+
+	return &OVHProvider{
+		zone:              normalizeName(config.Zone),
+		applicationKey:    config.ApplicationKey,
+		applicationSecret: config.ApplicationSecret,
+		consumerKey:       config.ConsumerKey,
+		endpoint:          strings.TrimSuffix(endpoint, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name.
+func (p *OVHProvider) Name() string {
+	return "ovh"
+}
+
+// ovhRecord represents a DNS record in an OVH zone. SubDomain is relative to
+// the zone and empty for the zone apex.
+type ovhRecord struct {
+	ID        int64  `json:"id,omitempty"`
+	Zone      string `json:"zone,omitempty"`
+	FieldType string `json:"fieldType,omitempty"`
+	SubDomain string `json:"subDomain"`
+	Target    string `json:"target"`
+	TTL       int    `json:"ttl"`
+}
+
+// OVHError is an error returned by the OVH API.
+type OVHError struct {
+	StatusCode int
+	Class      string
+	Message    string
+}
+
+func (e *OVHError) Error() string {
+	if e.Class != "" {
+		return fmt.Sprintf("ovh API error (%d %s): %s", e.StatusCode, e.Class, e.Message)
+	}
+	return fmt.Sprintf("ovh API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// ListRecords retrieves all DNS records for a domain.
+func (p *OVHProvider) ListRecords(ctx context.Context, domain string) ([]*cutover.DNSRecord, error) {
+	var ids []int64
+	if err := p.do(ctx, http.MethodGet, p.zonePath()+"/record", nil, nil, &ids); err != nil {
+		return nil, err
+	}
+
+	// The API only lists IDs, so every record is fetched on its own
+	result := make([]*cutover.DNSRecord, 0, len(ids))
+	for _, id := range ids {
+		record, err := p.record(ctx, strconv.FormatInt(id, 10))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ovhDNSRecord(record, domain))
+	}
+	return result, nil
+}
+
+// GetRecord retrieves a specific DNS record by ID.
+func (p *OVHProvider) GetRecord(ctx context.Context, domain, recordID string) (*cutover.DNSRecord, error) {
+	record, err := p.record(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+	return ovhDNSRecord(record, domain), nil
+}
+
+// CreateRecord creates a new DNS record.
+func (p *OVHProvider) CreateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	name, err := nameInZone(change, p.zone)
+	if err != nil {
+		return err
+	}
+
+	id, err := createRecords(ctx, p, name, change)
+	if err != nil {
+		return err
+	}
+	if err := p.refresh(ctx); err != nil {
+		return err
+	}
+
+	markApplied(change, id)
+	return nil
+}
+
+// UpdateRecord replaces the records of the change's name and type with its
+// values, or creates them if they don't exist.
+func (p *OVHProvider) UpdateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	name, err := nameInZone(change, p.zone)
+	if err != nil {
+		return err
+	}
+
+	id, err := replaceRecords(ctx, p, name, change)
+	if err != nil {
+		return err
+	}
+	if err := p.refresh(ctx); err != nil {
+		return err
+	}
+
+	markApplied(change, id)
+	return nil
+}
+
+// DeleteRecord deletes a DNS record.
+func (p *OVHProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	if err := p.deleteRecord(ctx, recordID); err != nil {
+		return err
+	}
+	return p.refresh(ctx)
+}
+
+// ValidateCredentials checks if the provider credentials are valid.
+func (p *OVHProvider) ValidateCredentials(ctx context.Context) error {
+	if p.zone == "" {
+		return fmt.Errorf("zone name is required")
+	}
+	if p.applicationKey == "" || p.applicationSecret == "" || p.consumerKey == "" {
+		return fmt.Errorf("application key, application secret and consumer key are required")
+	}
+
+	if err := p.do(ctx, http.MethodGet, p.zonePath(), nil, nil, nil); err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+
+	return nil
+}
+
+func (p *OVHProvider) findRecords(ctx context.Context, name, recordType string) ([]string, error) {
+	subDomain := ovhSubDomain(name)
+	query := url.Values{}
+	query.Set("fieldType", strings.ToUpper(recordType))
+	if subDomain != "" {
+		query.Set("subDomain", subDomain)
+	}
+
+	var ids []int64
+	if err := p.do(ctx, http.MethodGet, p.zonePath()+"/record", query, nil, &ids); err != nil {
+		return nil, err
+	}
+
+	// An empty subDomain filter matches every name, so apex records are
+	// picked out here
+	var result []string
+	for _, id := range ids {
+		recordID := strconv.FormatInt(id, 10)
+		if subDomain == "" {
+			record, err := p.record(ctx, recordID)
+			if err != nil {
+				return nil, err
+			}
+			if record.SubDomain != "" {
+				continue
+			}
+		}
+		result = append(result, recordID)
+	}
+	return result, nil
+}
+
+func (p *OVHProvider) createRecord(ctx context.Context, name string, change *cutover.DNSChange, value string) (string, error) {
+	record := p.recordFor(name, change, value)
+	record.FieldType = strings.ToUpper(change.RecordType)
+
+	var created ovhRecord
+	if err := p.do(ctx, http.MethodPost, p.zonePath()+"/record", nil, record, &created); err != nil {
+		return "", fmt.Errorf("failed to create record %s %s: %w", change.RecordType, change.FullName(), err)
+	}
+	return strconv.FormatInt(created.ID, 10), nil
+}
+
+func (p *OVHProvider) updateRecord(ctx context.Context, id, name string, change *cutover.DNSChange, value string) error {
+	if err := p.do(ctx, http.MethodPut, p.recordPath(id), nil, p.recordFor(name, change, value), nil); err != nil {
+		return fmt.Errorf("failed to update record %s %s: %w", change.RecordType, change.FullName(), err)
+	}
+	return nil
+}
+
+func (p *OVHProvider) deleteRecord(ctx context.Context, id string) error {
+	if err := p.do(ctx, http.MethodDelete, p.recordPath(id), nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete record %s: %w", id, err)
+	}
+	return nil
+}
+
+func (p *OVHProvider) recordFor(name string, change *cutover.DNSChange, value string) ovhRecord {
+	return ovhRecord{
+		SubDomain: ovhSubDomain(name),
+		Target:    zoneValue(change.RecordType, value, change),
+		TTL:       change.TTL,
+	}
+}
+
+func (p *OVHProvider) record(ctx context.Context, id string) (*ovhRecord, error) {
+	var record ovhRecord
+	if err := p.do(ctx, http.MethodGet, p.recordPath(id), nil, nil, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// refresh applies the pending changes of the zone.
+func (p *OVHProvider) refresh(ctx context.Context) error {
+	if err := p.do(ctx, http.MethodPost, p.zonePath()+"/refresh", nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to refresh zone %s: %w", p.zone, err)
+	}
+	return nil
+}
+
+func (p *OVHProvider) zonePath() string {
+	return "/domain/zone/" + url.PathEscape(p.zone)
+}
+
+func (p *OVHProvider) recordPath(id string) string {
+	return p.zonePath() + "/record/" + url.PathEscape(id)
+}
+
+// do sends a signed request to the OVH API and decodes the response into
+// out.
+func (p *OVHProvider) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		payload = data
+	}
+
+	timestamp, err := p.timestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.URL.RawQuery = query.Encode()
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	signOVHRequest(req, payload, p.applicationKey, p.applicationSecret, p.consumerKey, timestamp)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		apiErr := &OVHError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var errResp struct {
+			Class   string `json:"class"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &errResp) == nil && errResp.Message != "" {
+			apiErr.Class = errResp.Class
+			apiErr.Message = errResp.Message
+		}
+		return apiErr
+	}
+
+	if out != nil && len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// timestamp returns the current time of the API clock in Unix seconds. The
+// API rejects signatures made with a clock too far off its own.
+func (p *OVHProvider) timestamp(ctx context.Context) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.timeSynced {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"/auth/time", nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get API time: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		var serverTime int64
+		if err := json.NewDecoder(resp.Body).Decode(&serverTime); err != nil {
+			return 0, fmt.Errorf("failed to decode API time: %w", err)
+		}
+		p.timeDelta = time.Unix(serverTime, 0).Sub(time.Now())
+		p.timeSynced = true
+	}
+
+	return time.Now().Add(p.timeDelta).Unix(), nil
+}
+
+// signOVHRequest sets the authentication headers of the OVH API on req. The
+// signature covers the secret, the consumer key, the method, the full URL,
+// the body and the timestamp.
+func signOVHRequest(req *http.Request, payload []byte, applicationKey, applicationSecret, consumerKey string, timestamp int64) {
+	ts := strconv.FormatInt(timestamp, 10)
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		applicationSecret,
+		consumerKey,
+		req.Method,
+		req.URL.String(),
+		string(payload),
+		ts,
+	}, "+")))
+
+	req.Header.Set("X-Ovh-Application", applicationKey)
+	req.Header.Set("X-Ovh-Consumer", consumerKey)
+	req.Header.Set("X-Ovh-Timestamp", ts)
+	req.Header.Set("X-Ovh-Signature", "$1$"+hex.EncodeToString(sum[:]))
+}
+
+// ovhSubDomain converts a relative name to OVH's, which is empty for the
+// zone apex.
+func ovhSubDomain(name string) string {
+	if name == "@" {
+		return ""
+	}
+	return name
+}
+
+func ovhDNSRecord(r *ovhRecord, domain string) *cutover.DNSRecord {
+	name := r.SubDomain
+	if name == "" {
+		name = "@"
+	}
+
+	record := &cutover.DNSRecord{
+		ID:     strconv.FormatInt(r.ID, 10),
+		Domain: domain,
+		Type:   r.FieldType,
+		Name:   name,
+		TTL:    r.TTL,
+	}
+	setZoneValues(record, []string{r.Target})
+	return record
+}
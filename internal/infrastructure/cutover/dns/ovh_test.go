@@ -0,0 +1,216 @@
+package dns
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+// ovhStandIn is a local stand-in for the OVH API that checks request
+// signatures and keeps the records of zone example.com in memory. Its clock
+// runs an hour ahead.
+type ovhStandIn struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu        gosync.Mutex
+	records   []ovhRecord
+	nextID    int64
+	refreshes int
+}
+
+const ovhClockSkew = time.Hour
+
+func newOVHStandIn(t *testing.T, records ...ovhRecord) (*ovhStandIn, *OVHProvider) {
+	t.Helper()
+	standIn := &ovhStandIn{t: t, records: records, nextID: 100}
+	standIn.server = httptest.NewServer(standIn)
+	t.Cleanup(standIn.server.Close)
+
+	provider := NewOVHProvider(&OVHConfig{
+		Zone:              "example.com",
+		ApplicationKey:    "app-key",
+		ApplicationSecret: "app-secret",
+		ConsumerKey:       "consumer-key",
+		Endpoint:          standIn.server.URL,
+	})
+	return standIn, provider
+}
+
+func (s *ovhStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/auth/time" {
+		_, _ = fmt.Fprint(w, time.Now().Add(ovhClockSkew).Unix())
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	timestamp, _ := strconv.ParseInt(r.Header.Get("X-Ovh-Timestamp"), 10, 64)
+	sum := sha1.Sum([]byte("app-secret+consumer-key+" + r.Method + "+" + s.server.URL + r.URL.RequestURI() + "+" + string(body) + "+" + r.Header.Get("X-Ovh-Timestamp")))
+	if r.Header.Get("X-Ovh-Application") != "app-key" || r.Header.Get("X-Ovh-Signature") != "$1$"+hex.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprint(w, `{"class":"Client::Forbidden","message":"Invalid signature"}`)
+		return
+	}
+	if skew := time.Until(time.Unix(timestamp, 0)); skew < ovhClockSkew-time.Minute || skew > ovhClockSkew+time.Minute {
+		s.t.Errorf("timestamp is %v off the local clock, want %v", skew, ovhClockSkew)
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/domain/zone/example.com")
+	id := strings.TrimPrefix(path, "/record/")
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		_, _ = fmt.Fprint(w, `{"name":"example.com"}`)
+	case r.Method == http.MethodGet && path == "/record":
+		ids := []int64{}
+		for _, record := range s.records {
+			query := r.URL.Query()
+			if query.Get("fieldType") != "" && query.Get("fieldType") != record.FieldType ||
+				query.Get("subDomain") != "" && query.Get("subDomain") != record.SubDomain {
+				continue
+			}
+			ids = append(ids, record.ID)
+		}
+		_ = json.NewEncoder(w).Encode(ids)
+	case r.Method == http.MethodGet:
+		for _, record := range s.records {
+			if strconv.FormatInt(record.ID, 10) == id {
+				_ = json.NewEncoder(w).Encode(record)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `{"message":"The requested object does not exist"}`)
+	case r.Method == http.MethodPost && path == "/record":
+		var record ovhRecord
+		_ = json.Unmarshal(body, &record)
+		record.ID = s.nextID
+		record.Zone = "example.com"
+		s.nextID++
+		s.records = append(s.records, record)
+		_ = json.NewEncoder(w).Encode(record)
+	case r.Method == http.MethodPost && path == "/refresh":
+		s.refreshes++
+	case r.Method == http.MethodPut:
+		for i := range s.records {
+			if strconv.FormatInt(s.records[i].ID, 10) == id {
+				_ = json.Unmarshal(body, &s.records[i])
+				_, _ = fmt.Fprint(w, "null")
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodDelete:
+		for i := range s.records {
+			if strconv.FormatInt(s.records[i].ID, 10) == id {
+				s.records = append(s.records[:i], s.records[i+1:]...)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestOVHUpdateRecordSignsRequestsAndRefreshesTheZone(t *testing.T) {
+	standIn, provider := newOVHStandIn(t,
+		ovhRecord{ID: 1, Zone: "example.com", FieldType: "A", SubDomain: "", Target: "192.0.2.1", TTL: 3600},
+		ovhRecord{ID: 2, Zone: "example.com", FieldType: "A", SubDomain: "www", Target: "192.0.2.2", TTL: 3600},
+	)
+
+	change := &cutover.DNSChange{
+		ID:         "c1",
+		Domain:     "example.com",
+		RecordType: "A",
+		Name:       "@",
+		NewValue:   "198.51.100.1",
+		TTL:        60,
+	}
+	if err := provider.UpdateRecord(context.Background(), change); err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+	if change.Status != cutover.DNSChangeStatusApplied || change.ProviderRecordID != "1" {
+		t.Errorf("change not applied: status %s, record %q", change.Status, change.ProviderRecordID)
+	}
+
+	// Only the apex record is replaced, although an empty subDomain filter
+	// matches www as well
+	if r := standIn.records[0]; r.Target != "198.51.100.1" || r.TTL != 60 || r.SubDomain != "" {
+		t.Errorf("apex record = %+v", r)
+	}
+	if r := standIn.records[1]; r.Target != "192.0.2.2" {
+		t.Errorf("www record = %+v", r)
+	}
+	if standIn.refreshes != 1 {
+		t.Errorf("zone refreshed %d times, want 1", standIn.refreshes)
+	}
+}
+
+func TestOVHCreateAndGetRecord(t *testing.T) {
+	standIn, provider := newOVHStandIn(t)
+
+	change := &cutover.DNSChange{
+		ID:         "c1",
+		Domain:     "example.com",
+		RecordType: "TXT",
+		Name:       "_verify",
+		NewValue:   "token=abc",
+		TTL:        300,
+	}
+	if err := provider.CreateRecord(context.Background(), change); err != nil {
+		t.Fatalf("CreateRecord() error = %v", err)
+	}
+	if standIn.records[0].Target != `"token=abc"` || standIn.refreshes != 1 {
+		t.Errorf("record = %+v, refreshes = %d", standIn.records[0], standIn.refreshes)
+	}
+
+	record, err := provider.GetRecord(context.Background(), "example.com", change.ProviderRecordID)
+	if err != nil {
+		t.Fatalf("GetRecord() error = %v", err)
+	}
+	if record.Name != "_verify" || record.Type != "TXT" || record.Value != "token=abc" {
+		t.Errorf("unexpected record %+v", record)
+	}
+}
+
+func TestOVHValidateCredentials(t *testing.T) {
+	_, provider := newOVHStandIn(t)
+	if err := provider.ValidateCredentials(context.Background()); err != nil {
+		t.Errorf("ValidateCredentials() error = %v", err)
+	}
+
+	provider.applicationSecret = "wrong"
+	err := provider.ValidateCredentials(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "ovh API error (403 Client::Forbidden): Invalid signature") {
+		t.Errorf("ValidateCredentials() error = %v", err)
+	}
+}
+
+func TestNewOVHProviderResolvesEndpointNames(t *testing.T) {
+	tests := map[string]string{
+		"":                              "https://eu.api.ovh.com/1.0",
+		"ovh-ca":                        "https://ca.api.ovh.com/1.0",
+		"https://api.example.test/1.0/": "https://api.example.test/1.0",
+	}
+	for endpoint, want := range tests {
+		if got := NewOVHProvider(&OVHConfig{Endpoint: endpoint}).endpoint; got != want {
+			t.Errorf("endpoint %q resolves to %q, want %q", endpoint, got, want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+// changeValues returns the values of a change. Records with several values
+// list them one per line.
+func changeValues(change *cutover.DNSChange) []string {
+	var values []string
+	for _, value := range strings.Split(change.NewValue, "\n") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// zoneValue encodes a record value in zone file syntax, which most provider
+// APIs expect: MX and SRV values carry their priority, weight and port, and
+// TXT values are quoted.
+func zoneValue(recordType, value string, change *cutover.DNSChange) string {
+	switch recordType {
+	case "MX":
+		if change.Priority != nil && len(strings.Fields(value)) == 1 {
+			return fmt.Sprintf("%d %s", *change.Priority, value)
+		}
+	case "SRV":
+		if change.Priority != nil && change.Weight != nil && change.Port != nil && len(strings.Fields(value)) == 1 {
+			return fmt.Sprintf("%d %d %d %s", *change.Priority, *change.Weight, *change.Port, value)
+		}
+	case "TXT", "SPF":
+		if !strings.HasPrefix(value, `"`) {
+			return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+		}
+	}
+	return value
+}
+
+// setZoneValues sets the value of record from values in zone file syntax.
+// The priority, weight and port of a single MX or SRV value are split out and
+// a single TXT value is unquoted, so records read back as they are written.
+func setZoneValues(record *cutover.DNSRecord, values []string) {
+	if len(values) == 1 {
+		fields := strings.Fields(values[0])
+		switch {
+		case record.Type == "MX" && len(fields) == 2:
+			if priority, err := strconv.Atoi(fields[0]); err == nil {
+				record.Priority = &priority
+				values = []string{fields[1]}
+			}
+		case record.Type == "SRV" && len(fields) == 4:
+			priority, err1 := strconv.Atoi(fields[0])
+			weight, err2 := strconv.Atoi(fields[1])
+			port, err3 := strconv.Atoi(fields[2])
+			if err1 == nil && err2 == nil && err3 == nil {
+				record.Priority, record.Weight, record.Port = &priority, &weight, &port
+				values = []string{fields[3]}
+			}
+		case record.Type == "TXT" || record.Type == "SPF":
+			if unquoted, err := strconv.Unquote(values[0]); err == nil {
+				values = []string{unquoted}
+			}
+		}
+	}
+	record.Value = strings.Join(values, "\n")
+}
+
+// normalizeName lower-cases a DNS name, drops its trailing dot and decodes
+// the octal escapes zone files use for characters such as "*".
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if !strings.Contains(name, `\`) {
+		return name
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+3 < len(name) {
+			if code, err := strconv.ParseUint(name[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// relativeName returns the name of fqdn within domain, "@" for the domain
+// itself.
+func relativeName(fqdn, domain string) string {
+	if domain == "" {
+		return fqdn
+	}
+	if fqdn == domain {
+		return "@"
+	}
+	return strings.TrimSuffix(fqdn, "."+domain)
+}
+
+// nameInZone returns the name of the change's record within zone, which may
+// be a parent of the change's domain.
+func nameInZone(change *cutover.DNSChange, zone string) (string, error) {
+	fqdn := normalizeName(change.FullName())
+	if fqdn != zone && !strings.HasSuffix(fqdn, "."+zone) {
+		return "", fmt.Errorf("%s is not in zone %s", fqdn, zone)
+	}
+	return relativeName(fqdn, zone), nil
+}
+
+// rrsetID identifies a record set by its name within the zone and its type,
+// for providers that manage record sets rather than single records.
+func rrsetID(name, recordType string) string {
+	return name + "/" + recordType
+}
+
+func parseRRSetID(recordID string) (name, recordType string, err error) {
+	i := strings.LastIndex(recordID, "/")
+	if i <= 0 || i == len(recordID)-1 {
+		return "", "", fmt.Errorf("invalid record ID %q, expected name/type", recordID)
+	}
+	return recordID[:i], recordID[i+1:], nil
+}
+
+// recordStore is the record-level API of providers that keep one value per
+// record, such as Hetzner and OVH. Names are relative to the zone.
+type recordStore interface {
+	// findRecords returns the IDs of the records of a name and type.
+	findRecords(ctx context.Context, name, recordType string) ([]string, error)
+	createRecord(ctx context.Context, name string, change *cutover.DNSChange, value string) (string, error)
+	updateRecord(ctx context.Context, id, name string, change *cutover.DNSChange, value string) error
+	deleteRecord(ctx context.Context, id string) error
+}
+
+// createRecords creates one record per value of change and returns the ID of
+// the first one.
+func createRecords(ctx context.Context, store recordStore, name string, change *cutover.DNSChange) (string, error) {
+	values := changeValues(change)
+	if len(values) == 0 {
+		return "", fmt.Errorf("DNS change %s has no value", change.ID)
+	}
+
+	var first string
+	for i, value := range values {
+		id, err := store.createRecord(ctx, name, change, value)
+		if err != nil {
+			return "", err
+		}
+		if i == 0 {
+			first = id
+		}
+	}
+	return first, nil
+}
+
+// replaceRecords makes the records of a name and type hold the values of
+// change: existing records are updated in place, missing ones created and
+// extra ones deleted. It returns the ID of the first record.
+func replaceRecords(ctx context.Context, store recordStore, name string, change *cutover.DNSChange) (string, error) {
+	values := changeValues(change)
+	if len(values) == 0 {
+		return "", fmt.Errorf("DNS change %s has no value", change.ID)
+	}
+
+	ids, err := store.findRecords(ctx, name, change.RecordType)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up records: %w", err)
+	}
+
+	var first string
+	for i, value := range values {
+		id := ""
+		if i < len(ids) {
+			id = ids[i]
+			err = store.updateRecord(ctx, id, name, change, value)
+		} else {
+			id, err = store.createRecord(ctx, name, change, value)
+		}
+		if err != nil {
+			return "", err
+		}
+		if i == 0 {
+			first = id
+		}
+	}
+
+	for _, id := range ids[min(len(values), len(ids)):] {
+		if err := store.deleteRecord(ctx, id); err != nil {
+			return "", err
+		}
+	}
+
+	return first, nil
+}
+
+// markApplied records that change was applied as the record recordID.
+func markApplied(change *cutover.DNSChange, recordID string) {
+	change.ProviderRecordID = recordID
+	change.Status = cutover.DNSChangeStatusApplied
+	now := time.Now()
+	change.AppliedAt = &now
+}
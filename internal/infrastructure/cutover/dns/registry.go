@@ -104,6 +104,75 @@ func CreateProvider(providerType string, config *cutover.DNSProviderConfig) (cut
 			SessionToken:    config.APIToken,
 		}), nil
 
+	case cutover.DNSProviderHetzner:
+		if config == nil || config.APIToken == "" {
+			return nil, fmt.Errorf("hetzner provider requires API token")
+		}
+		if config.ZoneID == "" {
+			return nil, fmt.Errorf("hetzner provider requires zone ID")
+		}
+		return NewHetznerProvider(&HetznerConfig{
+			APIToken: config.APIToken,
+			ZoneID:   config.ZoneID,
+		}), nil
+
+	case cutover.DNSProviderOVH:
+		if config == nil || config.APIKey == "" || config.APISecret == "" || config.APIToken == "" {
+			return nil, fmt.Errorf("ovh provider requires application key, application secret and consumer key")
+		}
+		if config.ZoneID == "" {
+			return nil, fmt.Errorf("ovh provider requires zone name")
+		}
+		return NewOVHProvider(&OVHConfig{
+			Zone:              config.ZoneID,
+			ApplicationKey:    config.APIKey,
+			ApplicationSecret: config.APISecret,
+			ConsumerKey:       config.APIToken,
+			Endpoint:          config.Endpoint,
+		}), nil
+
+	case cutover.DNSProviderScaleway:
+		if config == nil || config.APIToken == "" {
+			return nil, fmt.Errorf("scaleway provider requires secret key")
+		}
+		if config.ZoneID == "" {
+			return nil, fmt.Errorf("scaleway provider requires zone name")
+		}
+		return NewScalewayProvider(&ScalewayConfig{
+			SecretKey: config.APIToken,
+			Zone:      config.ZoneID,
+		}), nil
+
+	case cutover.DNSProviderGandi:
+		if config == nil || config.APIToken == "" {
+			return nil, fmt.Errorf("gandi provider requires personal access token")
+		}
+		if config.ZoneID == "" {
+			return nil, fmt.Errorf("gandi provider requires zone name")
+		}
+		return NewGandiProvider(&GandiConfig{
+			PersonalAccessToken: config.APIToken,
+			Zone:                config.ZoneID,
+		}), nil
+
+	case cutover.DNSProviderRFC2136:
+		if config == nil || config.Endpoint == "" {
+			return nil, fmt.Errorf("rfc2136 provider requires name server")
+		}
+		if config.ZoneID == "" {
+			return nil, fmt.Errorf("rfc2136 provider requires zone name")
+		}
+		if (config.APIKey == "") != (config.APISecret == "") {
+			return nil, fmt.Errorf("rfc2136 provider requires both TSIG key name and secret")
+		}
+		return NewRFC2136Provider(&RFC2136Config{
+			Server:        config.Endpoint,
+			Zone:          config.ZoneID,
+			TSIGKeyName:   config.APIKey,
+			TSIGSecret:    config.APISecret,
+			TSIGAlgorithm: config.TSIGAlgorithm,
+		}), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported DNS provider: %s", providerType)
 	}
@@ -115,5 +184,10 @@ func SupportedProviders() []string {
 		string(cutover.DNSProviderManual),
 		string(cutover.DNSProviderCloudflare),
 		string(cutover.DNSProviderRoute53),
+		string(cutover.DNSProviderHetzner),
+		string(cutover.DNSProviderOVH),
+		string(cutover.DNSProviderScaleway),
+		string(cutover.DNSProviderGandi),
+		string(cutover.DNSProviderRFC2136),
 	}
 }
@@ -0,0 +1,341 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+const (
+	// rfc2136Timeout bounds each exchange with the name server.
+	rfc2136Timeout = 30 * time.Second
+
+	// tsigFudge is the clock skew allowed between signer and verifier.
+	tsigFudge = 300
+)
+
+// RFC2136Provider implements DNS operations with RFC 2136 dynamic updates
+// sent to a primary name server, such as BIND, Knot or PowerDNS. Messages
+// are signed with TSIG when a key is configured. Records are read with a
+// zone transfer, which the server must allow for the key.
+//
+// Updates replace record sets rather than records; they are identified by
+// their name within the zone and their type, e.g. "www/A". Record sets with
+// several values list them one per line.
+type RFC2136Provider struct {
+	// server is the name server address as host:port.
+	server string
+
+	// zone is the zone name (e.g., "example.com").
+	zone string
+
+	// TSIG key used to sign messages.
+	keyName   string
+	secret    string
+	algorithm string
+}
+
+// RFC2136Config contains RFC 2136-specific configuration.
+type RFC2136Config struct {
+	// Server is the name server address; the port defaults to 53.
+	Server string
+	Zone   string
+
+	// TSIGKeyName and TSIGSecret, in base64, name the key messages are
+	// signed with. TSIGAlgorithm defaults to hmac-sha256.
+	TSIGKeyName   string
+	TSIGSecret    string
+	TSIGAlgorithm string
+}
+
+// NewRFC2136Provider creates a new RFC 2136 DNS provider.
+func NewRFC2136Provider(config *RFC2136Config) *RFC2136Provider {
+	server := config.Server
+	if server != "" {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+		}
+	}
+
+	algorithm := mdns.HmacSHA256
+	if config.TSIGAlgorithm != "" {
+		algorithm = mdns.Fqdn(strings.ToLower(config.TSIGAlgorithm))
+	}
+
+	keyName := ""
+	if config.TSIGKeyName != "" {
+		keyName = mdns.CanonicalName(config.TSIGKeyName)
+	}
+
+	return &RFC2136Provider{
+		server:    server,
+		zone:      normalizeName(config.Zone),
+		keyName:   keyName,
+		secret:    config.TSIGSecret,
+		algorithm: algorithm,
+	}
+}
+
+// Name returns the provider name.
+func (p *RFC2136Provider) Name() string {
+	return "rfc2136"
+}
+
+// RFC2136Error is an error response from the name server.
+type RFC2136Error struct {
+	Rcode int
+}
+
+func (e *RFC2136Error) Error() string {
+	return fmt.Sprintf("name server answered %s", mdns.RcodeToString[e.Rcode])
+}
+
+// ListRecords retrieves all DNS records for a domain through a zone
+// transfer.
+func (p *RFC2136Provider) ListRecords(ctx context.Context, domain string) ([]*cutover.DNSRecord, error) {
+	msg := new(mdns.Msg)
+	msg.SetAxfr(mdns.Fqdn(p.zone))
+	p.sign(msg)
+
+	transfer := &mdns.Transfer{
+		DialTimeout:  rfc2136Timeout,
+		ReadTimeout:  rfc2136Timeout,
+		WriteTimeout: rfc2136Timeout,
+		TsigSecret:   p.tsigSecret(),
+	}
+	envelopes, err := transfer.In(msg, p.server)
+	if err != nil {
+		return nil, fmt.Errorf("zone transfer failed: %w", err)
+	}
+
+	domain = normalizeName(domain)
+	var rrs []mdns.RR
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, fmt.Errorf("zone transfer failed: %w", envelope.Error)
+		}
+		for _, rr := range envelope.RR {
+			name := normalizeName(rr.Header().Name)
+			if domain == "" || name == domain || strings.HasSuffix(name, "."+domain) {
+				rrs = append(rrs, rr)
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.records(rrs, domain), nil
+}
+
+// GetRecord retrieves a specific DNS record by ID.
+func (p *RFC2136Provider) GetRecord(ctx context.Context, domain, recordID string) (*cutover.DNSRecord, error) {
+	name, recordType, err := parseRRSetID(recordID)
+	if err != nil {
+		return nil, err
+	}
+	qtype, ok := mdns.StringToType[strings.ToUpper(recordType)]
+	if !ok {
+		return nil, fmt.Errorf("unknown record type %s", recordType)
+	}
+
+	msg := new(mdns.Msg)
+	msg.SetQuestion(p.fqdn(name), qtype)
+	resp, err := p.exchange(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var rrs []mdns.RR
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype == qtype && strings.EqualFold(rr.Header().Name, p.fqdn(name)) {
+			rrs = append(rrs, rr)
+		}
+	}
+	if len(rrs) == 0 {
+		return nil, fmt.Errorf("record not found: %s", recordID)
+	}
+
+	return p.records(rrs, normalizeName(domain))[0], nil
+}
+
+// CreateRecord creates a new DNS record.
+func (p *RFC2136Provider) CreateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	return p.update(ctx, change, false)
+}
+
+// UpdateRecord replaces the record set of the change's name and type, or
+// creates it if it doesn't exist.
+func (p *RFC2136Provider) UpdateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	return p.update(ctx, change, true)
+}
+
+// DeleteRecord deletes a DNS record.
+func (p *RFC2136Provider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	name, recordType, err := parseRRSetID(recordID)
+	if err != nil {
+		return err
+	}
+	rrtype, ok := mdns.StringToType[strings.ToUpper(recordType)]
+	if !ok {
+		return fmt.Errorf("unknown record type %s", recordType)
+	}
+
+	msg := new(mdns.Msg)
+	msg.SetUpdate(mdns.Fqdn(p.zone))
+	msg.RemoveRRset([]mdns.RR{&mdns.ANY{Hdr: mdns.RR_Header{Name: p.fqdn(name), Rrtype: rrtype}}})
+	if _, err := p.exchange(ctx, msg); err != nil {
+		return fmt.Errorf("failed to delete record %s: %w", recordID, err)
+	}
+	return nil
+}
+
+// ValidateCredentials checks if the provider credentials are valid by
+// asking the server for the zone's SOA record with a signed query.
+func (p *RFC2136Provider) ValidateCredentials(ctx context.Context) error {
+	if p.server == "" {
+		return fmt.Errorf("name server is required")
+	}
+	if p.zone == "" {
+		return fmt.Errorf("zone name is required")
+	}
+	if (p.keyName == "") != (p.secret == "") {
+		return fmt.Errorf("TSIG key name and secret must be set together")
+	}
+
+	msg := new(mdns.Msg)
+	msg.SetQuestion(mdns.Fqdn(p.zone), mdns.TypeSOA)
+	resp, err := p.exchange(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+	if !resp.Authoritative {
+		return fmt.Errorf("%s is not authoritative for %s", p.server, p.zone)
+	}
+
+	return nil
+}
+
+// update sends the records of a change, replacing the existing record set
+// first if replace is set. Both happen in one message, so the server
+// applies them atomically.
+func (p *RFC2136Provider) update(ctx context.Context, change *cutover.DNSChange, replace bool) error {
+	name, err := nameInZone(change, p.zone)
+	if err != nil {
+		return err
+	}
+
+	values := changeValues(change)
+	if len(values) == 0 {
+		return fmt.Errorf("DNS change %s has no value", change.ID)
+	}
+
+	recordType := strings.ToUpper(change.RecordType)
+	rrs := make([]mdns.RR, 0, len(values))
+	for _, value := range values {
+		rr, err := mdns.NewRR(fmt.Sprintf("%s %d IN %s %s", p.fqdn(name), change.TTL, recordType, zoneValue(recordType, value, change)))
+		if err != nil {
+			return fmt.Errorf("invalid %s value %q: %w", recordType, value, err)
+		}
+		rrs = append(rrs, rr)
+	}
+
+	msg := new(mdns.Msg)
+	msg.SetUpdate(mdns.Fqdn(p.zone))
+	if replace {
+		msg.RemoveRRset(rrs[:1])
+	}
+	msg.Insert(rrs)
+	if _, err := p.exchange(ctx, msg); err != nil {
+		return fmt.Errorf("failed to update record %s %s: %w", recordType, change.FullName(), err)
+	}
+
+	markApplied(change, rrsetID(name, recordType))
+	return nil
+}
+
+// exchange signs and sends msg over TCP. The response's signature is
+// checked by the client.
+func (p *RFC2136Provider) exchange(ctx context.Context, msg *mdns.Msg) (*mdns.Msg, error) {
+	p.sign(msg)
+
+	client := &mdns.Client{
+		Net:        "tcp",
+		Timeout:    rfc2136Timeout,
+		TsigSecret: p.tsigSecret(),
+	}
+	resp, _, err := client.ExchangeContext(ctx, msg, p.server)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.Rcode != mdns.RcodeSuccess {
+		return nil, &RFC2136Error{Rcode: resp.Rcode}
+	}
+	return resp, nil
+}
+
+func (p *RFC2136Provider) sign(msg *mdns.Msg) {
+	if p.keyName != "" {
+		msg.SetTsig(p.keyName, p.algorithm, tsigFudge, time.Now().Unix())
+	}
+}
+
+func (p *RFC2136Provider) tsigSecret() map[string]string {
+	if p.keyName == "" {
+		return nil
+	}
+	return map[string]string{p.keyName: p.secret}
+}
+
+// fqdn returns the fully qualified form of a name within the zone.
+func (p *RFC2136Provider) fqdn(name string) string {
+	if name == "@" || name == "" {
+		return mdns.Fqdn(p.zone)
+	}
+	return mdns.Fqdn(name + "." + p.zone)
+}
+
+// records groups resource records into record sets, in the order they first
+// appear. Duplicate records, such as the SOA closing a zone transfer, are
+// dropped.
+func (p *RFC2136Provider) records(rrs []mdns.RR, domain string) []*cutover.DNSRecord {
+	var result []*cutover.DNSRecord
+	values := map[*cutover.DNSRecord][]string{}
+	sets := map[string]*cutover.DNSRecord{}
+	for _, rr := range rrs {
+		hdr := rr.Header()
+		fqdn := normalizeName(hdr.Name)
+		recordType := mdns.TypeToString[hdr.Rrtype]
+		id := rrsetID(relativeName(fqdn, p.zone), recordType)
+
+		record, ok := sets[id]
+		if !ok {
+			record = &cutover.DNSRecord{
+				ID:     id,
+				Domain: domain,
+				Type:   recordType,
+				Name:   relativeName(fqdn, domain),
+				TTL:    int(hdr.Ttl),
+			}
+			sets[id] = record
+			result = append(result, record)
+		}
+
+		value := strings.TrimPrefix(rr.String(), hdr.String())
+		if !slices.Contains(values[record], value) {
+			values[record] = append(values[record], value)
+		}
+	}
+
+	for _, record := range result {
+		setZoneValues(record, values[record])
+	}
+	return result
+}
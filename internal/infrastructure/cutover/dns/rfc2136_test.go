@@ -0,0 +1,221 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+const (
+	testTSIGKey    = "homeport."
+	testTSIGSecret = "c2VjcmV0LXNlY3JldC1zZWNyZXQtc2VjcmV0LXNlY3JldA=="
+)
+
+// rfc2136StandIn is a name server for example.com that applies signed
+// dynamic updates to records kept in memory and serves them by query and
+// zone transfer.
+type rfc2136StandIn struct {
+	t *testing.T
+
+	mu  gosync.Mutex
+	rrs []mdns.RR
+}
+
+func newRFC2136StandIn(t *testing.T, records ...string) (*rfc2136StandIn, string) {
+	t.Helper()
+	standIn := &rfc2136StandIn{t: t}
+	for _, record := range append([]string{"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 1 7200 900 1209600 300"}, records...) {
+		standIn.rrs = append(standIn.rrs, mustRR(t, record))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &mdns.Server{
+		Listener:   listener,
+		Handler:    standIn,
+		TsigSecret: map[string]string{testTSIGKey: testTSIGSecret},
+		// The default accept function refuses updates
+		MsgAcceptFunc: func(mdns.Header) mdns.MsgAcceptAction { return mdns.MsgAccept },
+	}
+	started := make(chan struct{})
+	server.NotifyStartedFunc = func() { close(started) }
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return standIn, listener.Addr().String()
+}
+
+func mustRR(t *testing.T, s string) mdns.RR {
+	t.Helper()
+	rr, err := mdns.NewRR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rr
+}
+
+func (s *rfc2136StandIn) ServeDNS(w mdns.ResponseWriter, r *mdns.Msg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := new(mdns.Msg)
+	resp.SetReply(r)
+	resp.Authoritative = true
+	defer func() {
+		if r.IsTsig() != nil {
+			resp.SetTsig(testTSIGKey, mdns.HmacSHA256, tsigFudge, time.Now().Unix())
+		}
+		_ = w.WriteMsg(resp)
+	}()
+
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		resp.Rcode = mdns.RcodeNotAuth
+		return
+	}
+
+	question := r.Question[0]
+	switch {
+	case r.Opcode == mdns.OpcodeUpdate:
+		for _, rr := range r.Ns {
+			hdr := rr.Header()
+			switch hdr.Class {
+			case mdns.ClassANY:
+				s.rrs = removeRRset(s.rrs, hdr.Name, hdr.Rrtype)
+			case mdns.ClassINET:
+				s.rrs = append(s.rrs, rr)
+			}
+		}
+	case question.Qtype == mdns.TypeAXFR:
+		resp.Answer = append(append([]mdns.RR{}, s.rrs...), s.rrs[0])
+	default:
+		for _, rr := range s.rrs {
+			if rr.Header().Rrtype == question.Qtype && strings.EqualFold(rr.Header().Name, question.Name) {
+				resp.Answer = append(resp.Answer, rr)
+			}
+		}
+	}
+}
+
+func removeRRset(rrs []mdns.RR, name string, rrtype uint16) []mdns.RR {
+	var kept []mdns.RR
+	for _, rr := range rrs {
+		if rr.Header().Rrtype != rrtype || !strings.EqualFold(rr.Header().Name, name) {
+			kept = append(kept, rr)
+		}
+	}
+	return kept
+}
+
+func newTestRFC2136Provider(server string) *RFC2136Provider {
+	return NewRFC2136Provider(&RFC2136Config{
+		Server:      server,
+		Zone:        "example.com",
+		TSIGKeyName: "homeport",
+		TSIGSecret:  testTSIGSecret,
+	})
+}
+
+func TestRFC2136UpdateRecordReplacesTheRecordSet(t *testing.T) {
+	_, server := newRFC2136StandIn(t,
+		"www.app.example.com. 3600 IN A 192.0.2.1",
+		"www.app.example.com. 3600 IN A 192.0.2.2",
+		"www.app.example.com. 3600 IN AAAA 2001:db8::1",
+	)
+	provider := newTestRFC2136Provider(server)
+
+	change := &cutover.DNSChange{
+		ID:         "c1",
+		Domain:     "app.example.com",
+		RecordType: "A",
+		Name:       "www",
+		NewValue:   "198.51.100.1",
+		TTL:        60,
+	}
+	if err := provider.UpdateRecord(context.Background(), change); err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+	if change.Status != cutover.DNSChangeStatusApplied || change.ProviderRecordID != "www.app/A" {
+		t.Errorf("change not applied: status %s, record %q", change.Status, change.ProviderRecordID)
+	}
+
+	records, err := provider.ListRecords(context.Background(), "app.example.com")
+	if err != nil {
+		t.Fatalf("ListRecords() error = %v", err)
+	}
+	var got []string
+	for _, r := range records {
+		got = append(got, r.ID+" "+r.Name+" "+r.Value)
+	}
+	want := "www.app/AAAA www 2001:db8::1; www.app/A www 198.51.100.1"
+	if strings.Join(got, "; ") != want {
+		t.Errorf("records = %v, want %s", got, want)
+	}
+}
+
+func TestRFC2136CreateGetAndDeleteRecord(t *testing.T) {
+	_, server := newRFC2136StandIn(t)
+	provider := newTestRFC2136Provider(server)
+	ctx := context.Background()
+
+	change := &cutover.DNSChange{
+		ID:         "c1",
+		Domain:     "example.com",
+		RecordType: "MX",
+		Name:       "@",
+		NewValue:   "mail.example.com.",
+		TTL:        300,
+		Priority:   intPtr(10),
+	}
+	if err := provider.CreateRecord(ctx, change); err != nil {
+		t.Fatalf("CreateRecord() error = %v", err)
+	}
+
+	record, err := provider.GetRecord(ctx, "example.com", change.ProviderRecordID)
+	if err != nil {
+		t.Fatalf("GetRecord() error = %v", err)
+	}
+	if record.Name != "@" || record.Value != "mail.example.com." || record.Priority == nil || *record.Priority != 10 || record.TTL != 300 {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	if err := provider.DeleteRecord(ctx, "example.com", change.ProviderRecordID); err != nil {
+		t.Fatalf("DeleteRecord() error = %v", err)
+	}
+	if _, err := provider.GetRecord(ctx, "example.com", change.ProviderRecordID); err == nil {
+		t.Error("GetRecord() found the deleted record")
+	}
+}
+
+func TestRFC2136ValidateCredentials(t *testing.T) {
+	_, server := newRFC2136StandIn(t)
+	if err := newTestRFC2136Provider(server).ValidateCredentials(context.Background()); err != nil {
+		t.Errorf("ValidateCredentials() error = %v", err)
+	}
+
+	unsigned := NewRFC2136Provider(&RFC2136Config{Server: server, Zone: "example.com"})
+	if err := unsigned.ValidateCredentials(context.Background()); err == nil || !strings.Contains(err.Error(), "NOTAUTH") {
+		t.Errorf("ValidateCredentials() without a key error = %v", err)
+	}
+}
+
+func TestNewRFC2136ProviderDefaults(t *testing.T) {
+	p := NewRFC2136Provider(&RFC2136Config{Server: "ns1.example.com", Zone: "Example.com.", TSIGKeyName: "Key"})
+	if p.server != "ns1.example.com:53" || p.zone != "example.com" || p.keyName != "key." || p.algorithm != mdns.HmacSHA256 {
+		t.Errorf("unexpected provider %+v", p)
+	}
+
+	p = NewRFC2136Provider(&RFC2136Config{Server: "[2001:db8::53]:5353", TSIGAlgorithm: "HMAC-SHA512"})
+	if p.server != "[2001:db8::53]:5353" || p.algorithm != mdns.HmacSHA512 {
+		t.Errorf("unexpected provider %+v", p)
+	}
+}
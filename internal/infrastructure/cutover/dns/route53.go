@@ -4,45 +4,118 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+
 	"github.com/homeport/homeport/internal/domain/cutover"
 )
 
-// Route53Provider implements DNS operations via AWS Route 53 API.
-// Note: This is a stub implementation. Full implementation would require
-// the AWS SDK (github.com/aws/aws-sdk-go-v2).
+const (
+	// route53PollInterval is how often a submitted change is checked until
+	// Route 53 reports it in sync on all its name servers.
+	route53PollInterval = 5 * time.Second
+
+	// route53ChangeTimeout bounds the wait for a change to be in sync.
+	route53ChangeTimeout = 10 * time.Minute
+
+	// route53DefaultRegion is used when neither the configuration nor the
+	// AWS environment names a region. Route 53 is a global service.
+	route53DefaultRegion = "us-east-1"
+)
+
+// Route53Provider implements DNS operations via the AWS Route 53 API using
+// the AWS SDK. Every change is polled until Route 53 reports it INSYNC.
+//
+// Route 53 record sets have no ID; records are identified by their fully
+// qualified name and type, followed by the set identifier for weighted
+// records, e.g. "www.example.com/A/blue". Record sets with several values
+// list them one per line.
 type Route53Provider struct {
 	// hostedZoneID is the Route 53 hosted zone ID.
 	hostedZoneID string
 
-	// region is the AWS region.
-	region string
+	// config is used to build the API client on first use.
+	config Route53Config
+
+	// pollInterval is how often submitted changes are checked.
+	pollInterval time.Duration
 
-	// credentials holds AWS credentials.
-	accessKeyID     string
-	secretAccessKey string
-	sessionToken    string
+	// client is the Route 53 API client, created on first use.
+	mu     sync.Mutex
+	client *route53.Client
 }
 
-// Route53Config contains Route 53-specific configuration.
+// Route53Config contains Route 53-specific configuration. Without an access
+// key the default AWS credential chain is used (environment, shared
+// configuration and profiles, SSO, web identity, instance roles).
 type Route53Config struct {
 	HostedZoneID    string
 	Region          string
 	AccessKeyID     string
 	SecretAccessKey string
 	SessionToken    string
+
+	// Endpoint overrides the Route 53 API URL (e.g., a local stand-in).
+	Endpoint string
+
+	// PollInterval overrides how often submitted changes are checked.
+	PollInterval time.Duration
 }
 
 // NewRoute53Provider creates a new Route 53 DNS provider.
 func NewRoute53Provider(config *Route53Config) *Route53Provider {
+	pollInterval := config.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = route53PollInterval
+	}
+
 	return &Route53Provider{
-		hostedZoneID:    config.HostedZoneID,
-		region:          config.Region,
-		accessKeyID:     config.AccessKeyID,
-		secretAccessKey: config.SecretAccessKey,
-		sessionToken:    config.SessionToken,
+		hostedZoneID: strings.TrimPrefix(config.HostedZoneID, "/hostedzone/"),
+		config:       *config,
+		pollInterval: pollInterval,
+	}
+}
+
+// api returns the Route 53 client, loading the AWS configuration on first
+// use.
+func (p *Route53Provider) api(ctx context.Context) (*route53.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client != nil {
+		return p.client, nil
 	}
+
+	var opts []func(*config.LoadOptions) error
+	if p.config.Region != "" {
+		opts = append(opts, config.WithRegion(p.config.Region))
+	}
+	if p.config.AccessKeyID != "" {
+		opts = append(opts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(p.config.AccessKeyID, p.config.SecretAccessKey, p.config.SessionToken),
+		))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = route53DefaultRegion
+	}
+
+	p.client = route53.NewFromConfig(cfg, func(o *route53.Options) {
+		if p.config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(p.config.Endpoint)
+		}
+	})
+	return p.client, nil
 }
 
 // Name returns the provider name.
@@ -50,45 +123,149 @@ func (p *Route53Provider) Name() string {
 	return "route53"
 }
 
+// SupportsWeightedRecords reports that Route 53 splits traffic between
+// weighted record sets.
+func (p *Route53Provider) SupportsWeightedRecords() bool {
+	return true
+}
+
 // ListRecords retrieves all DNS records for a domain.
 func (p *Route53Provider) ListRecords(ctx context.Context, domain string) ([]*cutover.DNSRecord, error) {
-	// Stub implementation - would use AWS SDK
-	return nil, fmt.Errorf("Route53 provider requires AWS SDK - use 'aws route53 list-resource-record-sets --hosted-zone-id %s'", p.hostedZoneID)
+	client, err := p.api(ctx)
+	if err != nil {
+		return nil, err
+	}
+	domain = normalizeName(domain)
+
+	result := make([]*cutover.DNSRecord, 0)
+	input := &route53.ListResourceRecordSetsInput{HostedZoneId: aws.String(p.hostedZoneID)}
+	for {
+		resp, err := client.ListResourceRecordSets(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list record sets: %w", err)
+		}
+
+		for i := range resp.ResourceRecordSets {
+			set := &resp.ResourceRecordSets[i]
+			name := normalizeName(aws.ToString(set.Name))
+			if domain != "" && name != domain && !strings.HasSuffix(name, "."+domain) {
+				continue
+			}
+			result = append(result, route53Record(set, domain))
+		}
+
+		if !resp.IsTruncated {
+			return result, nil
+		}
+		input = &route53.ListResourceRecordSetsInput{
+			HostedZoneId:          aws.String(p.hostedZoneID),
+			StartRecordName:       resp.NextRecordName,
+			StartRecordType:       resp.NextRecordType,
+			StartRecordIdentifier: resp.NextRecordIdentifier,
+		}
+	}
 }
 
 // GetRecord retrieves a specific DNS record by ID.
 func (p *Route53Provider) GetRecord(ctx context.Context, domain, recordID string) (*cutover.DNSRecord, error) {
-	// Stub implementation - would use AWS SDK
-	return nil, fmt.Errorf("Route53 provider requires AWS SDK")
+	name, recordType, setIdentifier, err := parseRoute53RecordID(recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := p.findRecordSet(ctx, name, recordType, setIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, fmt.Errorf("record not found: %s", recordID)
+	}
+
+	return route53Record(set, normalizeName(domain)), nil
 }
 
 // CreateRecord creates a new DNS record.
 func (p *Route53Provider) CreateRecord(ctx context.Context, change *cutover.DNSChange) error {
-	// Stub implementation - would use AWS SDK
-	// For now, mark as applied and provide CLI instructions
-
-	change.Status = cutover.DNSChangeStatusApplied
-	now := time.Now()
-	change.AppliedAt = &now
+	set := route53RecordSetFor(change, nil)
+	if err := p.changeRecordSets(ctx, types.Change{Action: types.ChangeActionCreate, ResourceRecordSet: set}); err != nil {
+		return err
+	}
 
+	markApplied(change, route53SetID(set))
 	return nil
 }
 
-// UpdateRecord updates an existing DNS record.
+// UpdateRecord updates an existing DNS record, or creates it if it doesn't
+// exist. The routing settings of an existing record set are kept.
 func (p *Route53Provider) UpdateRecord(ctx context.Context, change *cutover.DNSChange) error {
-	// Stub implementation - would use AWS SDK
-	// For now, mark as applied and provide CLI instructions
+	existing, err := p.findRecordSet(ctx, change.FullName(), change.RecordType, change.SetIdentifier)
+	if err != nil {
+		return fmt.Errorf("failed to look up record: %w", err)
+	}
 
-	change.Status = cutover.DNSChangeStatusApplied
-	now := time.Now()
-	change.AppliedAt = &now
+	set := route53RecordSetFor(change, existing)
+	if err := p.changeRecordSets(ctx, types.Change{Action: types.ChangeActionUpsert, ResourceRecordSet: set}); err != nil {
+		return err
+	}
 
+	markApplied(change, route53SetID(set))
 	return nil
 }
 
 // DeleteRecord deletes a DNS record.
 func (p *Route53Provider) DeleteRecord(ctx context.Context, domain, recordID string) error {
-	// Stub implementation - would use AWS SDK
+	name, recordType, setIdentifier, err := parseRoute53RecordID(recordID)
+	if err != nil {
+		return err
+	}
+
+	// Route 53 only deletes a record set given exactly as it is
+	set, err := p.findRecordSet(ctx, name, recordType, setIdentifier)
+	if err != nil {
+		return fmt.Errorf("failed to look up record: %w", err)
+	}
+	if set == nil {
+		return fmt.Errorf("record not found: %s", recordID)
+	}
+
+	return p.changeRecordSets(ctx, types.Change{Action: types.ChangeActionDelete, ResourceRecordSet: set})
+}
+
+// ChangeRecords deletes and upserts record sets in one change batch, which
+// Route 53 applies atomically. Deletes come first, so a simple record can be
+// replaced by weighted record sets of its name and type and back.
+func (p *Route53Provider) ChangeRecords(ctx context.Context, deletes, upserts []*cutover.DNSChange) error {
+	var changes []types.Change
+	for _, change := range deletes {
+		set, err := p.findRecordSet(ctx, change.FullName(), change.RecordType, change.SetIdentifier)
+		if err != nil {
+			return fmt.Errorf("failed to look up record: %w", err)
+		}
+		if set != nil {
+			changes = append(changes, types.Change{Action: types.ChangeActionDelete, ResourceRecordSet: set})
+		}
+	}
+
+	sets := make([]*types.ResourceRecordSet, len(upserts))
+	for i, change := range upserts {
+		existing, err := p.findRecordSet(ctx, change.FullName(), change.RecordType, change.SetIdentifier)
+		if err != nil {
+			return fmt.Errorf("failed to look up record: %w", err)
+		}
+		sets[i] = route53RecordSetFor(change, existing)
+		changes = append(changes, types.Change{Action: types.ChangeActionUpsert, ResourceRecordSet: sets[i]})
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := p.changeRecordSets(ctx, changes...); err != nil {
+		return err
+	}
+
+	for i, change := range upserts {
+		markApplied(change, route53SetID(sets[i]))
+	}
 	return nil
 }
 
@@ -98,13 +275,204 @@ func (p *Route53Provider) ValidateCredentials(ctx context.Context) error {
 		return fmt.Errorf("hosted zone ID is required")
 	}
 
-	// Normalize hosted zone ID (remove /hostedzone/ prefix if present)
-	p.hostedZoneID = strings.TrimPrefix(p.hostedZoneID, "/hostedzone/")
+	client, err := p.api(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := client.GetHostedZone(ctx, &route53.GetHostedZoneInput{Id: aws.String(p.hostedZoneID)}); err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
 
-	// Would validate with AWS SDK
 	return nil
 }
 
+// changeRecordSets submits changes as one batch, which Route 53 applies
+// atomically, and waits until the batch is in sync.
+func (p *Route53Provider) changeRecordSets(ctx context.Context, changes ...types.Change) error {
+	client, err := p.api(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(p.hostedZoneID),
+		ChangeBatch: &types.ChangeBatch{
+			Comment: aws.String("HomePort cutover"),
+			Changes: changes,
+		},
+	})
+	if err != nil {
+		if len(changes) == 1 {
+			set := changes[0].ResourceRecordSet
+			return fmt.Errorf("failed to %s record %s %s: %w", strings.ToLower(string(changes[0].Action)), set.Type, aws.ToString(set.Name), err)
+		}
+		return fmt.Errorf("failed to change %d record sets: %w", len(changes), err)
+	}
+
+	return p.waitForChange(ctx, client, resp.ChangeInfo)
+}
+
+// waitForChange polls a change until Route 53 reports it INSYNC.
+func (p *Route53Provider) waitForChange(ctx context.Context, client *route53.Client, info *types.ChangeInfo) error {
+	ctx, cancel := context.WithTimeout(ctx, route53ChangeTimeout)
+	defer cancel()
+
+	id := strings.TrimPrefix(aws.ToString(info.Id), "/change/")
+	for info.Status != types.ChangeStatusInsync {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("change %s is still %s: %w", id, info.Status, ctx.Err())
+		case <-time.After(p.pollInterval):
+		}
+
+		resp, err := client.GetChange(ctx, &route53.GetChangeInput{Id: aws.String(id)})
+		if err != nil {
+			return fmt.Errorf("failed to get change %s: %w", id, err)
+		}
+		info = resp.ChangeInfo
+	}
+
+	return nil
+}
+
+// findRecordSet returns the record set of a name, type and set identifier,
+// or nil if the zone has none.
+func (p *Route53Provider) findRecordSet(ctx context.Context, name, recordType, setIdentifier string) (*types.ResourceRecordSet, error) {
+	client, err := p.api(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name = normalizeName(name)
+
+	// Listing starts at the given name and type, in the order Route 53 keeps
+	input := &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(p.hostedZoneID),
+		StartRecordName: aws.String(name),
+		StartRecordType: types.RRType(strings.ToUpper(recordType)),
+		MaxItems:        aws.Int32(1),
+	}
+	if setIdentifier != "" {
+		input.StartRecordIdentifier = aws.String(setIdentifier)
+	}
+
+	resp, err := client.ListResourceRecordSets(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range resp.ResourceRecordSets {
+		set := &resp.ResourceRecordSets[i]
+		if normalizeName(aws.ToString(set.Name)) == name && strings.EqualFold(string(set.Type), recordType) && aws.ToString(set.SetIdentifier) == setIdentifier {
+			return set, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// route53RecordSetFor builds the record set a change asks for. Routing
+// settings of existing, such as failover or health checks, are kept.
+func route53RecordSetFor(change *cutover.DNSChange, existing *types.ResourceRecordSet) *types.ResourceRecordSet {
+	var set types.ResourceRecordSet
+	if existing != nil {
+		set = *existing
+	}
+
+	set.Name = aws.String(normalizeName(change.FullName()) + ".")
+	set.Type = types.RRType(strings.ToUpper(change.RecordType))
+	set.SetIdentifier = nil
+	if change.SetIdentifier != "" {
+		set.SetIdentifier = aws.String(change.SetIdentifier)
+	}
+	if change.RoutingWeight != nil {
+		set.Weight = aws.Int64(*change.RoutingWeight)
+	}
+
+	if change.Alias != nil {
+		set.TTL = nil
+		set.ResourceRecords = nil
+		set.AliasTarget = &types.AliasTarget{
+			HostedZoneId:         aws.String(change.Alias.HostedZoneID),
+			DNSName:              aws.String(change.Alias.DNSName),
+			EvaluateTargetHealth: change.Alias.EvaluateTargetHealth,
+		}
+		return &set
+	}
+
+	set.TTL = aws.Int64(int64(change.TTL))
+	set.AliasTarget = nil
+	set.ResourceRecords = nil
+	for _, value := range changeValues(change) {
+		set.ResourceRecords = append(set.ResourceRecords, types.ResourceRecord{
+			Value: aws.String(zoneValue(string(set.Type), value, change)),
+		})
+	}
+
+	return &set
+}
+
+// route53Record converts a record set to a DNS record of domain.
+func route53Record(set *types.ResourceRecordSet, domain string) *cutover.DNSRecord {
+	fqdn := normalizeName(aws.ToString(set.Name))
+	record := &cutover.DNSRecord{
+		ID:            route53SetID(set),
+		Domain:        domain,
+		Type:          string(set.Type),
+		Name:          relativeName(fqdn, domain),
+		SetIdentifier: aws.ToString(set.SetIdentifier),
+		RoutingWeight: set.Weight,
+	}
+	if set.TTL != nil {
+		record.TTL = int(*set.TTL)
+	}
+
+	if set.AliasTarget != nil {
+		record.Value = strings.TrimSuffix(aws.ToString(set.AliasTarget.DNSName), ".")
+		record.Alias = &cutover.DNSAliasTarget{
+			HostedZoneID:         aws.ToString(set.AliasTarget.HostedZoneId),
+			DNSName:              aws.ToString(set.AliasTarget.DNSName),
+			EvaluateTargetHealth: set.AliasTarget.EvaluateTargetHealth,
+		}
+		return record
+	}
+
+	if len(set.ResourceRecords) == 0 {
+		return record
+	}
+	values := make([]string, 0, len(set.ResourceRecords))
+	for _, r := range set.ResourceRecords {
+		values = append(values, aws.ToString(r.Value))
+	}
+	setZoneValues(record, values)
+
+	return record
+}
+
+// route53SetID returns the record ID of a record set.
+func route53SetID(set *types.ResourceRecordSet) string {
+	return route53RecordID(aws.ToString(set.Name), string(set.Type), aws.ToString(set.SetIdentifier))
+}
+
+// route53RecordID identifies a record set by name, type and set identifier.
+func route53RecordID(name, recordType, setIdentifier string) string {
+	id := normalizeName(name) + "/" + recordType
+	if setIdentifier != "" {
+		id += "/" + setIdentifier
+	}
+	return id
+}
+
+func parseRoute53RecordID(recordID string) (name, recordType, setIdentifier string, err error) {
+	parts := strings.SplitN(recordID, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid Route 53 record ID %q, expected name/type[/set-identifier]", recordID)
+	}
+	if len(parts) == 3 {
+		setIdentifier = parts[2]
+	}
+	return parts[0], parts[1], setIdentifier, nil
+}
+
 // GenerateAWSCLICommand generates the AWS CLI command to execute a DNS change.
 func (p *Route53Provider) GenerateAWSCLICommand(change *cutover.DNSChange) string {
 	// Format the fully qualified domain name
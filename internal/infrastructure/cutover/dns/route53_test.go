@@ -0,0 +1,420 @@
+package dns
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+// route53RecordSet is a resource record set as the Route 53 API encodes it.
+// The field order follows the API schema.
+type route53RecordSet struct {
+	Name            string                  `xml:"Name"`
+	Type            string                  `xml:"Type"`
+	SetIdentifier   string                  `xml:"SetIdentifier,omitempty"`
+	Weight          *int64                  `xml:"Weight,omitempty"`
+	TTL             *int                    `xml:"TTL,omitempty"`
+	ResourceRecords *route53ResourceRecords `xml:"ResourceRecords,omitempty"`
+	AliasTarget     *route53AliasTarget     `xml:"AliasTarget,omitempty"`
+	HealthCheckID   string                  `xml:"HealthCheckId,omitempty"`
+}
+
+type route53ResourceRecords struct {
+	Records []route53ResourceRecord `xml:"ResourceRecord"`
+}
+
+type route53ResourceRecord struct {
+	Value string `xml:"Value"`
+}
+
+type route53AliasTarget struct {
+	HostedZoneID         string `xml:"HostedZoneId"`
+	DNSName              string `xml:"DNSName"`
+	EvaluateTargetHealth bool   `xml:"EvaluateTargetHealth"`
+}
+
+type route53ChangeRequest struct {
+	XMLName     xml.Name `xml:"ChangeResourceRecordSetsRequest"`
+	Xmlns       string   `xml:"xmlns,attr"`
+	ChangeBatch struct {
+		Comment string          `xml:"Comment"`
+		Changes []route53Change `xml:"Changes>Change"`
+	} `xml:"ChangeBatch"`
+}
+
+type route53Change struct {
+	Action            string           `xml:"Action"`
+	ResourceRecordSet route53RecordSet `xml:"ResourceRecordSet"`
+}
+
+type route53ListResponse struct {
+	XMLName              xml.Name           `xml:"ListResourceRecordSetsResponse"`
+	RecordSets           []route53RecordSet `xml:"ResourceRecordSets>ResourceRecordSet"`
+	IsTruncated          bool               `xml:"IsTruncated"`
+	NextRecordName       string             `xml:"NextRecordName,omitempty"`
+	NextRecordType       string             `xml:"NextRecordType,omitempty"`
+	NextRecordIdentifier string             `xml:"NextRecordIdentifier,omitempty"`
+}
+
+// route53StandIn is a local stand-in for the Route 53 API that keeps record
+// sets in memory and reports changes INSYNC after a few polls.
+type route53StandIn struct {
+	t *testing.T
+
+	mu          gosync.Mutex
+	sets        []route53RecordSet
+	changes     []route53Change
+	batches     int
+	polls       int
+	pollsToSync int
+	pageSize    int
+	failures    []string
+}
+
+func newRoute53StandIn(t *testing.T, sets ...route53RecordSet) (*route53StandIn, *Route53Provider) {
+	t.Helper()
+	standIn := &route53StandIn{t: t, sets: sets, pollsToSync: 2, pageSize: 100}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	provider := NewRoute53Provider(&Route53Config{
+		HostedZoneID:    "/hostedzone/Z123",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Endpoint:        server.URL,
+		PollInterval:    time.Millisecond,
+	})
+	return standIn, provider
+}
+
+func (s *route53StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/route53/aws4_request") {
+		s.t.Errorf("unexpected Authorization header %q", auth)
+	}
+	if !strings.Contains(auth, "x-amz-security-token") || r.Header.Get("X-Amz-Security-Token") != "session" {
+		s.t.Errorf("session token is not sent and signed: %q", auth)
+	}
+
+	if len(s.failures) > 0 {
+		code := s.failures[0]
+		s.failures = s.failures[1:]
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, `<ErrorResponse><Error><Type>Sender</Type><Code>%s</Code><Message>%s happened</Message></Error></ErrorResponse>`, code, code)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/hostedzone/Z123":
+		_, _ = io.WriteString(w, `<GetHostedZoneResponse><HostedZone><Id>/hostedzone/Z123</Id></HostedZone></GetHostedZoneResponse>`)
+	case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/hostedzone/Z123/rrset":
+		s.list(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/2013-04-01/hostedzone/Z123/rrset":
+		s.change(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/change/C42":
+		s.polls++
+		status := "PENDING"
+		if s.polls >= s.pollsToSync {
+			status = "INSYNC"
+		}
+		_, _ = fmt.Fprintf(w, `<GetChangeResponse><ChangeInfo><Id>/change/C42</Id><Status>%s</Status></ChangeInfo></GetChangeResponse>`, status)
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *route53StandIn) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start := 0
+	// Record sets are kept in listing order; a listing starts at the one
+	// named, or past the end when there is none
+	if name := query.Get("name"); name != "" {
+		start = len(s.sets)
+		for i, set := range s.sets {
+			if normalizeName(set.Name) == normalizeName(name) && set.Type == query.Get("type") && set.SetIdentifier == query.Get("identifier") {
+				start = i
+				break
+			}
+		}
+	}
+	limit := s.pageSize
+	if query.Get("maxitems") == "1" {
+		limit = 1
+	}
+
+	resp := route53ListResponse{}
+	end := min(start+limit, len(s.sets))
+	resp.RecordSets = s.sets[start:end]
+	if end < len(s.sets) {
+		resp.IsTruncated = true
+		resp.NextRecordName = s.sets[end].Name
+		resp.NextRecordType = s.sets[end].Type
+		resp.NextRecordIdentifier = s.sets[end].SetIdentifier
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	_ = xml.NewEncoder(w).Encode(resp)
+}
+
+func (s *route53StandIn) change(w http.ResponseWriter, r *http.Request) {
+	var request route53ChangeRequest
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.t.Fatalf("failed to decode change request: %v", err)
+	}
+	if request.Xmlns != "https://route53.amazonaws.com/doc/2013-04-01/" {
+		s.t.Errorf("unexpected namespace %s", request.Xmlns)
+	}
+
+	s.batches++
+	for _, change := range request.ChangeBatch.Changes {
+		s.changes = append(s.changes, change)
+		set := change.ResourceRecordSet
+		index := -1
+		for i, existing := range s.sets {
+			if existing.Name == set.Name && existing.Type == set.Type && existing.SetIdentifier == set.SetIdentifier {
+				index = i
+			}
+		}
+		switch {
+		case change.Action == "DELETE" && index >= 0:
+			s.sets = append(s.sets[:index], s.sets[index+1:]...)
+		case change.Action != "DELETE" && index >= 0:
+			s.sets[index] = set
+		case change.Action != "DELETE":
+			s.sets = append(s.sets, set)
+		}
+	}
+
+	s.polls = 0
+	_, _ = io.WriteString(w, `<ChangeResourceRecordSetsResponse><ChangeInfo><Id>/change/C42</Id><Status>PENDING</Status></ChangeInfo></ChangeResourceRecordSetsResponse>`)
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func intPtr(v int) *int { return &v }
+
+func TestRoute53UpdateRecordUpsertsAndWaitsForSync(t *testing.T) {
+	standIn, provider := newRoute53StandIn(t, route53RecordSet{
+		Name:            "www.example.com.",
+		Type:            "A",
+		TTL:             intPtr(60),
+		ResourceRecords: &route53ResourceRecords{Records: []route53ResourceRecord{{Value: "192.0.2.1"}}},
+		HealthCheckID:   "hc-1",
+	})
+
+	change := cutover.NewDNSChange("dns-1", "example.com", "A", "www", "192.0.2.1", "198.51.100.7\n198.51.100.8")
+	if err := provider.UpdateRecord(context.Background(), change); err != nil {
+		t.Fatalf("UpdateRecord failed: %v", err)
+	}
+
+	if change.Status != cutover.DNSChangeStatusApplied || change.AppliedAt == nil {
+		t.Errorf("expected change to be applied, got %s", change.Status)
+	}
+	if change.ProviderRecordID != "www.example.com/A" {
+		t.Errorf("unexpected record ID %q", change.ProviderRecordID)
+	}
+	if standIn.polls < standIn.pollsToSync {
+		t.Errorf("expected the change to be polled until INSYNC, got %d polls", standIn.polls)
+	}
+
+	if len(standIn.changes) != 1 || standIn.changes[0].Action != "UPSERT" {
+		t.Fatalf("expected one UPSERT, got %+v", standIn.changes)
+	}
+	set := standIn.changes[0].ResourceRecordSet
+	if set.Name != "www.example.com." || *set.TTL != 300 || len(set.ResourceRecords.Records) != 2 {
+		t.Errorf("unexpected record set %+v", set)
+	}
+	if set.HealthCheckID != "hc-1" {
+		t.Errorf("expected the health check of the existing record to be kept, got %q", set.HealthCheckID)
+	}
+}
+
+func TestRoute53CreatesAliasAndWeightedRecords(t *testing.T) {
+	standIn, provider := newRoute53StandIn(t)
+
+	alias := cutover.NewDNSChange("dns-1", "example.com", "A", "@", "", "")
+	alias.Alias = &cutover.DNSAliasTarget{
+		HostedZoneID:         "Z2FDTNDATAQYW2",
+		DNSName:              "d111111abcdef8.cloudfront.net.",
+		EvaluateTargetHealth: true,
+	}
+	if errs := alias.Validate(); len(errs) > 0 {
+		t.Fatalf("alias change should be valid: %v", errs)
+	}
+	if err := provider.CreateRecord(context.Background(), alias); err != nil {
+		t.Fatalf("CreateRecord failed for alias: %v", err)
+	}
+
+	weighted := cutover.NewDNSChange("dns-2", "example.com", "A", "api", "", "203.0.113.10")
+	weighted.SetIdentifier = "homeport"
+	weighted.RoutingWeight = int64Ptr(10)
+	if err := provider.UpdateRecord(context.Background(), weighted); err != nil {
+		t.Fatalf("UpdateRecord failed for weighted record: %v", err)
+	}
+
+	if len(standIn.changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(standIn.changes))
+	}
+
+	aliasSet := standIn.changes[0].ResourceRecordSet
+	if standIn.changes[0].Action != "CREATE" || aliasSet.AliasTarget == nil || aliasSet.TTL != nil || aliasSet.ResourceRecords != nil {
+		t.Errorf("unexpected alias change %+v", standIn.changes[0])
+	}
+	if aliasSet.AliasTarget.HostedZoneID != "Z2FDTNDATAQYW2" || !aliasSet.AliasTarget.EvaluateTargetHealth {
+		t.Errorf("unexpected alias target %+v", aliasSet.AliasTarget)
+	}
+
+	weightedSet := standIn.changes[1].ResourceRecordSet
+	if weightedSet.SetIdentifier != "homeport" || weightedSet.Weight == nil || *weightedSet.Weight != 10 {
+		t.Errorf("unexpected weighted record set %+v", weightedSet)
+	}
+	if weighted.ProviderRecordID != "api.example.com/A/homeport" {
+		t.Errorf("unexpected record ID %q", weighted.ProviderRecordID)
+	}
+}
+
+func TestRoute53ListRecordsFollowsPagesAndDecodesRecords(t *testing.T) {
+	standIn, provider := newRoute53StandIn(t,
+		route53RecordSet{Name: "example.com.", Type: "A", AliasTarget: &route53AliasTarget{HostedZoneID: "Z35SXDOTRQ7X7K", DNSName: "lb-1.eu-west-1.elb.amazonaws.com."}},
+		route53RecordSet{Name: "example.com.", Type: "MX", TTL: intPtr(300), ResourceRecords: &route53ResourceRecords{Records: []route53ResourceRecord{{Value: "10 mail.example.com."}}}},
+		route53RecordSet{Name: "example.com.", Type: "TXT", TTL: intPtr(300), ResourceRecords: &route53ResourceRecords{Records: []route53ResourceRecord{{Value: `"v=spf1 -all"`}}}},
+		route53RecordSet{Name: "api.example.com.", Type: "A", SetIdentifier: "blue", Weight: int64Ptr(90), TTL: intPtr(60), ResourceRecords: &route53ResourceRecords{Records: []route53ResourceRecord{{Value: "192.0.2.1"}}}},
+		route53RecordSet{Name: `\052.example.com.`, Type: "CNAME", TTL: intPtr(60), ResourceRecords: &route53ResourceRecords{Records: []route53ResourceRecord{{Value: "example.com"}}}},
+	)
+	standIn.pageSize = 2
+
+	records, err := provider.ListRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("ListRecords failed: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records across pages, got %d", len(records))
+	}
+
+	if records[0].Name != "@" || records[0].Alias == nil || records[0].Value != "lb-1.eu-west-1.elb.amazonaws.com" {
+		t.Errorf("unexpected alias record %+v", records[0])
+	}
+	if records[1].Priority == nil || *records[1].Priority != 10 || records[1].Value != "mail.example.com." {
+		t.Errorf("unexpected MX record %+v", records[1])
+	}
+	if records[2].Value != "v=spf1 -all" {
+		t.Errorf("expected unquoted TXT value, got %q", records[2].Value)
+	}
+	if records[3].ID != "api.example.com/A/blue" || records[3].RoutingWeight == nil || *records[3].RoutingWeight != 90 {
+		t.Errorf("unexpected weighted record %+v", records[3])
+	}
+	if records[4].Name != "*" {
+		t.Errorf("expected escaped wildcard to be decoded, got %q", records[4].Name)
+	}
+
+	change := records[0].ToChange("dns-1", "203.0.113.10")
+	if change.OldAlias == nil || change.OldAlias.HostedZoneID != "Z35SXDOTRQ7X7K" {
+		t.Errorf("expected the change to remember the alias it replaces, got %+v", change)
+	}
+}
+
+func TestRoute53DeleteRecordSendsTheRecordSetAsListed(t *testing.T) {
+	standIn, provider := newRoute53StandIn(t, route53RecordSet{
+		Name:            "api.example.com.",
+		Type:            "A",
+		SetIdentifier:   "blue",
+		Weight:          int64Ptr(90),
+		TTL:             intPtr(60),
+		ResourceRecords: &route53ResourceRecords{Records: []route53ResourceRecord{{Value: "192.0.2.1"}}},
+	})
+
+	if err := provider.DeleteRecord(context.Background(), "example.com", "api.example.com/A/blue"); err != nil {
+		t.Fatalf("DeleteRecord failed: %v", err)
+	}
+	if len(standIn.sets) != 0 {
+		t.Errorf("expected record set to be deleted, %d left", len(standIn.sets))
+	}
+	deleted := standIn.changes[0].ResourceRecordSet
+	if standIn.changes[0].Action != "DELETE" || deleted.Weight == nil || *deleted.Weight != 90 || *deleted.TTL != 60 {
+		t.Errorf("unexpected DELETE change %+v", standIn.changes[0])
+	}
+
+	if err := provider.DeleteRecord(context.Background(), "example.com", "api.example.com/A/blue"); err == nil {
+		t.Error("expected an error when deleting a missing record")
+	}
+}
+
+func TestRoute53ReportsAPIErrors(t *testing.T) {
+	standIn, provider := newRoute53StandIn(t)
+
+	if err := provider.ValidateCredentials(context.Background()); err != nil {
+		t.Fatalf("ValidateCredentials failed: %v", err)
+	}
+
+	standIn.failures = []string{"InvalidChangeBatch"}
+	change := cutover.NewDNSChange("dns-1", "example.com", "A", "www", "", "192.0.2.1")
+	err := provider.CreateRecord(context.Background(), change)
+	if err == nil || !strings.Contains(err.Error(), "InvalidChangeBatch") {
+		t.Fatalf("expected InvalidChangeBatch error, got %v", err)
+	}
+	if change.Status == cutover.DNSChangeStatusApplied {
+		t.Error("failed change must not be marked as applied")
+	}
+}
+
+func TestRoute53ChangeRecordsSubmitsOneBatch(t *testing.T) {
+	standIn, provider := newRoute53StandIn(t, route53RecordSet{
+		Name:            "www.example.com.",
+		Type:            "A",
+		TTL:             intPtr(300),
+		ResourceRecords: &route53ResourceRecords{Records: []route53ResourceRecord{{Value: "198.51.100.20"}}},
+	})
+
+	simple := cutover.NewDNSChange("original", "example.com", "A", "www", "198.51.100.20", "")
+	missing := cutover.NewDNSChange("missing", "example.com", "A", "www", "", "")
+	missing.SetIdentifier = "gone"
+	upserts := make([]*cutover.DNSChange, 0, 2)
+	for _, w := range []struct {
+		id, value string
+		weight    int64
+	}{{"homeport", "203.0.113.10", 5}, {"legacy", "198.51.100.20", 95}} {
+		change := cutover.NewDNSChange(w.id, "example.com", "A", "www", "", w.value)
+		change.TTL = 60
+		change.SetIdentifier = w.id
+		change.RoutingWeight = int64Ptr(w.weight)
+		upserts = append(upserts, change)
+	}
+
+	if err := provider.ChangeRecords(context.Background(), []*cutover.DNSChange{simple, missing}, upserts); err != nil {
+		t.Fatalf("ChangeRecords failed: %v", err)
+	}
+
+	if len(standIn.changes) != 3 || standIn.batches != 1 {
+		t.Fatalf("expected 3 changes in 1 batch, got %d in %d", len(standIn.changes), standIn.batches)
+	}
+	deleted := standIn.changes[0]
+	if deleted.Action != "DELETE" || deleted.ResourceRecordSet.SetIdentifier != "" || *deleted.ResourceRecordSet.TTL != 300 {
+		t.Errorf("expected the simple record to be deleted as listed first, got %+v", deleted)
+	}
+	for i, change := range standIn.changes[1:] {
+		set := change.ResourceRecordSet
+		if change.Action != "UPSERT" || set.SetIdentifier != upserts[i].SetIdentifier || *set.Weight != *upserts[i].RoutingWeight {
+			t.Errorf("unexpected weighted change %+v", change)
+		}
+	}
+	if len(standIn.sets) != 2 {
+		t.Errorf("expected only the weighted record sets to be left, got %+v", standIn.sets)
+	}
+	if upserts[0].ProviderRecordID != "www.example.com/A/homeport" {
+		t.Errorf("unexpected record ID %q", upserts[0].ProviderRecordID)
+	}
+}
@@ -0,0 +1,387 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+const scalewayAPIBase = "https://api.scaleway.com/domain/v2beta1"
+
+// ScalewayProvider implements DNS operations via the Scaleway Domains and
+// DNS API. All values of a name and type are replaced in a single request.
+type ScalewayProvider struct {
+	// secretKey is the Scaleway API secret key.
+	secretKey string
+
+	// zone is the DNS zone name (e.g., "example.com").
+	zone string
+
+	// endpoint is the base URL of the Scaleway Domains API.
+	endpoint string
+
+	// client is the HTTP client.
+	client *http.Client
+}
+
+// ScalewayConfig contains Scaleway-specific configuration.
+type ScalewayConfig struct {
+	SecretKey string
+	Zone      string
+
+	// Endpoint overrides the Scaleway Domains API URL (e.g., a local
+	// stand-in).
+	Endpoint string
+}
+
+// NewScalewayProvider creates a new Scaleway DNS provider.
+func NewScalewayProvider(config *ScalewayConfig) *ScalewayProvider {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = scalewayAPIBase
+	}
+
+	return &ScalewayProvider{
+		secretKey: config.SecretKey,
+		zone:      normalizeName(config.Zone),
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name.
+func (p *ScalewayProvider) Name() string {
+	return "scaleway"
+}
+
+// scalewayRecord represents a DNS record in Scaleway. Names are relative to
+// the zone and empty for the zone apex. MX and SRV priorities are kept apart
+// from the data.
+type scalewayRecord struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Data     string `json:"data"`
+	TTL      int    `json:"ttl"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+type scalewayRecordsResponse struct {
+	Records    []scalewayRecord `json:"records"`
+	TotalCount int              `json:"total_count"`
+}
+
+type scalewayIDFields struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// scalewayChange is one change of a PATCH request; exactly one field is set.
+type scalewayChange struct {
+	Add    *scalewayAddChange    `json:"add,omitempty"`
+	Set    *scalewaySetChange    `json:"set,omitempty"`
+	Delete *scalewayDeleteChange `json:"delete,omitempty"`
+}
+
+type scalewayAddChange struct {
+	Records []scalewayRecord `json:"records"`
+}
+
+// scalewaySetChange replaces every record of a name and type.
+type scalewaySetChange struct {
+	IDFields scalewayIDFields `json:"id_fields"`
+	Records  []scalewayRecord `json:"records"`
+}
+
+type scalewayDeleteChange struct {
+	ID string `json:"id"`
+}
+
+type scalewayPatchRequest struct {
+	Changes                 []scalewayChange `json:"changes"`
+	DisallowNewZoneCreation bool             `json:"disallow_new_zone_creation"`
+}
+
+// ScalewayError is an error returned by the Scaleway API.
+type ScalewayError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *ScalewayError) Error() string {
+	return fmt.Sprintf("scaleway API error (%d %s): %s", e.StatusCode, e.Type, e.Message)
+}
+
+// ListRecords retrieves all DNS records for a domain.
+func (p *ScalewayProvider) ListRecords(ctx context.Context, domain string) ([]*cutover.DNSRecord, error) {
+	records, err := p.listRecords(ctx, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*cutover.DNSRecord, 0, len(records))
+	for i := range records {
+		result = append(result, scalewayDNSRecord(&records[i], domain))
+	}
+	return result, nil
+}
+
+// GetRecord retrieves a specific DNS record by ID.
+func (p *ScalewayProvider) GetRecord(ctx context.Context, domain, recordID string) (*cutover.DNSRecord, error) {
+	query := url.Values{}
+	query.Set("id", recordID)
+	records, err := p.listRecords(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("record not found: %s", recordID)
+	}
+	return scalewayDNSRecord(&records[0], domain), nil
+}
+
+// CreateRecord creates a new DNS record.
+func (p *ScalewayProvider) CreateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	name, records, err := p.recordsFor(change)
+	if err != nil {
+		return err
+	}
+
+	created, err := p.patch(ctx, scalewayChange{Add: &scalewayAddChange{Records: records}})
+	if err != nil {
+		return fmt.Errorf("failed to create record %s %s: %w", change.RecordType, change.FullName(), err)
+	}
+
+	markApplied(change, scalewayRecordID(created, name, change.RecordType))
+	return nil
+}
+
+// UpdateRecord replaces the records of the change's name and type with its
+// values, or creates them if they don't exist.
+func (p *ScalewayProvider) UpdateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	name, records, err := p.recordsFor(change)
+	if err != nil {
+		return err
+	}
+
+	updated, err := p.patch(ctx, scalewayChange{Set: &scalewaySetChange{
+		IDFields: scalewayIDFields{Name: scalewayName(name), Type: strings.ToUpper(change.RecordType)},
+		Records:  records,
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to update record %s %s: %w", change.RecordType, change.FullName(), err)
+	}
+
+	markApplied(change, scalewayRecordID(updated, name, change.RecordType))
+	return nil
+}
+
+// DeleteRecord deletes a DNS record.
+func (p *ScalewayProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	if _, err := p.patch(ctx, scalewayChange{Delete: &scalewayDeleteChange{ID: recordID}}); err != nil {
+		return fmt.Errorf("failed to delete record %s: %w", recordID, err)
+	}
+	return nil
+}
+
+// ValidateCredentials checks if the provider credentials are valid.
+func (p *ScalewayProvider) ValidateCredentials(ctx context.Context) error {
+	if p.secretKey == "" {
+		return fmt.Errorf("secret key is required")
+	}
+	if p.zone == "" {
+		return fmt.Errorf("zone name is required")
+	}
+
+	query := url.Values{}
+	query.Set("dns_zone", p.zone)
+	var resp struct {
+		TotalCount int `json:"total_count"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/dns-zones", query, nil, &resp); err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+	if resp.TotalCount == 0 {
+		return fmt.Errorf("zone %s not found", p.zone)
+	}
+
+	return nil
+}
+
+// recordsFor returns the name of the change's record within the zone and
+// the records holding its values.
+func (p *ScalewayProvider) recordsFor(change *cutover.DNSChange) (string, []scalewayRecord, error) {
+	name, err := nameInZone(change, p.zone)
+	if err != nil {
+		return "", nil, err
+	}
+
+	values := changeValues(change)
+	if len(values) == 0 {
+		return "", nil, fmt.Errorf("DNS change %s has no value", change.ID)
+	}
+
+	recordType := strings.ToUpper(change.RecordType)
+	records := make([]scalewayRecord, 0, len(values))
+	for _, value := range values {
+		record := scalewayRecord{
+			Name: scalewayName(name),
+			Type: recordType,
+			Data: zoneValue(recordType, value, change),
+			TTL:  change.TTL,
+		}
+		// Scaleway takes the MX and SRV priority as a field of its own
+		if recordType == "MX" || recordType == "SRV" {
+			if fields := strings.SplitN(record.Data, " ", 2); len(fields) == 2 {
+				if priority, err := strconv.Atoi(fields[0]); err == nil {
+					record.Priority = priority
+					record.Data = fields[1]
+				}
+			}
+		}
+		records = append(records, record)
+	}
+	return name, records, nil
+}
+
+func (p *ScalewayProvider) listRecords(ctx context.Context, query url.Values) ([]scalewayRecord, error) {
+	var records []scalewayRecord
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		query.Set("page_size", "100")
+
+		var resp scalewayRecordsResponse
+		if err := p.do(ctx, http.MethodGet, p.zonePath()+"/records", query, nil, &resp); err != nil {
+			return nil, err
+		}
+		records = append(records, resp.Records...)
+
+		if len(resp.Records) == 0 || len(records) >= resp.TotalCount {
+			return records, nil
+		}
+	}
+}
+
+// patch applies a change to the zone and returns the records it touched.
+func (p *ScalewayProvider) patch(ctx context.Context, change scalewayChange) ([]scalewayRecord, error) {
+	request := scalewayPatchRequest{
+		Changes:                 []scalewayChange{change},
+		DisallowNewZoneCreation: true,
+	}
+
+	var resp scalewayRecordsResponse
+	if err := p.do(ctx, http.MethodPatch, p.zonePath()+"/records", nil, request, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Records, nil
+}
+
+func (p *ScalewayProvider) zonePath() string {
+	return "/dns-zones/" + url.PathEscape(p.zone)
+}
+
+// do sends a request to the Scaleway API and decodes the response into out.
+func (p *ScalewayProvider) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.endpoint+path, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.URL.RawQuery = query.Encode()
+	req.Header.Set("X-Auth-Token", p.secretKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		apiErr := &ScalewayError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var errResp struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &errResp) == nil && errResp.Message != "" {
+			apiErr.Type = errResp.Type
+			apiErr.Message = errResp.Message
+		}
+		return apiErr
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// scalewayName converts a relative name to Scaleway's, which is empty for
+// the zone apex.
+func scalewayName(name string) string {
+	if name == "@" {
+		return ""
+	}
+	return name
+}
+
+// scalewayRecordID returns the ID of the first record of a name and type.
+func scalewayRecordID(records []scalewayRecord, name, recordType string) string {
+	for _, r := range records {
+		if strings.EqualFold(r.Name, scalewayName(name)) && strings.EqualFold(r.Type, recordType) {
+			return r.ID
+		}
+	}
+	return ""
+}
+
+func scalewayDNSRecord(r *scalewayRecord, domain string) *cutover.DNSRecord {
+	name := r.Name
+	if name == "" {
+		name = "@"
+	}
+
+	record := &cutover.DNSRecord{
+		ID:     r.ID,
+		Domain: domain,
+		Type:   r.Type,
+		Name:   name,
+		TTL:    r.TTL,
+	}
+	value := r.Data
+	if r.Type == "MX" || r.Type == "SRV" {
+		value = strconv.Itoa(r.Priority) + " " + value
+	}
+	setZoneValues(record, []string{value})
+	return record
+}
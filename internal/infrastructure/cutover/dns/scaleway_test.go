@@ -0,0 +1,100 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+func TestScalewayUpdateRecordSetsTheRecordSet(t *testing.T) {
+	var got scalewayPatchRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != "secret" {
+			t.Errorf("unexpected X-Auth-Token %q", r.Header.Get("X-Auth-Token"))
+		}
+		if r.Method != http.MethodPatch || r.URL.Path != "/dns-zones/example.com/records" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = fmt.Fprint(w, `{"records":[{"id":"other","name":"","type":"A","data":"192.0.2.1"},{"id":"mx1","name":"","type":"MX","data":"mx1.example.com.","priority":10}]}`)
+	}))
+	defer server.Close()
+
+	provider := NewScalewayProvider(&ScalewayConfig{SecretKey: "secret", Zone: "example.com", Endpoint: server.URL})
+	change := &cutover.DNSChange{
+		ID:         "c1",
+		Domain:     "example.com",
+		RecordType: "MX",
+		Name:       "@",
+		NewValue:   "10 mx1.example.com.\n20 mx2.example.com.",
+		TTL:        300,
+	}
+	if err := provider.UpdateRecord(context.Background(), change); err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+	if change.Status != cutover.DNSChangeStatusApplied || change.ProviderRecordID != "mx1" {
+		t.Errorf("change not applied: status %s, record %q", change.Status, change.ProviderRecordID)
+	}
+
+	if len(got.Changes) != 1 || got.Changes[0].Set == nil || !got.DisallowNewZoneCreation {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	set := got.Changes[0].Set
+	if set.IDFields != (scalewayIDFields{Name: "", Type: "MX"}) {
+		t.Errorf("id_fields = %+v", set.IDFields)
+	}
+	want := []scalewayRecord{
+		{Name: "", Type: "MX", Data: "mx1.example.com.", TTL: 300, Priority: 10},
+		{Name: "", Type: "MX", Data: "mx2.example.com.", TTL: 300, Priority: 20},
+	}
+	if fmt.Sprint(set.Records) != fmt.Sprint(want) {
+		t.Errorf("records = %+v, want %+v", set.Records, want)
+	}
+}
+
+func TestScalewayListRecordsFollowsPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = fmt.Fprint(w, `{"total_count":2,"records":[{"id":"a","name":"www","type":"A","data":"192.0.2.1","ttl":60}]}`)
+		case "2":
+			_, _ = fmt.Fprint(w, `{"total_count":2,"records":[{"id":"b","name":"_sip._tcp","type":"SRV","data":"5 5060 sip.example.com.","ttl":60,"priority":10}]}`)
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewScalewayProvider(&ScalewayConfig{SecretKey: "secret", Zone: "example.com", Endpoint: server.URL})
+	records, err := provider.ListRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("ListRecords() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("ListRecords() = %d records, want 2", len(records))
+	}
+	srv := records[1]
+	if srv.Value != "sip.example.com." || *srv.Priority != 10 || *srv.Weight != 5 || *srv.Port != 5060 {
+		t.Errorf("unexpected SRV record %+v", srv)
+	}
+}
+
+func TestScalewayValidateCredentialsReportsMissingZone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("dns_zone") != "example.com" {
+			t.Errorf("unexpected dns_zone %q", r.URL.Query().Get("dns_zone"))
+		}
+		_, _ = fmt.Fprint(w, `{"total_count":0,"dns_zones":[]}`)
+	}))
+	defer server.Close()
+
+	provider := NewScalewayProvider(&ScalewayConfig{SecretKey: "secret", Zone: "example.com", Endpoint: server.URL})
+	if err := provider.ValidateCredentials(context.Background()); err == nil || err.Error() != "zone example.com not found" {
+		t.Errorf("ValidateCredentials() error = %v", err)
+	}
+}
@@ -1,11 +1,12 @@
 // Package cutover implements the cutover orchestrator for managing
-// migration cutover operations including DNS changes, health checks,
-// and automatic rollback.
+// migration cutover operations including DNS changes, progressive traffic
+// shifts, health checks, and automatic rollback.
 package cutover
 
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,7 +14,7 @@ import (
 )
 
 // Orchestrator coordinates cutover execution including pre-checks,
-// DNS changes, post-checks, and rollback operations.
+// DNS changes, traffic shifts, post-checks, and rollback operations.
 type Orchestrator struct {
 	// dnsProviders maps provider names to implementations.
 	dnsProviders map[string]cutover.DNSProvider
@@ -39,12 +40,25 @@ type OrchestratorOptions struct {
 	// Manual mode generates instructions instead of executing.
 	Manual bool
 
+	// TraefikConfigDir is the Traefik dynamic configuration directory that
+	// weighted routes of Traefik traffic shifts are written to.
+	TraefikConfigDir string
+
 	// Timeout is the maximum time for the entire cutover.
 	Timeout time.Duration
 
 	// Verbose enables detailed output.
 	Verbose bool
 
+	// Journal records every DNS mutation before and after it is made. A
+	// mutation is not made if it cannot be journaled first.
+	Journal func(mutation cutover.DNSMutation) error
+
+	// Checkpoint persists the plan once the live record of a DNS change has
+	// been captured, before the record is changed. The change is not made
+	// if the plan cannot be persisted.
+	Checkpoint func(plan *cutover.CutoverPlan) error
+
 	// OnStepStart is called when a step starts.
 	OnStepStart func(step *cutover.CutoverStep)
 
@@ -58,11 +72,12 @@ type OrchestratorOptions struct {
 // DefaultOptions returns default orchestrator options.
 func DefaultOptions() *OrchestratorOptions {
 	return &OrchestratorOptions{
-		DryRun:      false,
-		DNSProvider: "manual",
-		Manual:      false,
-		Timeout:     30 * time.Minute,
-		Verbose:     false,
+		DryRun:           false,
+		DNSProvider:      "manual",
+		Manual:           false,
+		TraefikConfigDir: "traefik/dynamic",
+		Timeout:          30 * time.Minute,
+		Verbose:          false,
 	}
 }
 
@@ -169,7 +184,18 @@ func (o *Orchestrator) Execute(ctx context.Context, plan *cutover.CutoverPlan, o
 	plan.ExecutedAt = &now
 	plan.DryRun = opts.DryRun
 
-	// Execute steps
+	// Route Traefik traffic shifts to the old endpoint before DNS changes
+	// send traffic to Traefik
+	if !opts.DryRun {
+		if err := o.armTrafficShifts(plan, opts); err != nil {
+			result.Error = err
+			plan.Status = cutover.CutoverStatusFailed
+			plan.Error = err.Error()
+			return result, err
+		}
+	}
+
+	// Execute steps, skipping those a resumed plan already completed
 	for i, step := range plan.Steps {
 		select {
 		case <-ctx.Done():
@@ -179,6 +205,11 @@ func (o *Orchestrator) Execute(ctx context.Context, plan *cutover.CutoverPlan, o
 		default:
 		}
 
+		if step.Status == cutover.CutoverStepStatusCompleted {
+			result.StepsCompleted++
+			continue
+		}
+
 		plan.CurrentStepIndex = i
 
 		if opts.OnStepStart != nil {
@@ -249,6 +280,8 @@ func (o *Orchestrator) executeStep(ctx context.Context, plan *cutover.CutoverPla
 		return o.executeHealthCheck(ctx, plan, step, true, opts)
 	case cutover.CutoverStepTypeDNSChange:
 		return o.executeDNSChange(ctx, plan, step, opts)
+	case cutover.CutoverStepTypeTrafficShift:
+		return o.executeTrafficShift(ctx, plan, step, opts)
 	case cutover.CutoverStepTypePostCheck:
 		return o.executeHealthCheck(ctx, plan, step, false, opts)
 	default:
@@ -309,10 +342,22 @@ func (o *Orchestrator) executeDNSChange(ctx context.Context, plan *cutover.Cutov
 		return fmt.Errorf("DNS change not found: %s", step.ReferenceID)
 	}
 
+	output, err := o.ApplyDNSChange(ctx, plan, change, opts)
+	step.Output = output
+	return err
+}
+
+// ApplyDNSChange makes a DNS change of plan and returns what was done. The
+// live record is captured and the plan checkpointed before the record is
+// changed, so a rollback restores what was there.
+func (o *Orchestrator) ApplyDNSChange(ctx context.Context, plan *cutover.CutoverPlan, change *cutover.DNSChange, opts *OrchestratorOptions) (string, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
 	if opts.DryRun {
-		step.Output = fmt.Sprintf("[DRY RUN] Would change %s record %s from %s to %s",
-			change.RecordType, change.FullName(), change.OldValue, change.NewValue)
-		return nil
+		return fmt.Sprintf("[DRY RUN] Would change %s record %s from %s to %s",
+			change.RecordType, change.FullName(), change.OldValue, change.NewValue), nil
 	}
 
 	// Get DNS provider
@@ -322,31 +367,139 @@ func (o *Orchestrator) executeDNSChange(ctx context.Context, plan *cutover.Cutov
 	}
 	if providerName == "" || providerName == "manual" {
 		// For manual provider, just mark as applied (user handles it)
+		if err := o.mutateDNS(plan.ID, cutover.DNSMutationApply, "manual", change, opts, func() error { return nil }); err != nil {
+			return "", err
+		}
 		change.Status = cutover.DNSChangeStatusApplied
 		now := time.Now()
 		change.AppliedAt = &now
-		step.Output = fmt.Sprintf("DNS change marked as applied (manual): %s -> %s", change.FullName(), change.NewValue)
-		return nil
+		return fmt.Sprintf("DNS change marked as applied (manual): %s -> %s", change.FullName(), change.NewValue), nil
 	}
 
 	provider, ok := o.GetDNSProvider(providerName)
 	if !ok {
-		return fmt.Errorf("DNS provider not found: %s", providerName)
+		return "", fmt.Errorf("DNS provider not found: %s", providerName)
+	}
+
+	// Capture the live record once, so a rollback restores what was there
+	// even when the plan does not know the old value
+	if change.CapturedAt == nil {
+		previous, err := liveRecord(ctx, provider, change)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the current record of %s: %w", change.FullName(), err)
+		}
+		now := time.Now()
+		change.Previous = previous
+		change.CapturedAt = &now
+
+		if opts.Checkpoint != nil {
+			if err := opts.Checkpoint(plan); err != nil {
+				return "", fmt.Errorf("failed to persist the current record of %s: %w", change.FullName(), err)
+			}
+		}
 	}
 
 	// Apply the change
-	if err := provider.UpdateRecord(ctx, change); err != nil {
+	err := o.mutateDNS(plan.ID, cutover.DNSMutationApply, providerName, change, opts, func() error {
+		return provider.UpdateRecord(ctx, change)
+	})
+	if err != nil {
 		change.Status = cutover.DNSChangeStatusFailed
 		change.Error = err.Error()
-		return fmt.Errorf("failed to apply DNS change: %w", err)
+		return "", fmt.Errorf("failed to apply DNS change: %w", err)
 	}
 
 	change.Status = cutover.DNSChangeStatusApplied
 	now := time.Now()
 	change.AppliedAt = &now
-	step.Output = fmt.Sprintf("DNS change applied: %s -> %s", change.FullName(), change.NewValue)
+	return fmt.Sprintf("DNS change applied: %s -> %s", change.FullName(), change.NewValue), nil
+}
 
-	return nil
+// liveRecord returns the record of a change's name, type and set identifier
+// as the provider has it, or nil if there is none.
+func liveRecord(ctx context.Context, provider cutover.DNSProvider, change *cutover.DNSChange) (*cutover.DNSRecord, error) {
+	records, err := provider.ListRecords(ctx, change.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(change.FullName(), ".")
+	for _, record := range records {
+		if record.SetIdentifier == change.SetIdentifier && strings.EqualFold(record.Type, change.RecordType) &&
+			strings.EqualFold(strings.TrimSuffix(record.FullName(), "."), name) {
+			return record, nil
+		}
+	}
+	return nil, nil
+}
+
+// reverseDNSChange returns the change that reverts change. The captured
+// live record is restored when there is one, the plan's old value otherwise.
+func reverseDNSChange(change *cutover.DNSChange) *cutover.DNSChange {
+	reverse := &cutover.DNSChange{
+		ID:               change.ID + "-rollback",
+		Domain:           change.Domain,
+		RecordType:       change.RecordType,
+		Name:             change.Name,
+		OldValue:         change.NewValue,
+		NewValue:         change.OldValue,
+		TTL:              change.TTL,
+		Provider:         change.Provider,
+		ProviderRecordID: change.ProviderRecordID,
+		ProxyEnabled:     change.ProxyEnabled,
+		Alias:            change.OldAlias,
+		OldAlias:         change.Alias,
+		SetIdentifier:    change.SetIdentifier,
+		RoutingWeight:    change.RoutingWeight,
+	}
+
+	if previous := change.Previous; previous != nil {
+		reverse.NewValue = previous.Value
+		reverse.TTL = previous.TTL
+		reverse.Priority = previous.Priority
+		reverse.Weight = previous.Weight
+		reverse.Port = previous.Port
+		reverse.ProxyEnabled = previous.ProxyEnabled
+		reverse.Alias = previous.Alias
+		reverse.RoutingWeight = previous.RoutingWeight
+	}
+
+	return reverse
+}
+
+// mutateDNS makes a DNS mutation with apply and journals it. The mutation is
+// not made if it cannot be journaled first.
+func (o *Orchestrator) mutateDNS(planID string, action cutover.DNSMutationAction, provider string, change *cutover.DNSChange, opts *OrchestratorOptions, apply func() error) error {
+	return o.mutateDNSBatch(planID, action, provider, []*cutover.DNSChange{change}, opts, apply)
+}
+
+// mutateDNSBatch journals changes that apply makes in one provider call.
+// Every change is journaled as started before apply runs and finished with
+// its outcome after.
+func (o *Orchestrator) mutateDNSBatch(planID string, action cutover.DNSMutationAction, provider string, changes []*cutover.DNSChange, opts *OrchestratorOptions, apply func() error) error {
+	if opts.Journal == nil {
+		return apply()
+	}
+
+	mutations := make([]cutover.DNSMutation, len(changes))
+	for i, change := range changes {
+		mutations[i] = cutover.NewDNSMutation(planID, action, provider, change)
+		if err := opts.Journal(mutations[i]); err != nil {
+			err = fmt.Errorf("failed to journal DNS mutation: %w", err)
+			for _, started := range mutations[:i] {
+				_ = opts.Journal(started.Finished(err))
+			}
+			return err
+		}
+	}
+
+	err := apply()
+	// The records already changed, so failing to journal the outcome must
+	// not fail the step; the started entries show the mutation was attempted.
+	for _, mutation := range mutations {
+		_ = opts.Journal(mutation.Finished(err))
+	}
+	return err
 }
 
 // shouldRollback determines if a rollback should be triggered.
@@ -368,71 +521,88 @@ func (o *Orchestrator) shouldRollback(plan *cutover.CutoverPlan, failedStep *cut
 		}
 	}
 
-	// Default: rollback on post-check failure
-	return failedStep.Type == cutover.CutoverStepTypePostCheck
+	// Default: rollback on post-check or traffic shift gate failure
+	return failedStep.Type == cutover.CutoverStepTypePostCheck ||
+		failedStep.Type == cutover.CutoverStepTypeTrafficShift
 }
 
-// Rollback reverts all applied DNS changes.
+// Rollback sends shifted traffic back and reverts all applied DNS changes.
 func (o *Orchestrator) Rollback(ctx context.Context, plan *cutover.CutoverPlan, opts *OrchestratorOptions) error {
 	if opts == nil {
 		opts = DefaultOptions()
 	}
 
+	// Send shifted traffic back to the old infrastructure first
+	if !opts.DryRun {
+		for i := len(plan.TrafficShifts) - 1; i >= 0; i-- {
+			if err := o.RollbackTrafficShift(ctx, plan.ID, plan.TrafficShifts[i], opts); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Revert DNS changes in reverse order
 	for i := len(plan.DNSChanges) - 1; i >= 0; i-- {
-		change := plan.DNSChanges[i]
-		if !change.CanRollback() {
-			continue
+		if err := o.RollbackDNSChange(ctx, plan, plan.DNSChanges[i], opts); err != nil {
+			return err
 		}
+	}
 
-		if opts.DryRun {
-			continue
-		}
+	// Mark plan as rolled back
+	plan.Status = cutover.CutoverStatusRolledBack
+	now := time.Now()
+	plan.RolledBackAt = &now
 
-		// Create reverse change
-		reverseChange := &cutover.DNSChange{
-			ID:               change.ID + "-rollback",
-			Domain:           change.Domain,
-			RecordType:       change.RecordType,
-			Name:             change.Name,
-			OldValue:         change.NewValue,
-			NewValue:         change.OldValue,
-			TTL:              change.TTL,
-			Provider:         change.Provider,
-			ProviderRecordID: change.ProviderRecordID,
-			ProxyEnabled:     change.ProxyEnabled,
-		}
+	return nil
+}
 
-		providerName := opts.DNSProvider
-		if providerName == "" {
-			providerName = change.Provider
-		}
-		if providerName == "" || providerName == "manual" {
-			change.Status = cutover.DNSChangeStatusRolledBack
-			now := time.Now()
-			change.RolledBackAt = &now
-			continue
-		}
+// RollbackDNSChange reverts a DNS change of plan, restoring the record
+// captured before it was applied. Changes that cannot be rolled back are
+// left alone.
+func (o *Orchestrator) RollbackDNSChange(ctx context.Context, plan *cutover.CutoverPlan, change *cutover.DNSChange, opts *OrchestratorOptions) error {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if !change.CanRollback() || opts.DryRun {
+		return nil
+	}
 
-		provider, ok := o.GetDNSProvider(providerName)
-		if !ok {
-			return fmt.Errorf("DNS provider not found for rollback: %s", providerName)
-		}
+	reverseChange := reverseDNSChange(change)
 
-		if err := provider.UpdateRecord(ctx, reverseChange); err != nil {
-			return fmt.Errorf("failed to rollback DNS change %s: %w", change.FullName(), err)
+	providerName := opts.DNSProvider
+	if providerName == "" {
+		providerName = change.Provider
+	}
+	if providerName == "" || providerName == "manual" {
+		if err := o.mutateDNS(plan.ID, cutover.DNSMutationRollback, "manual", reverseChange, opts, func() error { return nil }); err != nil {
+			return err
 		}
-
 		change.Status = cutover.DNSChangeStatusRolledBack
 		now := time.Now()
 		change.RolledBackAt = &now
+		return nil
 	}
 
-	// Mark plan as rolled back
-	plan.Status = cutover.CutoverStatusRolledBack
-	now := time.Now()
-	plan.RolledBackAt = &now
+	provider, ok := o.GetDNSProvider(providerName)
+	if !ok {
+		return fmt.Errorf("DNS provider not found for rollback: %s", providerName)
+	}
+
+	apply := func() error { return provider.UpdateRecord(ctx, reverseChange) }
+	if change.CapturedAt != nil && change.Previous == nil {
+		// The record did not exist before the change
+		reverseChange.NewValue = ""
+		apply = func() error { return provider.DeleteRecord(ctx, change.Domain, change.ProviderRecordID) }
+	}
 
+	err := o.mutateDNS(plan.ID, cutover.DNSMutationRollback, providerName, reverseChange, opts, apply)
+	if err != nil {
+		return fmt.Errorf("failed to rollback DNS change %s: %w", change.FullName(), err)
+	}
+
+	change.Status = cutover.DNSChangeStatusRolledBack
+	now := time.Now()
+	change.RolledBackAt = &now
 	return nil
 }
 
@@ -474,6 +644,17 @@ func (o *Orchestrator) generateManualInstructions(plan *cutover.CutoverPlan, res
 		}
 	}
 
+	// Traffic shifts
+	if len(plan.TrafficShifts) > 0 {
+		instructions = append(instructions, "## Traffic Shifts")
+		instructions = append(instructions, "")
+		for i, shift := range plan.TrafficShifts {
+			instructions = append(instructions, fmt.Sprintf("%d. %s", i+1, shift.Description()))
+			instructions = append(instructions, trafficShiftInstructions(shift)...)
+			instructions = append(instructions, "")
+		}
+	}
+
 	// Wait for propagation
 	instructions = append(instructions, "## DNS Propagation")
 	instructions = append(instructions, "")
@@ -496,6 +677,9 @@ func (o *Orchestrator) generateManualInstructions(plan *cutover.CutoverPlan, res
 	// Rollback instructions
 	instructions = append(instructions, "## Rollback Instructions")
 	instructions = append(instructions, "")
+	for _, shift := range plan.TrafficShifts {
+		instructions = append(instructions, fmt.Sprintf("Send all traffic for %s back to the old infrastructure (0%% new).", shift.FullName()))
+	}
 	instructions = append(instructions, "If issues occur, revert DNS changes:")
 	for i, change := range plan.DNSChanges {
 		instructions = append(instructions, fmt.Sprintf("%d. Revert %s record for %s to: %s", i+1, change.RecordType, change.FullName(), change.OldValue))
@@ -528,6 +712,13 @@ func (o *Orchestrator) ValidatePlan(ctx context.Context, plan *cutover.CutoverPl
 		}
 	}
 
+	// Validate a traffic shift method is available
+	for _, shift := range plan.TrafficShifts {
+		if _, err := o.trafficShiftMethod(shift, opts); err != nil {
+			issues = append(issues, fmt.Sprintf("traffic shift %s: %v", shift.ID, err))
+		}
+	}
+
 	// Validate health check endpoints are reachable
 	for _, check := range plan.PreChecks {
 		if errs := check.Validate(); len(errs) > 0 {
@@ -0,0 +1,157 @@
+package cutover
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+func newJournaledPlan() *cutover.CutoverPlan {
+	plan := cutover.NewCutoverPlan("plan", "bundle")
+	plan.DNSPropagationWait = 0
+	plan.AddDNSChange(cutover.NewDNSChange("dns-1", "example.com", "A", "www", "198.51.100.20", "203.0.113.10"))
+	return plan
+}
+
+func TestExecuteJournalsDNSMutations(t *testing.T) {
+	provider := newWeightedProvider()
+	orch := NewOrchestrator()
+	orch.RegisterDNSProvider("weighted", provider)
+
+	var journal []cutover.DNSMutation
+	opts := DefaultOptions()
+	opts.DNSProvider = "weighted"
+	opts.Journal = func(mutation cutover.DNSMutation) error {
+		journal = append(journal, mutation)
+		return nil
+	}
+
+	plan := newJournaledPlan()
+	if _, err := orch.Execute(context.Background(), plan, opts); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if err := orch.Rollback(context.Background(), plan, opts); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	want := []struct {
+		action   cutover.DNSMutationAction
+		status   cutover.DNSMutationStatus
+		newValue string
+	}{
+		{cutover.DNSMutationApply, cutover.DNSMutationStarted, "203.0.113.10"},
+		{cutover.DNSMutationApply, cutover.DNSMutationSucceeded, "203.0.113.10"},
+		{cutover.DNSMutationRollback, cutover.DNSMutationStarted, "198.51.100.20"},
+		{cutover.DNSMutationRollback, cutover.DNSMutationSucceeded, "198.51.100.20"},
+	}
+	if len(journal) != len(want) {
+		t.Fatalf("got %d journal entries, want %d", len(journal), len(want))
+	}
+	for i, w := range want {
+		got := journal[i]
+		if got.Action != w.action || got.Status != w.status || got.NewValue != w.newValue {
+			t.Errorf("entry %d = %s %s %s, want %s %s %s", i, got.Action, got.Status, got.NewValue, w.action, w.status, w.newValue)
+		}
+		if got.PlanID != "plan" || got.Provider != "weighted" {
+			t.Errorf("entry %d plan = %q provider = %q", i, got.PlanID, got.Provider)
+		}
+	}
+}
+
+func TestExecuteSkipsDNSMutationThatCannotBeJournaled(t *testing.T) {
+	provider := &weightedProvider{}
+	orch := NewOrchestrator()
+	orch.RegisterDNSProvider("weighted", provider)
+
+	opts := DefaultOptions()
+	opts.DNSProvider = "weighted"
+	opts.Journal = func(mutation cutover.DNSMutation) error {
+		return errors.New("disk full")
+	}
+
+	if _, err := orch.Execute(context.Background(), newJournaledPlan(), opts); err == nil {
+		t.Fatal("expected an error when the journal cannot be written")
+	}
+	if provider.batches != 0 {
+		t.Errorf("provider got %d changes, want none", provider.batches)
+	}
+}
+
+func TestExecuteCapturesLiveRecordBeforeChanging(t *testing.T) {
+	provider := newWeightedProvider()
+	orch := NewOrchestrator()
+	orch.RegisterDNSProvider("weighted", provider)
+
+	plan := cutover.NewCutoverPlan("plan", "bundle")
+	plan.DNSPropagationWait = 0
+	change := cutover.NewDNSChange("dns-1", "example.com", "A", "www", "", "203.0.113.10")
+	plan.AddDNSChange(change)
+
+	var journal []cutover.DNSMutation
+	checkpoints := 0
+	opts := DefaultOptions()
+	opts.DNSProvider = "weighted"
+	opts.Journal = func(mutation cutover.DNSMutation) error {
+		journal = append(journal, mutation)
+		return nil
+	}
+	opts.Checkpoint = func(p *cutover.CutoverPlan) error {
+		checkpoints++
+		if provider.records[""].Value != "198.51.100.20" {
+			t.Error("checkpoint ran after the record was changed")
+		}
+		if p.DNSChanges[0].Previous == nil {
+			t.Error("checkpoint ran before the live record was captured")
+		}
+		return nil
+	}
+
+	if _, err := orch.Execute(context.Background(), plan, opts); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if checkpoints != 1 {
+		t.Errorf("checkpoints = %d, want 1", checkpoints)
+	}
+	if change.Previous == nil || change.Previous.Value != "198.51.100.20" || change.Previous.TTL != 300 {
+		t.Fatalf("Previous = %+v, want the live record", change.Previous)
+	}
+	if len(journal) == 0 || journal[0].Previous == nil || journal[0].Previous.Value != "198.51.100.20" {
+		t.Errorf("expected the live record to be journaled before the change, got %+v", journal)
+	}
+	if !change.CanRollback() {
+		t.Fatal("expected a change with a captured record to be revertible without an old value")
+	}
+
+	if err := orch.Rollback(context.Background(), plan, opts); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if restored := provider.records[""]; restored.Value != "198.51.100.20" || restored.TTL != 300 {
+		t.Errorf("restored record = %+v, want the captured record", restored)
+	}
+}
+
+func TestRollbackDeletesRecordThatDidNotExist(t *testing.T) {
+	provider := &weightedProvider{}
+	orch := NewOrchestrator()
+	orch.RegisterDNSProvider("weighted", provider)
+
+	opts := DefaultOptions()
+	opts.DNSProvider = "weighted"
+	plan := newJournaledPlan()
+	change := plan.DNSChanges[0]
+	if _, err := orch.Execute(context.Background(), plan, opts); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if change.CapturedAt == nil || change.Previous != nil {
+		t.Fatalf("expected the missing record to be captured as nil, got %+v", change.Previous)
+	}
+
+	if err := orch.Rollback(context.Background(), plan, opts); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if len(provider.records) != 0 {
+		t.Errorf("expected the created record to be deleted, got %+v", provider.records)
+	}
+}
@@ -0,0 +1,590 @@
+package cutover
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+	"github.com/homeport/homeport/internal/infrastructure/generator/traefik"
+)
+
+// traefikRequestsMetric is the Traefik counter error rates are read from.
+const traefikRequestsMetric = "traefik_service_requests_total"
+
+// ShiftTraffic runs the stages of a traffic shift that are still ahead of
+// its current percentage. Each stage moves traffic, is observed for the
+// shift's stage duration and must pass the shift's gates; the first failed
+// stage is returned as an error, leaving the traffic where the stage put it.
+func (o *Orchestrator) ShiftTraffic(ctx context.Context, planID string, shift *cutover.TrafficShift, opts *OrchestratorOptions) (string, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	method, err := o.trafficShiftMethod(shift, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.DryRun {
+		return fmt.Sprintf("[DRY RUN] Would shift traffic for %s with %s: %s, observing each stage for %s",
+			shift.FullName(), method.DisplayName(), strings.Join(stageLabels(shift.Stages), " → "), shift.StageDuration), nil
+	}
+
+	var output []string
+	for _, percent := range shift.Stages {
+		if percent <= shift.CurrentPercent {
+			continue
+		}
+
+		result, err := o.runTrafficShiftStage(ctx, planID, shift, method, percent, opts)
+		shift.StageResults = append(shift.StageResults, result)
+		output = append(output, stageSummary(result))
+		if err != nil {
+			return strings.Join(output, "\n"), fmt.Errorf("traffic shift stage %d%% failed: %w", percent, err)
+		}
+	}
+
+	return strings.Join(output, "\n"), nil
+}
+
+// executeTrafficShift runs a traffic shift step.
+func (o *Orchestrator) executeTrafficShift(ctx context.Context, plan *cutover.CutoverPlan, step *cutover.CutoverStep, opts *OrchestratorOptions) error {
+	var shift *cutover.TrafficShift
+	for _, s := range plan.TrafficShifts {
+		if s.ID == step.ReferenceID {
+			shift = s
+			break
+		}
+	}
+
+	if shift == nil {
+		return fmt.Errorf("traffic shift not found: %s", step.ReferenceID)
+	}
+
+	output, err := o.ShiftTraffic(ctx, plan.ID, shift, opts)
+	step.Output = output
+	return err
+}
+
+// runTrafficShiftStage moves traffic to percent, observes it and evaluates
+// the stage gates.
+func (o *Orchestrator) runTrafficShiftStage(ctx context.Context, planID string, shift *cutover.TrafficShift, method cutover.TrafficShiftMethod, percent int, opts *OrchestratorOptions) (*cutover.TrafficShiftStageResult, error) {
+	result := &cutover.TrafficShiftStageResult{
+		Percent:   percent,
+		StartedAt: time.Now(),
+	}
+
+	fail := func(err error) (*cutover.TrafficShiftStageResult, error) {
+		completedAt := time.Now()
+		result.CompletedAt = &completedAt
+		result.Error = err.Error()
+		return result, err
+	}
+
+	if err := o.setTrafficWeight(ctx, planID, shift, method, percent, opts); err != nil {
+		return fail(err)
+	}
+	shift.CurrentPercent = percent
+
+	var before errorCounters
+	useMetrics := shift.MetricsURL != "" && shift.MaxErrorRate > 0
+	if useMetrics {
+		counters, err := o.readErrorCounters(ctx, shift)
+		if err != nil {
+			return fail(err)
+		}
+		before = counters
+	}
+
+	// Sample the gate checks while the stage is observed
+	var samples, failures int64
+	if shift.StageDuration > 0 {
+		interval := shift.SampleInterval
+		if interval <= 0 || interval > shift.StageDuration {
+			interval = shift.StageDuration
+		}
+		ticker := time.NewTicker(interval)
+		timer := time.NewTimer(shift.StageDuration)
+	observe:
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				timer.Stop()
+				return fail(ctx.Err())
+			case <-timer.C:
+				break observe
+			case <-ticker.C:
+				if len(shift.GateChecks) == 0 {
+					continue
+				}
+				samples++
+				if _, err := o.runGateChecks(ctx, shift); err != nil {
+					failures++
+				}
+			}
+		}
+		ticker.Stop()
+	}
+
+	// Every gate check must pass at the end of the stage
+	if check, err := o.runGateChecks(ctx, shift); err != nil {
+		return fail(fmt.Errorf("gate check %s failed: %w", check, err))
+	}
+
+	if useMetrics {
+		after, err := o.readErrorCounters(ctx, shift)
+		if err != nil {
+			return fail(err)
+		}
+		result.Requests = after.requests - before.requests
+		result.Errors = after.errors - before.errors
+	} else {
+		result.Requests = samples
+		result.Errors = failures
+	}
+	if result.Requests > 0 {
+		result.ErrorRate = float64(result.Errors) / float64(result.Requests) * 100
+	}
+
+	if shift.MaxErrorRate > 0 && result.ErrorRate > shift.MaxErrorRate {
+		return fail(fmt.Errorf("error rate %.2f%% exceeds %.2f%% (%d of %d)",
+			result.ErrorRate, shift.MaxErrorRate, result.Errors, result.Requests))
+	}
+
+	completedAt := time.Now()
+	result.CompletedAt = &completedAt
+	result.Passed = true
+	return result, nil
+}
+
+// runGateChecks runs the gate checks of a shift and returns the name of the
+// first failed check with its error.
+func (o *Orchestrator) runGateChecks(ctx context.Context, shift *cutover.TrafficShift) (string, error) {
+	for _, check := range shift.GateChecks {
+		result := o.healthChecker.Execute(ctx, check)
+		if !result.Passed {
+			return check.Name, fmt.Errorf("%s", result.Error)
+		}
+	}
+	return "", nil
+}
+
+// trafficShiftMethod resolves the method a shift is run with.
+func (o *Orchestrator) trafficShiftMethod(shift *cutover.TrafficShift, opts *OrchestratorOptions) (cutover.TrafficShiftMethod, error) {
+	switch shift.Method {
+	case cutover.TrafficShiftMethodDNS:
+		if _, err := o.weightedDNSProvider(opts); err != nil {
+			return "", err
+		}
+		return cutover.TrafficShiftMethodDNS, nil
+	case cutover.TrafficShiftMethodTraefik:
+		return cutover.TrafficShiftMethodTraefik, nil
+	}
+
+	if shift.HasWeightedRecords() {
+		if _, err := o.weightedDNSProvider(opts); err == nil {
+			return cutover.TrafficShiftMethodDNS, nil
+		}
+	}
+	if shift.HasTraefikService() {
+		return cutover.TrafficShiftMethodTraefik, nil
+	}
+
+	return "", fmt.Errorf("cannot shift traffic for %s: DNS provider %q does not support weighted records and no Traefik service is configured",
+		shift.FullName(), opts.DNSProvider)
+}
+
+// weightedDNSProvider returns the configured DNS provider if it supports
+// weighted records.
+func (o *Orchestrator) weightedDNSProvider(opts *OrchestratorOptions) (cutover.WeightedDNSProvider, error) {
+	if opts.DNSProvider == "" || opts.DNSProvider == "manual" {
+		return nil, fmt.Errorf("weighted DNS traffic shifts require a DNS provider")
+	}
+
+	provider, ok := o.GetDNSProvider(opts.DNSProvider)
+	if !ok {
+		return nil, fmt.Errorf("DNS provider not found: %s", opts.DNSProvider)
+	}
+
+	weighted, ok := provider.(cutover.WeightedDNSProvider)
+	if !ok || !weighted.SupportsWeightedRecords() {
+		return nil, fmt.Errorf("DNS provider %s does not support weighted records", opts.DNSProvider)
+	}
+
+	return weighted, nil
+}
+
+// setTrafficWeight sends percent of the traffic to the new infrastructure
+// and the rest to the old one.
+func (o *Orchestrator) setTrafficWeight(ctx context.Context, planID string, shift *cutover.TrafficShift, method cutover.TrafficShiftMethod, percent int, opts *OrchestratorOptions) error {
+	switch method {
+	case cutover.TrafficShiftMethodDNS:
+		provider, err := o.weightedDNSProvider(opts)
+		if err != nil {
+			return err
+		}
+
+		var deletes []*cutover.DNSChange
+		upserts := []*cutover.DNSChange{
+			weightedChange(shift, shift.NewValue, shift.NewIdentifier(), int64(percent), opts.DNSProvider),
+			weightedChange(shift, shift.OldValue, shift.OldIdentifier(), int64(100-percent), opts.DNSProvider),
+		}
+
+		// A simple record cannot coexist with weighted records of its name
+		// and type, so the first stage replaces it in the same change
+		if shift.CurrentPercent == 0 {
+			original, err := simpleRecord(ctx, provider, shift)
+			if err != nil {
+				return err
+			}
+			if original != nil {
+				shift.OriginalRecord = original
+				deletes = append(deletes, original.ToChange(shift.ID+"-original", ""))
+			}
+		}
+
+		return o.changeWeightedRecords(ctx, planID, cutover.DNSMutationWeight, provider, shift, deletes, upserts, opts)
+
+	case cutover.TrafficShiftMethodTraefik:
+		return writeWeightedRoute(shift, percent, opts.TraefikConfigDir)
+
+	default:
+		return fmt.Errorf("unknown traffic shift method: %s", method)
+	}
+}
+
+// restoreSimpleRecord removes the weighted records of a shift and restores
+// the simple record they replaced in one change. Without a simple record,
+// the old weighted record is left to take all traffic.
+func (o *Orchestrator) restoreSimpleRecord(ctx context.Context, planID string, shift *cutover.TrafficShift, opts *OrchestratorOptions) error {
+	provider, err := o.weightedDNSProvider(opts)
+	if err != nil {
+		return err
+	}
+
+	newRecord := weightedChange(shift, shift.NewValue, shift.NewIdentifier(), 0, opts.DNSProvider)
+	oldRecord := weightedChange(shift, shift.OldValue, shift.OldIdentifier(), 100, opts.DNSProvider)
+	if shift.OriginalRecord == nil {
+		return o.changeWeightedRecords(ctx, planID, cutover.DNSMutationRollback, provider, shift,
+			[]*cutover.DNSChange{deletion(newRecord)}, []*cutover.DNSChange{oldRecord}, opts)
+	}
+
+	original := shift.OriginalRecord
+	restore := original.ToChange(shift.ID+"-original", original.Value)
+	restore.Alias = original.Alias
+	restore.Provider = opts.DNSProvider
+	return o.changeWeightedRecords(ctx, planID, cutover.DNSMutationRollback, provider, shift,
+		[]*cutover.DNSChange{deletion(newRecord), deletion(oldRecord)}, []*cutover.DNSChange{restore}, opts)
+}
+
+// changeWeightedRecords journals and applies one atomic change to the
+// records of a shift.
+func (o *Orchestrator) changeWeightedRecords(ctx context.Context, planID string, action cutover.DNSMutationAction, provider cutover.WeightedDNSProvider, shift *cutover.TrafficShift, deletes, upserts []*cutover.DNSChange, opts *OrchestratorOptions) error {
+	changes := append(append([]*cutover.DNSChange{}, deletes...), upserts...)
+	err := o.mutateDNSBatch(planID, action, opts.DNSProvider, changes, opts, func() error {
+		return provider.ChangeRecords(ctx, deletes, upserts)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to change weighted records of %s: %w", shift.FullName(), err)
+	}
+	return nil
+}
+
+// simpleRecord returns the record of a shift's name and type without a set
+// identifier, or nil if there is none.
+func simpleRecord(ctx context.Context, provider cutover.DNSProvider, shift *cutover.TrafficShift) (*cutover.DNSRecord, error) {
+	lookup := &cutover.DNSChange{Domain: shift.Domain, Name: shift.RecordName, RecordType: shift.RecordType}
+	record, err := liveRecord(ctx, provider, lookup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the record of %s: %w", shift.FullName(), err)
+	}
+	return record, nil
+}
+
+// deletion returns a change that deletes the record change would set.
+func deletion(change *cutover.DNSChange) *cutover.DNSChange {
+	deleted := *change
+	deleted.OldValue = change.NewValue
+	deleted.NewValue = ""
+	return &deleted
+}
+
+// weightedChange builds the DNS change that sets the weight of one of the
+// weighted records of a shift.
+func weightedChange(shift *cutover.TrafficShift, value, setIdentifier string, weight int64, provider string) *cutover.DNSChange {
+	return &cutover.DNSChange{
+		ID:            shift.ID + "-" + setIdentifier,
+		Domain:        shift.Domain,
+		RecordType:    shift.RecordType,
+		Name:          shift.RecordName,
+		NewValue:      value,
+		TTL:           shift.TTL,
+		Provider:      provider,
+		SetIdentifier: setIdentifier,
+		RoutingWeight: &weight,
+		Status:        cutover.DNSChangeStatusPending,
+	}
+}
+
+// weightedRoute returns the Traefik weighted route of a shift.
+func weightedRoute(shift *cutover.TrafficShift, percent int) *traefik.WeightedRoute {
+	return &traefik.WeightedRoute{
+		Name:      "homeport-shift-" + routeName(shift.ID),
+		Host:      shift.FullName(),
+		Service:   shift.TraefikService,
+		LegacyURL: shift.LegacyURL,
+		Percent:   percent,
+	}
+}
+
+// writeWeightedRoute writes the Traefik weighted route of a shift to dir.
+// The file is replaced atomically so Traefik never reads a partial file.
+func writeWeightedRoute(shift *cutover.TrafficShift, percent int, dir string) error {
+	if dir == "" {
+		dir = DefaultOptions().TraefikConfigDir
+	}
+
+	route := weightedRoute(shift, percent)
+	content, err := traefik.GenerateWeightedRoute(route)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create Traefik config directory: %w", err)
+	}
+
+	path := filepath.Join(dir, route.FileName())
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write Traefik weighted route: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write Traefik weighted route: %w", err)
+	}
+
+	return nil
+}
+
+// routeName turns an ID into a name Traefik accepts.
+func routeName(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, id)
+}
+
+// armTrafficShifts writes the Traefik weighted routes of a plan before its
+// steps run, so traffic reaching Traefik after the DNS changes keeps going
+// to the old endpoint until the shift moves it.
+func (o *Orchestrator) armTrafficShifts(plan *cutover.CutoverPlan, opts *OrchestratorOptions) error {
+	for _, shift := range plan.TrafficShifts {
+		method, err := o.trafficShiftMethod(shift, opts)
+		if err != nil || method != cutover.TrafficShiftMethodTraefik {
+			continue
+		}
+		if err := writeWeightedRoute(shift, shift.CurrentPercent, opts.TraefikConfigDir); err != nil {
+			return fmt.Errorf("failed to arm traffic shift %s: %w", shift.ID, err)
+		}
+	}
+	return nil
+}
+
+// RollbackTrafficShift sends all traffic of a shift back to the old
+// infrastructure. With weighted DNS, the weighted records are removed and
+// the simple record they replaced is restored. Shifts that never moved
+// traffic are left alone.
+func (o *Orchestrator) RollbackTrafficShift(ctx context.Context, planID string, shift *cutover.TrafficShift, opts *OrchestratorOptions) error {
+	if shift.CurrentPercent == 0 && len(shift.StageResults) == 0 {
+		return nil
+	}
+
+	method, err := o.trafficShiftMethod(shift, opts)
+	if err != nil {
+		return err
+	}
+
+	if method == cutover.TrafficShiftMethodDNS {
+		err = o.restoreSimpleRecord(ctx, planID, shift, opts)
+	} else {
+		err = o.setTrafficWeight(ctx, planID, shift, method, 0, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to roll back traffic shift %s: %w", shift.FullName(), err)
+	}
+	shift.CurrentPercent = 0
+	return nil
+}
+
+// errorCounters are cumulative request and error counts of a service.
+type errorCounters struct {
+	requests int64
+	errors   int64
+}
+
+// readErrorCounters reads the request counters of a shift's service from
+// its metrics endpoint.
+func (o *Orchestrator) readErrorCounters(ctx context.Context, shift *cutover.TrafficShift) (errorCounters, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, shift.MetricsURL, nil)
+	if err != nil {
+		return errorCounters{}, fmt.Errorf("invalid metrics URL: %w", err)
+	}
+
+	resp, err := o.healthChecker.httpClient.Do(req)
+	if err != nil {
+		return errorCounters{}, fmt.Errorf("failed to read metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errorCounters{}, fmt.Errorf("failed to read metrics: status %d", resp.StatusCode)
+	}
+
+	return parseErrorCounters(resp.Body, shift.ErrorRateService())
+}
+
+// parseErrorCounters sums the Traefik request counters of service in the
+// Prometheus text format; 5xx responses count as errors.
+func parseErrorCounters(r io.Reader, service string) (errorCounters, error) {
+	var counters errorCounters
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, traefikRequestsMetric+"{") {
+			continue
+		}
+
+		labels, rest, ok := parseMetricLabels(line[len(traefikRequestsMetric)+1:])
+		if !ok || labels["service"] != service {
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return errorCounters{}, fmt.Errorf("invalid metric value %q: %w", fields[0], err)
+		}
+
+		counters.requests += int64(value)
+		if strings.HasPrefix(labels["code"], "5") {
+			counters.errors += int64(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errorCounters{}, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	return counters, nil
+}
+
+// parseMetricLabels parses the labels of a metric line after its opening
+// brace and returns them with the rest of the line.
+func parseMetricLabels(s string) (map[string]string, string, bool) {
+	labels := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " ,")
+		if strings.HasPrefix(s, "}") {
+			return labels, s[1:], true
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || len(s) < eq+2 || s[eq+1] != '"' {
+			return nil, "", false
+		}
+		name := strings.TrimSpace(s[:eq])
+
+		var value strings.Builder
+		i := eq + 2
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return nil, "", false
+		}
+
+		labels[name] = value.String()
+		s = s[i+1:]
+	}
+}
+
+// stageLabels formats traffic shift stages as percentages.
+func stageLabels(stages []int) []string {
+	labels := make([]string, len(stages))
+	for i, percent := range stages {
+		labels[i] = fmt.Sprintf("%d%%", percent)
+	}
+	return labels
+}
+
+// stageSummary describes the outcome of a stage.
+func stageSummary(result *cutover.TrafficShiftStageResult) string {
+	if !result.Passed {
+		return fmt.Sprintf("%d%%: failed - %s", result.Percent, result.Error)
+	}
+	return fmt.Sprintf("%d%%: passed (error rate %.2f%% over %d)", result.Percent, result.ErrorRate, result.Requests)
+}
+
+// trafficShiftInstructions describes how to run a traffic shift by hand.
+func trafficShiftInstructions(shift *cutover.TrafficShift) []string {
+	var instructions []string
+
+	if shift.HasWeightedRecords() && shift.Method != cutover.TrafficShiftMethodTraefik {
+		instructions = append(instructions, fmt.Sprintf("   Weighted %s records for %s (TTL %d seconds):", shift.RecordType, shift.FullName(), shift.TTL))
+		instructions = append(instructions, fmt.Sprintf("     New: %s (set identifier %s)", shift.NewValue, shift.NewIdentifier()))
+		instructions = append(instructions, fmt.Sprintf("     Old: %s (set identifier %s)", shift.OldValue, shift.OldIdentifier()))
+	} else {
+		route := weightedRoute(shift, 0)
+		instructions = append(instructions, fmt.Sprintf("   Traefik weighted route %s in the dynamic configuration directory:", route.FileName()))
+		instructions = append(instructions, fmt.Sprintf("     New: %s", shift.TraefikService))
+		instructions = append(instructions, fmt.Sprintf("     Old: %s", shift.LegacyURL))
+	}
+
+	for i, percent := range shift.Stages {
+		instructions = append(instructions, fmt.Sprintf("   Stage %d: weight %d new / %d old, then observe for %s", i+1, percent, 100-percent, shift.StageDuration))
+	}
+
+	for _, check := range shift.GateChecks {
+		instructions = append(instructions, fmt.Sprintf("   Gate: %s (%s %s)", check.Name, check.Type, check.Endpoint))
+	}
+	if shift.MaxErrorRate > 0 {
+		instructions = append(instructions, fmt.Sprintf("   Gate: error rate at most %.2f%%", shift.MaxErrorRate))
+	}
+	if shift.HasWeightedRecords() && shift.Method != cutover.TrafficShiftMethodTraefik {
+		instructions = append(instructions, "   Replace an existing simple record with both weighted records in one change.")
+		instructions = append(instructions, "   If a gate fails, delete both weighted records and restore the simple record in one change.")
+	} else {
+		instructions = append(instructions, "   If a gate fails, set the weights back to 0 new / 100 old.")
+	}
+
+	return instructions
+}
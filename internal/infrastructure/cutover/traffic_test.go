@@ -0,0 +1,372 @@
+package cutover
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/cutover"
+)
+
+// weightedProvider is a DNS provider that keeps the records of one name in
+// memory, keyed by set identifier, and counts the change batches it applies.
+// Like Route 53, it rejects a simple record next to weighted ones.
+type weightedProvider struct {
+	mu      sync.Mutex
+	records map[string]*cutover.DNSRecord
+	batches int
+}
+
+func (p *weightedProvider) Name() string { return "weighted" }
+
+func (p *weightedProvider) ListRecords(ctx context.Context, domain string) ([]*cutover.DNSRecord, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	records := make([]*cutover.DNSRecord, 0, len(p.records))
+	for _, record := range p.records {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (p *weightedProvider) GetRecord(ctx context.Context, domain, recordID string) (*cutover.DNSRecord, error) {
+	return nil, fmt.Errorf("not found")
+}
+
+func (p *weightedProvider) CreateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	return p.UpdateRecord(ctx, change)
+}
+
+func (p *weightedProvider) UpdateRecord(ctx context.Context, change *cutover.DNSChange) error {
+	return p.ChangeRecords(ctx, nil, []*cutover.DNSChange{change})
+}
+
+// DeleteRecord deletes a record; records are identified by their set
+// identifier.
+func (p *weightedProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.records, recordID)
+	return nil
+}
+
+func (p *weightedProvider) ValidateCredentials(ctx context.Context) error { return nil }
+
+func (p *weightedProvider) SupportsWeightedRecords() bool { return true }
+
+func (p *weightedProvider) ChangeRecords(ctx context.Context, deletes, upserts []*cutover.DNSChange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	records := make(map[string]*cutover.DNSRecord, len(p.records))
+	for id, record := range p.records {
+		records[id] = record
+	}
+	for _, change := range deletes {
+		delete(records, change.SetIdentifier)
+	}
+	for _, change := range upserts {
+		records[change.SetIdentifier] = &cutover.DNSRecord{
+			Domain:        change.Domain,
+			Type:          change.RecordType,
+			Name:          change.Name,
+			Value:         change.NewValue,
+			TTL:           change.TTL,
+			SetIdentifier: change.SetIdentifier,
+			RoutingWeight: change.RoutingWeight,
+		}
+	}
+	if _, simple := records[""]; simple && len(records) > 1 {
+		return fmt.Errorf("a simple record cannot coexist with weighted records")
+	}
+
+	p.records = records
+	p.batches++
+	return nil
+}
+
+// weight returns the routing weight of the record with setIdentifier and
+// value, or -1 if there is none.
+func (p *weightedProvider) weight(setIdentifier, value string) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	record, ok := p.records[setIdentifier]
+	if !ok || record.Value != value || record.RoutingWeight == nil {
+		return -1
+	}
+	return *record.RoutingWeight
+}
+
+// newWeightedProvider returns a provider holding the simple A record of
+// www.example.com.
+func newWeightedProvider() *weightedProvider {
+	return &weightedProvider{records: map[string]*cutover.DNSRecord{
+		"": {Domain: "example.com", Type: "A", Name: "www", Value: "198.51.100.20", TTL: 300},
+	}}
+}
+
+func newTestShift(t *testing.T) *cutover.TrafficShift {
+	t.Helper()
+	shift := cutover.NewTrafficShift("www", "example.com", "www")
+	shift.StageDuration = 20 * time.Millisecond
+	shift.SampleInterval = 5 * time.Millisecond
+	return shift
+}
+
+func gateCheck(url string) *cutover.HealthCheck {
+	check := cutover.NewHTTPHealthCheck("gate", "Gate", url, http.StatusOK)
+	check.Retries = 0
+	check.Timeout = time.Second
+	return check
+}
+
+func TestExecuteTrafficShiftWeightedDNS(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	shift := newTestShift(t)
+	shift.RecordType = "A"
+	shift.TTL = 60
+	shift.NewValue = "203.0.113.10"
+	shift.OldValue = "198.51.100.20"
+	shift.GateChecks = append(shift.GateChecks, gateCheck(healthy.URL))
+
+	plan := cutover.NewCutoverPlan("plan", "bundle")
+	plan.AddTrafficShift(shift)
+
+	provider := newWeightedProvider()
+	orch := NewOrchestrator()
+	orch.RegisterDNSProvider("weighted", provider)
+
+	opts := DefaultOptions()
+	opts.DNSProvider = "weighted"
+
+	result, err := orch.Execute(context.Background(), plan, opts)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !result.Success {
+		t.Fatal("expected the cutover to succeed")
+	}
+
+	if got := provider.weight("homeport", "203.0.113.10"); got != 100 {
+		t.Errorf("new record weight = %d, want 100", got)
+	}
+	if got := provider.weight("legacy", "198.51.100.20"); got != 0 {
+		t.Errorf("old record weight = %d, want 0", got)
+	}
+	if provider.batches != len(shift.Stages) {
+		t.Errorf("batches = %d, want one per stage (%d)", provider.batches, len(shift.Stages))
+	}
+	if shift.OriginalRecord == nil || shift.OriginalRecord.Value != "198.51.100.20" {
+		t.Errorf("OriginalRecord = %+v, want the replaced simple record", shift.OriginalRecord)
+	}
+
+	if shift.CurrentPercent != 100 {
+		t.Errorf("CurrentPercent = %d, want 100", shift.CurrentPercent)
+	}
+	if len(shift.StageResults) != len(shift.Stages) {
+		t.Fatalf("got %d stage results, want %d", len(shift.StageResults), len(shift.Stages))
+	}
+	for _, stage := range shift.StageResults {
+		if !stage.Passed {
+			t.Errorf("stage %d%% failed: %s", stage.Percent, stage.Error)
+		}
+	}
+}
+
+func TestRollbackTrafficShiftRestoresSimpleRecord(t *testing.T) {
+	shift := newTestShift(t)
+	shift.RecordType = "A"
+	shift.TTL = 60
+	shift.NewValue = "203.0.113.10"
+	shift.OldValue = "198.51.100.20"
+	shift.Stages = []int{25, 50}
+
+	provider := newWeightedProvider()
+	orch := NewOrchestrator()
+	orch.RegisterDNSProvider("weighted", provider)
+
+	var journal []cutover.DNSMutation
+	opts := DefaultOptions()
+	opts.DNSProvider = "weighted"
+	opts.Journal = func(mutation cutover.DNSMutation) error {
+		journal = append(journal, mutation)
+		return nil
+	}
+
+	if _, err := orch.ShiftTraffic(context.Background(), "plan", shift, opts); err != nil {
+		t.Fatalf("ShiftTraffic() error = %v", err)
+	}
+	if got := provider.weight("homeport", "203.0.113.10"); got != 50 {
+		t.Errorf("new record weight = %d, want 50", got)
+	}
+	// The first stage deletes the simple record and sets both weights
+	if len(journal) != 2*3+2*2 || journal[0].SetIdentifier != "" || journal[0].OldValue != "198.51.100.20" {
+		t.Errorf("unexpected journal %+v", journal)
+	}
+
+	journal = nil
+	if err := orch.RollbackTrafficShift(context.Background(), "plan", shift, opts); err != nil {
+		t.Fatalf("RollbackTrafficShift() error = %v", err)
+	}
+
+	if len(provider.records) != 1 {
+		t.Fatalf("got %d records after rollback, want only the simple record: %+v", len(provider.records), provider.records)
+	}
+	restored := provider.records[""]
+	if restored == nil || restored.Value != "198.51.100.20" || restored.TTL != 300 || restored.RoutingWeight != nil {
+		t.Errorf("restored record = %+v, want the original simple record", restored)
+	}
+	if provider.batches != len(shift.Stages)+1 {
+		t.Errorf("batches = %d, want %d", provider.batches, len(shift.Stages)+1)
+	}
+	if shift.CurrentPercent != 0 {
+		t.Errorf("CurrentPercent = %d, want 0", shift.CurrentPercent)
+	}
+	for _, mutation := range journal {
+		if mutation.Action != cutover.DNSMutationRollback {
+			t.Errorf("rollback journaled as %s", mutation.Action)
+		}
+	}
+	if len(journal) != 2*3 {
+		t.Errorf("got %d journal entries for the rollback, want 6", len(journal))
+	}
+}
+
+func TestExecuteTrafficShiftTraefikRollsBackOnErrorRate(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	// The error counter jumps during the second stage
+	var mu sync.Mutex
+	calls := 0
+	metrics := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		requests, errors := calls*100, 1
+		if calls >= 4 {
+			errors = 60
+		}
+		mu.Unlock()
+		fmt.Fprintf(w, "# TYPE traefik_service_requests_total counter\n")
+		fmt.Fprintf(w, "traefik_service_requests_total{code=\"200\",method=\"GET\",protocol=\"http\",service=\"app@docker\"} %d\n", requests-errors)
+		fmt.Fprintf(w, "traefik_service_requests_total{code=\"502\",method=\"GET\",protocol=\"http\",service=\"app@docker\"} %d\n", errors)
+		fmt.Fprintf(w, "traefik_service_requests_total{code=\"500\",method=\"GET\",protocol=\"http\",service=\"other@docker\"} 1000\n")
+	}))
+	defer metrics.Close()
+
+	shift := newTestShift(t)
+	shift.Method = cutover.TrafficShiftMethodTraefik
+	shift.TraefikService = "app@docker"
+	shift.LegacyURL = "https://legacy.example.net"
+	shift.MetricsURL = metrics.URL
+	shift.GateChecks = append(shift.GateChecks, gateCheck(healthy.URL))
+
+	plan := cutover.NewCutoverPlan("plan", "bundle")
+	plan.AddTrafficShift(shift)
+
+	dir := t.TempDir()
+	opts := DefaultOptions()
+	opts.TraefikConfigDir = dir
+
+	result, err := NewOrchestrator().Execute(context.Background(), plan, opts)
+	if err == nil {
+		t.Fatal("expected the error rate gate to fail")
+	}
+	if !strings.Contains(err.Error(), "traffic shift stage 25% failed") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !result.RolledBack {
+		t.Error("expected a rollback")
+	}
+	if plan.RolledBackAt == nil {
+		t.Error("expected the plan to record the rollback")
+	}
+
+	if len(shift.StageResults) != 2 {
+		t.Fatalf("got %d stage results, want 2", len(shift.StageResults))
+	}
+	if !shift.StageResults[0].Passed || shift.StageResults[1].Passed {
+		t.Errorf("expected the first stage to pass and the second to fail")
+	}
+	if shift.CurrentPercent != 0 {
+		t.Errorf("CurrentPercent = %d, want 0 after rollback", shift.CurrentPercent)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "homeport-shift-www.yml"))
+	if err != nil {
+		t.Fatalf("weighted route not written: %v", err)
+	}
+	if strings.Contains(string(content), "- name: app@docker") {
+		t.Errorf("rolled back route still sends traffic to the new service:\n%s", content)
+	}
+}
+
+func TestTrafficShiftMethodFallsBackToTraefik(t *testing.T) {
+	shift := newTestShift(t)
+	shift.RecordType = "A"
+	shift.TTL = 60
+	shift.NewValue = "203.0.113.10"
+	shift.OldValue = "198.51.100.20"
+
+	orch := NewOrchestrator()
+	opts := DefaultOptions()
+
+	if _, err := orch.trafficShiftMethod(shift, opts); err == nil {
+		t.Error("expected an error without a weighted DNS provider or Traefik service")
+	}
+
+	shift.TraefikService = "app@docker"
+	shift.LegacyURL = "https://legacy.example.net"
+	method, err := orch.trafficShiftMethod(shift, opts)
+	if err != nil {
+		t.Fatalf("trafficShiftMethod() error = %v", err)
+	}
+	if method != cutover.TrafficShiftMethodTraefik {
+		t.Errorf("method = %s, want traefik", method)
+	}
+
+	orch.RegisterDNSProvider("weighted", newWeightedProvider())
+	opts.DNSProvider = "weighted"
+	method, err = orch.trafficShiftMethod(shift, opts)
+	if err != nil {
+		t.Fatalf("trafficShiftMethod() error = %v", err)
+	}
+	if method != cutover.TrafficShiftMethodDNS {
+		t.Errorf("method = %s, want dns_weighted", method)
+	}
+}
+
+func TestParseErrorCounters(t *testing.T) {
+	metrics := `# HELP traefik_service_requests_total How many HTTP requests processed on a service.
+# TYPE traefik_service_requests_total counter
+traefik_service_requests_total{code="200",method="GET",protocol="http",service="app@docker"} 950
+traefik_service_requests_total{code="503",method="GET",protocol="http",service="app@docker"} 50 1700000000000
+traefik_service_requests_total{code="500",method="GET",protocol="http",service="legacy@file"} 7
+traefik_service_requests_total{code="404",method="GET",protocol="http",service="app@docker"} 1e+01
+traefik_service_open_connections{method="GET",protocol="http",service="app@docker"} 3
+`
+
+	counters, err := parseErrorCounters(strings.NewReader(metrics), "app@docker")
+	if err != nil {
+		t.Fatalf("parseErrorCounters() error = %v", err)
+	}
+	if counters.requests != 1010 {
+		t.Errorf("requests = %d, want 1010", counters.requests)
+	}
+	if counters.errors != 50 {
+		t.Errorf("errors = %d, want 50", counters.errors)
+	}
+}
@@ -0,0 +1,204 @@
+package exoscale
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+// Exoscale instance types
+const (
+	InstanceTypeSmall  = "standard.small"  // 2 vCPU, 2 GB
+	InstanceTypeMedium = "standard.medium" // 2 vCPU, 4 GB
+	InstanceTypeLarge  = "standard.large"  // 4 vCPU, 8 GB
+)
+
+// InstanceType returns the application instance type for an HA level.
+func InstanceType(level target.HALevel) string {
+	switch {
+	case level.RequiresCluster():
+		return InstanceTypeLarge
+	case level.Level() >= 1:
+		return InstanceTypeMedium
+	default:
+		return InstanceTypeSmall
+	}
+}
+
+// SKSNodeType returns the SKS node pool instance type for an HA level.
+func SKSNodeType(level target.HALevel) string {
+	if level.RequiresCluster() {
+		return InstanceTypeLarge
+	}
+	return InstanceTypeMedium
+}
+
+// SKSServiceLevel returns the SKS control plane service level. Only the pro
+// level runs a highly available control plane.
+func SKSServiceLevel(level target.HALevel) string {
+	if level.RequiresMultiServer() {
+		return "pro"
+	}
+	return "starter"
+}
+
+const cloudInit = `#cloud-config
+    packages:
+      - docker.io
+      - docker-compose
+    runcmd:
+      - systemctl enable --now docker`
+
+// GenerateComputeTF generates Terraform configuration for compute resources.
+// Single-server levels get one instance, which also attaches the block
+// volumes, and multi-server levels an instance pool behind the network load
+// balancer. Kubernetes workloads become SKS clusters.
+func GenerateComputeTF(compute, kubernetes, blockStorage []*mapper.MappingResult, config *generator.TargetConfig) string {
+	var buf bytes.Buffer
+	buf.WriteString("# Compute Resources\n\n")
+
+	buf.WriteString(`data "exoscale_template" "ubuntu" {
+  zone = var.zone
+  name = "Linux Ubuntu 22.04 LTS 64-bit"
+}
+
+`)
+
+	count := GetInstanceCount(config.HALevel)
+	if count > 1 {
+		buf.WriteString(fmt.Sprintf(`resource "exoscale_instance_pool" "app" {
+  zone               = var.zone
+  name               = "${var.project_name}-app"
+  size               = %d
+  template_id        = data.exoscale_template.ubuntu.id
+  instance_type      = var.instance_type
+  disk_size          = 50
+  key_pair           = var.ssh_key_name != "" ? var.ssh_key_name : null
+  security_group_ids = [exoscale_security_group.app.id]
+  network_ids        = [exoscale_private_network.main.id]
+
+  user_data = <<-EOF
+    %s
+  EOF
+
+  labels = {
+    managed_by = "homeport"
+  }
+}
+
+`, count, cloudInit))
+	} else {
+		volumes := volumeNames(blockStorage)
+		for i, name := range volumes {
+			volumes[i] = "exoscale_block_storage_volume." + name + ".id"
+		}
+		buf.WriteString(fmt.Sprintf(`resource "exoscale_compute_instance" "main" {
+  zone               = var.zone
+  name               = "${var.project_name}-main"
+  type               = var.instance_type
+  template_id        = data.exoscale_template.ubuntu.id
+  disk_size          = 50
+  ssh_key            = var.ssh_key_name != "" ? var.ssh_key_name : null
+  security_group_ids = [exoscale_security_group.app.id]
+
+  block_storage_volume_ids = [%s]
+
+  network_interface {
+    network_id = exoscale_private_network.main.id
+  }
+
+  user_data = <<-EOF
+    %s
+  EOF
+
+  labels = {
+    managed_by = "homeport"
+  }
+}
+
+`, strings.Join(volumes, ", "), cloudInit))
+	}
+
+	if len(kubernetes) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# SKS (Managed Kubernetes)\n")
+		buf.WriteString("# ============================================\n\n")
+
+		buf.WriteString(`resource "exoscale_security_group" "sks" {
+  name        = "${var.project_name}-sks-nodes"
+  description = "SKS worker nodes"
+}
+
+resource "exoscale_security_group_rule" "sks_kubelet" {
+  security_group_id      = exoscale_security_group.sks.id
+  type                   = "INGRESS"
+  protocol               = "TCP"
+  start_port             = 10250
+  end_port               = 10250
+  user_security_group_id = exoscale_security_group.sks.id
+}
+
+resource "exoscale_security_group_rule" "sks_calico" {
+  security_group_id      = exoscale_security_group.sks.id
+  type                   = "INGRESS"
+  protocol               = "UDP"
+  start_port             = 4789
+  end_port               = 4789
+  user_security_group_id = exoscale_security_group.sks.id
+}
+
+resource "exoscale_security_group_rule" "sks_nodeports" {
+  security_group_id = exoscale_security_group.sks.id
+  type              = "INGRESS"
+  protocol          = "TCP"
+  cidr              = "0.0.0.0/0"
+  start_port        = 30000
+  end_port          = 32767
+}
+
+`)
+
+		for i, r := range kubernetes {
+			name := SanitizeTFName(r.SourceResourceName)
+			if name == "" {
+				name = fmt.Sprintf("k8s_%d", i)
+			}
+
+			buf.WriteString(fmt.Sprintf("# Kubernetes: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			buf.WriteString(fmt.Sprintf(`resource "exoscale_sks_cluster" "%s" {
+  zone           = var.zone
+  name           = "${var.project_name}-%s"
+  service_level  = "%s"
+  cni            = "calico"
+  exoscale_ccm   = true
+  metrics_server = true
+  auto_upgrade   = true
+}
+
+resource "exoscale_sks_nodepool" "%s" {
+  zone               = var.zone
+  cluster_id         = exoscale_sks_cluster.%s.id
+  name               = "${var.project_name}-%s-workers"
+  instance_type      = "%s"
+  size               = %d
+  disk_size          = 50
+  security_group_ids = [exoscale_security_group.sks.id]
+}
+
+output "sks_%s_endpoint" {
+  description = "API endpoint of the %s cluster"
+  value       = exoscale_sks_cluster.%s.endpoint
+}
+
+`, name, SanitizeName(name), SKSServiceLevel(config.HALevel),
+				name, name, SanitizeName(name), SKSNodeType(config.HALevel), count+1,
+				name, r.SourceResourceName, name))
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,178 @@
+package exoscale
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+// Exoscale DBaaS service types
+const (
+	ServicePostgreSQL = "pg"
+	ServiceMySQL      = "mysql"
+	ServiceValkey     = "valkey"
+	ServiceKafka      = "kafka"
+	ServiceOpensearch = "opensearch"
+)
+
+// Exoscale DBaaS plan tiers
+const (
+	PlanHobbyist = "hobbyist" // Single node, no backups
+	PlanStartup  = "startup"  // Single node with backups
+	PlanBusiness = "business" // Primary and standby
+	PlanPremium  = "premium"  // Three nodes
+)
+
+// serviceVersions holds the version deployed for each service type. Valkey
+// is versionless in the API.
+var serviceVersions = map[string]string{
+	ServicePostgreSQL: "15",
+	ServiceMySQL:      "8",
+	ServiceKafka:      "3.7",
+	ServiceOpensearch: "2",
+}
+
+// DatabaseServiceType returns the DBaaS service type for a database mapping
+// result, or "" when Exoscale has no managed equivalent.
+func DatabaseServiceType(r *mapper.MappingResult) string {
+	source := strings.ToLower(r.SourceResourceType)
+	if r.SourceResource != nil {
+		source = strings.ToLower(r.SourceResource.GetConfigString("engine")) + " " + source
+	}
+
+	switch {
+	case strings.Contains(source, "mysql"), strings.Contains(source, "maria"):
+		return ServiceMySQL
+	case strings.Contains(source, "postgres"):
+		return ServicePostgreSQL
+	case strings.Contains(source, "kafka"), strings.Contains(source, "msk"):
+		return ServiceKafka
+	case strings.Contains(source, "opensearch"), strings.Contains(source, "elasticsearch"):
+		return ServiceOpensearch
+	case strings.Contains(source, "mongo"), strings.Contains(source, "docdb"), strings.Contains(source, "cosmos"),
+		strings.Contains(source, "dynamo"), strings.Contains(source, "cassandra"), strings.Contains(source, "keyspaces"):
+		return ""
+	default:
+		return ServicePostgreSQL
+	}
+}
+
+// DatabasePlanTier returns the DBaaS plan tier for an HA level.
+func DatabasePlanTier(level target.HALevel) string {
+	switch {
+	case level.RequiresCluster():
+		return PlanPremium
+	case level.RequiresMultiServer():
+		return PlanBusiness
+	case level.Level() >= 1:
+		return PlanStartup
+	default:
+		return PlanHobbyist
+	}
+}
+
+// DatabasePlan returns the full DBaaS plan name, such as "business-4", for a
+// service type and HA level. Kafka and OpenSearch have no hobbyist tier.
+func DatabasePlan(service string, level target.HALevel) string {
+	tier := DatabasePlanTier(level)
+	if tier == PlanHobbyist && (service == ServiceKafka || service == ServiceOpensearch) {
+		tier = PlanStartup
+	}
+	if tier == PlanHobbyist {
+		return tier + "-2"
+	}
+	return tier + "-4"
+}
+
+// GenerateDatabaseTF generates Terraform configuration for database resources.
+// Databases and caches become exoscale_dbaas services that only accept
+// connections from the application instances.
+func GenerateDatabaseTF(databases, caches []*mapper.MappingResult, config *generator.TargetConfig) string {
+	var buf bytes.Buffer
+	buf.WriteString("# Managed Databases (Exoscale DBaaS)\n\n")
+
+	clients := `["${exoscale_compute_instance.main.public_ip_address}/32"]`
+	if GetInstanceCount(config.HALevel) > 1 {
+		clients = `[for instance in exoscale_instance_pool.app.instances : "${instance.public_ip_address}/32"]`
+	}
+	buf.WriteString(fmt.Sprintf(`locals {
+  database_ip_filter = %s
+}
+
+`, clients))
+
+	writeService := func(r *mapper.MappingResult, name, service string) {
+		buf.WriteString(fmt.Sprintf(`resource "exoscale_dbaas" "%s" {
+  zone                   = var.zone
+  name                   = "${var.project_name}-%s"
+  type                   = "%s"
+  plan                   = "%s"
+  termination_protection = %t
+
+  %s {
+`, name, SanitizeName(name), service, DatabasePlan(service, config.HALevel), config.HALevel.RequiresMultiServer(), service))
+		if version, ok := serviceVersions[service]; ok {
+			buf.WriteString(fmt.Sprintf("    version   = \"%s\"\n", version))
+		}
+		buf.WriteString(fmt.Sprintf(`    ip_filter = local.database_ip_filter
+  }
+}
+
+data "exoscale_database_uri" "%s" {
+  zone = var.zone
+  name = exoscale_dbaas.%s.name
+  type = "%s"
+}
+
+output "db_%s_uri" {
+  description = "Connection URI of %s"
+  value       = data.exoscale_database_uri.%s.uri
+  sensitive   = true
+}
+
+`, name, name, service, name, r.SourceResourceName, name))
+	}
+
+	if len(databases) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Databases\n")
+		buf.WriteString("# ============================================\n\n")
+
+		for i, r := range databases {
+			name := SanitizeTFName(r.SourceResourceName)
+			if name == "" {
+				name = fmt.Sprintf("db_%d", i)
+			}
+
+			buf.WriteString(fmt.Sprintf("# Database: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			service := DatabaseServiceType(r)
+			if service == "" {
+				buf.WriteString("# Exoscale DBaaS has no managed equivalent; run it on the application instances.\n\n")
+				continue
+			}
+			writeService(r, name, service)
+		}
+	}
+
+	if len(caches) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Caches (Valkey)\n")
+		buf.WriteString("# ============================================\n\n")
+
+		for i, r := range caches {
+			name := SanitizeTFName(r.SourceResourceName)
+			if name == "" {
+				name = fmt.Sprintf("cache_%d", i)
+			}
+
+			buf.WriteString(fmt.Sprintf("# Cache: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			writeService(r, name, ServiceValkey)
+		}
+	}
+
+	return buf.String()
+}
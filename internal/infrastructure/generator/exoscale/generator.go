@@ -0,0 +1,513 @@
+// Package exoscale generates Terraform configurations for Exoscale deployments.
+// It converts mapping results from AWS/GCP/Azure to Exoscale compute instances,
+// SKS clusters, DBaaS services, SOS buckets and network load balancers.
+package exoscale
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/provider"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+// DefaultZone is the zone used when none is configured.
+const DefaultZone = "ch-gva-2"
+
+// Generator generates Terraform configurations for Exoscale.
+type Generator struct{}
+
+// New creates a new Exoscale Terraform generator.
+func New() *Generator {
+	return &Generator{}
+}
+
+// Platform returns the target platform.
+func (g *Generator) Platform() target.Platform {
+	return target.PlatformExoscale
+}
+
+// Name returns the generator name.
+func (g *Generator) Name() string {
+	return "exoscale-terraform"
+}
+
+// Description returns description.
+func (g *Generator) Description() string {
+	return "Generates Terraform for Exoscale (instances, SKS, DBaaS, SOS object storage, NLB)"
+}
+
+// SupportedHALevels returns supported levels.
+func (g *Generator) SupportedHALevels() []target.HALevel {
+	return []target.HALevel{
+		target.HALevelNone,
+		target.HALevelBasic,
+		target.HALevelMultiServer,
+		target.HALevelCluster,
+	}
+}
+
+// RequiresCredentials returns true.
+func (g *Generator) RequiresCredentials() bool {
+	return true
+}
+
+// RequiredCredentials returns required creds.
+func (g *Generator) RequiredCredentials() []string {
+	return []string{"EXOSCALE_API_KEY", "EXOSCALE_API_SECRET"}
+}
+
+// Validate validates inputs.
+func (g *Generator) Validate(results []*mapper.MappingResult, config *generator.TargetConfig) error {
+	if len(results) == 0 {
+		return fmt.Errorf("no mapping results")
+	}
+
+	supported := false
+	for _, level := range g.SupportedHALevels() {
+		if config.HALevel == level {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("HA level %s is not supported by Exoscale generator", config.HALevel)
+	}
+
+	zone := GetZone(config)
+	if provider.ProviderExoscale.GetRegion(zone) == nil {
+		return fmt.Errorf("unknown Exoscale zone: %s", zone)
+	}
+
+	return nil
+}
+
+// GetZone returns the Exoscale zone from the target configuration.
+func GetZone(config *generator.TargetConfig) string {
+	if config.TargetConfig != nil {
+		if config.TargetConfig.Exoscale != nil && config.TargetConfig.Exoscale.Zone != "" {
+			return config.TargetConfig.Exoscale.Zone
+		}
+		if config.TargetConfig.Region != "" {
+			return config.TargetConfig.Region
+		}
+	}
+	return DefaultZone
+}
+
+// Generate produces Terraform files.
+func (g *Generator) Generate(ctx context.Context, results []*mapper.MappingResult, config *generator.TargetConfig) (*generator.TargetOutput, error) {
+	if err := g.Validate(results, config); err != nil {
+		return nil, err
+	}
+
+	output := generator.NewTargetOutput(target.PlatformExoscale)
+	zone := GetZone(config)
+	categorized := g.categorizeResources(results)
+
+	// Generate main.tf
+	output.AddTerraformFile("main.tf", []byte(g.generateMain(categorized)))
+
+	// Generate variables.tf
+	output.AddTerraformFile("variables.tf", []byte(g.generateVariables(config, zone)))
+
+	// Generate networking.tf (private network, security group, NLB)
+	output.AddTerraformFile("networking.tf", []byte(GenerateNetworkingTF(config)))
+
+	// Generate compute.tf (instances or instance pool, SKS)
+	output.AddTerraformFile("compute.tf", []byte(GenerateComputeTF(categorized.Compute, categorized.Kubernetes, categorized.BlockStorage, config)))
+
+	// Generate database.tf (DBaaS)
+	if len(categorized.Databases) > 0 || len(categorized.Caches) > 0 {
+		output.AddTerraformFile("database.tf", []byte(GenerateDatabaseTF(categorized.Databases, categorized.Caches, config)))
+	}
+
+	// Generate storage.tf (SOS + block storage)
+	if len(categorized.ObjectStorage) > 0 || len(categorized.BlockStorage) > 0 {
+		output.AddTerraformFile("storage.tf", []byte(GenerateStorageTF(categorized.ObjectStorage, categorized.BlockStorage, config)))
+	}
+
+	// Generate outputs.tf
+	output.AddTerraformFile("outputs.tf", []byte(g.generateOutputs(config)))
+
+	// Generate terraform.tfvars.example
+	output.AddTerraformFile("terraform.tfvars.example", []byte(g.generateTfvarsExample(config, zone)))
+
+	if costEstimate, err := g.EstimateCost(results, config); err == nil {
+		output.EstimatedCost = costEstimate
+	}
+
+	output.MainFile = "main.tf"
+	output.GeneratedAt = time.Now()
+	output.Summary = fmt.Sprintf("Generated Exoscale Terraform in %s with %d compute, %d Kubernetes, %d databases, %d caches, %d buckets and %d volumes",
+		zone, len(categorized.Compute), len(categorized.Kubernetes), len(categorized.Databases), len(categorized.Caches),
+		len(categorized.ObjectStorage), len(categorized.BlockStorage))
+	output.AddManualStep("Create an Exoscale API key (IAM) and set exoscale_api_key/exoscale_api_secret in terraform.tfvars")
+	output.AddManualStep("terraform init && terraform plan && terraform apply")
+	if len(categorized.Databases) > 0 || len(categorized.Caches) > 0 {
+		output.AddManualStep("Migrate data into the DBaaS services using the URIs from terraform output")
+	}
+
+	return output, nil
+}
+
+// EstimateCost estimates the monthly cost using the provider catalog.
+func (g *Generator) EstimateCost(results []*mapper.MappingResult, config *generator.TargetConfig) (*generator.CostEstimate, error) {
+	estimate := generator.NewCostEstimate("EUR")
+
+	pricing := provider.GetProviderPricing(provider.ProviderExoscale)
+	if pricing == nil {
+		estimate.Compute = float64(len(results)) * 10.0
+		estimate.Calculate()
+		estimate.AddNote("Fallback pricing (catalog unavailable)")
+		return estimate, nil
+	}
+
+	categorized := g.categorizeResources(results)
+
+	requirements := provider.ExtractRequirements(results)
+	instanceType := provider.SelectInstance(provider.ProviderExoscale, requirements)
+	if instanceType == nil {
+		instanceType = g.selectInstanceType(config.HALevel, pricing)
+	}
+
+	instanceCount := GetInstanceCount(config.HALevel)
+	if instanceType != nil {
+		instanceCost := instanceType.PricePerMonth * float64(instanceCount)
+		estimate.Compute += instanceCost
+		estimate.AddDetail("instances_"+instanceType.Type, instanceCost)
+	}
+
+	if len(categorized.Kubernetes) > 0 {
+		// SKS Pro control plane
+		if config.HALevel.RequiresMultiServer() {
+			estimate.Compute += 30.0
+			estimate.AddDetail("sks_control_plane", 30.0)
+		}
+		if node := provider.FindInstance(provider.ProviderExoscale, SKSNodeType(config.HALevel)); node != nil {
+			nodeCost := node.PricePerMonth * float64(GetInstanceCount(config.HALevel)+1)
+			estimate.Compute += nodeCost
+			estimate.AddDetail("sks_nodes", nodeCost)
+		}
+	}
+
+	for range categorized.Databases {
+		dbCost := g.getDBCost(config.HALevel)
+		estimate.Database += dbCost
+		estimate.AddDetail("database", dbCost)
+	}
+	for range categorized.Caches {
+		cacheCost := g.getDBCost(config.HALevel) / 2
+		estimate.Database += cacheCost
+		estimate.AddDetail("cache", cacheCost)
+	}
+
+	// SOS is billed at 0.02 EUR/GB/month, block storage from the catalog
+	if len(categorized.ObjectStorage) > 0 {
+		sosCost := float64(len(categorized.ObjectStorage)) * 100 * 0.02
+		estimate.Storage += sosCost
+		estimate.AddDetail("object_storage", sosCost)
+	}
+	if len(categorized.BlockStorage) > 0 {
+		blockCost := pricing.Storage.EstimateStorageCost(len(categorized.BlockStorage) * 50)
+		estimate.Storage += blockCost
+		estimate.AddDetail("block_storage", blockCost)
+	}
+
+	if config.HALevel.RequiresMultiServer() {
+		nlbCost := 18.0
+		estimate.Network += nlbCost
+		estimate.AddDetail("load_balancer", nlbCost)
+	}
+
+	estimate.Calculate()
+
+	estimate.AddNote("Prices from Exoscale catalog (last updated: December 2024)")
+	estimate.AddNote(fmt.Sprintf("Block storage: %.2f EUR/GB/month", pricing.Storage.PricePerGBMonth))
+	estimate.AddNote("Swiss and EU datacenters, GDPR and Swiss DPA compliant")
+
+	return estimate, nil
+}
+
+// CategorizedResources holds resources grouped by type for generation.
+type CategorizedResources struct {
+	Compute       []*mapper.MappingResult
+	Kubernetes    []*mapper.MappingResult
+	Databases     []*mapper.MappingResult
+	Caches        []*mapper.MappingResult
+	ObjectStorage []*mapper.MappingResult
+	BlockStorage  []*mapper.MappingResult
+	LoadBalancers []*mapper.MappingResult
+	Other         []*mapper.MappingResult
+}
+
+// categorizeResources groups mapping results by resource type.
+func (g *Generator) categorizeResources(results []*mapper.MappingResult) *CategorizedResources {
+	categorized := &CategorizedResources{}
+
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+
+		resourceType := strings.ToLower(r.SourceResourceType)
+
+		switch r.SourceCategory {
+		case resource.CategoryCompute, resource.CategoryServerless:
+			categorized.Compute = append(categorized.Compute, r)
+
+		case resource.CategoryContainer:
+			if strings.Contains(resourceType, "kubernetes") ||
+				strings.Contains(resourceType, "eks") ||
+				strings.Contains(resourceType, "gke") ||
+				strings.Contains(resourceType, "aks") {
+				categorized.Kubernetes = append(categorized.Kubernetes, r)
+			} else {
+				categorized.Compute = append(categorized.Compute, r)
+			}
+
+		case resource.CategoryKubernetes:
+			categorized.Kubernetes = append(categorized.Kubernetes, r)
+
+		case resource.CategorySQLDatabase, resource.CategoryNoSQLDatabase:
+			categorized.Databases = append(categorized.Databases, r)
+
+		case resource.CategoryCache:
+			categorized.Caches = append(categorized.Caches, r)
+
+		case resource.CategoryObjectStorage:
+			categorized.ObjectStorage = append(categorized.ObjectStorage, r)
+
+		case resource.CategoryBlockStorage, resource.CategoryFileStorage:
+			categorized.BlockStorage = append(categorized.BlockStorage, r)
+
+		case resource.CategoryLoadBalancer:
+			categorized.LoadBalancers = append(categorized.LoadBalancers, r)
+
+		default:
+			categorized.Other = append(categorized.Other, r)
+		}
+	}
+
+	return categorized
+}
+
+// selectInstanceType selects the cheapest catalog instance meeting the
+// minimum specs of an HA level.
+func (g *Generator) selectInstanceType(level target.HALevel, pricing *provider.ProviderPricing) *provider.InstancePricing {
+	if pricing == nil || len(pricing.Instances) == 0 {
+		return nil
+	}
+
+	minVCPUs, minMemoryGB := 1, 2.0
+	switch level {
+	case target.HALevelMultiServer:
+		minVCPUs, minMemoryGB = 2, 4
+	case target.HALevelCluster:
+		minVCPUs, minMemoryGB = 4, 8
+	}
+
+	var bestMatch *provider.InstancePricing
+	for i := range pricing.Instances {
+		inst := &pricing.Instances[i]
+		if inst.VCPUs >= minVCPUs && inst.MemoryGB >= minMemoryGB {
+			if bestMatch == nil || inst.PricePerMonth < bestMatch.PricePerMonth {
+				bestMatch = inst
+			}
+		}
+	}
+	if bestMatch == nil {
+		bestMatch = &pricing.Instances[len(pricing.Instances)-1]
+	}
+	return bestMatch
+}
+
+// getDBCost returns the DBaaS cost estimate for the plan of an HA level.
+func (g *Generator) getDBCost(level target.HALevel) float64 {
+	switch DatabasePlanTier(level) {
+	case PlanPremium:
+		return 240.0
+	case PlanBusiness:
+		return 120.0
+	case PlanStartup:
+		return 45.0
+	default:
+		return 20.0
+	}
+}
+
+// GetInstanceCount returns the number of application instances for an HA level.
+func GetInstanceCount(level target.HALevel) int {
+	switch level {
+	case target.HALevelCluster:
+		return 3
+	case target.HALevelMultiServer:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func (g *Generator) generateMain(categorized *CategorizedResources) string {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf(`# Exoscale Terraform Configuration
+# Generated by Homeport - %s
+
+terraform {
+  required_version = ">= 1.0"
+  required_providers {
+    exoscale = {
+      source  = "exoscale/exoscale"
+      version = "~> 0.64"
+    }
+`, time.Now().Format(time.RFC3339)))
+	if len(categorized.ObjectStorage) > 0 {
+		buf.WriteString(`    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+`)
+	}
+	buf.WriteString(`  }
+}
+
+provider "exoscale" {
+  key    = var.exoscale_api_key
+  secret = var.exoscale_api_secret
+}
+`)
+	if len(categorized.ObjectStorage) > 0 {
+		buf.WriteString(`
+# SOS is S3-compatible and managed through the AWS provider
+provider "aws" {
+  alias      = "sos"
+  region     = var.zone
+  access_key = var.exoscale_api_key
+  secret_key = var.exoscale_api_secret
+
+  endpoints {
+    s3 = "https://sos-${var.zone}.exo.io"
+  }
+
+  skip_credentials_validation = true
+  skip_region_validation      = true
+  skip_requesting_account_id  = true
+  skip_metadata_api_check     = true
+  s3_use_path_style           = true
+}
+`)
+	}
+	return buf.String()
+}
+
+func (g *Generator) generateVariables(config *generator.TargetConfig, zone string) string {
+	return fmt.Sprintf(`variable "project_name" {
+  description = "Project name"
+  type        = string
+  default     = "%s"
+}
+
+variable "exoscale_api_key" {
+  description = "Exoscale API key"
+  type        = string
+  sensitive   = true
+}
+
+variable "exoscale_api_secret" {
+  description = "Exoscale API secret"
+  type        = string
+  sensitive   = true
+}
+
+variable "zone" {
+  description = "Exoscale zone"
+  type        = string
+  default     = "%s"
+}
+
+variable "instance_type" {
+  description = "Compute instance type"
+  type        = string
+  default     = "%s"
+}
+
+variable "ssh_key_name" {
+  description = "Name of an SSH key registered in Exoscale"
+  type        = string
+  default     = ""
+}
+`, config.ProjectName, zone, InstanceType(config.HALevel))
+}
+
+func (g *Generator) generateOutputs(config *generator.TargetConfig) string {
+	if GetInstanceCount(config.HALevel) > 1 {
+		return `# Outputs
+
+output "instance_ips" {
+  description = "Public IPs of the instance pool members"
+  value       = exoscale_instance_pool.app.instances[*].public_ip_address
+}
+
+output "load_balancer_ip" {
+  description = "Network load balancer IP"
+  value       = exoscale_nlb.main.ip_address
+}
+`
+	}
+	return `# Outputs
+
+output "main_instance_ip" {
+  description = "Main instance IP"
+  value       = exoscale_compute_instance.main.public_ip_address
+}
+`
+}
+
+func (g *Generator) generateTfvarsExample(config *generator.TargetConfig, zone string) string {
+	return fmt.Sprintf(`# Exoscale Terraform Variables
+# Copy to terraform.tfvars and fill in values
+
+project_name = "%s"
+zone         = "%s"
+
+# Exoscale API credentials (from the IAM section of the portal)
+exoscale_api_key    = ""
+exoscale_api_secret = ""
+
+ssh_key_name = ""
+`, config.ProjectName, zone)
+}
+
+// SanitizeName converts a name to a valid Exoscale resource name.
+func SanitizeName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, " ", "-")
+	name = strings.ReplaceAll(name, "_", "-")
+	var result strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			result.WriteRune(r)
+		}
+	}
+	return strings.Trim(result.String(), "-")
+}
+
+// SanitizeTFName converts a name to a valid Terraform resource name.
+func SanitizeTFName(name string) string {
+	clean := strings.ReplaceAll(SanitizeName(name), "-", "_")
+	// Ensure starts with letter
+	if len(clean) > 0 && clean[0] >= '0' && clean[0] <= '9' {
+		clean = "r_" + clean
+	}
+	return clean
+}
+
+func init() {
+	generator.RegisterGenerator(New())
+}
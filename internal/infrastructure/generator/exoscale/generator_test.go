@@ -0,0 +1,78 @@
+package exoscale
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+func testResults() []*mapper.MappingResult {
+	return []*mapper.MappingResult{
+		{SourceResourceName: "web", SourceResourceType: "aws_instance", SourceCategory: resource.CategoryCompute},
+		{SourceResourceName: "platform", SourceResourceType: "aws_eks_cluster", SourceCategory: resource.CategoryKubernetes},
+		{SourceResourceName: "orders-db", SourceResourceType: "aws_db_instance", SourceCategory: resource.CategorySQLDatabase},
+		{SourceResourceName: "sessions", SourceResourceType: "aws_elasticache_cluster", SourceCategory: resource.CategoryCache},
+		{SourceResourceName: "assets", SourceResourceType: "aws_s3_bucket", SourceCategory: resource.CategoryObjectStorage},
+		{SourceResourceName: "data", SourceResourceType: "aws_ebs_volume", SourceCategory: resource.CategoryBlockStorage},
+	}
+}
+
+func TestGenerateMultiServer(t *testing.T) {
+	config := &generator.TargetConfig{ProjectName: "shop", HALevel: target.HALevelMultiServer}
+	output, err := New().Generate(context.Background(), testResults(), config)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"compute.tf":    {`resource "exoscale_instance_pool" "app"`, `size               = 2`, `resource "exoscale_sks_cluster" "platform"`, `service_level  = "pro"`},
+		"networking.tf": {`resource "exoscale_nlb" "main"`, `instance_pool_id = exoscale_instance_pool.app.id`},
+		"database.tf":   {`type                   = "pg"`, `plan                   = "business-4"`, `type                   = "valkey"`},
+		"storage.tf":    {`resource "aws_s3_bucket" "assets"`, `resource "exoscale_block_storage_volume" "data"`},
+		"main.tf":       {`source  = "exoscale/exoscale"`, `s3 = "https://sos-${var.zone}.exo.io"`},
+	}
+	for file, snippets := range want {
+		content := string(output.TerraformFiles[file])
+		for _, snippet := range snippets {
+			if !strings.Contains(content, snippet) {
+				t.Errorf("%s missing %q", file, snippet)
+			}
+		}
+	}
+	if output.EstimatedCost == nil || output.EstimatedCost.Total <= 0 {
+		t.Errorf("EstimatedCost = %+v, want a positive total", output.EstimatedCost)
+	}
+}
+
+func TestGenerateSingleServerAttachesVolumes(t *testing.T) {
+	config := &generator.TargetConfig{ProjectName: "shop", HALevel: target.HALevelNone}
+	output, err := New().Generate(context.Background(), testResults(), config)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	compute := string(output.TerraformFiles["compute.tf"])
+	if !strings.Contains(compute, "block_storage_volume_ids = [exoscale_block_storage_volume.data.id]") {
+		t.Errorf("compute.tf does not attach the data volume:\n%s", compute)
+	}
+	if strings.Contains(string(output.TerraformFiles["networking.tf"]), "exoscale_nlb") {
+		t.Error("networking.tf has a load balancer for a single server")
+	}
+	if !strings.Contains(string(output.TerraformFiles["database.tf"]), `plan                   = "hobbyist-2"`) {
+		t.Error("database.tf does not use the hobbyist plan")
+	}
+}
+
+func TestValidateRejectsUnknownZone(t *testing.T) {
+	config := &generator.TargetConfig{
+		HALevel:      target.HALevelNone,
+		TargetConfig: &target.TargetConfig{Exoscale: &target.ExoscaleConfig{Zone: "us-east-1"}},
+	}
+	if err := New().Validate(testResults(), config); err == nil {
+		t.Fatal("Validate() error = nil, want unknown zone")
+	}
+}
@@ -0,0 +1,87 @@
+package exoscale
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+)
+
+// GenerateNetworkingTF generates the private network, the security group of
+// the application instances and, for multi-server levels, a network load
+// balancer in front of the instance pool.
+func GenerateNetworkingTF(config *generator.TargetConfig) string {
+	var buf bytes.Buffer
+	buf.WriteString("# Networking Resources\n\n")
+
+	buf.WriteString(`resource "exoscale_private_network" "main" {
+  zone     = var.zone
+  name     = "${var.project_name}-network"
+  netmask  = "255.255.255.0"
+  start_ip = "10.0.0.20"
+  end_ip   = "10.0.0.253"
+}
+
+resource "exoscale_security_group" "app" {
+  name        = "${var.project_name}-app"
+  description = "Application instances"
+}
+
+`)
+
+	for _, rule := range []struct {
+		name string
+		port int
+	}{{"ssh", 22}, {"http", 80}, {"https", 443}} {
+		buf.WriteString(fmt.Sprintf(`resource "exoscale_security_group_rule" "%s" {
+  security_group_id = exoscale_security_group.app.id
+  type              = "INGRESS"
+  protocol          = "TCP"
+  cidr              = "0.0.0.0/0"
+  start_port        = %d
+  end_port          = %d
+}
+
+`, rule.name, rule.port, rule.port))
+	}
+
+	if GetInstanceCount(config.HALevel) > 1 {
+		buf.WriteString(`# ============================================
+# Network Load Balancer
+# ============================================
+
+resource "exoscale_nlb" "main" {
+  zone = var.zone
+  name = "${var.project_name}-nlb"
+}
+
+`)
+		for _, svc := range []struct {
+			name string
+			port int
+		}{{"http", 80}, {"https", 443}} {
+			buf.WriteString(fmt.Sprintf(`resource "exoscale_nlb_service" "%s" {
+  zone             = var.zone
+  name             = "%s"
+  nlb_id           = exoscale_nlb.main.id
+  instance_pool_id = exoscale_instance_pool.app.id
+  protocol         = "tcp"
+  port             = %d
+  target_port      = %d
+  strategy         = "round-robin"
+
+  healthcheck {
+    mode     = "tcp"
+    port     = %d
+    interval = 10
+    timeout  = 5
+    retries  = 2
+  }
+}
+
+`, svc.name, svc.name, svc.port, svc.port, svc.port))
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,122 @@
+package exoscale
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+)
+
+// VolumeSize returns the size in GB of the block volume replacing a source
+// volume, 50 GB when the source does not say.
+func VolumeSize(r *mapper.MappingResult) int {
+	if r.SourceResource != nil {
+		for _, key := range []string{"size_gb", "size"} {
+			if size := r.SourceResource.GetConfigInt(key); size > 0 {
+				return size
+			}
+		}
+	}
+	return 50
+}
+
+// volumeNames returns the Terraform names of the block volumes.
+func volumeNames(blockStorage []*mapper.MappingResult) []string {
+	names := make([]string, 0, len(blockStorage))
+	for i, r := range blockStorage {
+		name := SanitizeTFName(r.SourceResourceName)
+		if name == "" {
+			name = fmt.Sprintf("volume_%d", i)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// GenerateStorageTF generates Terraform configuration for storage resources.
+// Buckets become SOS buckets, managed through the S3 API, and volumes become
+// block storage volumes. The main instance attaches the volumes; instance
+// pools cannot, so multi-server levels leave them detached.
+func GenerateStorageTF(objectStorage, blockStorage []*mapper.MappingResult, config *generator.TargetConfig) string {
+	var buf bytes.Buffer
+	buf.WriteString("# Storage Resources\n\n")
+
+	if len(objectStorage) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Object Storage (SOS)\n")
+		buf.WriteString("# ============================================\n\n")
+
+		for i, r := range objectStorage {
+			name := SanitizeTFName(r.SourceResourceName)
+			if name == "" {
+				name = fmt.Sprintf("bucket_%d", i)
+			}
+			displayName := SanitizeName(r.SourceResourceName)
+			if displayName == "" {
+				displayName = fmt.Sprintf("bucket-%d", i)
+			}
+
+			buf.WriteString(fmt.Sprintf("# Bucket: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			buf.WriteString(fmt.Sprintf(`resource "aws_s3_bucket" "%s" {
+  provider = aws.sos
+  bucket   = "${var.project_name}-%s"
+}
+
+`, name, displayName))
+
+			if config.HALevel.Level() >= 1 {
+				buf.WriteString(fmt.Sprintf(`resource "aws_s3_bucket_versioning" "%s" {
+  provider = aws.sos
+  bucket   = aws_s3_bucket.%s.id
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+`, name, name))
+			}
+		}
+
+		buf.WriteString(`output "sos_endpoint" {
+  description = "S3 endpoint for SOS"
+  value       = "https://sos-${var.zone}.exo.io"
+}
+
+`)
+	}
+
+	if len(blockStorage) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Block Storage Volumes\n")
+		buf.WriteString("# ============================================\n\n")
+
+		names := volumeNames(blockStorage)
+		for i, r := range blockStorage {
+			displayName := SanitizeName(r.SourceResourceName)
+			if displayName == "" {
+				displayName = fmt.Sprintf("volume-%d", i)
+			}
+
+			buf.WriteString(fmt.Sprintf("# Block Volume: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			buf.WriteString(fmt.Sprintf(`resource "exoscale_block_storage_volume" "%s" {
+  zone = var.zone
+  name = "${var.project_name}-%s"
+  size = %d
+
+  labels = {
+    managed_by = "homeport"
+  }
+}
+
+`, names[i], displayName, VolumeSize(r)))
+		}
+
+		if GetInstanceCount(config.HALevel) > 1 {
+			buf.WriteString("# Instance pools cannot attach block volumes: attach them to a dedicated instance or move the data to SOS.\n")
+		}
+	}
+
+	return buf.String()
+}
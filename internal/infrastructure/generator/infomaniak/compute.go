@@ -0,0 +1,85 @@
+package infomaniak
+
+import (
+	"fmt"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+// Infomaniak instance flavors
+const (
+	FlavorSmall  = "a1-ram2-disk20-perf1"  // 1 vCPU, 2 GB
+	FlavorMedium = "a2-ram4-disk50-perf1"  // 2 vCPU, 4 GB
+	FlavorLarge  = "a4-ram8-disk80-perf1"  // 4 vCPU, 8 GB
+	FlavorXLarge = "a4-ram16-disk80-perf1" // 4 vCPU, 16 GB
+)
+
+// Flavor returns the application instance flavor for an HA level.
+func Flavor(level target.HALevel) string {
+	switch {
+	case level.RequiresCluster():
+		return FlavorLarge
+	case level.Level() >= 1:
+		return FlavorMedium
+	default:
+		return FlavorSmall
+	}
+}
+
+// GenerateComputeTF generates the application instances, each with a
+// floating IP, that run the Docker Compose stack.
+func GenerateComputeTF(config *generator.TargetConfig) string {
+	return fmt.Sprintf(`# Compute Resources
+
+data "openstack_images_image_v2" "ubuntu" {
+  name        = "Ubuntu 22.04 LTS Jammy Jellyfish"
+  most_recent = true
+}
+
+resource "openstack_compute_keypair_v2" "main" {
+  count      = var.ssh_public_key != "" ? 1 : 0
+  name       = "${var.project_name}-key"
+  public_key = var.ssh_public_key
+}
+
+resource "openstack_compute_instance_v2" "app" {
+  count           = %d
+  name            = "${var.project_name}-app-${count.index + 1}"
+  image_id        = data.openstack_images_image_v2.ubuntu.id
+  flavor_name     = var.instance_flavor
+  key_pair        = var.ssh_public_key != "" ? openstack_compute_keypair_v2.main[0].name : null
+  security_groups = [openstack_networking_secgroup_v2.app.name]
+
+  network {
+    uuid = openstack_networking_network_v2.private.id
+  }
+
+  user_data = <<-EOF
+    #cloud-config
+    packages:
+      - docker.io
+      - docker-compose
+    runcmd:
+      - systemctl enable --now docker
+  EOF
+
+  metadata = {
+    managed_by = "homeport"
+  }
+
+  depends_on = [openstack_networking_router_interface_v2.private]
+}
+
+resource "openstack_networking_floatingip_v2" "app" {
+  count = %d
+  pool  = "%s"
+}
+
+resource "openstack_networking_floatingip_associate_v2" "app" {
+  count       = %d
+  floating_ip = openstack_networking_floatingip_v2.app[count.index].address
+  port_id     = openstack_compute_instance_v2.app[count.index].network[0].port
+}
+`, GetInstanceCount(config.HALevel), GetInstanceCount(config.HALevel), ExternalNetwork, GetInstanceCount(config.HALevel))
+}
@@ -0,0 +1,214 @@
+package infomaniak
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+// DatabaseVolumeSize is the size in GB of the data volume of the database server.
+const DatabaseVolumeSize = 100
+
+// DatabaseFlavor returns the flavor of the database server for an HA level.
+func DatabaseFlavor(level target.HALevel) string {
+	switch {
+	case level.RequiresCluster():
+		return FlavorXLarge
+	case level.RequiresMultiServer():
+		return FlavorLarge
+	default:
+		return FlavorMedium
+	}
+}
+
+// DatabaseContainer describes the container running one database or cache.
+type DatabaseContainer struct {
+	Name  string
+	Image string
+	Port  int
+	Env   []string
+}
+
+// ContainerFor returns the container replacing a database mapping result.
+func ContainerFor(r *mapper.MappingResult, name string) DatabaseContainer {
+	source := strings.ToLower(r.SourceResourceType)
+	if r.SourceResource != nil {
+		source = strings.ToLower(r.SourceResource.GetConfigString("engine")) + " " + source
+	}
+
+	switch {
+	case r.SourceCategory == resource.CategoryCache || strings.Contains(source, "redis") || strings.Contains(source, "elasticache") || strings.Contains(source, "memorystore"):
+		return DatabaseContainer{Name: name, Image: "redis:7", Port: 6379}
+	case strings.Contains(source, "mysql"), strings.Contains(source, "maria"):
+		return DatabaseContainer{Name: name, Image: "mysql:8.0", Port: 3306, Env: []string{"MYSQL_ROOT_PASSWORD=${var.db_password}", "MYSQL_DATABASE=" + name}}
+	case strings.Contains(source, "mongo"), strings.Contains(source, "docdb"), strings.Contains(source, "cosmos"):
+		return DatabaseContainer{Name: name, Image: "mongo:7", Port: 27017, Env: []string{"MONGO_INITDB_ROOT_USERNAME=homeport", "MONGO_INITDB_ROOT_PASSWORD=${var.db_password}"}}
+	default:
+		return DatabaseContainer{Name: name, Image: "postgres:16", Port: 5432, Env: []string{"POSTGRES_USER=homeport", "POSTGRES_PASSWORD=${var.db_password}", "POSTGRES_DB=" + name}}
+	}
+}
+
+// GenerateDatabaseTF generates a dedicated database server on the private
+// network. Infomaniak offers no managed databases through Terraform, so each
+// database and cache runs as a container with its data on a Cinder volume.
+func GenerateDatabaseTF(databases, caches []*mapper.MappingResult, config *generator.TargetConfig) string {
+	var containers []DatabaseContainer
+	used := map[int]bool{}
+	for i, r := range append(append([]*mapper.MappingResult{}, databases...), caches...) {
+		name := SanitizeTFName(r.SourceResourceName)
+		if name == "" {
+			name = fmt.Sprintf("db_%d", i)
+		}
+		c := ContainerFor(r, name)
+		// Containers of the same engine share the server, so shift their ports.
+		for used[c.Port] {
+			c.Port++
+		}
+		used[c.Port] = true
+		containers = append(containers, c)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Database Server (self-managed)\n\n")
+
+	buf.WriteString(fmt.Sprintf(`variable "db_password" {
+  description = "Password of the database administrator accounts"
+  type        = string
+  sensitive   = true
+}
+
+variable "db_flavor" {
+  description = "Database server flavor"
+  type        = string
+  default     = "%s"
+}
+
+resource "openstack_networking_secgroup_v2" "db" {
+  name        = "${var.project_name}-db"
+  description = "Database server, reachable from the private network only"
+}
+
+`, DatabaseFlavor(config.HALevel)))
+
+	for _, c := range containers {
+		buf.WriteString(fmt.Sprintf(`resource "openstack_networking_secgroup_rule_v2" "db_%s" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = %d
+  port_range_max    = %d
+  remote_ip_prefix  = openstack_networking_subnet_v2.private.cidr
+  security_group_id = openstack_networking_secgroup_v2.db.id
+}
+
+`, c.Name, c.Port, c.Port))
+	}
+
+	buf.WriteString(fmt.Sprintf(`resource "openstack_blockstorage_volume_v3" "db" {
+  name = "${var.project_name}-db-data"
+  size = %d
+}
+
+resource "openstack_compute_instance_v2" "db" {
+  name            = "${var.project_name}-db"
+  image_id        = data.openstack_images_image_v2.ubuntu.id
+  flavor_name     = var.db_flavor
+  key_pair        = var.ssh_public_key != "" ? openstack_compute_keypair_v2.main[0].name : null
+  security_groups = [openstack_networking_secgroup_v2.db.name]
+
+  network {
+    uuid = openstack_networking_network_v2.private.id
+  }
+
+  block_device {
+    uuid                  = data.openstack_images_image_v2.ubuntu.id
+    source_type           = "image"
+    destination_type      = "local"
+    boot_index            = 0
+    delete_on_termination = true
+  }
+
+  block_device {
+    uuid             = openstack_blockstorage_volume_v3.db.id
+    source_type      = "volume"
+    destination_type = "volume"
+    boot_index       = -1
+  }
+
+  user_data = <<-EOF
+    #cloud-config
+    packages:
+      - docker.io
+    runcmd:
+      - DEV=$(ls /dev/disk/by-id/*${substr(openstack_blockstorage_volume_v3.db.id, 0, 20)}* | head -n1)
+      - blkid "$DEV" || mkfs.ext4 "$DEV"
+      - mkdir -p /var/lib/homeport && mount "$DEV" /var/lib/homeport
+      - echo "$DEV /var/lib/homeport ext4 defaults,nofail 0 2" >> /etc/fstab
+      - systemctl enable --now docker
+`, DatabaseVolumeSize))
+
+	for _, c := range containers {
+		var env strings.Builder
+		for _, e := range c.Env {
+			env.WriteString(fmt.Sprintf(" -e %s", e))
+		}
+		buf.WriteString(fmt.Sprintf("      - docker run -d --name %s --restart unless-stopped -p %d:%d -v /var/lib/homeport/%s:%s%s %s\n",
+			c.Name, c.Port, containerPort(c.Image), c.Name, dataDir(c.Image), env.String(), c.Image))
+	}
+
+	buf.WriteString(`  EOF
+
+  metadata = {
+    managed_by = "homeport"
+    role       = "database"
+  }
+
+  depends_on = [openstack_networking_router_interface_v2.private]
+}
+
+`)
+
+	for _, c := range containers {
+		buf.WriteString(fmt.Sprintf(`output "db_%s_endpoint" {
+  description = "Private endpoint of %s"
+  value       = "${openstack_compute_instance_v2.db.access_ip_v4}:%d"
+}
+
+`, c.Name, c.Name, c.Port))
+	}
+
+	return buf.String()
+}
+
+// containerPort returns the port an image listens on inside the container.
+func containerPort(image string) int {
+	switch {
+	case strings.HasPrefix(image, "redis"):
+		return 6379
+	case strings.HasPrefix(image, "mysql"):
+		return 3306
+	case strings.HasPrefix(image, "mongo"):
+		return 27017
+	default:
+		return 5432
+	}
+}
+
+// dataDir returns the data directory of an image.
+func dataDir(image string) string {
+	switch {
+	case strings.HasPrefix(image, "redis"):
+		return "/data"
+	case strings.HasPrefix(image, "mysql"):
+		return "/var/lib/mysql"
+	case strings.HasPrefix(image, "mongo"):
+		return "/data/db"
+	default:
+		return "/var/lib/postgresql/data"
+	}
+}
@@ -0,0 +1,428 @@
+// Package infomaniak generates Terraform configurations for the Infomaniak
+// Public Cloud. The platform is OpenStack-based, so the output uses the
+// OpenStack provider: Nova instances, Neutron networking, Octavia load
+// balancers, Cinder volumes and Swift containers with S3 credentials.
+package infomaniak
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/provider"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+// DefaultRegion is the region used when none is configured.
+const DefaultRegion = "dc3-a"
+
+// cloudEndpoints maps each region to the public cloud cluster serving it.
+var cloudEndpoints = map[string]string{
+	"dc3-a": "pub1",
+	"dc4-a": "pub2",
+}
+
+// Generator generates Terraform configurations for Infomaniak Public Cloud.
+type Generator struct{}
+
+// New creates a new Infomaniak Terraform generator.
+func New() *Generator {
+	return &Generator{}
+}
+
+// Platform returns the target platform.
+func (g *Generator) Platform() target.Platform {
+	return target.PlatformInfomaniak
+}
+
+// Name returns the generator name.
+func (g *Generator) Name() string {
+	return "infomaniak-terraform"
+}
+
+// Description returns description.
+func (g *Generator) Description() string {
+	return "Generates Terraform for Infomaniak Public Cloud (OpenStack instances, load balancers, volumes, object storage)"
+}
+
+// SupportedHALevels returns supported levels.
+func (g *Generator) SupportedHALevels() []target.HALevel {
+	return []target.HALevel{
+		target.HALevelNone,
+		target.HALevelBasic,
+		target.HALevelMultiServer,
+		target.HALevelCluster,
+	}
+}
+
+// RequiresCredentials returns true.
+func (g *Generator) RequiresCredentials() bool {
+	return true
+}
+
+// RequiredCredentials returns required creds.
+func (g *Generator) RequiredCredentials() []string {
+	return []string{"OS_USERNAME", "OS_PASSWORD", "OS_PROJECT_NAME"}
+}
+
+// Validate validates inputs.
+func (g *Generator) Validate(results []*mapper.MappingResult, config *generator.TargetConfig) error {
+	if len(results) == 0 {
+		return fmt.Errorf("no mapping results")
+	}
+
+	supported := false
+	for _, level := range g.SupportedHALevels() {
+		if config.HALevel == level {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("HA level %s is not supported by Infomaniak generator", config.HALevel)
+	}
+
+	region := GetRegion(config)
+	if _, ok := cloudEndpoints[region]; !ok {
+		return fmt.Errorf("unknown Infomaniak region: %s", region)
+	}
+
+	return nil
+}
+
+// GetRegion returns the Infomaniak region from the target configuration.
+func GetRegion(config *generator.TargetConfig) string {
+	if config.TargetConfig != nil {
+		if config.TargetConfig.Infomaniak != nil && config.TargetConfig.Infomaniak.Region != "" {
+			return config.TargetConfig.Infomaniak.Region
+		}
+		if config.TargetConfig.Region != "" {
+			return config.TargetConfig.Region
+		}
+	}
+	return DefaultRegion
+}
+
+// Generate produces Terraform files.
+func (g *Generator) Generate(ctx context.Context, results []*mapper.MappingResult, config *generator.TargetConfig) (*generator.TargetOutput, error) {
+	if err := g.Validate(results, config); err != nil {
+		return nil, err
+	}
+
+	output := generator.NewTargetOutput(target.PlatformInfomaniak)
+	region := GetRegion(config)
+	categorized := g.categorizeResources(results)
+
+	// Generate main.tf
+	output.AddTerraformFile("main.tf", []byte(g.generateMain(region)))
+
+	// Generate variables.tf
+	output.AddTerraformFile("variables.tf", []byte(g.generateVariables(config, region)))
+
+	// Generate networking.tf (network, router, security group, load balancer)
+	output.AddTerraformFile("networking.tf", []byte(GenerateNetworkingTF(config)))
+
+	// Generate compute.tf
+	output.AddTerraformFile("compute.tf", []byte(GenerateComputeTF(config)))
+
+	// Generate database.tf (self-managed database server)
+	if len(categorized.Databases) > 0 || len(categorized.Caches) > 0 {
+		output.AddTerraformFile("database.tf", []byte(GenerateDatabaseTF(categorized.Databases, categorized.Caches, config)))
+	}
+
+	// Generate storage.tf (Swift containers + Cinder volumes)
+	if len(categorized.ObjectStorage) > 0 || len(categorized.BlockStorage) > 0 {
+		output.AddTerraformFile("storage.tf", []byte(GenerateStorageTF(categorized.ObjectStorage, categorized.BlockStorage, config, region)))
+	}
+
+	// Generate outputs.tf
+	output.AddTerraformFile("outputs.tf", []byte(g.generateOutputs(config)))
+
+	// Generate terraform.tfvars.example
+	output.AddTerraformFile("terraform.tfvars.example", []byte(g.generateTfvarsExample(config, region)))
+
+	if costEstimate, err := g.EstimateCost(results, config); err == nil {
+		output.EstimatedCost = costEstimate
+	}
+
+	output.MainFile = "main.tf"
+	output.GeneratedAt = time.Now()
+	output.Summary = fmt.Sprintf("Generated Infomaniak Terraform in %s with %d compute, %d databases, %d caches, %d buckets and %d volumes",
+		region, len(categorized.Compute)+len(categorized.Kubernetes), len(categorized.Databases), len(categorized.Caches),
+		len(categorized.ObjectStorage), len(categorized.BlockStorage))
+	output.AddManualStep("Download the OpenStack RC file from the Infomaniak Manager and fill in terraform.tfvars")
+	output.AddManualStep("terraform init && terraform plan && terraform apply")
+	if len(categorized.Databases) > 0 || len(categorized.Caches) > 0 {
+		output.AddWarning("Infomaniak Public Cloud has no managed databases in Terraform: databases and caches run as containers on a dedicated instance")
+	}
+	if len(categorized.Kubernetes) > 0 {
+		output.AddManualStep("Create the Kubernetes cluster in the Infomaniak Manager (Managed Kubernetes Service) and deploy the workloads there")
+	}
+
+	return output, nil
+}
+
+// EstimateCost estimates the monthly cost using the provider catalog.
+func (g *Generator) EstimateCost(results []*mapper.MappingResult, config *generator.TargetConfig) (*generator.CostEstimate, error) {
+	estimate := generator.NewCostEstimate("EUR")
+
+	pricing := provider.GetProviderPricing(provider.ProviderInfomaniak)
+	if pricing == nil {
+		estimate.Compute = float64(len(results)) * 8.0
+		estimate.Calculate()
+		estimate.AddNote("Fallback pricing (catalog unavailable)")
+		return estimate, nil
+	}
+
+	categorized := g.categorizeResources(results)
+
+	instanceType := provider.FindInstance(provider.ProviderInfomaniak, Flavor(config.HALevel))
+	instanceCount := GetInstanceCount(config.HALevel)
+	if instanceType != nil {
+		instanceCost := instanceType.PricePerMonth * float64(instanceCount)
+		estimate.Compute += instanceCost
+		estimate.AddDetail("instances_"+instanceType.Type, instanceCost)
+	}
+
+	if len(categorized.Databases) > 0 || len(categorized.Caches) > 0 {
+		if dbType := provider.FindInstance(provider.ProviderInfomaniak, DatabaseFlavor(config.HALevel)); dbType != nil {
+			estimate.Database += dbType.PricePerMonth
+			estimate.AddDetail("database_server", dbType.PricePerMonth)
+		}
+		dbStorage := pricing.Storage.EstimateStorageCost(DatabaseVolumeSize)
+		estimate.Storage += dbStorage
+		estimate.AddDetail("database_volume", dbStorage)
+	}
+
+	storageGB := len(categorized.ObjectStorage)*100 + len(categorized.BlockStorage)*50
+	if storageGB > 0 {
+		storageCost := pricing.Storage.EstimateStorageCost(storageGB)
+		estimate.Storage += storageCost
+		estimate.AddDetail("storage", storageCost)
+	}
+
+	if config.HALevel.RequiresMultiServer() {
+		lbCost := 10.0
+		estimate.Network += lbCost
+		estimate.AddDetail("load_balancer", lbCost)
+	}
+
+	estimate.Calculate()
+
+	estimate.AddNote("Prices from Infomaniak catalog (last updated: December 2024)")
+	estimate.AddNote("Network egress: FREE (Infomaniak doesn't charge for traffic)")
+	estimate.AddNote("Hosted in Switzerland, OpenStack-based infrastructure")
+
+	return estimate, nil
+}
+
+// CategorizedResources holds resources grouped by type for generation.
+type CategorizedResources struct {
+	Compute       []*mapper.MappingResult
+	Kubernetes    []*mapper.MappingResult
+	Databases     []*mapper.MappingResult
+	Caches        []*mapper.MappingResult
+	ObjectStorage []*mapper.MappingResult
+	BlockStorage  []*mapper.MappingResult
+	LoadBalancers []*mapper.MappingResult
+	Other         []*mapper.MappingResult
+}
+
+// categorizeResources groups mapping results by resource type.
+func (g *Generator) categorizeResources(results []*mapper.MappingResult) *CategorizedResources {
+	categorized := &CategorizedResources{}
+
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+
+		resourceType := strings.ToLower(r.SourceResourceType)
+
+		switch r.SourceCategory {
+		case resource.CategoryCompute, resource.CategoryServerless:
+			categorized.Compute = append(categorized.Compute, r)
+
+		case resource.CategoryContainer:
+			if strings.Contains(resourceType, "kubernetes") ||
+				strings.Contains(resourceType, "eks") ||
+				strings.Contains(resourceType, "gke") ||
+				strings.Contains(resourceType, "aks") {
+				categorized.Kubernetes = append(categorized.Kubernetes, r)
+			} else {
+				categorized.Compute = append(categorized.Compute, r)
+			}
+
+		case resource.CategoryKubernetes:
+			categorized.Kubernetes = append(categorized.Kubernetes, r)
+
+		case resource.CategorySQLDatabase, resource.CategoryNoSQLDatabase:
+			categorized.Databases = append(categorized.Databases, r)
+
+		case resource.CategoryCache:
+			categorized.Caches = append(categorized.Caches, r)
+
+		case resource.CategoryObjectStorage:
+			categorized.ObjectStorage = append(categorized.ObjectStorage, r)
+
+		case resource.CategoryBlockStorage, resource.CategoryFileStorage:
+			categorized.BlockStorage = append(categorized.BlockStorage, r)
+
+		case resource.CategoryLoadBalancer:
+			categorized.LoadBalancers = append(categorized.LoadBalancers, r)
+
+		default:
+			categorized.Other = append(categorized.Other, r)
+		}
+	}
+
+	return categorized
+}
+
+// GetInstanceCount returns the number of application instances for an HA level.
+func GetInstanceCount(level target.HALevel) int {
+	switch level {
+	case target.HALevelCluster:
+		return 3
+	case target.HALevelMultiServer:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func (g *Generator) generateMain(region string) string {
+	return fmt.Sprintf(`# Infomaniak Public Cloud Terraform Configuration
+# Generated by Homeport - %s
+
+terraform {
+  required_version = ">= 1.0"
+  required_providers {
+    openstack = {
+      source  = "terraform-provider-openstack/openstack"
+      version = "~> 2.1"
+    }
+  }
+}
+
+provider "openstack" {
+  auth_url            = "https://api.%s.infomaniak.cloud/identity/v3"
+  user_name           = var.os_username
+  password            = var.os_password
+  tenant_name         = var.os_project_name
+  user_domain_name    = "Default"
+  project_domain_name = "Default"
+  region              = var.region
+}
+`, time.Now().Format(time.RFC3339), cloudEndpoints[region])
+}
+
+func (g *Generator) generateVariables(config *generator.TargetConfig, region string) string {
+	return fmt.Sprintf(`variable "project_name" {
+  description = "Project name"
+  type        = string
+  default     = "%s"
+}
+
+variable "os_username" {
+  description = "OpenStack username"
+  type        = string
+}
+
+variable "os_password" {
+  description = "OpenStack password"
+  type        = string
+  sensitive   = true
+}
+
+variable "os_project_name" {
+  description = "OpenStack project name"
+  type        = string
+}
+
+variable "region" {
+  description = "Infomaniak region"
+  type        = string
+  default     = "%s"
+}
+
+variable "instance_flavor" {
+  description = "Instance flavor"
+  type        = string
+  default     = "%s"
+}
+
+variable "ssh_public_key" {
+  description = "SSH public key for the instances"
+  type        = string
+  default     = ""
+}
+`, config.ProjectName, region, Flavor(config.HALevel))
+}
+
+func (g *Generator) generateOutputs(config *generator.TargetConfig) string {
+	out := `# Outputs
+
+output "instance_ips" {
+  description = "Public IPs of the application instances"
+  value       = openstack_networking_floatingip_v2.app[*].address
+}
+`
+	if config.HALevel.RequiresMultiServer() {
+		out += `
+output "load_balancer_ip" {
+  description = "Load balancer IP"
+  value       = openstack_networking_floatingip_v2.lb.address
+}
+`
+	}
+	return out
+}
+
+func (g *Generator) generateTfvarsExample(config *generator.TargetConfig, region string) string {
+	return fmt.Sprintf(`# Infomaniak Terraform Variables
+# Copy to terraform.tfvars and fill in values
+
+project_name = "%s"
+region       = "%s"
+
+# OpenStack credentials (from the OpenStack RC file in the Infomaniak Manager)
+os_username     = ""
+os_password     = ""
+os_project_name = ""
+
+ssh_public_key = ""
+`, config.ProjectName, region)
+}
+
+// SanitizeName converts a name to a valid OpenStack resource name.
+func SanitizeName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, " ", "-")
+	name = strings.ReplaceAll(name, "_", "-")
+	var result strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			result.WriteRune(r)
+		}
+	}
+	return strings.Trim(result.String(), "-")
+}
+
+// SanitizeTFName converts a name to a valid Terraform resource name.
+func SanitizeTFName(name string) string {
+	clean := strings.ReplaceAll(SanitizeName(name), "-", "_")
+	// Ensure starts with letter
+	if len(clean) > 0 && clean[0] >= '0' && clean[0] <= '9' {
+		clean = "r_" + clean
+	}
+	return clean
+}
+
+func init() {
+	generator.RegisterGenerator(New())
+}
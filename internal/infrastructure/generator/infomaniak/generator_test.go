@@ -0,0 +1,72 @@
+package infomaniak
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+func testResults() []*mapper.MappingResult {
+	return []*mapper.MappingResult{
+		{SourceResourceName: "web", SourceResourceType: "aws_instance", SourceCategory: resource.CategoryCompute},
+		{SourceResourceName: "orders-db", SourceResourceType: "aws_db_instance", SourceCategory: resource.CategorySQLDatabase},
+		{SourceResourceName: "billing-db", SourceResourceType: "aws_db_instance", SourceCategory: resource.CategorySQLDatabase},
+		{SourceResourceName: "sessions", SourceResourceType: "aws_elasticache_cluster", SourceCategory: resource.CategoryCache},
+		{SourceResourceName: "assets", SourceResourceType: "aws_s3_bucket", SourceCategory: resource.CategoryObjectStorage},
+		{SourceResourceName: "data", SourceResourceType: "aws_ebs_volume", SourceCategory: resource.CategoryBlockStorage},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	config := &generator.TargetConfig{ProjectName: "shop", HALevel: target.HALevelMultiServer}
+	output, err := New().Generate(context.Background(), testResults(), config)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"main.tf":       {`auth_url            = "https://api.pub1.infomaniak.cloud/identity/v3"`},
+		"compute.tf":    {`resource "openstack_compute_instance_v2" "app"`, `count           = 2`},
+		"networking.tf": {`resource "openstack_lb_loadbalancer_v2" "main"`, `name = "ext-floating1"`},
+		"database.tf":   {"-p 5432:5432", "-p 5433:5432", "-p 6379:6379 -v /var/lib/homeport/sessions:/data redis:7"},
+		"storage.tf":    {`resource "openstack_objectstorage_container_v1" "assets"`, `resource "openstack_compute_volume_attach_v2" "data"`, `https://s3.pub1.infomaniak.cloud`},
+	}
+	for file, snippets := range want {
+		content := string(output.TerraformFiles[file])
+		for _, snippet := range snippets {
+			if !strings.Contains(content, snippet) {
+				t.Errorf("%s missing %q", file, snippet)
+			}
+		}
+	}
+	if !output.HasWarnings() {
+		t.Error("Generate() has no warning about self-managed databases")
+	}
+}
+
+func TestGetRegion(t *testing.T) {
+	config := &generator.TargetConfig{
+		HALevel:      target.HALevelNone,
+		TargetConfig: &target.TargetConfig{Infomaniak: &target.InfomaniakConfig{Region: "dc4-a"}},
+	}
+	if region := GetRegion(config); region != "dc4-a" {
+		t.Fatalf("GetRegion() = %s, want dc4-a", region)
+	}
+	output, err := New().Generate(context.Background(), testResults(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(output.TerraformFiles["main.tf"]), "api.pub2.infomaniak.cloud") {
+		t.Error("main.tf does not use the pub2 endpoint for dc4-a")
+	}
+
+	config.TargetConfig.Infomaniak.Region = "gra11"
+	if err := New().Validate(testResults(), config); err == nil {
+		t.Fatal("Validate() error = nil, want unknown region")
+	}
+}
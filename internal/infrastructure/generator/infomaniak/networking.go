@@ -0,0 +1,119 @@
+package infomaniak
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+)
+
+// ExternalNetwork is the public network floating IPs are allocated from.
+const ExternalNetwork = "ext-floating1"
+
+// GenerateNetworkingTF generates the private network and its router, the
+// application security group and, for multi-server levels, an Octavia load
+// balancer in front of the application instances.
+func GenerateNetworkingTF(config *generator.TargetConfig) string {
+	var buf bytes.Buffer
+	buf.WriteString("# Networking Resources\n\n")
+
+	buf.WriteString(fmt.Sprintf(`data "openstack_networking_network_v2" "external" {
+  name = "%s"
+}
+
+resource "openstack_networking_network_v2" "private" {
+  name           = "${var.project_name}-network"
+  admin_state_up = true
+}
+
+resource "openstack_networking_subnet_v2" "private" {
+  name            = "${var.project_name}-subnet"
+  network_id      = openstack_networking_network_v2.private.id
+  cidr            = "10.0.0.0/24"
+  ip_version      = 4
+  dns_nameservers = ["83.166.143.51", "83.166.143.52"]
+}
+
+resource "openstack_networking_router_v2" "main" {
+  name                = "${var.project_name}-router"
+  external_network_id = data.openstack_networking_network_v2.external.id
+}
+
+resource "openstack_networking_router_interface_v2" "private" {
+  router_id = openstack_networking_router_v2.main.id
+  subnet_id = openstack_networking_subnet_v2.private.id
+}
+
+resource "openstack_networking_secgroup_v2" "app" {
+  name        = "${var.project_name}-app"
+  description = "Application instances"
+}
+
+`, ExternalNetwork))
+
+	for _, rule := range []struct {
+		name string
+		port int
+	}{{"ssh", 22}, {"http", 80}, {"https", 443}} {
+		buf.WriteString(fmt.Sprintf(`resource "openstack_networking_secgroup_rule_v2" "%s" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = %d
+  port_range_max    = %d
+  remote_ip_prefix  = "0.0.0.0/0"
+  security_group_id = openstack_networking_secgroup_v2.app.id
+}
+
+`, rule.name, rule.port, rule.port))
+	}
+
+	if config.HALevel.RequiresMultiServer() {
+		buf.WriteString(fmt.Sprintf(`# ============================================
+# Load Balancer (Octavia)
+# ============================================
+
+resource "openstack_lb_loadbalancer_v2" "main" {
+  name          = "${var.project_name}-lb"
+  vip_subnet_id = openstack_networking_subnet_v2.private.id
+}
+
+resource "openstack_lb_listener_v2" "http" {
+  name            = "http"
+  protocol        = "TCP"
+  protocol_port   = 80
+  loadbalancer_id = openstack_lb_loadbalancer_v2.main.id
+}
+
+resource "openstack_lb_pool_v2" "http" {
+  name        = "http"
+  protocol    = "TCP"
+  lb_method   = "ROUND_ROBIN"
+  listener_id = openstack_lb_listener_v2.http.id
+}
+
+resource "openstack_lb_monitor_v2" "http" {
+  pool_id     = openstack_lb_pool_v2.http.id
+  type        = "TCP"
+  delay       = 10
+  timeout     = 5
+  max_retries = 2
+}
+
+resource "openstack_lb_member_v2" "http" {
+  count         = %d
+  pool_id       = openstack_lb_pool_v2.http.id
+  address       = openstack_compute_instance_v2.app[count.index].access_ip_v4
+  protocol_port = 80
+  subnet_id     = openstack_networking_subnet_v2.private.id
+}
+
+resource "openstack_networking_floatingip_v2" "lb" {
+  pool    = "%s"
+  port_id = openstack_lb_loadbalancer_v2.main.vip_port_id
+}
+`, GetInstanceCount(config.HALevel), ExternalNetwork))
+	}
+
+	return buf.String()
+}
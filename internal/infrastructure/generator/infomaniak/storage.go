@@ -0,0 +1,129 @@
+package infomaniak
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+)
+
+// VolumeSize returns the size in GB of the volume replacing a source volume,
+// 50 GB when the source does not say.
+func VolumeSize(r *mapper.MappingResult) int {
+	if r.SourceResource != nil {
+		for _, key := range []string{"size_gb", "size"} {
+			if size := r.SourceResource.GetConfigInt(key); size > 0 {
+				return size
+			}
+		}
+	}
+	return 50
+}
+
+// GenerateStorageTF generates Terraform configuration for storage resources.
+// Buckets become Swift containers, reachable through the S3 API with EC2
+// credentials, and volumes become Cinder volumes attached to the first
+// application instance.
+func GenerateStorageTF(objectStorage, blockStorage []*mapper.MappingResult, config *generator.TargetConfig, region string) string {
+	var buf bytes.Buffer
+	buf.WriteString("# Storage Resources\n\n")
+
+	if len(objectStorage) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Object Storage (Swift, S3-compatible)\n")
+		buf.WriteString("# ============================================\n\n")
+
+		for i, r := range objectStorage {
+			name := SanitizeTFName(r.SourceResourceName)
+			if name == "" {
+				name = fmt.Sprintf("bucket_%d", i)
+			}
+			displayName := SanitizeName(r.SourceResourceName)
+			if displayName == "" {
+				displayName = fmt.Sprintf("bucket-%d", i)
+			}
+
+			buf.WriteString(fmt.Sprintf("# Bucket: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			buf.WriteString(fmt.Sprintf(`resource "openstack_objectstorage_container_v1" "%s" {
+  name   = "${var.project_name}-%s"
+  region = var.region
+`, name, displayName))
+			if config.HALevel.Level() >= 1 {
+				buf.WriteString(`
+  versioning = true
+`)
+			}
+			buf.WriteString(fmt.Sprintf(`
+  metadata = {
+    managed_by = "homeport"
+  }
+}
+
+output "bucket_%s_name" {
+  description = "Name of the %s bucket"
+  value       = openstack_objectstorage_container_v1.%s.name
+}
+
+`, name, r.SourceResourceName, name))
+		}
+
+		buf.WriteString(fmt.Sprintf(`# S3 credentials for the buckets
+resource "openstack_identity_ec2_credential_v3" "storage" {}
+
+output "storage_endpoint" {
+  description = "S3 endpoint for Object Storage"
+  value       = "https://s3.%s.infomaniak.cloud"
+}
+
+output "storage_access_key_id" {
+  description = "Access key ID for Object Storage"
+  value       = openstack_identity_ec2_credential_v3.storage.access
+  sensitive   = true
+}
+
+output "storage_secret_key" {
+  description = "Secret key for Object Storage"
+  value       = openstack_identity_ec2_credential_v3.storage.secret
+  sensitive   = true
+}
+
+`, cloudEndpoints[region]))
+	}
+
+	if len(blockStorage) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Block Storage Volumes (Cinder)\n")
+		buf.WriteString("# ============================================\n\n")
+
+		for i, r := range blockStorage {
+			name := SanitizeTFName(r.SourceResourceName)
+			if name == "" {
+				name = fmt.Sprintf("volume_%d", i)
+			}
+			displayName := SanitizeName(r.SourceResourceName)
+			if displayName == "" {
+				displayName = fmt.Sprintf("volume-%d", i)
+			}
+
+			buf.WriteString(fmt.Sprintf("# Block Volume: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			buf.WriteString(fmt.Sprintf(`resource "openstack_blockstorage_volume_v3" "%s" {
+  name = "${var.project_name}-%s"
+  size = %d
+
+  metadata = {
+    managed_by = "homeport"
+  }
+}
+
+resource "openstack_compute_volume_attach_v2" "%s" {
+  instance_id = openstack_compute_instance_v2.app[0].id
+  volume_id   = openstack_blockstorage_volume_v3.%s.id
+}
+
+`, name, displayName, VolumeSize(r), name, name))
+		}
+	}
+
+	return buf.String()
+}
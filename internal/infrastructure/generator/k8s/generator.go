@@ -44,7 +44,7 @@ func (g *Generator) Name() string {
 
 // Description returns description.
 func (g *Generator) Description() string {
-	return "Generates Kubernetes manifests (Deployments, Services, ConfigMaps, Secrets, PVCs, Ingress) and Helm charts"
+	return "Generates Kubernetes manifests (Deployments, StatefulSets, Services, ConfigMaps, Secrets, PVCs, Ingress, NetworkPolicies, HPAs, PDBs) and Helm charts"
 }
 
 // SupportedHALevels returns supported HA levels.
@@ -93,11 +93,13 @@ func (g *Generator) Generate(ctx context.Context, results []*mapper.MappingResul
 	allManifests.WriteString(ns)
 	output.AddK8sManifest("00-namespace.yaml", []byte(ns))
 
-	// Collect services
+	// Collect services, remembering which ones back stateful resources
 	var services []*mapper.DockerService
+	stateful := make(map[*mapper.DockerService]bool)
 	for _, r := range results {
 		if r.DockerService != nil {
 			services = append(services, r.DockerService)
+			stateful[r.DockerService] = isStatefulCategory(r.SourceCategory)
 		}
 		services = append(services, r.AdditionalServices...)
 	}
@@ -123,20 +125,55 @@ func (g *Generator) Generate(ctx context.Context, results []*mapper.MappingResul
 			}
 		}
 
-		// PVCs
-		for _, vol := range svc.Volumes {
-			volName, _, isNamed := parseVolume(vol)
-			if isNamed {
-				pvc := g.generatePVC(volName, namespace)
-				allManifests.WriteString("\n---\n" + pvc)
-				output.AddK8sManifest(prefix+"-pvc-"+sanitizeName(volName)+".yaml", []byte(pvc))
+		svcReplicas := replicas
+		if stateful[svc] {
+			// StatefulSet with claim templates and its headless Service
+			w := workloadFromDockerService(svc, namespace)
+			svcReplicas = w.Replicas
+			sts := g.generateStatefulSet(w)
+			allManifests.WriteString("\n---\n" + sts)
+			output.AddK8sManifest(prefix+"-statefulset.yaml", []byte(sts))
+
+			headless := g.generateHeadlessService(w)
+			allManifests.WriteString("\n---\n" + headless)
+			output.AddK8sManifest(prefix+"-headless-service.yaml", []byte(headless))
+
+			if config.HALevel.RequiresMultiServer() && svcReplicas == 1 {
+				output.AddWarning(fmt.Sprintf("%s runs as a single-replica StatefulSet: HA level %s needs replication configured in the service or through an operator", svc.Name, config.HALevel))
+			}
+		} else {
+			// PVCs
+			for _, vol := range svc.Volumes {
+				volName, _, isNamed := parseVolume(vol)
+				if isNamed {
+					pvc := g.generatePVC(volName, namespace)
+					allManifests.WriteString("\n---\n" + pvc)
+					output.AddK8sManifest(prefix+"-pvc-"+sanitizeName(volName)+".yaml", []byte(pvc))
+				}
+			}
+
+			// Deployment, autoscaled when the service declares replicas
+			minReplicas, maxReplicas, autoscale := autoscaleBounds(mapperDeployReplicas(svc.Deploy), replicas)
+			if autoscale {
+				svcReplicas = minReplicas
+			}
+			deploy := g.generateDeployment(svc, namespace, svcReplicas)
+			allManifests.WriteString("\n---\n" + deploy)
+			output.AddK8sManifest(prefix+"-deployment.yaml", []byte(deploy))
+
+			if autoscale {
+				hpa := g.generateHPA(sanitizeName(svc.Name), namespace, minReplicas, maxReplicas)
+				allManifests.WriteString("\n---\n" + hpa)
+				output.AddK8sManifest(prefix+"-hpa.yaml", []byte(hpa))
 			}
 		}
 
-		// Deployment
-		deploy := g.generateDeployment(svc, namespace, replicas)
-		allManifests.WriteString("\n---\n" + deploy)
-		output.AddK8sManifest(prefix+"-deployment.yaml", []byte(deploy))
+		// PodDisruptionBudget
+		if needsDisruptionBudget(config.HALevel, svcReplicas) {
+			pdb := g.generatePDB(sanitizeName(svc.Name), namespace)
+			allManifests.WriteString("\n---\n" + pdb)
+			output.AddK8sManifest(prefix+"-pdb.yaml", []byte(pdb))
+		}
 
 		// Service
 		if len(svc.Ports) > 0 {
@@ -210,6 +247,7 @@ func (g *Generator) GenerateFromStacks(ctx context.Context, stacks *stack.Consol
 
 	// Order stacks by dependencies
 	orderedStacks := g.orderStacksByDependency(stacks.Stacks)
+	networks := newNetworkIndex(stacks)
 
 	// Generate per-stack Helm charts and manifests
 	for stackIdx, stk := range orderedStacks {
@@ -224,6 +262,7 @@ func (g *Generator) GenerateFromStacks(ctx context.Context, stacks *stack.Consol
 		}
 
 		// Generate raw K8s manifests for each service in the stack
+		exposed := false
 		for svcIdx, svc := range stk.Services {
 			svcPrefix := fmt.Sprintf("%s-%02d-%s", stackPrefix, svcIdx+1, sanitizeName(svc.Name))
 
@@ -242,20 +281,55 @@ func (g *Generator) GenerateFromStacks(ctx context.Context, stacks *stack.Consol
 				}
 			}
 
-			// PVCs
-			for _, vol := range svc.Volumes {
-				volName, _, isNamed := parseVolume(vol)
-				if isNamed {
-					pvc := g.generatePVC(volName, namespace)
-					allManifests.WriteString("\n---\n" + pvc)
-					output.AddK8sManifest(svcPrefix+"-pvc-"+sanitizeName(volName)+".yaml", []byte(pvc))
+			svcReplicas := replicas
+			if isStatefulStack(stk.Type) {
+				// StatefulSet with claim templates and its headless Service
+				w := workloadFromStackService(svc, namespace, stk.Type)
+				svcReplicas = w.Replicas
+				sts := g.generateStatefulSet(w)
+				allManifests.WriteString("\n---\n" + sts)
+				output.AddK8sManifest(svcPrefix+"-statefulset.yaml", []byte(sts))
+
+				headless := g.generateHeadlessService(w)
+				allManifests.WriteString("\n---\n" + headless)
+				output.AddK8sManifest(svcPrefix+"-headless-service.yaml", []byte(headless))
+
+				if config.HALevel.RequiresMultiServer() && svcReplicas == 1 {
+					output.AddWarning(fmt.Sprintf("%s runs as a single-replica StatefulSet: HA level %s needs replication configured in the service or through an operator", svc.Name, config.HALevel))
+				}
+			} else {
+				// PVCs
+				for _, vol := range svc.Volumes {
+					volName, _, isNamed := parseVolume(vol)
+					if isNamed {
+						pvc := g.generatePVC(volName, namespace)
+						allManifests.WriteString("\n---\n" + pvc)
+						output.AddK8sManifest(svcPrefix+"-pvc-"+sanitizeName(volName)+".yaml", []byte(pvc))
+					}
+				}
+
+				// Deployment, autoscaled when the service declares replicas
+				minReplicas, maxReplicas, autoscale := autoscaleBounds(stackDeployReplicas(svc.Deploy), replicas)
+				if autoscale {
+					svcReplicas = minReplicas
+				}
+				deploy := g.generateStackDeployment(svc, namespace, svcReplicas, stk.Type)
+				allManifests.WriteString("\n---\n" + deploy)
+				output.AddK8sManifest(svcPrefix+"-deployment.yaml", []byte(deploy))
+
+				if autoscale {
+					hpa := g.generateHPA(sanitizeName(svc.Name), namespace, minReplicas, maxReplicas)
+					allManifests.WriteString("\n---\n" + hpa)
+					output.AddK8sManifest(svcPrefix+"-hpa.yaml", []byte(hpa))
 				}
 			}
 
-			// Deployment
-			deploy := g.generateStackDeployment(svc, namespace, replicas, stk.Type)
-			allManifests.WriteString("\n---\n" + deploy)
-			output.AddK8sManifest(svcPrefix+"-deployment.yaml", []byte(deploy))
+			// PodDisruptionBudget
+			if needsDisruptionBudget(config.HALevel, svcReplicas) {
+				pdb := g.generatePDB(sanitizeName(svc.Name), namespace)
+				allManifests.WriteString("\n---\n" + pdb)
+				output.AddK8sManifest(svcPrefix+"-pdb.yaml", []byte(pdb))
+			}
 
 			// Service
 			if len(svc.Ports) > 0 {
@@ -266,12 +340,20 @@ func (g *Generator) GenerateFromStacks(ctx context.Context, stacks *stack.Consol
 
 			// Ingress
 			if config.BaseURL != "" && isStackServiceExposed(svc) {
+				exposed = true
 				ingress := g.generateStackIngress(svc, namespace, config, stk.Type)
 				allManifests.WriteString("\n---\n" + ingress)
 				output.AddK8sManifest(svcPrefix+"-ingress.yaml", []byte(ingress))
 			}
 		}
 
+		// NetworkPolicy mirroring the source security groups
+		if len(stk.Services) > 0 {
+			netpol := g.generateStackNetworkPolicy(stk, networks, namespace, exposed)
+			allManifests.WriteString("\n---\n" + netpol)
+			output.AddK8sManifest(stackPrefix+"-networkpolicy.yaml", []byte(netpol))
+		}
+
 		// Add stack configs and scripts
 		for name, content := range stk.Configs {
 			output.AddConfig(fmt.Sprintf("%s/%s", stk.Type.String(), name), content)
@@ -355,11 +437,15 @@ global:
 
 	for _, svc := range stk.Services {
 		svcName := sanitizeName(svc.Name)
+		svcReplicas := replicas
+		if isStatefulStack(stk.Type) {
+			svcReplicas = statefulReplicas(stackDeployReplicas(svc.Deploy))
+		}
 		valuesYaml.WriteString(fmt.Sprintf(`%s:
   enabled: true
   image: %s
   replicas: %d
-`, svcName, svc.Image, replicas))
+`, svcName, svc.Image, svcReplicas))
 
 		// Add ports if any
 		if len(svc.Ports) > 0 {
@@ -434,9 +520,13 @@ app.kubernetes.io/instance: {{ .Release.Name }}
 	for _, svc := range stk.Services {
 		svcName := sanitizeName(svc.Name)
 
-		// Deployment template
+		// Deployment or StatefulSet template
 		deployTpl := g.generateHelmDeploymentTemplate(svc, stk.Type)
-		files[fmt.Sprintf("templates/%s-deployment.yaml", svcName)] = []byte(deployTpl)
+		kind := "deployment"
+		if isStatefulStack(stk.Type) {
+			kind = "statefulset"
+		}
+		files[fmt.Sprintf("templates/%s-%s.yaml", svcName, kind)] = []byte(deployTpl)
 
 		// Service template
 		if len(svc.Ports) > 0 {
@@ -504,22 +594,35 @@ global:
 	return files
 }
 
-// generateHelmDeploymentTemplate generates a Helm template for a Deployment.
+// generateHelmDeploymentTemplate generates a Helm template for a Deployment,
+// or for a StatefulSet governed by the service template in stateful stacks.
 func (g *Generator) generateHelmDeploymentTemplate(svc *stack.Service, stackType stack.StackType) string {
 	svcName := sanitizeName(svc.Name)
 	chartName := stackType.String()
+	stateful := isStatefulStack(stackType)
+
+	kind := "Deployment"
+	replicas := fmt.Sprintf("{{ .Values.%s.replicas | default .Values.global.replicas }}", svcName)
+	if stateful {
+		kind = "StatefulSet"
+		replicas = fmt.Sprintf("{{ .Values.%s.replicas | default 1 }}", svcName)
+	}
 
 	var buf bytes.Buffer
 	buf.WriteString(fmt.Sprintf(`{{- if .Values.%s.enabled }}
 apiVersion: apps/v1
-kind: Deployment
+kind: %s
 metadata:
   name: {{ include "%s.fullname" . }}-%s
   labels:
     {{- include "%s.labels" . | nindent 4 }}
     app.kubernetes.io/component: %s
 spec:
-  replicas: {{ .Values.%s.replicas | default .Values.global.replicas }}
+`, svcName, kind, chartName, svcName, chartName, svcName))
+	if stateful {
+		buf.WriteString(fmt.Sprintf("  serviceName: {{ include \"%s.fullname\" . }}-%s\n", chartName, svcName))
+	}
+	buf.WriteString(fmt.Sprintf(`  replicas: %s
   selector:
     matchLabels:
       {{- include "%s.selectorLabels" . | nindent 6 }}
@@ -534,7 +637,7 @@ spec:
         - name: %s
           image: {{ .Values.%s.image }}
           imagePullPolicy: IfNotPresent
-`, svcName, chartName, svcName, chartName, svcName, svcName, chartName, svcName, chartName, svcName, svcName, svcName))
+`, replicas, chartName, svcName, chartName, svcName, svcName, svcName))
 
 	// Ports
 	if len(svc.Ports) > 0 {
@@ -557,6 +660,21 @@ spec:
 		}
 	}
 
+	// Volume mounts backed by claim templates
+	var claims []string
+	if stateful {
+		for _, vol := range svc.Volumes {
+			volName, mountPath, isNamed := parseVolume(vol)
+			if isNamed && mountPath != "" {
+				if len(claims) == 0 {
+					buf.WriteString("          volumeMounts:\n")
+				}
+				claims = append(claims, sanitizeName(volName))
+				buf.WriteString(fmt.Sprintf("            - name: %s\n              mountPath: %s\n", sanitizeName(volName), mountPath))
+			}
+		}
+	}
+
 	// Resources
 	buf.WriteString(`          resources:
             requests:
@@ -565,17 +683,38 @@ spec:
             limits:
               memory: "512Mi"
               cpu: "500m"
-{{- end }}
 `)
 
+	if len(claims) > 0 {
+		buf.WriteString("  volumeClaimTemplates:\n")
+		for _, claim := range claims {
+			buf.WriteString(fmt.Sprintf(`    - metadata:
+        name: %s
+      spec:
+        accessModes:
+          - ReadWriteOnce
+        resources:
+          requests:
+            storage: %s
+`, claim, defaultStorageSize))
+		}
+	}
+
+	buf.WriteString("{{- end }}\n")
 	return buf.String()
 }
 
-// generateHelmServiceTemplate generates a Helm template for a Service.
+// generateHelmServiceTemplate generates a Helm template for a Service. In
+// stateful stacks the Service is headless and governs the StatefulSet.
 func (g *Generator) generateHelmServiceTemplate(svc *stack.Service, stackType stack.StackType) string {
 	svcName := sanitizeName(svc.Name)
 	chartName := stackType.String()
 
+	headless := ""
+	if isStatefulStack(stackType) {
+		headless = "\n  clusterIP: None"
+	}
+
 	return fmt.Sprintf(`{{- if .Values.%s.enabled }}
 apiVersion: v1
 kind: Service
@@ -583,7 +722,7 @@ metadata:
   name: {{ include "%s.fullname" . }}-%s
   labels:
     {{- include "%s.labels" . | nindent 4 }}
-spec:
+spec:%s
   selector:
     {{- include "%s.selectorLabels" . | nindent 4 }}
     app.kubernetes.io/component: %s
@@ -593,7 +732,7 @@ spec:
       targetPort: {{ . }}
     {{- end }}
 {{- end }}
-`, svcName, chartName, svcName, chartName, chartName, svcName, svcName)
+`, svcName, chartName, svcName, chartName, headless, chartName, svcName, svcName)
 }
 
 // generateHelmConfigMapTemplate generates a Helm template for a ConfigMap.
@@ -731,13 +870,7 @@ spec:
 	}
 
 	// Resources
-	buf.WriteString("          resources:\n")
-	buf.WriteString("            requests:\n")
-	buf.WriteString("              memory: \"128Mi\"\n")
-	buf.WriteString("              cpu: \"100m\"\n")
-	buf.WriteString("            limits:\n")
-	buf.WriteString("              memory: \"512Mi\"\n")
-	buf.WriteString("              cpu: \"500m\"\n")
+	writeResources(&buf, stackResources(svc.Deploy))
 
 	return buf.String()
 }
@@ -1006,13 +1139,7 @@ spec:
 	}
 
 	// Resources
-	buf.WriteString("          resources:\n")
-	buf.WriteString("            requests:\n")
-	buf.WriteString("              memory: \"128Mi\"\n")
-	buf.WriteString("              cpu: \"100m\"\n")
-	buf.WriteString("            limits:\n")
-	buf.WriteString("              memory: \"512Mi\"\n")
-	buf.WriteString("              cpu: \"500m\"\n")
+	writeResources(&buf, mapperResources(svc.Deploy))
 
 	return buf.String()
 }
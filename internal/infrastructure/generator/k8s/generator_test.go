@@ -0,0 +1,234 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/domain/stack"
+	"github.com/homeport/homeport/internal/domain/target"
+	"gopkg.in/yaml.v3"
+)
+
+func testStacks() *stack.ConsolidatedResult {
+	result := stack.NewConsolidatedResult()
+
+	vpc := resource.NewAWSResource("vpc-1", "main", "aws_vpc")
+	vpc.Config["cidr_block"] = "10.0.0.0/16"
+
+	appSG := resource.NewAWSResource("sg-app", "app", "aws_security_group")
+	dbSG := resource.NewAWSResource("sg-db", "db", "aws_security_group")
+	dbSG.Config["ingress"] = []interface{}{
+		map[string]interface{}{"protocol": "tcp", "from_port": float64(5432), "to_port": float64(5432), "security_groups": []interface{}{"sg-app"}},
+		map[string]interface{}{"protocol": "tcp", "from_port": float64(5432), "to_port": float64(5432), "cidr_blocks": []interface{}{"10.0.1.0/24", "192.168.10.0/24"}},
+	}
+	result.AddPassthrough(vpc)
+	result.AddPassthrough(appSG)
+	result.AddPassthrough(dbSG)
+
+	rds := resource.NewAWSResource("orders", "orders", "aws_db_instance")
+	rds.Config["vpc_security_group_ids"] = []interface{}{"sg-db"}
+	db := stack.NewStack(stack.StackTypeDatabase, "database")
+	postgres := stack.NewService("postgres", "postgres:16")
+	postgres.Ports = []string{"5432:5432"}
+	postgres.Volumes = []string{"pgdata:/var/lib/postgresql/data"}
+	postgres.Environment["POSTGRES_PASSWORD"] = "secret"
+	postgres.HealthCheck = &stack.HealthCheck{Test: []string{"CMD-SHELL", "pg_isready -U postgres"}}
+	db.AddService(postgres)
+	db.AddSourceResource(rds)
+	result.AddStack(db)
+
+	ecs := resource.NewAWSResource("api", "api", "aws_ecs_service")
+	ecs.Config["security_groups"] = []interface{}{"sg-app"}
+	compute := stack.NewStack(stack.StackTypeCompute, "compute")
+	api := stack.NewService("api", "example/api:1.0")
+	api.Ports = []string{"8080:8080"}
+	api.Deploy = &stack.DeployConfig{
+		Replicas: 2,
+		Resources: &stack.ResourceConfig{
+			Limits:       &stack.ResourceSpec{CPUs: "1", Memory: "1G"},
+			Reservations: &stack.ResourceSpec{CPUs: "0.25", Memory: "256M"},
+		},
+	}
+	compute.AddService(api)
+	compute.AddSourceResource(ecs)
+	compute.AddDependency(stack.StackTypeDatabase)
+	result.AddStack(compute)
+
+	result.Metadata.TotalServices = 2
+	return result
+}
+
+func TestGenerateFromStacks(t *testing.T) {
+	config := &generator.TargetConfig{ProjectName: "shop", HALevel: target.HALevelMultiServer, BaseURL: "https://example.com"}
+	output, err := New().GenerateFromStacks(context.Background(), testStacks(), config)
+	if err != nil {
+		t.Fatalf("GenerateFromStacks() error = %v", err)
+	}
+
+	manifest := func(suffix string) string {
+		t.Helper()
+		for name, content := range output.K8sManifests {
+			if strings.HasSuffix(name, suffix) {
+				return string(content)
+			}
+		}
+		t.Fatalf("no manifest ending in %s", suffix)
+		return ""
+	}
+
+	t.Run("database renders as StatefulSet", func(t *testing.T) {
+		for name := range output.K8sManifests {
+			if strings.Contains(name, "postgres-deployment") || strings.Contains(name, "postgres-pvc") {
+				t.Errorf("unexpected manifest %s for a database service", name)
+			}
+		}
+		sts := manifest("postgres-statefulset.yaml")
+		for _, want := range []string{"kind: StatefulSet", "serviceName: postgres-headless", "replicas: 1", "volumeClaimTemplates:", "name: pgdata", `- "pg_isready -U postgres"`} {
+			if !strings.Contains(sts, want) {
+				t.Errorf("StatefulSet missing %q:\n%s", want, sts)
+			}
+		}
+		if headless := manifest("postgres-headless-service.yaml"); !strings.Contains(headless, "clusterIP: None") {
+			t.Errorf("headless Service is not headless:\n%s", headless)
+		}
+		if !output.HasWarnings() {
+			t.Error("no warning about the single-replica database at multi-server HA")
+		}
+	})
+
+	t.Run("NetworkPolicy mirrors security groups", func(t *testing.T) {
+		policy := manifest("database-networkpolicy.yaml")
+		for _, want := range []string{
+			"app.kubernetes.io/stack: compute", // sg-app reference
+			"podSelector: {}",                  // CIDR inside the VPC
+			"cidr: 192.168.10.0/24",            // CIDR outside the VPC
+			"port: 5432",
+		} {
+			if !strings.Contains(policy, want) {
+				t.Errorf("NetworkPolicy missing %q:\n%s", want, policy)
+			}
+		}
+		if compute := manifest("compute-networkpolicy.yaml"); !strings.Contains(compute, "kubernetes.io/metadata.name: kube-system") {
+			t.Errorf("exposed stack does not admit the ingress controller:\n%s", compute)
+		}
+	})
+
+	t.Run("HPA and PDB derive from deploy config and HA level", func(t *testing.T) {
+		hpa := manifest("api-hpa.yaml")
+		for _, want := range []string{"kind: HorizontalPodAutoscaler", "minReplicas: 2", "maxReplicas: 6", "name: api"} {
+			if !strings.Contains(hpa, want) {
+				t.Errorf("HPA missing %q:\n%s", want, hpa)
+			}
+		}
+		deploy := manifest("api-deployment.yaml")
+		for _, want := range []string{`cpu: "250m"`, `memory: "256Mi"`, `cpu: "1000m"`, `memory: "1Gi"`} {
+			if !strings.Contains(deploy, want) {
+				t.Errorf("Deployment missing %q:\n%s", want, deploy)
+			}
+		}
+		if pdb := manifest("api-pdb.yaml"); !strings.Contains(pdb, "maxUnavailable: 1") {
+			t.Errorf("PDB:\n%s", pdb)
+		}
+		for name := range output.K8sManifests {
+			if strings.HasSuffix(name, "postgres-pdb.yaml") {
+				t.Error("single-replica StatefulSet has a PDB")
+			}
+		}
+	})
+
+	t.Run("manifests are valid YAML", func(t *testing.T) {
+		dec := yaml.NewDecoder(bytes.NewReader(output.K8sManifests["manifests.yaml"]))
+		for {
+			var doc map[string]interface{}
+			err := dec.Decode(&doc)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("manifests.yaml: %v", err)
+			}
+		}
+	})
+
+	t.Run("Helm chart uses a StatefulSet", func(t *testing.T) {
+		tpl, ok := output.HelmCharts["charts/database/templates/postgres-statefulset.yaml"]
+		if !ok {
+			t.Fatal("no StatefulSet template in the database chart")
+		}
+		if !strings.Contains(string(tpl), "kind: StatefulSet") || !strings.Contains(string(tpl), "volumeClaimTemplates:") {
+			t.Errorf("template:\n%s", tpl)
+		}
+	})
+}
+
+func TestGenerateStatefulCategories(t *testing.T) {
+	results := []*mapper.MappingResult{
+		{
+			SourceCategory: resource.CategoryCache,
+			DockerService:  &mapper.DockerService{Name: "redis", Image: "redis:7", Ports: []string{"6379"}, Volumes: []string{"redis-data:/data"}},
+		},
+		{
+			SourceCategory: resource.CategoryContainer,
+			DockerService:  &mapper.DockerService{Name: "web", Image: "nginx", Ports: []string{"80:80"}},
+		},
+	}
+	config := &generator.TargetConfig{ProjectName: "shop", HALevel: target.HALevelCluster}
+	output, err := New().Generate(context.Background(), results, config)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	want := []string{"01-redis-statefulset.yaml", "01-redis-headless-service.yaml", "02-web-deployment.yaml", "02-web-pdb.yaml"}
+	for _, name := range want {
+		if _, ok := output.K8sManifests[name]; !ok {
+			t.Errorf("missing manifest %s", name)
+		}
+	}
+	for _, name := range []string{"01-redis-pvc-redis-data.yaml", "01-redis-deployment.yaml", "02-web-hpa.yaml"} {
+		if _, ok := output.K8sManifests[name]; ok {
+			t.Errorf("unexpected manifest %s", name)
+		}
+	}
+}
+
+func TestQuantities(t *testing.T) {
+	cpus := map[string]string{"0.5": "500m", "2": "2000m", "250m": "250m", "": "", "many": ""}
+	for in, want := range cpus {
+		if got := k8sCPU(in); got != want {
+			t.Errorf("k8sCPU(%q) = %q, want %q", in, got, want)
+		}
+	}
+	memory := map[string]string{"512M": "512Mi", "2g": "2Gi", "1GiB": "1Gi", "256MB": "256Mi", "1073741824": "1073741824", "lots": ""}
+	for in, want := range memory {
+		if got := k8sMemory(in); got != want {
+			t.Errorf("k8sMemory(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSecurityGroupPorts(t *testing.T) {
+	tests := []struct {
+		protocol string
+		from, to int
+		want     []policyPort
+	}{
+		{"tcp", 5432, 5432, []policyPort{{Protocol: "TCP", Port: 5432, EndPort: 5432}}},
+		{"udp", 8000, 8100, []policyPort{{Protocol: "UDP", Port: 8000, EndPort: 8100}}},
+		{"tcp", 0, 65535, []policyPort{{Protocol: "TCP", Port: 1, EndPort: 65535}}},
+		{"-1", 0, 0, nil},
+		{"icmp", -1, -1, nil},
+	}
+	for _, tt := range tests {
+		got := securityGroupPorts(tt.protocol, tt.from, tt.to)
+		if len(got) != len(tt.want) || (len(got) == 1 && got[0] != tt.want[0]) {
+			t.Errorf("securityGroupPorts(%s, %d, %d) = %+v, want %+v", tt.protocol, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
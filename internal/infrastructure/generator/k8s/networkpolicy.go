@@ -0,0 +1,371 @@
+package k8s
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/domain/stack"
+)
+
+// ingressControllerNamespace is where the Traefik ingress controller runs on
+// K3s and on the clusters set up by Homeport.
+const ingressControllerNamespace = "kube-system"
+
+// securityGroupRefKeys are the configuration keys under which source resources
+// reference their security groups.
+var securityGroupRefKeys = []string{"vpc_security_group_ids", "security_group_ids", "security_groups"}
+
+// policyPeer is one entry of a NetworkPolicy "from" list.
+type policyPeer struct {
+	// Stack selects the pods of a stack; "*" selects every pod of the namespace
+	Stack string
+
+	// CIDR selects an IP block
+	CIDR string
+
+	// Namespace selects every pod of a namespace
+	Namespace string
+}
+
+// policyPort is one entry of a NetworkPolicy "ports" list.
+type policyPort struct {
+	Protocol string
+	Port     int
+	EndPort  int
+}
+
+// policyRule is one NetworkPolicy ingress rule. No ports means all ports.
+type policyRule struct {
+	Peers []policyPeer
+	Ports []policyPort
+}
+
+// networkIndex resolves the security groups and VPCs of the passthrough
+// resources that the consolidated stacks reference.
+type networkIndex struct {
+	securityGroups map[string]*resource.Resource
+	vpcCIDRs       []*net.IPNet
+	sgStacks       map[string][]string
+}
+
+// newNetworkIndex indexes the security groups and VPC CIDRs of a
+// consolidation result, and which stacks use each security group.
+func newNetworkIndex(stacks *stack.ConsolidatedResult) *networkIndex {
+	idx := &networkIndex{
+		securityGroups: make(map[string]*resource.Resource),
+		sgStacks:       make(map[string][]string),
+	}
+
+	for _, res := range stacks.Passthrough {
+		switch res.Type {
+		case "aws_security_group":
+			for _, key := range []string{res.ID, res.Name, res.GetConfigString("name"), res.GetConfigString("id")} {
+				if key != "" {
+					idx.securityGroups[key] = res
+				}
+			}
+		case "aws_vpc":
+			if _, cidr, err := net.ParseCIDR(res.GetConfigString("cidr_block")); err == nil {
+				idx.vpcCIDRs = append(idx.vpcCIDRs, cidr)
+			}
+		}
+	}
+
+	for _, stk := range stacks.Stacks {
+		for _, sg := range idx.stackSecurityGroups(stk) {
+			idx.sgStacks[sg.ID] = appendUnique(idx.sgStacks[sg.ID], stk.Type.String())
+		}
+	}
+
+	return idx
+}
+
+// stackSecurityGroups returns the known security groups referenced by the
+// source resources of a stack.
+func (idx *networkIndex) stackSecurityGroups(stk *stack.Stack) []*resource.Resource {
+	var groups []*resource.Resource
+	seen := make(map[string]bool)
+	for _, res := range stk.SourceResources {
+		for _, key := range securityGroupRefKeys {
+			for _, ref := range configStrings(res.Config[key]) {
+				if sg, ok := idx.securityGroups[ref]; ok && !seen[sg.ID] {
+					seen[sg.ID] = true
+					groups = append(groups, sg)
+				}
+			}
+		}
+	}
+	return groups
+}
+
+// inVPC reports whether a CIDR block lies within one of the source VPCs.
+func (idx *networkIndex) inVPC(cidr string) bool {
+	ip, block, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	ones, _ := block.Mask.Size()
+	for _, vpc := range idx.vpcCIDRs {
+		vpcOnes, _ := vpc.Mask.Size()
+		if vpc.Contains(ip) && ones >= vpcOnes {
+			return true
+		}
+	}
+	return false
+}
+
+// stackIngressRules derives the ingress rules of a stack from the ingress
+// rules of its security groups. Traffic from within a source VPC becomes
+// traffic from the namespace, since the namespace replaces the VPC, and
+// security group references become the stacks using that group. Stacks
+// without security groups accept traffic from their namespace on their ports.
+func (idx *networkIndex) stackIngressRules(stk *stack.Stack) []policyRule {
+	var rules []policyRule
+
+	for _, sg := range idx.stackSecurityGroups(stk) {
+		for _, item := range configSlice(sg.Config["ingress"]) {
+			rule, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			var peers []policyPeer
+			for _, cidr := range configStrings(rule["cidr_blocks"]) {
+				if idx.inVPC(cidr) {
+					peers = append(peers, policyPeer{Stack: "*"})
+				} else {
+					peers = append(peers, policyPeer{CIDR: cidr})
+				}
+			}
+			for _, cidr := range configStrings(rule["ipv6_cidr_blocks"]) {
+				peers = append(peers, policyPeer{CIDR: cidr})
+			}
+			for _, ref := range configStrings(rule["security_groups"]) {
+				group := idx.securityGroups[ref]
+				if group == nil || len(idx.sgStacks[group.ID]) == 0 {
+					// Instances outside the cluster: keep the namespace reachable
+					peers = append(peers, policyPeer{Stack: "*"})
+					continue
+				}
+				for _, stackType := range idx.sgStacks[group.ID] {
+					peers = append(peers, policyPeer{Stack: stackType})
+				}
+			}
+			if configBool(rule["self"]) {
+				peers = append(peers, policyPeer{Stack: stk.Type.String()})
+			}
+			if len(peers) == 0 {
+				continue
+			}
+
+			rules = append(rules, policyRule{
+				Peers: dedupePeers(peers),
+				Ports: securityGroupPorts(configString(rule["protocol"]), configInt(rule["from_port"]), configInt(rule["to_port"])),
+			})
+		}
+	}
+
+	if len(rules) == 0 {
+		rules = append(rules, policyRule{
+			Peers: []policyPeer{{Stack: "*"}},
+			Ports: stackPorts(stk),
+		})
+	}
+
+	return rules
+}
+
+// generateStackNetworkPolicy generates the NetworkPolicy restricting ingress
+// to the pods of a stack. Exposed stacks also accept traffic from the ingress
+// controller.
+func (g *Generator) generateStackNetworkPolicy(stk *stack.Stack, idx *networkIndex, namespace string, exposed bool) string {
+	stackType := stk.Type.String()
+	rules := idx.stackIngressRules(stk)
+	if exposed {
+		rules = append(rules, policyRule{
+			Peers: []policyPeer{{Namespace: ingressControllerNamespace}},
+			Ports: stackPorts(stk),
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf(`apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: %s-ingress
+  namespace: %s
+  labels:
+    app.kubernetes.io/stack: %s
+    app.kubernetes.io/managed-by: homeport
+spec:
+  podSelector:
+    matchLabels:
+      app.kubernetes.io/stack: %s
+  policyTypes:
+    - Ingress
+  ingress:
+`, stackType, namespace, stackType, stackType))
+
+	for _, rule := range rules {
+		buf.WriteString("    - from:\n")
+		for _, peer := range rule.Peers {
+			switch {
+			case peer.CIDR != "":
+				buf.WriteString(fmt.Sprintf("        - ipBlock:\n            cidr: %s\n", peer.CIDR))
+			case peer.Namespace != "":
+				buf.WriteString(fmt.Sprintf("        - namespaceSelector:\n            matchLabels:\n              kubernetes.io/metadata.name: %s\n", peer.Namespace))
+			case peer.Stack == "*":
+				buf.WriteString("        - podSelector: {}\n")
+			default:
+				buf.WriteString(fmt.Sprintf("        - podSelector:\n            matchLabels:\n              app.kubernetes.io/stack: %s\n", peer.Stack))
+			}
+		}
+		if len(rule.Ports) > 0 {
+			buf.WriteString("      ports:\n")
+			for _, p := range rule.Ports {
+				buf.WriteString(fmt.Sprintf("        - protocol: %s\n          port: %d\n", p.Protocol, p.Port))
+				if p.EndPort > p.Port {
+					buf.WriteString(fmt.Sprintf("          endPort: %d\n", p.EndPort))
+				}
+			}
+		}
+	}
+
+	return buf.String()
+}
+
+// securityGroupPorts converts the protocol and port range of a security group
+// rule. Rules for all protocols or all ports return no ports.
+func securityGroupPorts(protocol string, fromPort, toPort int) []policyPort {
+	switch strings.ToLower(protocol) {
+	case "tcp", "6":
+		protocol = "TCP"
+	case "udp", "17":
+		protocol = "UDP"
+	case "sctp", "132":
+		protocol = "SCTP"
+	default:
+		// "-1", "all" and ICMP cannot be expressed as ports
+		return nil
+	}
+	if fromPort <= 0 && (toPort <= 0 || toPort >= 65535) {
+		return []policyPort{{Protocol: protocol, Port: 1, EndPort: 65535}}
+	}
+	if toPort < fromPort {
+		toPort = fromPort
+	}
+	return []policyPort{{Protocol: protocol, Port: fromPort, EndPort: toPort}}
+}
+
+// stackPorts returns the TCP container ports of the services of a stack.
+func stackPorts(stk *stack.Stack) []policyPort {
+	seen := make(map[int]bool)
+	var ports []policyPort
+	for _, svc := range stk.Services {
+		for _, p := range svc.Ports {
+			protocol := "TCP"
+			if strings.HasSuffix(p, "/udp") {
+				protocol = "UDP"
+			}
+			port, err := strconv.Atoi(extractContainerPort(p))
+			if err != nil || seen[port] {
+				continue
+			}
+			seen[port] = true
+			ports = append(ports, policyPort{Protocol: protocol, Port: port})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+	return ports
+}
+
+// dedupePeers removes duplicate peers, keeping their order.
+func dedupePeers(peers []policyPeer) []policyPeer {
+	seen := make(map[policyPeer]bool)
+	out := peers[:0]
+	for _, p := range peers {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// configSlice returns a configuration value as a slice.
+func configSlice(v interface{}) []interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return val
+	case []map[string]interface{}:
+		out := make([]interface{}, len(val))
+		for i, m := range val {
+			out[i] = m
+		}
+		return out
+	default:
+		return []interface{}{val}
+	}
+}
+
+// configStrings returns a configuration value as a string slice.
+func configStrings(v interface{}) []string {
+	if val, ok := v.([]string); ok {
+		return val
+	}
+	var out []string
+	for _, item := range configSlice(v) {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func configString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.Itoa(int(val))
+	case int:
+		return strconv.Itoa(val)
+	}
+	return ""
+}
+
+func configInt(v interface{}) int {
+	switch val := v.(type) {
+	case int:
+		return val
+	case int64:
+		return int(val)
+	case float64:
+		return int(val)
+	case string:
+		n, _ := strconv.Atoi(val)
+		return n
+	}
+	return 0
+}
+
+func configBool(v interface{}) bool {
+	b, _ := v.(bool)
+	return b
+}
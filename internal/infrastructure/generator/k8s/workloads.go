@@ -0,0 +1,488 @@
+package k8s
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/domain/stack"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+// defaultStorageSize is the size requested for each persistent volume claim.
+const defaultStorageSize = "10Gi"
+
+// targetCPUUtilization is the average CPU utilization HPAs scale on.
+const targetCPUUtilization = 70
+
+// resourceRequirements holds the container requests and limits.
+type resourceRequirements struct {
+	RequestCPU    string
+	RequestMemory string
+	LimitCPU      string
+	LimitMemory   string
+}
+
+// defaultResources are used when the source declares no resources.
+var defaultResources = resourceRequirements{
+	RequestCPU:    "100m",
+	RequestMemory: "128Mi",
+	LimitCPU:      "500m",
+	LimitMemory:   "512Mi",
+}
+
+// workload is the input-independent description of a StatefulSet, built from
+// either a mapper.DockerService or a stack.Service.
+type workload struct {
+	Name        string
+	Namespace   string
+	StackType   string
+	Image       string
+	Ports       []string
+	Volumes     []string
+	Environment map[string]string
+	Command     []string
+	HealthCheck []string
+	Replicas    int
+	Resources   resourceRequirements
+}
+
+// isStatefulStack reports whether services of a stack type keep state and
+// render as StatefulSets.
+func isStatefulStack(t stack.StackType) bool {
+	switch t {
+	case stack.StackTypeDatabase, stack.StackTypeCache, stack.StackTypeMessaging:
+		return true
+	default:
+		return false
+	}
+}
+
+// isStatefulCategory reports whether a mapped resource category keeps state.
+func isStatefulCategory(c resource.Category) bool {
+	switch c {
+	case resource.CategorySQLDatabase, resource.CategoryNoSQLDatabase, resource.CategoryCache,
+		resource.CategoryQueue, resource.CategoryPubSub, resource.CategoryStream, resource.CategoryMessaging:
+		return true
+	default:
+		return false
+	}
+}
+
+// statefulReplicas returns the replica count of a StatefulSet. A database
+// image does not replicate by itself, so the HA level is not applied: only an
+// explicit deploy replica count scales it.
+func statefulReplicas(deployReplicas int) int {
+	if deployReplicas > 0 {
+		return deployReplicas
+	}
+	return 1
+}
+
+// autoscaleBounds returns the HPA replica range for a stateless service. The
+// minimum is the larger of the deploy replica count and the HA replica count.
+// Services without deploy replicas are not autoscaled.
+func autoscaleBounds(deployReplicas, haReplicas int) (minReplicas, maxReplicas int, ok bool) {
+	if deployReplicas <= 0 {
+		return 0, 0, false
+	}
+	minReplicas = deployReplicas
+	if haReplicas > minReplicas {
+		minReplicas = haReplicas
+	}
+	return minReplicas, minReplicas * 3, true
+}
+
+// needsDisruptionBudget reports whether a workload gets a PodDisruptionBudget.
+// Budgets only make sense once the HA level spreads several replicas.
+func needsDisruptionBudget(level target.HALevel, replicas int) bool {
+	return level.RequiresMultiServer() && replicas > 1
+}
+
+// workloadFromDockerService builds a workload from a mapped Docker service.
+func workloadFromDockerService(svc *mapper.DockerService, namespace string) *workload {
+	w := &workload{
+		Name:        sanitizeName(svc.Name),
+		Namespace:   namespace,
+		Image:       svc.Image,
+		Ports:       svc.Ports,
+		Volumes:     svc.Volumes,
+		Environment: svc.Environment,
+		Command:     svc.Command,
+		Replicas:    statefulReplicas(mapperDeployReplicas(svc.Deploy)),
+		Resources:   mapperResources(svc.Deploy),
+	}
+	if svc.HealthCheck != nil {
+		w.HealthCheck = svc.HealthCheck.Test
+	}
+	return w
+}
+
+// workloadFromStackService builds a workload from a stack service.
+func workloadFromStackService(svc *stack.Service, namespace string, stackType stack.StackType) *workload {
+	w := &workload{
+		Name:        sanitizeName(svc.Name),
+		Namespace:   namespace,
+		StackType:   stackType.String(),
+		Image:       svc.Image,
+		Ports:       svc.Ports,
+		Volumes:     svc.Volumes,
+		Environment: svc.Environment,
+		Command:     svc.Command,
+		Replicas:    statefulReplicas(stackDeployReplicas(svc.Deploy)),
+		Resources:   stackResources(svc.Deploy),
+	}
+	if svc.HealthCheck != nil {
+		w.HealthCheck = svc.HealthCheck.Test
+	}
+	return w
+}
+
+// headlessServiceName returns the name of the headless Service governing a
+// StatefulSet.
+func headlessServiceName(name string) string {
+	return name + "-headless"
+}
+
+// labels renders the workload labels at the given indentation.
+func (w *workload) labels(indent string) string {
+	out := fmt.Sprintf("%sapp: %s\n", indent, w.Name)
+	if w.StackType != "" {
+		out += fmt.Sprintf("%sapp.kubernetes.io/stack: %s\n", indent, w.StackType)
+	}
+	return out
+}
+
+// generateStatefulSet generates a StatefulSet. Named volumes become
+// volumeClaimTemplates so that each replica gets its own claim.
+func (g *Generator) generateStatefulSet(w *workload) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf(`apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+%s    app.kubernetes.io/managed-by: homeport
+spec:
+  serviceName: %s
+  replicas: %d
+  podManagementPolicy: OrderedReady
+  updateStrategy:
+    type: RollingUpdate
+  selector:
+    matchLabels:
+      app: %s
+  template:
+    metadata:
+      labels:
+%s    spec:
+      terminationGracePeriodSeconds: 30
+      containers:
+        - name: %s
+          image: %s
+          imagePullPolicy: IfNotPresent
+`, w.Name, w.Namespace, w.labels("    "), headlessServiceName(w.Name), w.Replicas, w.Name, w.labels("        "), w.Name, w.Image))
+
+	if len(w.Command) > 0 {
+		buf.WriteString("          args:\n")
+		for _, arg := range w.Command {
+			buf.WriteString(fmt.Sprintf("            - %s\n", strconv.Quote(arg)))
+		}
+	}
+
+	if len(w.Ports) > 0 {
+		buf.WriteString("          ports:\n")
+		for _, p := range w.Ports {
+			buf.WriteString(fmt.Sprintf("            - containerPort: %s\n", extractContainerPort(p)))
+		}
+	}
+
+	if len(w.Environment) > 0 {
+		buf.WriteString("          envFrom:\n")
+		configVars, secretVars := splitEnvVars(w.Environment)
+		if len(configVars) > 0 {
+			buf.WriteString(fmt.Sprintf("            - configMapRef:\n                name: %s-config\n", w.Name))
+		}
+		if len(secretVars) > 0 {
+			buf.WriteString(fmt.Sprintf("            - secretRef:\n                name: %s-secret\n", w.Name))
+		}
+	}
+
+	var claims []string
+	var mounts bytes.Buffer
+	for _, vol := range w.Volumes {
+		volName, mountPath, isNamed := parseVolume(vol)
+		if isNamed && mountPath != "" {
+			claims = append(claims, sanitizeName(volName))
+			mounts.WriteString(fmt.Sprintf("            - name: %s\n              mountPath: %s\n", sanitizeName(volName), mountPath))
+		}
+	}
+	if mounts.Len() > 0 {
+		buf.WriteString("          volumeMounts:\n")
+		buf.Write(mounts.Bytes())
+	}
+
+	if command := probeCommand(w.HealthCheck); len(command) > 0 {
+		for _, probe := range []string{"readinessProbe", "livenessProbe"} {
+			buf.WriteString(fmt.Sprintf("          %s:\n            exec:\n              command:\n", probe))
+			for _, arg := range command {
+				buf.WriteString(fmt.Sprintf("                - %s\n", strconv.Quote(arg)))
+			}
+			buf.WriteString("            periodSeconds: 10\n")
+		}
+	}
+
+	writeResources(&buf, w.Resources)
+
+	if len(claims) > 0 {
+		buf.WriteString("  volumeClaimTemplates:\n")
+		for _, claim := range claims {
+			buf.WriteString(fmt.Sprintf(`    - metadata:
+        name: %s
+      spec:
+        accessModes:
+          - ReadWriteOnce
+        resources:
+          requests:
+            storage: %s
+`, claim, defaultStorageSize))
+		}
+	}
+
+	return buf.String()
+}
+
+// generateHeadlessService generates the headless Service that gives the pods
+// of a StatefulSet stable DNS names.
+func (g *Generator) generateHeadlessService(w *workload) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf(`apiVersion: v1
+kind: Service
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+%sspec:
+  clusterIP: None
+  publishNotReadyAddresses: true
+  selector:
+    app: %s
+`, headlessServiceName(w.Name), w.Namespace, w.labels("    "), w.Name))
+
+	if len(w.Ports) > 0 {
+		buf.WriteString("  ports:\n")
+		for _, p := range w.Ports {
+			port := extractContainerPort(p)
+			buf.WriteString(fmt.Sprintf("    - name: port-%s\n      port: %s\n      targetPort: %s\n", port, port, port))
+		}
+	}
+
+	return buf.String()
+}
+
+// generateHPA generates a HorizontalPodAutoscaler for a Deployment.
+func (g *Generator) generateHPA(name, namespace string, minReplicas, maxReplicas int) string {
+	return fmt.Sprintf(`apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    app: %s
+    app.kubernetes.io/managed-by: homeport
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: %s
+  minReplicas: %d
+  maxReplicas: %d
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: %d
+`, name, namespace, name, name, minReplicas, maxReplicas, targetCPUUtilization)
+}
+
+// generatePDB generates a PodDisruptionBudget that lets voluntary
+// disruptions take down one replica at a time.
+func (g *Generator) generatePDB(name, namespace string) string {
+	return fmt.Sprintf(`apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    app: %s
+    app.kubernetes.io/managed-by: homeport
+spec:
+  maxUnavailable: 1
+  selector:
+    matchLabels:
+      app: %s
+`, name, namespace, name, name)
+}
+
+// writeResources writes the container resources block.
+func writeResources(buf *bytes.Buffer, res resourceRequirements) {
+	buf.WriteString("          resources:\n")
+	buf.WriteString("            requests:\n")
+	buf.WriteString(fmt.Sprintf("              memory: \"%s\"\n", res.RequestMemory))
+	buf.WriteString(fmt.Sprintf("              cpu: \"%s\"\n", res.RequestCPU))
+	buf.WriteString("            limits:\n")
+	buf.WriteString(fmt.Sprintf("              memory: \"%s\"\n", res.LimitMemory))
+	buf.WriteString(fmt.Sprintf("              cpu: \"%s\"\n", res.LimitCPU))
+}
+
+// mapperDeployReplicas returns the replica count of a mapper deploy config.
+func mapperDeployReplicas(deploy *mapper.DeployConfig) int {
+	if deploy == nil {
+		return 0
+	}
+	return deploy.Replicas
+}
+
+// stackDeployReplicas returns the replica count of a stack deploy config.
+func stackDeployReplicas(deploy *stack.DeployConfig) int {
+	if deploy == nil {
+		return 0
+	}
+	return deploy.Replicas
+}
+
+// mapperResources converts the resources of a mapper deploy config.
+func mapperResources(deploy *mapper.DeployConfig) resourceRequirements {
+	if deploy == nil || deploy.Resources == nil {
+		return defaultResources
+	}
+	var limits, reservations [2]string
+	if l := deploy.Resources.Limits; l != nil {
+		limits = [2]string{l.CPUs, l.Memory}
+	}
+	if r := deploy.Resources.Reservations; r != nil {
+		reservations = [2]string{r.CPUs, r.Memory}
+	}
+	return buildResources(limits, reservations)
+}
+
+// stackResources converts the resources of a stack deploy config.
+func stackResources(deploy *stack.DeployConfig) resourceRequirements {
+	if deploy == nil || deploy.Resources == nil {
+		return defaultResources
+	}
+	var limits, reservations [2]string
+	if l := deploy.Resources.Limits; l != nil {
+		limits = [2]string{l.CPUs, l.Memory}
+	}
+	if r := deploy.Resources.Reservations; r != nil {
+		reservations = [2]string{r.CPUs, r.Memory}
+	}
+	return buildResources(limits, reservations)
+}
+
+// buildResources turns compose-style {cpus, memory} limits and reservations
+// into Kubernetes quantities. A missing request falls back to the limit, and
+// a missing limit to the request.
+func buildResources(limits, reservations [2]string) resourceRequirements {
+	res := defaultResources
+	limitCPU, limitMemory := k8sCPU(limits[0]), k8sMemory(limits[1])
+	requestCPU, requestMemory := k8sCPU(reservations[0]), k8sMemory(reservations[1])
+
+	if requestCPU == "" {
+		requestCPU = limitCPU
+	}
+	if limitCPU == "" {
+		limitCPU = requestCPU
+	}
+	if requestMemory == "" {
+		requestMemory = limitMemory
+	}
+	if limitMemory == "" {
+		limitMemory = requestMemory
+	}
+
+	if requestCPU != "" {
+		res.RequestCPU, res.LimitCPU = requestCPU, limitCPU
+	}
+	if requestMemory != "" {
+		res.RequestMemory, res.LimitMemory = requestMemory, limitMemory
+	}
+	return res
+}
+
+// k8sCPU converts a compose CPU count such as "0.5" to a Kubernetes quantity
+// such as "500m". It returns "" when the value cannot be parsed.
+func k8sCPU(cpus string) string {
+	cpus = strings.TrimSpace(cpus)
+	if cpus == "" {
+		return ""
+	}
+	if strings.HasSuffix(cpus, "m") {
+		return cpus
+	}
+	value, err := strconv.ParseFloat(cpus, 64)
+	if err != nil || value <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dm", int(value*1000+0.5))
+}
+
+// k8sMemory converts a compose memory size such as "512M" or "2g" to a
+// Kubernetes quantity such as "512Mi" or "2Gi". It returns "" when the value
+// cannot be parsed.
+func k8sMemory(memory string) string {
+	memory = strings.TrimSpace(memory)
+	if memory == "" {
+		return ""
+	}
+	units := map[string]string{"k": "Ki", "m": "Mi", "g": "Gi", "t": "Ti"}
+
+	lower := strings.ToLower(memory)
+	lower = strings.TrimSuffix(lower, "ib")
+	lower = strings.TrimSuffix(lower, "b")
+	lower = strings.TrimSuffix(lower, "i")
+	if lower == "" {
+		return ""
+	}
+
+	suffix := lower[len(lower)-1:]
+	number := lower
+	unit := ""
+	if u, ok := units[suffix]; ok {
+		number, unit = lower[:len(lower)-1], u
+	}
+	if _, err := strconv.ParseFloat(number, 64); err != nil {
+		return ""
+	}
+	return number + unit
+}
+
+// probeCommand converts a Docker health check test into an exec probe
+// command. It returns nil for NONE or an empty test.
+func probeCommand(test []string) []string {
+	if len(test) == 0 {
+		return nil
+	}
+	switch test[0] {
+	case "NONE":
+		return nil
+	case "CMD":
+		return test[1:]
+	case "CMD-SHELL":
+		return []string{"sh", "-c", strings.Join(test[1:], " ")}
+	default:
+		if len(test) == 1 {
+			return []string{"sh", "-c", test[0]}
+		}
+		return test
+	}
+}
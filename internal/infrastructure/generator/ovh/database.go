@@ -1,6 +1,16 @@
 // Package ovh generates Terraform configurations for OVHcloud deployments.
 package ovh
 
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
 // OVH managed database engines
 const (
 	EnginePostgreSQL = "postgresql"
@@ -20,3 +30,245 @@ const (
 	PlanBusiness   = "business"   // HA, production
 	PlanEnterprise = "enterprise" // Advanced HA, large scale
 )
+
+// engineVersions holds the major version deployed for each engine.
+var engineVersions = map[string]string{
+	EnginePostgreSQL: "15",
+	EngineMySQL:      "8",
+	EngineMongoDB:    "6.0",
+	EngineRedis:      "7.2",
+	EngineKafka:      "3.6",
+	EngineCassandra:  "4.1",
+	EngineOpensearch: "2",
+}
+
+// DatabaseEngine returns the OVH engine for a database mapping result. The
+// source engine setting wins over the resource type, and PostgreSQL is used
+// when neither names a known engine.
+func DatabaseEngine(r *mapper.MappingResult) string {
+	source := strings.ToLower(r.SourceResourceType)
+	if r.SourceResource != nil {
+		source = strings.ToLower(ResourceProperties(r.SourceResource.Config).GetString("engine")) + " " + source
+	}
+
+	switch {
+	case strings.Contains(source, "mysql"), strings.Contains(source, "maria"):
+		return EngineMySQL
+	case strings.Contains(source, "postgres"):
+		return EnginePostgreSQL
+	case strings.Contains(source, "mongo"), strings.Contains(source, "docdb"), strings.Contains(source, "cosmos"):
+		return EngineMongoDB
+	case strings.Contains(source, "cassandra"), strings.Contains(source, "keyspaces"):
+		return EngineCassandra
+	case strings.Contains(source, "opensearch"), strings.Contains(source, "elasticsearch"):
+		return EngineOpensearch
+	case strings.Contains(source, "kafka"), strings.Contains(source, "msk"):
+		return EngineKafka
+	default:
+		return EnginePostgreSQL
+	}
+}
+
+// DatabasePlan returns the OVH database plan for an HA level. Single-server
+// levels get the essential plan, active-passive gets business and clustered
+// levels get enterprise.
+func DatabasePlan(level target.HALevel) string {
+	switch {
+	case level.RequiresCluster():
+		return PlanEnterprise
+	case level.RequiresMultiServer():
+		return PlanBusiness
+	default:
+		return PlanEssential
+	}
+}
+
+// DatabaseNodeCount returns the number of nodes a plan runs for an engine.
+// Business clusters of quorum-based engines need three nodes.
+func DatabaseNodeCount(engine, plan string) int {
+	switch plan {
+	case PlanEnterprise:
+		return 3
+	case PlanBusiness:
+		switch engine {
+		case EnginePostgreSQL, EngineMySQL, EngineRedis:
+			return 2
+		default:
+			return 3
+		}
+	default:
+		return 1
+	}
+}
+
+// DatabaseFlavor returns the node flavor for a plan.
+func DatabaseFlavor(plan string) string {
+	switch plan {
+	case PlanEnterprise:
+		return "db1-15"
+	case PlanBusiness:
+		return "db1-7"
+	default:
+		return "db1-4"
+	}
+}
+
+// databaseRegion converts an OpenStack region such as GRA11 into the
+// datacenter code managed databases are deployed in (GRA).
+func databaseRegion(region string) string {
+	return strings.ToUpper(strings.TrimRight(region, "0123456789"))
+}
+
+// GenerateDatabaseTF generates Terraform configuration for database resources.
+// Databases and caches become ovh_cloud_project_database clusters sized by
+// the HA level, reachable only from the generated instances.
+func GenerateDatabaseTF(databases, caches []*mapper.MappingResult, config *generator.TargetConfig, region string) string {
+	var buf bytes.Buffer
+	buf.WriteString("# Managed Databases (OVH Public Cloud Databases)\n\n")
+
+	plan := DatabasePlan(config.HALevel)
+	dbRegion := databaseRegion(region)
+	// Databases and caches share resource types, so they share one namespace.
+	names := newNameAllocator()
+
+	clients := "[openstack_compute_instance_v2.main.access_ip_v4]"
+	if New().getInstanceCount(config.HALevel) > 1 {
+		clients = "concat([openstack_compute_instance_v2.main.access_ip_v4], openstack_compute_instance_v2.workers[*].access_ip_v4)"
+	}
+	buf.WriteString(fmt.Sprintf(`locals {
+  database_clients = %s
+}
+
+`, clients))
+
+	writeCluster := func(r *mapper.MappingResult, name, engine string) {
+		nodes := DatabaseNodeCount(engine, plan)
+		buf.WriteString(fmt.Sprintf(`resource "ovh_cloud_project_database" "%s" {
+  service_name = var.ovh_project_id
+  description  = "${var.project_name}-%s"
+  engine       = "%s"
+  version      = "%s"
+  plan         = "%s"
+  flavor       = "%s"
+`, name, SanitizeName(name), engine, engineVersions[engine], plan, DatabaseFlavor(plan)))
+		if r.SourceResource != nil {
+			if size := ResourceProperties(r.SourceResource.Config).GetInt("allocated_storage"); size > 0 {
+				buf.WriteString(fmt.Sprintf("  disk_size    = %d\n", size))
+			}
+		}
+		for i := 0; i < nodes; i++ {
+			buf.WriteString(fmt.Sprintf(`
+  nodes {
+    region = "%s"
+  }
+`, dbRegion))
+		}
+		buf.WriteString("}\n\n")
+
+		buf.WriteString(fmt.Sprintf(`resource "ovh_cloud_project_database_ip_restriction" "%s" {
+  count        = length(local.database_clients)
+  service_name = var.ovh_project_id
+  engine       = ovh_cloud_project_database.%s.engine
+  cluster_id   = ovh_cloud_project_database.%s.id
+  ip           = "${local.database_clients[count.index]}/32"
+  description  = "${var.project_name} instance ${count.index}"
+}
+
+`, name, name, name))
+	}
+
+	if len(databases) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Databases\n")
+		buf.WriteString("# ============================================\n\n")
+
+		for i, r := range databases {
+			name, _ := names.allocate(r.SourceResourceName, fmt.Sprintf("db_%d", i))
+			engine := DatabaseEngine(r)
+
+			buf.WriteString(fmt.Sprintf("# Database: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			writeCluster(r, name, engine)
+
+			switch engine {
+			case EnginePostgreSQL, EngineMySQL:
+				buf.WriteString(fmt.Sprintf(`resource "ovh_cloud_project_database_database" "%s" {
+  service_name = var.ovh_project_id
+  engine       = ovh_cloud_project_database.%s.engine
+  cluster_id   = ovh_cloud_project_database.%s.id
+  name         = "%s"
+}
+
+`, name, name, name, name))
+			}
+
+			switch engine {
+			case EnginePostgreSQL:
+				buf.WriteString(fmt.Sprintf(`resource "ovh_cloud_project_database_postgresql_user" "%s" {
+  service_name = var.ovh_project_id
+  cluster_id   = ovh_cloud_project_database.%s.id
+  name         = "homeport"
+  roles        = ["replication"]
+}
+
+`, name, name))
+			case EngineMySQL:
+				buf.WriteString(fmt.Sprintf(`resource "ovh_cloud_project_database_user" "%s" {
+  service_name = var.ovh_project_id
+  engine       = ovh_cloud_project_database.%s.engine
+  cluster_id   = ovh_cloud_project_database.%s.id
+  name         = "homeport"
+}
+
+`, name, name, name))
+			case EngineMongoDB:
+				buf.WriteString(fmt.Sprintf(`resource "ovh_cloud_project_database_mongodb_user" "%s" {
+  service_name = var.ovh_project_id
+  cluster_id   = ovh_cloud_project_database.%s.id
+  name         = "homeport@admin"
+  roles        = ["readWriteAnyDatabase@admin"]
+}
+
+`, name, name))
+			}
+
+			buf.WriteString(fmt.Sprintf(`output "db_%s_host" {
+  description = "Endpoint of the %s database"
+  value       = ovh_cloud_project_database.%s.endpoints[0].domain
+}
+
+`, name, r.SourceResourceName, name))
+		}
+	}
+
+	if len(caches) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Caches (Redis)\n")
+		buf.WriteString("# ============================================\n\n")
+
+		for i, r := range caches {
+			name, _ := names.allocate(r.SourceResourceName, fmt.Sprintf("cache_%d", i))
+
+			buf.WriteString(fmt.Sprintf("# Cache: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			writeCluster(r, name, EngineRedis)
+
+			buf.WriteString(fmt.Sprintf(`resource "ovh_cloud_project_database_redis_user" "%s" {
+  service_name = var.ovh_project_id
+  cluster_id   = ovh_cloud_project_database.%s.id
+  name         = "homeport"
+  categories   = ["+@all"]
+  commands     = ["+@all"]
+  keys         = ["*"]
+  channels     = ["*"]
+}
+
+output "redis_%s_host" {
+  description = "Endpoint of the %s cache"
+  value       = ovh_cloud_project_database.%s.endpoints[0].domain
+}
+
+`, name, name, name, r.SourceResourceName, name))
+		}
+	}
+
+	return buf.String()
+}
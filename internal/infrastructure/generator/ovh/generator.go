@@ -89,8 +89,10 @@ func (g *Generator) Generate(ctx context.Context, results []*mapper.MappingResul
 		services = append(services, r.AdditionalServices...)
 	}
 
+	categorized := g.categorizeResources(results)
+
 	// Generate main.tf
-	mainTf := g.generateMain(region)
+	mainTf := g.generateMain(region, ovhProviderVersion(categorized))
 	output.AddTerraformFile("main.tf", []byte(mainTf))
 
 	// Generate variables.tf
@@ -105,6 +107,18 @@ func (g *Generator) Generate(ctx context.Context, results []*mapper.MappingResul
 	networkTf := g.generateNetworking(config, region)
 	output.AddTerraformFile("networking.tf", []byte(networkTf))
 
+	// Generate database.tf
+	if len(categorized.Databases) > 0 || len(categorized.Caches) > 0 {
+		dbTf := GenerateDatabaseTF(categorized.Databases, categorized.Caches, config, region)
+		output.AddTerraformFile("database.tf", []byte(dbTf))
+	}
+
+	// Generate storage.tf
+	if len(categorized.ObjectStorage) > 0 || len(categorized.BlockStorage) > 0 {
+		storageTf := GenerateStorageTF(categorized.ObjectStorage, categorized.BlockStorage, config, region)
+		output.AddTerraformFile("storage.tf", []byte(storageTf))
+	}
+
 	// Generate outputs.tf
 	outputsTf := g.generateOutputs()
 	output.AddTerraformFile("outputs.tf", []byte(outputsTf))
@@ -114,8 +128,12 @@ func (g *Generator) Generate(ctx context.Context, results []*mapper.MappingResul
 	output.AddTerraformFile("terraform.tfvars.example", []byte(tfvars))
 
 	output.MainFile = "main.tf"
-	output.Summary = fmt.Sprintf("Generated OVH Terraform with %d services", len(services))
+	output.Summary = fmt.Sprintf("Generated OVH Terraform with %d services, %d databases, %d caches, %d buckets and %d volumes",
+		len(services), len(categorized.Databases), len(categorized.Caches), len(categorized.ObjectStorage), len(categorized.BlockStorage))
 	output.AddManualStep("Configure OVH API credentials in environment or tfvars")
+	if len(categorized.Databases) > 0 || len(categorized.Caches) > 0 {
+		output.AddManualStep("Migrate data into the managed databases with the generated homeport users")
+	}
 	output.AddManualStep("terraform init && terraform plan && terraform apply")
 
 	return output, nil
@@ -360,7 +378,18 @@ func (g *Generator) getCacheCost(level target.HALevel) float64 {
 	}
 }
 
-func (g *Generator) generateMain(region string) string {
+// ovhProviderVersion returns the ovh/ovh provider constraint for the
+// generated configuration. ovh_cloud_project_storage only exists in the 1.x
+// provider, so outputs without buckets keep the 0.x constraint they always
+// had instead of forcing a major provider upgrade on existing stacks.
+func ovhProviderVersion(categorized *CategorizedResources) string {
+	if len(categorized.ObjectStorage) > 0 {
+		return "~> 1.1"
+	}
+	return "~> 0.36"
+}
+
+func (g *Generator) generateMain(region, providerVersion string) string {
 	return fmt.Sprintf(`# OVHcloud Terraform Configuration
 # Generated by Homeport - %s
 
@@ -369,7 +398,7 @@ terraform {
   required_providers {
     ovh = {
       source  = "ovh/ovh"
-      version = "~> 0.36"
+      version = "%s"
     }
     openstack = {
       source  = "terraform-provider-openstack/openstack"
@@ -392,7 +421,7 @@ provider "openstack" {
   tenant_name = var.os_tenant_name
   region      = "%s"
 }
-`, time.Now().Format(time.RFC3339), region)
+`, time.Now().Format(time.RFC3339), providerVersion, region)
 }
 
 func (g *Generator) generateVariables(config *generator.TargetConfig) string {
@@ -426,6 +455,11 @@ variable "ovh_consumer_key" {
   sensitive   = true
 }
 
+variable "ovh_project_id" {
+  description = "OVH Public Cloud project ID (service name)"
+  type        = string
+}
+
 variable "os_auth_url" {
   description = "OpenStack auth URL"
   type        = string
@@ -569,6 +603,7 @@ project_name = "%s"
 ovh_application_key    = ""
 ovh_application_secret = ""
 ovh_consumer_key       = ""
+ovh_project_id         = ""
 
 # OpenStack credentials (from OVH control panel)
 os_username    = ""
@@ -0,0 +1,166 @@
+package ovh
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
+func testResults() []*mapper.MappingResult {
+	volume := resource.NewAWSResource("vol-1", "data", "aws_ebs_volume")
+	volume.Config["size"] = 120
+	return []*mapper.MappingResult{
+		{SourceResourceName: "orders-db", SourceResourceType: "aws_db_instance", SourceCategory: resource.CategorySQLDatabase},
+		{SourceResourceName: "sessions", SourceResourceType: "aws_elasticache_cluster", SourceCategory: resource.CategoryCache},
+		{SourceResourceName: "assets", SourceResourceType: "aws_s3_bucket", SourceCategory: resource.CategoryObjectStorage},
+		{SourceResourceName: "data", SourceResourceType: "aws_ebs_volume", SourceCategory: resource.CategoryBlockStorage, SourceResource: volume},
+	}
+}
+
+func TestGenerateRendersManagedDataServices(t *testing.T) {
+	config := &generator.TargetConfig{ProjectName: "shop", HALevel: target.HALevelMultiServer}
+	output, err := New().Generate(context.Background(), testResults(), config)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	database := string(output.TerraformFiles["database.tf"])
+	for _, want := range []string{
+		`resource "ovh_cloud_project_database" "orders_db"`,
+		`engine       = "postgresql"`,
+		`plan         = "business"`,
+		`resource "ovh_cloud_project_database" "sessions"`,
+		`engine       = "redis"`,
+		`region = "GRA"`,
+	} {
+		if !strings.Contains(database, want) {
+			t.Errorf("database.tf missing %q", want)
+		}
+	}
+	if n := strings.Count(database, "nodes {"); n != 4 {
+		t.Errorf("database.tf has %d nodes blocks, want 2 per cluster", n)
+	}
+
+	storage := string(output.TerraformFiles["storage.tf"])
+	for _, want := range []string{
+		`resource "ovh_cloud_project_storage" "assets"`,
+		`resource "openstack_blockstorage_volume_v3" "data"`,
+		`size        = 120`,
+		`volume_type = "high-speed"`,
+		`resource "openstack_compute_volume_attach_v2" "data"`,
+	} {
+		if !strings.Contains(storage, want) {
+			t.Errorf("storage.tf missing %q", want)
+		}
+	}
+}
+
+func TestDatabasePlanFollowsHALevel(t *testing.T) {
+	tests := []struct {
+		level  target.HALevel
+		plan   string
+		nodes  int
+		volume string
+	}{
+		{target.HALevelNone, PlanEssential, 1, VolumeTypeClassic},
+		{target.HALevelBasic, PlanEssential, 1, VolumeTypeHighSpeed},
+		{target.HALevelMultiServer, PlanBusiness, 2, VolumeTypeHighSpeed},
+		{target.HALevelCluster, PlanEnterprise, 3, VolumeTypeHighSpeedGen2},
+	}
+	for _, tt := range tests {
+		plan := DatabasePlan(tt.level)
+		if plan != tt.plan {
+			t.Errorf("DatabasePlan(%s) = %s, want %s", tt.level, plan, tt.plan)
+		}
+		if nodes := DatabaseNodeCount(EnginePostgreSQL, plan); nodes != tt.nodes {
+			t.Errorf("DatabaseNodeCount(%s) = %d, want %d", plan, nodes, tt.nodes)
+		}
+		if volume := VolumeType(tt.level); volume != tt.volume {
+			t.Errorf("VolumeType(%s) = %s, want %s", tt.level, volume, tt.volume)
+		}
+	}
+}
+
+func TestDatabaseEngineUsesSourceEngine(t *testing.T) {
+	db := resource.NewAWSResource("db-1", "legacy", "aws_db_instance")
+	db.Config["engine"] = "mariadb"
+	tests := map[string]*mapper.MappingResult{
+		EngineMySQL:      {SourceResourceType: "aws_db_instance", SourceResource: db},
+		EngineMongoDB:    {SourceResourceType: "aws_docdb_cluster"},
+		EngineOpensearch: {SourceResourceType: "aws_opensearch_domain"},
+		EnginePostgreSQL: {SourceResourceType: "google_sql_database_instance"},
+	}
+	for want, r := range tests {
+		if got := DatabaseEngine(r); got != want {
+			t.Errorf("DatabaseEngine(%s) = %s, want %s", r.SourceResourceType, got, want)
+		}
+	}
+}
+
+func TestGenerateDeduplicatesResourceNames(t *testing.T) {
+	config := &generator.TargetConfig{ProjectName: "shop", HALevel: target.HALevelNone}
+	results := []*mapper.MappingResult{
+		{SourceResourceName: "my-db", SourceResourceType: "aws_db_instance", SourceCategory: resource.CategorySQLDatabase},
+		{SourceResourceName: "my_db", SourceResourceType: "aws_db_instance", SourceCategory: resource.CategorySQLDatabase},
+		{SourceResourceName: "app", SourceResourceType: "aws_db_instance", SourceCategory: resource.CategorySQLDatabase},
+		{SourceResourceName: "app", SourceResourceType: "aws_elasticache_cluster", SourceCategory: resource.CategoryCache},
+		{SourceResourceName: "assets", SourceResourceType: "aws_s3_bucket", SourceCategory: resource.CategoryObjectStorage},
+		{SourceResourceName: "Assets", SourceResourceType: "aws_s3_bucket", SourceCategory: resource.CategoryObjectStorage},
+	}
+	output, err := New().Generate(context.Background(), results, config)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	database := string(output.TerraformFiles["database.tf"])
+	for _, want := range []string{
+		`resource "ovh_cloud_project_database" "my_db"`,
+		`resource "ovh_cloud_project_database" "my_db_2"`,
+		`resource "ovh_cloud_project_database" "app"`,
+		`resource "ovh_cloud_project_database" "app_2"`,
+		`output "db_app_host"`,
+		`output "redis_app_2_host"`,
+	} {
+		if n := strings.Count(database, want+" {"); n != 1 {
+			t.Errorf("database.tf has %d occurrences of %q, want 1", n, want)
+		}
+	}
+
+	storage := string(output.TerraformFiles["storage.tf"])
+	for _, want := range []string{
+		`resource "ovh_cloud_project_storage" "assets"`,
+		`resource "ovh_cloud_project_storage" "assets_2"`,
+		`name         = "${var.project_name}-assets"`,
+		`name         = "${var.project_name}-assets-2"`,
+	} {
+		if !strings.Contains(storage, want) {
+			t.Errorf("storage.tf missing %q", want)
+		}
+	}
+}
+
+func TestGenerateOnlyRequiresOVHProvider1xForBuckets(t *testing.T) {
+	config := &generator.TargetConfig{ProjectName: "shop", HALevel: target.HALevelNone}
+	tests := []struct {
+		name    string
+		results []*mapper.MappingResult
+		want    string
+	}{
+		{"without buckets", testResults()[:2], `version = "~> 0.36"`},
+		{"with buckets", testResults(), `version = "~> 1.1"`},
+	}
+	for _, tt := range tests {
+		output, err := New().Generate(context.Background(), tt.results, config)
+		if err != nil {
+			t.Fatalf("%s: Generate() error = %v", tt.name, err)
+		}
+		if main := string(output.TerraformFiles["main.tf"]); !strings.Contains(main, tt.want) {
+			t.Errorf("%s: main.tf missing %q", tt.name, tt.want)
+		}
+	}
+}
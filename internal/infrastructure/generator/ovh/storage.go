@@ -1,6 +1,16 @@
 // Package ovh generates Terraform configurations for OVHcloud deployments.
 package ovh
 
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+	"github.com/homeport/homeport/internal/domain/mapper"
+	"github.com/homeport/homeport/internal/domain/target"
+)
+
 // OVH Object Storage classes (S3-compatible)
 const (
 	StorageClassStandard = "standard"  // High performance
@@ -10,7 +20,169 @@ const (
 
 // OVH Block Storage volume types
 const (
-	VolumeTypeClassic       = "classic"        // Standard HDD
-	VolumeTypeHighSpeed     = "high-speed"     // High-speed SSD
+	VolumeTypeClassic       = "classic"         // Standard HDD
+	VolumeTypeHighSpeed     = "high-speed"      // High-speed SSD
 	VolumeTypeHighSpeedGen2 = "high-speed-gen2" // Gen2 NVMe SSD
 )
+
+// ObjectStorageClass returns the OVH storage class matching the storage
+// class of a source bucket.
+func ObjectStorageClass(r *mapper.MappingResult) string {
+	source := strings.ToLower(r.SourceResourceType)
+	if r.SourceResource != nil {
+		source = strings.ToLower(ResourceProperties(r.SourceResource.Config).GetString("storage_class")) + " " + source
+	}
+
+	switch {
+	case strings.Contains(source, "glacier"), strings.Contains(source, "archive"), strings.Contains(source, "cold"):
+		return StorageClassCold
+	case strings.Contains(source, "express"), strings.Contains(source, "premium"):
+		return StorageClassHigh
+	default:
+		return StorageClassStandard
+	}
+}
+
+// VolumeType returns the block storage volume type for an HA level.
+func VolumeType(level target.HALevel) string {
+	switch {
+	case level.RequiresCluster():
+		return VolumeTypeHighSpeedGen2
+	case level.Level() >= 1:
+		return VolumeTypeHighSpeed
+	default:
+		return VolumeTypeClassic
+	}
+}
+
+// GenerateStorageTF generates Terraform configuration for storage resources.
+// Buckets become OVH S3 containers and block volumes become Cinder volumes
+// attached to the main instance.
+func GenerateStorageTF(objectStorage, blockStorage []*mapper.MappingResult, config *generator.TargetConfig, region string) string {
+	var buf bytes.Buffer
+	buf.WriteString("# Storage Resources\n\n")
+	names := newNameAllocator()
+
+	if len(objectStorage) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Object Storage (S3)\n")
+		buf.WriteString("# ============================================\n\n")
+
+		s3Region := strings.ToLower(databaseRegion(region))
+		for i, r := range objectStorage {
+			name, suffix := names.allocate(r.SourceResourceName, fmt.Sprintf("bucket_%d", i))
+			displayName := SanitizeName(r.SourceResourceName)
+			if displayName == "" {
+				displayName = fmt.Sprintf("bucket-%d", i)
+			}
+			displayName += strings.ReplaceAll(suffix, "_", "-")
+
+			versioning := "disabled"
+			if config.HALevel.Level() >= 1 {
+				versioning = "enabled"
+			}
+
+			buf.WriteString(fmt.Sprintf("# Bucket: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			buf.WriteString(fmt.Sprintf(`# Storage class: %s
+resource "ovh_cloud_project_storage" "%s" {
+  service_name = var.ovh_project_id
+  region_name  = "%s"
+  name         = "${var.project_name}-%s"
+
+  versioning = {
+    status = "%s"
+  }
+
+  encryption = {
+    sse_algorithm = "AES256"
+  }
+}
+
+output "bucket_%s_name" {
+  description = "Name of the %s bucket"
+  value       = ovh_cloud_project_storage.%s.name
+}
+
+`, ObjectStorageClass(r), name, strings.ToUpper(s3Region), displayName, versioning, name, r.SourceResourceName, name))
+		}
+
+		buf.WriteString(fmt.Sprintf(`# S3 credentials for the buckets
+resource "ovh_cloud_project_user" "storage" {
+  service_name = var.ovh_project_id
+  description  = "${var.project_name} object storage"
+  role_name    = "objectstore_operator"
+}
+
+resource "ovh_cloud_project_user_s3_credential" "storage" {
+  service_name = var.ovh_project_id
+  user_id      = ovh_cloud_project_user.storage.id
+}
+
+output "storage_endpoint" {
+  description = "S3 endpoint for Object Storage"
+  value       = "https://s3.%s.io.cloud.ovh.net"
+}
+
+output "storage_access_key_id" {
+  description = "Access key ID for Object Storage"
+  value       = ovh_cloud_project_user_s3_credential.storage.access_key_id
+  sensitive   = true
+}
+
+output "storage_secret_key" {
+  description = "Secret key for Object Storage"
+  value       = ovh_cloud_project_user_s3_credential.storage.secret_access_key
+  sensitive   = true
+}
+
+`, s3Region))
+	}
+
+	if len(blockStorage) > 0 {
+		buf.WriteString("# ============================================\n")
+		buf.WriteString("# Block Storage Volumes (Cinder)\n")
+		buf.WriteString("# ============================================\n\n")
+
+		volumeType := VolumeType(config.HALevel)
+		for i, r := range blockStorage {
+			name, suffix := names.allocate(r.SourceResourceName, fmt.Sprintf("volume_%d", i))
+			displayName := SanitizeName(r.SourceResourceName)
+			if displayName == "" {
+				displayName = fmt.Sprintf("volume-%d", i)
+			}
+			displayName += strings.ReplaceAll(suffix, "_", "-")
+
+			// Determine volume size from source (default 50GB)
+			volumeSize := 50
+			if r.SourceResource != nil {
+				props := ResourceProperties(r.SourceResource.Config)
+				for _, key := range []string{"size_gb", "size"} {
+					if size := props.GetInt(key); size > 0 {
+						volumeSize = size
+						break
+					}
+				}
+			}
+
+			buf.WriteString(fmt.Sprintf("# Block Volume: %s (from %s)\n", r.SourceResourceName, r.SourceResourceType))
+			buf.WriteString(fmt.Sprintf(`resource "openstack_blockstorage_volume_v3" "%s" {
+  name        = "${var.project_name}-%s"
+  size        = %d
+  volume_type = "%s"
+
+  metadata = {
+    managed_by = "homeport"
+  }
+}
+
+resource "openstack_compute_volume_attach_v2" "%s" {
+  instance_id = openstack_compute_instance_v2.main.id
+  volume_id   = openstack_blockstorage_volume_v3.%s.id
+}
+
+`, name, displayName, volumeSize, volumeType, name, name))
+		}
+	}
+
+	return buf.String()
+}
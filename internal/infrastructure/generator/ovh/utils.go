@@ -1,6 +1,11 @@
 // Package ovh provides utility functions for OVH Cloud generator.
 package ovh
 
+import (
+	"fmt"
+	"strings"
+)
+
 // ResourceProperties provides a wrapper to access resource properties.
 type ResourceProperties map[string]interface{}
 
@@ -32,3 +37,64 @@ func (p ResourceProperties) GetBool(key string) bool {
 	}
 	return false
 }
+
+// SanitizeName converts a name to a valid OVH resource name.
+func SanitizeName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, " ", "-")
+	name = strings.ReplaceAll(name, "_", "-")
+	var result strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			result.WriteRune(r)
+		}
+	}
+	return strings.Trim(result.String(), "-")
+}
+
+// SanitizeTFName converts a name to a valid Terraform resource name.
+func SanitizeTFName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, " ", "_")
+	name = strings.ReplaceAll(name, "-", "_")
+	var result strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			result.WriteRune(r)
+		}
+	}
+	clean := strings.Trim(result.String(), "_")
+	// Ensure starts with letter
+	if len(clean) > 0 && clean[0] >= '0' && clean[0] <= '9' {
+		clean = "r_" + clean
+	}
+	return clean
+}
+
+// nameAllocator hands out Terraform names that are unique within one
+// generated file. Sources that sanitize to the same name, such as "my-db" and
+// "my_db", get a numeric suffix.
+type nameAllocator struct {
+	used map[string]bool
+}
+
+func newNameAllocator() *nameAllocator {
+	return &nameAllocator{used: make(map[string]bool)}
+}
+
+// allocate returns a unique Terraform name for source, using fallback when
+// source has no usable characters. The second result is the suffix appended
+// to make the name unique, or "" if none was needed.
+func (a *nameAllocator) allocate(source, fallback string) (string, string) {
+	base := SanitizeTFName(source)
+	if base == "" {
+		base = fallback
+	}
+	name, suffix := base, ""
+	for n := 2; a.used[name]; n++ {
+		suffix = fmt.Sprintf("_%d", n)
+		name = base + suffix
+	}
+	a.used[name] = true
+	return name, suffix
+}
@@ -0,0 +1,84 @@
+package traefik
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// WeightedRoutePriority is the priority of weighted routes, high enough to
+// take over the routers Docker labels create for the same host.
+const WeightedRoutePriority = 10000
+
+// WeightedRoute splits the traffic of a host between a self-hosted Traefik
+// service and the old cloud endpoint, for progressive cutovers.
+type WeightedRoute struct {
+	Name      string // Router name, also the prefix of the generated services
+	Host      string // Host the router matches
+	Service   string // Self-hosted Traefik service (e.g., "app@docker")
+	LegacyURL string // Old cloud endpoint the rest of the traffic goes to
+	Percent   int    // Share of traffic sent to Service, from 0 to 100
+}
+
+// LegacyService returns the name of the generated service that proxies to
+// the old cloud endpoint.
+func (r *WeightedRoute) LegacyService() string {
+	return r.Name + "-legacy"
+}
+
+// FileName returns the file name of the route in the dynamic configuration
+// directory.
+func (r *WeightedRoute) FileName() string {
+	return r.Name + ".yml"
+}
+
+// GenerateWeightedRoute generates a dynamic configuration with a router for
+// the route's host and a weighted round robin service that sends Percent of
+// the requests to the self-hosted service and the rest to the old endpoint.
+func GenerateWeightedRoute(route *WeightedRoute) (string, error) {
+	if route.Name == "" || route.Host == "" || route.Service == "" || route.LegacyURL == "" {
+		return "", fmt.Errorf("weighted route requires name, host, service and legacy URL")
+	}
+	if route.Percent < 0 || route.Percent > 100 {
+		return "", fmt.Errorf("weighted route percent must be between 0 and 100, got %d", route.Percent)
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("# Traefik Dynamic Configuration - Weighted Cutover Route\n")
+	buf.WriteString(fmt.Sprintf("# Generated by Homeport - %s\n", time.Now().Format(time.RFC3339)))
+	buf.WriteString(fmt.Sprintf("# %d%% to %s, %d%% to %s\n\n", route.Percent, route.Service, 100-route.Percent, route.LegacyURL))
+
+	buf.WriteString("http:\n")
+	buf.WriteString("  routers:\n")
+	buf.WriteString(fmt.Sprintf("    %s:\n", route.Name))
+	buf.WriteString("      rule: \"Host(`" + route.Host + "`)\"\n")
+	buf.WriteString(fmt.Sprintf("      service: %s\n", route.Name))
+	buf.WriteString(fmt.Sprintf("      priority: %d\n", WeightedRoutePriority))
+	buf.WriteString("      entryPoints:\n")
+	buf.WriteString("        - websecure\n")
+	buf.WriteString("      tls:\n")
+	buf.WriteString("        certResolver: letsencrypt\n\n")
+
+	buf.WriteString("  services:\n")
+	buf.WriteString(fmt.Sprintf("    %s:\n", route.Name))
+	buf.WriteString("      weighted:\n")
+	buf.WriteString("        services:\n")
+	// Traefik rejects zero weights, so an empty side is left out
+	if route.Percent > 0 {
+		buf.WriteString(fmt.Sprintf("          - name: %s\n", route.Service))
+		buf.WriteString(fmt.Sprintf("            weight: %d\n", route.Percent))
+	}
+	if route.Percent < 100 {
+		buf.WriteString(fmt.Sprintf("          - name: %s\n", route.LegacyService()))
+		buf.WriteString(fmt.Sprintf("            weight: %d\n", 100-route.Percent))
+	}
+	buf.WriteString("\n")
+
+	buf.WriteString(fmt.Sprintf("    %s:\n", route.LegacyService()))
+	buf.WriteString("      loadBalancer:\n")
+	buf.WriteString("        servers:\n")
+	buf.WriteString(fmt.Sprintf("          - url: %q\n", route.LegacyURL))
+
+	return buf.String(), nil
+}
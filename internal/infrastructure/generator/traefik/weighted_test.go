@@ -0,0 +1,69 @@
+package traefik
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateWeightedRoute(t *testing.T) {
+	route := &WeightedRoute{
+		Name:      "homeport-shift-www",
+		Host:      "www.example.com",
+		Service:   "app@docker",
+		LegacyURL: "https://legacy.example.net",
+		Percent:   25,
+	}
+
+	content, err := GenerateWeightedRoute(route)
+	if err != nil {
+		t.Fatalf("GenerateWeightedRoute() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"rule: \"Host(`www.example.com`)\"",
+		"service: homeport-shift-www\n",
+		"- name: app@docker\n            weight: 25\n",
+		"- name: homeport-shift-www-legacy\n            weight: 75\n",
+		"homeport-shift-www-legacy:\n      loadBalancer:",
+		"- url: \"https://legacy.example.net\"",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestGenerateWeightedRouteOmitsZeroWeights(t *testing.T) {
+	route := &WeightedRoute{
+		Name:      "homeport-shift-www",
+		Host:      "www.example.com",
+		Service:   "app@docker",
+		LegacyURL: "https://legacy.example.net",
+	}
+
+	content, err := GenerateWeightedRoute(route)
+	if err != nil {
+		t.Fatalf("GenerateWeightedRoute() error = %v", err)
+	}
+	if strings.Contains(content, "- name: app@docker") {
+		t.Errorf("0%% route should not send traffic to the new service:\n%s", content)
+	}
+
+	route.Percent = 100
+	content, err = GenerateWeightedRoute(route)
+	if err != nil {
+		t.Fatalf("GenerateWeightedRoute() error = %v", err)
+	}
+	if strings.Contains(content, "- name: homeport-shift-www-legacy") {
+		t.Errorf("100%% route should not send traffic to the legacy service:\n%s", content)
+	}
+}
+
+func TestGenerateWeightedRouteValidation(t *testing.T) {
+	if _, err := GenerateWeightedRoute(&WeightedRoute{Name: "r", Host: "h", Service: "s"}); err == nil {
+		t.Error("expected an error without a legacy URL")
+	}
+	if _, err := GenerateWeightedRoute(&WeightedRoute{Name: "r", Host: "h", Service: "s", LegacyURL: "u", Percent: 101}); err == nil {
+		t.Error("expected an error for a percent above 100")
+	}
+}
@@ -0,0 +1,128 @@
+package writer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// TarGzWriter writes files as a reproducible gzip-compressed tar archive.
+type TarGzWriter struct {
+	w io.Writer
+}
+
+// NewTarGzWriter creates a tar.gz writer that writes to w.
+func NewTarGzWriter(w io.Writer) *TarGzWriter {
+	return &TarGzWriter{w: w}
+}
+
+// Write writes files as a single archive. The gzip header carries no name
+// or timestamp, and tar entries carry no owner, so only the file names and
+// contents affect the output.
+func (t *TarGzWriter) Write(files map[string][]byte) error {
+	names, err := sortedNames(files)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(t.w)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		content := files[name]
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     entryName(name),
+			Mode:     int64(fileMode(name)),
+			Size:     int64(len(content)),
+			ModTime:  ModTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish gzip stream: %w", err)
+	}
+	return nil
+}
+
+// ZipWriter writes files as a reproducible zip archive.
+type ZipWriter struct {
+	w io.Writer
+}
+
+// NewZipWriter creates a zip writer that writes to w.
+func NewZipWriter(w io.Writer) *ZipWriter {
+	return &ZipWriter{w: w}
+}
+
+// Write writes files as a single archive.
+func (z *ZipWriter) Write(files map[string][]byte) error {
+	names, err := sortedNames(files)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(z.w)
+	for _, name := range names {
+		header := &zip.FileHeader{
+			Name:     entryName(name),
+			Method:   zip.Deflate,
+			Modified: ModTime,
+		}
+		header.SetMode(fileMode(name))
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip archive: %w", err)
+	}
+	return nil
+}
+
+// StreamWriter writes every file to a single stream in path order, each
+// preceded by a "---" separator and a "# Source: <path>" line, the layout
+// `helm template` uses. Multi-document YAML tools such as `kubectl apply -f -`
+// accept the result as is.
+type StreamWriter struct {
+	w io.Writer
+}
+
+// NewStreamWriter creates a stream writer that writes to w.
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{w: w}
+}
+
+// Write writes files to the stream.
+func (s *StreamWriter) Write(files map[string][]byte) error {
+	names, err := sortedNames(files)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		content := files[name]
+		if _, err := fmt.Fprintf(s.w, "---\n# Source: %s\n", entryName(name)); err != nil {
+			return err
+		}
+		if _, err := s.w.Write(content); err != nil {
+			return err
+		}
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			if _, err := io.WriteString(s.w, "\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package writer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestName is the file a DirWriter records the files it wrote in. Its
+// presence marks a directory as generated by homeport.
+const ManifestName = ".homeport-output.json"
+
+// ErrForeignOutput is returned when the output directory holds files that a
+// previous run did not generate, or that were edited since.
+var ErrForeignOutput = errors.New("output directory contains files homeport did not generate")
+
+// manifest lists the files of a generated directory with their SHA-256.
+type manifest struct {
+	Files map[string]string `json:"files"`
+}
+
+// DirWriter writes files into a directory. The files are written to a
+// temporary sibling directory first, which then replaces Dir, so readers
+// never see a half-written output.
+//
+// Dir is only replaced when it does not exist, is empty, or still holds
+// exactly the files an earlier DirWriter wrote. Anything else, such as
+// hand-edited or unrelated files, makes Write fail with ErrForeignOutput
+// unless Force is set.
+type DirWriter struct {
+	Dir   string
+	Force bool
+}
+
+// NewDirWriter creates a writer for the directory dir.
+func NewDirWriter(dir string) *DirWriter {
+	return &DirWriter{Dir: dir}
+}
+
+// Write replaces the contents of the directory with files.
+func (d *DirWriter) Write(files map[string][]byte) error {
+	names, err := sortedNames(files)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Clean(d.Dir)
+	parent, base := filepath.Dir(dir), filepath.Base(dir)
+
+	hadPrevious := false
+	if info, err := os.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("output path %s exists and is not a directory", dir)
+		}
+		if !d.Force {
+			if err := checkGenerated(dir); err != nil {
+				return err
+			}
+		}
+		hadPrevious = true
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(parent, "."+base+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+
+	m := manifest{Files: make(map[string]string, len(names))}
+	for _, name := range names {
+		entry := entryName(name)
+		if entry == ManifestName {
+			return fmt.Errorf("output file path %q is reserved", name)
+		}
+		fullPath := filepath.Join(tmp, filepath.FromSlash(entry))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(fullPath, files[name], fileMode(name)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		m.Files[entry] = fileHash(files[name])
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ManifestName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write output manifest: %w", err)
+	}
+
+	// Move the previous output aside, swap the new one in and only then
+	// remove the old one, restoring it if the swap fails.
+	old := tmp + ".old"
+	if hadPrevious {
+		if err := os.Rename(dir, old); err != nil {
+			return fmt.Errorf("failed to move previous output aside: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		if hadPrevious {
+			_ = os.Rename(old, dir)
+		}
+		return fmt.Errorf("failed to replace %s: %w", dir, err)
+	}
+	if hadPrevious {
+		if err := os.RemoveAll(old); err != nil {
+			return fmt.Errorf("failed to remove previous output: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkGenerated returns ErrForeignOutput unless dir is empty or holds
+// exactly the files listed, unchanged, in its manifest.
+func checkGenerated(dir string) error {
+	var m manifest
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("%w: %s has an unreadable %s", ErrForeignOutput, dir, ManifestName)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	var foreign []string
+	err = filepath.WalkDir(dir, func(p string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if name == ManifestName {
+			return nil
+		}
+		want, listed := m.Files[name]
+		if !listed || !entry.Type().IsRegular() {
+			foreign = append(foreign, name)
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if fileHash(content) != want {
+			foreign = append(foreign, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", dir, err)
+	}
+	if len(foreign) == 0 {
+		return nil
+	}
+
+	sort.Strings(foreign)
+	if len(foreign) > 5 {
+		foreign = append(foreign[:5], fmt.Sprintf("and %d more", len(foreign)-5))
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrForeignOutput, dir, strings.Join(foreign, ", "))
+}
+
+// fileHash returns the hex SHA-256 of content.
+func fileHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,236 @@
+// Package writer writes generated artifacts to a directory, a deterministic
+// tar.gz or zip archive, or a plain stream.
+//
+// Archives are reproducible: entries are sorted by path and every entry
+// carries the same modification time, so generating twice from the same
+// input yields byte-identical output that diffs cleanly.
+package writer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+)
+
+// Writer writes a set of generated files, keyed by slash-separated
+// relative path.
+type Writer interface {
+	Write(files map[string][]byte) error
+}
+
+// Format selects how generated files are written.
+type Format string
+
+const (
+	// FormatDir writes files into a directory.
+	FormatDir Format = "dir"
+
+	// FormatTarGz writes a gzip-compressed tar archive.
+	FormatTarGz Format = "tar.gz"
+
+	// FormatZip writes a zip archive.
+	FormatZip Format = "zip"
+
+	// FormatStream writes every file to a single stream, each preceded by a
+	// "# Source:" header.
+	FormatStream Format = "stream"
+)
+
+// Stdout is the destination that selects standard output.
+const Stdout = "-"
+
+// ModTime is the modification time of every archive entry. It is the
+// earliest time a zip archive can represent.
+var ModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Formats returns the supported formats.
+func Formats() []Format {
+	return []Format{FormatDir, FormatTarGz, FormatZip, FormatStream}
+}
+
+// ParseFormat parses a format name. "tgz" is accepted for FormatTarGz.
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(name)) {
+	case FormatDir, "":
+		return FormatDir, nil
+	case FormatTarGz, "tgz":
+		return FormatTarGz, nil
+	case FormatZip:
+		return FormatZip, nil
+	case FormatStream:
+		return FormatStream, nil
+	}
+	return "", fmt.Errorf("unknown output format %q (want dir, tar.gz, zip or stream)", name)
+}
+
+// New returns a writer for format that writes to dest. Archives and streams
+// go to stdout when dest is Stdout, and otherwise replace the file at dest
+// atomically. Directories cannot be written to stdout.
+func New(format Format, dest string, stdout io.Writer) (Writer, error) {
+	if dest == "" {
+		return nil, fmt.Errorf("output destination is required")
+	}
+	if format == FormatDir {
+		if dest == Stdout {
+			return nil, fmt.Errorf("directory output cannot be written to stdout, use tar.gz, zip or stream")
+		}
+		return NewDirWriter(dest), nil
+	}
+	if dest == Stdout {
+		return streamFor(format, stdout)
+	}
+	if _, err := streamFor(format, io.Discard); err != nil {
+		return nil, err
+	}
+	return &fileWriter{format: format, path: dest}, nil
+}
+
+func streamFor(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatTarGz:
+		return NewTarGzWriter(w), nil
+	case FormatZip:
+		return NewZipWriter(w), nil
+	case FormatStream:
+		return NewStreamWriter(w), nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+// fileWriter writes an archive or stream to a temporary file next to path
+// and renames it into place once complete.
+type fileWriter struct {
+	format Format
+	path   string
+}
+
+func (f *fileWriter) Write(files map[string][]byte) error {
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(f.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	w, err := streamFor(f.format, tmp)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := w.Write(files); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// ReadDir collects every regular file under dir, keyed by slash-separated
+// path relative to dir. It is the inverse of a DirWriter and skips the
+// manifest one writes.
+func ReadDir(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == ManifestName {
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// sortedNames validates and sorts the file names of files. Names must be
+// relative and stay inside the output.
+func sortedNames(files map[string][]byte) ([]string, error) {
+	names := make([]string, 0, len(files))
+	seen := make(map[string]string, len(files))
+	for name := range files {
+		clean := entryName(name)
+		if name == "" || path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+			return nil, fmt.Errorf("invalid output file path %q", name)
+		}
+		if other, ok := seen[clean]; ok {
+			return nil, fmt.Errorf("output file paths %q and %q name the same file", other, name)
+		}
+		seen[clean] = name
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// entryName returns the archive name of a validated file name.
+func entryName(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
+
+// fileMode returns the permissions a generated file is written with:
+// shell scripts are executable.
+func fileMode(name string) os.FileMode {
+	if strings.HasSuffix(name, ".sh") {
+		return 0755
+	}
+	return 0644
+}
+
+// outputWriter adapts this package to generator.OutputWriter.
+type outputWriter struct{}
+
+func (outputWriter) WriteFiles(basePath string, files map[string][]byte) error {
+	return NewDirWriter(basePath).Write(files)
+}
+
+func (outputWriter) WriteTo(w io.Writer, files map[string][]byte) (int64, error) {
+	counter := &countingWriter{w: w}
+	err := NewStreamWriter(counter).Write(files)
+	return counter.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func init() {
+	generator.RegisterOutputWriter(outputWriter{})
+}
@@ -0,0 +1,228 @@
+package writer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/generator"
+)
+
+func testFiles() map[string][]byte {
+	return map[string][]byte{
+		"docker-compose.yml":      []byte("services: {}\n"),
+		"scripts/migrate.sh":      []byte("#!/bin/sh\necho migrate\n"),
+		"configs/traefik/dyn.yml": []byte("http: {}"),
+	}
+}
+
+func TestDirWriterReplacesPreviousOutput(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := NewDirWriter(dir).Write(map[string][]byte{"stale.txt": []byte("old")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewDirWriter(dir).Write(testFiles()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got, err := ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, testFiles()) {
+		t.Fatalf("ReadDir() = %v, want exactly the written files", got)
+	}
+	info, err := os.Stat(filepath.Join(dir, "scripts", "migrate.sh"))
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Fatalf("migrate.sh mode = %v, %v; want 0755", info.Mode(), err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dir))
+	if len(entries) != 1 {
+		t.Fatalf("parent entries = %v, want no leftover temporary directories", entries)
+	}
+}
+
+func TestDirWriterRefusesToReplaceForeignFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewDirWriter(dir).Write(testFiles())
+	if !errors.Is(err, ErrForeignOutput) || !strings.Contains(err.Error(), "notes.md") {
+		t.Fatalf("Write() error = %v, want ErrForeignOutput naming notes.md", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "notes.md")); err != nil || string(content) != "mine" {
+		t.Fatalf("notes.md = %q, %v; want it untouched", content, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dir))
+	if len(entries) != 1 {
+		t.Fatalf("parent entries = %v, want no leftover temporary directories", entries)
+	}
+
+	if err := (&DirWriter{Dir: dir, Force: true}).Write(testFiles()); err != nil {
+		t.Fatalf("Write(Force) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.md")); !os.IsNotExist(err) {
+		t.Fatalf("notes.md after forced write: %v, want it replaced", err)
+	}
+}
+
+func TestDirWriterRefusesToReplaceEditedOutput(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := NewDirWriter(dir).Write(testFiles()); err != nil {
+		t.Fatal(err)
+	}
+	edited := filepath.Join(dir, "docker-compose.yml")
+	if err := os.WriteFile(edited, []byte("services: {web: {}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewDirWriter(dir).Write(testFiles()); !errors.Is(err, ErrForeignOutput) {
+		t.Fatalf("Write() error = %v, want ErrForeignOutput for the edited file", err)
+	}
+	if content, _ := os.ReadFile(edited); string(content) != "services: {web: {}}\n" {
+		t.Fatalf("docker-compose.yml = %q, want the edit kept", content)
+	}
+}
+
+func TestDirWriterWritesIntoEmptyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewDirWriter(dir).Write(testFiles()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ManifestName)); err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+}
+
+func TestDirWriterKeepsPreviousOutputOnInvalidPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := NewDirWriter(dir).Write(map[string][]byte{"keep.txt": []byte("v1")}); err != nil {
+		t.Fatal(err)
+	}
+	err := NewDirWriter(dir).Write(map[string][]byte{"../escape.txt": []byte("x")})
+	if err == nil {
+		t.Fatal("Write(../escape.txt) error = nil, want invalid path")
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "keep.txt")); err != nil || string(content) != "v1" {
+		t.Fatalf("keep.txt = %q, %v; want previous output untouched", content, err)
+	}
+}
+
+func TestArchiveWritersAreReproducible(t *testing.T) {
+	for _, format := range []Format{FormatTarGz, FormatZip, FormatStream} {
+		var first, second bytes.Buffer
+		for _, buf := range []*bytes.Buffer{&first, &second} {
+			w, err := New(format, Stdout, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Write(testFiles()); err != nil {
+				t.Fatalf("%s Write() error = %v", format, err)
+			}
+		}
+		if !bytes.Equal(first.Bytes(), second.Bytes()) {
+			t.Fatalf("%s output differs between runs", format)
+		}
+	}
+}
+
+func TestTarGzWriterOrdersEntriesWithFixedMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewTarGzWriter(&buf).Write(testFiles()); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gz.ModTime.IsZero() || gz.Name != "" {
+		t.Fatalf("gzip header = %+v, want no name or timestamp", gz.Header)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !header.ModTime.Equal(ModTime) || header.Uid != 0 || header.Uname != "" {
+			t.Fatalf("header %s = %+v, want fixed time and no owner", header.Name, header)
+		}
+		names = append(names, header.Name)
+	}
+	want := []string{"configs/traefik/dyn.yml", "docker-compose.yml", "scripts/migrate.sh"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("entries = %v, want %v", names, want)
+	}
+}
+
+func TestFileWriterWritesZipAtomically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deploy", "stack.zip")
+	w, err := New(FormatZip, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(testFiles()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	if len(zr.File) != 3 || zr.File[2].Name != "scripts/migrate.sh" || zr.File[2].Mode().Perm() != 0755 {
+		t.Fatalf("zip entries = %v, want sorted entries with executable script", zr.File)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("output directory = %v, want only stack.zip", entries)
+	}
+}
+
+func TestNewRejectsDirectoryOnStdout(t *testing.T) {
+	if _, err := New(FormatDir, Stdout, io.Discard); err == nil {
+		t.Fatal("New(dir, -) error = nil, want error")
+	}
+	if _, err := ParseFormat("rar"); err == nil {
+		t.Fatal("ParseFormat(rar) error = nil, want error")
+	}
+}
+
+func TestOutputWriteToStreamsFilesInOrder(t *testing.T) {
+	output := generator.NewOutput()
+	output.AddFileString("b.yml", "b: 1")
+	output.AddFileString("a.yml", "a: 1\n")
+	var buf bytes.Buffer
+	n, err := output.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	want := "---\n# Source: a.yml\na: 1\n---\n# Source: b.yml\nb: 1\n"
+	if buf.String() != want || n != int64(len(want)) {
+		t.Fatalf("WriteTo() = %d %q, want %q", n, buf.String(), want)
+	}
+
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := output.WriteFiles(dir); err != nil {
+		t.Fatalf("WriteFiles() error = %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "b.yml")); err != nil || !strings.HasPrefix(string(content), "b: 1") {
+		t.Fatalf("b.yml = %q, %v", content, err)
+	}
+}
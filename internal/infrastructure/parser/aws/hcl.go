@@ -5,14 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
-	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclparse"
-	"github.com/zclconf/go-cty/cty"
-
 	"github.com/homeport/homeport/internal/domain/parser"
 	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/infrastructure/parser/tfconfig"
 )
 
 // HCLParser parses Terraform HCL (.tf) files for AWS resources.
@@ -132,29 +130,29 @@ func (p *HCLParser) Parse(ctx context.Context, path string, opts *parser.ParseOp
 	}
 
 	infra := resource.NewInfrastructure(resource.ProviderAWS)
+	evalOpts := tfconfig.FromParseOptions(opts)
 
+	var mods []*tfconfig.Module
 	if info.IsDir() {
-		err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
-			if err != nil || info.IsDir() {
-				return nil
-			}
-			if strings.HasSuffix(strings.ToLower(filePath), ".tf") {
-				if parseErr := p.parseHCLFile(filePath, infra, opts); parseErr != nil {
-					if opts.IgnoreErrors {
-						return nil
-					}
-					return parseErr
-				}
-			}
-			return nil
-		})
+		mods, err = tfconfig.LoadTree(path, evalOpts, opts.IgnoreErrors)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		if err := p.parseHCLFile(path, infra, opts); err != nil {
+		mod, err := tfconfig.LoadFiles([]string{path}, evalOpts)
+		if err != nil {
 			return nil, err
 		}
+		mods = append(mods, mod)
+	}
+
+	modulesFollowed := 0
+	for _, mod := range mods {
+		p.addModuleResources(mod, infra, opts)
+		modulesFollowed += mod.ModulesFollowed()
+	}
+	if modulesFollowed > 0 {
+		infra.Metadata[parser.MetadataModulesFollowed] = strconv.Itoa(modulesFollowed)
 	}
 
 	return infra, nil
@@ -173,93 +171,82 @@ func (p *HCLParser) hasAWSConfig(path string) bool {
 		strings.Contains(content, `data "aws_`)
 }
 
-// parseHCLFile parses a single HCL file.
-func (p *HCLParser) parseHCLFile(path string, infra *resource.Infrastructure, opts *parser.ParseOptions) error {
-	hclParser := hclparse.NewParser()
-	file, diags := hclParser.ParseHCLFile(path)
-	if diags.HasErrors() {
-		return fmt.Errorf("failed to parse HCL file: %s", diags.Error())
-	}
-
-	body := file.Body
-	content, _, diags := body.PartialContent(&hcl.BodySchema{
-		Blocks: []hcl.BlockHeaderSchema{
-			{Type: "resource", LabelNames: []string{"type", "name"}},
-			{Type: "data", LabelNames: []string{"type", "name"}},
-			{Type: "variable", LabelNames: []string{"name"}},
-			{Type: "output", LabelNames: []string{"name"}},
-			{Type: "locals"},
-		},
-	})
-	if diags.HasErrors() {
-		return fmt.Errorf("failed to decode HCL content: %s", diags.Error())
-	}
+// addModuleResources adds the AWS resources of an evaluated module to infra.
+func (p *HCLParser) addModuleResources(mod *tfconfig.Module, infra *resource.Infrastructure, opts *parser.ParseOptions) {
+	var added []*resource.Resource
+	for _, inst := range mod.Resources {
+		if inst.Mode != tfconfig.ModeManaged || !strings.HasPrefix(inst.Type, "aws_") {
+			continue
+		}
 
-	for _, block := range content.Blocks {
-		if block.Type == "resource" && len(block.Labels) >= 2 {
-			resourceType := block.Labels[0]
-			resourceName := block.Labels[1]
+		res := p.parseResourceInstance(inst)
 
-			if !strings.HasPrefix(resourceType, "aws_") {
-				continue
-			}
+		if !p.shouldIncludeResource(res, opts) {
+			continue
+		}
 
-			res := p.parseResourceBlock(resourceType, resourceName, block)
+		infra.AddResource(res)
+		added = append(added, res)
+	}
 
-			if !p.shouldIncludeResource(res, opts) {
-				continue
+	// Drop edges to resources that were filtered out or are not ours.
+	for _, res := range added {
+		deps := make([]string, 0, len(res.Dependencies))
+		for _, dep := range res.Dependencies {
+			if _, ok := infra.Resources[dep]; ok {
+				deps = append(deps, dep)
 			}
-
-			infra.AddResource(res)
 		}
+		res.Dependencies = deps
 	}
-
-	return nil
 }
 
-// parseResourceBlock parses a resource block into our Resource model.
-func (p *HCLParser) parseResourceBlock(resourceType, resourceName string, block *hcl.Block) *resource.Resource {
-	resourceID := fmt.Sprintf("%s.%s", resourceType, resourceName)
-	resType := mapAWSTerraformType(resourceType)
+// parseResourceInstance converts an evaluated resource instance into our Resource model.
+func (p *HCLParser) parseResourceInstance(inst *tfconfig.ResourceInstance) *resource.Resource {
+	resourceName := inst.Name
+	if inst.HasKey() {
+		resourceName = fmt.Sprintf("%s-%s", inst.Name, inst.KeyString())
+	}
+	resType := mapAWSTerraformType(inst.Type)
 
-	res := resource.NewAWSResource(resourceID, resourceName, resType)
-	res.Config["terraform_type"] = resourceType
+	res := resource.NewAWSResource(inst.Address, resourceName, resType)
+	res.Config["terraform_type"] = inst.Type
 
-	attrs, _ := block.Body.JustAttributes()
-	for attrName, attr := range attrs {
-		val, diags := attr.Expr.Value(nil)
-		if !diags.HasErrors() {
-			res.Config[attrName] = ctyValueToInterface(val)
+	for _, dep := range inst.Dependencies {
+		res.AddDependency(dep)
+	}
 
-			if attrName == "name" {
-				if strVal, ok := res.Config[attrName].(string); ok && strVal != "" {
-					res.Name = strVal
-				}
+	for attrName, val := range inst.Attributes {
+		res.Config[attrName] = tfconfig.ToInterface(val)
+
+		if attrName == "name" {
+			if strVal, ok := res.Config[attrName].(string); ok && strVal != "" {
+				res.Name = strVal
 			}
+		}
 
-			if attrName == "region" {
-				if strVal, ok := res.Config[attrName].(string); ok {
-					res.Region = strVal
-				}
+		if attrName == "region" {
+			if strVal, ok := res.Config[attrName].(string); ok {
+				res.Region = strVal
 			}
+		}
 
-			if attrName == "availability_zone" {
-				if strVal, ok := res.Config[attrName].(string); ok && len(strVal) > 1 {
-					res.Region = strVal[:len(strVal)-1]
-				}
+		if attrName == "availability_zone" {
+			if strVal, ok := res.Config[attrName].(string); ok && len(strVal) > 1 {
+				res.Region = strVal[:len(strVal)-1]
 			}
+		}
 
-			if attrName == "tags" {
-				if tags, ok := res.Config[attrName].(map[string]interface{}); ok {
-					for k, v := range tags {
-						if strVal, ok := v.(string); ok {
-							res.Tags[k] = strVal
-						}
-					}
-					if nameTag, ok := tags["Name"].(string); ok && nameTag != "" {
-						res.Name = nameTag
+		if attrName == "tags" {
+			if tags, ok := res.Config[attrName].(map[string]interface{}); ok {
+				for k, v := range tags {
+					if strVal, ok := v.(string); ok {
+						res.Tags[k] = strVal
 					}
 				}
+				if nameTag, ok := tags["Name"].(string); ok && nameTag != "" {
+					res.Name = nameTag
+				}
 			}
 		}
 	}
@@ -267,39 +254,6 @@ func (p *HCLParser) parseResourceBlock(resourceType, resourceName string, block
 	return res
 }
 
-// ctyValueToInterface converts a cty.Value to a Go interface{}.
-func ctyValueToInterface(val cty.Value) interface{} {
-	if val.IsNull() {
-		return nil
-	}
-
-	switch {
-	case val.Type() == cty.String:
-		return val.AsString()
-	case val.Type() == cty.Number:
-		f, _ := val.AsBigFloat().Float64()
-		return f
-	case val.Type() == cty.Bool:
-		return val.True()
-	case val.Type().IsListType() || val.Type().IsTupleType():
-		var result []interface{}
-		for it := val.ElementIterator(); it.Next(); {
-			_, v := it.Element()
-			result = append(result, ctyValueToInterface(v))
-		}
-		return result
-	case val.Type().IsMapType() || val.Type().IsObjectType():
-		result := make(map[string]interface{})
-		for it := val.ElementIterator(); it.Next(); {
-			k, v := it.Element()
-			result[k.AsString()] = ctyValueToInterface(v)
-		}
-		return result
-	default:
-		return val.GoString()
-	}
-}
-
 // shouldIncludeResource checks if a resource matches the filter criteria.
 func (p *HCLParser) shouldIncludeResource(res *resource.Resource, opts *parser.ParseOptions) bool {
 	if len(opts.FilterTypes) > 0 {
@@ -0,0 +1,144 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+	"github.com/homeport/homeport/internal/domain/resource"
+)
+
+func TestHCLParser_ParseEvaluatesVariables(t *testing.T) {
+	tmpDir := t.TempDir()
+	tfContent := `
+provider "aws" {
+  region = var.region
+}
+
+variable "region" {
+  default = "eu-west-1"
+}
+
+variable "env" {}
+
+variable "engine" {
+  default = "postgres"
+}
+
+locals {
+  name_prefix = "shop-${var.env}"
+}
+
+resource "aws_db_instance" "main" {
+  identifier     = "${local.name_prefix}-db"
+  engine         = var.engine
+  instance_class = var.env == "prod" ? "db.r5.large" : "db.t3.micro"
+}
+
+resource "aws_instance" "web" {
+  count         = 2
+  instance_type = "t3.small"
+  tags = {
+    Name = "${local.name_prefix}-web-${count.index}"
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "terraform.tfvars"), []byte(`engine = "mysql"`), 0644); err != nil {
+		t.Fatalf("failed to write tfvars: %v", err)
+	}
+
+	p := NewHCLParser()
+	opts := parser.NewParseOptions().WithVariables(map[string]string{"env": "prod"})
+	infra, err := p.Parse(context.Background(), tmpDir, opts)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	db, ok := infra.Resources["aws_db_instance.main"]
+	if !ok {
+		t.Fatal("expected aws_db_instance.main")
+	}
+	if db.Type != resource.TypeRDSInstance {
+		t.Errorf("expected type %s, got %s", resource.TypeRDSInstance, db.Type)
+	}
+	if got := db.Config["identifier"]; got != "shop-prod-db" {
+		t.Errorf("identifier = %v, want shop-prod-db", got)
+	}
+	if got := db.Config["engine"]; got != "mysql" {
+		t.Errorf("engine = %v, want mysql (from terraform.tfvars)", got)
+	}
+	if got := db.Config["instance_class"]; got != "db.r5.large" {
+		t.Errorf("instance_class = %v, want db.r5.large", got)
+	}
+
+	for i, name := range []string{"shop-prod-web-0", "shop-prod-web-1"} {
+		id := "aws_instance.web[" + string(rune('0'+i)) + "]"
+		web, ok := infra.Resources[id]
+		if !ok {
+			t.Fatalf("expected %s", id)
+		}
+		if web.Name != name {
+			t.Errorf("%s name = %q, want %q", id, web.Name, name)
+		}
+	}
+	if _, ok := infra.Resources["aws_instance.web"]; ok {
+		t.Error("counted resource should not appear unexpanded")
+	}
+}
+
+func TestHCLParser_ParseFollowsModules(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.tf": `
+provider "aws" {
+  region = "eu-west-1"
+}
+
+module "db" {
+  source = "./modules/db"
+  name   = "orders"
+}
+`,
+		"modules/db/main.tf": `
+variable "name" {}
+
+resource "aws_db_instance" "this" {
+  identifier = "${var.name}-db"
+  engine     = "postgres"
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	infra, err := NewHCLParser().Parse(context.Background(), tmpDir, parser.NewParseOptions())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(infra.Resources) != 1 {
+		t.Fatalf("expected 1 resource, got %d", len(infra.Resources))
+	}
+	db, ok := infra.Resources["module.db.aws_db_instance.this"]
+	if !ok {
+		t.Fatal("expected module.db.aws_db_instance.this")
+	}
+	if got := db.Config["identifier"]; got != "orders-db" {
+		t.Errorf("identifier = %v, want orders-db", got)
+	}
+	if stats := parser.NewParseStats(infra); stats.ModulesFollowed != 1 {
+		t.Errorf("ModulesFollowed = %d, want 1", stats.ModulesFollowed)
+	}
+}
@@ -266,7 +266,9 @@ func shouldIncludeResource(res *resource.Resource, opts *parser.ParseOptions) bo
 func RegisterAll(registry *parser.Registry) {
 	// Terraform parsers (dedicated)
 	registry.Register(NewTFStateParser())
+	registry.Register(NewTFPlanParser())
 	registry.Register(NewHCLParser())
+	registry.Register(NewTerragruntParser())
 
 	// Legacy combined parser (for backwards compatibility)
 	registry.Register(NewTerraformParser())
@@ -274,6 +276,11 @@ func RegisterAll(registry *parser.Registry) {
 	// CloudFormation parser
 	registry.Register(NewCloudFormationParser())
 
+	// Container workload parsers
+	registry.Register(NewComposeParser())
+	registry.Register(NewKubernetesParser())
+	registry.Register(NewHelmParser())
+
 	// API parser
 	registry.Register(NewAPIParser())
 }
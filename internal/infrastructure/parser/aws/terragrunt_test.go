@@ -0,0 +1,97 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTerragruntParser_Parse(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"terragrunt.hcl": `
+inputs = {
+  project = "shop"
+}
+`,
+		"modules/db/main.tf": `
+variable "project" {}
+variable "env" {}
+resource "aws_security_group" "db" {
+  name = "${var.project}-${var.env}-db-sg"
+}
+resource "aws_db_instance" "main" {
+  identifier             = "${var.project}-${var.env}-db"
+  engine                 = "postgres"
+  vpc_security_group_ids = [aws_security_group.db.id]
+}
+`,
+		"prod/db/terragrunt.hcl": `
+include "root" {
+  path = find_in_parent_folders()
+}
+terraform {
+  source = "../../modules//db"
+}
+inputs = {
+  env = "prod"
+}
+`,
+		"staging/db/terragrunt.hcl": `
+include "root" {
+  path = find_in_parent_folders()
+}
+terraform {
+  source = "../../modules//db"
+}
+inputs = {
+  env = "staging"
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	p := NewTerragruntParser()
+	_, hclConfidence := NewHCLParser().AutoDetect(tmpDir)
+	if ok, confidence := p.AutoDetect(tmpDir); !ok || confidence <= hclConfidence {
+		t.Errorf("AutoDetect = %v, %v; want it to win over the HCL parser", ok, confidence)
+	}
+
+	infra, err := p.Parse(context.Background(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(infra.Resources) != 4 {
+		t.Fatalf("expected 4 resources, got %d", len(infra.Resources))
+	}
+
+	db := infra.Resources["prod/db:aws_db_instance.main"]
+	if db == nil {
+		t.Fatal("expected prod/db:aws_db_instance.main")
+	}
+	if got := db.GetConfigString("identifier"); got != "shop-prod-db" {
+		t.Errorf("identifier = %q, want shop-prod-db", got)
+	}
+	if db.Tags["homeport.environment"] != "prod" || db.Tags["homeport.unit"] != "prod/db" {
+		t.Errorf("tags = %v", db.Tags)
+	}
+	if len(db.Dependencies) != 1 || db.Dependencies[0] != "prod/db:aws_security_group.db" {
+		t.Errorf("dependencies = %v", db.Dependencies)
+	}
+
+	if staging := infra.Resources["staging/db:aws_db_instance.main"]; staging == nil || staging.GetConfigString("identifier") != "shop-staging-db" {
+		t.Errorf("expected the staging instance with its own inputs, got %+v", staging)
+	}
+	if infra.Metadata["environments"] != "prod,staging" {
+		t.Errorf("environments = %q", infra.Metadata["environments"])
+	}
+}
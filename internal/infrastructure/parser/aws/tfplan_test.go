@@ -0,0 +1,114 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+	"github.com/homeport/homeport/internal/domain/resource"
+)
+
+func TestTFPlanParser_Parse(t *testing.T) {
+	tmpDir := t.TempDir()
+	plan := `{
+  "format_version": "1.2",
+  "terraform_version": "1.7.0",
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "aws_db_instance.main",
+          "mode": "managed", "type": "aws_db_instance", "name": "main",
+          "values": {"identifier": "shop-db", "engine": "postgres", "availability_zone": "eu-west-1a"}
+        },
+        {
+          "address": "aws_security_group.db",
+          "mode": "managed", "type": "aws_security_group", "name": "db",
+          "values": {"name": "shop-db-sg"}
+        },
+        {
+          "address": "google_storage_bucket.other",
+          "mode": "managed", "type": "google_storage_bucket", "name": "other",
+          "values": {"name": "other"}
+        }
+      ]
+    }
+  },
+  "resource_changes": [
+    {
+      "address": "aws_db_instance.main",
+      "mode": "managed", "type": "aws_db_instance", "name": "main",
+      "change": {"actions": ["create"]}
+    },
+    {
+      "address": "aws_s3_bucket.old",
+      "mode": "managed", "type": "aws_s3_bucket", "name": "old",
+      "change": {"actions": ["delete"], "before": {"bucket": "old-assets"}}
+    }
+  ],
+  "configuration": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "aws_db_instance.main",
+          "mode": "managed", "type": "aws_db_instance", "name": "main",
+          "expressions": {"vpc_security_group_ids": {"references": ["aws_security_group.db.id", "aws_security_group.db"]}}
+        }
+      ]
+    }
+  }
+}`
+	planPath := filepath.Join(tmpDir, "tfplan.json")
+	if err := os.WriteFile(planPath, []byte(plan), 0644); err != nil {
+		t.Fatalf("failed to write plan: %v", err)
+	}
+
+	p := NewTFPlanParser()
+	if ok, confidence := p.AutoDetect(tmpDir); !ok || confidence <= 0 {
+		t.Errorf("AutoDetect(dir) = %v, %v", ok, confidence)
+	}
+	if err := p.Validate(planPath); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+
+	infra, err := p.Parse(context.Background(), planPath, nil)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(infra.Resources) != 3 {
+		t.Fatalf("expected 3 AWS resources, got %d", len(infra.Resources))
+	}
+
+	db := infra.Resources["aws_db_instance.main"]
+	if db == nil {
+		t.Fatal("expected aws_db_instance.main")
+	}
+	if db.Type != resource.TypeRDSInstance {
+		t.Errorf("type = %s, want %s", db.Type, resource.TypeRDSInstance)
+	}
+	if db.Region != "eu-west-1" {
+		t.Errorf("region = %q, want eu-west-1", db.Region)
+	}
+	if got := db.GetConfigString("terraform_plan_action"); got != "create" {
+		t.Errorf("plan action = %q, want create", got)
+	}
+	if len(db.Dependencies) != 1 || db.Dependencies[0] != "aws_security_group.db" {
+		t.Errorf("dependencies = %v, want [aws_security_group.db]", db.Dependencies)
+	}
+
+	old := infra.Resources["aws_s3_bucket.old"]
+	if old == nil || old.GetConfigString("terraform_plan_action") != "delete" {
+		t.Errorf("expected aws_s3_bucket.old flagged for deletion, got %+v", old)
+	}
+
+	if infra.Metadata["planned_creates"] != "1" || infra.Metadata["planned_destroys"] != "1" {
+		t.Errorf("metadata = %v", infra.Metadata)
+	}
+
+	if _, err := p.Parse(context.Background(), planPath, parser.NewParseOptions().WithFilterTypes(resource.TypeS3Bucket)); err != nil {
+		t.Errorf("Parse with filter failed: %v", err)
+	}
+}
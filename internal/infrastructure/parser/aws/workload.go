@@ -0,0 +1,378 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/infrastructure/parser/workload"
+)
+
+// managedImages maps well-known database, cache and broker images to the
+// managed AWS service they stand in for, so they consolidate into the same
+// stacks as RDS, ElastiCache and Amazon MQ resources.
+var managedImages = map[string]struct {
+	resourceType resource.Type
+	engine       string
+}{
+	"postgres":       {resource.TypeRDSInstance, "postgres"},
+	"postgresql":     {resource.TypeRDSInstance, "postgres"},
+	"postgis":        {resource.TypeRDSInstance, "postgres"},
+	"timescaledb":    {resource.TypeRDSInstance, "postgres"},
+	"timescaledb-ha": {resource.TypeRDSInstance, "postgres"},
+	"mysql":          {resource.TypeRDSInstance, "mysql"},
+	"mysql-server":   {resource.TypeRDSInstance, "mysql"},
+	"percona":        {resource.TypeRDSInstance, "mysql"},
+	"percona-server": {resource.TypeRDSInstance, "mysql"},
+	"mariadb":        {resource.TypeRDSInstance, "mariadb"},
+	"redis":          {resource.TypeElastiCache, "redis"},
+	"redis-stack":    {resource.TypeElastiCache, "redis"},
+	"valkey":         {resource.TypeElastiCache, "redis"},
+	"memcached":      {resource.TypeElastiCache, "memcached"},
+	"rabbitmq":       {resource.TypeMQBroker, "RabbitMQ"},
+}
+
+// databaseEnv lists the environment variables official images read the
+// initial database name and user from.
+var databaseEnv = map[string][2]string{
+	"postgres": {"POSTGRES_DB", "POSTGRES_USER"},
+	"mysql":    {"MYSQL_DATABASE", "MYSQL_USER"},
+	"mariadb":  {"MARIADB_DATABASE", "MARIADB_USER"},
+}
+
+// WorkloadParser parses Docker Compose projects, Kubernetes manifests and
+// rendered Helm charts. Containers become ECS services; well-known
+// database, cache and broker images become RDS, ElastiCache and MQ
+// resources.
+type WorkloadParser struct {
+	format parser.Format
+}
+
+// NewComposeParser creates a new Docker Compose parser.
+func NewComposeParser() *WorkloadParser {
+	return &WorkloadParser{format: parser.FormatDockerCompose}
+}
+
+// NewKubernetesParser creates a new Kubernetes manifest parser.
+func NewKubernetesParser() *WorkloadParser {
+	return &WorkloadParser{format: parser.FormatKubernetes}
+}
+
+// NewHelmParser creates a new parser for charts rendered with helm template.
+func NewHelmParser() *WorkloadParser {
+	return &WorkloadParser{format: parser.FormatHelm}
+}
+
+// Provider returns the cloud provider.
+func (p *WorkloadParser) Provider() resource.Provider {
+	return resource.ProviderAWS
+}
+
+// SupportedFormats returns the supported formats.
+func (p *WorkloadParser) SupportedFormats() []parser.Format {
+	return []parser.Format{p.format}
+}
+
+// Validate checks if the path holds workloads in the parser's format.
+func (p *WorkloadParser) Validate(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return parser.ErrInvalidPath
+	}
+	if ok, _ := p.AutoDetect(path); !ok {
+		return parser.ErrUnsupportedFormat
+	}
+	return nil
+}
+
+// AutoDetect checks if this parser can handle the given path.
+func (p *WorkloadParser) AutoDetect(path string) (bool, float64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, 0
+	}
+
+	if p.format == parser.FormatDockerCompose {
+		files, err := workload.FindCompose(path)
+		if err != nil {
+			return false, 0
+		}
+		data, err := os.ReadFile(files[0])
+		if err != nil || !workload.IsComposeFile(data) {
+			return false, 0
+		}
+		if info.IsDir() || isComposeFileName(info.Name()) {
+			return true, 0.9
+		}
+		return true, 0.8
+	}
+
+	found, helm := workload.ScanManifests(path)
+	switch {
+	case !found:
+		return false, 0
+	case p.format == parser.FormatHelm && helm:
+		return true, 0.9
+	case p.format == parser.FormatHelm:
+		return false, 0
+	case helm:
+		return true, 0.6
+	default:
+		return true, 0.85
+	}
+}
+
+// Parse loads the workloads at path and returns them as AWS infrastructure.
+func (p *WorkloadParser) Parse(ctx context.Context, path string, opts *parser.ParseOptions) (*resource.Infrastructure, error) {
+	if opts == nil {
+		opts = parser.NewParseOptions()
+	}
+
+	var project *workload.Project
+	var err error
+	if p.format == parser.FormatDockerCompose {
+		project, err = workload.LoadCompose(path)
+	} else {
+		project, err = workload.LoadManifests(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	infra := resource.NewInfrastructure(resource.ProviderAWS)
+	infra.Metadata["format"] = string(project.Format)
+	infra.Metadata["project"] = project.Name
+	if project.Chart != nil {
+		infra.Metadata["helm_chart"] = project.Chart.Name
+		infra.Metadata["helm_chart_version"] = project.Chart.Version
+		infra.Metadata["helm_release"] = project.Chart.Release
+	}
+
+	for _, w := range project.Workloads {
+		res := p.convertWorkload(project, w)
+		if !shouldIncludeResource(res, opts) {
+			continue
+		}
+		infra.AddResource(res)
+	}
+
+	// Drop edges to resources that were filtered out.
+	for _, res := range infra.Resources {
+		deps := make([]string, 0, len(res.Dependencies))
+		for _, dep := range res.Dependencies {
+			if _, ok := infra.Resources[dep]; ok {
+				deps = append(deps, dep)
+			}
+		}
+		res.Dependencies = deps
+	}
+
+	return infra, nil
+}
+
+// convertWorkload converts a workload to our Resource model.
+func (p *WorkloadParser) convertWorkload(project *workload.Project, w *workload.Workload) *resource.Resource {
+	res := resource.NewAWSResource(w.ID, w.Name, resource.TypeECSService)
+	for k, v := range w.Labels {
+		res.Tags[k] = v
+	}
+	for _, dep := range w.DependsOn {
+		res.AddDependency(dep)
+	}
+
+	res.Config["source_format"] = string(project.Format)
+	res.Config["source_kind"] = w.Kind
+	res.Config["image"] = w.Image
+	if w.Namespace != "" {
+		res.Config["namespace"] = w.Namespace
+	}
+
+	port := 0
+	if len(w.Ports) > 0 {
+		port = w.Ports[0].Container
+	}
+
+	managed, ok := managedImages[path.Base(w.ImageName())]
+	if ok && w.Kind != "Job" && w.Kind != "CronJob" {
+		res.Type = managed.resourceType
+		version := engineVersion(w.ImageTag())
+		switch managed.resourceType {
+		case resource.TypeRDSInstance:
+			res.Config["identifier"] = w.Name
+			res.Config["engine"] = managed.engine
+			res.Config["engine_version"] = version
+			if env, ok := databaseEnv[managed.engine]; ok {
+				res.Config["db_name"] = w.Env[env[0]]
+				res.Config["username"] = w.Env[env[1]]
+			}
+			if port > 0 {
+				res.Config["port"] = port
+			}
+		case resource.TypeElastiCache:
+			res.Config["cluster_id"] = w.Name
+			res.Config["engine"] = managed.engine
+			res.Config["engine_version"] = version
+			res.Config["num_cache_nodes"] = w.Replicas
+			if port > 0 {
+				res.Config["port"] = port
+			}
+		case resource.TypeMQBroker:
+			res.Config["broker_name"] = w.Name
+			res.Config["engine_type"] = managed.engine
+			res.Config["engine_version"] = version
+		}
+		return res
+	}
+
+	res.Config["name"] = w.Name
+	res.Config["desired_count"] = w.Replicas
+	if w.CPU > 0 {
+		res.Config["cpu"] = int(w.CPU * 1024)
+	}
+	if w.MemoryMB > 0 {
+		res.Config["memory"] = w.MemoryMB
+	}
+	if w.Schedule != "" {
+		res.Config["schedule"] = w.Schedule
+	}
+	if w.Build != "" {
+		res.Config["build_context"] = w.Build
+	}
+	if len(w.Hosts) > 0 {
+		res.Config["hosts"] = toInterfaceSlice(w.Hosts)
+	}
+	if len(w.Sidecars) > 0 {
+		res.Config["sidecars"] = toInterfaceSlice(w.Sidecars)
+	}
+	if w.Public && port > 0 {
+		res.Config["load_balancer"] = []interface{}{
+			map[string]interface{}{"container_port": float64(port)},
+		}
+	}
+	res.Config["container_definitions"] = []interface{}{containerDefinition(w)}
+
+	return res
+}
+
+// containerDefinition renders a workload as an ECS container definition,
+// the shape the ECS mapper and secret detector read.
+func containerDefinition(w *workload.Workload) map[string]interface{} {
+	def := map[string]interface{}{
+		"name":  w.Name,
+		"image": w.Image,
+	}
+	if len(w.Command) > 0 {
+		def["command"] = toInterfaceSlice(w.Command)
+	}
+
+	var env []interface{}
+	for _, name := range sortedKeys(w.Env) {
+		env = append(env, map[string]interface{}{"name": name, "value": w.Env[name]})
+	}
+	if len(env) > 0 {
+		def["environment"] = env
+	}
+
+	var secrets []interface{}
+	for _, name := range sortedKeys(w.Secrets) {
+		secrets = append(secrets, map[string]interface{}{"name": name, "valueFrom": w.Secrets[name]})
+	}
+	if len(secrets) > 0 {
+		def["secrets"] = secrets
+	}
+
+	var ports []interface{}
+	for _, port := range w.Ports {
+		pm := map[string]interface{}{"containerPort": float64(port.Container)}
+		if port.Published > 0 {
+			pm["hostPort"] = float64(port.Published)
+		}
+		if port.Protocol != "" {
+			pm["protocol"] = port.Protocol
+		}
+		ports = append(ports, pm)
+	}
+	if len(ports) > 0 {
+		def["portMappings"] = ports
+	}
+
+	var mounts []interface{}
+	for _, mount := range w.Mounts {
+		mounts = append(mounts, map[string]interface{}{
+			"sourceVolume":  volumeName(mount.Source),
+			"containerPath": mount.Target,
+			"readOnly":      mount.ReadOnly,
+		})
+	}
+	if len(mounts) > 0 {
+		def["mountPoints"] = mounts
+	}
+
+	if hc := w.HealthCheck; hc != nil {
+		check := map[string]interface{}{"command": toInterfaceSlice(hc.Test)}
+		if hc.Interval > 0 {
+			check["interval"] = hc.Interval.Seconds()
+		}
+		if hc.Timeout > 0 {
+			check["timeout"] = hc.Timeout.Seconds()
+		}
+		if hc.Retries > 0 {
+			check["retries"] = float64(hc.Retries)
+		}
+		def["healthCheck"] = check
+	}
+
+	return def
+}
+
+// engineVersion extracts the version from an image tag, e.g. "16" from
+// "16-alpine". Tags without a leading version yield "".
+func engineVersion(tag string) string {
+	end := 0
+	for end < len(tag) && (tag[end] >= '0' && tag[end] <= '9' || tag[end] == '.') {
+		end++
+	}
+	return strings.TrimSuffix(tag[:end], ".")
+}
+
+// isComposeFileName reports whether name is one of the default compose file
+// names.
+func isComposeFileName(name string) bool {
+	for _, candidate := range workload.ComposeFiles {
+		if name == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// volumeName turns a volume source into a name usable as a data directory.
+func volumeName(source string) string {
+	name := strings.Trim(strings.NewReplacer("/", "-", ".", "").Replace(source), "-")
+	if name == "" {
+		return "data"
+	}
+	return name
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toInterfaceSlice converts a string slice to the generic form used in Config.
+func toInterfaceSlice(list []string) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, s := range list {
+		out[i] = s
+	}
+	return out
+}
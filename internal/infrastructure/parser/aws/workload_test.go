@@ -0,0 +1,208 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+	"github.com/homeport/homeport/internal/domain/resource"
+)
+
+func TestComposeParser_Parse(t *testing.T) {
+	tmpDir := t.TempDir()
+	compose := `
+name: shop
+services:
+  web:
+    image: acme/shop-web:1.0
+    ports:
+      - "8080:80"
+    environment:
+      DATABASE_URL: postgres://app@db:5432/shop
+    healthcheck:
+      test: ["CMD", "curl", "-f", "http://localhost/"]
+      interval: 30s
+  db:
+    image: postgres:16-alpine
+    environment:
+      POSTGRES_DB: shop
+      POSTGRES_USER: app
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+  cache:
+    image: redis:7.2
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "docker-compose.yml"), []byte(compose), 0644); err != nil {
+		t.Fatalf("failed to write compose file: %v", err)
+	}
+
+	p := NewComposeParser()
+	if ok, confidence := p.AutoDetect(tmpDir); !ok || confidence < 0.9 {
+		t.Errorf("AutoDetect = %v, %v", ok, confidence)
+	}
+	if ok, _ := NewKubernetesParser().AutoDetect(tmpDir); ok {
+		t.Error("kubernetes parser should not detect a compose project")
+	}
+
+	infra, err := p.Parse(context.Background(), tmpDir, parser.NewParseOptions())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if infra.Metadata["format"] != string(parser.FormatDockerCompose) || infra.Metadata["project"] != "shop" {
+		t.Errorf("metadata = %v", infra.Metadata)
+	}
+	if len(infra.Resources) != 3 {
+		t.Fatalf("expected 3 resources, got %d", len(infra.Resources))
+	}
+
+	web := infra.Resources["shop/web"]
+	if web == nil || web.Type != resource.TypeECSService {
+		t.Fatalf("web = %+v", web)
+	}
+	if len(web.Dependencies) != 1 || web.Dependencies[0] != "shop/db" {
+		t.Errorf("web dependencies = %v", web.Dependencies)
+	}
+	if _, ok := web.Config["load_balancer"]; !ok {
+		t.Error("published web service should get a load balancer")
+	}
+	defs, _ := web.Config["container_definitions"].([]interface{})
+	if len(defs) != 1 {
+		t.Fatalf("container_definitions = %v", web.Config["container_definitions"])
+	}
+	def := defs[0].(map[string]interface{})
+	ports := def["portMappings"].([]interface{})
+	if pm := ports[0].(map[string]interface{}); pm["containerPort"] != float64(80) || pm["hostPort"] != float64(8080) {
+		t.Errorf("port mapping = %v", pm)
+	}
+	if hc := def["healthCheck"].(map[string]interface{}); hc["interval"] != float64(30) {
+		t.Errorf("health check = %v", hc)
+	}
+
+	db := infra.Resources["shop/db"]
+	if db.Type != resource.TypeRDSInstance {
+		t.Fatalf("db type = %s", db.Type)
+	}
+	if db.Config["engine"] != "postgres" || db.Config["engine_version"] != "16" || db.Config["db_name"] != "shop" || db.Config["username"] != "app" {
+		t.Errorf("db config = %v", db.Config)
+	}
+
+	cache := infra.Resources["shop/cache"]
+	if cache.Type != resource.TypeElastiCache || cache.Config["engine_version"] != "7.2" {
+		t.Errorf("cache = %s %v", cache.Type, cache.Config)
+	}
+}
+
+func TestKubernetesParser_Parse(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifests := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: shop
+spec:
+  replicas: 2
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+        - name: api
+          image: acme/api:3.1
+          ports:
+            - containerPort: 9000
+          env:
+            - name: AMQP_URL
+              value: amqp://queue:5672
+          resources:
+            requests:
+              cpu: 250m
+              memory: 128Mi
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: queue
+  namespace: shop
+spec:
+  template:
+    metadata:
+      labels:
+        app: queue
+    spec:
+      containers:
+        - name: rabbitmq
+          image: rabbitmq:3.13-management
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: queue
+  namespace: shop
+spec:
+  selector:
+    app: queue
+  ports:
+    - port: 5672
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: shop
+spec:
+  type: LoadBalancer
+  selector:
+    app: api
+  ports:
+    - port: 80
+      targetPort: 9000
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "app.yaml"), []byte(manifests), 0644); err != nil {
+		t.Fatalf("failed to write manifests: %v", err)
+	}
+
+	p := NewKubernetesParser()
+	if ok, confidence := p.AutoDetect(tmpDir); !ok || confidence < 0.8 {
+		t.Errorf("AutoDetect = %v, %v", ok, confidence)
+	}
+	if ok, _ := NewHelmParser().AutoDetect(tmpDir); ok {
+		t.Error("helm parser should not detect plain manifests")
+	}
+
+	infra, err := p.Parse(context.Background(), tmpDir, parser.NewParseOptions())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	api := infra.Resources["shop/deployment/api"]
+	if api == nil || api.Type != resource.TypeECSService {
+		t.Fatalf("api = %+v", api)
+	}
+	if api.Config["desired_count"] != 2 || api.Config["cpu"] != 256 || api.Config["memory"] != 128 {
+		t.Errorf("api config = %v", api.Config)
+	}
+	if len(api.Dependencies) != 1 || api.Dependencies[0] != "shop/statefulset/queue" {
+		t.Errorf("api dependencies = %v", api.Dependencies)
+	}
+	if _, ok := api.Config["load_balancer"]; !ok {
+		t.Error("LoadBalancer service should expose api")
+	}
+
+	queue := infra.Resources["shop/statefulset/queue"]
+	if queue == nil || queue.Type != resource.TypeMQBroker || queue.Config["engine_version"] != "3.13" {
+		t.Errorf("queue = %+v", queue)
+	}
+
+	opts := parser.NewParseOptions().WithFilterTypes(resource.TypeECSService)
+	infra, err = p.Parse(context.Background(), tmpDir, opts)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(infra.Resources) != 1 || len(infra.Resources["shop/deployment/api"].Dependencies) != 0 {
+		t.Errorf("filtered resources = %v", infra.Resources)
+	}
+}
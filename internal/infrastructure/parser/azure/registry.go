@@ -8,7 +8,9 @@ import (
 func RegisterAll(registry *parser.Registry) {
 	// Terraform parsers (dedicated)
 	registry.Register(NewTFStateParser())
+	registry.Register(NewTFPlanParser())
 	registry.Register(NewHCLParser())
+	registry.Register(NewTerragruntParser())
 
 	// Legacy combined parser (for backwards compatibility)
 	registry.Register(NewTerraformParser())
@@ -0,0 +1,148 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/infrastructure/parser/terragrunt"
+	"github.com/homeport/homeport/internal/infrastructure/parser/tfconfig"
+)
+
+// TerragruntParser parses Terragrunt projects for Azure resources. Each unit's
+// module is evaluated by the HCL parser with the unit inputs bound.
+type TerragruntParser struct {
+	hcl *HCLParser
+}
+
+// NewTerragruntParser creates a new Azure Terragrunt parser.
+func NewTerragruntParser() *TerragruntParser {
+	return &TerragruntParser{hcl: NewHCLParser()}
+}
+
+// Provider returns the cloud provider.
+func (p *TerragruntParser) Provider() resource.Provider {
+	return resource.ProviderAzure
+}
+
+// SupportedFormats returns the supported formats.
+func (p *TerragruntParser) SupportedFormats() []parser.Format {
+	return []parser.Format{parser.FormatTerragrunt}
+}
+
+// Validate checks if the path is a Terragrunt project with Azure units.
+func (p *TerragruntParser) Validate(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return parser.ErrInvalidPath
+	}
+	if !terragrunt.IsProject(path) {
+		return parser.ErrNoFilesFound
+	}
+	if ok, _ := p.AutoDetect(path); !ok {
+		return parser.ErrUnsupportedFormat
+	}
+	return nil
+}
+
+// AutoDetect checks if this parser can handle the given path.
+func (p *TerragruntParser) AutoDetect(path string) (bool, float64) {
+	if !terragrunt.IsProject(path) {
+		return false, 0
+	}
+
+	units, err := terragrunt.Load(path, nil)
+	if err != nil {
+		return false, 0
+	}
+	for _, unit := range units {
+		if unit.SourceDir == "" {
+			continue
+		}
+		if ok, _ := p.hcl.AutoDetect(unit.SourceDir); ok {
+			return true, 0.95
+		}
+	}
+	return false, 0
+}
+
+// Parse evaluates every Terragrunt unit and returns the merged Azure
+// infrastructure. Resource IDs are prefixed with the unit directory and
+// resources are tagged with their environment and unit.
+func (p *TerragruntParser) Parse(ctx context.Context, path string, opts *parser.ParseOptions) (*resource.Infrastructure, error) {
+	if opts == nil {
+		opts = parser.NewParseOptions()
+	}
+
+	evalOpts := tfconfig.FromParseOptions(opts)
+	units, err := terragrunt.Load(path, evalOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	infra := resource.NewInfrastructure(resource.ProviderAzure)
+	infra.Metadata["format"] = string(parser.FormatTerragrunt)
+
+	environments := make(map[string]bool)
+	modulesFollowed := 0
+	for _, unit := range units {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		if unit.SourceDir == "" {
+			if opts.IgnoreErrors {
+				continue
+			}
+			return nil, fmt.Errorf("terragrunt unit %s: module source %q not found", unit.RelDir, unit.Source)
+		}
+
+		unitOpts := *evalOpts
+		unitOpts.Inputs = unit.Inputs
+		mod, err := tfconfig.LoadDir(unit.SourceDir, &unitOpts)
+		if err != nil {
+			if opts.IgnoreErrors {
+				continue
+			}
+			return nil, fmt.Errorf("terragrunt unit %s: %w", unit.RelDir, err)
+		}
+		modulesFollowed += mod.ModulesFollowed()
+
+		unitInfra := resource.NewInfrastructure(resource.ProviderAzure)
+		p.hcl.addModuleResources(mod, unitInfra, opts)
+		for _, res := range unitInfra.Resources {
+			res.ID = unit.RelDir + ":" + res.ID
+			for i, dep := range res.Dependencies {
+				res.Dependencies[i] = unit.RelDir + ":" + dep
+			}
+			if unit.Environment != "" {
+				res.Tags["homeport.environment"] = unit.Environment
+			}
+			res.Tags["homeport.unit"] = unit.RelDir
+			infra.AddResource(res)
+		}
+		if unit.Environment != "" {
+			environments[unit.Environment] = true
+		}
+	}
+
+	if len(environments) > 0 {
+		names := make([]string, 0, len(environments))
+		for name := range environments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		infra.Metadata["environments"] = strings.Join(names, ",")
+	}
+	if modulesFollowed > 0 {
+		infra.Metadata[parser.MetadataModulesFollowed] = strconv.Itoa(modulesFollowed)
+	}
+
+	return infra, nil
+}
@@ -0,0 +1,178 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+	"github.com/homeport/homeport/internal/domain/resource"
+	"github.com/homeport/homeport/internal/infrastructure/parser/tfplan"
+)
+
+// TFPlanParser parses Terraform JSON plans (terraform show -json) for Azure resources.
+type TFPlanParser struct {
+	state *TFStateParser
+}
+
+// NewTFPlanParser creates a new Azure Terraform plan parser.
+func NewTFPlanParser() *TFPlanParser {
+	return &TFPlanParser{state: NewTFStateParser()}
+}
+
+// Provider returns the cloud provider.
+func (p *TFPlanParser) Provider() resource.Provider {
+	return resource.ProviderAzure
+}
+
+// SupportedFormats returns the supported formats.
+func (p *TFPlanParser) SupportedFormats() []parser.Format {
+	return []parser.Format{parser.FormatTFPlan}
+}
+
+// Validate checks if the path is a Terraform JSON plan with Azure resources.
+func (p *TFPlanParser) Validate(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return parser.ErrInvalidPath
+	}
+
+	planFile, err := tfplan.Find(path)
+	if err != nil {
+		return parser.ErrNoFilesFound
+	}
+
+	plan, err := tfplan.Load(planFile)
+	if err != nil {
+		return parser.ErrUnsupportedFormat
+	}
+
+	if count, _ := p.countResources(plan); count == 0 {
+		return parser.ErrUnsupportedFormat
+	}
+
+	return nil
+}
+
+// AutoDetect checks if this parser can handle the given path.
+func (p *TFPlanParser) AutoDetect(path string) (bool, float64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, 0
+	}
+	if !info.IsDir() && strings.ToLower(filepath.Ext(path)) != ".json" {
+		return false, 0
+	}
+
+	planFile, err := tfplan.Find(path)
+	if err != nil {
+		return false, 0
+	}
+
+	plan, err := tfplan.Load(planFile)
+	if err != nil {
+		return false, 0
+	}
+
+	azureCount, totalCount := p.countResources(plan)
+	if azureCount > 0 {
+		confidence := float64(azureCount) / float64(totalCount)
+		if confidence > 0.9 {
+			return true, 0.95
+		}
+		return true, confidence * 0.85
+	}
+
+	return false, 0
+}
+
+// Parse parses a Terraform JSON plan and returns Azure infrastructure.
+func (p *TFPlanParser) Parse(ctx context.Context, path string, opts *parser.ParseOptions) (*resource.Infrastructure, error) {
+	if opts == nil {
+		opts = parser.NewParseOptions()
+	}
+
+	planFile, err := tfplan.Find(path)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := tfplan.Load(planFile)
+	if err != nil {
+		return nil, err
+	}
+
+	infra := resource.NewInfrastructure(resource.ProviderAzure)
+	infra.Metadata["format"] = "tfplan"
+	infra.Metadata["terraform_version"] = plan.TerraformVersion
+
+	creates, destroys := 0, 0
+	for _, planned := range plan.Resources() {
+		if planned.Mode != "managed" || !strings.HasPrefix(planned.Type, "azurerm_") {
+			continue
+		}
+
+		res := p.convertResource(planned)
+		if !p.state.shouldIncludeResource(res, opts) {
+			continue
+		}
+
+		infra.AddResource(res)
+		if planned.Creates() {
+			creates++
+		}
+		if planned.Destroys() {
+			destroys++
+		}
+	}
+
+	// Drop edges to resources that were filtered out or are not ours.
+	for _, res := range infra.Resources {
+		deps := make([]string, 0, len(res.Dependencies))
+		for _, dep := range res.Dependencies {
+			if _, ok := infra.Resources[dep]; ok {
+				deps = append(deps, dep)
+			}
+		}
+		res.Dependencies = deps
+	}
+
+	infra.Metadata["planned_creates"] = strconv.Itoa(creates)
+	infra.Metadata["planned_destroys"] = strconv.Itoa(destroys)
+
+	return infra, nil
+}
+
+// countResources counts the Azure and all managed resources in a plan.
+func (p *TFPlanParser) countResources(plan *tfplan.Plan) (int, int) {
+	azureCount, totalCount := 0, 0
+	for _, planned := range plan.Resources() {
+		if planned.Mode != "managed" {
+			continue
+		}
+		totalCount++
+		if strings.HasPrefix(planned.Type, "azurerm_") {
+			azureCount++
+		}
+	}
+	return azureCount, totalCount
+}
+
+// convertResource converts a planned resource to our Resource model. The
+// planned action is recorded in the terraform_plan_action config key.
+func (p *TFPlanParser) convertResource(planned *tfplan.Resource) *resource.Resource {
+	res := p.state.convertResource(
+		StateResource{Mode: planned.Mode, Type: planned.Type, Name: planned.Name},
+		ResourceInstance{Attributes: planned.Values, IndexKey: planned.Index},
+	)
+	res.ID = planned.Address
+	if planned.Index != nil && res.Name == planned.Name {
+		res.Name = fmt.Sprintf("%s-%v", planned.Name, planned.Index)
+	}
+	res.Dependencies = append(res.Dependencies[:0], planned.Dependencies...)
+	res.Config["terraform_type"] = planned.Type
+	res.Config["terraform_plan_action"] = string(planned.Action)
+	return res
+}
@@ -0,0 +1,592 @@
+package terragrunt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/homeport/homeport/internal/infrastructure/parser/tfconfig"
+)
+
+// Include merge strategies.
+const (
+	mergeShallow = "shallow"
+	mergeDeep    = "deep"
+	mergeNone    = "no_merge"
+)
+
+// scope is the directory context a configuration file is evaluated in.
+// Included files are evaluated in the context of the including unit, which
+// is what makes path_relative_to_include work in a root configuration.
+type scope struct {
+	// unitDir is the directory of the unit being evaluated.
+	unitDir string
+
+	// configDir is the directory of the file being evaluated.
+	configDir string
+
+	// includeDir is the directory of the included configuration, if any.
+	includeDir string
+}
+
+// config is one evaluated terragrunt.hcl, with its includes merged in.
+type config struct {
+	path         string
+	locals       map[string]cty.Value
+	inputs       map[string]cty.Value
+	source       string
+	includes     []string
+	dependencies []string
+	diags        hcl.Diagnostics
+}
+
+// loader evaluates the configurations of a project. Units and dependency
+// outputs are evaluated once and cached.
+type loader struct {
+	root     string
+	opts     *tfconfig.Options
+	configs  map[string]*config
+	units    map[string]*Unit
+	outputs  map[string]map[string]cty.Value
+	visiting map[string]bool
+}
+
+// newLoader creates a loader for the project at root.
+func newLoader(root string, opts *tfconfig.Options) *loader {
+	if opts == nil {
+		opts = &tfconfig.Options{}
+	}
+	return &loader{
+		root:     root,
+		opts:     opts,
+		configs:  make(map[string]*config),
+		units:    make(map[string]*Unit),
+		outputs:  make(map[string]map[string]cty.Value),
+		visiting: make(map[string]bool),
+	}
+}
+
+// config returns the evaluated configuration of the unit at path.
+func (l *loader) config(path string) *config {
+	if cfg, ok := l.configs[path]; ok {
+		return cfg
+	}
+	dir := filepath.Dir(path)
+	cfg := l.evaluate(path, scope{unitDir: dir, configDir: dir})
+	l.configs[path] = cfg
+	return cfg
+}
+
+// unit returns the evaluated unit at path.
+func (l *loader) unit(path string) *Unit {
+	if unit, ok := l.units[path]; ok {
+		return unit
+	}
+
+	cfg := l.config(path)
+	dir := filepath.Dir(path)
+	unit := &Unit{
+		Path:        path,
+		Dir:         dir,
+		RelDir:      l.relative(dir),
+		Source:      cfg.source,
+		Inputs:      cfg.inputs,
+		Locals:      cfg.locals,
+		Diagnostics: cfg.diags,
+	}
+	for _, dep := range cfg.dependencies {
+		unit.Dependencies = append(unit.Dependencies, l.relative(dep))
+	}
+	sort.Strings(unit.Dependencies)
+
+	sourceDir, err := l.sourceDir(dir, cfg.source)
+	if err != nil {
+		unit.Diagnostics = append(unit.Diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Module source not found",
+			Detail:   err.Error(),
+		})
+	}
+	unit.SourceDir = sourceDir
+	unit.Environment = environment(unit)
+
+	l.units[path] = unit
+	return unit
+}
+
+// relative returns dir relative to the project root, slash separated.
+func (l *loader) relative(dir string) string {
+	rel, err := filepath.Rel(l.root, dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// evaluate evaluates the file at path in the given scope: includes first,
+// then locals, dependency blocks, the terraform source and inputs, and
+// finally merges the included configurations underneath.
+func (l *loader) evaluate(path string, sc scope) *config {
+	cfg := &config{
+		path:   path,
+		locals: make(map[string]cty.Value),
+		inputs: make(map[string]cty.Value),
+	}
+	if l.visiting[path] {
+		cfg.diags = append(cfg.diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Include cycle",
+			Detail:   fmt.Sprintf("%s includes itself", path),
+		})
+		return cfg
+	}
+	l.visiting[path] = true
+	defer delete(l.visiting, path)
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		cfg.diags = append(cfg.diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read file",
+			Detail:   err.Error(),
+		})
+		return cfg
+	}
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	cfg.diags = append(cfg.diags, diags...)
+	if diags.HasErrors() {
+		return cfg
+	}
+	body := file.Body.(*hclsyntax.Body)
+
+	vars := make(map[string]cty.Value)
+	type parent struct {
+		cfg      *config
+		strategy string
+	}
+	var parents []parent
+	unnamed := cty.EmptyObjectVal
+	named := make(map[string]cty.Value)
+	for _, block := range body.Blocks {
+		if block.Type != "include" {
+			continue
+		}
+		ctx := l.context(sc, vars)
+		incPath, ok := l.pathAttribute(block, "path", sc.configDir, ctx, &cfg.diags)
+		if !ok {
+			continue
+		}
+		strategy := mergeShallow
+		if attr, ok := block.Body.Attributes["merge_strategy"]; ok {
+			if s, ok := stringValue(l.value(attr, ctx, &cfg.diags)); ok {
+				strategy = s
+			}
+		}
+
+		incDir := filepath.Dir(incPath)
+		inc := l.evaluate(incPath, scope{unitDir: sc.unitDir, configDir: incDir, includeDir: incDir})
+		cfg.diags = append(cfg.diags, inc.diags...)
+		cfg.includes = append(append(cfg.includes, incPath), inc.includes...)
+		if strategy != mergeNone {
+			parents = append(parents, parent{cfg: inc, strategy: strategy})
+		}
+
+		exposed := cty.ObjectVal(map[string]cty.Value{
+			"locals": objectOrEmpty(inc.locals),
+			"inputs": objectOrEmpty(inc.inputs),
+		})
+		if len(block.Labels) > 0 {
+			named[block.Labels[0]] = exposed
+		} else {
+			unnamed = exposed
+		}
+	}
+	if sc.includeDir == "" && len(cfg.includes) > 0 {
+		sc.includeDir = filepath.Dir(cfg.includes[0])
+	}
+	if len(named) > 0 {
+		vars["include"] = cty.ObjectVal(named)
+	} else {
+		vars["include"] = unnamed
+	}
+
+	ctx := l.context(sc, vars)
+	l.evaluateLocals(body, ctx, cfg)
+	l.evaluateDependencies(body, sc, ctx, cfg)
+
+	for _, block := range body.Blocks {
+		if block.Type != "terraform" {
+			continue
+		}
+		if attr, ok := block.Body.Attributes["source"]; ok {
+			if s, ok := stringValue(l.value(attr, ctx, &cfg.diags)); ok {
+				cfg.source = s
+			}
+		}
+	}
+
+	if attr, ok := body.Attributes["inputs"]; ok {
+		cfg.inputs = l.objectAttribute(attr, ctx, &cfg.diags)
+	}
+
+	for _, p := range parents {
+		if cfg.source == "" {
+			cfg.source = p.cfg.source
+		}
+		cfg.dependencies = append(cfg.dependencies, p.cfg.dependencies...)
+		cfg.inputs = mergeValues(p.cfg.inputs, cfg.inputs, p.strategy == mergeDeep)
+	}
+	cfg.dependencies = uniqueStrings(cfg.dependencies)
+
+	return cfg
+}
+
+// evaluateLocals evaluates the locals blocks of body in dependency order.
+// Locals that cannot be evaluated, including those in a cycle, are unknown.
+func (l *loader) evaluateLocals(body *hclsyntax.Body, ctx *hcl.EvalContext, cfg *config) {
+	pending := make(map[string]*hclsyntax.Attribute)
+	for _, block := range body.Blocks {
+		if block.Type != "locals" {
+			continue
+		}
+		for name, attr := range block.Body.Attributes {
+			pending[name] = attr
+		}
+	}
+
+	ready := func(attr *hclsyntax.Attribute) bool {
+		for _, traversal := range attr.Expr.Variables() {
+			if traversal.RootName() != "local" || len(traversal) < 2 {
+				continue
+			}
+			if step, ok := traversal[1].(hcl.TraverseAttr); ok {
+				if _, waiting := pending[step.Name]; waiting {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	for len(pending) > 0 {
+		names := make([]string, 0, len(pending))
+		for name := range pending {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		progress := false
+		for _, name := range names {
+			attr := pending[name]
+			if !ready(attr) {
+				continue
+			}
+			ctx.Variables["local"] = objectOrEmpty(cfg.locals)
+			cfg.locals[name] = l.value(attr, ctx, &cfg.diags)
+			delete(pending, name)
+			progress = true
+		}
+		if progress {
+			continue
+		}
+
+		for _, name := range names {
+			cfg.diags = append(cfg.diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Local value cycle",
+				Detail:   fmt.Sprintf("local.%s refers to itself", name),
+				Subject:  pending[name].SrcRange.Ptr(),
+			})
+			cfg.locals[name] = cty.DynamicVal
+		}
+		break
+	}
+	ctx.Variables["local"] = objectOrEmpty(cfg.locals)
+}
+
+// evaluateDependencies evaluates the dependency and dependencies blocks of
+// body and exposes dependency.<name>.outputs to later expressions.
+func (l *loader) evaluateDependencies(body *hclsyntax.Body, sc scope, ctx *hcl.EvalContext, cfg *config) {
+	deps := make(map[string]cty.Value)
+	for _, block := range body.Blocks {
+		switch block.Type {
+		case "dependency":
+			if len(block.Labels) < 1 {
+				continue
+			}
+			dir, ok := l.pathAttribute(block, "config_path", sc.unitDir, ctx, &cfg.diags)
+			if !ok {
+				continue
+			}
+			cfg.dependencies = append(cfg.dependencies, dir)
+			deps[block.Labels[0]] = cty.ObjectVal(map[string]cty.Value{
+				"config_path": cty.StringVal(dir),
+				"outputs":     l.dependencyOutputs(block, dir, ctx, cfg),
+			})
+
+		case "dependencies":
+			attr, ok := block.Body.Attributes["paths"]
+			if !ok {
+				continue
+			}
+			val := l.value(attr, ctx, &cfg.diags)
+			if !val.IsWhollyKnown() || val.IsNull() || !val.CanIterateElements() {
+				continue
+			}
+			for it := val.ElementIterator(); it.Next(); {
+				_, elem := it.Element()
+				if s, ok := stringValue(elem); ok {
+					cfg.dependencies = append(cfg.dependencies, absPath(sc.unitDir, s))
+				}
+			}
+		}
+	}
+	ctx.Variables["dependency"] = objectOrEmpty(deps)
+}
+
+// dependencyOutputs returns the outputs of the dependency at dir, falling
+// back to mock_outputs for outputs that are unknown or cannot be loaded.
+func (l *loader) dependencyOutputs(block *hclsyntax.Block, dir string, ctx *hcl.EvalContext, cfg *config) cty.Value {
+	outputs := make(map[string]cty.Value)
+	if attr, ok := block.Body.Attributes["mock_outputs"]; ok {
+		outputs = l.objectAttribute(attr, ctx, &cfg.diags)
+	}
+
+	skip := false
+	if attr, ok := block.Body.Attributes["skip_outputs"]; ok {
+		val := l.value(attr, ctx, &cfg.diags)
+		skip = val.IsKnown() && !val.IsNull() && val.Type() == cty.Bool && val.True()
+	}
+	if !skip {
+		real, err := l.moduleOutputs(dir)
+		if err != nil && len(outputs) == 0 {
+			cfg.diags = append(cfg.diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Dependency outputs unavailable",
+				Detail:   fmt.Sprintf("dependency %q: %s", block.Labels[0], err),
+				Subject:  block.DefRange().Ptr(),
+			})
+		}
+		for name, val := range real {
+			if _, mocked := outputs[name]; !mocked || val.IsWhollyKnown() {
+				outputs[name] = val
+			}
+		}
+	}
+
+	if len(outputs) == 0 {
+		return cty.DynamicVal
+	}
+	return cty.ObjectVal(outputs)
+}
+
+// moduleOutputs evaluates the module of the unit at dir with its inputs
+// bound and returns its outputs.
+func (l *loader) moduleOutputs(dir string) (map[string]cty.Value, error) {
+	path := filepath.Join(dir, ConfigFile)
+	if outputs, ok := l.outputs[path]; ok {
+		return outputs, nil
+	}
+	if l.visiting[path] {
+		return nil, fmt.Errorf("dependency cycle through %s", l.relative(dir))
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("%s not found", path)
+	}
+
+	unit := l.unit(path)
+	if unit.SourceDir == "" {
+		return nil, fmt.Errorf("module source of %s not found", l.relative(dir))
+	}
+	opts := *l.opts
+	opts.Inputs = unit.Inputs
+	mod, err := tfconfig.LoadDir(unit.SourceDir, &opts)
+	if err != nil {
+		return nil, err
+	}
+	l.outputs[path] = mod.Outputs
+	return mod.Outputs, nil
+}
+
+// sourceDir resolves the terraform.source of the unit in unitDir to a
+// directory. Local paths may use the "//" subdirectory notation. Remote
+// sources are looked up in the module cache and in .terragrunt-cache.
+func (l *loader) sourceDir(unitDir, source string) (string, error) {
+	if source == "" {
+		if hasTerraformFiles(unitDir) {
+			return unitDir, nil
+		}
+		return "", fmt.Errorf("%s has no terraform source", l.relative(unitDir))
+	}
+
+	base, subdir := splitSubdir(source)
+	if strings.HasPrefix(base, "./") || strings.HasPrefix(base, "../") || filepath.IsAbs(base) {
+		dir := filepath.Join(absPath(unitDir, base), filepath.FromSlash(subdir))
+		if hasTerraformFiles(dir) {
+			return dir, nil
+		}
+		return "", fmt.Errorf("source directory %s not found", dir)
+	}
+
+	if dir, err := tfconfig.SourceDir(unitDir, source, l.opts); err == nil {
+		return dir, nil
+	}
+
+	// terragrunt init downloads sources to .terragrunt-cache/<hash>/<hash>.
+	matches, _ := filepath.Glob(filepath.Join(unitDir, ".terragrunt-cache", "*", "*"))
+	for _, match := range matches {
+		dir := filepath.Join(match, filepath.FromSlash(subdir))
+		if hasTerraformFiles(dir) {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("source %s is not downloaded, run terragrunt init", source)
+}
+
+// context builds the evaluation context for a scope.
+func (l *loader) context(sc scope, vars map[string]cty.Value) *hcl.EvalContext {
+	funcs := tfconfig.Functions(sc.unitDir)
+	for name, fn := range l.functions(sc) {
+		funcs[name] = fn
+	}
+	return &hcl.EvalContext{Variables: vars, Functions: funcs}
+}
+
+// value evaluates an attribute. Attributes that fail to evaluate are unknown.
+func (l *loader) value(attr *hclsyntax.Attribute, ctx *hcl.EvalContext, diags *hcl.Diagnostics) cty.Value {
+	val, d := attr.Expr.Value(ctx)
+	if d.HasErrors() {
+		*diags = append(*diags, d...)
+		return cty.DynamicVal
+	}
+	return val
+}
+
+// objectAttribute evaluates an object attribute such as inputs. Items of
+// an object literal are evaluated one by one so a single failing item
+// does not hide the others.
+func (l *loader) objectAttribute(attr *hclsyntax.Attribute, ctx *hcl.EvalContext, diags *hcl.Diagnostics) map[string]cty.Value {
+	values := make(map[string]cty.Value)
+	if cons, ok := attr.Expr.(*hclsyntax.ObjectConsExpr); ok {
+		for _, item := range cons.Items {
+			key, d := item.KeyExpr.Value(ctx)
+			name, ok := stringValue(key)
+			if d.HasErrors() || !ok {
+				*diags = append(*diags, d...)
+				continue
+			}
+			val, d := item.ValueExpr.Value(ctx)
+			if d.HasErrors() {
+				*diags = append(*diags, d...)
+				val = cty.DynamicVal
+			}
+			values[name] = val
+		}
+		return values
+	}
+
+	val := l.value(attr, ctx, diags)
+	if val.IsWhollyKnown() && !val.IsNull() && (val.Type().IsObjectType() || val.Type().IsMapType()) {
+		for name, v := range val.AsValueMap() {
+			values[name] = v
+		}
+	}
+	return values
+}
+
+// pathAttribute evaluates a path attribute of a block, resolving relative
+// paths against dir.
+func (l *loader) pathAttribute(block *hclsyntax.Block, name, dir string, ctx *hcl.EvalContext, diags *hcl.Diagnostics) (string, bool) {
+	attr, ok := block.Body.Attributes[name]
+	if !ok {
+		*diags = append(*diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Missing attribute",
+			Detail:   fmt.Sprintf("%s block has no %s", block.Type, name),
+			Subject:  block.DefRange().Ptr(),
+		})
+		return "", false
+	}
+	s, ok := stringValue(l.value(attr, ctx, diags))
+	if !ok || s == "" {
+		return "", false
+	}
+	return absPath(dir, s), true
+}
+
+// mergeValues merges child values over parent values. With deep set,
+// nested objects and maps are merged as well.
+func mergeValues(parent, child map[string]cty.Value, deep bool) map[string]cty.Value {
+	merged := make(map[string]cty.Value, len(parent)+len(child))
+	for name, val := range parent {
+		merged[name] = val
+	}
+	for name, val := range child {
+		if prev, ok := merged[name]; ok && deep && isMergeable(prev) && isMergeable(val) {
+			val = cty.ObjectVal(mergeValues(prev.AsValueMap(), val.AsValueMap(), true))
+		}
+		merged[name] = val
+	}
+	return merged
+}
+
+// isMergeable reports whether val is a known, non-empty object or map.
+func isMergeable(val cty.Value) bool {
+	return val.IsWhollyKnown() && !val.IsNull() && val.LengthInt() > 0 &&
+		(val.Type().IsObjectType() || val.Type().IsMapType())
+}
+
+// splitSubdir splits the "//" subdirectory off a module source, dropping
+// any query string from it.
+func splitSubdir(source string) (string, string) {
+	offset := 0
+	if i := strings.Index(source, "://"); i >= 0 {
+		offset = i + 3
+	}
+	i := strings.Index(source[offset:], "//")
+	if i < 0 {
+		return source, ""
+	}
+	base, subdir := source[:offset+i], source[offset+i+2:]
+	if q := strings.Index(subdir, "?"); q >= 0 {
+		base += subdir[q:]
+		subdir = subdir[:q]
+	}
+	return base, strings.Trim(subdir, "/")
+}
+
+// absPath resolves path against dir unless it is absolute.
+func absPath(dir, path string) string {
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// objectOrEmpty returns m as an object value.
+func objectOrEmpty(m map[string]cty.Value) cty.Value {
+	if len(m) == 0 {
+		return cty.EmptyObjectVal
+	}
+	return cty.ObjectVal(m)
+}
+
+// uniqueStrings returns the distinct strings of list in order.
+func uniqueStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	unique := list[:0]
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
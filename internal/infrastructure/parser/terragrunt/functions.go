@@ -0,0 +1,179 @@
+package terragrunt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// unknownFunctions are Terragrunt functions that need credentials or run
+// commands. They evaluate to unknown strings.
+var unknownFunctions = []string{
+	"get_aws_account_id",
+	"get_aws_caller_identity_arn",
+	"get_aws_caller_identity_user_id",
+	"get_terraform_command",
+	"run_cmd",
+	"sops_decrypt_file",
+}
+
+// functions returns the Terragrunt built-in functions for a scope.
+func (l *loader) functions(sc scope) map[string]function.Function {
+	includeDir := sc.includeDir
+	if includeDir == "" {
+		includeDir = sc.unitDir
+	}
+
+	funcs := map[string]function.Function{
+		"find_in_parent_folders": function.New(&function.Spec{
+			VarParam: &function.Parameter{Name: "args", Type: cty.String},
+			Type:     function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				if len(args) > 2 {
+					return cty.NilVal, fmt.Errorf("expected at most 2 arguments, got %d", len(args))
+				}
+				name := ConfigFile
+				if len(args) > 0 {
+					name = args[0].AsString()
+				}
+				if path, ok := findInParentFolders(sc.unitDir, name); ok {
+					return cty.StringVal(path), nil
+				}
+				if len(args) > 1 {
+					return args[1], nil
+				}
+				return cty.NilVal, fmt.Errorf("could not find %s in any parent folder of %s", name, sc.unitDir)
+			},
+		}),
+		"path_relative_to_include":    relativePathFunc(includeDir, sc.unitDir),
+		"path_relative_from_include":  relativePathFunc(sc.unitDir, includeDir),
+		"get_terragrunt_dir":          stringFunc(sc.unitDir),
+		"get_original_terragrunt_dir": stringFunc(sc.unitDir),
+		"get_parent_terragrunt_dir":   stringFunc(includeDir),
+		"get_platform":                stringFunc(runtime.GOOS),
+		"get_repo_root": function.New(&function.Spec{
+			Type: function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				root, ok := repoRoot(sc.unitDir)
+				if !ok {
+					return cty.NilVal, fmt.Errorf("%s is not in a git repository", sc.unitDir)
+				}
+				return cty.StringVal(root), nil
+			},
+		}),
+		"get_path_from_repo_root": function.New(&function.Spec{
+			Type: function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				root, ok := repoRoot(sc.unitDir)
+				if !ok {
+					return cty.NilVal, fmt.Errorf("%s is not in a git repository", sc.unitDir)
+				}
+				return relativePath(root, sc.unitDir), nil
+			},
+		}),
+		"get_env": function.New(&function.Spec{
+			Params:   []function.Parameter{{Name: "name", Type: cty.String}},
+			VarParam: &function.Parameter{Name: "default", Type: cty.String},
+			Type:     function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				if val, ok := os.LookupEnv(args[0].AsString()); ok {
+					return cty.StringVal(val), nil
+				}
+				if len(args) > 1 {
+					return args[1], nil
+				}
+				return cty.StringVal(""), nil
+			},
+		}),
+		"read_terragrunt_config": function.New(&function.Spec{
+			Params:   []function.Parameter{{Name: "path", Type: cty.String}},
+			VarParam: &function.Parameter{Name: "default", Type: cty.DynamicPseudoType},
+			Type:     function.StaticReturnType(cty.DynamicPseudoType),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				path := absPath(sc.unitDir, args[0].AsString())
+				if _, err := os.Stat(path); err != nil {
+					if len(args) > 1 {
+						return args[1], nil
+					}
+					return cty.NilVal, fmt.Errorf("%s not found", path)
+				}
+				dir := filepath.Dir(path)
+				cfg := l.evaluate(path, scope{unitDir: dir, configDir: dir})
+				return cty.ObjectVal(map[string]cty.Value{
+					"locals": objectOrEmpty(cfg.locals),
+					"inputs": objectOrEmpty(cfg.inputs),
+				}), nil
+			},
+		}),
+	}
+
+	for _, name := range unknownFunctions {
+		funcs[name] = function.New(&function.Spec{
+			VarParam: &function.Parameter{Name: "args", Type: cty.DynamicPseudoType, AllowUnknown: true, AllowNull: true},
+			Type:     function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				return cty.UnknownVal(cty.String), nil
+			},
+		})
+	}
+	return funcs
+}
+
+// stringFunc returns a function without arguments returning s.
+func stringFunc(s string) function.Function {
+	return function.New(&function.Spec{
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal(s), nil
+		},
+	})
+}
+
+// relativePathFunc returns a function without arguments returning the path
+// of target relative to base.
+func relativePathFunc(base, target string) function.Function {
+	return function.New(&function.Spec{
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return relativePath(base, target), nil
+		},
+	})
+}
+
+// relativePath returns target relative to base as a slash separated string.
+func relativePath(base, target string) cty.Value {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return cty.StringVal(filepath.ToSlash(target))
+	}
+	return cty.StringVal(filepath.ToSlash(rel))
+}
+
+// findInParentFolders looks for name in the parent directories of dir.
+func findInParentFolders(dir, name string) (string, bool) {
+	for current := filepath.Dir(dir); ; current = filepath.Dir(current) {
+		path := filepath.Join(current, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		if current == filepath.Dir(current) {
+			return "", false
+		}
+	}
+}
+
+// repoRoot returns the closest directory at or above dir containing .git.
+func repoRoot(dir string) (string, bool) {
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current, true
+		}
+		if current == filepath.Dir(current) {
+			return "", false
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package terragrunt discovers and evaluates Terragrunt projects.
+//
+// Every terragrunt.hcl that is not included by another configuration is a
+// unit. Units are evaluated the way Terragrunt would: include blocks
+// (find_in_parent_folders and friends) are merged in, locals are resolved,
+// dependency blocks are filled with the outputs of the dependency's module
+// or its mock_outputs, and terraform.source is resolved to a directory on
+// disk so the module can be handed to tfconfig with the unit inputs bound.
+package terragrunt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/homeport/homeport/internal/infrastructure/parser/tfconfig"
+)
+
+// ConfigFile is the name of a Terragrunt configuration file.
+const ConfigFile = "terragrunt.hcl"
+
+// groupingDirs are path segments that organize a live repository without
+// naming an environment.
+var groupingDirs = map[string]bool{
+	"live":           true,
+	"envs":           true,
+	"environments":   true,
+	"infra":          true,
+	"infrastructure": true,
+	"terragrunt":     true,
+}
+
+// Unit is an evaluated Terragrunt unit.
+type Unit struct {
+	// Path is the path of the unit's terragrunt.hcl.
+	Path string
+
+	// Dir is the directory of the unit.
+	Dir string
+
+	// RelDir is Dir relative to the project root, slash separated.
+	RelDir string
+
+	// Source is terraform.source after merging includes. It is empty when
+	// the unit holds its Terraform configuration itself.
+	Source string
+
+	// SourceDir is the directory the Terraform module is loaded from, or ""
+	// when the source could not be found on disk.
+	SourceDir string
+
+	// Inputs are the merged inputs passed to the module as variables.
+	Inputs map[string]cty.Value
+
+	// Locals holds the unit's own evaluated locals.
+	Locals map[string]cty.Value
+
+	// Dependencies are the directories of the units named in dependency and
+	// dependencies blocks, relative to the project root.
+	Dependencies []string
+
+	// Environment is the environment the unit belongs to, taken from an
+	// environment/env local or input, or else from the directory layout.
+	Environment string
+
+	// Diagnostics collects non-fatal evaluation problems.
+	Diagnostics hcl.Diagnostics
+}
+
+// Discover returns the terragrunt.hcl files below root in lexical order.
+// Hidden directories and .terragrunt-cache are skipped.
+func Discover(root string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() == ConfigFile {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// IsProject reports whether path is a terragrunt.hcl file or a directory
+// containing one.
+func IsProject(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() {
+		return info.Name() == ConfigFile
+	}
+	files, err := Discover(path)
+	return err == nil && len(files) > 0
+}
+
+// Load discovers and evaluates the units below root. Configurations that
+// are only included by others (the usual root terragrunt.hcl) are not
+// units. opts configures how dependency modules are evaluated for their
+// outputs; it may be nil.
+func Load(root string, opts *tfconfig.Options) ([]*Unit, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := Discover(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s files found in %s", ConfigFile, root)
+	}
+
+	l := newLoader(root, opts)
+	included := make(map[string]bool)
+	for _, file := range files {
+		cfg := l.config(file)
+		for _, inc := range cfg.includes {
+			included[inc] = true
+		}
+	}
+
+	var units []*Unit
+	for _, file := range files {
+		if included[file] {
+			continue
+		}
+		unit := l.unit(file)
+		if unit.Source == "" && !hasTerraformFiles(unit.Dir) {
+			continue
+		}
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+// environment determines the environment of a unit.
+func environment(unit *Unit) string {
+	for _, values := range []map[string]cty.Value{unit.Locals, unit.Inputs} {
+		for _, name := range []string{"environment", "env"} {
+			if s, ok := stringValue(values[name]); ok && s != "" {
+				return s
+			}
+		}
+	}
+
+	// The last segment names the unit itself.
+	segments := strings.Split(unit.RelDir, "/")
+	for _, segment := range segments[:len(segments)-1] {
+		if segment == "" || groupingDirs[segment] {
+			continue
+		}
+		return segment
+	}
+	return ""
+}
+
+// hasTerraformFiles reports whether dir holds Terraform configuration files.
+func hasTerraformFiles(dir string) bool {
+	files, err := tfconfig.ConfigFiles(dir)
+	return err == nil && len(files) > 0
+}
+
+// stringValue returns the Go string of a known, non-null string value.
+func stringValue(val cty.Value) (string, bool) {
+	if val == cty.NilVal || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
@@ -0,0 +1,213 @@
+package terragrunt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+var testProject = map[string]string{
+	"terragrunt.hcl": `
+locals {
+  project = "shop"
+}
+inputs = {
+  project   = local.project
+  state_key = "${path_relative_to_include()}/terraform.tfstate"
+}
+`,
+	"modules/vpc/main.tf": `
+variable "project" {}
+variable "cidr" {}
+resource "aws_vpc" "main" {
+  cidr_block = var.cidr
+}
+output "name" {
+  value = "${var.project}-vpc"
+}
+`,
+	"modules/db/main.tf": `
+variable "project" {}
+variable "env" {}
+variable "vpc_name" {}
+resource "aws_db_instance" "main" {
+  identifier = "${var.project}-${var.env}-db"
+}
+`,
+	"live/prod/env.hcl": `
+locals {
+  environment = "prod"
+}
+`,
+	"live/prod/vpc/terragrunt.hcl": `
+include "root" {
+  path = find_in_parent_folders()
+}
+terraform {
+  source = "../../../modules//vpc"
+}
+inputs = {
+  cidr = "10.0.0.0/16"
+}
+`,
+	"live/prod/db/terragrunt.hcl": `
+include {
+  path = find_in_parent_folders()
+}
+locals {
+  env_vars = read_terragrunt_config(find_in_parent_folders("env.hcl"))
+  env      = local.env_vars.locals.environment
+}
+terraform {
+  source = "${get_parent_terragrunt_dir()}/modules//db"
+}
+dependency "vpc" {
+  config_path  = "../vpc"
+  mock_outputs = {
+    name = "mock-vpc"
+  }
+}
+inputs = {
+  env      = local.env
+  vpc_name = dependency.vpc.outputs.name
+  owner    = include.locals.project
+}
+`,
+	"live/staging/vpc/terragrunt.hcl": `
+include "root" {
+  path = find_in_parent_folders()
+}
+terraform {
+  source = "${get_parent_terragrunt_dir()}/modules//vpc"
+}
+inputs = {
+  project = "shop-staging"
+  cidr    = "10.1.0.0/16"
+}
+`,
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, testProject)
+
+	units, err := Load(dir, nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(units) != 3 {
+		t.Fatalf("expected 3 units, got %d", len(units))
+	}
+	byDir := make(map[string]*Unit)
+	for _, unit := range units {
+		byDir[unit.RelDir] = unit
+	}
+
+	db := byDir["live/prod/db"]
+	if db == nil {
+		t.Fatalf("missing live/prod/db, got %v", byDir)
+	}
+	if db.SourceDir != filepath.Join(dir, "modules", "db") {
+		t.Errorf("SourceDir = %q", db.SourceDir)
+	}
+	if db.Environment != "prod" {
+		t.Errorf("Environment = %q, want prod", db.Environment)
+	}
+	if len(db.Dependencies) != 1 || db.Dependencies[0] != "live/prod/vpc" {
+		t.Errorf("Dependencies = %v", db.Dependencies)
+	}
+
+	wantInputs := map[string]string{
+		"project":   "shop",
+		"env":       "prod",
+		"vpc_name":  "shop-vpc",
+		"owner":     "shop",
+		"state_key": "live/prod/db/terraform.tfstate",
+	}
+	for name, want := range wantInputs {
+		if got, ok := stringValue(db.Inputs[name]); !ok || got != want {
+			t.Errorf("input %s = %#v, want %q", name, db.Inputs[name], want)
+		}
+	}
+
+	staging := byDir["live/staging/vpc"]
+	if staging == nil {
+		t.Fatal("missing live/staging/vpc")
+	}
+	if staging.Environment != "staging" {
+		t.Errorf("Environment = %q, want staging", staging.Environment)
+	}
+	if !staging.Inputs["project"].RawEquals(cty.StringVal("shop-staging")) {
+		t.Errorf("child input should override the root, got %#v", staging.Inputs["project"])
+	}
+}
+
+func TestLoad_MockOutputs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app/main.tf": `variable "vpc_id" {}`,
+		"app/terragrunt.hcl": `
+dependency "vpc" {
+  config_path  = "../vpc"
+  mock_outputs = {
+    vpc_id = "vpc-mock"
+  }
+}
+inputs = {
+  vpc_id = dependency.vpc.outputs.vpc_id
+}
+`,
+		"vpc/terragrunt.hcl": `
+terraform {
+  source = "git::https://example.com/modules.git//vpc?ref=v1.0.0"
+}
+`,
+	})
+
+	units, err := Load(dir, nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(units) != 2 {
+		t.Fatalf("expected 2 units, got %d", len(units))
+	}
+	app := units[0]
+	if got, _ := stringValue(app.Inputs["vpc_id"]); got != "vpc-mock" {
+		t.Errorf("vpc_id = %#v, want vpc-mock", app.Inputs["vpc_id"])
+	}
+	if units[1].SourceDir != "" || len(units[1].Diagnostics) == 0 {
+		t.Errorf("expected an undownloaded source to be reported, got %q", units[1].SourceDir)
+	}
+}
+
+func TestSplitSubdir(t *testing.T) {
+	tests := []struct {
+		source, base, subdir string
+	}{
+		{"../../modules//vpc", "../../modules", "vpc"},
+		{"git::https://example.com/m.git//db?ref=v1", "git::https://example.com/m.git?ref=v1", "db"},
+		{"tfr:///terraform-aws-modules/vpc/aws?version=5.0.0", "tfr:///terraform-aws-modules/vpc/aws?version=5.0.0", ""},
+		{"./local", "./local", ""},
+	}
+	for _, tt := range tests {
+		base, subdir := splitSubdir(tt.source)
+		if base != tt.base || subdir != tt.subdir {
+			t.Errorf("splitSubdir(%q) = %q, %q", tt.source, base, subdir)
+		}
+	}
+}
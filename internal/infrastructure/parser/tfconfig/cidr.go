@@ -0,0 +1,172 @@
+package tfconfig
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+var cidrHostFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "hostnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		hostnum, _ := args[1].AsBigFloat().Int(nil)
+
+		ones, bits := network.Mask.Size()
+		hostBits := uint(bits - ones)
+		limit := new(big.Int).Lsh(big.NewInt(1), hostBits)
+		if hostnum.Sign() < 0 {
+			hostnum.Add(hostnum, limit)
+		}
+		if hostnum.Sign() < 0 || hostnum.Cmp(limit) >= 0 {
+			return cty.UnknownVal(cty.String), fmt.Errorf("prefix of %d does not accommodate a host numbered %s", ones, args[1].AsBigFloat().String())
+		}
+
+		ip := ipFromInt(new(big.Int).Or(ipToInt(network.IP), hostnum), len(network.IP))
+		return cty.StringVal(ip.String()), nil
+	},
+})
+
+var cidrNetmaskFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		if len(network.IP) != net.IPv4len {
+			return cty.UnknownVal(cty.String), fmt.Errorf("IPv6 addresses cannot have a netmask: %s", args[0].AsString())
+		}
+		return cty.StringVal(net.IP(network.Mask).String()), nil
+	},
+})
+
+var cidrSubnetFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "newbits", Type: cty.Number},
+		{Name: "netnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		var newbits int
+		if err := gocty.FromCtyValue(args[1], &newbits); err != nil {
+			return cty.UnknownVal(cty.String), function.NewArgError(1, err)
+		}
+		netnum, _ := args[2].AsBigFloat().Int(nil)
+
+		subnet, err := subnetAt(network, newbits, netnum)
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		return cty.StringVal(subnet.String()), nil
+	},
+})
+
+var cidrSubnetsFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+	},
+	VarParam: &function.Parameter{Name: "newbits", Type: cty.Number},
+	Type:     function.StaticReturnType(cty.List(cty.String)),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(retType), err
+		}
+		if len(args) == 1 {
+			return cty.ListValEmpty(cty.String), nil
+		}
+
+		ones, bits := network.Mask.Size()
+		total := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+		offset := new(big.Int)
+		base := ipToInt(network.IP)
+
+		subnets := make([]cty.Value, 0, len(args)-1)
+		for i, arg := range args[1:] {
+			var newbits int
+			if err := gocty.FromCtyValue(arg, &newbits); err != nil {
+				return cty.UnknownVal(retType), function.NewArgError(i+1, err)
+			}
+			if newbits < 1 || ones+newbits > bits {
+				return cty.UnknownVal(retType), function.NewArgErrorf(i+1, "would extend prefix to %d bits, which is too long for an address of %d bits", ones+newbits, bits)
+			}
+
+			// Align the offset to the size of the requested subnet.
+			size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones-newbits))
+			rem := new(big.Int).Mod(offset, size)
+			if rem.Sign() != 0 {
+				offset.Add(offset, new(big.Int).Sub(size, rem))
+			}
+			if new(big.Int).Add(offset, size).Cmp(total) > 0 {
+				return cty.UnknownVal(retType), function.NewArgErrorf(i+1, "not enough remaining address space for a subnet with a prefix of %d bits", ones+newbits)
+			}
+
+			ip := ipFromInt(new(big.Int).Add(base, offset), len(network.IP))
+			subnet := &net.IPNet{IP: ip, Mask: net.CIDRMask(ones+newbits, bits)}
+			subnets = append(subnets, cty.StringVal(subnet.String()))
+			offset.Add(offset, size)
+		}
+		return cty.ListVal(subnets), nil
+	},
+})
+
+// parseCIDR parses a CIDR prefix, normalizing IPv4 addresses to 4 bytes.
+func parseCIDR(prefix string) (*net.IPNet, error) {
+	_, network, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR expression: %w", err)
+	}
+	if ip4 := network.IP.To4(); ip4 != nil {
+		network.IP = ip4
+	}
+	return network, nil
+}
+
+// subnetAt returns the netnum-th subnet of network extended by newbits.
+func subnetAt(network *net.IPNet, newbits int, netnum *big.Int) (*net.IPNet, error) {
+	ones, bits := network.Mask.Size()
+	if newbits < 0 || ones+newbits > bits {
+		return nil, fmt.Errorf("insufficient address space to extend prefix of %d by %d", ones, newbits)
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(newbits))
+	if netnum.Sign() < 0 || netnum.Cmp(limit) >= 0 {
+		return nil, fmt.Errorf("prefix extension of %d does not accommodate a subnet numbered %s", newbits, netnum.String())
+	}
+
+	shifted := new(big.Int).Lsh(netnum, uint(bits-ones-newbits))
+	ip := ipFromInt(new(big.Int).Or(ipToInt(network.IP), shifted), len(network.IP))
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(ones+newbits, bits)}, nil
+}
+
+// ipToInt converts an IP address to an integer.
+func ipToInt(ip net.IP) *big.Int {
+	return new(big.Int).SetBytes(ip)
+}
+
+// ipFromInt converts an integer back into an IP address of the given length.
+func ipFromInt(n *big.Int, length int) net.IP {
+	raw := n.Bytes()
+	ip := make(net.IP, length)
+	copy(ip[length-len(raw):], raw)
+	return ip
+}
@@ -0,0 +1,97 @@
+package tfconfig
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ToInterface converts a cty.Value to a plain Go value. Unknown values become
+// nil; unknown collection elements and attributes are dropped.
+func ToInterface(val cty.Value) interface{} {
+	if val.IsNull() || !val.IsKnown() {
+		return nil
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return val.AsString()
+	case ty == cty.Number:
+		f, _ := val.AsBigFloat().Float64()
+		return f
+	case ty == cty.Bool:
+		return val.True()
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		var result []interface{}
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if !v.IsKnown() {
+				continue
+			}
+			result = append(result, ToInterface(v))
+		}
+		return result
+	case ty.IsMapType() || ty.IsObjectType():
+		result := make(map[string]interface{})
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			if !v.IsKnown() {
+				continue
+			}
+			result[k.AsString()] = ToInterface(v)
+		}
+		return result
+	default:
+		return val.GoString()
+	}
+}
+
+// FromInterface converts a decoded JSON or YAML value into a cty.Value.
+func FromInterface(v interface{}) cty.Value {
+	switch t := v.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType)
+	case string:
+		return cty.StringVal(t)
+	case bool:
+		return cty.BoolVal(t)
+	case int:
+		return cty.NumberIntVal(int64(t))
+	case int64:
+		return cty.NumberIntVal(t)
+	case uint64:
+		return cty.NumberUIntVal(t)
+	case float64:
+		return cty.NumberFloatVal(t)
+	case *big.Float:
+		return cty.NumberVal(t)
+	case []interface{}:
+		if len(t) == 0 {
+			return cty.EmptyTupleVal
+		}
+		elems := make([]cty.Value, len(t))
+		for i, e := range t {
+			elems[i] = FromInterface(e)
+		}
+		return cty.TupleVal(elems)
+	case map[string]interface{}:
+		if len(t) == 0 {
+			return cty.EmptyObjectVal
+		}
+		attrs := make(map[string]cty.Value, len(t))
+		for k, e := range t {
+			attrs[k] = FromInterface(e)
+		}
+		return cty.ObjectVal(attrs)
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			converted[fmt.Sprint(k)] = e
+		}
+		return FromInterface(converted)
+	default:
+		return cty.StringVal(fmt.Sprint(t))
+	}
+}
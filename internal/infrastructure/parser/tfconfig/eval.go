@@ -0,0 +1,871 @@
+package tfconfig
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// metaArguments are resource arguments interpreted by Terraform itself rather
+// than the provider.
+var metaArguments = map[string]bool{
+	"count":      true,
+	"for_each":   true,
+	"depends_on": true,
+	"provider":   true,
+}
+
+// builtinRoots are traversal roots that never refer to a resource.
+var builtinRoots = map[string]bool{
+	"var":       true,
+	"local":     true,
+	"path":      true,
+	"terraform": true,
+	"module":    true,
+	"count":     true,
+	"each":      true,
+	"self":      true,
+}
+
+// computedAttributes are always present on resource values so that common
+// references like aws_instance.web.id resolve (to unknown) instead of failing.
+var computedAttributes = []string{"id", "arn"}
+
+// resourceDecl is a declared resource or data block awaiting evaluation.
+type resourceDecl struct {
+	mode  string
+	typ   string
+	name  string
+	block *hcl.Block
+
+	// refs are the resources this block references, directly or through
+	// local values.
+	refs []reference
+
+	// referencedAttrs are attribute names other blocks access on this resource.
+	referencedAttrs map[string]bool
+}
+
+// reference is a reference to a resource, optionally to a single instance.
+// Module outputs (module.<name>) and, inside child modules, input variables
+// (var.<name>) are references too.
+type reference struct {
+	addr string
+
+	// key is the literal instance key used in the reference, or cty.NilVal
+	// when the whole resource is referenced.
+	key cty.Value
+
+	// output is the module output accessed by a module reference.
+	output string
+}
+
+// id returns a string identifying the reference for deduplication.
+func (r reference) id() string {
+	if r.output != "" {
+		return instanceAddress(r.addr, r.key) + "." + r.output
+	}
+	return instanceAddress(r.addr, r.key)
+}
+
+// address returns the Terraform address of the declaration.
+func (d *resourceDecl) address() string {
+	if d.mode == ModeData {
+		return "data." + d.typ + "." + d.name
+	}
+	return d.typ + "." + d.name
+}
+
+// evaluator resolves locals and resources of a single module in dependency order.
+type evaluator struct {
+	dir       string
+	rootDir   string
+	files     []string
+	workspace string
+	funcs     map[string]function.Function
+	opts      *Options
+
+	// prefix is prepended to instance addresses, e.g. "module.db.".
+	prefix string
+
+	// callPath is the chain of module call names leading to this module,
+	// used to look modules up in .terraform/modules/modules.json.
+	callPath []string
+
+	// key is the instance key of the module call that created this module.
+	key cty.Value
+
+	manifest *moduleManifest
+
+	vars       map[string]cty.Value
+	localExprs map[string]hcl.Expression
+	locals     map[string]cty.Value
+	localRefs  map[string][]reference
+
+	decls     []*resourceDecl
+	declIndex map[string]*resourceDecl
+	values    map[string]cty.Value
+	expanded  map[string][]*ResourceInstance
+	instances []*ResourceInstance
+
+	calls       []*moduleCall
+	callIndex   map[string]*moduleCall
+	outputExprs map[string]hcl.Expression
+	outputs     map[string]cty.Value
+
+	// inputDeps maps the input variables of a child module to the parent
+	// resource instances their arguments depend on; callDeps lists those
+	// named in the module block's depends_on.
+	inputDeps map[string][]string
+	callDeps  []string
+
+	// visiting tracks in-progress evaluations to detect reference cycles.
+	visiting map[string]bool
+
+	diags hcl.Diagnostics
+}
+
+// newEvaluator creates an evaluator for a root module in dir.
+func newEvaluator(dir string, files []string, opts *Options) *evaluator {
+	workspace := opts.Workspace
+	if workspace == "" {
+		workspace = "default"
+	}
+	return &evaluator{
+		dir:         dir,
+		rootDir:     dir,
+		files:       files,
+		workspace:   workspace,
+		funcs:       Functions(dir),
+		opts:        opts,
+		key:         cty.NilVal,
+		manifest:    &moduleManifest{},
+		vars:        make(map[string]cty.Value),
+		localExprs:  make(map[string]hcl.Expression),
+		locals:      make(map[string]cty.Value),
+		localRefs:   make(map[string][]reference),
+		declIndex:   make(map[string]*resourceDecl),
+		values:      make(map[string]cty.Value),
+		expanded:    make(map[string][]*ResourceInstance),
+		callIndex:   make(map[string]*moduleCall),
+		outputExprs: make(map[string]hcl.Expression),
+		outputs:     make(map[string]cty.Value),
+		visiting:    make(map[string]bool),
+	}
+}
+
+// run registers the blocks of a module and evaluates them.
+func (e *evaluator) run(blocks hcl.Blocks) {
+	for _, block := range blocks.OfType("locals") {
+		attrs, diags := block.Body.JustAttributes()
+		e.diags = append(e.diags, diags...)
+		for name, attr := range attrs {
+			e.localExprs[name] = attr.Expr
+		}
+	}
+
+	for _, block := range blocks {
+		switch block.Type {
+		case "resource":
+			e.addResource(ModeManaged, block)
+		case "data":
+			e.addResource(ModeData, block)
+		case "module":
+			e.addModuleCall(block)
+		case "output":
+			e.addOutput(block)
+		}
+	}
+
+	e.evaluate()
+}
+
+// module returns the evaluated module.
+func (e *evaluator) module() *Module {
+	mod := &Module{
+		Dir:         e.dir,
+		Path:        strings.TrimSuffix(e.prefix, "."),
+		Files:       e.files,
+		Variables:   e.vars,
+		Locals:      e.locals,
+		Outputs:     e.outputs,
+		Resources:   e.instances,
+		Diagnostics: e.diags,
+	}
+	for _, call := range e.calls {
+		for _, child := range call.children {
+			mod.Children = append(mod.Children, child.module())
+		}
+	}
+	return mod
+}
+
+// addResource registers a resource or data block.
+func (e *evaluator) addResource(mode string, block *hcl.Block) {
+	if len(block.Labels) < 2 {
+		return
+	}
+	decl := &resourceDecl{
+		mode:            mode,
+		typ:             block.Labels[0],
+		name:            block.Labels[1],
+		block:           block,
+		referencedAttrs: make(map[string]bool),
+	}
+	if _, exists := e.declIndex[decl.address()]; exists {
+		return
+	}
+	e.decls = append(e.decls, decl)
+	e.declIndex[decl.address()] = decl
+}
+
+// evaluate resolves all locals, resources, module calls and outputs.
+func (e *evaluator) evaluate() {
+	// Record which attributes are referenced on each resource and which
+	// resources each block depends on.
+	for _, decl := range e.decls {
+		for _, ref := range e.references(bodyTraversals(decl.block.Body)) {
+			if ref.addr != decl.address() {
+				decl.refs = appendReference(decl.refs, ref)
+			}
+		}
+	}
+	for _, expr := range e.localExprs {
+		e.references(expr.Variables())
+	}
+
+	names := make([]string, 0, len(e.localExprs))
+	for name := range e.localExprs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e.resolveLocal(name)
+	}
+
+	for _, decl := range e.decls {
+		e.resolveResource(decl)
+	}
+	for _, call := range e.calls {
+		e.resolveModule(call)
+	}
+	for _, decl := range e.decls {
+		addr := e.prefix + decl.address()
+		visiting := map[string]bool{decl.address(): true}
+		var deps []string
+		for _, dep := range e.dependencies(decl.refs, visiting) {
+			if dep != addr && !strings.HasPrefix(dep, addr+"[") {
+				deps = append(deps, dep)
+			}
+		}
+		deps = mergeDependencies(deps, e.callDeps)
+		for _, inst := range e.expanded[decl.address()] {
+			inst.Dependencies = deps
+		}
+		e.instances = append(e.instances, e.expanded[decl.address()]...)
+	}
+	for _, call := range e.calls {
+		for _, child := range call.children {
+			e.instances = append(e.instances, child.instances...)
+		}
+	}
+
+	names = names[:0]
+	for name := range e.outputExprs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		expr := e.outputExprs[name]
+		e.resolveDependencies(expr.Variables())
+		val, diags := expr.Value(e.context(nil))
+		if diags.HasErrors() {
+			val = cty.DynamicVal
+		}
+		e.outputs[name] = val
+	}
+}
+
+// references returns the resources referenced by traversals, including those
+// reached through local values, and records the attributes accessed on them.
+func (e *evaluator) references(traversals []hcl.Traversal) []reference {
+	var refs []reference
+	for _, traversal := range traversals {
+		switch traversal.RootName() {
+		case "local":
+			if len(traversal) > 1 {
+				if attr, ok := traversal[1].(hcl.TraverseAttr); ok {
+					for _, ref := range e.localReferences(attr.Name) {
+						refs = appendReference(refs, ref)
+					}
+				}
+			}
+			continue
+		case "var":
+			if len(traversal) > 1 {
+				if attr, ok := traversal[1].(hcl.TraverseAttr); ok && len(e.inputDeps[attr.Name]) > 0 {
+					refs = appendReference(refs, reference{addr: "var." + attr.Name, key: cty.NilVal})
+				}
+			}
+			continue
+		case "module":
+			name, key, output := moduleReference(traversal)
+			if _, ok := e.callIndex[name]; ok {
+				refs = appendReference(refs, reference{addr: "module." + name, key: key, output: output})
+			}
+			continue
+		}
+
+		addr, key, attr := resourceReference(traversal)
+		target, ok := e.declIndex[addr]
+		if addr == "" || !ok {
+			continue
+		}
+		if attr != "" {
+			target.referencedAttrs[attr] = true
+		}
+		refs = appendReference(refs, reference{addr: addr, key: key})
+	}
+	return refs
+}
+
+// localReferences returns the resources a local value refers to, directly or
+// through other locals.
+func (e *evaluator) localReferences(name string) []reference {
+	if refs, ok := e.localRefs[name]; ok {
+		return refs
+	}
+	expr, ok := e.localExprs[name]
+	if !ok {
+		return nil
+	}
+
+	// Guard against cycles between locals while this one is being resolved.
+	e.localRefs[name] = nil
+	refs := e.references(expr.Variables())
+	e.localRefs[name] = refs
+	return refs
+}
+
+// dependencies returns the addresses of the managed resource instances refs
+// resolve to. References to data sources are followed to the managed
+// resources they read from, and module references to the resources behind
+// the module output.
+func (e *evaluator) dependencies(refs []reference, visiting map[string]bool) []string {
+	var deps []string
+	seen := make(map[string]bool)
+	add := func(addr string) {
+		if !seen[addr] {
+			seen[addr] = true
+			deps = append(deps, addr)
+		}
+	}
+
+	for _, ref := range refs {
+		switch {
+		case strings.HasPrefix(ref.addr, "var."):
+			for _, dep := range e.inputDeps[strings.TrimPrefix(ref.addr, "var.")] {
+				add(dep)
+			}
+			continue
+		case strings.HasPrefix(ref.addr, "module."):
+			for _, dep := range e.callIndex[strings.TrimPrefix(ref.addr, "module.")].dependencies(ref.key, ref.output) {
+				add(dep)
+			}
+			continue
+		}
+
+		target := e.declIndex[ref.addr]
+		if target.mode == ModeData {
+			if !visiting[target.address()] {
+				visiting[target.address()] = true
+				for _, dep := range e.dependencies(target.refs, visiting) {
+					add(dep)
+				}
+			}
+			continue
+		}
+
+		instances := e.expanded[ref.addr]
+		matched := false
+		if ref.key != cty.NilVal {
+			want := e.prefix + instanceAddress(ref.addr, ref.key)
+			for _, inst := range instances {
+				if inst.Address == want {
+					add(inst.Address)
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			for _, inst := range instances {
+				add(inst.Address)
+			}
+		}
+	}
+
+	sort.Strings(deps)
+	return deps
+}
+
+// mergeDependencies adds extra to deps, keeping the result sorted and unique.
+func mergeDependencies(deps, extra []string) []string {
+	if len(extra) == 0 {
+		return deps
+	}
+	seen := make(map[string]bool, len(deps)+len(extra))
+	merged := make([]string, 0, len(deps)+len(extra))
+	for _, dep := range append(deps, extra...) {
+		if !seen[dep] {
+			seen[dep] = true
+			merged = append(merged, dep)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// appendReference appends ref unless an identical reference is present.
+func appendReference(refs []reference, ref reference) []reference {
+	for _, existing := range refs {
+		if existing.id() == ref.id() {
+			return refs
+		}
+	}
+	return append(refs, ref)
+}
+
+// resolveLocal evaluates a local value after its dependencies.
+func (e *evaluator) resolveLocal(name string) cty.Value {
+	if val, ok := e.locals[name]; ok {
+		return val
+	}
+	expr, ok := e.localExprs[name]
+	if !ok {
+		return cty.DynamicVal
+	}
+
+	key := "local." + name
+	if e.visiting[key] {
+		e.diags = append(e.diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Cycle in local values",
+			Detail:   fmt.Sprintf("local.%s refers to itself", name),
+			Subject:  expr.Range().Ptr(),
+		})
+		return cty.DynamicVal
+	}
+	e.visiting[key] = true
+	defer delete(e.visiting, key)
+
+	e.resolveDependencies(expr.Variables())
+
+	val, diags := expr.Value(e.context(nil))
+	if diags.HasErrors() {
+		e.diags = append(e.diags, diags...)
+		val = cty.DynamicVal
+	}
+	e.locals[name] = val
+	return val
+}
+
+// resolveDependencies makes sure every local, resource and module call in
+// the traversals has been evaluated.
+func (e *evaluator) resolveDependencies(traversals []hcl.Traversal) {
+	for _, traversal := range traversals {
+		if traversal.RootName() == "local" && len(traversal) > 1 {
+			if attr, ok := traversal[1].(hcl.TraverseAttr); ok {
+				e.resolveLocal(attr.Name)
+			}
+			continue
+		}
+		if traversal.RootName() == "module" {
+			if name, _, _ := moduleReference(traversal); name != "" {
+				if call, ok := e.callIndex[name]; ok {
+					e.resolveModule(call)
+				}
+			}
+			continue
+		}
+		if addr, _, _ := resourceReference(traversal); addr != "" {
+			if decl, ok := e.declIndex[addr]; ok {
+				e.resolveResource(decl)
+			}
+		}
+	}
+}
+
+// resolveResource evaluates and expands a resource after its dependencies.
+func (e *evaluator) resolveResource(decl *resourceDecl) {
+	addr := decl.address()
+	if _, ok := e.values[addr]; ok {
+		return
+	}
+	if e.visiting[addr] {
+		e.values[addr] = cty.DynamicVal
+		return
+	}
+	e.visiting[addr] = true
+	defer delete(e.visiting, addr)
+
+	e.resolveDependencies(bodyTraversals(decl.block.Body))
+
+	attrs, _ := decl.block.Body.JustAttributes()
+
+	reps, kind, ok := e.expand(attrs)
+	instances := make([]*ResourceInstance, 0, len(reps))
+	values := make([]cty.Value, 0, len(reps))
+	for _, rep := range reps {
+		inst := e.instance(decl, attrs, rep.key, rep.extra)
+		instances = append(instances, inst)
+		values = append(values, e.instanceValue(decl, inst))
+	}
+
+	value := cty.DynamicVal
+	if ok {
+		value = collect(kind, reps, values)
+	}
+
+	e.values[addr] = value
+	e.expanded[addr] = instances
+}
+
+// repetition is one count or for_each repetition of a block.
+type repetition struct {
+	// key is the instance key, or cty.NilVal for blocks without count or
+	// for_each and for repetitions whose key is not known.
+	key cty.Value
+
+	// extra holds the count or each object visible inside the block.
+	extra map[string]cty.Value
+}
+
+// expand evaluates the count or for_each argument in attrs and returns the
+// repetitions of the block along with which of the two was used ("" for
+// neither). When the value is not known, ok is false and a single
+// repetition with unknown count.index or each values is returned.
+func (e *evaluator) expand(attrs hcl.Attributes) ([]repetition, string, bool) {
+	switch {
+	case attrs["count"] != nil:
+		countVal, diags := attrs["count"].Expr.Value(e.context(nil))
+		count, ok := countValue(countVal, diags)
+		if !ok {
+			e.diags = append(e.diags, diags...)
+			return []repetition{{
+				key: cty.NilVal,
+				extra: map[string]cty.Value{
+					"count": cty.ObjectVal(map[string]cty.Value{"index": cty.UnknownVal(cty.Number)}),
+				},
+			}}, "count", false
+		}
+		reps := make([]repetition, 0, count)
+		for i := 0; i < count; i++ {
+			key := cty.NumberIntVal(int64(i))
+			reps = append(reps, repetition{
+				key: key,
+				extra: map[string]cty.Value{
+					"count": cty.ObjectVal(map[string]cty.Value{"index": key}),
+				},
+			})
+		}
+		return reps, "count", true
+
+	case attrs["for_each"] != nil:
+		eachVal, diags := attrs["for_each"].Expr.Value(e.context(nil))
+		items, ok := forEachItems(eachVal, diags)
+		if !ok {
+			e.diags = append(e.diags, diags...)
+			return []repetition{{
+				key: cty.NilVal,
+				extra: map[string]cty.Value{
+					"each": cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.UnknownVal(cty.String),
+						"value": cty.DynamicVal,
+					}),
+				},
+			}}, "for_each", false
+		}
+		keys := make([]string, 0, len(items))
+		for k := range items {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		reps := make([]repetition, 0, len(keys))
+		for _, k := range keys {
+			key := cty.StringVal(k)
+			reps = append(reps, repetition{
+				key: key,
+				extra: map[string]cty.Value{
+					"each": cty.ObjectVal(map[string]cty.Value{
+						"key":   key,
+						"value": items[k],
+					}),
+				},
+			})
+		}
+		return reps, "for_each", true
+
+	default:
+		return []repetition{{key: cty.NilVal}}, "", true
+	}
+}
+
+// collect combines the values of the repetitions of a block into the value
+// other expressions see: a tuple for count, an object for for_each, or the
+// single value.
+func collect(kind string, reps []repetition, values []cty.Value) cty.Value {
+	switch kind {
+	case "count":
+		return cty.TupleVal(values)
+	case "for_each":
+		elems := make(map[string]cty.Value, len(values))
+		for i, rep := range reps {
+			elems[rep.key.AsString()] = values[i]
+		}
+		return cty.ObjectVal(elems)
+	default:
+		return values[0]
+	}
+}
+
+// instance evaluates the attributes of a single resource instance.
+func (e *evaluator) instance(decl *resourceDecl, attrs hcl.Attributes, key cty.Value, extra map[string]cty.Value) *ResourceInstance {
+	inst := &ResourceInstance{
+		Mode:       decl.mode,
+		Type:       decl.typ,
+		Name:       decl.name,
+		Key:        key,
+		Module:     strings.TrimSuffix(e.prefix, "."),
+		Address:    e.prefix + instanceAddress(decl.address(), key),
+		Attributes: make(map[string]cty.Value),
+		Block:      decl.block,
+	}
+
+	ctx := e.context(extra)
+	for name, attr := range attrs {
+		if metaArguments[name] {
+			continue
+		}
+		val, diags := attr.Expr.Value(ctx)
+		if diags.HasErrors() || !val.IsKnown() {
+			continue
+		}
+		inst.Attributes[name] = val
+	}
+	return inst
+}
+
+// instanceValue builds the object other expressions see when referencing an
+// instance. Attributes that are referenced but not known become unknown.
+func (e *evaluator) instanceValue(decl *resourceDecl, inst *ResourceInstance) cty.Value {
+	attrs := make(map[string]cty.Value, len(inst.Attributes)+len(decl.referencedAttrs))
+	for name, val := range inst.Attributes {
+		attrs[name] = val
+	}
+	for name := range decl.referencedAttrs {
+		if _, ok := attrs[name]; !ok {
+			attrs[name] = cty.DynamicVal
+		}
+	}
+	for _, name := range computedAttributes {
+		if _, ok := attrs[name]; !ok {
+			attrs[name] = cty.DynamicVal
+		}
+	}
+	return cty.ObjectVal(attrs)
+}
+
+// context builds the evaluation context with the values resolved so far.
+func (e *evaluator) context(extra map[string]cty.Value) *hcl.EvalContext {
+	cwd, _ := os.Getwd()
+	vars := map[string]cty.Value{
+		"var":   objectOrEmpty(e.vars),
+		"local": objectOrEmpty(e.locals),
+		"path": cty.ObjectVal(map[string]cty.Value{
+			"module": cty.StringVal(e.dir),
+			"root":   cty.StringVal(e.rootDir),
+			"cwd":    cty.StringVal(cwd),
+		}),
+		"terraform": cty.ObjectVal(map[string]cty.Value{
+			"workspace": cty.StringVal(e.workspace),
+		}),
+	}
+
+	modules := make(map[string]cty.Value, len(e.calls))
+	for _, call := range e.calls {
+		modules[call.name] = call.value
+	}
+	vars["module"] = objectOrEmpty(modules)
+
+	managed := make(map[string]map[string]cty.Value)
+	data := make(map[string]map[string]cty.Value)
+	for _, decl := range e.decls {
+		val, ok := e.values[decl.address()]
+		if !ok {
+			continue
+		}
+		group := managed
+		if decl.mode == ModeData {
+			group = data
+		}
+		if group[decl.typ] == nil {
+			group[decl.typ] = make(map[string]cty.Value)
+		}
+		group[decl.typ][decl.name] = val
+	}
+	for typ, byName := range managed {
+		if !builtinRoots[typ] {
+			vars[typ] = cty.ObjectVal(byName)
+		}
+	}
+	dataTypes := make(map[string]cty.Value, len(data))
+	for typ, byName := range data {
+		dataTypes[typ] = cty.ObjectVal(byName)
+	}
+	vars["data"] = cty.ObjectVal(dataTypes)
+
+	for name, val := range extra {
+		vars[name] = val
+	}
+
+	return &hcl.EvalContext{
+		Variables: vars,
+		Functions: e.funcs,
+	}
+}
+
+// objectOrEmpty converts a map into an object value.
+func objectOrEmpty(m map[string]cty.Value) cty.Value {
+	if len(m) == 0 {
+		return cty.EmptyObjectVal
+	}
+	return cty.ObjectVal(m)
+}
+
+// countValue validates a count value.
+func countValue(val cty.Value, diags hcl.Diagnostics) (int, bool) {
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.Number {
+		return 0, false
+	}
+	bf := val.AsBigFloat()
+	n, _ := bf.Int64()
+	if n < 0 {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// forEachItems validates a for_each value and returns its items by key.
+func forEachItems(val cty.Value, diags hcl.Diagnostics) (map[string]cty.Value, bool) {
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return nil, false
+	}
+	ty := val.Type()
+	items := make(map[string]cty.Value)
+	switch {
+	case ty.IsMapType() || ty.IsObjectType():
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			items[k.AsString()] = v
+		}
+	case ty.IsSetType() && ty.ElementType() == cty.String:
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			items[v.AsString()] = v
+		}
+	default:
+		return nil, false
+	}
+	return items, true
+}
+
+// instanceAddress formats the address of a resource instance.
+func instanceAddress(base string, key cty.Value) string {
+	if key == cty.NilVal {
+		return base
+	}
+	if key.Type() == cty.Number {
+		bf := key.AsBigFloat()
+		i, _ := bf.Int64()
+		return fmt.Sprintf("%s[%d]", base, i)
+	}
+	return fmt.Sprintf("%s[%q]", base, key.AsString())
+}
+
+// resourceReference extracts the resource address, literal instance key and
+// accessed attribute from a traversal, e.g. aws_db_subnet_group.main.name ->
+// ("aws_db_subnet_group.main", cty.NilVal, "name"). It returns an empty
+// address for traversals that do not refer to a resource.
+func resourceReference(traversal hcl.Traversal) (string, cty.Value, string) {
+	root := traversal.RootName()
+	if builtinRoots[root] || len(traversal) < 2 {
+		return "", cty.NilVal, ""
+	}
+
+	rest := traversal[1:]
+	prefix := ""
+	if root == "data" {
+		if len(traversal) < 3 {
+			return "", cty.NilVal, ""
+		}
+		typ, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			return "", cty.NilVal, ""
+		}
+		prefix = "data."
+		root = typ.Name
+		rest = traversal[2:]
+	}
+
+	name, ok := rest[0].(hcl.TraverseAttr)
+	if !ok {
+		return "", cty.NilVal, ""
+	}
+	addr := prefix + root + "." + name.Name
+
+	key := cty.NilVal
+	rest = rest[1:]
+	if len(rest) > 0 {
+		if index, ok := rest[0].(hcl.TraverseIndex); ok {
+			if index.Key.IsKnown() && !index.Key.IsNull() &&
+				(index.Key.Type() == cty.Number || index.Key.Type() == cty.String) {
+				key = index.Key
+			}
+			rest = rest[1:]
+		}
+	}
+	if len(rest) > 0 {
+		if attr, ok := rest[0].(hcl.TraverseAttr); ok {
+			return addr, key, attr.Name
+		}
+	}
+	return addr, key, ""
+}
+
+// bodyTraversals returns every variable traversal in a body, including nested
+// blocks.
+func bodyTraversals(body hcl.Body) []hcl.Traversal {
+	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
+		var traversals []hcl.Traversal
+		for _, attr := range syntaxBody.Attributes {
+			traversals = append(traversals, attr.Expr.Variables()...)
+		}
+		for _, block := range syntaxBody.Blocks {
+			traversals = append(traversals, bodyTraversals(block.Body)...)
+		}
+		return traversals
+	}
+
+	attrs, _ := body.JustAttributes()
+	var traversals []hcl.Traversal
+	for _, attr := range attrs {
+		traversals = append(traversals, attr.Expr.Variables()...)
+	}
+	return traversals
+}
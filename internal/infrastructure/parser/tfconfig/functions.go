@@ -0,0 +1,566 @@
+package tfconfig
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/tryfunc"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	"gopkg.in/yaml.v3"
+)
+
+// Functions returns the Terraform function library. Relative file paths are
+// resolved against baseDir.
+func Functions(baseDir string) map[string]function.Function {
+	funcs := map[string]function.Function{
+		// Numeric
+		"abs":      stdlib.AbsoluteFunc,
+		"ceil":     stdlib.CeilFunc,
+		"floor":    stdlib.FloorFunc,
+		"log":      stdlib.LogFunc,
+		"max":      stdlib.MaxFunc,
+		"min":      stdlib.MinFunc,
+		"parseint": stdlib.ParseIntFunc,
+		"pow":      stdlib.PowFunc,
+		"signum":   stdlib.SignumFunc,
+
+		// String
+		"chomp":      stdlib.ChompFunc,
+		"endswith":   endsWithFunc,
+		"format":     stdlib.FormatFunc,
+		"formatlist": stdlib.FormatListFunc,
+		"indent":     stdlib.IndentFunc,
+		"join":       stdlib.JoinFunc,
+		"lower":      stdlib.LowerFunc,
+		"regex":      stdlib.RegexFunc,
+		"regexall":   stdlib.RegexAllFunc,
+		"replace":    replaceFunc,
+		"split":      stdlib.SplitFunc,
+		"startswith": startsWithFunc,
+		"strrev":     stdlib.ReverseFunc,
+		"substr":     stdlib.SubstrFunc,
+		"title":      stdlib.TitleFunc,
+		"trim":       stdlib.TrimFunc,
+		"trimprefix": stdlib.TrimPrefixFunc,
+		"trimspace":  stdlib.TrimSpaceFunc,
+		"trimsuffix": stdlib.TrimSuffixFunc,
+		"upper":      stdlib.UpperFunc,
+
+		// Collection
+		"alltrue":         allTrueFunc,
+		"anytrue":         anyTrueFunc,
+		"chunklist":       stdlib.ChunklistFunc,
+		"coalesce":        stdlib.CoalesceFunc,
+		"coalescelist":    stdlib.CoalesceListFunc,
+		"compact":         stdlib.CompactFunc,
+		"concat":          stdlib.ConcatFunc,
+		"contains":        stdlib.ContainsFunc,
+		"distinct":        stdlib.DistinctFunc,
+		"element":         stdlib.ElementFunc,
+		"flatten":         stdlib.FlattenFunc,
+		"index":           stdlib.IndexFunc,
+		"keys":            stdlib.KeysFunc,
+		"length":          lengthFunc,
+		"lookup":          stdlib.LookupFunc,
+		"merge":           stdlib.MergeFunc,
+		"one":             oneFunc,
+		"range":           stdlib.RangeFunc,
+		"reverse":         stdlib.ReverseListFunc,
+		"setintersection": stdlib.SetIntersectionFunc,
+		"setproduct":      stdlib.SetProductFunc,
+		"setsubtract":     stdlib.SetSubtractFunc,
+		"setunion":        stdlib.SetUnionFunc,
+		"slice":           stdlib.SliceFunc,
+		"sort":            stdlib.SortFunc,
+		"sum":             sumFunc,
+		"values":          stdlib.ValuesFunc,
+		"zipmap":          stdlib.ZipmapFunc,
+
+		// Encoding
+		"base64decode": base64DecodeFunc,
+		"base64encode": base64EncodeFunc,
+		"csvdecode":    stdlib.CSVDecodeFunc,
+		"jsondecode":   stdlib.JSONDecodeFunc,
+		"jsonencode":   stdlib.JSONEncodeFunc,
+		"urlencode":    urlEncodeFunc,
+		"yamldecode":   yamlDecodeFunc,
+		"yamlencode":   yamlEncodeFunc,
+
+		// Filesystem
+		"abspath":    pathFunc(filepath.Abs),
+		"basename":   pathFunc(func(p string) (string, error) { return filepath.Base(p), nil }),
+		"dirname":    pathFunc(func(p string) (string, error) { return filepath.Dir(p), nil }),
+		"pathexpand": pathFunc(expandHome),
+		"file":       fileFunc(baseDir, func(b []byte) string { return string(b) }),
+		"filebase64": fileFunc(baseDir, func(b []byte) string { return base64.StdEncoding.EncodeToString(b) }),
+		"fileexists": fileExistsFunc(baseDir),
+		"fileset":    fileSetFunc(baseDir),
+
+		// Date and time
+		"formatdate": stdlib.FormatDateFunc,
+		"timeadd":    stdlib.TimeAddFunc,
+		"timestamp":  unknownFunc(cty.String),
+
+		// Hash and crypto
+		"base64sha256": hashFunc(sha256.New, true),
+		"base64sha512": hashFunc(sha512.New, true),
+		"md5":          hashFunc(md5.New, false),
+		"sha1":         hashFunc(sha1.New, false),
+		"sha256":       hashFunc(sha256.New, false),
+		"sha512":       hashFunc(sha512.New, false),
+		"uuid":         unknownFunc(cty.String),
+
+		// IP network
+		"cidrhost":    cidrHostFunc,
+		"cidrnetmask": cidrNetmaskFunc,
+		"cidrsubnet":  cidrSubnetFunc,
+		"cidrsubnets": cidrSubnetsFunc,
+
+		// Type conversion
+		"can":          tryfunc.CanFunc,
+		"nonsensitive": identityFunc,
+		"sensitive":    identityFunc,
+		"tobool":       convertFunc(cty.Bool),
+		"tolist":       convertFunc(cty.List(cty.DynamicPseudoType)),
+		"tomap":        convertFunc(cty.Map(cty.DynamicPseudoType)),
+		"tonumber":     convertFunc(cty.Number),
+		"toset":        convertFunc(cty.Set(cty.DynamicPseudoType)),
+		"tostring":     convertFunc(cty.String),
+		"try":          tryfunc.TryFunc,
+	}
+
+	funcs["templatefile"] = templateFileFunc(baseDir, funcs)
+	return funcs
+}
+
+// resolvePath resolves a possibly relative path against baseDir.
+func resolvePath(baseDir, path string) string {
+	path, _ = expandHome(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path, err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+var identityFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "value", Type: cty.DynamicPseudoType, AllowNull: true, AllowUnknown: true, AllowDynamicType: true},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		return args[0].Type(), nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return args[0], nil
+	},
+})
+
+// unknownFunc returns a function whose result is not known until apply time.
+func unknownFunc(ty cty.Type) function.Function {
+	return function.New(&function.Spec{
+		Type: function.StaticReturnType(ty),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.UnknownVal(ty), nil
+		},
+	})
+}
+
+// convertFunc returns a to<type> conversion function.
+func convertFunc(ty cty.Type) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{Name: "v", Type: cty.DynamicPseudoType, AllowNull: true, AllowDynamicType: true},
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			out, err := convert.Convert(args[0], ty)
+			if err != nil {
+				return cty.NilType, function.NewArgError(0, err)
+			}
+			return out.Type(), nil
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return convert.Convert(args[0], retType)
+		},
+	})
+}
+
+var lengthFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "value", Type: cty.DynamicPseudoType, AllowDynamicType: true, AllowUnknown: true},
+	},
+	Type: function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		val := args[0]
+		ty := val.Type()
+		if !val.IsKnown() && ty == cty.String {
+			return cty.UnknownVal(cty.Number), nil
+		}
+		switch {
+		case ty == cty.String:
+			return stdlib.Strlen(val)
+		case ty.IsTupleType():
+			return cty.NumberIntVal(int64(ty.Length())), nil
+		case ty.IsObjectType():
+			return cty.NumberIntVal(int64(len(ty.AttributeTypes()))), nil
+		case ty.IsListType() || ty.IsSetType() || ty.IsMapType():
+			return val.Length(), nil
+		case ty == cty.DynamicPseudoType:
+			return cty.UnknownVal(cty.Number), nil
+		default:
+			return cty.UnknownVal(cty.Number), fmt.Errorf("argument must be a string, a collection type, or a structural type")
+		}
+	},
+})
+
+var replaceFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+		{Name: "substr", Type: cty.String},
+		{Name: "replace", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		substr := args[1].AsString()
+		if len(substr) > 1 && strings.HasPrefix(substr, "/") && strings.HasSuffix(substr, "/") {
+			re, err := regexp.Compile(substr[1 : len(substr)-1])
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			return cty.StringVal(re.ReplaceAllString(args[0].AsString(), args[2].AsString())), nil
+		}
+		return stdlib.Replace(args[0], args[1], args[2])
+	},
+})
+
+var startsWithFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+		{Name: "prefix", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.Bool),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.BoolVal(strings.HasPrefix(args[0].AsString(), args[1].AsString())), nil
+	},
+})
+
+var endsWithFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+		{Name: "suffix", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.Bool),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.BoolVal(strings.HasSuffix(args[0].AsString(), args[1].AsString())), nil
+	},
+})
+
+var allTrueFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "list", Type: cty.List(cty.Bool)},
+	},
+	Type: function.StaticReturnType(cty.Bool),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		for it := args[0].ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if v.IsNull() || v.False() {
+				return cty.False, nil
+			}
+		}
+		return cty.True, nil
+	},
+})
+
+var anyTrueFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "list", Type: cty.List(cty.Bool)},
+	},
+	Type: function.StaticReturnType(cty.Bool),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		for it := args[0].ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if !v.IsNull() && v.True() {
+				return cty.True, nil
+			}
+		}
+		return cty.False, nil
+	},
+})
+
+var oneFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "list", Type: cty.DynamicPseudoType},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		ty := args[0].Type()
+		switch {
+		case ty.IsListType() || ty.IsSetType():
+			return ty.ElementType(), nil
+		case ty.IsTupleType():
+			elems := ty.TupleElementTypes()
+			if len(elems) == 0 {
+				return cty.DynamicPseudoType, nil
+			}
+			return elems[0], nil
+		}
+		return cty.NilType, function.NewArgErrorf(0, "must be a list, set, or tuple value")
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		val := args[0]
+		switch val.LengthInt() {
+		case 0:
+			return cty.NullVal(retType), nil
+		case 1:
+			it := val.ElementIterator()
+			it.Next()
+			_, v := it.Element()
+			return v, nil
+		}
+		return cty.NilVal, function.NewArgErrorf(0, "must be a list, set, or tuple value with either zero or one elements")
+	},
+})
+
+var sumFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "list", Type: cty.DynamicPseudoType},
+	},
+	Type: function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		list, err := convert.Convert(args[0], cty.List(cty.Number))
+		if err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+		total := cty.Zero
+		for it := list.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if v.IsNull() {
+				return cty.NilVal, function.NewArgErrorf(0, "argument must be list, set, or tuple of number values")
+			}
+			total = total.Add(v)
+		}
+		return total, nil
+	},
+})
+
+var base64EncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "str", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(base64.StdEncoding.EncodeToString([]byte(args[0].AsString()))), nil
+	},
+})
+
+var base64DecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "str", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		decoded, err := base64.StdEncoding.DecodeString(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), fmt.Errorf("failed to decode base64 data: %w", err)
+		}
+		return cty.StringVal(string(decoded)), nil
+	},
+})
+
+var urlEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "str", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(url.QueryEscape(args[0].AsString())), nil
+	},
+})
+
+var yamlEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "value", Type: cty.DynamicPseudoType, AllowNull: true},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		out, err := yaml.Marshal(ToInterface(args[0]))
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		return cty.StringVal(string(out)), nil
+	},
+})
+
+var yamlDecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "src", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		var decoded interface{}
+		if err := yaml.Unmarshal([]byte(args[0].AsString()), &decoded); err != nil {
+			return cty.DynamicVal, err
+		}
+		return FromInterface(decoded), nil
+	},
+})
+
+// hashFunc returns a function hashing its string argument, hex- or
+// base64-encoded.
+func hashFunc(newHash func() hash.Hash, b64 bool) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "str", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			h := newHash()
+			h.Write([]byte(args[0].AsString()))
+			sum := h.Sum(nil)
+			if b64 {
+				return cty.StringVal(base64.StdEncoding.EncodeToString(sum)), nil
+			}
+			return cty.StringVal(hex.EncodeToString(sum)), nil
+		},
+	})
+}
+
+// pathFunc returns a function transforming a path string.
+func pathFunc(fn func(string) (string, error)) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "path", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			out, err := fn(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			return cty.StringVal(out), nil
+		},
+	})
+}
+
+// fileFunc returns a function reading a file and encoding its contents.
+func fileFunc(baseDir string, encode func([]byte) string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "path", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			data, err := os.ReadFile(resolvePath(baseDir, args[0].AsString()))
+			if err != nil {
+				return cty.UnknownVal(cty.String), fmt.Errorf("failed to read file: %w", err)
+			}
+			return cty.StringVal(encode(data)), nil
+		},
+	})
+}
+
+// fileExistsFunc returns the fileexists function.
+func fileExistsFunc(baseDir string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "path", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.Bool),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			info, err := os.Stat(resolvePath(baseDir, args[0].AsString()))
+			if err != nil {
+				return cty.False, nil
+			}
+			return cty.BoolVal(info.Mode().IsRegular()), nil
+		},
+	})
+}
+
+// fileSetFunc returns the fileset function.
+func fileSetFunc(baseDir string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{Name: "path", Type: cty.String},
+			{Name: "pattern", Type: cty.String},
+		},
+		Type: function.StaticReturnType(cty.Set(cty.String)),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			root := resolvePath(baseDir, args[0].AsString())
+			matches, err := filepath.Glob(filepath.Join(root, args[1].AsString()))
+			if err != nil {
+				return cty.UnknownVal(retType), err
+			}
+			sort.Strings(matches)
+			var vals []cty.Value
+			for _, match := range matches {
+				if info, err := os.Stat(match); err != nil || !info.Mode().IsRegular() {
+					continue
+				}
+				rel, err := filepath.Rel(root, match)
+				if err != nil {
+					continue
+				}
+				vals = append(vals, cty.StringVal(filepath.ToSlash(rel)))
+			}
+			if len(vals) == 0 {
+				return cty.SetValEmpty(cty.String), nil
+			}
+			return cty.SetVal(vals), nil
+		},
+	})
+}
+
+// templateFileFunc returns the templatefile function. Templates can use every
+// function except templatefile itself.
+func templateFileFunc(baseDir string, funcs map[string]function.Function) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{Name: "path", Type: cty.String},
+			{Name: "vars", Type: cty.DynamicPseudoType},
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := resolvePath(baseDir, args[0].AsString())
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return cty.DynamicVal, fmt.Errorf("failed to read template: %w", err)
+			}
+			expr, diags := hclsyntax.ParseTemplate(src, path, hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				return cty.DynamicVal, diags
+			}
+
+			vars := make(map[string]cty.Value)
+			varsVal := args[1]
+			if !varsVal.IsNull() && (varsVal.Type().IsMapType() || varsVal.Type().IsObjectType()) {
+				for it := varsVal.ElementIterator(); it.Next(); {
+					k, v := it.Element()
+					vars[k.AsString()] = v
+				}
+			}
+
+			templateFuncs := make(map[string]function.Function, len(funcs))
+			for name, fn := range funcs {
+				if name != "templatefile" {
+					templateFuncs[name] = fn
+				}
+			}
+
+			val, diags := expr.Value(&hcl.EvalContext{Variables: vars, Functions: templateFuncs})
+			if diags.HasErrors() {
+				return cty.DynamicVal, diags
+			}
+			return val, nil
+		},
+	})
+}
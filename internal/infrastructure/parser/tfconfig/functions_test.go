@@ -0,0 +1,70 @@
+package tfconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func evalExpr(t *testing.T, dir, src string) cty.Value {
+	t.Helper()
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "test.tf", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("parse %q: %s", src, diags.Error())
+	}
+	val, diags := expr.Value(&hcl.EvalContext{Functions: Functions(dir)})
+	if diags.HasErrors() {
+		t.Fatalf("eval %q: %s", src, diags.Error())
+	}
+	return val
+}
+
+func TestFunctions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "user_data.tpl"), []byte("hello ${name}"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	tests := []struct {
+		expr string
+		want cty.Value
+	}{
+		{`cidrsubnet("10.0.0.0/16", 8, 2)`, cty.StringVal("10.0.2.0/24")},
+		{`cidrhost("10.0.1.0/24", 5)`, cty.StringVal("10.0.1.5")},
+		{`cidrhost("10.0.1.0/24", -2)`, cty.StringVal("10.0.1.254")},
+		{`cidrnetmask("172.16.0.0/12")`, cty.StringVal("255.240.0.0")},
+		{`cidrsubnets("10.1.0.0/16", 4, 4, 8)`, cty.ListVal([]cty.Value{
+			cty.StringVal("10.1.0.0/20"),
+			cty.StringVal("10.1.16.0/20"),
+			cty.StringVal("10.1.32.0/24"),
+		})},
+		{`length("abc")`, cty.NumberIntVal(3)},
+		{`length(["a", "b"])`, cty.NumberIntVal(2)},
+		{`replace("a-b-c", "/-/", "_")`, cty.StringVal("a_b_c")},
+		{`lookup({a = "x"}, "b", "y")`, cty.StringVal("y")},
+		{`merge({a = 1}, {b = 2})["b"]`, cty.NumberIntVal(2)},
+		{`try(tonumber("nope"), 7)`, cty.NumberIntVal(7)},
+		{`can(tonumber("nope"))`, cty.False},
+		{`sum([1, 2, 3])`, cty.NumberIntVal(6)},
+		{`one(["only"])`, cty.StringVal("only")},
+		{`startswith("prod-db", "prod")`, cty.True},
+		{`base64decode(base64encode("hi"))`, cty.StringVal("hi")},
+		{`md5("homeport")`, cty.StringVal("818e4248173118b246882863315abad8")},
+		{`templatefile("user_data.tpl", { name = "world" })`, cty.StringVal("hello world")},
+		{`fileexists("missing.txt")`, cty.False},
+		{`yamldecode("a: b")["a"]`, cty.StringVal("b")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got := evalExpr(t, dir, tt.expr)
+			if !got.Equals(tt.want).True() {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+// Package tfconfig loads and evaluates Terraform configurations.
+//
+// It resolves variables (defaults, tfvars files, -var overrides), locals and
+// the Terraform function library, and expands count/for_each resources into
+// individual instances so provider parsers see the values Terraform would.
+// Module calls are followed into local, vendored (.terraform/modules) and
+// cached git sources, and their resources get module-qualified addresses.
+package tfconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+)
+
+// Resource modes.
+const (
+	ModeManaged = "managed"
+	ModeData    = "data"
+)
+
+// Options configures how a configuration is evaluated.
+type Options struct {
+	// Variables are raw -var style overrides (name -> value).
+	Variables map[string]string
+
+	// VarFiles are additional .tfvars files applied after the auto-loaded ones.
+	VarFiles []string
+
+	// Inputs are variable values set by a wrapper such as Terragrunt. Like
+	// the TF_VAR_ variables such wrappers export, they override defaults and
+	// are overridden by everything else.
+	Inputs map[string]cty.Value
+
+	// Workspace is the value of terraform.workspace. Defaults to "default".
+	Workspace string
+
+	// FollowModules loads the sources of module blocks and evaluates their
+	// resources as part of the calling module.
+	FollowModules bool
+
+	// MaxDepth limits module nesting when following modules. 0 means unlimited.
+	MaxDepth int
+
+	// ModuleCacheDir holds pinned git module sources laid out as
+	// <host>/<path>/<ref>. Defaults to ~/.homeport/modules.
+	ModuleCacheDir string
+}
+
+// Module is an evaluated Terraform module.
+type Module struct {
+	// Dir is the directory the module was loaded from.
+	Dir string
+
+	// Path is the module address, e.g. `module.db` or `module.app["web"]`.
+	// It is empty for the root module.
+	Path string
+
+	// Files are the configuration files that make up the module.
+	Files []string
+
+	// Variables holds the resolved input variable values.
+	Variables map[string]cty.Value
+
+	// Locals holds the evaluated local values.
+	Locals map[string]cty.Value
+
+	// Outputs holds the evaluated output values.
+	Outputs map[string]cty.Value
+
+	// Resources holds all expanded managed and data resource instances in
+	// declaration order, followed by those of child modules.
+	Resources []*ResourceInstance
+
+	// Children are the followed child module instances.
+	Children []*Module
+
+	// Diagnostics collects non-fatal evaluation problems.
+	Diagnostics hcl.Diagnostics
+}
+
+// ResourceInstance is a single resource instance after count/for_each expansion.
+type ResourceInstance struct {
+	// Mode is ModeManaged or ModeData.
+	Mode string
+
+	// Type is the Terraform resource type, e.g. "aws_db_instance".
+	Type string
+
+	// Name is the resource label, e.g. "main".
+	Name string
+
+	// Key is the instance key: a number for count, a string for for_each,
+	// or cty.NilVal for single-instance resources.
+	Key cty.Value
+
+	// Module is the address of the module that declares the instance, or ""
+	// for the root module.
+	Module string
+
+	// Address is the Terraform address, e.g. `aws_instance.web[0]` or
+	// `module.db.aws_db_instance.this`.
+	Address string
+
+	// Attributes holds the evaluated attribute values. Attributes that could
+	// not be evaluated (unknown references, errors) are omitted.
+	Attributes map[string]cty.Value
+
+	// Dependencies are the addresses of the managed resource instances this
+	// instance references or lists in depends_on.
+	Dependencies []string
+
+	// Block is the source block.
+	Block *hcl.Block
+}
+
+// ModulesFollowed returns the number of child module instances loaded below m.
+func (m *Module) ModulesFollowed() int {
+	n := len(m.Children)
+	for _, child := range m.Children {
+		n += child.ModulesFollowed()
+	}
+	return n
+}
+
+// HasKey reports whether the instance came from count or for_each expansion.
+func (r *ResourceInstance) HasKey() bool {
+	return r.Key != cty.NilVal
+}
+
+// KeyString returns the instance key as a plain string, or "" if the
+// instance has no key.
+func (r *ResourceInstance) KeyString() string {
+	if !r.HasKey() || !r.Key.IsKnown() || r.Key.IsNull() {
+		return ""
+	}
+	if r.Key.Type() == cty.Number {
+		bf := r.Key.AsBigFloat()
+		i, _ := bf.Int64()
+		return fmt.Sprintf("%d", i)
+	}
+	return r.Key.AsString()
+}
+
+// LoadDir loads and evaluates all configuration files in a directory.
+// Subdirectories are not included; each directory is a separate module.
+func LoadDir(dir string, opts *Options) (*Module, error) {
+	files, err := ConfigFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no terraform configuration files in %s", dir)
+	}
+	return load(dir, files, opts)
+}
+
+// LoadFiles loads and evaluates an explicit set of configuration files as one
+// module. Variable files are looked up in the directory of the first file.
+func LoadFiles(files []string, opts *Options) (*Module, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no terraform configuration files given")
+	}
+	return load(filepath.Dir(files[0]), files, opts)
+}
+
+// ConfigFiles returns the Terraform configuration files (.tf, .tf.json) in dir,
+// sorted by name.
+func ConfigFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if IsConfigFile(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// IsConfigFile reports whether name is a Terraform configuration file.
+func IsConfigFile(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".tf") || strings.HasSuffix(lower, ".tf.json")
+}
+
+// moduleSchema is the top-level Terraform block schema.
+var moduleSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "locals"},
+		{Type: "module", LabelNames: []string{"name"}},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "terraform"},
+	},
+}
+
+// load parses the given files and evaluates them as a root module.
+func load(dir string, files []string, opts *Options) (*Module, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	blocks, err := parseFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	e := newEvaluator(dir, files, opts)
+	vars, diags := resolveVariables(dir, blocks.OfType("variable"), opts)
+	e.diags = append(e.diags, diags...)
+	e.vars = vars
+	e.run(blocks)
+
+	return e.module(), nil
+}
+
+// parseFiles parses configuration files and returns their top-level blocks.
+func parseFiles(files []string) (hcl.Blocks, error) {
+	p := hclparse.NewParser()
+	var blocks hcl.Blocks
+	for _, path := range files {
+		var file *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(strings.ToLower(path), ".json") {
+			file, diags = p.ParseJSONFile(path)
+		} else {
+			file, diags = p.ParseHCLFile(path)
+		}
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse HCL file: %s", diags.Error())
+		}
+
+		content, _, diags := file.Body.PartialContent(moduleSchema)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to decode HCL content: %s", diags.Error())
+		}
+		blocks = append(blocks, content.Blocks...)
+	}
+	return blocks, nil
+}
+
+// FromParseOptions builds evaluation options from parser options.
+func FromParseOptions(opts *parser.ParseOptions) *Options {
+	if opts == nil {
+		return &Options{}
+	}
+	return &Options{
+		Variables:     opts.Variables,
+		VarFiles:      opts.VarFiles,
+		FollowModules: opts.FollowModules,
+		MaxDepth:      opts.MaxDepth,
+	}
+}
+
+// ModuleDirs returns every directory under root that contains Terraform
+// configuration files, in walk order. Hidden directories such as .terraform
+// are skipped.
+func ModuleDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		files, err := ConfigFiles(path)
+		if err == nil && len(files) > 0 {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// LoadTree loads every module directory under root. Directories that were
+// followed as child modules of another directory are not returned on their
+// own, so their resources only appear once, under module addresses. With
+// ignoreErrors, directories that fail to load are skipped.
+func LoadTree(root string, opts *Options, ignoreErrors bool) ([]*Module, error) {
+	dirs, err := ModuleDirs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var mods []*Module
+	followed := make(map[string]bool)
+	for _, dir := range dirs {
+		mod, err := LoadDir(dir, opts)
+		if err != nil {
+			if ignoreErrors {
+				continue
+			}
+			return nil, err
+		}
+		mods = append(mods, mod)
+		markFollowed(mod, followed)
+	}
+
+	roots := make([]*Module, 0, len(mods))
+	for _, mod := range mods {
+		if !followed[filepath.Clean(mod.Dir)] {
+			roots = append(roots, mod)
+		}
+	}
+	return roots, nil
+}
+
+// markFollowed records the directories of all child modules of mod.
+func markFollowed(mod *Module, followed map[string]bool) {
+	for _, child := range mod.Children {
+		followed[filepath.Clean(child.Dir)] = true
+		markFollowed(child, followed)
+	}
+}
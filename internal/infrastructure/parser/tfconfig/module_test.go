@@ -0,0 +1,331 @@
+package tfconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func findInstance(mod *Module, address string) *ResourceInstance {
+	for _, inst := range mod.Resources {
+		if inst.Address == address {
+			return inst
+		}
+	}
+	return nil
+}
+
+func TestLoadDir_VariablesAndLocals(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": `
+variable "env" {
+  type    = string
+  default = "dev"
+}
+
+variable "instance_class" {
+  type = string
+}
+
+variable "sizes" {
+  type    = map(number)
+  default = { dev = 20, prod = 100 }
+}
+
+locals {
+  prefix = "app-${var.env}"
+  db_name = "${local.prefix}-db"
+}
+
+resource "aws_db_instance" "main" {
+  identifier        = local.db_name
+  instance_class    = var.instance_class
+  allocated_storage = var.sizes[var.env]
+  engine            = upper("postgres")
+}
+`,
+		"terraform.tfvars":      `instance_class = "db.t3.micro"`,
+		"prod.auto.tfvars":      `env = "prod"`,
+		"override.tfvars":       `instance_class = "db.r5.large"`,
+		"ignored/unused.tfvars": `env = "ignored"`,
+	})
+
+	mod, err := LoadDir(dir, &Options{
+		VarFiles: []string{filepath.Join(dir, "override.tfvars")},
+	})
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	inst := findInstance(mod, "aws_db_instance.main")
+	if inst == nil {
+		t.Fatal("expected aws_db_instance.main")
+	}
+
+	if got := inst.Attributes["identifier"]; !got.RawEquals(cty.StringVal("app-prod-db")) {
+		t.Errorf("identifier = %#v, want app-prod-db", got)
+	}
+	if got := inst.Attributes["instance_class"]; !got.RawEquals(cty.StringVal("db.r5.large")) {
+		t.Errorf("instance_class = %#v, want db.r5.large", got)
+	}
+	if got := ToInterface(inst.Attributes["allocated_storage"]); got != float64(100) {
+		t.Errorf("allocated_storage = %v, want 100", got)
+	}
+	if got := inst.Attributes["engine"]; !got.RawEquals(cty.StringVal("POSTGRES")) {
+		t.Errorf("engine = %#v, want POSTGRES", got)
+	}
+}
+
+func TestLoadDir_VarOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": `
+variable "name" {}
+variable "zones" {
+  type = list(string)
+}
+
+resource "aws_s3_bucket" "b" {
+  bucket = var.name
+  zones  = var.zones
+}
+`,
+	})
+
+	mod, err := LoadDir(dir, &Options{
+		Variables: map[string]string{
+			"name":  "assets",
+			"zones": `["a", "b"]`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	inst := findInstance(mod, "aws_s3_bucket.b")
+	if inst == nil {
+		t.Fatal("expected aws_s3_bucket.b")
+	}
+	if got := inst.Attributes["bucket"]; !got.RawEquals(cty.StringVal("assets")) {
+		t.Errorf("bucket = %#v, want assets", got)
+	}
+	zones, ok := ToInterface(inst.Attributes["zones"]).([]interface{})
+	if !ok || len(zones) != 2 || zones[1] != "b" {
+		t.Errorf("zones = %#v, want [a b]", inst.Attributes["zones"])
+	}
+}
+
+func TestLoadDir_CountAndForEach(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": `
+variable "replicas" {
+  default = 3
+}
+
+resource "aws_instance" "web" {
+  count         = var.replicas
+  instance_type = "t3.micro"
+  tags = {
+    Name = "web-${count.index}"
+  }
+}
+
+resource "aws_sqs_queue" "q" {
+  for_each = toset(["orders", "emails"])
+  name     = "${each.key}-queue"
+}
+
+resource "aws_s3_bucket" "disabled" {
+  count  = 0
+  bucket = "never"
+}
+`,
+	})
+
+	mod, err := LoadDir(dir, nil)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	if len(mod.Resources) != 5 {
+		t.Fatalf("expected 5 instances, got %d", len(mod.Resources))
+	}
+
+	web := findInstance(mod, "aws_instance.web[2]")
+	if web == nil {
+		t.Fatal("expected aws_instance.web[2]")
+	}
+	if web.KeyString() != "2" {
+		t.Errorf("key = %q, want 2", web.KeyString())
+	}
+	tags := ToInterface(web.Attributes["tags"]).(map[string]interface{})
+	if tags["Name"] != "web-2" {
+		t.Errorf("Name tag = %v, want web-2", tags["Name"])
+	}
+	if _, ok := web.Attributes["count"]; ok {
+		t.Error("count meta-argument should not be an attribute")
+	}
+
+	q := findInstance(mod, `aws_sqs_queue.q["orders"]`)
+	if q == nil {
+		t.Fatal(`expected aws_sqs_queue.q["orders"]`)
+	}
+	if got := q.Attributes["name"]; !got.RawEquals(cty.StringVal("orders-queue")) {
+		t.Errorf("name = %#v, want orders-queue", got)
+	}
+
+	if findInstance(mod, "aws_s3_bucket.disabled[0]") != nil {
+		t.Error("count = 0 should produce no instances")
+	}
+}
+
+func TestLoadDir_ResourceReferences(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": `
+resource "aws_db_instance" "main" {
+  db_subnet_group_name = aws_db_subnet_group.main.name
+  security_group_ids   = [aws_security_group.db.id]
+}
+
+resource "aws_db_subnet_group" "main" {
+  name = "main-subnets"
+}
+
+resource "aws_security_group" "db" {
+  name = "db"
+}
+`,
+	})
+
+	mod, err := LoadDir(dir, nil)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	db := findInstance(mod, "aws_db_instance.main")
+	if db == nil {
+		t.Fatal("expected aws_db_instance.main")
+	}
+	if got := db.Attributes["db_subnet_group_name"]; !got.RawEquals(cty.StringVal("main-subnets")) {
+		t.Errorf("db_subnet_group_name = %#v, want main-subnets", got)
+	}
+	if ids, _ := ToInterface(db.Attributes["security_group_ids"]).([]interface{}); len(ids) != 0 {
+		t.Errorf("computed ids should be dropped, got %v", ids)
+	}
+}
+
+func TestLoadDir_UnknownVariable(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": `
+variable "region" {}
+
+resource "aws_s3_bucket" "b" {
+  bucket = "static"
+  region = var.region
+}
+`,
+	})
+
+	mod, err := LoadDir(dir, nil)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	inst := findInstance(mod, "aws_s3_bucket.b")
+	if _, ok := inst.Attributes["region"]; ok {
+		t.Error("attribute referencing an unset variable should be omitted")
+	}
+	if got := inst.Attributes["bucket"]; !got.RawEquals(cty.StringVal("static")) {
+		t.Errorf("bucket = %#v, want static", got)
+	}
+}
+
+func TestLoadDir_Dependencies(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": `
+locals {
+  subnet_group = aws_db_subnet_group.main.name
+}
+
+resource "aws_db_subnet_group" "main" {
+  name       = "main"
+  subnet_ids = aws_subnet.private[*].id
+}
+
+resource "aws_subnet" "private" {
+  count      = 2
+  cidr_block = cidrsubnet("10.0.0.0/16", 8, count.index)
+}
+
+data "aws_iam_policy_document" "read" {
+  statement {
+    resources = [aws_s3_bucket.assets.arn]
+  }
+}
+
+resource "aws_s3_bucket" "assets" {
+  bucket = "assets"
+}
+
+resource "aws_iam_policy" "read" {
+  policy = data.aws_iam_policy_document.read.json
+}
+
+resource "aws_db_instance" "main" {
+  db_subnet_group_name = local.subnet_group
+  availability_zone    = aws_subnet.private[1].availability_zone
+  depends_on           = [aws_s3_bucket.assets]
+}
+`,
+	})
+
+	mod, err := LoadDir(dir, nil)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	tests := []struct {
+		address string
+		want    []string
+	}{
+		{"aws_db_instance.main", []string{"aws_db_subnet_group.main", "aws_s3_bucket.assets", "aws_subnet.private[1]"}},
+		{"aws_db_subnet_group.main", []string{"aws_subnet.private[0]", "aws_subnet.private[1]"}},
+		{"aws_iam_policy.read", []string{"aws_s3_bucket.assets"}},
+		{"aws_s3_bucket.assets", nil},
+	}
+	for _, tt := range tests {
+		inst := findInstance(mod, tt.address)
+		if inst == nil {
+			t.Fatalf("expected %s", tt.address)
+		}
+		if len(inst.Dependencies) != len(tt.want) {
+			t.Errorf("%s dependencies = %v, want %v", tt.address, inst.Dependencies, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if inst.Dependencies[i] != tt.want[i] {
+				t.Errorf("%s dependencies = %v, want %v", tt.address, inst.Dependencies, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package tfconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// moduleArguments are module block arguments that are not input variables.
+var moduleArguments = map[string]bool{
+	"source":     true,
+	"version":    true,
+	"providers":  true,
+	"count":      true,
+	"for_each":   true,
+	"depends_on": true,
+}
+
+// outputSchema describes the attributes of an output block we care about.
+var outputSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "value"},
+	},
+}
+
+// moduleCall is a declared module block.
+type moduleCall struct {
+	name  string
+	block *hcl.Block
+
+	resolved bool
+
+	// value is the object of outputs other expressions see, combined like
+	// resource values when the call uses count or for_each.
+	value cty.Value
+
+	// children are the evaluated module instances.
+	children []*evaluator
+}
+
+// addModuleCall registers a module block.
+func (e *evaluator) addModuleCall(block *hcl.Block) {
+	if len(block.Labels) < 1 {
+		return
+	}
+	name := block.Labels[0]
+	if _, exists := e.callIndex[name]; exists {
+		return
+	}
+	call := &moduleCall{name: name, block: block, value: cty.DynamicVal}
+	e.calls = append(e.calls, call)
+	e.callIndex[name] = call
+}
+
+// addOutput registers an output block.
+func (e *evaluator) addOutput(block *hcl.Block) {
+	if len(block.Labels) < 1 {
+		return
+	}
+	content, _, diags := block.Body.PartialContent(outputSchema)
+	e.diags = append(e.diags, diags...)
+	if attr, ok := content.Attributes["value"]; ok {
+		e.outputExprs[block.Labels[0]] = attr.Expr
+	}
+}
+
+// resolveModule loads and evaluates the instances of a module call after
+// the values its arguments depend on. Calls that are not followed keep an
+// unknown value.
+func (e *evaluator) resolveModule(call *moduleCall) {
+	if call.resolved {
+		return
+	}
+	key := "module." + call.name
+	if e.visiting[key] {
+		return
+	}
+	e.visiting[key] = true
+	defer delete(e.visiting, key)
+	defer func() { call.resolved = true }()
+
+	if !e.opts.FollowModules {
+		return
+	}
+	if e.opts.MaxDepth > 0 && len(e.callPath) >= e.opts.MaxDepth {
+		e.diags = append(e.diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Module not followed",
+			Detail:   fmt.Sprintf("module %q is nested deeper than the maximum depth of %d", call.name, e.opts.MaxDepth),
+			Subject:  call.block.DefRange.Ptr(),
+		})
+		return
+	}
+
+	attrs, _ := call.block.Body.JustAttributes()
+	source := ""
+	if attr, ok := attrs["source"]; ok {
+		if val, diags := attr.Expr.Value(nil); !diags.HasErrors() && val.Type() == cty.String && val.IsKnown() && !val.IsNull() {
+			source = val.AsString()
+		}
+	}
+	if source == "" {
+		e.diags = append(e.diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Invalid module source",
+			Detail:   fmt.Sprintf("module %q must have a literal string source", call.name),
+			Subject:  call.block.DefRange.Ptr(),
+		})
+		return
+	}
+
+	dir, err := e.moduleDir(call.name, source)
+	if err != nil {
+		e.diags = append(e.diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Module not followed",
+			Detail:   err.Error(),
+			Subject:  call.block.DefRange.Ptr(),
+		})
+		return
+	}
+	files, err := ConfigFiles(dir)
+	if err == nil && len(files) == 0 {
+		err = fmt.Errorf("no terraform configuration files in %s", dir)
+	}
+	var blocks hcl.Blocks
+	if err == nil {
+		blocks, err = parseFiles(files)
+	}
+	if err != nil {
+		e.diags = append(e.diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Module not followed",
+			Detail:   fmt.Sprintf("module %q: %s", call.name, err),
+			Subject:  call.block.DefRange.Ptr(),
+		})
+		return
+	}
+
+	e.resolveDependencies(bodyTraversals(call.block.Body))
+
+	// Resources inside the module depend on whatever the arguments feeding
+	// their variables depend on, and everything named in depends_on.
+	inputDeps := make(map[string][]string)
+	var callDeps []string
+	for name, attr := range attrs {
+		refs := e.references(attr.Expr.Variables())
+		switch {
+		case name == "depends_on":
+			callDeps = e.dependencies(refs, make(map[string]bool))
+		case !moduleArguments[name]:
+			inputDeps[name] = e.dependencies(refs, make(map[string]bool))
+		}
+	}
+
+	reps, kind, ok := e.expand(attrs)
+	values := make([]cty.Value, 0, len(reps))
+	for _, rep := range reps {
+		ctx := e.context(rep.extra)
+		inputs := make(map[string]cty.Value)
+		for name, attr := range attrs {
+			if moduleArguments[name] {
+				continue
+			}
+			val, diags := attr.Expr.Value(ctx)
+			if diags.HasErrors() {
+				val = cty.DynamicVal
+			}
+			inputs[name] = val
+		}
+
+		child := e.child(call.name, rep.key, dir, files)
+		child.inputDeps = inputDeps
+		child.callDeps = callDeps
+		vars, diags := moduleVariables(blocks.OfType("variable"), inputs)
+		child.diags = append(child.diags, diags...)
+		child.vars = vars
+		child.run(blocks)
+
+		e.diags = append(e.diags, child.diags...)
+		call.children = append(call.children, child)
+		values = append(values, objectOrEmpty(child.outputs))
+	}
+
+	if ok {
+		call.value = collect(kind, reps, values)
+	}
+}
+
+// child creates the evaluator for one instance of a module call.
+func (e *evaluator) child(name string, key cty.Value, dir string, files []string) *evaluator {
+	child := newEvaluator(dir, files, e.opts)
+	child.rootDir = e.rootDir
+	child.workspace = e.workspace
+	child.manifest = e.manifest
+	child.prefix = e.prefix + instanceAddress("module."+name, key) + "."
+	child.callPath = append(append([]string{}, e.callPath...), name)
+	child.key = key
+	return child
+}
+
+// dependencies returns the managed resource instances behind a module
+// reference. When the referenced output is known only the resources it
+// refers to are returned, otherwise every managed resource of the module.
+func (c *moduleCall) dependencies(key cty.Value, output string) []string {
+	var deps []string
+	for _, child := range c.children {
+		if key != cty.NilVal && child.key != cty.NilVal && instanceAddress("", key) != instanceAddress("", child.key) {
+			continue
+		}
+		if expr, ok := child.outputExprs[output]; ok {
+			deps = append(deps, child.dependencies(child.references(expr.Variables()), make(map[string]bool))...)
+			continue
+		}
+		for _, inst := range child.instances {
+			if inst.Mode == ModeManaged {
+				deps = append(deps, inst.Address)
+			}
+		}
+	}
+	return deps
+}
+
+// moduleReference extracts the module name, literal instance key and output
+// name from a traversal like module.db.endpoint or module.app["web"].url.
+// It returns an empty name for traversals that do not refer to a module.
+func moduleReference(traversal hcl.Traversal) (string, cty.Value, string) {
+	if traversal.RootName() != "module" || len(traversal) < 2 {
+		return "", cty.NilVal, ""
+	}
+	name, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return "", cty.NilVal, ""
+	}
+
+	key := cty.NilVal
+	rest := traversal[2:]
+	if len(rest) > 0 {
+		if index, ok := rest[0].(hcl.TraverseIndex); ok {
+			if index.Key.IsKnown() && !index.Key.IsNull() &&
+				(index.Key.Type() == cty.Number || index.Key.Type() == cty.String) {
+				key = index.Key
+			}
+			rest = rest[1:]
+		}
+	}
+	if len(rest) > 0 {
+		if attr, ok := rest[0].(hcl.TraverseAttr); ok {
+			return name.Name, key, attr.Name
+		}
+	}
+	return name.Name, key, ""
+}
+
+// moduleDir finds the directory holding the source of a module call.
+// Relative paths are resolved against the calling module; anything else is
+// looked up in .terraform/modules/modules.json of the root module and then,
+// for pinned git sources, in the module cache.
+func (e *evaluator) moduleDir(name, source string) (string, error) {
+	if !isLocalSource(source) {
+		key := strings.Join(append(append([]string{}, e.callPath...), name), ".")
+		if dir, ok := e.manifest.lookup(e.rootDir, key); ok {
+			return dir, nil
+		}
+	}
+
+	dir, err := SourceDir(e.dir, source, e.opts)
+	if err != nil {
+		return "", fmt.Errorf("module %q: %w", name, err)
+	}
+	return dir, nil
+}
+
+// SourceDir resolves a module source to a directory on disk without a
+// .terraform/modules manifest: relative paths are resolved against baseDir
+// and pinned git sources are looked up in the module cache.
+func SourceDir(baseDir, source string, opts *Options) (string, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	if isLocalSource(source) || filepath.IsAbs(source) {
+		dir := filepath.FromSlash(source)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(baseDir, dir)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("source directory %s not found", dir)
+		}
+		return dir, nil
+	}
+
+	if repo, subdir, ref, ok := parseGitSource(source); ok {
+		if ref == "" {
+			return "", fmt.Errorf("git source %s is not pinned to a ref", source)
+		}
+		cacheDir := moduleCacheDir(opts)
+		dir := filepath.Join(cacheDir, filepath.FromSlash(repo), ref, filepath.FromSlash(subdir))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+		return "", fmt.Errorf("%s@%s is not in the module cache (%s)", repo, ref, cacheDir)
+	}
+
+	return "", fmt.Errorf("source %s is not installed, run terraform init", source)
+}
+
+// moduleCacheDir returns the directory pinned git modules are cached in.
+func moduleCacheDir(opts *Options) string {
+	if opts.ModuleCacheDir != "" {
+		return opts.ModuleCacheDir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".homeport", "modules")
+	}
+	return filepath.Join(home, ".homeport", "modules")
+}
+
+// isLocalSource reports whether a module source is a relative path.
+func isLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") ||
+		strings.HasPrefix(source, `.\`) || strings.HasPrefix(source, `..\`)
+}
+
+// parseGitSource splits a git module source into a normalized repository
+// path (host/owner/repo), the subdirectory after "//" and the ref query
+// parameter. It accepts git:: URLs, scp-like git@host:path addresses and
+// the github.com/bitbucket.org shorthands.
+func parseGitSource(source string) (repo, subdir, ref string, ok bool) {
+	src := source
+	switch {
+	case strings.HasPrefix(src, "git::"):
+		src = strings.TrimPrefix(src, "git::")
+	case strings.HasPrefix(src, "git@"),
+		strings.HasPrefix(src, "github.com/"),
+		strings.HasPrefix(src, "bitbucket.org/"):
+	default:
+		return "", "", "", false
+	}
+
+	if i := strings.Index(src, "?"); i >= 0 {
+		query, err := url.ParseQuery(src[i+1:])
+		if err == nil {
+			ref = query.Get("ref")
+		}
+		src = src[:i]
+	}
+
+	if i := strings.Index(src, "://"); i >= 0 {
+		src = src[i+3:]
+	}
+	if i := strings.Index(src, "//"); i >= 0 {
+		subdir = strings.Trim(src[i+2:], "/")
+		src = src[:i]
+	}
+
+	// Drop the user and turn scp-like host:path into host/path.
+	if i := strings.Index(src, "@"); i >= 0 {
+		src = src[i+1:]
+	}
+	if i := strings.Index(src, ":"); i >= 0 && !strings.Contains(src[:i], "/") {
+		src = src[:i] + "/" + src[i+1:]
+	}
+	src = strings.TrimSuffix(strings.Trim(src, "/"), ".git")
+
+	if src == "" {
+		return "", "", "", false
+	}
+	return src, subdir, ref, true
+}
+
+// moduleManifest is the module index terraform init writes to
+// .terraform/modules/modules.json. It is loaded once per root module.
+type moduleManifest struct {
+	loaded bool
+	dirs   map[string]string
+}
+
+// lookup returns the installed directory of the module with the given key
+// (module call names joined by dots, e.g. "vpc.subnets").
+func (m *moduleManifest) lookup(rootDir, key string) (string, bool) {
+	if !m.loaded {
+		m.loaded = true
+		m.dirs = readModuleManifest(rootDir)
+	}
+	dir, ok := m.dirs[key]
+	return dir, ok
+}
+
+// readModuleManifest reads .terraform/modules/modules.json under rootDir.
+func readModuleManifest(rootDir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(rootDir, ".terraform", "modules", "modules.json"))
+	if err != nil {
+		return nil
+	}
+
+	var manifest struct {
+		Modules []struct {
+			Key    string `json:"Key"`
+			Source string `json:"Source"`
+			Dir    string `json:"Dir"`
+		} `json:"Modules"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+
+	dirs := make(map[string]string, len(manifest.Modules))
+	for _, mod := range manifest.Modules {
+		if mod.Key == "" {
+			continue
+		}
+		dir := filepath.FromSlash(mod.Dir)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(rootDir, dir)
+		}
+		dirs[mod.Key] = dir
+	}
+	return dirs
+}
@@ -0,0 +1,284 @@
+package tfconfig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestLoadDir_LocalModules(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": `
+resource "aws_db_subnet_group" "main" {
+  name = "main"
+}
+
+module "db" {
+  source       = "./modules/db"
+  name         = "orders"
+  subnet_group = aws_db_subnet_group.main.name
+}
+
+module "queue" {
+  source   = "./modules/queue"
+  for_each = toset(["emails", "jobs"])
+  name     = each.key
+}
+
+resource "aws_route53_record" "db" {
+  name    = "db.example.com"
+  records = [module.db.address]
+}
+`,
+		"modules/db/main.tf": `
+variable "name" {}
+variable "subnet_group" {}
+variable "engine" {
+  default = "postgres"
+}
+
+resource "aws_db_instance" "this" {
+  identifier           = "${var.name}-db"
+  engine               = var.engine
+  db_subnet_group_name = var.subnet_group
+}
+
+resource "aws_security_group" "this" {
+  name = "${var.name}-db"
+}
+
+output "address" {
+  value = aws_db_instance.this.identifier
+}
+`,
+		"modules/queue/main.tf": `
+variable "name" {}
+
+resource "aws_sqs_queue" "this" {
+  name = "${var.name}-queue"
+}
+`,
+	})
+
+	mod, err := LoadDir(dir, &Options{FollowModules: true})
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	if got := mod.ModulesFollowed(); got != 3 {
+		t.Errorf("ModulesFollowed() = %d, want 3", got)
+	}
+
+	db := findInstance(mod, "module.db.aws_db_instance.this")
+	if db == nil {
+		t.Fatal("expected module.db.aws_db_instance.this")
+	}
+	if db.Module != "module.db" {
+		t.Errorf("Module = %q, want module.db", db.Module)
+	}
+	if got := db.Attributes["identifier"]; !got.RawEquals(cty.StringVal("orders-db")) {
+		t.Errorf("identifier = %#v, want orders-db", got)
+	}
+	if got := db.Attributes["engine"]; !got.RawEquals(cty.StringVal("postgres")) {
+		t.Errorf("engine = %#v, want postgres", got)
+	}
+	if len(db.Dependencies) != 1 || db.Dependencies[0] != "aws_db_subnet_group.main" {
+		t.Errorf("db dependencies = %v, want [aws_db_subnet_group.main]", db.Dependencies)
+	}
+
+	sg := findInstance(mod, "module.db.aws_security_group.this")
+	if sg == nil {
+		t.Fatal("expected module.db.aws_security_group.this")
+	}
+	if len(sg.Dependencies) != 0 {
+		t.Errorf("sg dependencies = %v, want none", sg.Dependencies)
+	}
+
+	q := findInstance(mod, `module.queue["jobs"].aws_sqs_queue.this`)
+	if q == nil {
+		t.Fatal(`expected module.queue["jobs"].aws_sqs_queue.this`)
+	}
+	if got := q.Attributes["name"]; !got.RawEquals(cty.StringVal("jobs-queue")) {
+		t.Errorf("name = %#v, want jobs-queue", got)
+	}
+
+	record := findInstance(mod, "aws_route53_record.db")
+	if record == nil {
+		t.Fatal("expected aws_route53_record.db")
+	}
+	records, _ := ToInterface(record.Attributes["records"]).([]interface{})
+	if len(records) != 1 || records[0] != "orders-db" {
+		t.Errorf("records = %v, want [orders-db]", records)
+	}
+	if len(record.Dependencies) != 1 || record.Dependencies[0] != "module.db.aws_db_instance.this" {
+		t.Errorf("record dependencies = %v, want [module.db.aws_db_instance.this]", record.Dependencies)
+	}
+}
+
+func TestLoadDir_InstalledModules(t *testing.T) {
+	dir := t.TempDir()
+	cache := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": `
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+  name    = "main"
+}
+
+module "cache" {
+  source = "git::https://github.com/acme/terraform-modules.git//redis?ref=v1.2.0"
+  name   = "sessions"
+}
+
+module "unpinned" {
+  source = "github.com/acme/other"
+}
+`,
+		".terraform/modules/modules.json": `{"Modules":[
+  {"Key":"","Source":"","Dir":"."},
+  {"Key":"vpc","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Version":"5.0.0","Dir":".terraform/modules/vpc"},
+  {"Key":"vpc.subnets","Source":"./modules/subnets","Dir":".terraform/modules/vpc/modules/subnets"}
+]}`,
+		".terraform/modules/vpc/main.tf": `
+variable "name" {}
+
+resource "aws_vpc" "this" {
+  tags = { Name = var.name }
+}
+
+module "subnets" {
+  source = "./modules/subnets"
+  vpc_id = aws_vpc.this.id
+}
+`,
+		".terraform/modules/vpc/modules/subnets/main.tf": `
+variable "vpc_id" {}
+
+resource "aws_subnet" "this" {
+  count  = 2
+  vpc_id = var.vpc_id
+}
+`,
+	})
+	writeFiles(t, cache, map[string]string{
+		"github.com/acme/terraform-modules/v1.2.0/redis/main.tf": `
+variable "name" {}
+
+resource "aws_elasticache_cluster" "this" {
+  cluster_id = var.name
+}
+`,
+	})
+
+	mod, err := LoadDir(dir, &Options{FollowModules: true, ModuleCacheDir: cache})
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	for _, addr := range []string{
+		"module.vpc.aws_vpc.this",
+		"module.vpc.module.subnets.aws_subnet.this[1]",
+		"module.cache.aws_elasticache_cluster.this",
+	} {
+		if findInstance(mod, addr) == nil {
+			t.Errorf("expected %s", addr)
+		}
+	}
+
+	subnet := findInstance(mod, "module.vpc.module.subnets.aws_subnet.this[0]")
+	if subnet == nil {
+		t.Fatal("expected module.vpc.module.subnets.aws_subnet.this[0]")
+	}
+	if len(subnet.Dependencies) != 1 || subnet.Dependencies[0] != "module.vpc.aws_vpc.this" {
+		t.Errorf("subnet dependencies = %v, want [module.vpc.aws_vpc.this]", subnet.Dependencies)
+	}
+
+	if got := mod.ModulesFollowed(); got != 3 {
+		t.Errorf("ModulesFollowed() = %d, want 3", got)
+	}
+	if len(mod.Diagnostics) == 0 {
+		t.Error("expected a diagnostic for the unpinned git module")
+	}
+}
+
+func TestLoadDir_ModuleDepth(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf":   `module "a" { source = "./a" }`,
+		"a/main.tf": "resource \"aws_s3_bucket\" \"a\" {}\nmodule \"b\" { source = \"../b\" }",
+		"b/main.tf": `resource "aws_s3_bucket" "b" {}`,
+	})
+
+	mod, err := LoadDir(dir, &Options{FollowModules: true, MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if findInstance(mod, "module.a.aws_s3_bucket.a") == nil {
+		t.Error("expected module.a.aws_s3_bucket.a")
+	}
+	if findInstance(mod, "module.a.module.b.aws_s3_bucket.b") != nil {
+		t.Error("module.a.module.b should not be followed past MaxDepth")
+	}
+
+	mod, err = LoadDir(dir, &Options{})
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if len(mod.Resources) != 0 || mod.ModulesFollowed() != 0 {
+		t.Errorf("modules should not be followed when FollowModules is false")
+	}
+}
+
+func TestLoadTree_SkipsFollowedModules(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"envs/prod/main.tf": `
+module "app" {
+  source = "../../modules/app"
+}
+`,
+		"modules/app/main.tf": `resource "aws_s3_bucket" "this" {}`,
+		"tools/main.tf":       `resource "aws_sqs_queue" "jobs" {}`,
+	})
+
+	mods, err := LoadTree(dir, &Options{FollowModules: true}, false)
+	if err != nil {
+		t.Fatalf("LoadTree failed: %v", err)
+	}
+	if len(mods) != 2 {
+		t.Fatalf("expected 2 root modules, got %d", len(mods))
+	}
+	if mods[0].Dir != filepath.Join(dir, "envs", "prod") {
+		t.Errorf("first root = %s, want envs/prod", mods[0].Dir)
+	}
+	if findInstance(mods[0], "module.app.aws_s3_bucket.this") == nil {
+		t.Error("expected module.app.aws_s3_bucket.this")
+	}
+}
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		source string
+		repo   string
+		subdir string
+		ref    string
+	}{
+		{"git::https://github.com/acme/mods.git//db?ref=v1", "github.com/acme/mods", "db", "v1"},
+		{"git::ssh://git@gitlab.com/acme/mods.git?ref=abc123", "gitlab.com/acme/mods", "", "abc123"},
+		{"git@github.com:acme/mods.git//net/vpc?ref=v2.0.0", "github.com/acme/mods", "net/vpc", "v2.0.0"},
+		{"github.com/acme/mods", "github.com/acme/mods", "", ""},
+	}
+	for _, tt := range tests {
+		repo, subdir, ref, ok := parseGitSource(tt.source)
+		if !ok || repo != tt.repo || subdir != tt.subdir || ref != tt.ref {
+			t.Errorf("parseGitSource(%q) = %q, %q, %q, %v; want %q, %q, %q",
+				tt.source, repo, subdir, ref, ok, tt.repo, tt.subdir, tt.ref)
+		}
+	}
+	if _, _, _, ok := parseGitSource("terraform-aws-modules/vpc/aws"); ok {
+		t.Error("registry sources are not git sources")
+	}
+}
@@ -0,0 +1,244 @@
+package tfconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// variableSchema describes the attributes of a variable block we care about.
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "default"},
+		{Name: "type"},
+	},
+}
+
+// variableDecl is a declared input variable.
+type variableDecl struct {
+	name       string
+	typ        cty.Type
+	hasDefault bool
+	def        cty.Value
+}
+
+// resolveVariables determines the value of every declared variable using the
+// same precedence as Terraform: defaults, wrapper inputs, TF_VAR_ environment variables,
+// terraform.tfvars, terraform.tfvars.json, *.auto.tfvars(.json) in lexical
+// order, explicit var files, and finally -var overrides.
+// Variables without any value resolve to an unknown value.
+func resolveVariables(dir string, blocks hcl.Blocks, opts *Options) (map[string]cty.Value, hcl.Diagnostics) {
+	decls, diags := declareVariables(blocks)
+
+	values := make(map[string]cty.Value, len(decls))
+	for name, decl := range decls {
+		if decl.hasDefault {
+			values[name] = decl.def
+		}
+	}
+
+	// Inputs from wrappers, then environment variables
+	for name, val := range opts.Inputs {
+		if _, ok := decls[name]; ok {
+			values[name] = val
+		}
+	}
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, "TF_VAR_") {
+			continue
+		}
+		kv := strings.SplitN(strings.TrimPrefix(env, "TF_VAR_"), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		if decl, ok := decls[kv[0]]; ok {
+			values[kv[0]] = parseRawValue(kv[1], decl.typ)
+		}
+	}
+
+	// Auto-loaded variable files, then explicit var files
+	for _, path := range varFiles(dir, opts.VarFiles) {
+		fileVals, d := readVarFile(path)
+		diags = append(diags, d...)
+		for name, val := range fileVals {
+			if _, ok := decls[name]; ok {
+				values[name] = val
+			}
+		}
+	}
+
+	// -var overrides
+	names := make([]string, 0, len(opts.Variables))
+	for name := range opts.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if decl, ok := decls[name]; ok {
+			values[name] = parseRawValue(opts.Variables[name], decl.typ)
+		}
+	}
+
+	return finalizeVariables(decls, values, diags)
+}
+
+// moduleVariables determines the variables of a child module. Unlike a root
+// module, a child module only sees its defaults and the arguments of the
+// module block that calls it.
+func moduleVariables(blocks hcl.Blocks, inputs map[string]cty.Value) (map[string]cty.Value, hcl.Diagnostics) {
+	decls, diags := declareVariables(blocks)
+
+	values := make(map[string]cty.Value, len(decls))
+	for name, decl := range decls {
+		if decl.hasDefault {
+			values[name] = decl.def
+		}
+		if val, ok := inputs[name]; ok {
+			values[name] = val
+		}
+	}
+
+	return finalizeVariables(decls, values, diags)
+}
+
+// declareVariables reads the variable blocks of a module.
+func declareVariables(blocks hcl.Blocks) (map[string]*variableDecl, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	decls := make(map[string]*variableDecl)
+
+	for _, block := range blocks {
+		if len(block.Labels) == 0 {
+			continue
+		}
+		decl := &variableDecl{name: block.Labels[0], typ: cty.DynamicPseudoType}
+
+		content, _, d := block.Body.PartialContent(variableSchema)
+		diags = append(diags, d...)
+		if attr, ok := content.Attributes["type"]; ok {
+			if ty, d := typeexpr.TypeConstraint(attr.Expr); !d.HasErrors() {
+				decl.typ = ty
+			}
+		}
+		if attr, ok := content.Attributes["default"]; ok {
+			val, d := attr.Expr.Value(nil)
+			if d.HasErrors() {
+				diags = append(diags, d...)
+			} else {
+				decl.hasDefault = true
+				decl.def = val
+			}
+		}
+		decls[decl.name] = decl
+	}
+
+	return decls, diags
+}
+
+// finalizeVariables converts values to their declared types. Declared
+// variables without a value become unknown.
+func finalizeVariables(decls map[string]*variableDecl, values map[string]cty.Value, diags hcl.Diagnostics) (map[string]cty.Value, hcl.Diagnostics) {
+	for name, decl := range decls {
+		val, ok := values[name]
+		if !ok {
+			values[name] = cty.UnknownVal(decl.typ)
+			continue
+		}
+		if decl.typ != cty.DynamicPseudoType {
+			if converted, err := convert.Convert(val, decl.typ); err == nil {
+				val = converted
+			} else {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Invalid value for variable",
+					Detail:   fmt.Sprintf("variable %q: %s", name, err),
+				})
+			}
+		}
+		values[name] = val
+	}
+
+	return values, diags
+}
+
+// varFiles returns the variable files to load for dir, in precedence order.
+func varFiles(dir string, extra []string) []string {
+	var files []string
+	for _, name := range []string{"terraform.tfvars", "terraform.tfvars.json"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err == nil {
+		var auto []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() {
+				continue
+			}
+			if strings.HasSuffix(name, ".auto.tfvars") || strings.HasSuffix(name, ".auto.tfvars.json") {
+				auto = append(auto, filepath.Join(dir, name))
+			}
+		}
+		sort.Strings(auto)
+		files = append(files, auto...)
+	}
+
+	return append(files, extra...)
+}
+
+// readVarFile reads a .tfvars or .tfvars.json file.
+func readVarFile(path string) (map[string]cty.Value, hcl.Diagnostics) {
+	p := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		file, diags = p.ParseJSONFile(path)
+	} else {
+		file, diags = p.ParseHCLFile(path)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	values := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		val, d := attr.Expr.Value(nil)
+		diags = append(diags, d...)
+		if !d.HasErrors() {
+			values[name] = val
+		}
+	}
+	return values, diags
+}
+
+// parseRawValue interprets a raw string variable value the way Terraform does
+// for -var and TF_VAR_: strings are taken literally, anything else is parsed
+// as an HCL expression.
+func parseRawValue(raw string, ty cty.Type) cty.Value {
+	if ty == cty.String || ty == cty.DynamicPseudoType {
+		return cty.StringVal(raw)
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(raw), "<value>", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.StringVal(raw)
+	}
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.StringVal(raw)
+	}
+	return val
+}
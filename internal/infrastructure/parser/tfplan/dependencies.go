@@ -0,0 +1,219 @@
+package tfplan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// skippedRoots are reference roots that never refer to a resource.
+var skippedRoots = map[string]bool{
+	"var":       true,
+	"local":     true,
+	"path":      true,
+	"terraform": true,
+	"count":     true,
+	"each":      true,
+	"self":      true,
+	"data":      true,
+}
+
+// linkDependencies fills in resource dependencies from the references and
+// depends_on lists of the configuration section. References are resolved
+// in the module instance of each resource, so module.app["a"] resources
+// only depend on resources of module.app["a"].
+func (p *Plan) linkDependencies(resources []*Resource) {
+	if p.Configuration == nil || p.Configuration.RootModule == nil {
+		return
+	}
+
+	refs := make(map[string][]string)
+	var walk func(path string, mod *ConfigModule)
+	walk = func(path string, mod *ConfigModule) {
+		if mod == nil {
+			return
+		}
+		for _, cr := range mod.Resources {
+			var list []string
+			collectReferences(cr.Expressions, &list)
+			list = append(list, cr.DependsOn...)
+			refs[joinAddress(path, cr.Address)] = list
+		}
+		for name, call := range mod.ModuleCalls {
+			walk(joinAddress(path, "module."+name), call.Module)
+		}
+	}
+	walk("", p.Configuration.RootModule)
+
+	byAddress := make(map[string]*Resource, len(resources))
+	byBase := make(map[string][]*Resource)
+	for _, res := range resources {
+		if res.Mode != "managed" {
+			continue
+		}
+		byAddress[res.Address] = res
+		base := baseAddress(res)
+		byBase[base] = append(byBase[base], res)
+	}
+
+	for _, res := range resources {
+		if res.Mode != "managed" {
+			continue
+		}
+		key := joinAddress(stripKeys(res.ModuleAddress), res.Type+"."+res.Name)
+
+		seen := map[string]bool{res.Address: true}
+		var deps []string
+		add := func(dep *Resource) {
+			if !seen[dep.Address] {
+				seen[dep.Address] = true
+				deps = append(deps, dep.Address)
+			}
+		}
+
+		for _, ref := range refs[key] {
+			target, index, isModule := parseReference(ref)
+			if target == "" {
+				continue
+			}
+			target = joinAddress(res.ModuleAddress, target)
+
+			if isModule {
+				for _, other := range resources {
+					if other.Mode != "managed" {
+						continue
+					}
+					if (index != "" && strings.HasPrefix(other.Address, target+index+".")) ||
+						(index == "" && (strings.HasPrefix(other.Address, target+".") || strings.HasPrefix(other.Address, target+"["))) {
+						add(other)
+					}
+				}
+				continue
+			}
+
+			if index != "" {
+				if dep, ok := byAddress[target+index]; ok {
+					add(dep)
+					continue
+				}
+			}
+			for _, dep := range byBase[target] {
+				add(dep)
+			}
+		}
+
+		sort.Strings(deps)
+		res.Dependencies = deps
+	}
+}
+
+// collectReferences gathers every "references" list in a tree of
+// configuration expressions.
+func collectReferences(v interface{}, refs *[]string) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if k == "references" {
+				if list, ok := child.([]interface{}); ok {
+					for _, item := range list {
+						if s, ok := item.(string); ok {
+							*refs = append(*refs, s)
+						}
+					}
+				}
+				continue
+			}
+			collectReferences(child, refs)
+		}
+	case []interface{}:
+		for _, child := range val {
+			collectReferences(child, refs)
+		}
+	}
+}
+
+// parseReference returns the resource or module address a reference points
+// to (without instance key), the literal instance key in brackets if any,
+// and whether it refers to a module. Non-resource references return an
+// empty target.
+func parseReference(ref string) (target, index string, isModule bool) {
+	root, rest := identifier(ref)
+	if skippedRoots[root] || !strings.HasPrefix(rest, ".") {
+		return "", "", false
+	}
+	name, rest := identifier(rest[1:])
+	if name == "" {
+		return "", "", false
+	}
+	if strings.HasPrefix(rest, "[") {
+		if end := closingBracket(rest); end > 0 {
+			index = rest[:end+1]
+		}
+	}
+	return root + "." + name, index, root == "module"
+}
+
+// identifier splits a leading identifier off s.
+func identifier(s string) (string, string) {
+	i := strings.IndexAny(s, ".[")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// closingBracket returns the index of the bracket closing s[0], skipping
+// over quoted strings, or -1.
+func closingBracket(s string) int {
+	inString := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case inString && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			inString = !inString
+		case !inString && s[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// stripKeys removes instance keys from a module address, turning
+// module.app["a"].module.db[0] into module.app.module.db.
+func stripKeys(addr string) string {
+	var sb strings.Builder
+	for i := 0; i < len(addr); i++ {
+		if addr[i] == '[' {
+			if end := closingBracket(addr[i:]); end > 0 {
+				i += end
+				continue
+			}
+		}
+		sb.WriteByte(addr[i])
+	}
+	return sb.String()
+}
+
+// baseAddress returns the address of a resource without its instance key.
+func baseAddress(res *Resource) string {
+	if res.Index == nil {
+		return res.Address
+	}
+	var suffix string
+	switch idx := res.Index.(type) {
+	case string:
+		suffix = fmt.Sprintf("[%q]", idx)
+	default:
+		suffix = fmt.Sprintf("[%v]", idx)
+	}
+	return strings.TrimSuffix(res.Address, suffix)
+}
+
+// joinAddress joins a module address and a relative address.
+func joinAddress(module, addr string) string {
+	if module == "" {
+		return addr
+	}
+	return module + "." + addr
+}
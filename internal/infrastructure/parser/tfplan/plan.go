@@ -0,0 +1,272 @@
+// Package tfplan reads Terraform plan JSON, as produced by
+// `terraform show -json plan.out`.
+//
+// A plan already has every variable, local, module and count/for_each
+// resolved, so provider parsers can take resource values as Terraform will
+// apply them. Resources are flattened across modules and annotated with the
+// change Terraform plans to make to them.
+package tfplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultFiles are the plan file names looked up when a directory is given.
+var DefaultFiles = []string{"tfplan.json", "plan.json", "terraform.tfplan.json"}
+
+// Action is the change Terraform plans for a resource.
+type Action string
+
+// Planned actions.
+const (
+	ActionNoOp    Action = "no-op"
+	ActionCreate  Action = "create"
+	ActionRead    Action = "read"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionReplace Action = "replace"
+	ActionForget  Action = "forget"
+)
+
+// Plan is a Terraform JSON plan.
+type Plan struct {
+	FormatVersion    string           `json:"format_version"`
+	TerraformVersion string           `json:"terraform_version"`
+	PlannedValues    *Values          `json:"planned_values"`
+	ResourceChanges  []ResourceChange `json:"resource_changes"`
+	Configuration    *Configuration   `json:"configuration"`
+}
+
+// Values is the planned_values section.
+type Values struct {
+	RootModule *ValuesModule `json:"root_module"`
+}
+
+// ValuesModule is a module in planned_values.
+type ValuesModule struct {
+	Address      string          `json:"address"`
+	Resources    []ValueResource `json:"resources"`
+	ChildModules []*ValuesModule `json:"child_modules"`
+}
+
+// ValueResource is a resource instance in planned_values.
+type ValueResource struct {
+	Address      string                 `json:"address"`
+	Mode         string                 `json:"mode"`
+	Type         string                 `json:"type"`
+	Name         string                 `json:"name"`
+	Index        interface{}            `json:"index,omitempty"`
+	ProviderName string                 `json:"provider_name"`
+	Values       map[string]interface{} `json:"values"`
+}
+
+// ResourceChange is an entry of resource_changes.
+type ResourceChange struct {
+	Address       string      `json:"address"`
+	ModuleAddress string      `json:"module_address,omitempty"`
+	Mode          string      `json:"mode"`
+	Type          string      `json:"type"`
+	Name          string      `json:"name"`
+	Index         interface{} `json:"index,omitempty"`
+	ProviderName  string      `json:"provider_name"`
+	Change        Change      `json:"change"`
+}
+
+// Change describes the planned change of a resource.
+type Change struct {
+	Actions []string               `json:"actions"`
+	Before  map[string]interface{} `json:"before"`
+	After   map[string]interface{} `json:"after"`
+}
+
+// Configuration is the configuration section of a plan.
+type Configuration struct {
+	RootModule *ConfigModule `json:"root_module"`
+}
+
+// ConfigModule is a module in the configuration section.
+type ConfigModule struct {
+	Resources   []ConfigResource       `json:"resources"`
+	ModuleCalls map[string]*ModuleCall `json:"module_calls"`
+}
+
+// ConfigResource is a resource block in the configuration section.
+type ConfigResource struct {
+	Address     string                 `json:"address"`
+	Mode        string                 `json:"mode"`
+	Type        string                 `json:"type"`
+	Name        string                 `json:"name"`
+	Expressions map[string]interface{} `json:"expressions"`
+	DependsOn   []string               `json:"depends_on"`
+}
+
+// ModuleCall is a module block in the configuration section.
+type ModuleCall struct {
+	Source    string        `json:"source"`
+	Module    *ConfigModule `json:"module"`
+	DependsOn []string      `json:"depends_on"`
+}
+
+// Resource is a flattened resource instance of a plan.
+type Resource struct {
+	// Address is the full instance address, e.g. `module.db.aws_db_instance.this`.
+	Address string
+
+	// ModuleAddress is the address of the containing module instance, or ""
+	// for the root module.
+	ModuleAddress string
+
+	Mode  string
+	Type  string
+	Name  string
+	Index interface{}
+
+	// Values are the planned attribute values. For resources that are about
+	// to be deleted they are the values before the change.
+	Values map[string]interface{}
+
+	// Action is the planned change.
+	Action Action
+
+	// Dependencies are the addresses of the managed resource instances this
+	// instance references or lists in depends_on.
+	Dependencies []string
+}
+
+// Creates reports whether applying the plan creates the resource.
+func (r *Resource) Creates() bool {
+	return r.Action == ActionCreate || r.Action == ActionReplace
+}
+
+// Destroys reports whether applying the plan destroys the resource.
+func (r *Resource) Destroys() bool {
+	return r.Action == ActionDelete || r.Action == ActionReplace
+}
+
+// Find returns the plan file for path: path itself, or one of DefaultFiles
+// when path is a directory.
+func Find(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, name := range DefaultFiles {
+		candidate := filepath.Join(path, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no terraform plan found in %s", path)
+}
+
+// Load reads a JSON plan file.
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+	return Decode(data)
+}
+
+// Decode parses JSON plan data.
+func Decode(data []byte) (*Plan, error) {
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+	if plan.FormatVersion == "" || (plan.PlannedValues == nil && plan.ResourceChanges == nil) {
+		return nil, fmt.Errorf("not a terraform JSON plan (use `terraform show -json`)")
+	}
+	return &plan, nil
+}
+
+// Resources returns all resource instances of the plan sorted by address.
+// Resources that are about to be deleted are included with their current
+// values.
+func (p *Plan) Resources() []*Resource {
+	byAddress := make(map[string]*Resource)
+
+	var walk func(mod *ValuesModule)
+	walk = func(mod *ValuesModule) {
+		if mod == nil {
+			return
+		}
+		for _, vr := range mod.Resources {
+			byAddress[vr.Address] = &Resource{
+				Address:       vr.Address,
+				ModuleAddress: mod.Address,
+				Mode:          vr.Mode,
+				Type:          vr.Type,
+				Name:          vr.Name,
+				Index:         vr.Index,
+				Values:        vr.Values,
+				Action:        ActionNoOp,
+			}
+		}
+		for _, child := range mod.ChildModules {
+			walk(child)
+		}
+	}
+	if p.PlannedValues != nil {
+		walk(p.PlannedValues.RootModule)
+	}
+
+	for _, rc := range p.ResourceChanges {
+		action := changeAction(rc.Change.Actions)
+		res, ok := byAddress[rc.Address]
+		if !ok {
+			values := rc.Change.After
+			if action == ActionDelete || action == ActionForget {
+				values = rc.Change.Before
+			}
+			res = &Resource{
+				Address:       rc.Address,
+				ModuleAddress: rc.ModuleAddress,
+				Mode:          rc.Mode,
+				Type:          rc.Type,
+				Name:          rc.Name,
+				Index:         rc.Index,
+				Values:        values,
+			}
+			byAddress[rc.Address] = res
+		}
+		res.Action = action
+	}
+
+	resources := make([]*Resource, 0, len(byAddress))
+	for _, res := range byAddress {
+		if res.Values == nil {
+			res.Values = make(map[string]interface{})
+		}
+		resources = append(resources, res)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Address < resources[j].Address
+	})
+
+	p.linkDependencies(resources)
+	return resources
+}
+
+// changeAction maps the action list of a change to a single action.
+func changeAction(actions []string) Action {
+	switch len(actions) {
+	case 0:
+		return ActionNoOp
+	case 1:
+		return Action(actions[0])
+	}
+	if len(actions) == 2 &&
+		((actions[0] == "delete" && actions[1] == "create") || (actions[0] == "create" && actions[1] == "delete")) {
+		return ActionReplace
+	}
+	return Action(strings.Join(actions, "-"))
+}
@@ -0,0 +1,211 @@
+package tfplan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPlan = `{
+  "format_version": "1.2",
+  "terraform_version": "1.6.6",
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "aws_subnet.private[0]",
+          "mode": "managed", "type": "aws_subnet", "name": "private", "index": 0,
+          "values": {"cidr_block": "10.0.0.0/24"}
+        },
+        {
+          "address": "aws_subnet.private[1]",
+          "mode": "managed", "type": "aws_subnet", "name": "private", "index": 1,
+          "values": {"cidr_block": "10.0.1.0/24"}
+        },
+        {
+          "address": "aws_route53_record.db",
+          "mode": "managed", "type": "aws_route53_record", "name": "db",
+          "values": {"name": "db.example.com"}
+        }
+      ],
+      "child_modules": [
+        {
+          "address": "module.db",
+          "resources": [
+            {
+              "address": "module.db.aws_db_instance.this",
+              "mode": "managed", "type": "aws_db_instance", "name": "this",
+              "values": {"identifier": "orders-db", "engine": "postgres"}
+            },
+            {
+              "address": "module.db.aws_db_subnet_group.this",
+              "mode": "managed", "type": "aws_db_subnet_group", "name": "this",
+              "values": {"name": "orders"}
+            }
+          ]
+        }
+      ]
+    }
+  },
+  "resource_changes": [
+    {
+      "address": "aws_subnet.private[0]",
+      "mode": "managed", "type": "aws_subnet", "name": "private", "index": 0,
+      "change": {"actions": ["no-op"]}
+    },
+    {
+      "address": "module.db.aws_db_instance.this",
+      "module_address": "module.db",
+      "mode": "managed", "type": "aws_db_instance", "name": "this",
+      "change": {"actions": ["create"], "before": null, "after": {"identifier": "orders-db"}}
+    },
+    {
+      "address": "module.db.aws_db_subnet_group.this",
+      "module_address": "module.db",
+      "mode": "managed", "type": "aws_db_subnet_group", "name": "this",
+      "change": {"actions": ["delete", "create"]}
+    },
+    {
+      "address": "aws_s3_bucket.legacy",
+      "mode": "managed", "type": "aws_s3_bucket", "name": "legacy",
+      "change": {"actions": ["delete"], "before": {"bucket": "legacy-assets"}, "after": null}
+    }
+  ],
+  "configuration": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "aws_route53_record.db",
+          "mode": "managed", "type": "aws_route53_record", "name": "db",
+          "expressions": {"records": {"references": ["module.db.address", "module.db"]}}
+        }
+      ],
+      "module_calls": {
+        "db": {
+          "source": "./modules/db",
+          "module": {
+            "resources": [
+              {
+                "address": "aws_db_instance.this",
+                "mode": "managed", "type": "aws_db_instance", "name": "this",
+                "expressions": {
+                  "db_subnet_group_name": {"references": ["aws_db_subnet_group.this.name", "aws_db_subnet_group.this"]},
+                  "engine": {"constant_value": "postgres"}
+                }
+              },
+              {
+                "address": "aws_db_subnet_group.this",
+                "mode": "managed", "type": "aws_db_subnet_group", "name": "this",
+                "expressions": {"subnet_ids": {"references": ["var.subnet_ids"]}}
+              }
+            ]
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestPlanResources(t *testing.T) {
+	plan, err := Decode([]byte(testPlan))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	resources := plan.Resources()
+	if len(resources) != 6 {
+		t.Fatalf("expected 6 resources, got %d", len(resources))
+	}
+	byAddress := make(map[string]*Resource)
+	for _, res := range resources {
+		byAddress[res.Address] = res
+	}
+
+	tests := []struct {
+		address  string
+		action   Action
+		creates  bool
+		destroys bool
+	}{
+		{"aws_subnet.private[0]", ActionNoOp, false, false},
+		{"aws_subnet.private[1]", ActionNoOp, false, false},
+		{"module.db.aws_db_instance.this", ActionCreate, true, false},
+		{"module.db.aws_db_subnet_group.this", ActionReplace, true, true},
+		{"aws_s3_bucket.legacy", ActionDelete, false, true},
+	}
+	for _, tt := range tests {
+		res, ok := byAddress[tt.address]
+		if !ok {
+			t.Errorf("missing %s", tt.address)
+			continue
+		}
+		if res.Action != tt.action || res.Creates() != tt.creates || res.Destroys() != tt.destroys {
+			t.Errorf("%s: action=%s creates=%v destroys=%v, want %s %v %v",
+				tt.address, res.Action, res.Creates(), res.Destroys(), tt.action, tt.creates, tt.destroys)
+		}
+	}
+
+	if got := byAddress["aws_s3_bucket.legacy"].Values["bucket"]; got != "legacy-assets" {
+		t.Errorf("deleted resource values = %v, want before values", got)
+	}
+	if got := byAddress["module.db.aws_db_instance.this"].ModuleAddress; got != "module.db" {
+		t.Errorf("ModuleAddress = %q, want module.db", got)
+	}
+
+	db := byAddress["module.db.aws_db_instance.this"]
+	if len(db.Dependencies) != 1 || db.Dependencies[0] != "module.db.aws_db_subnet_group.this" {
+		t.Errorf("db dependencies = %v", db.Dependencies)
+	}
+	record := byAddress["aws_route53_record.db"]
+	if len(record.Dependencies) != 2 {
+		t.Errorf("record dependencies = %v, want both module.db resources", record.Dependencies)
+	}
+}
+
+func TestFindAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Find(dir); err == nil {
+		t.Error("expected an error for a directory without a plan")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tfplan.json"), []byte(testPlan), 0644); err != nil {
+		t.Fatalf("failed to write plan: %v", err)
+	}
+
+	path, err := Find(dir)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	plan, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if plan.TerraformVersion != "1.6.6" {
+		t.Errorf("TerraformVersion = %q", plan.TerraformVersion)
+	}
+
+	if _, err := Decode([]byte(`{"version": 4, "resources": []}`)); err == nil {
+		t.Error("expected state JSON to be rejected")
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		ref, target, index string
+		module             bool
+	}{
+		{"aws_subnet.a[0].id", "aws_subnet.a", "[0]", false},
+		{`aws_s3_bucket.b["x.y"]`, "aws_s3_bucket.b", `["x.y"]`, false},
+		{"module.db.endpoint", "module.db", "", true},
+		{"var.name", "", "", false},
+		{"data.aws_ami.ubuntu.id", "", "", false},
+	}
+	for _, tt := range tests {
+		target, index, module := parseReference(tt.ref)
+		if target != tt.target || index != tt.index || module != tt.module {
+			t.Errorf("parseReference(%q) = %q, %q, %v", tt.ref, target, index, module)
+		}
+	}
+	if got := stripKeys(`module.app["a.b"].module.db[0]`); got != "module.app.module.db" {
+		t.Errorf("stripKeys = %q", got)
+	}
+}
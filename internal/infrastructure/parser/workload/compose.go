@@ -0,0 +1,571 @@
+package workload
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+)
+
+// ComposeFiles are the file names docker compose looks for, in order.
+var ComposeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// projectNamePattern matches characters not allowed in compose project names.
+var projectNamePattern = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// FindCompose returns the compose file at path, or the first of
+// ComposeFiles in the directory path, followed by its override file if
+// one exists next to it.
+func FindCompose(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := path
+	if info.IsDir() {
+		file = ""
+		for _, name := range ComposeFiles {
+			candidate := filepath.Join(path, name)
+			if _, err := os.Stat(candidate); err == nil {
+				file = candidate
+				break
+			}
+		}
+		if file == "" {
+			return nil, fmt.Errorf("no compose file found in %s", path)
+		}
+	}
+
+	files := []string{file}
+	ext := filepath.Ext(file)
+	override := strings.TrimSuffix(file, ext) + ".override" + ext
+	if _, err := os.Stat(override); err == nil {
+		files = append(files, override)
+	}
+	return files, nil
+}
+
+// IsComposeFile reports whether data is a compose file: a top-level
+// services map whose entries have an image or build.
+func IsComposeFile(data []byte) bool {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	services := mapOf(doc["services"])
+	if len(services) == 0 {
+		return false
+	}
+	for _, svc := range services {
+		m := mapOf(svc)
+		if m == nil {
+			return false
+		}
+		if _, ok := m["image"]; !ok {
+			if _, ok := m["build"]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// LoadCompose loads the compose project at path, a compose file or a
+// directory holding one. Override files are merged in and ${VAR}
+// references are interpolated from .env and the environment.
+func LoadCompose(path string) (*Project, error) {
+	files, err := FindCompose(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(files[0])
+
+	env := make(map[string]string)
+	if dotenv, err := readEnvFile(filepath.Join(dir, ".env")); err == nil {
+		env = dotenv
+	}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	var doc map[string]interface{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var m map[string]interface{}
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		m = mapOf(interpolateValue(m, env))
+		if doc == nil {
+			doc = m
+		} else {
+			doc = mergeMaps(doc, m)
+		}
+	}
+
+	services := mapOf(doc["services"])
+	if len(services) == 0 {
+		return nil, fmt.Errorf("%s defines no services", files[0])
+	}
+
+	name := str(doc["name"])
+	if name == "" {
+		name = env["COMPOSE_PROJECT_NAME"]
+	}
+	if name == "" {
+		abs, _ := filepath.Abs(dir)
+		name = filepath.Base(abs)
+	}
+	name = projectNamePattern.ReplaceAllString(strings.ToLower(name), "")
+
+	project := &Project{
+		Name:   name,
+		Format: parser.FormatDockerCompose,
+		Files:  files,
+	}
+
+	hosts := make(map[string][]string)
+	for svcName, raw := range services {
+		w, err := composeWorkload(project.Name, svcName, mapOf(raw), dir)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", svcName, err)
+		}
+		project.Workloads = append(project.Workloads, w)
+		hosts[svcName] = append(hosts[svcName], w.ID)
+		for _, alias := range networkAliases(mapOf(raw)) {
+			hosts[alias] = append(hosts[alias], w.ID)
+		}
+	}
+	linkByHost(project.Workloads, hosts, nil)
+	sortWorkloads(project.Workloads)
+
+	return project, nil
+}
+
+// composeWorkload converts one compose service.
+func composeWorkload(projectName, name string, svc map[string]interface{}, dir string) (*Workload, error) {
+	w := &Workload{
+		ID:       projectName + "/" + name,
+		Name:     name,
+		Kind:     KindComposeService,
+		Image:    str(svc["image"]),
+		Env:      make(map[string]string),
+		Secrets:  make(map[string]string),
+		Labels:   stringMap(svc["labels"]),
+		Replicas: 1,
+	}
+
+	switch build := svc["build"].(type) {
+	case string:
+		w.Build = build
+	case map[string]interface{}:
+		w.Build = str(build["context"])
+	}
+
+	w.Command = append(stringList(svc["entrypoint"]), stringList(svc["command"])...)
+
+	var envFiles []string
+	switch ef := svc["env_file"].(type) {
+	case string:
+		envFiles = []string{ef}
+	case []interface{}:
+		for _, item := range ef {
+			if m := mapOf(item); m != nil {
+				envFiles = append(envFiles, str(m["path"]))
+			} else {
+				envFiles = append(envFiles, str(item))
+			}
+		}
+	}
+	for _, file := range envFiles {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		values, err := readEnvFile(file)
+		if err != nil {
+			continue
+		}
+		for k, v := range values {
+			w.Env[k] = v
+		}
+	}
+	for k, v := range stringMap(svc["environment"]) {
+		w.Env[k] = v
+	}
+
+	for _, item := range listOf(svc["ports"]) {
+		w.Ports = append(w.Ports, composePort(item)...)
+	}
+	for _, item := range listOf(svc["expose"]) {
+		for _, port := range composePort(str(item)) {
+			port.Published = 0
+			w.Ports = append(w.Ports, port)
+		}
+	}
+	for _, port := range w.Ports {
+		if port.Published > 0 {
+			w.Public = true
+		}
+	}
+
+	for _, item := range listOf(svc["volumes"]) {
+		if mount, ok := composeMount(item); ok {
+			w.Mounts = append(w.Mounts, mount)
+		}
+	}
+
+	deps := make(map[string]bool)
+	switch dependsOn := svc["depends_on"].(type) {
+	case []interface{}:
+		for _, dep := range dependsOn {
+			deps[str(dep)] = true
+		}
+	case map[string]interface{}:
+		for dep := range dependsOn {
+			deps[dep] = true
+		}
+	}
+	for _, link := range listOf(svc["links"]) {
+		dep, _, _ := strings.Cut(str(link), ":")
+		deps[dep] = true
+	}
+	for dep := range deps {
+		if dep != "" && dep != name {
+			w.DependsOn = append(w.DependsOn, projectName+"/"+dep)
+		}
+	}
+
+	if hc := mapOf(svc["healthcheck"]); hc != nil {
+		w.HealthCheck = composeHealthCheck(hc)
+	}
+
+	deploy := mapOf(svc["deploy"])
+	if replicas, ok := deploy["replicas"]; ok {
+		w.Replicas = num(replicas)
+	} else if scale, ok := svc["scale"]; ok {
+		w.Replicas = num(scale)
+	}
+	for k, v := range stringMap(deploy["labels"]) {
+		w.Labels[k] = v
+	}
+
+	limits := mapOf(mapOf(deploy["resources"])["limits"])
+	cpus, memory := str(limits["cpus"]), str(limits["memory"])
+	if cpus == "" {
+		cpus = str(svc["cpus"])
+	}
+	if memory == "" {
+		memory = str(svc["mem_limit"])
+	}
+	var err error
+	if w.CPU, err = parseCPU(cpus); err != nil {
+		return nil, err
+	}
+	if w.MemoryMB, err = parseMemoryMB(memory, false); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// composePort parses a port in short ("8080:80/tcp", "127.0.0.1:8080:80",
+// "80") or long syntax.
+func composePort(item interface{}) []Port {
+	if m := mapOf(item); m != nil {
+		port := Port{
+			Container: num(m["target"]),
+			Published: rangeStart(str(m["published"])),
+			Protocol:  str(m["protocol"]),
+		}
+		if port.Container == 0 {
+			return nil
+		}
+		return []Port{port}
+	}
+
+	spec, protocol, _ := strings.Cut(str(item), "/")
+	parts := strings.Split(spec, ":")
+	port := Port{Container: rangeStart(parts[len(parts)-1]), Protocol: protocol}
+	if len(parts) >= 2 {
+		port.Published = rangeStart(parts[len(parts)-2])
+	}
+	if port.Container == 0 {
+		return nil
+	}
+	return []Port{port}
+}
+
+// rangeStart returns the first port of a port or port range.
+func rangeStart(s string) int {
+	start, _, _ := strings.Cut(s, "-")
+	return num(start)
+}
+
+// composeMount parses a volume in short ("data:/var/lib/data:ro",
+// "./conf:/etc/app") or long syntax. Anonymous volumes use the target as
+// source.
+func composeMount(item interface{}) (Mount, bool) {
+	if m := mapOf(item); m != nil {
+		mount := Mount{
+			Source:   str(m["source"]),
+			Target:   str(m["target"]),
+			ReadOnly: str(m["read_only"]) == "true",
+		}
+		if mount.Source == "" {
+			mount.Source = mount.Target
+		}
+		return mount, mount.Target != ""
+	}
+
+	parts := strings.Split(str(item), ":")
+	switch len(parts) {
+	case 1:
+		return Mount{Source: parts[0], Target: parts[0]}, parts[0] != ""
+	case 2:
+		return Mount{Source: parts[0], Target: parts[1]}, true
+	default:
+		return Mount{Source: parts[0], Target: parts[1], ReadOnly: strings.Contains(parts[2], "ro")}, true
+	}
+}
+
+// composeHealthCheck converts a compose healthcheck.
+func composeHealthCheck(hc map[string]interface{}) *HealthCheck {
+	if str(hc["disable"]) == "true" {
+		return nil
+	}
+
+	var test []string
+	switch t := hc["test"].(type) {
+	case string:
+		test = []string{"CMD-SHELL", t}
+	case []interface{}:
+		test = stringList(t)
+	}
+	if len(test) == 0 || test[0] == "NONE" {
+		return nil
+	}
+
+	check := &HealthCheck{Test: test, Retries: num(hc["retries"])}
+	check.Interval, _ = time.ParseDuration(str(hc["interval"]))
+	check.Timeout, _ = time.ParseDuration(str(hc["timeout"]))
+	return check
+}
+
+// networkAliases returns the aliases of a service on its networks.
+func networkAliases(svc map[string]interface{}) []string {
+	var aliases []string
+	for _, network := range mapOf(svc["networks"]) {
+		for _, alias := range listOf(mapOf(network)["aliases"]) {
+			aliases = append(aliases, str(alias))
+		}
+	}
+	return aliases
+}
+
+// readEnvFile reads a KEY=VALUE file. Blank lines and comments are
+// skipped and matching quotes around values are removed.
+func readEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		values[strings.TrimSpace(k)] = v
+	}
+	return values, scanner.Err()
+}
+
+// interpolateValue interpolates every string in a decoded YAML value.
+func interpolateValue(v interface{}, env map[string]string) interface{} {
+	switch val := v.(type) {
+	case string:
+		return interpolate(val, env)
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = interpolateValue(item, env)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = interpolateValue(item, env)
+		}
+		return val
+	}
+	return v
+}
+
+// interpolate expands $VAR, ${VAR}, ${VAR:-default}, ${VAR-default},
+// ${VAR:+alt}, ${VAR+alt} and ${VAR:?err} references. $$ is a literal $.
+func interpolate(s string, env map[string]string) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		switch next := s[i+1]; {
+		case next == '$':
+			sb.WriteByte('$')
+			i++
+
+		case next == '{':
+			end := closingBrace(s, i+1)
+			if end < 0 {
+				sb.WriteString(s[i:])
+				return sb.String()
+			}
+			sb.WriteString(expandVariable(s[i+2:end], env))
+			i = end
+
+		case isNameChar(next, true):
+			j := i + 1
+			for j < len(s) && isNameChar(s[j], false) {
+				j++
+			}
+			sb.WriteString(env[s[i+1:j]])
+			i = j - 1
+
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String()
+}
+
+// expandVariable expands the body of a ${...} reference.
+func expandVariable(expr string, env map[string]string) string {
+	j := 0
+	for j < len(expr) && isNameChar(expr[j], j == 0) {
+		j++
+	}
+	name, rest := expr[:j], expr[j:]
+	val, set := env[name]
+
+	switch {
+	case strings.HasPrefix(rest, ":-"):
+		if !set || val == "" {
+			return interpolate(rest[2:], env)
+		}
+	case strings.HasPrefix(rest, "-"):
+		if !set {
+			return interpolate(rest[1:], env)
+		}
+	case strings.HasPrefix(rest, ":+"):
+		if set && val != "" {
+			return interpolate(rest[2:], env)
+		}
+		return ""
+	case strings.HasPrefix(rest, "+"):
+		if set {
+			return interpolate(rest[1:], env)
+		}
+		return ""
+	}
+	return val
+}
+
+// closingBrace returns the index of the brace closing the one at open.
+func closingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isNameChar reports whether c may appear in a variable name.
+func isNameChar(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
+
+// mergeMaps merges override into base: nested maps are merged, anything
+// else is replaced.
+func mergeMaps(base, override map[string]interface{}) map[string]interface{} {
+	for k, v := range override {
+		if bm, ok := base[k].(map[string]interface{}); ok {
+			if om, ok := v.(map[string]interface{}); ok {
+				base[k] = mergeMaps(bm, om)
+				continue
+			}
+		}
+		base[k] = v
+	}
+	return base
+}
+
+// linkByHost adds dependencies between workloads whose environment or
+// command mention each other's host names. hosts maps host names to the
+// IDs of the workloads behind them; extra holds more text to scan per ID.
+func linkByHost(workloads []*Workload, hosts map[string][]string, extra map[string][]string) {
+	for _, w := range workloads {
+		seen := make(map[string]bool, len(w.DependsOn))
+		for _, dep := range w.DependsOn {
+			seen[dep] = true
+		}
+
+		texts := append(append([]string{}, w.Command...), extra[w.ID]...)
+		keys := make([]string, 0, len(w.Env))
+		for k := range w.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			texts = append(texts, w.Env[k])
+		}
+
+		for _, text := range texts {
+			for _, token := range hostTokens(text) {
+				for _, id := range hosts[token] {
+					if id != w.ID && !seen[id] {
+						seen[id] = true
+						w.DependsOn = append(w.DependsOn, id)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,643 @@
+package workload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+)
+
+// Markers of Helm charts and their rendered output.
+const (
+	helmChartLabel    = "helm.sh/chart"
+	managedByLabel    = "app.kubernetes.io/managed-by"
+	helmInstanceLabel = "app.kubernetes.io/instance"
+	helmReleaseLabel  = "release"
+	helmSourceComment = "# Source: "
+	chartFile         = "Chart.yaml"
+	chartTemplatesDir = "templates"
+)
+
+// Kubernetes kinds and service types with special handling.
+const (
+	defaultNamespace = "default"
+
+	kindList      = "List"
+	kindCronJob   = "CronJob"
+	kindPod       = "Pod"
+	kindDaemonSet = "DaemonSet"
+	kindService   = "Service"
+	kindIngress   = "Ingress"
+	kindConfigMap = "ConfigMap"
+	kindSecret    = "Secret"
+
+	serviceLoadBalancer = "LoadBalancer"
+	serviceNodePort     = "NodePort"
+)
+
+// workloadKinds are the kinds that run containers.
+var workloadKinds = map[string]bool{
+	"Deployment":            true,
+	"StatefulSet":           true,
+	"DaemonSet":             true,
+	"ReplicaSet":            true,
+	"ReplicationController": true,
+	"Job":                   true,
+	"CronJob":               true,
+	"Pod":                   true,
+}
+
+// object is a decoded Kubernetes object.
+type object struct {
+	kind      string
+	name      string
+	namespace string
+	labels    map[string]string
+	body      map[string]interface{}
+}
+
+// spec returns the spec of the object.
+func (o *object) spec() map[string]interface{} {
+	return mapOf(o.body["spec"])
+}
+
+// key returns the namespaced name of the object.
+func (o *object) key() string {
+	return o.namespace + "/" + o.name
+}
+
+// ManifestFiles returns the YAML and JSON files at path. Hidden directories
+// and the unrendered templates directory of Helm charts are skipped.
+func ManifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if p != path && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if info.Name() == chartTemplatesDir {
+				if _, err := os.Stat(filepath.Join(filepath.Dir(p), chartFile)); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// decodeManifests decodes the Kubernetes objects of a YAML stream. It also
+// reports whether the stream carries the "# Source:" comments of helm
+// template output.
+func decodeManifests(data []byte) ([]*object, bool, error) {
+	helm := bytes.HasPrefix(data, []byte(helmSourceComment)) ||
+		bytes.Contains(data, []byte("\n"+helmSourceComment))
+
+	var objects []*object
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]interface{}
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		objects = append(objects, toObjects(doc)...)
+	}
+	return objects, helm, nil
+}
+
+// toObjects converts a decoded document, expanding List kinds.
+func toObjects(doc map[string]interface{}) []*object {
+	kind := str(doc["kind"])
+	if kind == "" || str(doc["apiVersion"]) == "" {
+		return nil
+	}
+	if kind == kindList {
+		var objects []*object
+		for _, item := range listOf(doc["items"]) {
+			if m := mapOf(item); m != nil {
+				objects = append(objects, toObjects(m)...)
+			}
+		}
+		return objects
+	}
+
+	meta := mapOf(doc["metadata"])
+	obj := &object{
+		kind:      kind,
+		name:      str(meta["name"]),
+		namespace: str(meta["namespace"]),
+		labels:    stringMap(meta["labels"]),
+		body:      doc,
+	}
+	if obj.namespace == "" {
+		obj.namespace = defaultNamespace
+	}
+	return []*object{obj}
+}
+
+// IsManifest reports whether data holds at least one Kubernetes workload,
+// and whether it was rendered by Helm.
+func IsManifest(data []byte) (bool, bool) {
+	objects, helm, err := decodeManifests(data)
+	if err != nil {
+		return false, false
+	}
+	for _, obj := range objects {
+		if workloadKinds[obj.kind] {
+			return true, helm || isHelmObject(obj)
+		}
+	}
+	return false, false
+}
+
+// LoadManifests loads the workloads of the Kubernetes manifests at path, a
+// file or a directory. Manifests rendered by helm template are loaded as
+// a Helm project. Unrendered chart templates are not supported.
+func LoadManifests(path string) (*Project, error) {
+	files, err := ManifestFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*object
+	var loaded []string
+	helm := false
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		objs, rendered, err := decodeManifests(data)
+		if err != nil {
+			if len(files) == 1 {
+				return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+			continue
+		}
+		if len(objs) == 0 {
+			continue
+		}
+		objects = append(objects, objs...)
+		loaded = append(loaded, file)
+		helm = helm || rendered
+	}
+
+	project := &Project{Format: parser.FormatKubernetes, Files: loaded}
+	for _, obj := range objects {
+		if isHelmObject(obj) {
+			helm = true
+			if project.Chart == nil {
+				project.Chart = chartOf(obj)
+			}
+		}
+	}
+	if helm {
+		project.Format = parser.FormatHelm
+	}
+	if project.Chart != nil && project.Chart.Release != "" {
+		project.Name = project.Chart.Release
+	} else if abs, err := filepath.Abs(path); err == nil {
+		project.Name = strings.TrimSuffix(filepath.Base(abs), filepath.Ext(abs))
+	}
+
+	if err := buildWorkloads(project, objects); err != nil {
+		return nil, err
+	}
+	if len(project.Workloads) == 0 {
+		if _, err := os.Stat(filepath.Join(path, chartFile)); err == nil {
+			return nil, fmt.Errorf("%s is an unrendered Helm chart, render it with helm template first", path)
+		}
+		return nil, fmt.Errorf("no Kubernetes workloads found in %s", path)
+	}
+	return project, nil
+}
+
+// buildWorkloads converts the workload objects and wires them to the
+// services, ingresses, config maps and secrets around them.
+func buildWorkloads(project *Project, objects []*object) error {
+	configMaps := make(map[string]map[string]string)
+	secretKeys := make(map[string][]string)
+	var services, ingresses []*object
+	for _, obj := range objects {
+		switch obj.kind {
+		case kindConfigMap:
+			configMaps[obj.key()] = stringMap(obj.body["data"])
+		case kindSecret:
+			for k := range mapOf(obj.body["data"]) {
+				secretKeys[obj.key()] = append(secretKeys[obj.key()], k)
+			}
+			for k := range mapOf(obj.body["stringData"]) {
+				secretKeys[obj.key()] = append(secretKeys[obj.key()], k)
+			}
+		case kindService:
+			services = append(services, obj)
+		case kindIngress:
+			ingresses = append(ingresses, obj)
+		}
+	}
+
+	podLabels := make(map[string]map[string]string)
+	extra := make(map[string][]string)
+	for _, obj := range objects {
+		if !workloadKinds[obj.kind] {
+			continue
+		}
+		w, labels, texts, err := kubernetesWorkload(obj, configMaps, secretKeys)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", obj.kind, obj.key(), err)
+		}
+		if w == nil {
+			continue
+		}
+		project.Workloads = append(project.Workloads, w)
+		podLabels[w.ID] = labels
+		extra[w.ID] = texts
+	}
+
+	// Services select workloads by pod labels; their names are the host
+	// names other workloads use.
+	hosts := make(map[string][]string)
+	selected := make(map[string][]*Workload)
+	for _, svc := range services {
+		selector := stringMap(svc.spec()["selector"])
+		if len(selector) == 0 {
+			continue
+		}
+		for _, w := range project.Workloads {
+			if w.Namespace != svc.namespace || !matchLabels(selector, podLabels[w.ID]) {
+				continue
+			}
+			selected[svc.key()] = append(selected[svc.key()], w)
+			hosts[svc.name] = append(hosts[svc.name], w.ID)
+			exposeService(w, svc)
+		}
+	}
+
+	for _, ing := range ingresses {
+		for _, route := range ingressRoutes(ing) {
+			for _, w := range selected[ing.namespace+"/"+route.service] {
+				w.Public = true
+				if route.host != "" && !contains(w.Hosts, route.host) {
+					w.Hosts = append(w.Hosts, route.host)
+				}
+			}
+		}
+	}
+
+	linkByHost(project.Workloads, hosts, extra)
+	sortWorkloads(project.Workloads)
+	return nil
+}
+
+// kubernetesWorkload converts a workload object. It also returns the pod
+// labels and the init container commands to scan for host names.
+func kubernetesWorkload(obj *object, configMaps map[string]map[string]string, secretKeys map[string][]string) (*Workload, map[string]string, []string, error) {
+	spec := obj.spec()
+	template := mapOf(spec["template"])
+	switch obj.kind {
+	case kindPod:
+		template = map[string]interface{}{"metadata": obj.body["metadata"], "spec": spec}
+	case kindCronJob:
+		template = mapOf(mapOf(mapOf(spec["jobTemplate"])["spec"])["template"])
+	}
+	podSpec := mapOf(template["spec"])
+	containers := listOf(podSpec["containers"])
+	if len(containers) == 0 {
+		return nil, nil, nil, nil
+	}
+	main := mapOf(containers[0])
+
+	w := &Workload{
+		ID:        obj.namespace + "/" + strings.ToLower(obj.kind) + "/" + obj.name,
+		Name:      obj.name,
+		Kind:      obj.kind,
+		Namespace: obj.namespace,
+		Image:     str(main["image"]),
+		Command:   append(stringList(main["command"]), stringList(main["args"])...),
+		Env:       make(map[string]string),
+		Secrets:   make(map[string]string),
+		Labels:    obj.labels,
+		Replicas:  1,
+	}
+	if replicas, ok := spec["replicas"]; ok && obj.kind != kindDaemonSet {
+		w.Replicas = num(replicas)
+	}
+	if obj.kind == kindCronJob {
+		w.Schedule = str(spec["schedule"])
+	}
+	for _, c := range containers[1:] {
+		w.Sidecars = append(w.Sidecars, str(mapOf(c)["image"]))
+	}
+
+	// envFrom first, explicit env entries override.
+	for _, item := range listOf(main["envFrom"]) {
+		from := mapOf(item)
+		prefix := str(from["prefix"])
+		if ref := mapOf(from["configMapRef"]); ref != nil {
+			for k, v := range configMaps[obj.namespace+"/"+str(ref["name"])] {
+				w.Env[prefix+k] = v
+			}
+		}
+		if ref := mapOf(from["secretRef"]); ref != nil {
+			name := str(ref["name"])
+			for _, k := range secretKeys[obj.namespace+"/"+name] {
+				w.Secrets[prefix+k] = name + "/" + k
+			}
+		}
+	}
+	for _, item := range listOf(main["env"]) {
+		env := mapOf(item)
+		name := str(env["name"])
+		valueFrom := mapOf(env["valueFrom"])
+		switch {
+		case valueFrom == nil:
+			w.Env[name] = str(env["value"])
+		case valueFrom["configMapKeyRef"] != nil:
+			ref := mapOf(valueFrom["configMapKeyRef"])
+			if v, ok := configMaps[obj.namespace+"/"+str(ref["name"])][str(ref["key"])]; ok {
+				w.Env[name] = v
+			}
+		case valueFrom["secretKeyRef"] != nil:
+			ref := mapOf(valueFrom["secretKeyRef"])
+			w.Secrets[name] = str(ref["name"]) + "/" + str(ref["key"])
+		}
+	}
+
+	portNames := make(map[string]int)
+	for _, item := range listOf(main["ports"]) {
+		p := mapOf(item)
+		port := Port{
+			Container: num(p["containerPort"]),
+			Published: num(p["hostPort"]),
+			Protocol:  strings.ToLower(str(p["protocol"])),
+		}
+		if port.Published > 0 {
+			w.Public = true
+		}
+		w.Ports = append(w.Ports, port)
+		if name := str(p["name"]); name != "" {
+			portNames[name] = port.Container
+		}
+	}
+
+	volumes := make(map[string]string)
+	for _, item := range listOf(podSpec["volumes"]) {
+		v := mapOf(item)
+		name := str(v["name"])
+		switch {
+		case v["persistentVolumeClaim"] != nil:
+			volumes[name] = str(mapOf(v["persistentVolumeClaim"])["claimName"])
+		case v["configMap"] != nil:
+			volumes[name] = "configmap/" + str(mapOf(v["configMap"])["name"])
+		case v["secret"] != nil:
+			volumes[name] = "secret/" + str(mapOf(v["secret"])["secretName"])
+		case v["hostPath"] != nil:
+			volumes[name] = str(mapOf(v["hostPath"])["path"])
+		default:
+			volumes[name] = name
+		}
+	}
+	for _, item := range listOf(spec["volumeClaimTemplates"]) {
+		name := str(mapOf(mapOf(item)["metadata"])["name"])
+		volumes[name] = name
+	}
+	for _, item := range listOf(main["volumeMounts"]) {
+		m := mapOf(item)
+		source, ok := volumes[str(m["name"])]
+		if !ok {
+			source = str(m["name"])
+		}
+		w.Mounts = append(w.Mounts, Mount{
+			Source:   source,
+			Target:   str(m["mountPath"]),
+			ReadOnly: str(m["readOnly"]) == "true",
+		})
+	}
+
+	resources := mapOf(main["resources"])
+	for _, key := range []string{"requests", "limits"} {
+		values := stringMap(resources[key])
+		if cpu, err := parseCPU(values["cpu"]); err != nil {
+			return nil, nil, nil, err
+		} else if cpu > 0 {
+			w.CPU = cpu
+		}
+		if memory, err := parseMemoryMB(values["memory"], true); err != nil {
+			return nil, nil, nil, err
+		} else if memory > 0 {
+			w.MemoryMB = memory
+		}
+	}
+
+	for _, key := range []string{"livenessProbe", "readinessProbe"} {
+		if probe := mapOf(main[key]); probe != nil {
+			w.HealthCheck = probeHealthCheck(probe, portNames)
+			break
+		}
+	}
+
+	var texts []string
+	for _, item := range listOf(podSpec["initContainers"]) {
+		c := mapOf(item)
+		texts = append(texts, stringList(c["command"])...)
+		texts = append(texts, stringList(c["args"])...)
+	}
+
+	labels := stringMap(mapOf(template["metadata"])["labels"])
+	return w, labels, texts, nil
+}
+
+// probeHealthCheck converts a probe to a Docker health check.
+func probeHealthCheck(probe map[string]interface{}, portNames map[string]int) *HealthCheck {
+	check := &HealthCheck{
+		Interval: time.Duration(num(probe["periodSeconds"])) * time.Second,
+		Timeout:  time.Duration(num(probe["timeoutSeconds"])) * time.Second,
+		Retries:  num(probe["failureThreshold"]),
+	}
+
+	port := func(v interface{}) int {
+		if n := num(v); n > 0 {
+			return n
+		}
+		return portNames[str(v)]
+	}
+
+	switch {
+	case probe["exec"] != nil:
+		check.Test = append([]string{"CMD"}, stringList(mapOf(probe["exec"])["command"])...)
+	case probe["httpGet"] != nil:
+		get := mapOf(probe["httpGet"])
+		path := str(get["path"])
+		if path == "" {
+			path = "/"
+		}
+		check.Test = []string{"CMD-SHELL", fmt.Sprintf("curl -fsS http://localhost:%d%s || exit 1", port(get["port"]), path)}
+	case probe["tcpSocket"] != nil:
+		check.Test = []string{"CMD-SHELL", fmt.Sprintf("nc -z localhost %d || exit 1", port(mapOf(probe["tcpSocket"])["port"]))}
+	default:
+		return nil
+	}
+	return check
+}
+
+// exposeService publishes the ports of a LoadBalancer or NodePort service
+// on the workload it selects.
+func exposeService(w *Workload, svc *object) {
+	spec := svc.spec()
+	svcType := str(spec["type"])
+	if svcType != serviceLoadBalancer && svcType != serviceNodePort {
+		return
+	}
+	w.Public = true
+
+	for _, item := range listOf(spec["ports"]) {
+		p := mapOf(item)
+		published := num(p["port"])
+		if svcType == serviceNodePort && num(p["nodePort"]) > 0 {
+			published = num(p["nodePort"])
+		}
+		target := num(p["targetPort"])
+		if target == 0 {
+			target = num(p["port"])
+		}
+
+		found := false
+		for i := range w.Ports {
+			if w.Ports[i].Container == target {
+				w.Ports[i].Published = published
+				found = true
+			}
+		}
+		if !found {
+			w.Ports = append(w.Ports, Port{Container: target, Published: published})
+		}
+	}
+}
+
+// ingressRoute is a host routed to a service by an ingress.
+type ingressRoute struct {
+	host    string
+	service string
+}
+
+// ingressRoutes returns the routes of an ingress, accepting both the v1
+// and the older extensions/v1beta1 backend layout.
+func ingressRoutes(ing *object) []ingressRoute {
+	backend := func(b map[string]interface{}) string {
+		if svc := mapOf(b["service"]); svc != nil {
+			return str(svc["name"])
+		}
+		return str(b["serviceName"])
+	}
+
+	spec := ing.spec()
+	var routes []ingressRoute
+	for _, key := range []string{"defaultBackend", "backend"} {
+		if b := mapOf(spec[key]); b != nil {
+			routes = append(routes, ingressRoute{service: backend(b)})
+		}
+	}
+	for _, item := range listOf(spec["rules"]) {
+		rule := mapOf(item)
+		for _, p := range listOf(mapOf(rule["http"])["paths"]) {
+			routes = append(routes, ingressRoute{
+				host:    str(rule["host"]),
+				service: backend(mapOf(mapOf(p)["backend"])),
+			})
+		}
+	}
+	return routes
+}
+
+// isHelmObject reports whether an object carries Helm's labels.
+func isHelmObject(obj *object) bool {
+	return obj.labels[helmChartLabel] != "" || strings.EqualFold(obj.labels[managedByLabel], "helm")
+}
+
+// chartOf reads the chart and release of a Helm-managed object. The chart
+// label has the form <name>-<version>.
+func chartOf(obj *object) *Chart {
+	chart := &Chart{Release: obj.labels[helmInstanceLabel]}
+	if chart.Release == "" {
+		chart.Release = obj.labels[helmReleaseLabel]
+	}
+	label := obj.labels[helmChartLabel]
+	chart.Name = label
+	for i := len(label) - 1; i > 0; i-- {
+		if label[i-1] == '-' && label[i] >= '0' && label[i] <= '9' {
+			chart.Name, chart.Version = label[:i-1], label[i:]
+			break
+		}
+	}
+	return chart
+}
+
+// matchLabels reports whether labels contain every selector entry.
+func matchLabels(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanManifests reports whether the files at path hold Kubernetes
+// workloads, and whether any of them were rendered by Helm.
+func ScanManifests(path string) (bool, bool) {
+	files, err := ManifestFiles(path)
+	if err != nil {
+		return false, false
+	}
+	found := false
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		ok, helm := IsManifest(data)
+		if helm {
+			return true, true
+		}
+		found = found || ok
+	}
+	return found, false
+}
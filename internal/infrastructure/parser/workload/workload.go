@@ -0,0 +1,368 @@
+// Package workload loads container workloads from Docker Compose projects,
+// Kubernetes manifests and rendered Helm charts.
+//
+// All three sources are reduced to the same Workload model: one container
+// image with its command, environment, ports, mounts, resources, health
+// check and the workloads it depends on. Provider parsers map workloads to
+// the resource types their mappers understand.
+package workload
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+)
+
+// Workload kinds besides the Kubernetes workload kinds.
+const (
+	KindComposeService = "ComposeService"
+)
+
+// Project is a set of workloads loaded from one source.
+type Project struct {
+	// Name is the compose project name or, for manifests, the Helm release
+	// or directory name.
+	Name string
+
+	// Format is the source format.
+	Format parser.Format
+
+	// Files are the files the workloads were loaded from.
+	Files []string
+
+	// Workloads are sorted by ID.
+	Workloads []*Workload
+
+	// Chart describes the Helm chart manifests were rendered from, if any.
+	Chart *Chart
+}
+
+// Chart identifies a rendered Helm chart.
+type Chart struct {
+	Name    string
+	Version string
+	Release string
+}
+
+// Workload is a containerized service.
+type Workload struct {
+	// ID is unique within the project: <project>/<service> for compose
+	// services and <namespace>/<kind>/<name> for Kubernetes workloads.
+	ID string
+
+	// Name is the service or object name.
+	Name string
+
+	// Kind is KindComposeService or the Kubernetes kind (Deployment, ...).
+	Kind string
+
+	// Namespace is the Kubernetes namespace.
+	Namespace string
+
+	// Image is the container image. Compose services that are only built
+	// locally have an empty image and a Build context.
+	Image string
+	Build string
+
+	// Command is the container command (entrypoint and arguments).
+	Command []string
+
+	// Env holds plain environment variables.
+	Env map[string]string
+
+	// Secrets maps environment variables to the Kubernetes secret key they
+	// are read from, as <secret>/<key>.
+	Secrets map[string]string
+
+	Ports  []Port
+	Mounts []Mount
+
+	// Replicas is the desired number of instances.
+	Replicas int
+
+	// CPU is the CPU limit in cores and MemoryMB the memory limit.
+	CPU      float64
+	MemoryMB int
+
+	HealthCheck *HealthCheck
+
+	// DependsOn holds the IDs of the workloads this one talks to.
+	DependsOn []string
+
+	Labels map[string]string
+
+	// Schedule is the cron schedule of CronJobs.
+	Schedule string
+
+	// Public is set when the workload is reachable from outside: a
+	// published port, a LoadBalancer or NodePort service, or an ingress.
+	Public bool
+
+	// Hosts are the ingress host names routed to the workload.
+	Hosts []string
+
+	// Sidecars are the images of additional containers.
+	Sidecars []string
+}
+
+// Port is a container port, optionally published on the host.
+type Port struct {
+	Container int
+	Published int
+	Protocol  string
+}
+
+// Mount is a volume mounted into the container.
+type Mount struct {
+	// Source is the volume, claim or host path.
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// HealthCheck is a container health check in Docker form.
+type HealthCheck struct {
+	Test     []string
+	Interval time.Duration
+	Timeout  time.Duration
+	Retries  int
+}
+
+// ImageName returns the image without registry, tag and digest, e.g.
+// "postgres" for docker.io/library/postgres:16-alpine.
+func (w *Workload) ImageName() string {
+	name, _ := splitImage(w.Image)
+	return name
+}
+
+// ImageTag returns the image tag, or "" when the image is untagged.
+func (w *Workload) ImageTag() string {
+	_, tag := splitImage(w.Image)
+	return tag
+}
+
+// splitImage splits an image reference into its repository path without
+// registry host and its tag.
+func splitImage(image string) (string, string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	tag := ""
+	if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i:], "/") {
+		image, tag = image[:i], image[i+1:]
+	}
+	parts := strings.Split(image, "/")
+	if len(parts) > 1 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		parts = parts[1:]
+	}
+	if len(parts) > 1 && parts[0] == "library" {
+		parts = parts[1:]
+	}
+	return strings.Join(parts, "/"), tag
+}
+
+// sortWorkloads sorts workloads by ID and their dependency lists.
+func sortWorkloads(workloads []*Workload) {
+	sort.Slice(workloads, func(i, j int) bool { return workloads[i].ID < workloads[j].ID })
+	for _, w := range workloads {
+		sort.Strings(w.DependsOn)
+	}
+}
+
+// parseMemoryMB parses a memory quantity into megabytes. Docker notation
+// (512m, 1g, 1GB) is binary and case-insensitive; with decimalSI set, the
+// Kubernetes decimal suffixes K, M, G and T are honored while Ki, Mi, Gi
+// and Ti stay binary.
+func parseMemoryMB(s string, decimalSI bool) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	multiplier := 1.0
+	value := s
+	switch {
+	case strings.HasSuffix(s, "i") && len(s) > 2:
+		value = s[:len(s)-2]
+		multiplier = binaryUnit(s[len(s)-2])
+	default:
+		value = strings.TrimSuffix(strings.TrimSuffix(s, "b"), "B")
+		if value != "" {
+			last := value[len(value)-1]
+			if unit := binaryUnit(last); unit > 0 {
+				value = value[:len(value)-1]
+				multiplier = unit
+				if decimalSI && last >= 'A' && last <= 'Z' {
+					multiplier = decimalUnit(last)
+				}
+			}
+		}
+	}
+	if multiplier == 0 {
+		return 0, fmt.Errorf("invalid memory quantity %q", s)
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory quantity %q", s)
+	}
+	return int(math.Ceil(n * multiplier / (1 << 20))), nil
+}
+
+// binaryUnit returns the size of a k, m, g or t unit in bytes, or 0.
+func binaryUnit(c byte) float64 {
+	switch c {
+	case 'k', 'K':
+		return 1 << 10
+	case 'm', 'M':
+		return 1 << 20
+	case 'g', 'G':
+		return 1 << 30
+	case 't', 'T':
+		return 1 << 40
+	}
+	return 0
+}
+
+// decimalUnit returns the size of a K, M, G or T unit in bytes, or 0.
+func decimalUnit(c byte) float64 {
+	switch c {
+	case 'K':
+		return 1e3
+	case 'M':
+		return 1e6
+	case 'G':
+		return 1e9
+	case 'T':
+		return 1e12
+	}
+	return 0
+}
+
+// parseCPU parses a CPU quantity in cores ("0.5") or millicores ("500m").
+func parseCPU(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "m") {
+		n, err := strconv.ParseFloat(strings.TrimSuffix(s, "m"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cpu quantity %q", s)
+		}
+		return n / 1000, nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cpu quantity %q", s)
+	}
+	return n, nil
+}
+
+// hostTokens splits s into the host names it may mention, e.g. "db" and
+// "cache" in "postgres://db:5432/app?cache=cache.default.svc".
+func hostTokens(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.')
+	})
+	var tokens []string
+	for _, field := range fields {
+		tokens = append(tokens, field)
+		if i := strings.Index(field, "."); i > 0 {
+			tokens = append(tokens, field[:i])
+		}
+	}
+	return tokens
+}
+
+// Generic YAML helpers. Both loaders work on decoded maps because compose
+// and Kubernetes fields accept several shapes.
+
+// str returns v as a string. Numbers and booleans are formatted.
+func str(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// num returns v as an int.
+func num(v interface{}) int {
+	switch val := v.(type) {
+	case int:
+		return val
+	case float64:
+		return int(val)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(val))
+		return n
+	}
+	return 0
+}
+
+// mapOf returns v as a map with string keys.
+func mapOf(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	return nil
+}
+
+// listOf returns v as a list.
+func listOf(v interface{}) []interface{} {
+	if l, ok := v.([]interface{}); ok {
+		return l
+	}
+	return nil
+}
+
+// stringList returns v as a list of strings; a single string becomes a list
+// of its shell words.
+func stringList(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		return strings.Fields(val)
+	case []interface{}:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			out = append(out, str(item))
+		}
+		return out
+	}
+	return nil
+}
+
+// stringMap returns v as a map of strings. Lists of KEY=VALUE entries are
+// accepted as well.
+func stringMap(v interface{}) map[string]string {
+	out := make(map[string]string)
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			out[k] = str(item)
+		}
+	case []interface{}:
+		for _, item := range val {
+			k, value, _ := strings.Cut(str(item), "=")
+			if k != "" {
+				out[k] = value
+			}
+		}
+	}
+	return out
+}
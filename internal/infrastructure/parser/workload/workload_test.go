@@ -0,0 +1,356 @@
+package workload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/homeport/homeport/internal/domain/parser"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func byID(project *Project) map[string]*Workload {
+	m := make(map[string]*Workload)
+	for _, w := range project.Workloads {
+		m[w.ID] = w
+	}
+	return m
+}
+
+func TestLoadCompose(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".env": "PG_VERSION=16\nWEB_PORT=8080\n",
+		"docker-compose.yml": `
+name: Shop
+services:
+  web:
+    image: ghcr.io/acme/shop-web:${TAG:-1.4.0}
+    ports:
+      - "${WEB_PORT}:80"
+      - target: 443
+        published: 8443
+    environment:
+      DATABASE_URL: postgres://app@db:5432/shop
+      PRICE: "$$5"
+    depends_on:
+      cache:
+        condition: service_started
+    healthcheck:
+      test: curl -f http://localhost/health
+      interval: 30s
+      timeout: 5s
+      retries: 3
+    deploy:
+      replicas: 2
+      resources:
+        limits:
+          cpus: "0.5"
+          memory: 512M
+  db:
+    image: postgres:${PG_VERSION}-alpine
+    environment:
+      - POSTGRES_DB=shop
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+  cache:
+    image: redis:7
+    expose:
+      - "6379"
+volumes:
+  pgdata: {}
+`,
+		"docker-compose.override.yml": `
+services:
+  web:
+    environment:
+      DEBUG: "true"
+`,
+	})
+
+	project, err := LoadCompose(dir)
+	if err != nil {
+		t.Fatalf("LoadCompose failed: %v", err)
+	}
+	if project.Name != "shop" || project.Format != parser.FormatDockerCompose || len(project.Files) != 2 {
+		t.Errorf("project = %q %s %v", project.Name, project.Format, project.Files)
+	}
+	if len(project.Workloads) != 3 {
+		t.Fatalf("expected 3 workloads, got %d", len(project.Workloads))
+	}
+
+	workloads := byID(project)
+	web := workloads["shop/web"]
+	if web == nil {
+		t.Fatal("missing shop/web")
+	}
+	if web.Image != "ghcr.io/acme/shop-web:1.4.0" || web.ImageName() != "acme/shop-web" || web.ImageTag() != "1.4.0" {
+		t.Errorf("image = %q (%q, %q)", web.Image, web.ImageName(), web.ImageTag())
+	}
+	if len(web.Ports) != 2 || web.Ports[0] != (Port{Container: 80, Published: 8080}) || web.Ports[1].Published != 8443 || !web.Public {
+		t.Errorf("ports = %+v, public = %v", web.Ports, web.Public)
+	}
+	if web.Env["PRICE"] != "$5" || web.Env["DEBUG"] != "true" {
+		t.Errorf("env = %v", web.Env)
+	}
+	if web.Replicas != 2 || web.CPU != 0.5 || web.MemoryMB != 512 {
+		t.Errorf("replicas=%d cpu=%v memory=%d", web.Replicas, web.CPU, web.MemoryMB)
+	}
+	if web.HealthCheck == nil || web.HealthCheck.Test[0] != "CMD-SHELL" || web.HealthCheck.Interval != 30*time.Second {
+		t.Errorf("healthcheck = %+v", web.HealthCheck)
+	}
+	if len(web.DependsOn) != 2 || web.DependsOn[0] != "shop/cache" || web.DependsOn[1] != "shop/db" {
+		t.Errorf("depends on = %v, want cache from depends_on and db from DATABASE_URL", web.DependsOn)
+	}
+
+	db := workloads["shop/db"]
+	if db.Image != "postgres:16-alpine" || db.ImageName() != "postgres" {
+		t.Errorf("db image = %q", db.Image)
+	}
+	if len(db.Mounts) != 1 || db.Mounts[0].Source != "pgdata" || db.Env["POSTGRES_DB"] != "shop" {
+		t.Errorf("db = %+v", db)
+	}
+	if cache := workloads["shop/cache"]; cache.Public || len(cache.Ports) != 1 || cache.Ports[0].Container != 6379 {
+		t.Errorf("cache ports = %+v", cache.Ports)
+	}
+}
+
+const testManifests = `---
+# Source: shop/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shop-config
+data:
+  LOG_LEVEL: info
+---
+# Source: shop/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: shop-web
+  labels:
+    helm.sh/chart: shop-1.2.3
+    app.kubernetes.io/instance: prod
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      initContainers:
+        - name: wait
+          image: busybox
+          command: ["sh", "-c", "until nc -z shop-db 5432; do sleep 1; done"]
+      containers:
+        - name: web
+          image: acme/shop-web:2.0.0
+          ports:
+            - name: http
+              containerPort: 8080
+          envFrom:
+            - configMapRef:
+                name: shop-config
+          env:
+            - name: DB_PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: shop-db
+                  key: password
+          resources:
+            limits:
+              cpu: 500m
+              memory: 256Mi
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: http
+            periodSeconds: 10
+        - name: proxy
+          image: envoyproxy/envoy:v1.29
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: shop-db
+spec:
+  template:
+    metadata:
+      labels:
+        app: db
+    spec:
+      containers:
+        - name: postgres
+          image: postgres:15
+          volumeMounts:
+            - name: data
+              mountPath: /var/lib/postgresql/data
+  volumeClaimTemplates:
+    - metadata:
+        name: data
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: shop-db
+spec:
+  selector:
+    app: db
+  ports:
+    - port: 5432
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: shop-web
+spec:
+  selector:
+    app: web
+  ports:
+    - port: 80
+      targetPort: 8080
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: shop
+spec:
+  rules:
+    - host: shop.example.com
+      http:
+        paths:
+          - path: /
+            backend:
+              service:
+                name: shop-web
+                port:
+                  number: 80
+`
+
+func TestLoadManifests(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"rendered.yaml":    testManifests,
+		"chart/Chart.yaml": "apiVersion: v2\nname: shop\nversion: 1.2.3\n",
+		"chart/templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+`,
+	})
+
+	if ok, helm := IsManifest([]byte(testManifests)); !ok || !helm {
+		t.Errorf("IsManifest = %v, %v", ok, helm)
+	}
+
+	project, err := LoadManifests(dir)
+	if err != nil {
+		t.Fatalf("LoadManifests failed: %v", err)
+	}
+	if project.Format != parser.FormatHelm || project.Name != "prod" {
+		t.Errorf("project = %q %s", project.Name, project.Format)
+	}
+	if project.Chart == nil || project.Chart.Name != "shop" || project.Chart.Version != "1.2.3" {
+		t.Errorf("chart = %+v", project.Chart)
+	}
+	if len(project.Workloads) != 2 {
+		t.Fatalf("expected 2 workloads, got %d", len(project.Workloads))
+	}
+
+	workloads := byID(project)
+	web := workloads["default/deployment/shop-web"]
+	if web == nil {
+		t.Fatal("missing default/deployment/shop-web")
+	}
+	if web.Replicas != 3 || web.CPU != 0.5 || web.MemoryMB != 256 {
+		t.Errorf("replicas=%d cpu=%v memory=%d", web.Replicas, web.CPU, web.MemoryMB)
+	}
+	if web.Env["LOG_LEVEL"] != "info" || web.Secrets["DB_PASSWORD"] != "shop-db/password" {
+		t.Errorf("env = %v, secrets = %v", web.Env, web.Secrets)
+	}
+	if !web.Public || len(web.Hosts) != 1 || web.Hosts[0] != "shop.example.com" {
+		t.Errorf("public = %v, hosts = %v", web.Public, web.Hosts)
+	}
+	if web.HealthCheck == nil || web.HealthCheck.Test[1] != "curl -fsS http://localhost:8080/healthz || exit 1" {
+		t.Errorf("healthcheck = %+v", web.HealthCheck)
+	}
+	if len(web.DependsOn) != 1 || web.DependsOn[0] != "default/statefulset/shop-db" {
+		t.Errorf("depends on = %v", web.DependsOn)
+	}
+	if len(web.Sidecars) != 1 {
+		t.Errorf("sidecars = %v", web.Sidecars)
+	}
+
+	db := workloads["default/statefulset/shop-db"]
+	if db == nil || len(db.Mounts) != 1 || db.Mounts[0].Source != "data" {
+		t.Errorf("db = %+v", db)
+	}
+
+	if _, err := LoadManifests(filepath.Join(dir, "chart")); err == nil {
+		t.Error("expected an error for an unrendered chart")
+	}
+}
+
+func TestParseQuantities(t *testing.T) {
+	memory := []struct {
+		in        string
+		decimalSI bool
+		want      int
+	}{
+		{"512m", false, 512},
+		{"1g", false, 1024},
+		{"1GB", false, 1024},
+		{"256Mi", true, 256},
+		{"1Gi", true, 1024},
+		{"1G", true, 954},
+		{"134217728", true, 128},
+	}
+	for _, tt := range memory {
+		got, err := parseMemoryMB(tt.in, tt.decimalSI)
+		if err != nil || got != tt.want {
+			t.Errorf("parseMemoryMB(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	if cpu, err := parseCPU("250m"); err != nil || cpu != 0.25 {
+		t.Errorf("parseCPU(250m) = %v, %v", cpu, err)
+	}
+	if _, err := parseCPU("lots"); err == nil {
+		t.Error("expected an error for an invalid cpu quantity")
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"A": "a", "EMPTY": ""}
+	tests := map[string]string{
+		"$A-${A}":             "a-a",
+		"${EMPTY:-x}":         "x",
+		"${EMPTY-x}":          "",
+		"${MISSING-${A}}":     "a",
+		"${A:+set}${EMPTY:+}": "set",
+		"$$A":                 "$A",
+		"cost: 5$":            "cost: 5$",
+	}
+	for in, want := range tests {
+		if got := interpolate(in, env); got != want {
+			t.Errorf("interpolate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
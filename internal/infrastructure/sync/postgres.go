@@ -4,8 +4,12 @@ package sync
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
@@ -595,3 +599,446 @@ func MonitorPgDumpProgress(stderr io.Reader, reporter *sync.ProgressReporter) {
 		}
 	}
 }
+
+const (
+	// replicationPollInterval is how often replication progress is measured.
+	replicationPollInterval = time.Second
+
+	// replicationCaughtUpLag is the lag under which Sync considers a
+	// replicating target caught up. Changes keep streaming after it returns;
+	// cutover waits for the lag to reach zero once writes have stopped.
+	replicationCaughtUpLag = 1 << 20
+)
+
+// PostgresReplicationSync keeps a target in sync using PostgreSQL logical
+// replication. The source publishes all its tables and the target subscribes
+// to them, so after the initial copy changes stream to the target until
+// cutover instead of the whole database being copied again.
+type PostgresReplicationSync struct {
+	*PostgresSync
+}
+
+// NewPostgresReplicationSync creates a logical replication PostgreSQL sync.
+func NewPostgresReplicationSync() *PostgresReplicationSync {
+	base := NewPostgresSync()
+	base.BaseStrategy = sync.NewBaseStrategy("postgres-replication", sync.SyncTypeDatabase, true, true)
+	return &PostgresReplicationSync{
+		PostgresSync: base,
+	}
+}
+
+// Sync copies the schema, publishes the source tables and subscribes the
+// target to them, then returns once the initial copy is done and the target
+// has caught up. On a target that already subscribes it only waits for it to
+// catch up, so running it again resumes an interrupted sync.
+func (p *PostgresReplicationSync) Sync(ctx context.Context, source, target *sync.Endpoint, progress chan<- sync.Progress) error {
+	reporter := sync.NewProgressReporter("postgres-replication", progress, nil)
+	reporter.SetPhase("initializing")
+
+	size, err := p.EstimateSize(ctx, source)
+	if err != nil {
+		reporter.Error(fmt.Sprintf("failed to estimate size: %v", err))
+		return err
+	}
+	reporter.SetTotals(size, 0)
+
+	if err := p.checkWALLevel(ctx, source); err != nil {
+		reporter.Error(err.Error())
+		return err
+	}
+
+	reporter.SetPhase("preparing target")
+	if err := p.createTargetDatabase(ctx, target); err != nil {
+		reporter.Error(fmt.Sprintf("failed to create target database: %v", err))
+		return err
+	}
+
+	name := replicationName(source, target)
+	subscribed, err := p.subscriptionExists(ctx, target, name)
+	if err != nil {
+		reporter.Error(err.Error())
+		return err
+	}
+
+	if !subscribed {
+		reporter.SetPhase("copying schema")
+		if err := p.copySchema(ctx, source, target); err != nil {
+			reporter.Error(err.Error())
+			return err
+		}
+
+		reporter.SetPhase("publishing")
+		if err := p.createPublication(ctx, source, name); err != nil {
+			reporter.Error(err.Error())
+			return err
+		}
+
+		reporter.SetPhase("subscribing")
+		if err := p.createSubscription(ctx, source, target, name); err != nil {
+			reporter.Error(err.Error())
+			return err
+		}
+	}
+
+	return p.waitForLag(ctx, source, target, replicationCaughtUpLag, reporter)
+}
+
+// WaitForLag blocks until every table has been copied and the target is at
+// most maxLag bytes behind the source, reporting the lag as it goes. Cutover
+// calls it with 0 once writes to the source have stopped.
+func (p *PostgresReplicationSync) WaitForLag(ctx context.Context, source, target *sync.Endpoint, maxLag int64, progress chan<- sync.Progress) error {
+	reporter := sync.NewProgressReporter("postgres-replication", progress, nil)
+	return p.waitForLag(ctx, source, target, maxLag, reporter)
+}
+
+// Promote detaches the target from the source at cutover. Sequences, which
+// logical replication does not carry, are set to their source values first,
+// then replication is stopped.
+func (p *PostgresReplicationSync) Promote(ctx context.Context, source, target *sync.Endpoint) error {
+	sourceDB, err := p.connect(ctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source: %w", err)
+	}
+	defer func() { _ = sourceDB.Close() }()
+
+	targetDB, err := p.connect(ctx, target)
+	if err != nil {
+		return fmt.Errorf("failed to connect to target: %w", err)
+	}
+	defer func() { _ = targetDB.Close() }()
+
+	if err := p.copySequences(ctx, sourceDB, targetDB); err != nil {
+		return err
+	}
+
+	return p.StopReplication(ctx, source, target)
+}
+
+// StopReplication drops the subscription, its replication slot and the
+// publication, leaving the target data as it is. Missing objects are skipped,
+// so it also cleans up after a sync that stopped half way. The source is
+// cleaned up even when the target cannot be reached, since an abandoned slot
+// makes the source retain WAL indefinitely.
+func (p *PostgresReplicationSync) StopReplication(ctx context.Context, source, target *sync.Endpoint) error {
+	name := replicationName(source, target)
+	var errs []error
+
+	// Dropping the subscription also drops its slot on the source.
+	if targetDB, err := p.connect(ctx, target); err != nil {
+		errs = append(errs, fmt.Errorf("failed to connect to target: %w", err))
+	} else {
+		if _, err := targetDB.ExecContext(ctx, "DROP SUBSCRIPTION IF EXISTS "+quoteIdentifier(name)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to drop subscription: %w", err))
+		}
+		_ = targetDB.Close()
+	}
+
+	sourceDB, err := p.connect(ctx, source)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to connect to source: %w", err))
+		return errors.Join(errs...)
+	}
+	defer func() { _ = sourceDB.Close() }()
+
+	// A slot whose subscription is gone is no longer in use and is dropped
+	// here instead.
+	query := "SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots WHERE slot_name = $1 AND NOT active"
+	if _, err := sourceDB.ExecContext(ctx, query, name); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drop replication slot: %w", err))
+	}
+	if _, err := sourceDB.ExecContext(ctx, "DROP PUBLICATION IF EXISTS "+quoteIdentifier(name)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drop publication: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// waitForLag polls the subscription until it is caught up to maxLag.
+func (p *PostgresReplicationSync) waitForLag(ctx context.Context, source, target *sync.Endpoint, maxLag int64, reporter *sync.ProgressReporter) error {
+	reporter.SetPhase("replicating")
+
+	sourceDB, err := p.connect(ctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source: %w", err)
+	}
+	defer func() { _ = sourceDB.Close() }()
+
+	targetDB, err := p.connect(ctx, target)
+	if err != nil {
+		return fmt.Errorf("failed to connect to target: %w", err)
+	}
+	defer func() { _ = targetDB.Close() }()
+
+	name := replicationName(source, target)
+	for {
+		state, err := p.replicationState(ctx, sourceDB, targetDB, name)
+		if err != nil {
+			reporter.Error(err.Error())
+			return err
+		}
+
+		current := reporter.GetProgress()
+		bytesTotal := current.BytesTotal
+		if state.tables != current.ItemsTotal {
+			reporter.SetTotals(bytesTotal, state.tables)
+		}
+
+		switch {
+		case state.tablesReady < state.tables:
+			reporter.Update(0, state.tablesReady, fmt.Sprintf("Copying tables (%d/%d)", state.tablesReady, state.tables))
+		case !state.lag.Valid:
+			reporter.Update(0, state.tablesReady, "Waiting for the subscription to report its position")
+		default:
+			reporter.SetReplicationLag(state.lag.Int64)
+			if state.lag.Int64 <= maxLag {
+				reporter.SetPhase("streaming")
+				reporter.Update(bytesTotal, state.tablesReady, "Target caught up with source, streaming changes")
+				return nil
+			}
+			reporter.Update(0, state.tablesReady, fmt.Sprintf("Applying changes, %s behind source", sync.FormatBytes(state.lag.Int64)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(replicationPollInterval):
+		}
+	}
+}
+
+// replicationState is a snapshot of a subscription's progress.
+type replicationState struct {
+	// tables and tablesReady count the subscribed tables and those whose
+	// initial copy is done.
+	tables, tablesReady int64
+	// lag is how many WAL bytes the subscription has yet to confirm. It is
+	// null until the subscription first reports its position.
+	lag sql.NullInt64
+}
+
+// replicationState reads the table states of the subscription from the target
+// and the lag of its replication slot from the source.
+func (p *PostgresReplicationSync) replicationState(ctx context.Context, sourceDB, targetDB *sql.DB, name string) (replicationState, error) {
+	var state replicationState
+
+	query := `
+		SELECT count(*), count(*) FILTER (WHERE r.srsubstate = 'r')
+		FROM pg_subscription_rel r
+		JOIN pg_subscription s ON s.oid = r.srsubid
+		WHERE s.subname = $1
+	`
+	if err := targetDB.QueryRowContext(ctx, query, name).Scan(&state.tables, &state.tablesReady); err != nil {
+		return state, fmt.Errorf("failed to query subscription tables: %w", err)
+	}
+
+	query = `
+		SELECT pg_wal_lsn_diff(pg_current_wal_lsn(), confirmed_flush_lsn)::bigint
+		FROM pg_replication_slots
+		WHERE slot_name = $1
+	`
+	err := sourceDB.QueryRowContext(ctx, query, name).Scan(&state.lag)
+	if err == sql.ErrNoRows {
+		return state, fmt.Errorf("replication slot %s not found on source", name)
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to query replication lag: %w", err)
+	}
+
+	return state, nil
+}
+
+// checkWALLevel ensures the source can decode its WAL for logical replication.
+func (p *PostgresReplicationSync) checkWALLevel(ctx context.Context, source *sync.Endpoint) error {
+	db, err := p.connect(ctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var level string
+	if err := db.QueryRowContext(ctx, "SHOW wal_level").Scan(&level); err != nil {
+		return fmt.Errorf("failed to query wal_level: %w", err)
+	}
+	if level != "logical" {
+		return fmt.Errorf("source wal_level is %s, logical replication requires wal_level = logical", level)
+	}
+
+	return nil
+}
+
+// copySchema restores the schema of the source on the target. The data is
+// copied by the subscription itself.
+func (p *PostgresReplicationSync) copySchema(ctx context.Context, source, target *sync.Endpoint) error {
+	dumpCmd := p.buildSchemaDumpCommand(source)
+	restoreCmd := p.buildSchemaRestoreCommand(target)
+
+	dumpProc := exec.CommandContext(ctx, dumpCmd[0], dumpCmd[1:]...)
+	dumpProc.Env = p.buildEnv(source)
+	var dumpStderr strings.Builder
+	dumpProc.Stderr = &dumpStderr
+
+	schema, err := dumpProc.Output()
+	if err != nil {
+		return fmt.Errorf("pg_dump failed: %w - stderr: %s", err, dumpStderr.String())
+	}
+
+	restoreProc := exec.CommandContext(ctx, restoreCmd[0], restoreCmd[1:]...)
+	restoreProc.Env = p.buildEnv(target)
+	restoreProc.Stdin = bytes.NewReader(schema)
+
+	if output, err := restoreProc.CombinedOutput(); err != nil {
+		return fmt.Errorf("pg_restore failed: %w - stderr: %s", err, output)
+	}
+
+	return nil
+}
+
+// buildSchemaDumpCommand constructs a pg_dump command for the schema only.
+func (p *PostgresReplicationSync) buildSchemaDumpCommand(source *sync.Endpoint) []string {
+	args := p.buildDumpCommand(source)
+
+	// The database name comes last
+	args = args[:len(args)-1]
+	return append(args, "--schema-only", source.Database)
+}
+
+// buildSchemaRestoreCommand constructs a pg_restore command that leaves out
+// the publications and subscriptions of the source.
+func (p *PostgresReplicationSync) buildSchemaRestoreCommand(target *sync.Endpoint) []string {
+	args := p.buildRestoreCommand(target)
+	return append(args, "--no-publications", "--no-subscriptions")
+}
+
+// createPublication publishes all tables of the source if it isn't already.
+func (p *PostgresReplicationSync) createPublication(ctx context.Context, source *sync.Endpoint, name string) error {
+	db, err := p.connect(ctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM pg_publication WHERE pubname = $1)"
+	if err := db.QueryRowContext(ctx, query, name).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check publication existence: %w", err)
+	}
+
+	if !exists {
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("CREATE PUBLICATION %s FOR ALL TABLES", quoteIdentifier(name))); err != nil {
+			return fmt.Errorf("failed to create publication: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// subscriptionExists checks whether the target database has the subscription.
+func (p *PostgresReplicationSync) subscriptionExists(ctx context.Context, target *sync.Endpoint, name string) (bool, error) {
+	db, err := p.connect(ctx, target)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to target: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	// Subscriptions are shared across databases, names are unique per database
+	var exists bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM pg_subscription s
+			JOIN pg_database d ON d.oid = s.subdbid
+			WHERE s.subname = $1 AND d.datname = current_database()
+		)
+	`
+	if err := db.QueryRowContext(ctx, query, name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check subscription existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// createSubscription subscribes the target to the publication of the source.
+// The subscription creates a replication slot of the same name on the source
+// and copies the existing rows of every table before streaming changes.
+func (p *PostgresReplicationSync) createSubscription(ctx context.Context, source, target *sync.Endpoint, name string) error {
+	db, err := p.connect(ctx, target)
+	if err != nil {
+		return fmt.Errorf("failed to connect to target: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	query := fmt.Sprintf("CREATE SUBSCRIPTION %s CONNECTION %s PUBLICATION %s",
+		quoteIdentifier(name), quoteLiteral(source.ConnectionString()), quoteIdentifier(name))
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	return nil
+}
+
+// copySequences sets the sequences of the target to their source values.
+func (p *PostgresReplicationSync) copySequences(ctx context.Context, sourceDB, targetDB *sql.DB) error {
+	query := `
+		SELECT quote_ident(schemaname) || '.' || quote_ident(sequencename), last_value
+		FROM pg_sequences
+		WHERE last_value IS NOT NULL
+	`
+	rows, err := sourceDB.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query source sequences: %w", err)
+	}
+
+	values := make(map[string]int64)
+	for rows.Next() {
+		var sequence string
+		var value int64
+		if err := rows.Scan(&sequence, &value); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to read source sequences: %w", err)
+		}
+		values[sequence] = value
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read source sequences: %w", err)
+	}
+
+	for sequence, value := range values {
+		if _, err := targetDB.ExecContext(ctx, "SELECT setval($1::regclass, $2)", sequence, value); err != nil {
+			return fmt.Errorf("failed to set sequence %s: %w", sequence, err)
+		}
+	}
+
+	return nil
+}
+
+// replicationName names the publication, subscription and replication slot
+// of a sync after the target database, followed by a hash of both endpoints,
+// so targets with the same database name on different hosts, or fed from
+// different sources, never share a slot. Slot names may only hold lower case
+// letters, digits and underscores, and are at most 63 bytes long.
+func replicationName(source, target *sync.Endpoint) string {
+	var b strings.Builder
+	b.WriteString("homeport_")
+	for _, r := range strings.ToLower(target.Database) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d/%s>%s:%d/%s",
+		source.Host, source.Port, source.Database, target.Host, target.Port, target.Database)))
+	suffix := "_" + hex.EncodeToString(sum[:4])
+
+	name := b.String()
+	if len(name) > 63-len(suffix) {
+		name = name[:63-len(suffix)]
+	}
+	return name + suffix
+}
+
+// quoteLiteral safely quotes a PostgreSQL string literal.
+func quoteLiteral(value string) string {
+	return `'` + strings.ReplaceAll(value, `'`, `''`) + `'`
+}
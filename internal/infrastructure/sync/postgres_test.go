@@ -0,0 +1,99 @@
+package sync
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/homeport/homeport/internal/domain/sync"
+)
+
+func TestPostgresReplicationSync_Name(t *testing.T) {
+	p := NewPostgresReplicationSync()
+	if p.Name() != "postgres-replication" {
+		t.Errorf("expected name 'postgres-replication', got '%s'", p.Name())
+	}
+}
+
+func TestPostgresReplicationSync_SupportsIncremental(t *testing.T) {
+	p := NewPostgresReplicationSync()
+	if !p.SupportsIncremental() {
+		t.Error("expected SupportsIncremental to return true")
+	}
+	if NewPostgresSync().SupportsIncremental() {
+		t.Error("expected the dump strategy not to support incremental syncs")
+	}
+}
+
+func TestPostgresReplicationSync_IsReplicating(t *testing.T) {
+	var strategy sync.SyncStrategy = NewPostgresReplicationSync()
+	if _, ok := strategy.(sync.ReplicatingStrategy); !ok {
+		t.Error("expected postgres-replication to implement ReplicatingStrategy, so syncs promote or stop it")
+	}
+}
+
+func TestPostgresReplicationSync_BuildSchemaDumpCommand(t *testing.T) {
+	p := NewPostgresReplicationSync()
+	source := &sync.Endpoint{
+		Type:        "postgres",
+		Host:        "db.example.com",
+		Port:        5432,
+		Database:    "shop",
+		Credentials: &sync.Credentials{Username: "admin"},
+	}
+
+	got := p.buildSchemaDumpCommand(source)
+	want := []string{"pg_dump", "-h", "db.example.com", "-p", "5432", "-U", "admin", "-Fc", "-v", "--schema-only", "shop"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestReplicationName(t *testing.T) {
+	source := &sync.Endpoint{Host: "old-db.example.com", Port: 5432, Database: "shop"}
+	tests := []struct {
+		database string
+		prefix   string
+	}{
+		{"shop", "homeport_shop_"},
+		{"Shop-Prod.v2", "homeport_shop_prod_v2_"},
+		{"a_very_long_database_name_that_goes_past_the_identifier_limit", "homeport_a_very_long_database_name_that_goes_past_the__"},
+	}
+
+	for _, tt := range tests {
+		got := replicationName(source, &sync.Endpoint{Host: "db.example.com", Port: 5432, Database: tt.database})
+		if !strings.HasPrefix(got, tt.prefix) || len(got) != len(tt.prefix)+8 {
+			t.Errorf("replicationName(%q) = %q, want %q and an 8 character hash", tt.database, got, tt.prefix)
+		}
+		if len(got) > 63 {
+			t.Errorf("replicationName(%q) is %d bytes long", tt.database, len(got))
+		}
+	}
+}
+
+func TestReplicationNameIsUniquePerEndpointPair(t *testing.T) {
+	source := &sync.Endpoint{Host: "old-db.example.com", Port: 5432, Database: "shop"}
+	target := &sync.Endpoint{Host: "db-a.example.com", Port: 5432, Database: "shop"}
+	name := replicationName(source, target)
+
+	if got := replicationName(source, &sync.Endpoint{Host: "db-a.example.com", Port: 5432, Database: "shop"}); got != name {
+		t.Errorf("replicationName() = %q for the same endpoints, want %q", got, name)
+	}
+	others := []struct{ source, target *sync.Endpoint }{
+		{source, &sync.Endpoint{Host: "db-b.example.com", Port: 5432, Database: "shop"}},
+		{source, &sync.Endpoint{Host: "db-a.example.com", Port: 5433, Database: "shop"}},
+		{&sync.Endpoint{Host: "other-db.example.com", Port: 5432, Database: "shop"}, target},
+	}
+	for _, other := range others {
+		if got := replicationName(other.source, other.target); got == name {
+			t.Errorf("replicationName(%+v, %+v) = %q, shared with another sync", other.source, other.target, got)
+		}
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	got := quoteLiteral("host=db password=it's")
+	if got != `'host=db password=it''s'` {
+		t.Errorf("unexpected literal %s", got)
+	}
+}
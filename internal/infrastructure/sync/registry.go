@@ -10,6 +10,7 @@ func NewDefaultRegistry() *sync.StrategyRegistry {
 
 	// Register database sync strategies
 	registry.Register(NewPostgresSync())
+	registry.Register(NewPostgresReplicationSync())
 	registry.Register(NewMySQLSync())
 
 	// Register cache sync strategies
@@ -22,6 +23,7 @@ func NewDefaultRegistry() *sync.StrategyRegistry {
 func RegisterAllStrategies(registry *sync.StrategyRegistry) {
 	// Database strategies
 	registry.Register(NewPostgresSync())
+	registry.Register(NewPostgresReplicationSync())
 	registry.Register(NewMySQLSync())
 
 	// Cache strategies
@@ -32,6 +34,7 @@ func RegisterAllStrategies(registry *sync.StrategyRegistry) {
 func GetDatabaseStrategies() []sync.SyncStrategy {
 	return []sync.SyncStrategy{
 		NewPostgresSync(),
+		NewPostgresReplicationSync(),
 		NewMySQLSync(),
 	}
 }
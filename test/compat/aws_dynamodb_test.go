@@ -628,6 +628,375 @@ func TestDynamoDBCompatibilityAdapterRejectsMalformedListTablesStartName(t *test
 	}
 }
 
+func TestDynamoDBCompatibilityAdapterUpdatesItemsWithExpressions(t *testing.T) {
+	server := httptest.NewServer(compataws.NewDynamoDBAdapter())
+	defer server.Close()
+	client := dynamoClient(server.URL)
+	ctx := context.Background()
+	if _, err := client.CreateTable(ctx, dynamoCreateTableInput("carts")); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	key := map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "c1"}}
+
+	created, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String("carts"),
+		Key:                      key,
+		ConditionExpression:      aws.String("attribute_not_exists(id)"),
+		UpdateExpression:         aws.String("SET #owner = :owner, lines = :lines ADD quantity :one"),
+		ExpressionAttributeNames: map[string]string{"#owner": "owner"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner": &types.AttributeValueMemberS{Value: "ada"},
+			":lines": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "book"}}},
+			":one":   &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err != nil {
+		t.Fatalf("UpdateItem(create) error = %v", err)
+	}
+	if got := created.Attributes["quantity"].(*types.AttributeValueMemberN).Value; got != "1" {
+		t.Fatalf("UpdateItem(create) quantity = %q, want 1", got)
+	}
+
+	updated, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String("carts"),
+		Key:                      key,
+		ConditionExpression:      aws.String("quantity < :max AND begins_with(#owner, :prefix)"),
+		UpdateExpression:         aws.String("SET quantity = quantity + :two, lines = list_append(lines, :more) REMOVE coupon"),
+		ExpressionAttributeNames: map[string]string{"#owner": "owner"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":max":    &types.AttributeValueMemberN{Value: "10"},
+			":prefix": &types.AttributeValueMemberS{Value: "ad"},
+			":two":    &types.AttributeValueMemberN{Value: "2"},
+			":more":   &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "pen"}}},
+		},
+		ReturnValues: types.ReturnValueUpdatedOld,
+	})
+	if err != nil {
+		t.Fatalf("UpdateItem(update) error = %v", err)
+	}
+	if _, ok := updated.Attributes["owner"]; ok || updated.Attributes["quantity"].(*types.AttributeValueMemberN).Value != "1" {
+		t.Fatalf("UpdateItem(UPDATED_OLD) = %#v, want only the old quantity and lines", updated.Attributes)
+	}
+
+	got, err := client.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("carts"), Key: key, ProjectionExpression: aws.String("quantity, lines[1]")})
+	if err != nil {
+		t.Fatalf("GetItem() error = %v", err)
+	}
+	lines := got.Item["lines"].(*types.AttributeValueMemberL).Value
+	if len(got.Item) != 2 || got.Item["quantity"].(*types.AttributeValueMemberN).Value != "3" || len(lines) != 1 || lines[0].(*types.AttributeValueMemberS).Value != "pen" {
+		t.Fatalf("GetItem(projection) = %#v, want quantity 3 and lines [pen]", got.Item)
+	}
+
+	_, err = client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                           aws.String("carts"),
+		Key:                                 key,
+		ConditionExpression:                 aws.String("quantity > :max"),
+		UpdateExpression:                    aws.String("SET quantity = :max"),
+		ExpressionAttributeValues:           map[string]types.AttributeValue{":max": &types.AttributeValueMemberN{Value: "10"}},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var failed *types.ConditionalCheckFailedException
+	if !errors.As(err, &failed) || failed.Item["quantity"].(*types.AttributeValueMemberN).Value != "3" {
+		t.Fatalf("UpdateItem(failed condition) error = %v, want ConditionalCheckFailedException with the old item", err)
+	}
+
+	_, err = client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("carts"),
+		Key:                       key,
+		UpdateExpression:          aws.String("SET id = :id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":id": &types.AttributeValueMemberS{Value: "c2"}},
+	})
+	assertAPIErrorCode(t, err, "ValidationException")
+
+	deleted, err := client.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String("carts"), Key: key, ReturnValues: types.ReturnValueAllOld})
+	if err != nil || deleted.Attributes["owner"].(*types.AttributeValueMemberS).Value != "ada" {
+		t.Fatalf("DeleteItem() = %#v, %v; want the deleted item", deleted, err)
+	}
+	_, err = client.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String("carts"), Key: key, ConditionExpression: aws.String("attribute_exists(id)")})
+	assertAPIErrorCode(t, err, "ConditionalCheckFailedException")
+}
+
+func TestDynamoDBCompatibilityAdapterRejectsOverwritingPutWithCondition(t *testing.T) {
+	server := httptest.NewServer(compataws.NewDynamoDBAdapter())
+	defer server.Close()
+	client := dynamoClient(server.URL)
+	ctx := context.Background()
+	if _, err := client.CreateTable(ctx, dynamoCreateTableInput("users")); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	put := &dynamodb.PutItemInput{
+		TableName:           aws.String("users"),
+		Item:                map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "ada"}},
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}
+	if _, err := client.PutItem(ctx, put); err != nil {
+		t.Fatalf("PutItem(first) error = %v", err)
+	}
+	_, err := client.PutItem(ctx, put)
+	assertAPIErrorCode(t, err, "ConditionalCheckFailedException")
+}
+
+func TestDynamoDBCompatibilityAdapterQueriesCompositeKeysAndFilters(t *testing.T) {
+	server := httptest.NewServer(compataws.NewDynamoDBAdapter())
+	defer server.Close()
+	client := dynamoClient(server.URL)
+	ctx := context.Background()
+	input := dynamoCreateTableInput("orders", func(input *dynamodb.CreateTableInput) {
+		input.AttributeDefinitions = []types.AttributeDefinition{
+			{AttributeName: aws.String("customer"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("placed"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("total"), AttributeType: types.ScalarAttributeTypeN},
+		}
+		input.KeySchema = []types.KeySchemaElement{
+			{AttributeName: aws.String("customer"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("placed"), KeyType: types.KeyTypeRange},
+		}
+		input.GlobalSecondaryIndexes = []types.GlobalSecondaryIndex{{
+			IndexName: aws.String("status-total"),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("total"), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly},
+		}}
+	})
+	if _, err := client.CreateTable(ctx, input); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	orders := []struct{ customer, placed, status, total string }{
+		{"ada", "2026-01-05", "shipped", "30"},
+		{"ada", "2026-02-11", "open", "12"},
+		{"ada", "2026-02-20", "shipped", "5"},
+		{"ada", "2026-03-01", "open", "100"},
+		{"bob", "2026-02-15", "open", "7"},
+	}
+	for _, order := range orders {
+		if _, err := client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("orders"), Item: map[string]types.AttributeValue{
+			"customer": &types.AttributeValueMemberS{Value: order.customer},
+			"placed":   &types.AttributeValueMemberS{Value: order.placed},
+			"status":   &types.AttributeValueMemberS{Value: order.status},
+			"total":    &types.AttributeValueMemberN{Value: order.total},
+		}}); err != nil {
+			t.Fatalf("PutItem(%v) error = %v", order, err)
+		}
+	}
+
+	query := &dynamodb.QueryInput{
+		TableName:              aws.String("orders"),
+		KeyConditionExpression: aws.String("customer = :c AND begins_with(placed, :month)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":c":     &types.AttributeValueMemberS{Value: "ada"},
+			":month": &types.AttributeValueMemberS{Value: "2026-02"},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(1),
+	}
+	var placed []string
+	for {
+		page, err := client.Query(ctx, query)
+		if err != nil {
+			t.Fatalf("Query(begins_with) error = %v", err)
+		}
+		for _, item := range page.Items {
+			placed = append(placed, item["placed"].(*types.AttributeValueMemberS).Value)
+		}
+		if page.LastEvaluatedKey == nil {
+			break
+		}
+		query.ExclusiveStartKey = page.LastEvaluatedKey
+	}
+	if strings.Join(placed, ",") != "2026-02-20,2026-02-11" {
+		t.Fatalf("Query(begins_with, descending) = %v, want 2026-02-20,2026-02-11", placed)
+	}
+
+	filtered, err := client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                aws.String("orders"),
+		KeyConditionExpression:   aws.String("customer = :c AND placed BETWEEN :from AND :to"),
+		FilterExpression:         aws.String("#status = :open"),
+		ProjectionExpression:     aws.String("placed, total"),
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":c":    &types.AttributeValueMemberS{Value: "ada"},
+			":from": &types.AttributeValueMemberS{Value: "2026-01-01"},
+			":to":   &types.AttributeValueMemberS{Value: "2026-02-28"},
+			":open": &types.AttributeValueMemberS{Value: "open"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Query(filter) error = %v", err)
+	}
+	if filtered.Count != 1 || filtered.ScannedCount != 3 || len(filtered.Items[0]) != 2 || filtered.Items[0]["total"].(*types.AttributeValueMemberN).Value != "12" {
+		t.Fatalf("Query(filter) = %d/%d %#v, want one projected open order out of three", filtered.Count, filtered.ScannedCount, filtered.Items)
+	}
+
+	indexed, err := client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                aws.String("orders"),
+		IndexName:                aws.String("status-total"),
+		KeyConditionExpression:   aws.String("#status = :open AND total >= :min"),
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":open": &types.AttributeValueMemberS{Value: "open"},
+			":min":  &types.AttributeValueMemberN{Value: "10"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Query(index) error = %v", err)
+	}
+	var totals []string
+	for _, item := range indexed.Items {
+		if len(item) != 4 {
+			t.Fatalf("Query(KEYS_ONLY index) item = %#v, want table and index keys only", item)
+		}
+		totals = append(totals, item["total"].(*types.AttributeValueMemberN).Value)
+	}
+	if strings.Join(totals, ",") != "12,100" {
+		t.Fatalf("Query(index) totals = %v, want numeric order 12,100", totals)
+	}
+
+	scanned, err := client.Scan(ctx, &dynamodb.ScanInput{
+		TableName:                 aws.String("orders"),
+		FilterExpression:          aws.String("total > :n AND customer IN (:a, :b)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":n": &types.AttributeValueMemberN{Value: "10"}, ":a": &types.AttributeValueMemberS{Value: "ada"}, ":b": &types.AttributeValueMemberS{Value: "bob"}},
+		Select:                    types.SelectCount,
+	})
+	if err != nil || scanned.Count != 3 || scanned.ScannedCount != 5 || scanned.Items != nil {
+		t.Fatalf("Scan(COUNT) = %#v, %v; want count 3 of 5 without items", scanned, err)
+	}
+}
+
+func TestDynamoDBCompatibilityAdapterBatchesAndTransactsItems(t *testing.T) {
+	server := httptest.NewServer(compataws.NewDynamoDBAdapter())
+	defer server.Close()
+	client := dynamoClient(server.URL)
+	ctx := context.Background()
+	for _, name := range []string{"accounts", "ledger"} {
+		if _, err := client.CreateTable(ctx, dynamoCreateTableInput(name)); err != nil {
+			t.Fatalf("CreateTable(%s) error = %v", name, err)
+		}
+	}
+	account := func(id, balance string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}, "balance": &types.AttributeValueMemberN{Value: balance}}
+	}
+	key := func(id string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}}
+	}
+
+	if _, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"accounts": {
+			{PutRequest: &types.PutRequest{Item: account("a", "100")}},
+			{PutRequest: &types.PutRequest{Item: account("b", "0")}},
+			{PutRequest: &types.PutRequest{Item: account("c", "5")}},
+		},
+	}}); err != nil {
+		t.Fatalf("BatchWriteItem() error = %v", err)
+	}
+	if _, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"accounts": {{DeleteRequest: &types.DeleteRequest{Key: key("c")}}},
+	}}); err != nil {
+		t.Fatalf("BatchWriteItem(delete) error = %v", err)
+	}
+	_, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"accounts": {{PutRequest: &types.PutRequest{Item: account("a", "1")}}, {DeleteRequest: &types.DeleteRequest{Key: key("a")}}},
+	}})
+	assertAPIErrorCode(t, err, "ValidationException")
+
+	transfer := func(amount string) error {
+		_, err := client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+			{Update: &types.Update{
+				TableName:                 aws.String("accounts"),
+				Key:                       key("a"),
+				UpdateExpression:          aws.String("SET balance = balance - :amount"),
+				ConditionExpression:       aws.String("balance >= :amount"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":amount": &types.AttributeValueMemberN{Value: amount}},
+			}},
+			{Update: &types.Update{
+				TableName:                 aws.String("accounts"),
+				Key:                       key("b"),
+				UpdateExpression:          aws.String("ADD balance :amount"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":amount": &types.AttributeValueMemberN{Value: amount}},
+			}},
+			{Put: &types.Put{
+				TableName: aws.String("ledger"),
+				Item:      map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "transfer-" + amount}},
+			}},
+		}})
+		return err
+	}
+	if err := transfer("40"); err != nil {
+		t.Fatalf("TransactWriteItems(40) error = %v", err)
+	}
+	err = transfer("70")
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) || len(canceled.CancellationReasons) != 3 || aws.ToString(canceled.CancellationReasons[0].Code) != "ConditionalCheckFailed" || aws.ToString(canceled.CancellationReasons[1].Code) != "None" {
+		t.Fatalf("TransactWriteItems(70) error = %v, want TransactionCanceledException failing the first item", err)
+	}
+
+	got, err := client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+		"accounts": {Keys: []map[string]types.AttributeValue{key("a"), key("b"), key("c")}},
+		"ledger":   {Keys: []map[string]types.AttributeValue{key("transfer-40"), key("transfer-70")}},
+	}})
+	if err != nil {
+		t.Fatalf("BatchGetItem() error = %v", err)
+	}
+	balances := map[string]string{}
+	for _, item := range got.Responses["accounts"] {
+		balances[item["id"].(*types.AttributeValueMemberS).Value] = item["balance"].(*types.AttributeValueMemberN).Value
+	}
+	if fmt.Sprint(balances) != "map[a:60 b:40]" || len(got.Responses["ledger"]) != 1 {
+		t.Fatalf("BatchGetItem() = %v and %d ledger entries, want a:60 b:40 and one entry", balances, len(got.Responses["ledger"]))
+	}
+
+	_, err = client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{ConditionCheck: &types.ConditionCheck{TableName: aws.String("accounts"), Key: key("a"), ConditionExpression: aws.String("attribute_exists(id)")}},
+		{Delete: &types.Delete{TableName: aws.String("accounts"), Key: key("a")}},
+	}})
+	assertAPIErrorCode(t, err, "ValidationException")
+}
+
+func TestDynamoDBCompatibilityAdapterPersistsTablesInFileBackend(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := compataws.NewFileDynamoDBBackend(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(compataws.NewDynamoDBAdapter(compataws.WithDynamoDBBackend(backend)))
+	client := dynamoClient(server.URL)
+	ctx := context.Background()
+	if _, err := client.CreateTable(ctx, dynamoCreateTableInput("durable")); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if _, err := client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("durable"), Item: map[string]types.AttributeValue{
+		"id":   &types.AttributeValueMemberS{Value: "1"},
+		"tags": &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+	}}); err != nil {
+		t.Fatalf("PutItem() error = %v", err)
+	}
+	server.Close()
+
+	reopened, err := compataws.NewFileDynamoDBBackend(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server = httptest.NewServer(compataws.NewDynamoDBAdapter(compataws.WithDynamoDBBackend(reopened)))
+	defer server.Close()
+	client = dynamoClient(server.URL)
+	got, err := client.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("durable"), Key: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}}})
+	if err != nil {
+		t.Fatalf("GetItem(reopened) error = %v", err)
+	}
+	if tags, ok := got.Item["tags"].(*types.AttributeValueMemberSS); !ok || len(tags.Value) != 2 {
+		t.Fatalf("GetItem(reopened) = %#v, want persisted string set", got.Item)
+	}
+	if _, err := client.DeleteTable(ctx, &dynamodb.DeleteTableInput{TableName: aws.String("durable")}); err != nil {
+		t.Fatalf("DeleteTable() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "durable.json")); !os.IsNotExist(err) {
+		t.Fatalf("stat durable.json after DeleteTable error = %v, want not exist", err)
+	}
+}
+
 func dynamoClient(endpoint string) *dynamodb.Client {
 	return dynamodb.NewFromConfig(aws.Config{
 		Region:      "us-east-1",
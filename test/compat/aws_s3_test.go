@@ -1204,3 +1204,381 @@ func TestS3CompatibilityAdapterRejectsInvalidListObjectsV2MaxKeys(t *testing.T)
 		t.Fatalf("ListObjectsV2(invalid max keys) error = %v, want InvalidRequest", err)
 	}
 }
+
+func TestS3CompatibilityAdapterPersistsObjectsInFilesystemBackend(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := compataws.NewFileS3Backend(dir)
+	if err != nil {
+		t.Fatalf("NewFileS3Backend() error = %v", err)
+	}
+	server := httptest.NewServer(compataws.NewS3Adapter(compataws.WithS3Backend(backend)))
+	client := newS3TestClient(server.URL)
+
+	if _, err := client.CreateBucket(context.Background(), &s3.CreateBucketInput{Bucket: aws.String("bucket")}); err != nil {
+		t.Fatalf("CreateBucket() error = %v", err)
+	}
+	if _, err := client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:       aws.String("bucket"),
+		Key:          aws.String("docs/report.json"),
+		Body:         strings.NewReader(`{"ok":true}`),
+		ContentType:  aws.String("application/json"),
+		CacheControl: aws.String("max-age=60"),
+		Metadata:     map[string]string{"owner": "billing"},
+	}); err != nil {
+		t.Fatalf("PutObject() error = %v", err)
+	}
+	server.Close()
+
+	// A new adapter over the same directory sees the object and its metadata.
+	backend, err = compataws.NewFileS3Backend(dir)
+	if err != nil {
+		t.Fatalf("NewFileS3Backend() error = %v", err)
+	}
+	server = httptest.NewServer(compataws.NewS3Adapter(compataws.WithS3Backend(backend)))
+	defer server.Close()
+	client = newS3TestClient(server.URL)
+
+	got, err := client.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("docs/report.json")})
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	body, _ := io.ReadAll(got.Body)
+	if string(body) != `{"ok":true}` || aws.ToString(got.ContentType) != "application/json" || aws.ToString(got.CacheControl) != "max-age=60" || got.Metadata["owner"] != "billing" {
+		t.Fatalf("GetObject() = %q %q %q %v, want stored body and metadata", body, aws.ToString(got.ContentType), aws.ToString(got.CacheControl), got.Metadata)
+	}
+	head, err := client.HeadObject(context.Background(), &s3.HeadObjectInput{Bucket: aws.String("bucket"), Key: aws.String("docs/report.json")})
+	if err != nil {
+		t.Fatalf("HeadObject() error = %v", err)
+	}
+	if aws.ToInt64(head.ContentLength) != 11 || head.Metadata["owner"] != "billing" || head.LastModified == nil {
+		t.Fatalf("HeadObject() = %#v, want size, metadata and last modified", head)
+	}
+	list, err := client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{Bucket: aws.String("bucket")})
+	if err != nil {
+		t.Fatalf("ListObjectsV2() error = %v", err)
+	}
+	if len(list.Contents) != 1 || aws.ToString(list.Contents[0].Key) != "docs/report.json" || aws.ToInt64(list.Contents[0].Size) != 11 {
+		t.Fatalf("ListObjectsV2() = %#v, want persisted object", list.Contents)
+	}
+}
+
+func TestS3CompatibilityAdapterServesRangedGets(t *testing.T) {
+	server := httptest.NewServer(compataws.NewS3Adapter())
+	defer server.Close()
+	client := newS3TestClient(server.URL)
+
+	if _, err := client.CreateBucket(context.Background(), &s3.CreateBucketInput{Bucket: aws.String("bucket")}); err != nil {
+		t.Fatalf("CreateBucket() error = %v", err)
+	}
+	if _, err := client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("alphabet.txt"),
+		Body:   strings.NewReader("abcdefghij"),
+	}); err != nil {
+		t.Fatalf("PutObject() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		rangeHeader  string
+		body         string
+		contentRange string
+	}{
+		{"bytes=2-4", "cde", "bytes 2-4/10"},
+		{"bytes=7-", "hij", "bytes 7-9/10"},
+		{"bytes=-2", "ij", "bytes 8-9/10"},
+		{"bytes=8-100", "ij", "bytes 8-9/10"},
+	} {
+		got, err := client.GetObject(context.Background(), &s3.GetObjectInput{
+			Bucket: aws.String("bucket"),
+			Key:    aws.String("alphabet.txt"),
+			Range:  aws.String(tt.rangeHeader),
+		})
+		if err != nil {
+			t.Fatalf("GetObject(%s) error = %v", tt.rangeHeader, err)
+		}
+		body, _ := io.ReadAll(got.Body)
+		if string(body) != tt.body || aws.ToString(got.ContentRange) != tt.contentRange {
+			t.Fatalf("GetObject(%s) = %q %q, want %q %q", tt.rangeHeader, body, aws.ToString(got.ContentRange), tt.body, tt.contentRange)
+		}
+	}
+
+	_, err := client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("alphabet.txt"),
+		Range:  aws.String("bytes=20-30"),
+	})
+	var apiErr smithy.APIError
+	if err == nil || !errors.As(err, &apiErr) || apiErr.ErrorCode() != "InvalidRange" {
+		t.Fatalf("GetObject(unsatisfiable range) error = %v, want InvalidRange", err)
+	}
+}
+
+func TestS3CompatibilityAdapterCompletesMultipartUploads(t *testing.T) {
+	backend, err := compataws.NewFileS3Backend(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileS3Backend() error = %v", err)
+	}
+	server := httptest.NewServer(compataws.NewS3Adapter(compataws.WithS3Backend(backend)))
+	defer server.Close()
+	client := newS3TestClient(server.URL)
+
+	if _, err := client.CreateBucket(context.Background(), &s3.CreateBucketInput{Bucket: aws.String("bucket")}); err != nil {
+		t.Fatalf("CreateBucket() error = %v", err)
+	}
+	assertS3MultipartUpload(t, client)
+}
+
+func TestS3CompatibilityAdapterPaginatesListObjectsV2WithDelimiter(t *testing.T) {
+	server := httptest.NewServer(compataws.NewS3Adapter())
+	defer server.Close()
+	client := newS3TestClient(server.URL)
+
+	if _, err := client.CreateBucket(context.Background(), &s3.CreateBucketInput{Bucket: aws.String("bucket")}); err != nil {
+		t.Fatalf("CreateBucket() error = %v", err)
+	}
+	for _, key := range []string{"logs/a/1.txt", "logs/a/2.txt", "logs/b/1.txt", "logs/root.txt", "logs/z/1.txt", "other.txt"} {
+		if _, err := client.PutObject(context.Background(), &s3.PutObjectInput{
+			Bucket: aws.String("bucket"),
+			Key:    aws.String(key),
+			Body:   strings.NewReader(key),
+		}); err != nil {
+			t.Fatalf("PutObject(%s) error = %v", key, err)
+		}
+	}
+
+	var entries []string
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String("bucket"),
+		Prefix:    aws.String("logs/"),
+		Delimiter: aws.String("/"),
+		MaxKeys:   aws.Int32(1),
+	})
+	for pages := 0; paginator.HasMorePages(); pages++ {
+		if pages > 10 {
+			t.Fatal("ListObjectsV2 paginator did not terminate")
+		}
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			t.Fatalf("ListObjectsV2 page error = %v", err)
+		}
+		for _, prefix := range page.CommonPrefixes {
+			entries = append(entries, aws.ToString(prefix.Prefix))
+		}
+		for _, object := range page.Contents {
+			entries = append(entries, aws.ToString(object.Key))
+		}
+	}
+	want := "logs/a/,logs/b/,logs/root.txt,logs/z/"
+	if got := strings.Join(entries, ","); got != want {
+		t.Fatalf("ListObjectsV2 entries = %s, want %s", got, want)
+	}
+
+	after, err := client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{
+		Bucket:     aws.String("bucket"),
+		StartAfter: aws.String("logs/root.txt"),
+	})
+	if err != nil {
+		t.Fatalf("ListObjectsV2(start-after) error = %v", err)
+	}
+	if len(after.Contents) != 2 || aws.ToString(after.Contents[0].Key) != "logs/z/1.txt" {
+		t.Fatalf("ListObjectsV2(start-after) = %#v, want keys after logs/root.txt", after.Contents)
+	}
+}
+
+func TestS3CompatibilityAdapterProxiesToMinIOBackend(t *testing.T) {
+	// A second adapter stands in for the MinIO server of the storage stack.
+	upstream := compataws.NewS3Adapter()
+	var unsigned []string
+	minio := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio-user/") {
+			unsigned = append(unsigned, r.Method+" "+r.URL.String())
+		}
+		upstream.ServeHTTP(w, r)
+	}))
+	defer minio.Close()
+
+	backend, err := compataws.NewMinIOS3Backend(minio.URL, "minio-user", "minio-secret")
+	if err != nil {
+		t.Fatalf("NewMinIOS3Backend() error = %v", err)
+	}
+	server := httptest.NewServer(compataws.NewS3Adapter(compataws.WithS3Backend(backend)))
+	defer server.Close()
+	client := newS3TestClient(server.URL)
+
+	if _, err := client.CreateBucket(context.Background(), &s3.CreateBucketInput{Bucket: aws.String("bucket")}); err != nil {
+		t.Fatalf("CreateBucket() error = %v", err)
+	}
+	_, err = client.CreateBucket(context.Background(), &s3.CreateBucketInput{Bucket: aws.String("bucket")})
+	var apiErr smithy.APIError
+	if err == nil || !errors.As(err, &apiErr) || apiErr.ErrorCode() != "BucketAlreadyOwnedByYou" {
+		t.Fatalf("CreateBucket(duplicate) error = %v, want BucketAlreadyOwnedByYou", err)
+	}
+	if _, err := client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:      aws.String("bucket"),
+		Key:         aws.String("dir/hello world.txt"),
+		Body:        strings.NewReader("hello world"),
+		ContentType: aws.String("text/plain"),
+		Metadata:    map[string]string{"team": "ops"},
+	}); err != nil {
+		t.Fatalf("PutObject() error = %v", err)
+	}
+	got, err := client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("dir/hello world.txt"),
+		Range:  aws.String("bytes=6-"),
+	})
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	body, _ := io.ReadAll(got.Body)
+	if string(body) != "world" || aws.ToString(got.ContentType) != "text/plain" || got.Metadata["team"] != "ops" {
+		t.Fatalf("GetObject() = %q %q %v, want ranged body and metadata from MinIO", body, aws.ToString(got.ContentType), got.Metadata)
+	}
+	_, err = client.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("missing.txt")})
+	var noSuchKey *s3types.NoSuchKey
+	if !errors.As(err, &noSuchKey) {
+		t.Fatalf("GetObject(missing) error = %v, want NoSuchKey", err)
+	}
+	if _, err := client.PutBucketTagging(context.Background(), &s3.PutBucketTaggingInput{
+		Bucket:  aws.String("bucket"),
+		Tagging: &s3types.Tagging{TagSet: []s3types.Tag{{Key: aws.String("env"), Value: aws.String("prod")}}},
+	}); err != nil {
+		t.Fatalf("PutBucketTagging() error = %v", err)
+	}
+	tags, err := client.GetBucketTagging(context.Background(), &s3.GetBucketTaggingInput{Bucket: aws.String("bucket")})
+	if err != nil || len(tags.TagSet) != 1 || aws.ToString(tags.TagSet[0].Value) != "prod" {
+		t.Fatalf("GetBucketTagging() = %#v, %v, want env=prod", tags, err)
+	}
+
+	assertS3MultipartUpload(t, client)
+
+	list, err := client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{Bucket: aws.String("bucket"), MaxKeys: aws.Int32(1)})
+	if err != nil {
+		t.Fatalf("ListObjectsV2() error = %v", err)
+	}
+	next, err := client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{
+		Bucket:            aws.String("bucket"),
+		MaxKeys:           aws.Int32(1),
+		ContinuationToken: list.NextContinuationToken,
+	})
+	if err != nil {
+		t.Fatalf("ListObjectsV2(next) error = %v", err)
+	}
+	if len(list.Contents) != 1 || len(next.Contents) != 1 || aws.ToString(list.Contents[0].Key) != "big.bin" || aws.ToString(next.Contents[0].Key) != "dir/hello world.txt" || aws.ToBool(next.IsTruncated) {
+		t.Fatalf("ListObjectsV2 pages = %#v / %#v, want big.bin then dir/hello world.txt", list.Contents, next.Contents)
+	}
+	if len(unsigned) > 0 {
+		t.Fatalf("requests reached MinIO without a signature: %v", unsigned)
+	}
+}
+
+// assertS3MultipartUpload uploads big.bin in two parts through client and
+// checks the assembled object, then checks that undersized parts are
+// rejected.
+func assertS3MultipartUpload(t *testing.T, client *s3.Client) {
+	t.Helper()
+	ctx := context.Background()
+	first := bytes.Repeat([]byte("a"), 5<<20)
+	second := []byte("tail")
+
+	created, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String("bucket"),
+		Key:         aws.String("big.bin"),
+		ContentType: aws.String("application/octet-stream"),
+		Metadata:    map[string]string{"source": "multipart"},
+	})
+	if err != nil {
+		t.Fatalf("CreateMultipartUpload() error = %v", err)
+	}
+	var completed []s3types.CompletedPart
+	for i, data := range [][]byte{first, second} {
+		part, err := client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String("bucket"),
+			Key:        aws.String("big.bin"),
+			UploadId:   created.UploadId,
+			PartNumber: aws.Int32(int32(i + 1)),
+			Body:       bytes.NewReader(data),
+		})
+		if err != nil {
+			t.Fatalf("UploadPart(%d) error = %v", i+1, err)
+		}
+		completed = append(completed, s3types.CompletedPart{ETag: part.ETag, PartNumber: aws.Int32(int32(i + 1))})
+	}
+	done, err := client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String("bucket"),
+		Key:             aws.String("big.bin"),
+		UploadId:        created.UploadId,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		t.Fatalf("CompleteMultipartUpload() error = %v", err)
+	}
+	if !strings.HasSuffix(aws.ToString(done.ETag), `-2"`) {
+		t.Fatalf("CompleteMultipartUpload ETag = %q, want multipart ETag", aws.ToString(done.ETag))
+	}
+
+	got, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("big.bin"), Range: aws.String("bytes=-6")})
+	if err != nil {
+		t.Fatalf("GetObject(big.bin) error = %v", err)
+	}
+	tail, _ := io.ReadAll(got.Body)
+	if string(tail) != "aatail" || got.Metadata["source"] != "multipart" {
+		t.Fatalf("GetObject(big.bin) tail = %q metadata %v, want aatail and multipart metadata", tail, got.Metadata)
+	}
+
+	small, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{Bucket: aws.String("bucket"), Key: aws.String("small.bin")})
+	if err != nil {
+		t.Fatalf("CreateMultipartUpload(small) error = %v", err)
+	}
+	var parts []s3types.CompletedPart
+	for i := 1; i <= 2; i++ {
+		part, err := client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String("bucket"),
+			Key:        aws.String("small.bin"),
+			UploadId:   small.UploadId,
+			PartNumber: aws.Int32(int32(i)),
+			Body:       strings.NewReader("tiny"),
+		})
+		if err != nil {
+			t.Fatalf("UploadPart(small %d) error = %v", i, err)
+		}
+		parts = append(parts, s3types.CompletedPart{ETag: part.ETag, PartNumber: aws.Int32(int32(i))})
+	}
+	_, err = client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String("bucket"),
+		Key:             aws.String("small.bin"),
+		UploadId:        small.UploadId,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+	})
+	var apiErr smithy.APIError
+	if err == nil || !errors.As(err, &apiErr) || apiErr.ErrorCode() != "EntityTooSmall" {
+		t.Fatalf("CompleteMultipartUpload(small parts) error = %v, want EntityTooSmall", err)
+	}
+	if _, err := client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String("bucket"),
+		Key:      aws.String("small.bin"),
+		UploadId: small.UploadId,
+	}); err != nil {
+		t.Fatalf("AbortMultipartUpload() error = %v", err)
+	}
+	_, err = client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String("bucket"),
+		Key:        aws.String("small.bin"),
+		UploadId:   small.UploadId,
+		PartNumber: aws.Int32(3),
+		Body:       strings.NewReader("late"),
+	})
+	if err == nil || !errors.As(err, &apiErr) || apiErr.ErrorCode() != "NoSuchUpload" {
+		t.Fatalf("UploadPart(aborted) error = %v, want NoSuchUpload", err)
+	}
+}
+
+func newS3TestClient(endpoint string) *s3.Client {
+	return s3.NewFromConfig(aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("homeport", "homeport", ""),
+	}, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+	})
+}
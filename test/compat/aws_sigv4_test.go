@@ -0,0 +1,168 @@
+package compat_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	compataws "github.com/homeport/homeport/internal/app/compat/aws"
+	"github.com/homeport/homeport/internal/domain/authz"
+)
+
+func TestSigV4VerifierAuthenticatesS3HeaderAndPresignedRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access-keys.json")
+	store, err := compataws.NewFileAccessKeyStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := store.Issue("shop", "ci-deployer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed := store.List("shop"); len(listed) != 1 || listed[0].SecretAccessKey != "" {
+		t.Fatalf("List(shop) = %#v, want one key without its secret", listed)
+	}
+
+	var mu sync.Mutex
+	var seen []authz.Request
+	adapter := compataws.NewS3Adapter(compataws.WithS3Authorizer(authz.AuthorizerFunc(func(_ context.Context, req authz.Request) (authz.Decision, error) {
+		mu.Lock()
+		seen = append(seen, req)
+		mu.Unlock()
+		return authz.Decision{Request: req, Allowed: true}, nil
+	})))
+	// Mirror the gateway: verify against the full path, then strip it.
+	const prefix = "/api/v1/compat/aws/s3"
+	server := httptest.NewServer(compataws.NewSigV4Verifier(store).Handler("s3", http.StripPrefix(prefix, adapter)))
+	defer server.Close()
+
+	client := newSigV4S3Client(server.URL+prefix, key.AccessKeyID, key.SecretAccessKey)
+	ctx := context.Background()
+	if _, err := client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String("invoices")}); err != nil {
+		t.Fatalf("CreateBucket() error = %v", err)
+	}
+	if _, err := client.PutObject(ctx, &s3.PutObjectInput{Bucket: aws.String("invoices"), Key: aws.String("2026/march report.pdf"), Body: strings.NewReader("pdf")}); err != nil {
+		t.Fatalf("PutObject() error = %v", err)
+	}
+	mu.Lock()
+	for _, req := range seen {
+		if req.Principal != "ci-deployer" || req.PrincipalAttributes["workspace"] != "shop" || req.PrincipalAttributes["access_key_id"] != key.AccessKeyID {
+			t.Fatalf("authz request = %#v, want verified ci-deployer in shop", req)
+		}
+	}
+	mu.Unlock()
+
+	presigned, err := s3.NewPresignClient(client).PresignGetObject(ctx, &s3.GetObjectInput{Bucket: aws.String("invoices"), Key: aws.String("2026/march report.pdf")}, s3.WithPresignExpires(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(presigned.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "pdf" {
+		t.Fatalf("presigned GET = %d %q, want 200 pdf", resp.StatusCode, body)
+	}
+
+	tampered := strings.Replace(presigned.URL, "invoices/", "invoices/other-", 1)
+	resp, err = http.Get(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || !strings.Contains(string(body), "<Code>SignatureDoesNotMatch</Code>") {
+		t.Fatalf("tampered presigned GET = %d %s, want SignatureDoesNotMatch", resp.StatusCode, body)
+	}
+
+	_, err = newSigV4S3Client(server.URL+prefix, key.AccessKeyID, "wrong-secret").ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: aws.String("invoices")})
+	assertAPIErrorCode(t, err, "SignatureDoesNotMatch")
+
+	if err := store.Revoke(key.AccessKeyID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: aws.String("invoices")})
+	assertAPIErrorCode(t, err, "InvalidClientTokenId")
+
+	reopened, err := compataws.NewFileAccessKeyStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.LookupAccessKey(key.AccessKeyID); !errors.Is(err, compataws.ErrAccessKeyNotFound) {
+		t.Fatalf("LookupAccessKey(revoked) error = %v, want ErrAccessKeyNotFound", err)
+	}
+}
+
+func TestSigV4VerifierRejectsSkewedAndUnknownDynamoDBRequests(t *testing.T) {
+	keys := compataws.StaticAccessKeys{"HPKATEST": {SecretAccessKey: "secret", Workspace: "shop", Principal: "orders"}}
+	clock := time.Now()
+	verifier := compataws.NewSigV4Verifier(keys, compataws.WithSigV4Clock(func() time.Time { return clock }))
+	server := httptest.NewServer(verifier.Handler("dynamodb", compataws.NewDynamoDBAdapter()))
+	defer server.Close()
+
+	newClient := func(accessKey string) *dynamodb.Client {
+		return dynamodb.NewFromConfig(aws.Config{
+			Region:           "us-east-1",
+			Credentials:      credentials.NewStaticCredentialsProvider(accessKey, "secret", ""),
+			RetryMaxAttempts: 1,
+		}, func(o *dynamodb.Options) {
+			o.BaseEndpoint = aws.String(server.URL)
+		})
+	}
+	ctx := context.Background()
+	if _, err := newClient("HPKATEST").ListTables(ctx, &dynamodb.ListTablesInput{}); err != nil {
+		t.Fatalf("ListTables() error = %v", err)
+	}
+
+	_, err := newClient("AKIAUNKNOWN").ListTables(ctx, &dynamodb.ListTablesInput{})
+	assertAPIErrorCode(t, err, "InvalidClientTokenId")
+
+	clock = clock.Add(time.Hour)
+	_, err = newClient("HPKATEST").ListTables(ctx, &dynamodb.ListTablesInput{})
+	assertAPIErrorCode(t, err, "RequestTimeTooSkewed")
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+	req.Header.Set("X-Amz-Target", "DynamoDB_20120810.ListTables")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || !strings.Contains(string(body), `"__type":"MissingAuthenticationToken"`) {
+		t.Fatalf("unsigned request = %d %s, want MissingAuthenticationToken", resp.StatusCode, body)
+	}
+}
+
+func newSigV4S3Client(endpoint, accessKey, secretKey string) *s3.Client {
+	return s3.NewFromConfig(aws.Config{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""),
+		RetryMaxAttempts: 1,
+	}, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+	})
+}
+
+func assertAPIErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
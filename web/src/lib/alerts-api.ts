@@ -0,0 +1,219 @@
+import { fetchAPI } from './api';
+
+export type AlertSeverity = 'info' | 'warning' | 'critical';
+export type AlertState = 'pending' | 'firing' | 'resolved';
+export type AlertOperator = 'gt' | 'gte' | 'lt' | 'lte' | 'eq';
+export type AlertAggregation = 'avg' | 'max' | 'min' | 'sum' | 'count' | 'p50' | 'p95' | 'p99';
+export type NotificationChannelType = 'webhook' | 'smtp' | 'ntfy' | 'gotify' | 'slack';
+
+export interface AlertThreshold {
+  metric_name: string;
+  container_id?: string;
+  operator: AlertOperator;
+  value: number;
+  duration: number;
+  severity: AlertSeverity;
+}
+
+export interface Alert {
+  id: string;
+  rule_id: string;
+  rule_name: string;
+  state: AlertState;
+  threshold: AlertThreshold;
+  triggered_at: string;
+  fired_at?: string;
+  resolved_at?: string;
+  current_value: number;
+  silenced: boolean;
+  message: string;
+}
+
+export interface AlertsResponse {
+  alerts: Alert[];
+  count: number;
+}
+
+// Durations use Go syntax, e.g. "5m" or "1h30m"
+export interface AlertRuleRequest {
+  name: string;
+  description?: string;
+  metric_name: string;
+  container_id?: string;
+  aggregation?: AlertAggregation;
+  window?: string;
+  operator: AlertOperator;
+  threshold: number;
+  for?: string;
+  severity?: AlertSeverity;
+  channels?: string[];
+  enabled?: boolean;
+}
+
+export interface AlertRule {
+  id: string;
+  name: string;
+  description?: string;
+  metric_name: string;
+  container_id?: string;
+  aggregation: AlertAggregation;
+  window: string;
+  operator: AlertOperator;
+  threshold: number;
+  for: string;
+  severity: AlertSeverity;
+  channels: string[];
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SilenceRequest {
+  rule_id?: string;
+  starts_at?: string;
+  ends_at?: string;
+  duration?: string;
+  comment?: string;
+  created_by?: string;
+}
+
+export interface Silence {
+  id: string;
+  rule_id?: string;
+  starts_at: string;
+  ends_at: string;
+  comment?: string;
+  created_by?: string;
+  created_at: string;
+}
+
+// Secrets (token, password, header values) are returned as "********";
+// sending them back unchanged keeps the stored value.
+export interface NotificationChannel {
+  id: string;
+  name: string;
+  type: NotificationChannelType;
+  enabled: boolean;
+  url?: string;
+  token?: string;
+  headers?: Record<string, string>;
+  smtp_host?: string;
+  smtp_port?: number;
+  username?: string;
+  password?: string;
+  from?: string;
+  to?: string[];
+  min_severity?: AlertSeverity;
+  created_at: string;
+  updated_at: string;
+}
+
+export type NotificationChannelRequest = Omit<NotificationChannel, 'id' | 'created_at' | 'updated_at'>;
+
+// List pending and firing alerts
+export async function listActiveAlerts(): Promise<AlertsResponse> {
+  return fetchAPI<AlertsResponse>('/alerts', {
+    method: 'GET',
+  });
+}
+
+// List recently resolved alerts
+export async function listAlertHistory(): Promise<AlertsResponse> {
+  return fetchAPI<AlertsResponse>('/alerts/history', {
+    method: 'GET',
+  });
+}
+
+// List alert rules
+export async function listAlertRules(): Promise<{ rules: AlertRule[]; count: number }> {
+  return fetchAPI<{ rules: AlertRule[]; count: number }>('/alerts/rules', {
+    method: 'GET',
+  });
+}
+
+// Create an alert rule
+export async function createAlertRule(request: AlertRuleRequest): Promise<AlertRule> {
+  return fetchAPI<AlertRule>('/alerts/rules', {
+    method: 'POST',
+    body: JSON.stringify(request),
+  });
+}
+
+// Replace an alert rule
+export async function updateAlertRule(ruleId: string, request: AlertRuleRequest): Promise<AlertRule> {
+  return fetchAPI<AlertRule>(`/alerts/rules/${ruleId}`, {
+    method: 'PUT',
+    body: JSON.stringify(request),
+  });
+}
+
+// Delete an alert rule
+export async function deleteAlertRule(ruleId: string): Promise<void> {
+  await fetchAPI<void>(`/alerts/rules/${ruleId}`, {
+    method: 'DELETE',
+  });
+}
+
+// List silences, including expired ones
+export async function listSilences(): Promise<{ silences: Silence[]; count: number }> {
+  return fetchAPI<{ silences: Silence[]; count: number }>('/alerts/silences', {
+    method: 'GET',
+  });
+}
+
+// Silence one rule, or every rule when rule_id is omitted
+export async function createSilence(request: SilenceRequest): Promise<Silence> {
+  return fetchAPI<Silence>('/alerts/silences', {
+    method: 'POST',
+    body: JSON.stringify(request),
+  });
+}
+
+// End a silence early
+export async function deleteSilence(silenceId: string): Promise<void> {
+  await fetchAPI<void>(`/alerts/silences/${silenceId}`, {
+    method: 'DELETE',
+  });
+}
+
+// List notification channels
+export async function listNotificationChannels(): Promise<{ channels: NotificationChannel[]; count: number }> {
+  return fetchAPI<{ channels: NotificationChannel[]; count: number }>('/alerts/channels', {
+    method: 'GET',
+  });
+}
+
+// Create a notification channel
+export async function createNotificationChannel(
+  request: NotificationChannelRequest
+): Promise<NotificationChannel> {
+  return fetchAPI<NotificationChannel>('/alerts/channels', {
+    method: 'POST',
+    body: JSON.stringify(request),
+  });
+}
+
+// Update a notification channel
+export async function updateNotificationChannel(
+  channelId: string,
+  request: Partial<NotificationChannelRequest>
+): Promise<NotificationChannel> {
+  return fetchAPI<NotificationChannel>(`/alerts/channels/${channelId}`, {
+    method: 'PUT',
+    body: JSON.stringify(request),
+  });
+}
+
+// Delete a notification channel
+export async function deleteNotificationChannel(channelId: string): Promise<void> {
+  await fetchAPI<void>(`/alerts/channels/${channelId}`, {
+    method: 'DELETE',
+  });
+}
+
+// Send a test notification through a channel
+export async function testNotificationChannel(channelId: string): Promise<void> {
+  await fetchAPI<void>(`/alerts/channels/${channelId}/test`, {
+    method: 'POST',
+  });
+}
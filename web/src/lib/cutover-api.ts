@@ -20,11 +20,33 @@ export interface DNSChangeRequest {
   ttl?: number;
 }
 
+export interface TrafficShiftRequest {
+  id: string;
+  name?: string;
+  method?: 'dns_weighted' | 'traefik';
+  domain: string;
+  record_name?: string;
+  stages?: number[];
+  stage_duration_seconds?: number;
+  sample_interval_seconds?: number;
+  gate_checks?: HealthCheckRequest[];
+  max_error_rate?: number;
+  metrics_url?: string;
+  metrics_service?: string;
+  record_type?: 'A' | 'CNAME' | 'AAAA';
+  ttl?: number;
+  new_value?: string;
+  old_value?: string;
+  traefik_service?: string;
+  legacy_url?: string;
+}
+
 export interface CreateCutoverRequest {
   bundle_id: string;
   name?: string;
   pre_checks: HealthCheckRequest[];
   dns_changes: DNSChangeRequest[];
+  traffic_shifts?: TrafficShiftRequest[];
   post_checks: HealthCheckRequest[];
   dry_run: boolean;
   dns_provider?: string;
@@ -58,6 +80,46 @@ export interface CutoverStatusResponse {
   error?: string;
 }
 
+export interface InterruptedCutoversResponse {
+  cutovers: CutoverStatusResponse[];
+  count: number;
+}
+
+// A DNS record as the provider had it before a change
+export interface DNSRecord {
+  id?: string;
+  domain: string;
+  type: string;
+  name: string;
+  value: string;
+  ttl: number;
+  set_identifier?: string;
+  routing_weight?: number;
+}
+
+export interface DNSMutation {
+  time: string;
+  plan_id: string;
+  change_id: string;
+  action: 'apply' | 'rollback' | 'weight';
+  status: 'started' | 'succeeded' | 'failed';
+  provider: string;
+  name: string;
+  record_type: string;
+  old_value?: string;
+  new_value?: string;
+  ttl?: number;
+  set_identifier?: string;
+  routing_weight?: number;
+  previous?: DNSRecord;
+  error?: string;
+}
+
+export interface CutoverJournalResponse {
+  mutations: DNSMutation[];
+  count: number;
+}
+
 export interface CutoverEvent {
   type: string;
   plan_id: string;
@@ -133,6 +195,27 @@ export async function rollbackCutover(cutoverId: string): Promise<void> {
   });
 }
 
+// List cutovers interrupted by a restart
+export async function listInterruptedCutovers(): Promise<InterruptedCutoversResponse> {
+  return fetchAPI<InterruptedCutoversResponse>('/cutover/interrupted', {
+    method: 'GET',
+  });
+}
+
+// Resume an interrupted cutover
+export async function resumeCutover(cutoverId: string): Promise<void> {
+  await fetchAPI<void>(`/cutover/${cutoverId}/resume`, {
+    method: 'POST',
+  });
+}
+
+// Get the journal of DNS mutations made by a cutover
+export async function getCutoverJournal(cutoverId: string): Promise<CutoverJournalResponse> {
+  return fetchAPI<CutoverJournalResponse>(`/cutover/${cutoverId}/journal`, {
+    method: 'GET',
+  });
+}
+
 // Subscribe to cutover progress via SSE
 export function subscribeToCutover(
   cutoverId: string,
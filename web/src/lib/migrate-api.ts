@@ -27,13 +27,21 @@ export interface AppChange {
   reason: string;
   adapter_url?: string;
   file?: string;
+  start_line?: number;
+  end_line?: number;
   search?: string;
   replace?: string;
   validation_cmd?: string;
 }
 
+export interface AppChangePatch {
+  file: string;
+  diff: string;
+}
+
 export interface AppChangeReport {
   changes: AppChange[];
+  patches?: AppChangePatch[];
 }
 
 export interface GenerateOptions {
@@ -45,6 +45,7 @@ export interface SyncTaskStatus {
   bytes_done: number;
   items_total: number;
   items_done: number;
+  replication_lag_bytes?: number;
   error?: string;
 }
 
@@ -69,6 +70,7 @@ export interface SyncEvent {
   bytes_done?: number;
   items_total?: number;
   items_done?: number;
+  replication_lag_bytes?: number;
   error?: string;
   message?: string;
 }
@@ -113,6 +115,13 @@ export async function resumeSync(syncId: string): Promise<void> {
   });
 }
 
+// Complete a replicating sync, promoting its targets once they caught up
+export async function completeSync(syncId: string): Promise<void> {
+  await fetchAPI<void>(`/sync/${syncId}/complete`, {
+    method: 'POST',
+  });
+}
+
 // Cancel a running sync
 export async function cancelSync(syncId: string): Promise<void> {
   await fetchAPI<void>(`/sync/${syncId}/cancel`, {